@@ -0,0 +1,105 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AccessLogEntry describes an upgrade request handled by an Upgrader, for
+// access logging. See Upgrader.AccessLog.
+type AccessLogEntry struct {
+	// Time is the time when the upgrade started.
+	Time time.Time
+
+	// Duration is the time taken by the upgrade, from the start of Upgrade
+	// to the handshake response or the failure.
+	Duration time.Duration
+
+	// RemoteAddr, Host and RequestURI are the fields of the request.
+	RemoteAddr string
+	Host       string
+	RequestURI string
+
+	// Origin and UserAgent are the Origin and User-Agent request headers.
+	Origin    string
+	UserAgent string
+
+	// RequestedSubprotocols are the subprotocols requested by the client and
+	// RequestedExtensions is the Sec-WebSocket-Extensions request header.
+	RequestedSubprotocols []string
+	RequestedExtensions   string
+
+	// Subprotocol is the selected subprotocol and Extensions is the
+	// Sec-WebSocket-Extensions response header. Both are empty if the
+	// upgrade failed.
+	Subprotocol string
+	Extensions  string
+
+	// Status is the status code of the handshake response:
+	// http.StatusSwitchingProtocols if the upgrade succeeded, the status of
+	// the error response if it was rejected, or zero if the upgrade failed
+	// after the connection was hijacked.
+	Status int
+
+	// Err is the error returned by Upgrade, or nil if the upgrade succeeded.
+	Err error
+}
+
+// String returns the entry as space-separated key=value pairs with quoted
+// values, suitable for a line of an access log.
+func (e AccessLogEntry) String() string {
+	var b strings.Builder
+	b.WriteString(e.Time.Format(time.RFC3339Nano))
+	field := func(key, value string) {
+		b.WriteByte(' ')
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(value))
+	}
+	field("remote", e.RemoteAddr)
+	field("host", e.Host)
+	field("uri", e.RequestURI)
+	field("origin", e.Origin)
+	field("user_agent", e.UserAgent)
+	field("requested_subprotocols", strings.Join(e.RequestedSubprotocols, ", "))
+	field("requested_extensions", e.RequestedExtensions)
+	field("subprotocol", e.Subprotocol)
+	field("extensions", e.Extensions)
+	b.WriteString(" status=")
+	b.WriteString(strconv.Itoa(e.Status))
+	b.WriteString(" duration=")
+	b.WriteString(e.Duration.String())
+	if e.Err != nil {
+		field("error", e.Err.Error())
+	}
+	return b.String()
+}
+
+// logAccess calls the AccessLog function with the result of an upgrade
+// that started at start.
+func (u *Upgrader) logAccess(r *http.Request, c *Conn, err error, start time.Time) {
+	e := AccessLogEntry{
+		Time:                  start,
+		Duration:              clockOrSystem(u.Clock).Now().Sub(start),
+		RemoteAddr:            r.RemoteAddr,
+		Host:                  r.Host,
+		RequestURI:            r.RequestURI,
+		Origin:                r.Header.Get("Origin"),
+		UserAgent:             r.UserAgent(),
+		RequestedSubprotocols: Subprotocols(r),
+		RequestedExtensions:   strings.Join(r.Header.Values("Sec-Websocket-Extensions"), ", "),
+		Status:                handshakeStatus(err),
+		Err:                   err,
+	}
+	if c != nil {
+		e.Subprotocol = c.subprotocol
+		e.Extensions = c.extensions
+	}
+	u.AccessLog(e)
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stepClock is a Clock that advances by a step on every call to Now.
+type stepClock struct {
+	mu   sync.Mutex
+	now  time.Time
+	step time.Duration
+}
+
+func (c *stepClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(c.step)
+	return c.now
+}
+
+func (c *stepClock) NewTimer(d time.Duration) Timer { return systemClock{}.NewTimer(d) }
+
+func TestAccessLog(t *testing.T) {
+	clock := &stepClock{now: time.Now(), step: time.Millisecond}
+	entries := make(chan AccessLogEntry, 1)
+	upgrader := Upgrader{
+		Subprotocols:      []string{"chat"},
+		EnableCompression: true,
+		Clock:             clock,
+		CheckOrigin: func(r *http.Request) bool {
+			return r.Header.Get("Origin") != "http://evil.example"
+		},
+		AccessLog: func(e AccessLogEntry) { entries <- e },
+	}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err == nil {
+			c.Close()
+		}
+	}))
+	defer s.Close()
+
+	tests := []struct {
+		name   string
+		origin string
+		status int
+	}{
+		{"success", "http://good.example", http.StatusSwitchingProtocols},
+		{"failure", "http://evil.example", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		d := Dialer{Subprotocols: []string{"other", "chat"}, EnableCompression: true}
+		header := http.Header{"Origin": {tt.origin}, "User-Agent": {"test-agent"}}
+		c, _, err := d.Dial(makeWsProto(s.URL)+"/path?q=1", header)
+		if err == nil {
+			c.Close()
+		}
+		e := <-entries
+		if e.Status != tt.status || e.Origin != tt.origin || e.UserAgent != "test-agent" ||
+			e.RequestURI != "/path?q=1" || e.RemoteAddr == "" || e.Duration <= 0 ||
+			!reflect.DeepEqual(e.RequestedSubprotocols, []string{"other", "chat"}) ||
+			!strings.HasPrefix(e.RequestedExtensions, "permessage-deflate") {
+			t.Errorf("%s: entry = %+v", tt.name, e)
+		}
+		if tt.status == http.StatusSwitchingProtocols {
+			if e.Err != nil || e.Subprotocol != "chat" || e.Extensions != permessageDeflateResponse {
+				t.Errorf("%s: entry error, subprotocol, extensions = %v, %q, %q", tt.name, e.Err, e.Subprotocol, e.Extensions)
+			}
+		} else {
+			var he HandshakeError
+			if !errors.As(e.Err, &he) || e.Subprotocol != "" || e.Extensions != "" {
+				t.Errorf("%s: entry error, subprotocol, extensions = %v, %q, %q", tt.name, e.Err, e.Subprotocol, e.Extensions)
+			}
+		}
+	}
+}
+
+func TestAccessLogEntryString(t *testing.T) {
+	e := AccessLogEntry{
+		Time:                  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Duration:              1500 * time.Microsecond,
+		RemoteAddr:            "192.0.2.1:1234",
+		Host:                  "example.com",
+		RequestURI:            "/ws",
+		UserAgent:             "agent \"1\"",
+		RequestedSubprotocols: []string{"a", "b"},
+		Subprotocol:           "a",
+		Status:                http.StatusForbidden,
+		Err:                   errors.New("denied"),
+	}
+	want := `2026-01-02T03:04:05Z remote="192.0.2.1:1234" host="example.com" uri="/ws" origin="" user_agent="agent \"1\"" ` +
+		`requested_subprotocols="a, b" requested_extensions="" subprotocol="a" extensions="" status=403 duration=1.5ms error="denied"`
+	if got := e.String(); got != want {
+		t.Errorf("String() =\n%s\nwant\n%s", got, want)
+	}
+}
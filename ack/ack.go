@@ -0,0 +1,397 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ack adds at-least-once delivery to websocket connections.
+//
+// Both peers wrap their connections with a Conn. Each message written with
+// Conn.WriteMessage carries a sequence number and stays pending until the
+// peer acknowledges it. Pending messages are retransmitted when a new
+// connection is attached after a reconnect and when the peer does not
+// acknowledge them within the retransmit timeout. The receiving Conn
+// acknowledges messages as it reads them and drops duplicates, so the
+// application reads each message once and in order while the Conn lives.
+//
+//	c, err := (&ack.Config{Store: store}).NewConn()
+//	...
+//	for {
+//		ws, _, err := dialer.Dial(url, nil)
+//		...
+//		c.Attach(ws)
+//		for {
+//			_, p, err := c.ReadMessage()
+//			if err != nil {
+//				break // reconnect
+//			}
+//			...
+//		}
+//	}
+//
+// A Store persists the pending messages, so that they survive a restart of
+// the sending process. The receiving application can persist LastReceived
+// and set Config.LastReceived on restart to keep dropping duplicates. When
+// the messages carry application IDs, a Dedup window drops the duplicates
+// that the sequence numbers do not catch.
+//
+// # Protocol
+//
+// A data message is sent with its type and a payload of "d", the decimal
+// sequence number, a space and the application data. An acknowledgement
+// is a text message of "a" and the decimal sequence number of the last
+// message received in order.
+package ack
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const defaultRetransmitTimeout = 30 * time.Second
+
+var (
+	// ErrClosed is returned by the methods of a closed Conn.
+	ErrClosed = errors.New("ack: connection closed")
+
+	errNotAttached = errors.New("ack: no connection attached")
+	errBadMessage  = errors.New("ack: malformed message")
+)
+
+// Message is a message that awaits acknowledgement.
+type Message struct {
+	Seq  uint64
+	Type int
+	Data []byte
+}
+
+// Store persists the messages that await acknowledgement. The methods are
+// called with the Conn's lock held and must not call the Conn.
+type Store interface {
+	// Save is called before a message is sent for the first time.
+	Save(m Message) error
+
+	// Ack is called when the peer acknowledges the messages up to and
+	// including seq.
+	Ack(seq uint64) error
+
+	// Load returns the pending messages saved by a previous Conn in order
+	// of their sequence numbers. Load is called by Config.NewConn.
+	Load() ([]Message, error)
+}
+
+// Config specifies the configuration of a Conn.
+type Config struct {
+	// Store persists pending messages. If Store is nil, pending messages
+	// are kept in memory only.
+	Store Store
+
+	// RetransmitTimeout is the time to wait for an acknowledgement before
+	// retransmitting a message. If RetransmitTimeout is zero, 30 seconds is
+	// used.
+	RetransmitTimeout time.Duration
+
+	// LastReceived is the sequence number of the last message received from
+	// the peer by a previous Conn. Messages up to LastReceived are dropped
+	// as duplicates.
+	LastReceived uint64
+
+	// Dedup drops the messages with an ID in the window as duplicates. The
+	// ID of a message is returned by MessageID. Messages are acknowledged
+	// whether or not they are dropped.
+	Dedup *Dedup
+
+	// MessageID returns the ID of a message for Dedup, or the empty string
+	// if the message has no ID.
+	MessageID func(messageType int, data []byte) string
+}
+
+type pendingMessage struct {
+	Message
+	sent time.Time // zero if not sent on the current connection
+}
+
+// Conn is a message connection with at-least-once delivery. The methods of
+// Conn are safe for concurrent use, but only one goroutine may read at a
+// time.
+type Conn struct {
+	store     Store
+	timeout   time.Duration
+	dedup     *Dedup
+	messageID func(messageType int, data []byte) string
+
+	writeMu sync.Mutex // serializes writes to ws
+
+	mu       sync.Mutex
+	ws       *websocket.Conn
+	nextSeq  uint64
+	pending  []pendingMessage
+	lastRecv uint64
+	closed   bool
+	done     chan struct{}
+}
+
+// NewConn returns a Conn with the configuration. The Conn has no network
+// connection until Attach is called.
+func (cfg *Config) NewConn() (*Conn, error) {
+	c := &Conn{
+		store:    cfg.Store,
+		timeout:  cfg.RetransmitTimeout,
+		nextSeq:  1,
+		lastRecv: cfg.LastReceived,
+		done:     make(chan struct{}),
+	}
+	if cfg.MessageID != nil {
+		c.dedup = cfg.Dedup
+		c.messageID = cfg.MessageID
+	}
+	if c.timeout <= 0 {
+		c.timeout = defaultRetransmitTimeout
+	}
+	if c.store != nil {
+		msgs, err := c.store.Load()
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range msgs {
+			c.pending = append(c.pending, pendingMessage{Message: m})
+			if m.Seq >= c.nextSeq {
+				c.nextSeq = m.Seq + 1
+			}
+		}
+	}
+	go c.retransmitLoop()
+	return c, nil
+}
+
+// Attach makes ws the network connection of c and retransmits the pending
+// messages. The previous connection, if any, is closed.
+func (c *Conn) Attach(ws *websocket.Conn) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		ws.Close()
+		return ErrClosed
+	}
+	if c.ws != nil {
+		c.ws.Close()
+	}
+	c.ws = ws
+	for i := range c.pending {
+		c.pending[i].sent = time.Time{}
+	}
+	c.mu.Unlock()
+	return c.flush()
+}
+
+// flush sends the pending messages that were not sent within the
+// retransmit timeout.
+func (c *Conn) flush() error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	now := time.Now()
+	for {
+		c.mu.Lock()
+		ws := c.ws
+		var m *pendingMessage
+		for i := range c.pending {
+			if now.Sub(c.pending[i].sent) >= c.timeout {
+				m = &c.pending[i]
+				break
+			}
+		}
+		if ws == nil || m == nil {
+			c.mu.Unlock()
+			return nil
+		}
+		m.sent = now
+		msg := m.Message
+		c.mu.Unlock()
+		if err := ws.WriteMessage(msg.Type, appendData(nil, msg.Seq, msg.Data)); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Conn) retransmitLoop() {
+	ticker := time.NewTicker(c.timeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			_ = c.flush()
+		}
+	}
+}
+
+// WriteMessage sends a data message. The message is pending until the peer
+// acknowledges it. If no connection is attached or the write fails,
+// WriteMessage returns an error and the message is retransmitted later.
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	m := Message{Seq: c.nextSeq, Type: messageType, Data: append([]byte(nil), data...)}
+	if c.store != nil {
+		if err := c.store.Save(m); err != nil {
+			c.mu.Unlock()
+			return err
+		}
+	}
+	c.nextSeq++
+	c.pending = append(c.pending, pendingMessage{Message: m, sent: time.Now()})
+	ws := c.ws
+	c.mu.Unlock()
+
+	if ws == nil {
+		return errNotAttached
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return ws.WriteMessage(messageType, appendData(nil, m.Seq, m.Data))
+}
+
+// ReadMessage reads the next data message from the peer. ReadMessage
+// handles acknowledgements from the peer, acknowledges data messages and
+// drops duplicates. After a read error, attach a new connection to
+// continue.
+func (c *Conn) ReadMessage() (messageType int, p []byte, err error) {
+	c.mu.Lock()
+	ws := c.ws
+	c.mu.Unlock()
+	if ws == nil {
+		return 0, nil, errNotAttached
+	}
+	for {
+		messageType, p, err = ws.ReadMessage()
+		if err != nil {
+			return 0, nil, err
+		}
+		if len(p) == 0 {
+			return 0, nil, errBadMessage
+		}
+		switch p[0] {
+		case 'a':
+			seq, err := strconv.ParseUint(string(p[1:]), 10, 64)
+			if err != nil {
+				return 0, nil, errBadMessage
+			}
+			if err := c.acked(seq); err != nil {
+				return 0, nil, err
+			}
+		case 'd':
+			seq, data, err := parseData(p)
+			if err != nil {
+				return 0, nil, err
+			}
+			c.mu.Lock()
+			next := seq == c.lastRecv+1
+			c.mu.Unlock()
+			deliver := next
+			if next && c.dedup != nil {
+				if id := c.messageID(messageType, data); id != "" {
+					seen, err := c.dedup.Seen(id)
+					if err != nil {
+						// Not acknowledged, the peer retransmits.
+						return 0, nil, err
+					}
+					deliver = !seen
+				}
+			}
+			c.mu.Lock()
+			if next {
+				c.lastRecv = seq
+			}
+			last := c.lastRecv
+			c.mu.Unlock()
+			// Acknowledge duplicates too, in case the previous
+			// acknowledgement was lost.
+			c.writeMu.Lock()
+			err = ws.WriteMessage(websocket.TextMessage, strconv.AppendUint([]byte{'a'}, last, 10))
+			c.writeMu.Unlock()
+			if err != nil {
+				return 0, nil, err
+			}
+			if deliver {
+				return messageType, data, nil
+			}
+		default:
+			return 0, nil, errBadMessage
+		}
+	}
+}
+
+// acked removes the messages up to seq from the pending messages.
+func (c *Conn) acked(seq uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for n < len(c.pending) && c.pending[n].Seq <= seq {
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	c.pending = append(c.pending[:0], c.pending[n:]...)
+	if c.store != nil {
+		return c.store.Ack(seq)
+	}
+	return nil
+}
+
+// Pending returns the number of messages that await acknowledgement.
+func (c *Conn) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.pending)
+}
+
+// LastReceived returns the sequence number of the last message delivered
+// by ReadMessage.
+func (c *Conn) LastReceived() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastRecv
+}
+
+// Close closes the network connection and stops retransmission. Pending
+// messages remain in the Store.
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+	c.closed = true
+	close(c.done)
+	if c.ws != nil {
+		return c.ws.Close()
+	}
+	return nil
+}
+
+func appendData(b []byte, seq uint64, data []byte) []byte {
+	b = append(b, 'd')
+	b = strconv.AppendUint(b, seq, 10)
+	b = append(b, ' ')
+	return append(b, data...)
+}
+
+func parseData(p []byte) (seq uint64, data []byte, err error) {
+	i := bytes.IndexByte(p, ' ')
+	if i < 0 {
+		return 0, nil, errBadMessage
+	}
+	seq, err = strconv.ParseUint(string(p[1:i]), 10, 64)
+	if err != nil {
+		return 0, nil, errBadMessage
+	}
+	return seq, p[i+1:], nil
+}
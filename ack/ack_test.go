@@ -0,0 +1,184 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ack
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type memStore struct {
+	mu   sync.Mutex
+	msgs []Message
+}
+
+func (s *memStore) Save(m Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.msgs = append(s.msgs, m)
+	return nil
+}
+
+func (s *memStore) Ack(seq uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.msgs) > 0 && s.msgs[0].Seq <= seq {
+		s.msgs = s.msgs[1:]
+	}
+	return nil
+}
+
+func (s *memStore) Load() ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.msgs...), nil
+}
+
+func (s *memStore) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.msgs)
+}
+
+func newConn(t *testing.T, cfg *Config) *Conn {
+	t.Helper()
+	c, err := cfg.NewConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// readAcks reads from c until an error so that c processes acknowledgements.
+func readAcks(c *Conn) {
+	go func() {
+		for {
+			if _, _, err := c.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+func waitPending(t *testing.T, c *Conn, want int) {
+	t.Helper()
+	for start := time.Now(); c.Pending() != want; time.Sleep(time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("Pending() = %d, want %d", c.Pending(), want)
+		}
+	}
+}
+
+func readString(t *testing.T, c *Conn) string {
+	t.Helper()
+	_, p, err := c.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	return string(p)
+}
+
+func TestDelivery(t *testing.T) {
+	store := &memStore{}
+	sender := newConn(t, &Config{Store: store})
+	defer sender.Close()
+	receiver := newConn(t, &Config{})
+	defer receiver.Close()
+
+	// Messages written before a connection is attached are sent on attach.
+	if err := sender.WriteMessage(websocket.TextMessage, []byte("a")); err == nil {
+		t.Error("WriteMessage() without connection returned nil error")
+	}
+	client, server := websocket.Pipe()
+	sender.Attach(client)
+	receiver.Attach(server)
+	readAcks(sender)
+	sender.WriteMessage(websocket.BinaryMessage, []byte("b"))
+
+	for _, want := range []string{"a", "b"} {
+		if got := readString(t, receiver); got != want {
+			t.Errorf("ReadMessage() = %q, want %q", got, want)
+		}
+	}
+	waitPending(t, sender, 0)
+	if n := store.len(); n != 0 {
+		t.Errorf("store has %d messages after acknowledgement, want 0", n)
+	}
+	if n := receiver.LastReceived(); n != 2 {
+		t.Errorf("LastReceived() = %d, want 2", n)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	store := &memStore{}
+	sender := newConn(t, &Config{Store: store})
+	receiver := newConn(t, &Config{})
+	defer receiver.Close()
+
+	// The first connection drops before the peer reads the message.
+	client, server := websocket.Pipe()
+	sender.Attach(client)
+	sender.WriteMessage(websocket.TextMessage, []byte("a"))
+	server.Close()
+	client.Close()
+	sender.Close()
+
+	// A new Conn loads the pending message from the store and sends it on
+	// the new connection.
+	sender = newConn(t, &Config{Store: store})
+	defer sender.Close()
+	if n := sender.Pending(); n != 1 {
+		t.Fatalf("Pending() after NewConn = %d, want 1", n)
+	}
+	client, server = websocket.Pipe()
+	sender.Attach(client)
+	receiver.Attach(server)
+	readAcks(sender)
+	sender.WriteMessage(websocket.TextMessage, []byte("b"))
+	for _, want := range []string{"a", "b"} {
+		if got := readString(t, receiver); got != want {
+			t.Errorf("ReadMessage() = %q, want %q", got, want)
+		}
+	}
+	waitPending(t, sender, 0)
+}
+
+func TestDuplicates(t *testing.T) {
+	sender := newConn(t, &Config{})
+	defer sender.Close()
+	receiver := newConn(t, &Config{LastReceived: 1})
+	defer receiver.Close()
+	client, server := websocket.Pipe()
+	sender.Attach(client)
+	receiver.Attach(server)
+	readAcks(sender)
+
+	sender.WriteMessage(websocket.TextMessage, []byte("duplicate"))
+	sender.WriteMessage(websocket.TextMessage, []byte("new"))
+	if got := readString(t, receiver); got != "new" {
+		t.Errorf("ReadMessage() = %q, want new", got)
+	}
+	waitPending(t, sender, 0)
+}
+
+func TestRetransmitTimeout(t *testing.T) {
+	sender := newConn(t, &Config{RetransmitTimeout: 20 * time.Millisecond})
+	defer sender.Close()
+	client, peer := websocket.Pipe()
+	defer peer.Close()
+	sender.Attach(client)
+	sender.WriteMessage(websocket.TextMessage, []byte("a"))
+
+	// The peer does not acknowledge, so the message is retransmitted.
+	for i := 0; i < 2; i++ {
+		_, p, err := peer.ReadMessage()
+		if err != nil || string(p) != "d1 a" {
+			t.Fatalf("transmission %d = %q, %v, want d1 a", i, p, err)
+		}
+	}
+}
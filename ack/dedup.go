@@ -0,0 +1,122 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ack
+
+import (
+	"container/list"
+	"sync"
+)
+
+const defaultDedupSize = 1024
+
+// DedupStore persists the message IDs of a Dedup window. The methods are
+// called with the Dedup's lock held and must not call the Dedup.
+type DedupStore interface {
+	// Add is called when an ID is added to the window.
+	Add(id string) error
+
+	// Remove is called when the least recently seen ID is evicted from the
+	// window.
+	Remove(id string) error
+
+	// Load returns the IDs saved by a previous Dedup from the least to the
+	// most recently seen. Load is called by DedupConfig.NewDedup.
+	Load() ([]string, error)
+}
+
+// DedupConfig specifies the configuration of a Dedup.
+type DedupConfig struct {
+	// Size is the number of IDs in the window. If Size is zero, 1024 is
+	// used.
+	Size int
+
+	// Store persists the IDs in the window. If Store is nil, the IDs are
+	// kept in memory only.
+	Store DedupStore
+}
+
+// Dedup is a window of the most recently seen message IDs. It drops the
+// messages that a peer retransmits after a reconnect or a restart when the
+// sequence numbers of the Conn cannot tell them apart, for example because
+// the receiving process restarted without its LastReceived. IDs that are
+// evicted from the window are forgotten, so the window must cover the
+// messages that a peer may retransmit.
+//
+// The methods of Dedup are safe for concurrent use.
+type Dedup struct {
+	size  int
+	store DedupStore
+
+	mu    sync.Mutex
+	order *list.List // of string, most recently seen at the front
+	ids   map[string]*list.Element
+}
+
+// NewDedup returns a Dedup with the configuration and the IDs loaded from
+// the Store.
+func (cfg *DedupConfig) NewDedup() (*Dedup, error) {
+	d := &Dedup{
+		size:  cfg.Size,
+		store: cfg.Store,
+		order: list.New(),
+		ids:   make(map[string]*list.Element),
+	}
+	if d.size <= 0 {
+		d.size = defaultDedupSize
+	}
+	if d.store != nil {
+		ids, err := d.store.Load()
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if e, ok := d.ids[id]; ok {
+				d.order.MoveToFront(e)
+			} else {
+				d.ids[id] = d.order.PushFront(id)
+			}
+		}
+		// Forget the IDs beyond the window without calling the Store.
+		for d.order.Len() > d.size {
+			delete(d.ids, d.order.Remove(d.order.Back()).(string))
+		}
+	}
+	return d, nil
+}
+
+// Seen reports whether id is in the window and adds it to the window
+// otherwise. If the Store fails, Seen returns the error and id is not
+// added, so that the message is delivered when it is retransmitted.
+func (d *Dedup) Seen(id string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if e, ok := d.ids[id]; ok {
+		d.order.MoveToFront(e)
+		return true, nil
+	}
+	if d.order.Len() >= d.size {
+		oldest := d.order.Back()
+		if d.store != nil {
+			if err := d.store.Remove(oldest.Value.(string)); err != nil {
+				return false, err
+			}
+		}
+		delete(d.ids, d.order.Remove(oldest).(string))
+	}
+	if d.store != nil {
+		if err := d.store.Add(id); err != nil {
+			return false, err
+		}
+	}
+	d.ids[id] = d.order.PushFront(id)
+	return false, nil
+}
+
+// Len returns the number of IDs in the window.
+func (d *Dedup) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.order.Len()
+}
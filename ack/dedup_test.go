@@ -0,0 +1,148 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ack
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// memDedupStore is a DedupStore that records the IDs in order of addition.
+type memDedupStore struct {
+	ids []string
+	err error
+}
+
+func (s *memDedupStore) Add(id string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.ids = append(s.ids, id)
+	return nil
+}
+
+func (s *memDedupStore) Remove(id string) error {
+	if s.err != nil {
+		return s.err
+	}
+	for i, x := range s.ids {
+		if x == id {
+			s.ids = append(s.ids[:i], s.ids[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *memDedupStore) Load() ([]string, error) {
+	return append([]string(nil), s.ids...), s.err
+}
+
+func newDedup(t *testing.T, cfg *DedupConfig) *Dedup {
+	t.Helper()
+	d, err := cfg.NewDedup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestDedupSeen(t *testing.T) {
+	d := newDedup(t, &DedupConfig{Size: 3})
+	steps := []struct {
+		id   string
+		seen bool
+	}{
+		{"a", false},
+		{"b", false},
+		{"a", true},
+		{"c", false},
+		{"d", false}, // evicts b, the least recently seen
+		{"a", true},
+		{"b", false}, // evicts c
+		{"c", false},
+	}
+	for i, s := range steps {
+		seen, err := d.Seen(s.id)
+		if err != nil || seen != s.seen {
+			t.Errorf("%d: Seen(%q) = %v, %v, want %v", i, s.id, seen, err, s.seen)
+		}
+	}
+	if n := d.Len(); n != 3 {
+		t.Errorf("Len() = %d, want 3", n)
+	}
+}
+
+func TestDedupStore(t *testing.T) {
+	store := &memDedupStore{}
+	d := newDedup(t, &DedupConfig{Size: 2, Store: store})
+	for _, id := range []string{"a", "b", "c"} {
+		d.Seen(id)
+	}
+	if want := []string{"b", "c"}; !reflect.DeepEqual(store.ids, want) {
+		t.Errorf("stored IDs = %q, want %q", store.ids, want)
+	}
+
+	// A new Dedup loads the window.
+	d = newDedup(t, &DedupConfig{Size: 2, Store: store})
+	if seen, _ := d.Seen("c"); !seen {
+		t.Error("Seen(c) after load = false, want true")
+	}
+
+	// An ID is not added when the store fails.
+	store.err = errors.New("disk full")
+	if _, err := d.Seen("x"); err != store.err {
+		t.Errorf("Seen(x) error = %v, want %v", err, store.err)
+	}
+	store.err = nil
+	if seen, _ := d.Seen("x"); seen {
+		t.Error("Seen(x) after store failure = true, want false")
+	}
+
+	// A smaller window keeps the most recently seen IDs.
+	d = newDedup(t, &DedupConfig{Size: 1, Store: store})
+	if seen, _ := d.Seen("x"); !seen || d.Len() != 1 {
+		t.Errorf("Seen(x) = %v, Len() = %d, want true, 1", seen, d.Len())
+	}
+}
+
+func TestConnDedup(t *testing.T) {
+	d := newDedup(t, &DedupConfig{})
+	receiver := newConn(t, &Config{
+		Dedup: d,
+		MessageID: func(messageType int, data []byte) string {
+			id, _, _ := bytes.Cut(data, []byte(":"))
+			return string(id)
+		},
+	})
+	defer receiver.Close()
+	client, peer := websocket.Pipe()
+	defer peer.Close()
+	receiver.Attach(client)
+
+	// The peer restarted without its store and sends a delivered message
+	// again with a new sequence number.
+	for _, p := range []string{"d1 x:hello", "d2 x:hello", "d3 :no id", "d4 y:bye"} {
+		peer.WriteMessage(websocket.TextMessage, []byte(p))
+	}
+	for _, want := range []string{"x:hello", ":no id", "y:bye"} {
+		if got := readString(t, receiver); got != want {
+			t.Errorf("ReadMessage() = %q, want %q", got, want)
+		}
+	}
+	if n := receiver.LastReceived(); n != 4 {
+		t.Errorf("LastReceived() = %d, want 4", n)
+	}
+	for seq := 1; seq <= 4; seq++ {
+		_, p, err := peer.ReadMessage()
+		if err != nil || string(p) != "a"+string(rune('0'+seq)) {
+			t.Errorf("acknowledgement = %q, %v, want a%d", p, err, seq)
+		}
+	}
+}
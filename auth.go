@@ -0,0 +1,139 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AuthError is an error returned by Upgrader.Authenticate that specifies the
+// response to a rejected handshake.
+type AuthError struct {
+	// Status is the HTTP status of the response. If Status is zero,
+	// http.StatusUnauthorized is used.
+	Status int
+
+	// Header is added to the response header, for example a
+	// WWW-Authenticate challenge.
+	Header http.Header
+
+	// Body is the response body. If Body is empty, the status text is
+	// used.
+	Body string
+
+	// Err is the underlying error, if any.
+	Err error
+}
+
+func (e *AuthError) Error() string {
+	s := "websocket: authentication failed"
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s
+}
+
+func (e *AuthError) Unwrap() error { return e.Err }
+
+// authenticate runs the Authenticate hook. If the request is rejected,
+// authenticate writes the response and returns a non-nil error.
+func (u *Upgrader) authenticate(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	claims, err := u.Authenticate(r)
+	if err == nil {
+		return claims, nil
+	}
+	ae, ok := err.(*AuthError)
+	if !ok {
+		_, err := u.returnError(w, r, http.StatusUnauthorized, "auth", "websocket: authentication failed: "+err.Error())
+		return nil, err
+	}
+	status := ae.Status
+	if status == 0 {
+		status = http.StatusUnauthorized
+	}
+	countUpgradeFailure("auth")
+	for k, v := range ae.Header {
+		w.Header()[k] = append(w.Header()[k], v...)
+	}
+	body := ae.Body
+	if body == "" {
+		body = http.StatusText(status)
+	}
+	http.Error(w, body, status)
+	return nil, HandshakeError{message: ae.Error(), Status: status}
+}
+
+type claimsKey struct{}
+
+// AuthClaims returns the claims returned by Upgrader.Authenticate from the
+// context of a connection, or nil.
+func AuthClaims(ctx context.Context) interface{} {
+	return ctx.Value(claimsKey{})
+}
+
+// Context returns the context of the connection. For connections opened by
+// Upgrade, the context carries the values of the handshake request's
+// context and the claims returned by Upgrader.Authenticate, see AuthClaims.
+// The context is not canceled when the handshake request ends. For other
+// connections, Context returns context.Background().
+func (c *Conn) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// valuesContext is a context with the values of a parent context but
+// without its deadline and cancellation.
+type valuesContext struct{ parent context.Context }
+
+func (valuesContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (valuesContext) Done() <-chan struct{}               { return nil }
+func (valuesContext) Err() error                          { return nil }
+func (c valuesContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
+
+// BearerToken returns the token of an "Authorization: Bearer" header, or "".
+func BearerToken(r *http.Request) string {
+	const prefix = "bearer "
+	h := r.Header.Get("Authorization")
+	if len(h) > len(prefix) && equalASCIIFold(h[:len(prefix)], prefix) {
+		return strings.TrimSpace(h[len(prefix):])
+	}
+	return ""
+}
+
+// CookieToken returns the value of the named cookie, or "".
+func CookieToken(r *http.Request, name string) string {
+	c, err := r.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return c.Value
+}
+
+// QueryToken returns the value of the named query parameter, or "".
+func QueryToken(r *http.Request, name string) string {
+	return r.URL.Query().Get(name)
+}
+
+// SubprotocolToken returns the rest of the first subprotocol requested by
+// the client that starts with prefix, or "". Browsers cannot set headers on
+// WebSocket handshake requests, but they can send a token as a subprotocol:
+//
+//	new WebSocket(url, ["chat", "token." + token])
+//
+// Do not list the token subprotocol in Upgrader.Subprotocols; the server
+// must not select it.
+func SubprotocolToken(r *http.Request, prefix string) string {
+	for _, p := range Subprotocols(r) {
+		if strings.HasPrefix(p, prefix) {
+			return p[len(prefix):]
+		}
+	}
+	return ""
+}
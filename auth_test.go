@@ -0,0 +1,119 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ctxKey struct{}
+
+func TestUpgraderAuthenticate(t *testing.T) {
+	claims := make(chan interface{}, 1)
+	upgrader := Upgrader{
+		Authenticate: func(r *http.Request) (interface{}, error) {
+			switch token := BearerToken(r); token {
+			case "good":
+				return map[string]string{"sub": "alice"}, nil
+			case "":
+				return nil, errors.New("no token")
+			default:
+				return nil, &AuthError{
+					Status: http.StatusForbidden,
+					Header: http.Header{"Www-Authenticate": {`Bearer error="invalid_token"`}},
+					Body:   "invalid token",
+				}
+			}
+		},
+	}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(context.WithValue(r.Context(), ctxKey{}, "request value"))
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		ctx := c.Context()
+		if ctx.Value(ctxKey{}) != "request value" {
+			t.Errorf("context does not carry the request values")
+		}
+		claims <- AuthClaims(ctx)
+	}))
+	defer s.Close()
+
+	tests := []struct {
+		auth   string
+		status int
+		body   string
+		header string
+	}{
+		{"Bearer good", http.StatusSwitchingProtocols, "", ""},
+		{"", http.StatusUnauthorized, "Unauthorized\n", ""},
+		{"bearer bad", http.StatusForbidden, "invalid token\n", `Bearer error="invalid_token"`},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		if tt.auth != "" {
+			h.Set("Authorization", tt.auth)
+		}
+		c, resp, err := DefaultDialer.Dial(makeWsProto(s.URL), h)
+		if resp == nil {
+			t.Fatalf("%q: Dial() error = %v", tt.auth, err)
+		}
+		if resp.StatusCode != tt.status {
+			t.Errorf("%q: status = %d, want %d", tt.auth, resp.StatusCode, tt.status)
+		}
+		if c != nil {
+			got := <-claims
+			if m, ok := got.(map[string]string); !ok || m["sub"] != "alice" {
+				t.Errorf("%q: claims = %v", tt.auth, got)
+			}
+			c.Close()
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if string(body) != tt.body {
+			t.Errorf("%q: body = %q, want %q", tt.auth, body, tt.body)
+		}
+		if got := resp.Header.Get("Www-Authenticate"); got != tt.header {
+			t.Errorf("%q: WWW-Authenticate = %q, want %q", tt.auth, got, tt.header)
+		}
+	}
+}
+
+func TestAuthTokens(t *testing.T) {
+	r := httptest.NewRequest("GET", "http://example.com/ws?access_token=q", nil)
+	r.Header.Set("Authorization", "BEARER  h ")
+	r.Header.Set("Sec-Websocket-Protocol", "chat, token.s")
+	r.AddCookie(&http.Cookie{Name: "session", Value: "c"})
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"bearer", BearerToken(r), "h"},
+		{"cookie", CookieToken(r, "session"), "c"},
+		{"missing cookie", CookieToken(r, "other"), ""},
+		{"query", QueryToken(r, "access_token"), "q"},
+		{"subprotocol", SubprotocolToken(r, "token."), "s"},
+		{"missing subprotocol", SubprotocolToken(r, "jwt."), ""},
+		{"basic", BearerToken(&http.Request{Header: http.Header{"Authorization": {"Basic x"}}}), ""},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if got := (&Conn{}).Context(); got != context.Background() {
+		t.Errorf("Context() = %v, want context.Background()", got)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package autobahn runs the Autobahn WebSocket test suite against servers
+// and clients built with the websocket package and reports the results as Go
+// tests, with a subtest for each case of the suite.
+//
+// The suite's wstest tool runs natively or in the
+// crossbario/autobahn-testsuite Docker image. Tests are skipped when the
+// tool is not available.
+//
+//	func TestAutobahnServer(t *testing.T) {
+//		r := &autobahn.Runner{Docker: true}
+//		r.TestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//			c, err := upgrader.Upgrade(w, r, nil)
+//			if err != nil {
+//				return
+//			}
+//			defer c.Close()
+//			autobahn.Echo(c)
+//		}))
+//	}
+//
+//	func TestAutobahnClient(t *testing.T) {
+//		r := &autobahn.Runner{Docker: true}
+//		r.TestClient(t, "Client", func(url string) {
+//			c, _, err := dialer.Dial(url, nil)
+//			if err != nil {
+//				return
+//			}
+//			defer c.Close()
+//			autobahn.Echo(c)
+//		})
+//	}
+package autobahn
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Behaviors reported by the test suite.
+const (
+	BehaviorOK            = "OK"
+	BehaviorNonStrict     = "NON-STRICT"
+	BehaviorInformational = "INFORMATIONAL"
+	BehaviorUnimplemented = "UNIMPLEMENTED"
+	BehaviorFailed        = "FAILED"
+)
+
+// Result is the result of a test case.
+type Result struct {
+	// Behavior is the behavior of the agent during the case.
+	Behavior string `json:"behavior"`
+
+	// BehaviorClose is the behavior of the agent during the closing
+	// handshake.
+	BehaviorClose string `json:"behaviorClose"`
+
+	// Duration is the duration of the case in milliseconds.
+	Duration int `json:"duration"`
+
+	// RemoteCloseCode is the close code sent by the agent, if any.
+	RemoteCloseCode *int `json:"remoteCloseCode"`
+
+	// ReportFile is the name of the case's HTML report in the report
+	// directory.
+	ReportFile string `json:"reportfile"`
+}
+
+// Passed reports whether the agent passed the case. Non-strict behavior
+// fails the case if strict is true.
+func (r *Result) Passed(strict bool) bool {
+	for _, b := range []string{r.Behavior, r.BehaviorClose} {
+		switch b {
+		case BehaviorFailed:
+			return false
+		case BehaviorNonStrict:
+			if strict {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Report is a test suite report. It maps agent names to case IDs to
+// results.
+type Report map[string]map[string]*Result
+
+// ReadReport reads the index.json file of a test suite report.
+func ReadReport(name string) (Report, error) {
+	p, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var r Report
+	if err := json.Unmarshal(p, &r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Cases returns the IDs of the cases run for agent in numeric order.
+func (r Report) Cases(agent string) []string {
+	var ids []string
+	for id := range r[agent] {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return lessCaseID(ids[i], ids[j]) })
+	return ids
+}
+
+// lessCaseID compares case IDs such as "1.1.2" and "1.1.10" numerically.
+func lessCaseID(a, b string) bool {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, errx := strconv.Atoi(as[i])
+		y, erry := strconv.Atoi(bs[i])
+		switch {
+		case errx != nil || erry != nil:
+			if as[i] != bs[i] {
+				return as[i] < bs[i]
+			}
+		case x != y:
+			return x < y
+		}
+	}
+	return len(as) < len(bs)
+}
+
+// Test runs a subtest for each case run for agent. A subtest fails if the
+// agent did not pass the case.
+func (r Report) Test(t *testing.T, agent string, strict bool) {
+	t.Helper()
+	results, ok := r[agent]
+	if !ok {
+		t.Errorf("autobahn: no results for agent %q", agent)
+		return
+	}
+	for _, id := range r.Cases(agent) {
+		res := results[id]
+		t.Run(id, func(t *testing.T) {
+			if !res.Passed(strict) {
+				t.Errorf("behavior %s, close behavior %s; see %s", res.Behavior, res.BehaviorClose, res.ReportFile)
+			}
+		})
+	}
+}
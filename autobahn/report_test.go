@@ -0,0 +1,61 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package autobahn
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadReport(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "index.json")
+	index := `{"Agent": {
+		"1.1.10": {"behavior": "OK", "behaviorClose": "OK", "duration": 2, "remoteCloseCode": 1000, "reportfile": "a_case_1_1_10.json"},
+		"1.1.9": {"behavior": "NON-STRICT", "behaviorClose": "OK", "duration": 1, "remoteCloseCode": null, "reportfile": "a_case_1_1_9.json"},
+		"12.1.1": {"behavior": "OK", "behaviorClose": "OK"},
+		"2.1": {"behavior": "FAILED", "behaviorClose": "OK"}
+	}}`
+	if err := os.WriteFile(name, []byte(index), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := ReadReport(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.Cases("Agent"), []string{"1.1.9", "1.1.10", "2.1", "12.1.1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Cases() = %q, want %q", got, want)
+	}
+	res := r["Agent"]["1.1.10"]
+	if res.Duration != 2 || res.RemoteCloseCode == nil || *res.RemoteCloseCode != 1000 || res.ReportFile != "a_case_1_1_10.json" {
+		t.Errorf("result = %+v", res)
+	}
+	if r["Agent"]["1.1.9"].RemoteCloseCode != nil {
+		t.Error("RemoteCloseCode not nil for null")
+	}
+}
+
+func TestPassed(t *testing.T) {
+	tests := []struct {
+		behavior, behaviorClose string
+		strict, want            bool
+	}{
+		{BehaviorOK, BehaviorOK, true, true},
+		{BehaviorInformational, BehaviorOK, true, true},
+		{BehaviorUnimplemented, BehaviorOK, true, true},
+		{BehaviorNonStrict, BehaviorOK, false, true},
+		{BehaviorNonStrict, BehaviorOK, true, false},
+		{BehaviorOK, BehaviorNonStrict, true, false},
+		{BehaviorFailed, BehaviorOK, false, false},
+		{BehaviorOK, BehaviorFailed, false, false},
+	}
+	for _, tt := range tests {
+		r := &Result{Behavior: tt.behavior, BehaviorClose: tt.behaviorClose}
+		if got := r.Passed(tt.strict); got != tt.want {
+			t.Errorf("Passed(%v) for %s/%s = %v, want %v", tt.strict, tt.behavior, tt.behaviorClose, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,325 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package autobahn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultImage is the Docker image of the test suite.
+const DefaultImage = "crossbario/autobahn-testsuite"
+
+// Runner runs the test suite.
+type Runner struct {
+	// Docker specifies that wstest runs in a Docker container.
+	Docker bool
+
+	// Image is the Docker image. If Image is empty, DefaultImage is used.
+	Image string
+
+	// Wstest is the path of the native wstest command. If Wstest is empty,
+	// wstest is looked up in PATH.
+	Wstest string
+
+	// Cases are the IDs of the cases to run. Wildcards are allowed. If Cases
+	// is nil, all cases are run.
+	Cases []string
+
+	// ExcludeCases are the IDs of the cases to skip.
+	ExcludeCases []string
+
+	// Strict specifies that non-strict behavior fails a case.
+	Strict bool
+
+	// ReportDir is the directory for the configuration and the report. If
+	// ReportDir is empty, a temporary directory is used.
+	ReportDir string
+
+	// StartTimeout is the time to wait for the fuzzing server to start. If
+	// StartTimeout is zero, one minute is used.
+	StartTimeout time.Duration
+}
+
+// Echo echoes messages received on c until reading fails. Text messages that
+// are not valid UTF-8 are answered with a close message with code
+// CloseInvalidFramePayloadData, as the test suite expects.
+func Echo(c *websocket.Conn) {
+	for {
+		mt, p, err := c.ReadMessage()
+		if err != nil {
+			return
+		}
+		if mt == websocket.TextMessage && !utf8.Valid(p) {
+			c.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseInvalidFramePayloadData, ""),
+				time.Now().Add(time.Second))
+			return
+		}
+		if err := c.WriteMessage(mt, p); err != nil {
+			return
+		}
+	}
+}
+
+// ServerAgent is the agent name of the server in TestServer reports.
+const ServerAgent = "Server"
+
+// TestServer runs the fuzzing client of the test suite against handler and
+// runs a subtest for each case.
+func (r *Runner) TestServer(t *testing.T, handler http.Handler) {
+	t.Helper()
+	r.lookPath(t)
+	dir := r.reportDir(t)
+
+	listenAddr, host := "127.0.0.1:0", "127.0.0.1"
+	if r.Docker {
+		listenAddr, host = "0.0.0.0:0", "host.docker.internal"
+	}
+	ln, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: handler}
+	go srv.Serve(ln)
+	defer srv.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	cfg := r.config(dir)
+	cfg["options"] = map[string]interface{}{"failByDrop": false}
+	cfg["servers"] = []map[string]string{{
+		"agent": ServerAgent,
+		"url":   fmt.Sprintf("ws://%s:%d/", host, port),
+	}}
+	r.writeConfig(t, dir, "fuzzingclient.json", cfg)
+
+	cmd := r.command(dir, "fuzzingclient", containerName(), nil)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("autobahn: %v: %v\n%s", cmd.Args, err, out)
+	}
+	r.test(t, dir, ServerAgent)
+}
+
+// TestClient starts the fuzzing server of the test suite and calls client
+// for each case with the URL of the case. The client connects to the URL,
+// echoes the messages that it receives and returns when the connection is
+// closed. TestClient runs a subtest for each case.
+func (r *Runner) TestClient(t *testing.T, agent string, client func(url string)) {
+	t.Helper()
+	r.lookPath(t)
+	dir := r.reportDir(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	cfg := r.config(dir)
+	var ports []string
+	if r.Docker {
+		cfg["url"] = "ws://0.0.0.0:9001"
+		ports = []string{"-p", fmt.Sprintf("127.0.0.1:%d:9001", port)}
+	} else {
+		cfg["url"] = fmt.Sprintf("ws://127.0.0.1:%d", port)
+	}
+	r.writeConfig(t, dir, "fuzzingserver.json", cfg)
+
+	name := containerName()
+	cmd := r.command(dir, "fuzzingserver", name, ports)
+	out := &syncBuffer{}
+	cmd.Stdout, cmd.Stderr = out, out
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("autobahn: %v: %v", cmd.Args, err)
+	}
+	defer r.stop(cmd, name)
+
+	base := fmt.Sprintf("ws://127.0.0.1:%d", port)
+	n, err := r.caseCount(base)
+	if err != nil {
+		t.Fatalf("autobahn: fuzzing server: %v\n%s", err, out.String())
+	}
+	for i := 1; i <= n; i++ {
+		client(fmt.Sprintf("%s/runCase?case=%d&agent=%s", base, i, agent))
+	}
+	c, _, err := websocket.DefaultDialer.Dial(base+"/updateReports?agent="+agent, nil)
+	if err != nil {
+		t.Fatalf("autobahn: update reports: %v", err)
+	}
+	for {
+		if _, _, err := c.ReadMessage(); err != nil {
+			break
+		}
+	}
+	c.Close()
+	r.test(t, dir, agent)
+}
+
+// caseCount waits for the fuzzing server to start and returns the number of
+// cases.
+func (r *Runner) caseCount(base string) (int, error) {
+	timeout := r.StartTimeout
+	if timeout == 0 {
+		timeout = time.Minute
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		c, _, err := websocket.DefaultDialer.Dial(base+"/getCaseCount", nil)
+		if err != nil {
+			if time.Now().After(deadline) {
+				return 0, err
+			}
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		defer c.Close()
+		_, p, err := c.ReadMessage()
+		if err != nil {
+			return 0, err
+		}
+		return strconv.Atoi(string(p))
+	}
+}
+
+func (r *Runner) lookPath(t *testing.T) {
+	t.Helper()
+	name := r.Wstest
+	if r.Docker {
+		name = "docker"
+	} else if name == "" {
+		name = "wstest"
+	}
+	if _, err := exec.LookPath(name); err != nil {
+		t.Skipf("autobahn: %s not available: %v", name, err)
+	}
+}
+
+func (r *Runner) reportDir(t *testing.T) string {
+	t.Helper()
+	if r.ReportDir == "" {
+		return t.TempDir()
+	}
+	dir, err := filepath.Abs(r.ReportDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// config returns the configuration common to both modes.
+func (r *Runner) config(dir string) map[string]interface{} {
+	cases := r.Cases
+	if cases == nil {
+		cases = []string{"*"}
+	}
+	exclude := r.ExcludeCases
+	if exclude == nil {
+		exclude = []string{}
+	}
+	outdir := filepath.Join(dir, "reports")
+	if r.Docker {
+		outdir = "/work/reports"
+	}
+	return map[string]interface{}{
+		"cases":               cases,
+		"exclude-cases":       exclude,
+		"exclude-agent-cases": map[string]interface{}{},
+		"outdir":              outdir,
+	}
+}
+
+func (r *Runner) writeConfig(t *testing.T, dir, name string, cfg map[string]interface{}) {
+	t.Helper()
+	p, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), p, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func containerName() string {
+	return fmt.Sprintf("autobahn-%d-%d", os.Getpid(), time.Now().UnixNano())
+}
+
+// command returns the wstest command for mode. In Docker, the container has
+// the given name and dir is mounted at /work.
+func (r *Runner) command(dir, mode, name string, dockerArgs []string) *exec.Cmd {
+	spec := filepath.Join(dir, mode+".json")
+	if !r.Docker {
+		wstest := r.Wstest
+		if wstest == "" {
+			wstest = "wstest"
+		}
+		return exec.Command(wstest, "-m", mode, "-s", spec)
+	}
+	image := r.Image
+	if image == "" {
+		image = DefaultImage
+	}
+	args := []string{"run", "--rm", "--name", name,
+		"--add-host=host.docker.internal:host-gateway",
+		"-v", dir + ":/work"}
+	args = append(args, dockerArgs...)
+	args = append(args, image, "wstest", "-m", mode, "-s", "/work/"+mode+".json")
+	return exec.Command("docker", args...)
+}
+
+// stop stops a running wstest command.
+func (r *Runner) stop(cmd *exec.Cmd, name string) {
+	if r.Docker {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		exec.CommandContext(ctx, "docker", "rm", "-f", name).Run()
+	}
+	cmd.Process.Kill()
+	cmd.Wait()
+}
+
+func (r *Runner) test(t *testing.T, dir, agent string) {
+	t.Helper()
+	report, err := ReadReport(filepath.Join(dir, "reports", "index.json"))
+	if err != nil {
+		t.Fatalf("autobahn: %v", err)
+	}
+	report.Test(t, agent, r.Strict)
+}
+
+// syncBuffer collects the output of a running command.
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.String()
+}
@@ -0,0 +1,208 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package autobahn
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// The test binary acts as a fake wstest when this variable is set in the
+// environment.
+const fakeWstestEnv = "AUTOBAHN_FAKE_WSTEST"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeWstestEnv) == "1" {
+		fakeWstest()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// fakeCases are the cases run by the fake wstest. Each case sends a text
+// message and checks the response.
+var fakeCases = []struct {
+	id   string
+	send string
+	// close is the expected close code, or zero if the message is echoed.
+	close int
+}{
+	{"1.1.1", "hello", 0},
+	{"6.3.1", "\xce\xba\xe1\xbd\xb9\xcf\x83\xce\xbc\xce\xb5\xed\xa0\x80", websocket.CloseInvalidFramePayloadData},
+}
+
+func runFakeCase(c *websocket.Conn, i int) *Result {
+	fc := fakeCases[i]
+	res := &Result{Behavior: BehaviorFailed, BehaviorClose: BehaviorOK, ReportFile: "case_" + fc.id + ".json"}
+	c.WriteMessage(websocket.TextMessage, []byte(fc.send))
+	_, p, err := c.ReadMessage()
+	if fc.close == 0 && err == nil && string(p) == fc.send || websocket.IsCloseError(err, fc.close) {
+		res.Behavior = BehaviorOK
+	}
+	c.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	return res
+}
+
+func writeFakeReport(outdir string, report Report) {
+	os.MkdirAll(outdir, 0o755)
+	p, _ := json.Marshal(report)
+	if err := os.WriteFile(filepath.Join(outdir, "index.json"), p, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func fakeWstest() {
+	mode := flag.String("m", "", "mode")
+	spec := flag.String("s", "", "spec")
+	flag.Parse()
+	p, err := os.ReadFile(*spec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var cfg struct {
+		URL     string `json:"url"`
+		Outdir  string `json:"outdir"`
+		Servers []struct {
+			Agent string `json:"agent"`
+			URL   string `json:"url"`
+		} `json:"servers"`
+	}
+	if err := json.Unmarshal(p, &cfg); err != nil {
+		log.Fatal(err)
+	}
+
+	switch *mode {
+	case "fuzzingclient":
+		report := Report{}
+		for _, s := range cfg.Servers {
+			report[s.Agent] = map[string]*Result{}
+			for i, fc := range fakeCases {
+				c, _, err := websocket.DefaultDialer.Dial(s.URL, nil)
+				if err != nil {
+					log.Fatal(err)
+				}
+				report[s.Agent][fc.id] = runFakeCase(c, i)
+				c.Close()
+			}
+		}
+		writeFakeReport(cfg.Outdir, report)
+	case "fuzzingserver":
+		u, err := url.Parse(cfg.URL)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var mu sync.Mutex
+		report := Report{}
+		upgrader := websocket.Upgrader{}
+		http.HandleFunc("/getCaseCount", func(w http.ResponseWriter, r *http.Request) {
+			c, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer c.Close()
+			c.WriteMessage(websocket.TextMessage, []byte(fmt.Sprint(len(fakeCases))))
+		})
+		http.HandleFunc("/runCase", func(w http.ResponseWriter, r *http.Request) {
+			var i int
+			fmt.Sscan(r.FormValue("case"), &i)
+			agent := r.FormValue("agent")
+			c, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer c.Close()
+			res := runFakeCase(c, i-1)
+			mu.Lock()
+			if report[agent] == nil {
+				report[agent] = map[string]*Result{}
+			}
+			report[agent][fakeCases[i-1].id] = res
+			mu.Unlock()
+		})
+		http.HandleFunc("/updateReports", func(w http.ResponseWriter, r *http.Request) {
+			c, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer c.Close()
+			mu.Lock()
+			writeFakeReport(cfg.Outdir, report)
+			mu.Unlock()
+		})
+		log.Fatal(http.ListenAndServe(u.Host, nil))
+	default:
+		log.Fatalf("unknown mode %q", *mode)
+	}
+}
+
+func fakeRunner(t *testing.T) *Runner {
+	t.Setenv(fakeWstestEnv, "1")
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Runner{Wstest: exe}
+}
+
+func TestServer(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	fakeRunner(t).TestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		c, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		Echo(c)
+	}))
+	mu.Lock()
+	defer mu.Unlock()
+	if len(paths) != len(fakeCases) {
+		t.Errorf("server handled %d connections, want %d", len(paths), len(fakeCases))
+	}
+}
+
+func TestClient(t *testing.T) {
+	var urls []string
+	fakeRunner(t).TestClient(t, "Client", func(u string) {
+		urls = append(urls, u)
+		c, _, err := websocket.DefaultDialer.Dial(u, nil)
+		if err != nil {
+			t.Errorf("Dial(%s) error = %v", u, err)
+			return
+		}
+		defer c.Close()
+		Echo(c)
+	})
+	if len(urls) != len(fakeCases) || !strings.HasSuffix(urls[0], "/runCase?case=1&agent=Client") {
+		t.Errorf("case URLs = %q", urls)
+	}
+}
+
+func TestSkip(t *testing.T) {
+	r := &Runner{Wstest: filepath.Join(t.TempDir(), "wstest")}
+	var skipped bool
+	t.Run("skip", func(t *testing.T) {
+		defer func() { skipped = t.Skipped() }()
+		r.TestServer(t, http.NotFoundHandler())
+	})
+	if !skipped {
+		t.Error("TestServer did not skip without wstest")
+	}
+}
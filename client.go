@@ -35,16 +35,89 @@ var errInvalidCompression = errors.New("websocket: invalid compression negotiati
 // non-nil *http.Response so that callers can handle redirects, authentication,
 // etc.
 //
-// Deprecated: Use Dialer instead.
+// Deprecated: Use NewClientConn or Dialer instead.
 func NewClient(netConn net.Conn, u *url.URL, requestHeader http.Header, readBufSize, writeBufSize int) (c *Conn, response *http.Response, err error) {
-	d := Dialer{
-		ReadBufferSize:  readBufSize,
-		WriteBufferSize: writeBufSize,
-		NetDial: func(net, addr string) (net.Conn, error) {
-			return netConn, nil
-		},
-	}
-	return d.Dial(u.String(), requestHeader)
+	return NewClientConn(netConn, u, ClientConfig{
+		Dialer: &Dialer{ReadBufferSize: readBufSize, WriteBufferSize: writeBufSize},
+		Header: requestHeader,
+	})
+}
+
+// ClientConfig configures a client connection created by NewClientConn.
+type ClientConfig struct {
+	// Dialer specifies the options of the handshake and of the connection.
+	// The fields that create the network connection, NetDial,
+	// NetDialContext, NetDialTLSContext, Proxy, ProxyPool, TLSClientConfig
+	// and SocketOptions, are not used. If Dialer is nil, DefaultDialer is
+	// used.
+	Dialer *Dialer
+
+	// Header is the request header of the handshake as described for
+	// Dialer.DialContext.
+	Header http.Header
+
+	// Context is used for the handshake. If Context is nil, the background
+	// context is used.
+	Context context.Context
+
+	// SkipHandshake creates the connection without the HTTP handshake, for
+	// transports where the peers agree by other means that the connection
+	// carries WebSocket frames. The connection uses Subprotocol and
+	// Compression as the negotiated subprotocol and compression, and
+	// NewClientConn returns a nil response.
+	SkipHandshake bool
+
+	// Subprotocol is the subprotocol of the connection when SkipHandshake
+	// is set.
+	Subprotocol string
+
+	// Compression enables per message compression without context takeover
+	// when SkipHandshake is set.
+	Compression bool
+}
+
+// NewClientConn creates a new client connection over an established
+// transport such as a QUIC stream, an SSH channel or a TLS connection
+// negotiated by the application. NewClientConn performs the handshake over
+// netConn as described for Dialer.DialContext. The URL u specifies the host
+// and request URI; the transport is not dialed and no TLS handshake is
+// done for the wss scheme. If the handshake fails, netConn is closed.
+func NewClientConn(netConn net.Conn, u *url.URL, cfg ClientConfig) (*Conn, *http.Response, error) {
+	d := nilDialer
+	if cfg.Dialer != nil {
+		d = *cfg.Dialer
+	}
+	if cfg.SkipHandshake {
+		version, err := d.version()
+		if err != nil {
+			return nil, nil, err
+		}
+		conn := newConn(netConn, false, d.ReadBufferSize, d.WriteBufferSize, d.WriteBufferPool, nil, nil)
+		conn.clock = clockOrSystem(d.Clock)
+		conn.subprotocol = cfg.Subprotocol
+		if cfg.Compression {
+			conn.newCompressionWriter = compressNoContextTakeover
+			conn.newDecompressionReader = decompressNoContextTakeover
+		}
+		d.setOptions(conn, version)
+		countDial(conn)
+		return conn, nil, nil
+	}
+
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return netConn, nil
+	}
+	d.NetDial = nil
+	d.NetDialContext = dial
+	d.NetDialTLSContext = dial
+	d.Proxy = nil
+	d.ProxyPool = nil
+	d.SocketOptions = nil
+	ctx := cfg.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return d.dialContext(ctx, u.String(), cfg.Header)
 }
 
 // A Dialer contains options for connecting to WebSocket server.
@@ -75,11 +148,31 @@ type Dialer struct {
 	// If nil, the default configuration is used.
 	// If either NetDialTLS or NetDialTLSContext are set, Dial assumes the TLS handshake
 	// is done there and TLSClientConfig is ignored.
+	//
+	// Set the ClientSessionCache field of the configuration to resume TLS
+	// sessions when reconnecting, which saves the server the cost of a full
+	// handshake. The crypto/tls package does not send TLS 1.3 early data
+	// (0-RTT), so the upgrade request is always sent after the handshake
+	// completes and is never replayable.
 	TLSClientConfig *tls.Config
 
 	// HandshakeTimeout specifies the duration for the handshake to complete.
 	HandshakeTimeout time.Duration
 
+	// ConnectTimeout, TLSHandshakeTimeout and UpgradeTimeout limit the
+	// phases of the handshake within HandshakeTimeout, so that the
+	// application can tell network problems from a slow server. The
+	// connect phase dials the connection, including the connection to a
+	// proxy and the TLS handshake done by NetDialTLSContext. The TLS
+	// handshake phase is the handshake done with TLSClientConfig. The
+	// upgrade phase writes the request and reads the response. A phase
+	// that exceeds its timeout fails with ErrConnectTimeout,
+	// ErrTLSHandshakeTimeout or ErrUpgradeTimeout. If a timeout is zero,
+	// the phase is limited by HandshakeTimeout only.
+	ConnectTimeout      time.Duration
+	TLSHandshakeTimeout time.Duration
+	UpgradeTimeout      time.Duration
+
 	// ReadBufferSize and WriteBufferSize specify I/O buffer sizes in bytes. If a buffer
 	// size is zero, then a useful default size is used. The I/O buffer sizes
 	// do not limit the size of the messages that can be sent or received.
@@ -109,6 +202,92 @@ type Dialer struct {
 	// If Jar is nil, cookies are not sent in requests and ignored
 	// in responses.
 	Jar http.CookieJar
+
+	// Clock specifies the clock for the handshake timeout and for the
+	// connection's deadlines. If Clock is nil, the system clock is used. If
+	// Clock is set, the handshake timeout is applied as a deadline on the
+	// network connection only.
+	Clock Clock
+
+	// Observer observes the lifecycle of the connections opened by the
+	// dialer.
+	Observer Observer
+
+	// ReadRateLimit limits the rate of messages and bytes read from the
+	// peer on the connections opened by the dialer. See
+	// Conn.SetReadRateLimit.
+	ReadRateLimit *RateLimit
+
+	// WriteThrottle limits the rate of bytes written to the connections
+	// opened by the dialer. The throttle is shared by the connections, so it
+	// limits their total rate. See Conn.SetWriteThrottle.
+	WriteThrottle *Throttle
+
+	// OnProtocolViolation is called when a connection opened by the dialer
+	// fails because the peer violated the protocol. See
+	// Conn.SetProtocolViolationHandler.
+	OnProtocolViolation func(v ProtocolViolation)
+
+	// Strictness selects how strictly the connections opened by the dialer
+	// check the frames read from the peer. See Conn.SetStrictness.
+	Strictness Strictness
+
+	// Quirks enables workarounds for bugs of the peers of the connections
+	// opened by the dialer. See Conn.SetQuirks.
+	Quirks Quirk
+
+	// Version is the value of the Sec-WebSocket-Version header. Versions
+	// other than Version13 must be registered with RegisterVersion. If
+	// Version is empty, Version13 is used.
+	Version string
+
+	// MaskKeySource is the source of the masking keys of the frames
+	// written by the connections. If MaskKeySource is nil,
+	// crypto/rand.Reader is used. See Conn.SetMaskKeySource.
+	MaskKeySource io.Reader
+
+	// ProxyPool keeps connections to HTTP proxies ready for the dialer. If
+	// ProxyPool is nil, every connection through a proxy dials the proxy.
+	ProxyPool *ProxyPool
+
+	// DefaultWriteTimeout is the time allowed to write a message on the
+	// connections opened by the dialer when the application does not set a
+	// write deadline. See Conn.SetDefaultWriteTimeout.
+	DefaultWriteTimeout time.Duration
+
+	// ReadActivityTimeout extends the read deadline of the connections
+	// opened by the dialer when frames arrive. See
+	// Conn.SetReadActivityTimeout.
+	ReadActivityTimeout time.Duration
+
+	// CompressionPolicy selects the messages compressed by the connections
+	// opened by the dialer when compression is negotiated. See
+	// Conn.SetCompressionPolicy.
+	CompressionPolicy CompressionPolicy
+
+	// CompressionLevelPolicy selects the compression level of the messages
+	// compressed by the connections opened by the dialer. See
+	// Conn.SetCompressionLevelPolicy.
+	CompressionLevelPolicy CompressionLevelPolicy
+
+	// FlowControlWindow offers the x-flow-control extension on the
+	// connections opened by the dialer. The window is the number of bytes
+	// the server may send before the application consumes them. If
+	// FlowControlWindow is zero, the extension is not offered. See
+	// Conn.FlowControlNegotiated.
+	FlowControlWindow int
+
+	// Spool configures the spooling of large messages to temporary files on
+	// the connections opened by the dialer. See Conn.SetSpool.
+	Spool *SpoolConfig
+
+	// Labels are the labels of the connections opened by the dialer. See
+	// Conn.SetLabel.
+	Labels map[string]string
+
+	// SocketOptions are the options of the TCP connections opened by the
+	// dialer. If SocketOptions is nil, the connections are not changed.
+	SocketOptions *SocketOptions
 }
 
 // Dial creates a new client connection by calling DialContext with a background context.
@@ -118,6 +297,8 @@ func (d *Dialer) Dial(urlStr string, requestHeader http.Header) (*Conn, *http.Re
 
 var errMalformedURL = errors.New("malformed ws or wss URL")
 
+// hostPortNoPort returns the address to dial and the host name for TLS. The
+// brackets and the zone of an IPv6 literal are removed from hostNoPort.
 func hostPortNoPort(u *url.URL) (hostPort, hostNoPort string) {
 	hostPort = u.Host
 	hostNoPort = u.Host
@@ -133,6 +314,12 @@ func hostPortNoPort(u *url.URL) (hostPort, hostNoPort string) {
 			hostPort += ":80"
 		}
 	}
+	if strings.HasPrefix(hostNoPort, "[") && strings.HasSuffix(hostNoPort, "]") {
+		hostNoPort = hostNoPort[1 : len(hostNoPort)-1]
+		if i := strings.LastIndex(hostNoPort, "%"); i >= 0 {
+			hostNoPort = hostNoPort[:i]
+		}
+	}
 	return hostPort, hostNoPort
 }
 
@@ -145,6 +332,9 @@ var DefaultDialer = &Dialer{
 // nilDialer is dialer to use when receiver is nil.
 var nilDialer = *DefaultDialer
 
+// dialBrowser dials with the browser's WebSocket API. It is set on js/wasm.
+var dialBrowser func(ctx context.Context, d *Dialer, urlStr string, requestHeader http.Header) (*Conn, *http.Response, error)
+
 // DialContext creates a new client connection. Use requestHeader to specify the
 // origin (Origin), subprotocols (Sec-WebSocket-Protocol) and cookies (Cookie).
 // Use the response.Header to get the selected subprotocol
@@ -156,11 +346,59 @@ var nilDialer = *DefaultDialer
 // non-nil *http.Response so that callers can handle redirects, authentication,
 // etcetera. The response body may not contain the entire response and does not
 // need to be closed by the application.
+//
+// On js/wasm, the connection is opened with the browser's WebSocket API. The
+// browser performs the handshake, so requestHeader and the proxy, TLS and
+// compression options are not used, and ping and pong messages are not sent.
 func (d *Dialer) DialContext(ctx context.Context, urlStr string, requestHeader http.Header) (*Conn, *http.Response, error) {
 	if d == nil {
 		d = &nilDialer
 	}
 
+	if dialBrowser != nil {
+		return dialBrowser(ctx, d, urlStr, requestHeader)
+	}
+	return d.dialContext(ctx, urlStr, requestHeader)
+}
+
+// dialContext creates a client connection for DialContext and
+// NewClientConn.
+func (d *Dialer) dialContext(ctx context.Context, urlStr string, requestHeader http.Header) (*Conn, *http.Response, error) {
+	trace := ContextConnTrace(ctx)
+	if trace == nil {
+		conn, resp, err := d.dial(ctx, urlStr, requestHeader, nil)
+		countDial(conn)
+		return conn, resp, err
+	}
+	started := false
+	conn, resp, err := d.dial(ctx, urlStr, requestHeader, func(req *http.Request) {
+		started = true
+		if trace.HandshakeStart != nil {
+			trace.HandshakeStart(req)
+		}
+	})
+	countDial(conn)
+	if conn != nil {
+		conn.trace = trace
+	}
+	if started && trace.HandshakeDone != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		trace.HandshakeDone(conn, status, err)
+	}
+	return conn, resp, err
+}
+
+// dial performs the handshake for DialContext. If onStart is not nil, dial
+// calls it with the request before sending the request.
+func (d *Dialer) dial(ctx context.Context, urlStr string, requestHeader http.Header, onStart func(*http.Request)) (*Conn, *http.Response, error) {
+	version, err := d.version()
+	if err != nil {
+		return nil, nil, err
+	}
+
 	challengeKey, err := generateChallengeKey()
 	if err != nil {
 		return nil, nil, err
@@ -185,6 +423,11 @@ func (d *Dialer) DialContext(ctx context.Context, urlStr string, requestHeader h
 		return nil, nil, errMalformedURL
 	}
 
+	// Dial and verify international domain names in the ASCII form.
+	if u.Host, err = asciiHostPort(u.Host); err != nil {
+		return nil, nil, err
+	}
+
 	req := &http.Request{
 		Method:     http.MethodGet,
 		URL:        u,
@@ -210,7 +453,7 @@ func (d *Dialer) DialContext(ctx context.Context, urlStr string, requestHeader h
 	req.Header["Upgrade"] = []string{"websocket"}
 	req.Header["Connection"] = []string{"Upgrade"}
 	req.Header["Sec-WebSocket-Key"] = []string{challengeKey}
-	req.Header["Sec-WebSocket-Version"] = []string{"13"}
+	req.Header["Sec-WebSocket-Version"] = []string{version.Name}
 	if len(d.Subprotocols) > 0 {
 		req.Header["Sec-WebSocket-Protocol"] = []string{strings.Join(d.Subprotocols, ", ")}
 	}
@@ -234,11 +477,22 @@ func (d *Dialer) DialContext(ctx context.Context, urlStr string, requestHeader h
 		}
 	}
 
+	var extensions []string
 	if d.EnableCompression {
-		req.Header["Sec-WebSocket-Extensions"] = []string{"permessage-deflate; server_no_context_takeover; client_no_context_takeover"}
+		extensions = append(extensions, "permessage-deflate; server_no_context_takeover; client_no_context_takeover")
+	}
+	if d.FlowControlWindow > 0 {
+		extensions = append(extensions, flowControlOffer(flowControlWindow(d.FlowControlWindow)))
+	}
+	if len(extensions) > 0 {
+		req.Header["Sec-WebSocket-Extensions"] = []string{strings.Join(extensions, ", ")}
+	}
+
+	if onStart != nil {
+		onStart(req)
 	}
 
-	if d.HandshakeTimeout != 0 {
+	if d.HandshakeTimeout != 0 && d.Clock == nil {
 		var cancel func()
 		ctx, cancel = context.WithTimeout(ctx, d.HandshakeTimeout)
 		defer cancel()
@@ -259,7 +513,11 @@ func (d *Dialer) DialContext(ctx context.Context, urlStr string, requestHeader h
 	}
 
 	// If needed, wrap the dial function to set the connection deadline.
-	if deadline, ok := ctx.Deadline(); ok {
+	deadline, ok := ctx.Deadline()
+	if d.HandshakeTimeout != 0 && d.Clock != nil {
+		deadline, ok = d.Clock.Now().Add(d.HandshakeTimeout), true
+	}
+	if ok {
 		forwardDial := netDial
 		netDial = func(ctx context.Context, network, addr string) (net.Conn, error) {
 			c, err := forwardDial(ctx, network, addr)
@@ -282,7 +540,8 @@ func (d *Dialer) DialContext(ctx context.Context, urlStr string, requestHeader h
 			return nil, nil, err
 		}
 		if proxyURL != nil {
-			netDial, err = proxyFromURL(proxyURL, netDial)
+			pipeline := d.ProxyPool != nil && d.ProxyPool.Pipeline && u.Scheme == "https" && d.NetDialTLSContext == nil
+			netDial, err = proxyFromURL(proxyURL, netDial, d.ProxyPool, pipeline)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -295,8 +554,17 @@ func (d *Dialer) DialContext(ctx context.Context, urlStr string, requestHeader h
 		trace.GetConn(hostPort)
 	}
 
-	netConn, err := netDial(ctx, "tcp", hostPort)
+	dialCtx := ctx
+	if d.ConnectTimeout > 0 {
+		var cancel func()
+		dialCtx, cancel = context.WithTimeout(ctx, d.ConnectTimeout)
+		defer cancel()
+	}
+	netConn, err := netDial(dialCtx, "tcp", hostPort)
 	if err != nil {
+		if dialCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			err = ErrConnectTimeout
+		}
 		return nil, nil, err
 	}
 	if trace != nil && trace.GotConn != nil {
@@ -317,6 +585,10 @@ func (d *Dialer) DialContext(ctx context.Context, urlStr string, requestHeader h
 		}
 	}()
 
+	if err := d.SocketOptions.apply(netConn); err != nil {
+		return nil, nil, err
+	}
+
 	if u.Scheme == "https" && d.NetDialTLSContext == nil {
 		// If NetDialTLSContext is set, assume that the TLS handshake has already been done
 
@@ -330,21 +602,39 @@ func (d *Dialer) DialContext(ctx context.Context, urlStr string, requestHeader h
 		if trace != nil && trace.TLSHandshakeStart != nil {
 			trace.TLSHandshakeStart()
 		}
-		err := doHandshake(ctx, tlsConn, cfg)
+		phase, err := d.beginPhase(netConn, d.TLSHandshakeTimeout, deadline, ok)
+		if err == nil {
+			err = doHandshake(ctx, tlsConn, cfg)
+		}
 		if trace != nil && trace.TLSHandshakeDone != nil {
 			trace.TLSHandshakeDone(tlsConn.ConnectionState(), err)
 		}
+		if err == nil {
+			err = d.endPhase(netConn, phase, deadline, ok)
+		}
 
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, phaseError(phase, err, ErrTLSHandshakeTimeout)
 		}
 	}
 
 	conn := newConn(netConn, false, d.ReadBufferSize, d.WriteBufferSize, d.WriteBufferPool, nil, nil)
+	conn.clock = clockOrSystem(d.Clock)
+	// Remove the buffers of the connection from the memory total when
+	// returning an error.
+	defer func() {
+		if netConn != nil {
+			conn.releaseMemory()
+		}
+	}()
 
-	if err := req.Write(netConn); err != nil {
+	phase, err := d.beginPhase(netConn, d.UpgradeTimeout, deadline, ok)
+	if err != nil {
 		return nil, nil, err
 	}
+	if err := req.Write(netConn); err != nil {
+		return nil, nil, phaseError(phase, err, ErrUpgradeTimeout)
+	}
 
 	if trace != nil && trace.GotFirstResponseByte != nil {
 		if peek, err := conn.br.Peek(1); err == nil && len(peek) == 1 {
@@ -354,6 +644,9 @@ func (d *Dialer) DialContext(ctx context.Context, urlStr string, requestHeader h
 
 	resp, err := http.ReadResponse(conn.br, req)
 	if err != nil {
+		if perr := phaseError(phase, err, ErrUpgradeTimeout); perr != err {
+			return nil, nil, perr
+		}
 		if d.TLSClientConfig != nil {
 			for _, proto := range d.TLSClientConfig.NextProtos {
 				if proto != "http/1.1" {
@@ -388,7 +681,15 @@ func (d *Dialer) DialContext(ctx context.Context, urlStr string, requestHeader h
 	}
 
 	for _, ext := range parseExtensions(resp.Header) {
-		if ext[""] != "permessage-deflate" {
+		if ext[""] == flowControlExtension {
+			window, ok := parseFlowControlWindow(ext)
+			if d.FlowControlWindow <= 0 || !ok {
+				return nil, resp, errInvalidFlowControl
+			}
+			conn.flow = newFlowControl(flowControlWindow(d.FlowControlWindow), window)
+			continue
+		}
+		if ext[""] != "permessage-deflate" || conn.newCompressionWriter != nil {
 			continue
 		}
 		_, snct := ext["server_no_context_takeover"]
@@ -396,9 +697,15 @@ func (d *Dialer) DialContext(ctx context.Context, urlStr string, requestHeader h
 		if !snct || !cnct {
 			return nil, resp, errInvalidCompression
 		}
+		if bits, ok := ext["client_max_window_bits"]; ok {
+			n, err := parseMaxWindowBits(bits)
+			if err != nil {
+				return nil, resp, err
+			}
+			conn.compressParams.maxWindowBits = n
+		}
 		conn.newCompressionWriter = compressNoContextTakeover
 		conn.newDecompressionReader = decompressNoContextTakeover
-		break
 	}
 
 	resp.Body = io.NopCloser(bytes.NewReader([]byte{}))
@@ -412,9 +719,64 @@ func (d *Dialer) DialContext(ctx context.Context, urlStr string, requestHeader h
 	// closing the network connection.
 	netConn = nil
 
+	d.setOptions(conn, version)
 	return conn, resp, nil
 }
 
+// beginPhase sets the deadline of a handshake phase with the timeout on
+// netConn. The phase is true if the deadline of the phase is before the
+// deadline of the handshake.
+func (d *Dialer) beginPhase(netConn net.Conn, timeout time.Duration, deadline time.Time, hasDeadline bool) (phase bool, err error) {
+	if timeout <= 0 {
+		return false, nil
+	}
+	phaseDeadline := clockOrSystem(d.Clock).Now().Add(timeout)
+	if hasDeadline && !phaseDeadline.Before(deadline) {
+		return false, nil
+	}
+	return true, netConn.SetDeadline(phaseDeadline)
+}
+
+// endPhase restores the deadline of the handshake after a phase.
+func (d *Dialer) endPhase(netConn net.Conn, phase bool, deadline time.Time, hasDeadline bool) error {
+	if !phase {
+		return nil
+	}
+	if !hasDeadline {
+		deadline = time.Time{}
+	}
+	return netConn.SetDeadline(deadline)
+}
+
+// phaseError returns timeoutErr if err is a timeout of a phase.
+func phaseError(phase bool, err error, timeoutErr error) error {
+	var ne net.Error
+	if phase && errors.As(err, &ne) && ne.Timeout() {
+		return timeoutErr
+	}
+	return err
+}
+
+// setOptions sets the options of the dialer on a new connection.
+func (d *Dialer) setOptions(conn *Conn, version ProtocolVersion) {
+	conn.SetReadRateLimit(d.ReadRateLimit)
+	conn.SetWriteThrottle(d.WriteThrottle)
+	conn.SetProtocolViolationHandler(d.OnProtocolViolation)
+	conn.SetStrictness(d.Strictness)
+	conn.SetQuirks(d.Quirks)
+	conn.SetMaskKeySource(d.MaskKeySource)
+	conn.SetDefaultWriteTimeout(d.DefaultWriteTimeout)
+	conn.SetReadActivityTimeout(d.ReadActivityTimeout)
+	conn.SetCompressionPolicy(d.CompressionPolicy)
+	conn.SetCompressionLevelPolicy(d.CompressionLevelPolicy)
+	conn.SetSpool(d.Spool)
+	conn.setLabels(d.Labels)
+	// The version is applied last so that its adjustments are not
+	// overwritten by the options of the dialer.
+	conn.useVersion(version)
+	conn.observeOpen(d.Observer)
+}
+
 func cloneTLSConfig(cfg *tls.Config) *tls.Config {
 	if cfg == nil {
 		return &tls.Config{}
@@ -0,0 +1,360 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build js && wasm
+
+package websocket
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"syscall/js"
+	"time"
+)
+
+func init() {
+	dialBrowser = dialJS
+}
+
+// dialJS dials with the browser's WebSocket API. The browser performs the
+// opening handshake and compression, so the request header, proxy, TLS and
+// compression options of the Dialer are not used. Subprotocols are
+// negotiated as usual.
+//
+// The returned Conn is backed by a browserConn that converts between browser
+// messages and websocket frames. Ping and pong messages written by the
+// application are discarded because browsers do not expose them.
+func dialJS(ctx context.Context, d *Dialer, urlStr string, requestHeader http.Header) (*Conn, *http.Response, error) {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if u.Scheme != "ws" && u.Scheme != "wss" {
+		return nil, nil, errMalformedURL
+	}
+	if u.User != nil {
+		return nil, nil, errMalformedURL
+	}
+
+	if d.HandshakeTimeout != 0 {
+		var cancel func()
+		ctx, cancel = context.WithTimeout(ctx, d.HandshakeTimeout)
+		defer cancel()
+	}
+
+	protocols := make([]interface{}, len(d.Subprotocols))
+	for i, p := range d.Subprotocols {
+		protocols[i] = p
+	}
+	ws := js.Global().Get("WebSocket").New(u.String(), protocols)
+	ws.Set("binaryType", "arraybuffer")
+
+	bc := newBrowserConn(ws, u)
+	select {
+	case <-bc.opened:
+	case <-bc.readReady:
+		// The connection closed before it opened.
+		bc.Close()
+		return nil, nil, ErrBadHandshake
+	case <-ctx.Done():
+		bc.Close()
+		return nil, nil, ctx.Err()
+	}
+
+	conn := newConn(bc, false, d.ReadBufferSize, d.WriteBufferSize, d.WriteBufferPool, nil, nil)
+	conn.subprotocol = ws.Get("protocol").String()
+	resp := &http.Response{
+		Status:     "101 Switching Protocols",
+		StatusCode: http.StatusSwitchingProtocols,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+	}
+	if conn.subprotocol != "" {
+		resp.Header.Set("Sec-Websocket-Protocol", conn.subprotocol)
+	}
+	return conn, resp, nil
+}
+
+type browserAddr string
+
+func (a browserAddr) Network() string { return "websocket" }
+func (a browserAddr) String() string  { return string(a) }
+
+// browserConn is a net.Conn over a browser WebSocket object. Read returns
+// server frames built from received browser messages. Write parses the
+// client frames written by Conn and sends the messages with the browser
+// API.
+type browserConn struct {
+	ws    js.Value
+	addr  browserAddr
+	funcs []js.Func
+
+	opened chan struct{}
+
+	mu           sync.Mutex
+	rbuf         []byte
+	eof          bool
+	readDeadline time.Time
+	readReady    chan struct{} // signaled when rbuf or eof changes
+
+	closed bool
+
+	// Write state. Conn serializes writes.
+	wbuf    []byte
+	msg     []byte
+	msgText bool
+}
+
+func newBrowserConn(ws js.Value, u *url.URL) *browserConn {
+	bc := &browserConn{
+		ws:        ws,
+		addr:      browserAddr(u.String()),
+		opened:    make(chan struct{}),
+		readReady: make(chan struct{}, 1),
+	}
+	var openOnce sync.Once
+	bc.on("open", func(js.Value) {
+		openOnce.Do(func() { close(bc.opened) })
+	})
+	bc.on("message", func(ev js.Value) {
+		data := ev.Get("data")
+		if data.Type() == js.TypeString {
+			bc.receive(TextMessage, []byte(data.String()))
+			return
+		}
+		arr := js.Global().Get("Uint8Array").New(data)
+		p := make([]byte, arr.Get("length").Int())
+		js.CopyBytesToGo(p, arr)
+		bc.receive(BinaryMessage, p)
+	})
+	bc.on("close", func(ev js.Value) {
+		code := ev.Get("code").Int()
+		switch code {
+		case CloseAbnormalClosure, CloseTLSHandshake:
+			// Not sent on the wire. Conn reports an unexpected EOF.
+		default:
+			bc.receive(CloseMessage, FormatCloseMessage(code, ev.Get("reason").String()))
+		}
+		bc.mu.Lock()
+		bc.eof = true
+		bc.mu.Unlock()
+		bc.signal()
+	})
+	return bc
+}
+
+func (bc *browserConn) on(event string, fn func(ev js.Value)) {
+	f := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		fn(args[0])
+		return nil
+	})
+	bc.funcs = append(bc.funcs, f)
+	bc.ws.Call("addEventListener", event, f)
+}
+
+func (bc *browserConn) signal() {
+	select {
+	case bc.readReady <- struct{}{}:
+	default:
+	}
+}
+
+// receive appends an unmasked server frame with the payload to the read
+// buffer.
+func (bc *browserConn) receive(frameType int, p []byte) {
+	bc.mu.Lock()
+	b := append(bc.rbuf, finalBit|byte(frameType))
+	switch {
+	case len(p) <= 125:
+		b = append(b, byte(len(p)))
+	case len(p) <= 65535:
+		b = append(b, 126, 0, 0)
+		binary.BigEndian.PutUint16(b[len(b)-2:], uint16(len(p)))
+	default:
+		b = append(b, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(b[len(b)-8:], uint64(len(p)))
+	}
+	bc.rbuf = append(b, p...)
+	bc.mu.Unlock()
+	bc.signal()
+}
+
+func (bc *browserConn) Read(p []byte) (int, error) {
+	for {
+		bc.mu.Lock()
+		if len(bc.rbuf) > 0 {
+			n := copy(p, bc.rbuf)
+			bc.rbuf = bc.rbuf[n:]
+			bc.mu.Unlock()
+			return n, nil
+		}
+		if bc.eof {
+			bc.mu.Unlock()
+			return 0, net.ErrClosed
+		}
+		deadline := bc.readDeadline
+		bc.mu.Unlock()
+
+		if deadline.IsZero() {
+			<-bc.readReady
+			continue
+		}
+		d := time.Until(deadline)
+		if d <= 0 {
+			return 0, os.ErrDeadlineExceeded
+		}
+		t := time.NewTimer(d)
+		select {
+		case <-bc.readReady:
+			t.Stop()
+		case <-t.C:
+			return 0, os.ErrDeadlineExceeded
+		}
+	}
+}
+
+var errBrowserFrame = errors.New("websocket: invalid frame written to browser connection")
+
+// Write parses the client frames in p and sends complete messages.
+func (bc *browserConn) Write(p []byte) (int, error) {
+	bc.mu.Lock()
+	closed := bc.closed
+	bc.mu.Unlock()
+	if closed {
+		return 0, net.ErrClosed
+	}
+	bc.wbuf = append(bc.wbuf, p...)
+	for {
+		b := bc.wbuf
+		if len(b) < 2 {
+			break
+		}
+		final := b[0]&finalBit != 0
+		frameType := int(b[0] & 0xf)
+		n := int(b[1] & 0x7f)
+		i := 2
+		switch n {
+		case 126:
+			if len(b) < 4 {
+				return len(p), nil
+			}
+			n = int(binary.BigEndian.Uint16(b[2:]))
+			i = 4
+		case 127:
+			if len(b) < 10 {
+				return len(p), nil
+			}
+			n = int(binary.BigEndian.Uint64(b[2:]))
+			i = 10
+		}
+		if b[1]&maskBit == 0 {
+			return 0, errBrowserFrame
+		}
+		if len(b) < i+4+n {
+			break
+		}
+		var key [4]byte
+		copy(key[:], b[i:])
+		payload := b[i+4 : i+4+n]
+		maskBytes(key, 0, payload)
+		if err := bc.writeFrame(final, frameType, payload); err != nil {
+			return 0, err
+		}
+		bc.wbuf = b[i+4+n:]
+	}
+	if len(bc.wbuf) == 0 {
+		bc.wbuf = nil
+	}
+	return len(p), nil
+}
+
+func (bc *browserConn) writeFrame(final bool, frameType int, payload []byte) error {
+	switch frameType {
+	case TextMessage, BinaryMessage:
+		bc.msgText = frameType == TextMessage
+		bc.msg = append(bc.msg[:0], payload...)
+	case continuationFrame:
+		bc.msg = append(bc.msg, payload...)
+	case CloseMessage:
+		code := CloseNoStatusReceived
+		reason := ""
+		if len(payload) >= 2 {
+			code = int(binary.BigEndian.Uint16(payload))
+			reason = string(payload[2:])
+		}
+		// Browsers accept only 1000 and the application range.
+		if code == CloseNormalClosure || (code >= 3000 && code <= 4999) {
+			bc.ws.Call("close", code, reason)
+		} else {
+			bc.ws.Call("close")
+		}
+		return nil
+	default:
+		// Browsers do not expose ping and pong.
+		return nil
+	}
+	if !final {
+		return nil
+	}
+	if bc.msgText {
+		bc.ws.Call("send", string(bc.msg))
+	} else {
+		arr := js.Global().Get("Uint8Array").New(len(bc.msg))
+		js.CopyBytesToJS(arr, bc.msg)
+		bc.ws.Call("send", arr)
+	}
+	bc.msg = bc.msg[:0]
+	return nil
+}
+
+func (bc *browserConn) Close() error {
+	bc.mu.Lock()
+	if bc.closed {
+		bc.mu.Unlock()
+		return nil
+	}
+	bc.closed = true
+	bc.eof = true
+	bc.mu.Unlock()
+	bc.signal()
+	bc.ws.Call("close")
+	for _, f := range bc.funcs {
+		f.Release()
+	}
+	return nil
+}
+
+// BufferedAmount returns the browser's count of bytes queued for sending.
+func (bc *browserConn) BufferedAmount() int {
+	return bc.ws.Get("bufferedAmount").Int()
+}
+
+func (bc *browserConn) LocalAddr() net.Addr  { return bc.addr }
+func (bc *browserConn) RemoteAddr() net.Addr { return bc.addr }
+
+func (bc *browserConn) SetDeadline(t time.Time) error {
+	return bc.SetReadDeadline(t)
+}
+
+func (bc *browserConn) SetReadDeadline(t time.Time) error {
+	bc.mu.Lock()
+	bc.readDeadline = t
+	bc.mu.Unlock()
+	bc.signal()
+	return nil
+}
+
+// SetWriteDeadline has no effect because browser sends do not block.
+func (bc *browserConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build js && wasm
+
+package websocket
+
+import (
+	"net/url"
+	"syscall/js"
+	"testing"
+	"time"
+)
+
+// fakeBrowserSocket is a JavaScript object with the parts of the WebSocket
+// interface used by browserConn.
+type fakeBrowserSocket struct {
+	obj       js.Value
+	listeners map[string]js.Value
+	sent      []interface{}
+	closed    []interface{}
+}
+
+func newFakeBrowserSocket() *fakeBrowserSocket {
+	s := &fakeBrowserSocket{obj: js.Global().Get("Object").New(), listeners: map[string]js.Value{}}
+	s.obj.Set("addEventListener", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		s.listeners[args[0].String()] = args[1]
+		return nil
+	}))
+	s.obj.Set("send", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		if args[0].Type() == js.TypeString {
+			s.sent = append(s.sent, args[0].String())
+		} else {
+			p := make([]byte, args[0].Get("length").Int())
+			js.CopyBytesToGo(p, args[0])
+			s.sent = append(s.sent, p)
+		}
+		return nil
+	}))
+	s.obj.Set("close", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		for _, a := range args {
+			if a.Type() == js.TypeNumber {
+				s.closed = append(s.closed, a.Int())
+			} else {
+				s.closed = append(s.closed, a.String())
+			}
+		}
+		return nil
+	}))
+	return s
+}
+
+func (s *fakeBrowserSocket) dispatch(event string, fields map[string]interface{}) {
+	ev := js.Global().Get("Object").New()
+	for k, v := range fields {
+		ev.Set(k, v)
+	}
+	s.listeners[event].Invoke(ev)
+}
+
+func TestBrowserConn(t *testing.T) {
+	s := newFakeBrowserSocket()
+	u, _ := url.Parse("ws://example.com/")
+	bc := newBrowserConn(s.obj, u)
+	c := newConn(bc, false, 0, 0, nil, nil, nil)
+	c.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	// Received messages.
+	s.dispatch("message", map[string]interface{}{"data": "hello"})
+	bin := js.Global().Get("Uint8Array").New(300)
+	bin.SetIndex(299, 7)
+	s.dispatch("message", map[string]interface{}{"data": bin.Get("buffer")})
+
+	mt, p, err := c.ReadMessage()
+	if err != nil || mt != TextMessage || string(p) != "hello" {
+		t.Fatalf("ReadMessage() = %d, %q, %v", mt, p, err)
+	}
+	mt, p, err = c.ReadMessage()
+	if err != nil || mt != BinaryMessage || len(p) != 300 || p[299] != 7 {
+		t.Fatalf("ReadMessage() = %d, %d bytes, %v", mt, len(p), err)
+	}
+
+	// Sent messages, including a fragmented message and a ping that is
+	// dropped.
+	c.WriteMessage(TextMessage, []byte("one"))
+	c.WriteControl(PingMessage, nil, time.Now().Add(time.Second))
+	w, _ := c.NextWriter(BinaryMessage)
+	w.Write(make([]byte, 5000))
+	w.Write([]byte{9})
+	w.Close()
+	if len(s.sent) != 2 || s.sent[0] != "one" {
+		t.Fatalf("sent = %v", s.sent)
+	}
+	if b := s.sent[1].([]byte); len(b) != 5001 || b[5000] != 9 {
+		t.Fatalf("sent binary message of %d bytes", len(b))
+	}
+
+	// Close codes are passed in both directions.
+	c.WriteControl(CloseMessage, FormatCloseMessage(4000, "done"), time.Now().Add(time.Second))
+	if len(s.closed) != 2 || s.closed[0] != 4000 || s.closed[1] != "done" {
+		t.Errorf("close arguments = %v", s.closed)
+	}
+	s.dispatch("close", map[string]interface{}{"code": 4001, "reason": "bye"})
+	_, _, err = c.ReadMessage()
+	if e, ok := err.(*CloseError); !ok || e.Code != 4001 || e.Text != "bye" {
+		t.Errorf("ReadMessage() error = %v, want close 4001", err)
+	}
+}
+
+func TestBrowserConnReadDeadline(t *testing.T) {
+	s := newFakeBrowserSocket()
+	u, _ := url.Parse("ws://example.com/")
+	c := newConn(newBrowserConn(s.obj, u), false, 0, 0, nil, nil, nil)
+	c.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	if _, _, err := c.ReadMessage(); err == nil {
+		t.Fatal("ReadMessage() succeeded")
+	} else if ne, ok := err.(interface{ Timeout() bool }); !ok || !ne.Timeout() {
+		t.Errorf("ReadMessage() error = %v, want timeout", err)
+	}
+}
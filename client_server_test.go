@@ -330,6 +330,74 @@ func TestDialTLS(t *testing.T) {
 	sendRecv(t, ws)
 }
 
+func TestDialTLSResumption(t *testing.T) {
+	s := newTLSServer(t)
+	defer s.Close()
+
+	d := cstDialer
+	d.TLSClientConfig = &tls.Config{
+		RootCAs:            rootCAs(t, s.Server),
+		ClientSessionCache: tls.NewLRUClientSessionCache(1),
+	}
+	for i, want := range []bool{false, true} {
+		ws, _, err := d.Dial(s.URL, nil)
+		if err != nil {
+			t.Fatalf("Dial: %v", err)
+		}
+		// Read a message to receive the session ticket.
+		sendRecv(t, ws)
+		if got := ws.UnderlyingConn().(*tls.Conn).ConnectionState().DidResume; got != want {
+			t.Errorf("dial %d: DidResume = %v, want %v", i, got, want)
+		}
+		ws.Close()
+	}
+}
+
+func TestNewClientConn(t *testing.T) {
+	s := newTLSServer(t)
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	tlsConn, err := tls.Dial("tcp", u.Host, &tls.Config{RootCAs: rootCAs(t, s.Server)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := cstDialer
+	ws, resp, err := NewClientConn(tlsConn, u, ClientConfig{Dialer: &d})
+	if err != nil {
+		t.Fatalf("NewClientConn: %v", err)
+	}
+	defer ws.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols || ws.Subprotocol() != "p1" {
+		t.Errorf("status = %d, subprotocol = %q, want 101, p1", resp.StatusCode, ws.Subprotocol())
+	}
+	if ws.UnderlyingConn() != tlsConn {
+		t.Errorf("UnderlyingConn() = %T, want the TLS connection", ws.UnderlyingConn())
+	}
+	sendRecv(t, ws)
+}
+
+func TestNewClientConnSkipHandshake(t *testing.T) {
+	p1, p2 := net.Pipe()
+	server := newConn(p2, true, 0, 0, nil, nil, nil)
+	defer server.Close()
+	client, resp, err := NewClientConn(p1, &url.URL{Scheme: "ws", Host: "example.com"}, ClientConfig{
+		SkipHandshake: true,
+		Subprotocol:   "chat",
+	})
+	if err != nil || resp != nil {
+		t.Fatalf("NewClientConn() = %v, %v, want nil response and error", resp, err)
+	}
+	defer client.Close()
+	if client.Subprotocol() != "chat" {
+		t.Errorf("Subprotocol() = %q, want chat", client.Subprotocol())
+	}
+	go client.WriteMessage(TextMessage, []byte("hello"))
+	if _, p, err := server.ReadMessage(); err != nil || string(p) != "hello" {
+		t.Errorf("ReadMessage() = %q, %v, want hello", p, err)
+	}
+}
+
 func TestDialTimeout(t *testing.T) {
 	s := newServer(t)
 	defer s.Close()
@@ -423,6 +491,82 @@ func TestHandshakeTimeoutInContext(t *testing.T) {
 	ws.Close()
 }
 
+func TestDialPhaseTimeouts(t *testing.T) {
+	// The silent server accepts connections and never responds.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	var conns []net.Conn
+	var mu sync.Mutex
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, c)
+			mu.Unlock()
+		}
+	}()
+	defer func() {
+		mu.Lock()
+		for _, c := range conns {
+			c.Close()
+		}
+		mu.Unlock()
+	}()
+	silentURL := "://" + l.Addr().String()
+
+	blockingDial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	const short = 50 * time.Millisecond
+	tests := []struct {
+		name string
+		url  string
+		d    Dialer
+		want error
+	}{
+		{"connect", "ws" + silentURL, Dialer{NetDialContext: blockingDial, ConnectTimeout: short}, ErrConnectTimeout},
+		{"tls handshake", "wss" + silentURL, Dialer{TLSHandshakeTimeout: short}, ErrTLSHandshakeTimeout},
+		{"upgrade", "ws" + silentURL, Dialer{UpgradeTimeout: short}, ErrUpgradeTimeout},
+		{"upgrade after tls", "wss" + silentURL, Dialer{TLSHandshakeTimeout: short, UpgradeTimeout: time.Minute}, ErrTLSHandshakeTimeout},
+		{"handshake before phase", "ws" + silentURL, Dialer{HandshakeTimeout: short, UpgradeTimeout: time.Minute}, nil},
+	}
+	for _, tt := range tests {
+		_, _, err := tt.d.Dial(tt.url, nil)
+		if tt.want != nil && err != tt.want {
+			t.Errorf("%s: Dial() error = %v, want %v", tt.name, err, tt.want)
+		}
+		var te *TimeoutError
+		if tt.want == nil && (err == nil || errors.As(err, &te)) {
+			t.Errorf("%s: Dial() error = %v, want handshake timeout", tt.name, err)
+		}
+	}
+}
+
+func TestDialPhaseTimeoutsSuccess(t *testing.T) {
+	s := newTLSServer(t)
+	defer s.Close()
+
+	d := cstDialer
+	d.TLSClientConfig = &tls.Config{RootCAs: rootCAs(t, s.Server)}
+	d.ConnectTimeout = time.Minute
+	d.TLSHandshakeTimeout = time.Minute
+	d.UpgradeTimeout = time.Minute
+	ws, _, err := d.Dial(s.URL, nil)
+	if err != nil {
+		t.Fatal("Dial:", err)
+	}
+	defer ws.Close()
+	sendRecv(t, ws)
+}
+
 func TestDialBadScheme(t *testing.T) {
 	s := newServer(t)
 	defer s.Close()
@@ -17,6 +17,8 @@ var hostPortNoPortTests = []struct {
 	{&url.URL{Scheme: "wss", Host: "example.com"}, "example.com:443", "example.com"},
 	{&url.URL{Scheme: "ws", Host: "example.com:7777"}, "example.com:7777", "example.com"},
 	{&url.URL{Scheme: "wss", Host: "example.com:7777"}, "example.com:7777", "example.com"},
+	{&url.URL{Scheme: "wss", Host: "[::1]"}, "[::1]:443", "::1"},
+	{&url.URL{Scheme: "ws", Host: "[fe80::1%eth0]:7777"}, "[fe80::1%eth0]:7777", "fe80::1"},
 }
 
 func TestHostPortNoPort(t *testing.T) {
@@ -0,0 +1,191 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock that advances only when the test advances it. It
+// starts at the Unix epoch so that deadlines computed from it have expired
+// on the system clock.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock *fakeClock
+	when  time.Time
+	c     chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, when: c.now.Add(d), c: make(chan time.Time, 1)}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	for i, u := range t.clock.timers {
+		if u == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// advance waits for a timer to be created and advances the clock by d.
+func (c *fakeClock) advance(t *testing.T, d time.Duration) {
+	t.Helper()
+	for start := time.Now(); ; time.Sleep(time.Millisecond) {
+		c.mu.Lock()
+		n := len(c.timers)
+		c.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("no timer created")
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	timers := c.timers[:0]
+	for _, t := range c.timers {
+		if t.when.After(c.now) {
+			timers = append(timers, t)
+		} else {
+			t.c <- c.now
+		}
+	}
+	c.timers = timers
+}
+
+func TestClockReadDeadline(t *testing.T) {
+	clock := newFakeClock()
+	client, _ := (&PipeConfig{Clock: clock}).Pipe()
+	client.SetReadDeadline(clock.Now().Add(time.Minute))
+	errs := make(chan error, 1)
+	go func() {
+		_, _, err := client.ReadMessage()
+		errs <- err
+	}()
+	clock.advance(t, time.Minute-time.Second)
+	select {
+	case err := <-errs:
+		t.Fatalf("ReadMessage() returned %v before the deadline", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.advance(t, time.Second)
+	if err := <-errs; !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("ReadMessage() error = %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+}
+
+func TestReadActivityTimeout(t *testing.T) {
+	clock := newFakeClock()
+	client, server := (&PipeConfig{Clock: clock}).Pipe()
+	server.SetReadDeadline(clock.Now().Add(time.Minute))
+	server.SetReadActivityTimeout(2 * time.Minute)
+	pings := make(chan struct{}, 1)
+	server.SetPingHandler(func(string) error {
+		pings <- struct{}{}
+		return nil
+	})
+	errs := make(chan error, 1)
+	go func() {
+		_, _, err := server.ReadMessage()
+		errs <- err
+	}()
+
+	// The ping moves the deadline to two minutes after its arrival.
+	clock.advance(t, 30*time.Second)
+	client.WriteControl(PingMessage, nil, time.Time{})
+	<-pings
+	clock.advance(t, 2*time.Minute-time.Second)
+	select {
+	case err := <-errs:
+		t.Fatalf("ReadMessage() returned %v before the extended deadline", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.advance(t, time.Second)
+	if err := <-errs; !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("ReadMessage() error = %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+}
+
+func TestReadActivityTimeoutLaterDeadline(t *testing.T) {
+	clock := newFakeClock()
+	client, server := (&PipeConfig{Clock: clock}).Pipe()
+	deadline := clock.Now().Add(time.Hour)
+	server.SetReadDeadline(deadline)
+	server.SetReadActivityTimeout(time.Minute)
+	client.WriteMessage(TextMessage, []byte("hello"))
+	if _, _, err := server.ReadMessage(); err != nil {
+		t.Fatal(err)
+	}
+	if got := server.readDeadline.Load(); got != deadline.UnixNano() {
+		t.Errorf("read deadline = %v, want %v", time.Unix(0, got), deadline)
+	}
+}
+
+func TestClockWriteControl(t *testing.T) {
+	clock := newFakeClock()
+	client, _ := (&PipeConfig{Clock: clock}).Pipe()
+	if err := client.WriteControl(PingMessage, nil, clock.Now().Add(time.Second)); err != nil {
+		t.Errorf("WriteControl() error = %v", err)
+	}
+	if err := client.WriteControl(PingMessage, nil, clock.Now().Add(-time.Second)); err != errWriteTimeout {
+		t.Errorf("WriteControl() error = %v, want %v", err, errWriteTimeout)
+	}
+}
+
+func TestClockHandshakeTimeout(t *testing.T) {
+	clock := newFakeClock()
+	d := Dialer{
+		Clock:            clock,
+		HandshakeTimeout: time.Second,
+		NetDialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			// The server end never responds.
+			c, _ := newPipeConns(clock)
+			return c, nil
+		},
+	}
+	errs := make(chan error, 1)
+	go func() {
+		_, _, err := d.Dial("ws://example.com/", nil)
+		errs <- err
+	}()
+	clock.advance(t, time.Second)
+	if err := <-errs; !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("Dial() error = %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"strconv"
+
+	"github.com/gorilla/websocket/wsframe"
+)
+
+// ParseCloseMessage parses the payload of a close message received from a
+// peer into the close code and text. An empty payload returns the code
+// CloseNoStatusReceived. ParseCloseMessage returns a *ProtocolError if the
+// payload has a single byte, the code is not valid in a received close
+// message or the text is not valid UTF-8.
+func ParseCloseMessage(data []byte) (code int, text string, err error) {
+	code, reason, err := wsframe.ParseClose(data)
+	switch err {
+	case nil:
+		return code, string(reason), nil
+	case wsframe.ErrClosePayload:
+		return 0, "", &ProtocolError{Kind: ViolationCloseCode, Message: "close payload of length 1"}
+	case wsframe.ErrCloseCode:
+		return 0, "", &ProtocolError{Kind: ViolationCloseCode, Message: "bad close code " + strconv.Itoa(code)}
+	default:
+		return 0, "", &ProtocolError{Kind: ViolationCloseText, Message: "invalid utf8 payload in close frame"}
+	}
+}
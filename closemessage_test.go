@@ -0,0 +1,82 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFormatCloseMessageTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"short", "bye", "bye"},
+		{"max", strings.Repeat("a", 123), strings.Repeat("a", 123)},
+		{"ascii", strings.Repeat("a", 200), strings.Repeat("a", 123)},
+		{"two byte runes", strings.Repeat("é", 100), strings.Repeat("é", 61)},
+		{"three byte rune at limit", strings.Repeat("a", 121) + "€", strings.Repeat("a", 121)},
+		{"four byte rune at limit", strings.Repeat("a", 122) + "😀", strings.Repeat("a", 122)},
+	}
+	for _, tt := range tests {
+		p := FormatCloseMessage(CloseGoingAway, tt.text)
+		if len(p) > maxControlFramePayloadSize {
+			t.Errorf("%s: len(FormatCloseMessage()) = %d", tt.name, len(p))
+		}
+		if !utf8.Valid(p[2:]) {
+			t.Errorf("%s: text is not valid UTF-8", tt.name)
+		}
+		code, text, err := ParseCloseMessage(p)
+		if err != nil || code != CloseGoingAway || text != tt.want {
+			t.Errorf("%s: ParseCloseMessage() = %d, %q, %v, want %d, %q", tt.name, code, text, err, CloseGoingAway, tt.want)
+		}
+	}
+}
+
+func TestParseCloseMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		code int
+		text string
+		kind ViolationKind // kind of the *ProtocolError, if any
+	}{
+		{"empty", []byte{}, CloseNoStatusReceived, "", ""},
+		{"code", []byte{0x03, 0xe8}, CloseNormalClosure, "", ""},
+		{"text", []byte{0x0f, 0xa0, 'o', 'k'}, 4000, "ok", ""},
+		{"one byte", []byte{0x03}, 0, "", ViolationCloseCode},
+		{"reserved code", FormatCloseMessage(CloseNoStatusReceived+1, ""), 0, "", ViolationCloseCode},
+		{"invalid text", []byte{0x03, 0xe8, 0xc3}, 0, "", ViolationCloseText},
+	}
+	for _, tt := range tests {
+		code, text, err := ParseCloseMessage(tt.data)
+		if tt.kind != "" {
+			if pe, ok := err.(*ProtocolError); !ok || pe.Kind != tt.kind {
+				t.Errorf("%s: ParseCloseMessage() error = %v, want kind %s", tt.name, err, tt.kind)
+			}
+			continue
+		}
+		if err != nil || code != tt.code || text != tt.text {
+			t.Errorf("%s: ParseCloseMessage() = %d, %q, %v, want %d, %q", tt.name, code, text, err, tt.code, tt.text)
+		}
+	}
+}
+
+func TestProtocolErrorCloseText(t *testing.T) {
+	var buf strings.Builder
+	c := newTestConn(nil, &buf, true)
+	c.handleProtocolError(ViolationCloseText, strings.Repeat("ü", 100))
+	p := []byte(buf.String())
+	_, n, ok := parseFrameHeader(p)
+	if !ok {
+		t.Fatal("no close frame written")
+	}
+	if _, text, err := ParseCloseMessage(p[len(p)-int(n):]); err != nil || len(text) != 122 {
+		t.Errorf("ParseCloseMessage() = %d bytes, %v, want 122 bytes", len(text), err)
+	}
+}
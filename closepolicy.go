@@ -0,0 +1,117 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"errors"
+	"sync"
+)
+
+var closeCodeNames = map[int]string{
+	CloseNormalClosure:           "normal",
+	CloseGoingAway:               "going away",
+	CloseProtocolError:           "protocol error",
+	CloseUnsupportedData:         "unsupported data",
+	CloseNoStatusReceived:        "no status",
+	CloseAbnormalClosure:         "abnormal closure",
+	CloseInvalidFramePayloadData: "invalid payload data",
+	ClosePolicyViolation:         "policy violation",
+	CloseMessageTooBig:           "message too big",
+	CloseMandatoryExtension:      "mandatory extension missing",
+	CloseInternalServerErr:       "internal server error",
+	CloseTLSHandshake:            "TLS handshake error",
+}
+
+var (
+	appCloseCodeMu    sync.RWMutex
+	appCloseCodeNames = map[int]string{}
+)
+
+var errAppCloseCode = errors.New("websocket: application close codes must be in the range 4000-4999")
+
+// RegisterCloseCode registers the name of an application close code in the
+// range 4000-4999 reserved for private use by RFC 6455. The name is used by
+// CloseCodeName and in the text of CloseError, for example
+// "websocket: close 4001 (session expired)".
+func RegisterCloseCode(code int, name string) error {
+	if code < 4000 || code > 4999 {
+		return errAppCloseCode
+	}
+	appCloseCodeMu.Lock()
+	defer appCloseCodeMu.Unlock()
+	appCloseCodeNames[code] = name
+	return nil
+}
+
+// CloseCodeName returns a short name of a close code, such as "going away",
+// or the name registered with RegisterCloseCode. CloseCodeName returns ""
+// for unknown codes.
+func CloseCodeName(code int) string {
+	if name, ok := closeCodeNames[code]; ok {
+		return name
+	}
+	appCloseCodeMu.RLock()
+	defer appCloseCodeMu.RUnlock()
+	return appCloseCodeNames[code]
+}
+
+// ClosePolicy classifies close codes as expected, which are normal ways for
+// a connection to end, or unexpected, which applications report as errors.
+// A ClosePolicy is safe for concurrent use.
+type ClosePolicy struct {
+	mu       sync.RWMutex
+	expected map[int]bool
+}
+
+// DefaultClosePolicy expects CloseNormalClosure, CloseGoingAway and
+// CloseNoStatusReceived. Applications can change it at initialization, for
+// example to expect their own close codes.
+var DefaultClosePolicy = NewClosePolicy(CloseNormalClosure, CloseGoingAway, CloseNoStatusReceived)
+
+// NewClosePolicy returns a policy that expects the given codes.
+func NewClosePolicy(expected ...int) *ClosePolicy {
+	p := &ClosePolicy{expected: make(map[int]bool)}
+	p.Expect(expected...)
+	return p
+}
+
+// Expect adds codes to the expected codes.
+func (p *ClosePolicy) Expect(codes ...int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, code := range codes {
+		p.expected[code] = true
+	}
+}
+
+// Unexpect removes codes from the expected codes.
+func (p *ClosePolicy) Unexpect(codes ...int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, code := range codes {
+		delete(p.expected, code)
+	}
+}
+
+// Expected returns whether code is expected.
+func (p *ClosePolicy) Expected(code int) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.expected[code]
+}
+
+// IsUnexpectedCloseError returns whether err is a *CloseError with a code
+// that is not expected. It replaces calls to the IsUnexpectedCloseError
+// function with a list of codes repeated across an application:
+//
+//	if websocket.DefaultClosePolicy.IsUnexpectedCloseError(err) {
+//		log.Printf("error: %v", err)
+//	}
+func (p *ClosePolicy) IsUnexpectedCloseError(err error) bool {
+	if e, ok := err.(*CloseError); ok {
+		return !p.Expected(e.Code)
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegisterCloseCode(t *testing.T) {
+	for _, code := range []int{CloseNormalClosure, 3000, 3999, 5000} {
+		if err := RegisterCloseCode(code, "bad"); err != errAppCloseCode {
+			t.Errorf("RegisterCloseCode(%d) error = %v, want %v", code, err, errAppCloseCode)
+		}
+	}
+	if err := RegisterCloseCode(4999, "session expired"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		code int
+		name string
+		err  string
+	}{
+		{CloseGoingAway, "going away", "websocket: close 1001 (going away): bye"},
+		{4999, "session expired", "websocket: close 4999 (session expired): bye"},
+		{4998, "", "websocket: close 4998: bye"},
+		{5000, "", "websocket: close 5000: bye"},
+	}
+	for _, tt := range tests {
+		if got := CloseCodeName(tt.code); got != tt.name {
+			t.Errorf("CloseCodeName(%d) = %q, want %q", tt.code, got, tt.name)
+		}
+		if got := (&CloseError{Code: tt.code, Text: "bye"}).Error(); got != tt.err {
+			t.Errorf("CloseError{%d}.Error() = %q, want %q", tt.code, got, tt.err)
+		}
+	}
+}
+
+func TestClosePolicy(t *testing.T) {
+	p := NewClosePolicy(CloseNormalClosure, 4000)
+	p.Expect(4001)
+	p.Unexpect(4000)
+
+	tests := []struct {
+		err        error
+		unexpected bool
+	}{
+		{&CloseError{Code: CloseNormalClosure}, false},
+		{&CloseError{Code: 4001}, false},
+		{&CloseError{Code: 4000}, true},
+		{&CloseError{Code: CloseGoingAway}, true},
+		{errUnexpectedEOF, true},
+		{errors.New("other"), false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := p.IsUnexpectedCloseError(tt.err); got != tt.unexpected {
+			t.Errorf("IsUnexpectedCloseError(%v) = %v, want %v", tt.err, got, tt.unexpected)
+		}
+	}
+
+	for _, code := range []int{CloseNormalClosure, CloseGoingAway, CloseNoStatusReceived} {
+		if !DefaultClosePolicy.Expected(code) {
+			t.Errorf("DefaultClosePolicy does not expect %d", code)
+		}
+	}
+	if DefaultClosePolicy.Expected(CloseAbnormalClosure) {
+		t.Errorf("DefaultClosePolicy expects %d", CloseAbnormalClosure)
+	}
+}
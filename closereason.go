@@ -0,0 +1,171 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+)
+
+// Initiators of the end of a connection.
+const (
+	// InitiatorPeer means that the peer sent a close message first.
+	InitiatorPeer = "peer"
+
+	// InitiatorLocal means that the application sent a close message first
+	// or closed the connection without a read error.
+	InitiatorLocal = "local"
+
+	// InitiatorError means that reading failed before a close message was
+	// sent or received.
+	InitiatorError = "error"
+)
+
+// Classes of the read errors that end connections.
+const (
+	ErrorClassTimeout   = "timeout"    // the read deadline expired
+	ErrorClassReset     = "reset"      // the peer reset the network connection
+	ErrorClassEOF       = "eof"        // the network connection ended without a close message
+	ErrorClassProtocol  = "protocol"   // the peer violated the protocol
+	ErrorClassReadLimit = "read_limit" // a message exceeded the read limit
+	ErrorClassRateLimit = "rate_limit" // the peer exceeded the read rate limit
+	ErrorClassClosed    = "closed"     // the connection was closed locally while reading
+	ErrorClassOther     = "other"
+)
+
+// CloseReason describes why a connection ended.
+type CloseReason struct {
+	// Initiator is InitiatorPeer, InitiatorLocal or InitiatorError, or
+	// empty if the connection has not ended.
+	Initiator string
+
+	// PeerCode is the code of the close message received from the peer, or
+	// zero if none was received.
+	PeerCode int
+
+	// LocalCode is the code of the close message sent to the peer, or zero
+	// if none was sent.
+	LocalCode int
+
+	// ErrorClass is the class of the error returned by NextReader, such as
+	// ErrorClassTimeout, or empty if reading did not fail or ended with the
+	// peer's close message.
+	ErrorClass string
+
+	// Err is the error returned by NextReader, if any.
+	Err error
+}
+
+// String returns a short description of the reason such as "peer 1000" or
+// "local 1002 protocol", suitable as a metric label.
+func (r CloseReason) String() string {
+	s := r.Initiator
+	switch {
+	case r.Initiator == InitiatorPeer && r.PeerCode != 0:
+		s += " " + strconv.Itoa(r.PeerCode)
+	case r.Initiator == InitiatorLocal && r.LocalCode != 0:
+		s += " " + strconv.Itoa(r.LocalCode)
+	}
+	if r.ErrorClass != "" {
+		s += " " + r.ErrorClass
+	}
+	return s
+}
+
+// CloseReason returns the reason why the connection ended. The reason is
+// complete when Close is called, for example in the ConnTrace Closed hook.
+func (c *Conn) CloseReason() CloseReason {
+	c.reasonMu.Lock()
+	defer c.reasonMu.Unlock()
+	return c.reason
+}
+
+func (c *Conn) recordPeerClose(code int) {
+	c.reasonMu.Lock()
+	defer c.reasonMu.Unlock()
+	if c.reason.PeerCode == 0 {
+		c.reason.PeerCode = code
+		if c.reason.Initiator == "" {
+			c.reason.Initiator = InitiatorPeer
+		}
+	}
+}
+
+// recordLocalClose records a close message written by Conn.write.
+func (c *Conn) recordLocalClose(buf []byte) {
+	code := CloseNoStatusReceived
+	if hdr, _, ok := parseFrameHeader(buf); ok && len(buf) >= hdr+2 {
+		p := []byte{buf[hdr], buf[hdr+1]}
+		if buf[1]&maskBit != 0 {
+			var key [4]byte
+			copy(key[:], buf[hdr-4:hdr])
+			maskBytes(key, 0, p)
+		}
+		code = int(binary.BigEndian.Uint16(p))
+	}
+	c.reasonMu.Lock()
+	defer c.reasonMu.Unlock()
+	if c.reason.LocalCode == 0 {
+		c.reason.LocalCode = code
+		if c.reason.Initiator == "" {
+			c.reason.Initiator = InitiatorLocal
+		}
+	}
+}
+
+func (c *Conn) recordProtocolError() {
+	c.reasonMu.Lock()
+	defer c.reasonMu.Unlock()
+	c.protocolError = true
+}
+
+// recordReadError records the first error returned by NextReader.
+func (c *Conn) recordReadError(err error) {
+	c.reasonMu.Lock()
+	defer c.reasonMu.Unlock()
+	if c.reason.Err != nil {
+		return
+	}
+	c.reason.Err = err
+	var ce *CloseError
+	if errors.As(err, &ce) && ce.Code == c.reason.PeerCode {
+		return
+	}
+	var ne net.Error
+	switch {
+	case c.protocolError:
+		c.reason.ErrorClass = ErrorClassProtocol
+	case errors.Is(err, ErrReadLimit):
+		c.reason.ErrorClass = ErrorClassReadLimit
+	case errors.Is(err, ErrRateLimit):
+		c.reason.ErrorClass = ErrorClassRateLimit
+	case errors.Is(err, net.ErrClosed):
+		c.reason.ErrorClass = ErrorClassClosed
+	case errors.As(err, &ne) && ne.Timeout():
+		c.reason.ErrorClass = ErrorClassTimeout
+	case isConnReset(err):
+		c.reason.ErrorClass = ErrorClassReset
+	case err == errUnexpectedEOF || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		c.reason.ErrorClass = ErrorClassEOF
+	default:
+		c.reason.ErrorClass = ErrorClassOther
+	}
+	if c.reason.Initiator == "" {
+		c.reason.Initiator = InitiatorError
+	}
+}
+
+// recordClose completes the reason when the connection is closed.
+func (c *Conn) recordClose() CloseReason {
+	c.reasonMu.Lock()
+	defer c.reasonMu.Unlock()
+	if c.reason.Initiator == "" {
+		c.reason.Initiator = InitiatorLocal
+	}
+	return c.reason
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCloseReason(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, client, server *Conn)
+		read  bool // read on the server until an error
+		want  string
+	}{
+		{"peer close", func(t *testing.T, client, server *Conn) {
+			client.WriteControl(CloseMessage, FormatCloseMessage(CloseGoingAway, ""), time.Time{})
+		}, true, "peer 1001"},
+		{"local close", func(t *testing.T, client, server *Conn) {
+			server.WriteControl(CloseMessage, FormatCloseMessage(ClosePolicyViolation, "kick"), time.Time{})
+			go client.ReadMessage()
+		}, true, "local 1008"},
+		{"local close without status", func(t *testing.T, client, server *Conn) {
+			server.WriteMessage(CloseMessage, nil)
+		}, false, "local 1005"},
+		{"timeout", func(t *testing.T, client, server *Conn) {
+			server.SetReadDeadline(time.Now().Add(-time.Second))
+		}, true, "error timeout"},
+		{"eof", func(t *testing.T, client, server *Conn) {
+			client.Close()
+		}, true, "error eof"},
+		{"protocol violation", func(t *testing.T, client, server *Conn) {
+			client.NetConn().Write([]byte{0x83, 0x80, 0, 0, 0, 0})
+		}, true, "local 1002 protocol"},
+		{"read limit", func(t *testing.T, client, server *Conn) {
+			server.SetReadLimit(2)
+			client.WriteMessage(TextMessage, []byte("hello"))
+		}, true, "local 1009 read_limit"},
+		{"closed", func(t *testing.T, client, server *Conn) {}, false, "local"},
+	}
+	for _, tt := range tests {
+		client, server := Pipe()
+		tt.setup(t, client, server)
+		if tt.read {
+			for {
+				if _, _, err := server.ReadMessage(); err != nil {
+					break
+				}
+			}
+		}
+		if r := server.CloseReason(); tt.read && r.Err == nil {
+			t.Errorf("%s: Err = nil", tt.name)
+		}
+		server.Close()
+		client.Close()
+		if got := server.CloseReason().String(); got != tt.want {
+			t.Errorf("%s: CloseReason() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCloseReasonClient(t *testing.T) {
+	client, server := Pipe()
+	server.WriteControl(CloseMessage, FormatCloseMessage(CloseNormalClosure, ""), time.Time{})
+	if _, _, err := client.ReadMessage(); !IsCloseError(err, CloseNormalClosure) {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	client.Close()
+	server.Close()
+	want := CloseReason{Initiator: InitiatorPeer, PeerCode: CloseNormalClosure, LocalCode: CloseNormalClosure}
+	if got := client.CloseReason(); got.Initiator != want.Initiator || got.PeerCode != want.PeerCode ||
+		got.LocalCode != want.LocalCode || got.ErrorClass != "" {
+		t.Errorf("CloseReason() = %+v, want %+v", got, want)
+	}
+}
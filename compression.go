@@ -8,6 +8,7 @@ import (
 	"compress/flate"
 	"errors"
 	"io"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -19,12 +20,97 @@ const (
 )
 
 var (
+	// flateWriterPools holds a pool of writers per compression level. A
+	// flate.Writer keeps its level when reset, so connections that change
+	// the level of their messages reuse the writers of the new level.
 	flateWriterPools [maxCompressionLevel - minCompressionLevel + 1]sync.Pool
 	flateReaderPool  = sync.Pool{New: func() interface{} {
+		countFlatePoolMiss()
 		return flate.NewReader(nil)
 	}}
 )
 
+// CompressionPolicy reports whether a data message is compressed. The size
+// is the payload size of the message, or -1 when the message is written
+// with NextWriter or WriteMessageFrom and its size is not known in advance:
+//
+//	c.SetCompressionPolicy(func(messageType, size int) bool {
+//		return messageType == websocket.TextMessage && (size < 0 || size >= 512)
+//	})
+type CompressionPolicy func(messageType int, size int) bool
+
+// CompressionLevelPolicy returns the flate compression level of a compressed
+// data message. The size is as for CompressionPolicy. The policy is called
+// for every compressed message, so it can adapt the level to the conditions
+// of the process at runtime, for example to its CPU load:
+//
+//	c.SetCompressionLevelPolicy(func(messageType, size int) int {
+//		switch load := cpuLoad.Load(); {
+//		case load > 80:
+//			return flate.HuffmanOnly
+//		case load < 20:
+//			return 6
+//		}
+//		return flate.BestSpeed
+//	})
+//
+// An invalid level selects the level set by SetCompressionLevel.
+type CompressionLevelPolicy func(messageType int, size int) int
+
+// CompressTypes returns a policy that compresses the messages of the given
+// types.
+func CompressTypes(messageTypes ...int) CompressionPolicy {
+	var text, binary bool
+	for _, t := range messageTypes {
+		switch t {
+		case TextMessage:
+			text = true
+		case BinaryMessage:
+			binary = true
+		}
+	}
+	return func(messageType int, size int) bool {
+		return (messageType == TextMessage && text) || (messageType == BinaryMessage && binary)
+	}
+}
+
+// compressionParams are the permessage-deflate parameters that apply to the
+// messages written by a connection. The zero value is the configuration
+// that the package negotiates: no context takeover and the default window.
+type compressionParams struct {
+	// contextTakeover specifies that the compressor keeps its dictionary
+	// between messages.
+	contextTakeover bool
+
+	// maxWindowBits is the base-2 logarithm of the largest LZ77 window that
+	// the peer accepts, or zero for the default of 15.
+	maxWindowBits int
+}
+
+// shareable reports whether the messages compressed with the parameters
+// are independent of the connection, so that PreparedMessage can share
+// their frames between connections.
+func (p compressionParams) shareable() bool {
+	return !p.contextTakeover
+}
+
+// compressible reports whether messages can be compressed with the
+// parameters. The compress/flate package always uses a 32KB window, so
+// connections that negotiated a smaller window write uncompressed messages.
+func (p compressionParams) compressible() bool {
+	return p.maxWindowBits == 0 || p.maxWindowBits == 15
+}
+
+// parseMaxWindowBits parses the value of a max_window_bits extension
+// parameter.
+func parseMaxWindowBits(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 8 || n > 15 {
+		return 0, errInvalidCompression
+	}
+	return n, nil
+}
+
 func decompressNoContextTakeover(r io.Reader) io.ReadCloser {
 	const tail =
 	// Add four bytes as specified in RFC
@@ -32,6 +118,7 @@ func decompressNoContextTakeover(r io.Reader) io.ReadCloser {
 		// Add final block to squelch unexpected EOF error from flate reader.
 		"\x01\x00\x00\xff\xff"
 
+	countFlatePoolGet()
 	fr, _ := flateReaderPool.Get().(io.ReadCloser)
 	mr := io.MultiReader(r, strings.NewReader(tail))
 	if err := fr.(flate.Resetter).Reset(mr, nil); err != nil {
@@ -48,8 +135,10 @@ func isValidCompressionLevel(level int) bool {
 func compressNoContextTakeover(w io.WriteCloser, level int) io.WriteCloser {
 	p := &flateWriterPools[level-minCompressionLevel]
 	tw := &truncWriter{w: w}
+	countFlatePoolGet()
 	fw, _ := p.Get().(*flate.Writer)
 	if fw == nil {
+		countFlatePoolMiss()
 		fw, _ = flate.NewWriter(tw, level)
 	} else {
 		fw.Reset(tw)
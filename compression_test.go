@@ -2,8 +2,11 @@ package websocket
 
 import (
 	"bytes"
+	"compress/flate"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -77,3 +80,161 @@ func TestValidCompressionLevel(t *testing.T) {
 		}
 	}
 }
+
+func TestClientMaxWindowBits(t *testing.T) {
+	tests := []struct {
+		param string
+		bits  int
+		err   error
+	}{
+		{"", 0, nil},
+		{"; client_max_window_bits=15", 15, nil},
+		{"; client_max_window_bits=10", 10, nil},
+		{"; client_max_window_bits=20", 0, errInvalidCompression},
+	}
+	for _, tt := range tests {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			netConn, brw, err := http.NewResponseController(w).Hijack()
+			if err != nil {
+				return
+			}
+			defer netConn.Close()
+			brw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+			brw.WriteString("Sec-WebSocket-Accept: " + computeAcceptKey(r.Header.Get("Sec-Websocket-Key")) + "\r\n")
+			brw.WriteString("Sec-WebSocket-Extensions: permessage-deflate; server_no_context_takeover; client_no_context_takeover" + tt.param + "\r\n\r\n")
+			brw.Flush()
+			netConn.Read(make([]byte, 1))
+		}))
+		d := Dialer{EnableCompression: true}
+		c, _, err := d.Dial(makeWsProto(s.URL), nil)
+		if err != tt.err {
+			t.Errorf("%q: Dial() error = %v, want %v", tt.param, err, tt.err)
+		}
+		if c != nil {
+			if c.compressParams.maxWindowBits != tt.bits {
+				t.Errorf("%q: maxWindowBits = %d, want %d", tt.param, c.compressParams.maxWindowBits, tt.bits)
+			}
+			if got, want := c.writeCompression(TextMessage, -1), tt.bits != 10; got != want {
+				t.Errorf("%q: writeCompression() = %t, want %t", tt.param, got, want)
+			}
+			c.Close()
+		}
+		s.Close()
+	}
+}
+
+func TestCompressionPolicy(t *testing.T) {
+	minSize := func(messageType, size int) bool { return size < 0 || size >= 100 }
+	tests := []struct {
+		name        string
+		policy      CompressionPolicy
+		messageType int
+		size        int
+		write       string // WriteMessage if empty, "writer" or "prepared"
+		want        bool
+	}{
+		{"no policy", nil, BinaryMessage, 10, "", true},
+		{"text only, text", CompressTypes(TextMessage), TextMessage, 10, "", true},
+		{"text only, binary", CompressTypes(TextMessage), BinaryMessage, 10, "", false},
+		{"text only, binary writer", CompressTypes(TextMessage), BinaryMessage, 10, "writer", false},
+		{"min size, small", minSize, TextMessage, 10, "", false},
+		{"min size, large", minSize, TextMessage, 100, "", true},
+		{"min size, unknown size", minSize, TextMessage, 10, "writer", true},
+		{"text only, binary prepared", CompressTypes(TextMessage), BinaryMessage, 10, "prepared", false},
+	}
+	for _, tt := range tests {
+		for _, isServer := range []bool{false, true} {
+			var buf bytes.Buffer
+			c := newTestConn(nil, &buf, isServer)
+			c.newCompressionWriter = compressNoContextTakeover
+			c.SetCompressionPolicy(tt.policy)
+			data := bytes.Repeat([]byte{'a'}, tt.size)
+			var err error
+			switch tt.write {
+			case "writer":
+				var w io.WriteCloser
+				if w, err = c.NextWriter(tt.messageType); err == nil {
+					w.Write(data)
+					err = w.Close()
+				}
+			case "prepared":
+				var pm *PreparedMessage
+				if pm, err = NewPreparedMessage(tt.messageType, data); err == nil {
+					err = c.WritePreparedMessage(pm)
+				}
+			default:
+				err = c.WriteMessage(tt.messageType, data)
+			}
+			if err != nil {
+				t.Fatalf("%s: write error %v", tt.name, err)
+			}
+			if got := buf.Bytes()[0]&rsv1Bit != 0; got != tt.want {
+				t.Errorf("%s, server=%v: compressed = %v, want %v", tt.name, isServer, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestCompressionLevelPolicy(t *testing.T) {
+	data := bytes.Repeat([]byte("hello, compression level "), 100)
+	write := func(c *Conn, how string) []byte {
+		var buf bytes.Buffer
+		c.conn = fakeNetConn{Writer: &buf}
+		var err error
+		switch how {
+		case "writer":
+			var w io.WriteCloser
+			if w, err = c.NextWriter(TextMessage); err == nil {
+				w.Write(data)
+				err = w.Close()
+			}
+		case "prepared":
+			var pm *PreparedMessage
+			if pm, err = NewPreparedMessage(TextMessage, data); err == nil {
+				err = c.WritePreparedMessage(pm)
+			}
+		default:
+			err = c.WriteMessage(TextMessage, data)
+		}
+		if err != nil {
+			t.Fatalf("%s: write error %v", how, err)
+		}
+		return buf.Bytes()
+	}
+	tests := []struct {
+		name   string
+		policy CompressionLevelPolicy
+		want   int
+	}{
+		{"no policy", nil, 9},
+		{"huffman only", func(messageType, size int) int { return flate.HuffmanOnly }, flate.HuffmanOnly},
+		{"by size", func(messageType, size int) int {
+			if size < 0 {
+				return flate.NoCompression
+			}
+			return flate.HuffmanOnly
+		}, -1},
+		{"invalid level", func(messageType, size int) int { return 42 }, 9},
+	}
+	for _, tt := range tests {
+		for _, how := range []string{"", "writer", "prepared"} {
+			c := newTestConn(nil, nil, true)
+			c.newCompressionWriter = compressNoContextTakeover
+			c.SetCompressionLevel(9)
+			c.SetCompressionLevelPolicy(tt.policy)
+			got := write(c, how)
+
+			want := tt.want
+			if want == -1 {
+				// The size is not known to the policy for NextWriter.
+				want = map[bool]int{false: flate.HuffmanOnly, true: flate.NoCompression}[how == "writer"]
+			}
+			ref := newTestConn(nil, nil, true)
+			ref.newCompressionWriter = compressNoContextTakeover
+			ref.SetCompressionLevel(want)
+			if !bytes.Equal(got, write(ref, how)) {
+				t.Errorf("%s, %q: message not compressed with level %d", tt.name, how, want)
+			}
+		}
+	}
+}
@@ -6,16 +6,21 @@ package websocket
 
 import (
 	"bufio"
+	"context"
 	"crypto/rand"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
 	"net"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unicode/utf8"
+
+	"github.com/gorilla/websocket/wsframe"
 )
 
 const (
@@ -89,16 +94,14 @@ var ErrCloseSent = errors.New("websocket: close sent")
 // read limit set for the connection.
 var ErrReadLimit = errors.New("websocket: read limit exceeded")
 
-// netError satisfies the net Error interface.
-type netError struct {
-	msg       string
-	temporary bool
-	timeout   bool
-}
+// ErrMessageTooLarge is returned by ReadMessage and the other methods that
+// read a whole message into a byte slice when the message does not fit in a
+// slice, for example a message of more than 2 GiB on a 32-bit platform. Use
+// NextReader, ReadMessageTo or ReadMessageChunks to read such messages.
+var ErrMessageTooLarge = errors.New("websocket: message too large for a byte slice")
 
-func (e *netError) Error() string   { return e.msg }
-func (e *netError) Temporary() bool { return e.temporary }
-func (e *netError) Timeout() bool   { return e.timeout }
+// maxInt is the length of the largest byte slice.
+const maxInt = int(^uint(0) >> 1)
 
 // CloseError represents a close message.
 type CloseError struct {
@@ -112,31 +115,10 @@ type CloseError struct {
 func (e *CloseError) Error() string {
 	s := []byte("websocket: close ")
 	s = strconv.AppendInt(s, int64(e.Code), 10)
-	switch e.Code {
-	case CloseNormalClosure:
-		s = append(s, " (normal)"...)
-	case CloseGoingAway:
-		s = append(s, " (going away)"...)
-	case CloseProtocolError:
-		s = append(s, " (protocol error)"...)
-	case CloseUnsupportedData:
-		s = append(s, " (unsupported data)"...)
-	case CloseNoStatusReceived:
-		s = append(s, " (no status)"...)
-	case CloseAbnormalClosure:
-		s = append(s, " (abnormal closure)"...)
-	case CloseInvalidFramePayloadData:
-		s = append(s, " (invalid payload data)"...)
-	case ClosePolicyViolation:
-		s = append(s, " (policy violation)"...)
-	case CloseMessageTooBig:
-		s = append(s, " (message too big)"...)
-	case CloseMandatoryExtension:
-		s = append(s, " (mandatory extension missing)"...)
-	case CloseInternalServerErr:
-		s = append(s, " (internal server error)"...)
-	case CloseTLSHandshake:
-		s = append(s, " (TLS handshake error)"...)
+	if name := CloseCodeName(e.Code); name != "" {
+		s = append(s, " ("...)
+		s = append(s, name...)
+		s = append(s, ')')
 	}
 	if e.Text != "" {
 		s = append(s, ": "...)
@@ -159,7 +141,8 @@ func IsCloseError(err error, codes ...int) bool {
 }
 
 // IsUnexpectedCloseError returns boolean indicating whether the error is a
-// *CloseError with a code not in the list of expected codes.
+// *CloseError with a code not in the list of expected codes. See ClosePolicy
+// for a list of expected codes configured once for an application.
 func IsUnexpectedCloseError(err error, expectedCodes ...int) bool {
 	if e, ok := err.(*CloseError); ok {
 		for _, code := range expectedCodes {
@@ -173,7 +156,7 @@ func IsUnexpectedCloseError(err error, expectedCodes ...int) bool {
 }
 
 var (
-	errWriteTimeout        = &netError{msg: "websocket: write timeout", timeout: true, temporary: true}
+	errWriteTimeout        = &TimeoutError{Op: "write"}
 	errUnexpectedEOF       = &CloseError{Code: CloseAbnormalClosure, Text: io.ErrUnexpectedEOF.Error()}
 	errBadWriteOpCode      = errors.New("websocket: bad write message type")
 	errWriteClosed         = errors.New("websocket: write closed")
@@ -200,27 +183,8 @@ func isData(frameType int) bool {
 	return frameType == TextMessage || frameType == BinaryMessage
 }
 
-var validReceivedCloseCodes = map[int]bool{
-	// see http://www.iana.org/assignments/websocket/websocket.xhtml#close-code-number
-
-	CloseNormalClosure:           true,
-	CloseGoingAway:               true,
-	CloseProtocolError:           true,
-	CloseUnsupportedData:         true,
-	CloseNoStatusReceived:        false,
-	CloseAbnormalClosure:         false,
-	CloseInvalidFramePayloadData: true,
-	ClosePolicyViolation:         true,
-	CloseMessageTooBig:           true,
-	CloseMandatoryExtension:      true,
-	CloseInternalServerErr:       true,
-	CloseServiceRestart:          true,
-	CloseTryAgainLater:           true,
-	CloseTLSHandshake:            false,
-}
-
 func isValidReceivedCloseCode(code int) bool {
-	return validReceivedCloseCodes[code] || (code >= 3000 && code <= 4999)
+	return wsframe.ValidCloseCode(code)
 }
 
 // BufferPool represents a pool of buffers. The *sync.Pool type satisfies this
@@ -242,21 +206,57 @@ type Conn struct {
 	conn        net.Conn
 	isServer    bool
 	subprotocol string
+	extensions  string // Sec-WebSocket-Extensions response of the upgrader
+	version     string // negotiated Sec-WebSocket-Version
+	clock       Clock
+	trace       *ConnTrace      // hooks from the handshake context
+	ctx         context.Context // returned by Context
+	counted     int32           // 1 if counted in the expvar active connections
+
+	ctxBinding   atomic.Pointer[contextBinding] // set by WithContext
+	ctxCloseCode int                            // set by SetContextCloseCode
+
+	observer       Observer
+	observerClosed int32 // 1 after OnClose is called
+
+	dump        atomic.Pointer[frameDumper]       // set by SetFrameDump
+	teeReads    atomic.Pointer[tee]               // set by TeeReads
+	teeWrites   atomic.Pointer[tee]               // set by TeeWrites
+	spool       *SpoolConfig                      // set by SetSpool
+	profilerTag atomic.Pointer[string]            // set by SetProfilerTag
+	labels      atomic.Pointer[map[string]string] // set by SetLabel
+	labelsMu    sync.Mutex                        // serializes SetLabel
+	maskSource  atomic.Pointer[maskKeySource]     // set by SetMaskKeySource
+	memory      memoryAccount                     // see Memory
+
+	reasonMu      sync.Mutex
+	reason        CloseReason
+	protocolError bool // the peer violated the protocol
 
 	// Write fields
-	mu            chan struct{} // used as mutex to protect write to conn
-	writeBuf      []byte        // frame is constructed in this buffer.
-	writePool     BufferPool
-	writeBufSize  int
-	writeDeadline time.Time
-	writer        io.WriteCloser // the current writer returned to the application
-	isWriting     bool           // for best-effort concurrent write detection
+	mu             chan struct{} // used as mutex to protect write to conn
+	writeBuf       []byte        // frame is constructed in this buffer.
+	writePool      BufferPool
+	writeBufSize   int
+	writeDeadline  time.Time
+	writeTimeout   time.Duration  // set by SetDefaultWriteTimeout
+	writeBuffered  atomic.Int64   // bytes of the current message in writeBuf
+	writePending   atomic.Int64   // bytes of the frames being written to conn
+	writeQueued    atomic.Int64   // bytes of the messages in a SendQueue
+	writer         io.WriteCloser // the current writer returned to the application
+	isWriting      bool           // for best-effort concurrent write detection
+	writeCheck     writeCheck     // for the optional concurrency check
+	writeThrottles []*Throttle    // set by SetWriteThrottle
+	writeProgress  func(Progress) // set by SetWriteProgressHandler
 
 	writeErrMu sync.Mutex
 	writeErr   error
 
 	enableWriteCompression bool
+	compressionPolicy      CompressionPolicy // set by SetCompressionPolicy
 	compressionLevel       int
+	compressionLevelPolicy CompressionLevelPolicy // set by SetCompressionLevelPolicy
+	compressParams         compressionParams
 	newCompressionWriter   func(io.WriteCloser, int) io.WriteCloser
 
 	// Read fields
@@ -265,20 +265,43 @@ type Conn struct {
 	br      *bufio.Reader
 	// bytes remaining in current frame.
 	// set setReadRemaining to safely update this value and prevent overflow
-	readRemaining int64
-	readFinal     bool  // true the current message has more frames.
-	readLength    int64 // Message size.
-	readLimit     int64 // Maximum message size.
-	readMaskPos   int
-	readMaskKey   [4]byte
-	handlePong    func(string) error
-	handlePing    func(string) error
-	handleClose   func(int, string) error
-	readErrCount  int
-	messageReader *messageReader // the current low-level reader
+	readRemaining   int64
+	readFinal       bool  // true the current message has more frames.
+	readLength      int64 // Message size.
+	readLimit       int64 // Maximum message size.
+	readMaskPos     int
+	readMasked      bool // the current frame is masked
+	readMaskKey     [4]byte
+	handlePong      func(string) error
+	handlePing      func(string) error
+	handleClose     func(int, string) error
+	readErrCount    int
+	lastData        atomic.Int64     // UnixNano of the last data frame, see Activity
+	lastPong        atomic.Int64     // UnixNano of the last pong, see Activity
+	rtt             atomic.Int64     // smoothed RTT measured by a Heartbeat
+	readRateLimiter *readRateLimiter // set by SetReadRateLimit
+	readDropping    bool             // skipping the frames of a dropped message
+	readDeadline    atomic.Int64     // UnixNano of the read deadline, zero for none
+	readActivity    time.Duration    // set by SetReadActivityTimeout
+	handleViolation func(ProtocolViolation)
+	strictness      Strictness
+	quirks          Quirk               // set by SetQuirks
+	quirksUsed      Quirk               // quirks reported to the QuirkTriggered hook
+	transform       PayloadTransform    // set by SetPayloadTransform
+	inbound         []MessageMiddleware // set by UseInbound
+	outbound        []MessageMiddleware // set by UseOutbound
+	handleReadLimit func(size int64) ReadLimitAction
+	messageReader   *messageReader // the current low-level reader
+	tracedReader    *tracedReader  // the current reader if hooks are set
+	readProgress    func(Progress) // set by SetReadProgressHandler
+	readCompression bool           // the decompressor of the message is accounted
 
 	readDecompress         bool // whether last read frame had RSV1 set
 	newDecompressionReader func(io.Reader) io.ReadCloser
+	deflateFrame           *deflateFrameReader // set if x-webkit-deflate-frame was negotiated
+	readInflate            bool                // whether last read frame is inflated by deflateFrame
+	readInflated           []byte              // unread payload of an inflated frame
+	flow                   *flowControl        // set if x-flow-control was negotiated
 }
 
 func newConn(conn net.Conn, isServer bool, readBufferSize, writeBufferSize int, writeBufferPool BufferPool, br *bufio.Reader, writeBuf []byte) *Conn {
@@ -315,10 +338,14 @@ func newConn(conn net.Conn, isServer bool, readBufferSize, writeBufferSize int,
 		writeBufSize:           writeBufferSize,
 		enableWriteCompression: true,
 		compressionLevel:       defaultCompressionLevel,
+		clock:                  systemClock{},
+		writeCheck:             writeCheck{enable: checkWritesDefault},
 	}
 	c.SetCloseHandler(nil)
 	c.SetPingHandler(nil)
 	c.SetPongHandler(nil)
+	c.addMemory(memReadBuffer, int64(br.Size()))
+	c.addMemory(memWriteBuffer, int64(len(writeBuf)))
 	return c
 }
 
@@ -341,9 +368,48 @@ func (c *Conn) Subprotocol() string {
 // Close closes the underlying network connection without sending or waiting
 // for a close message.
 func (c *Conn) Close() error {
+	c.stopContext()
+	c.recordClose()
+	if c.trace != nil && c.trace.Closed != nil {
+		c.trace.Closed(c)
+	}
+	countClose(c)
+	c.observeClose(CloseAbnormalClosure, "")
+	c.releaseMemory()
+	if c.flow != nil {
+		c.flow.close()
+	}
 	return c.conn.Close()
 }
 
+// CloseWrite starts the closing handshake: it sends a close message with
+// the code and text and refuses further writes with ErrCloseSent, while the
+// read side stays open. The application continues to read the messages
+// that the peer sends until the read methods return the peer's close
+// message as a *CloseError or another error, and then calls Close:
+//
+//	if err := c.CloseWrite(websocket.CloseNormalClosure, "done"); err != nil {
+//		return c.Close()
+//	}
+//	for {
+//		if _, _, err := c.ReadMessage(); err != nil {
+//			break
+//		}
+//	}
+//	c.Close()
+//
+// The text is truncated as described in FormatCloseMessage. CloseWrite
+// returns ErrCloseSent if a close message was already sent.
+func (c *Conn) CloseWrite(code int, text string) error {
+	return c.WriteControl(CloseMessage, FormatCloseMessage(code, text), c.clock.Now().Add(writeWait))
+}
+
+// CompressionNegotiated reports whether the peers negotiated per message
+// compression in the handshake.
+func (c *Conn) CompressionNegotiated() bool {
+	return c.newCompressionWriter != nil
+}
+
 // LocalAddr returns the local network address.
 func (c *Conn) LocalAddr() net.Addr {
 	return c.conn.LocalAddr()
@@ -357,11 +423,20 @@ func (c *Conn) RemoteAddr() net.Addr {
 // Write methods
 
 func (c *Conn) writeFatal(err error) error {
+	sent := err == ErrCloseSent
+	err = c.contextError(err)
 	c.writeErrMu.Lock()
-	if c.writeErr == nil {
+	first := c.writeErr == nil
+	if first {
 		c.writeErr = err
 	}
 	c.writeErrMu.Unlock()
+	if first && c.flow != nil {
+		c.flow.close()
+	}
+	if first && c.observer != nil && !sent {
+		c.observer.OnError(c, err)
+	}
 	return err
 }
 
@@ -390,15 +465,24 @@ func (c *Conn) write(frameType int, deadline time.Time, buf0, buf1 []byte) error
 	if err := c.conn.SetWriteDeadline(deadline); err != nil {
 		return c.writeFatal(err)
 	}
-	if len(buf1) == 0 {
+	if d := c.dump.Load(); d != nil {
+		c.dumpWrittenFrames(d, buf0, buf1)
+	}
+	c.writePending.Store(int64(len(buf0) + len(buf1)))
+	defer c.writePending.Store(0)
+	switch {
+	case len(c.writeThrottles) > 0 && !isControl(frameType):
+		err = c.writeThrottled(deadline, buf0, buf1)
+	case len(buf1) == 0:
 		_, err = c.conn.Write(buf0)
-	} else {
+	default:
 		err = c.writeBufs(buf0, buf1)
 	}
 	if err != nil {
 		return c.writeFatal(err)
 	}
 	if frameType == CloseMessage {
+		c.recordLocalClose(buf0)
 		_ = c.writeFatal(ErrCloseSent)
 	}
 	return nil
@@ -411,7 +495,8 @@ func (c *Conn) writeBufs(bufs ...[]byte) error {
 }
 
 // WriteControl writes a control message with the given deadline. The allowed
-// message types are CloseMessage, PingMessage and PongMessage.
+// message types are CloseMessage, PingMessage and PongMessage. A zero
+// deadline means the default write timeout, see SetDefaultWriteTimeout.
 func (c *Conn) WriteControl(messageType int, data []byte, deadline time.Time) error {
 	if !isControl(messageType) {
 		return errBadWriteOpCode
@@ -419,41 +504,42 @@ func (c *Conn) WriteControl(messageType int, data []byte, deadline time.Time) er
 	if len(data) > maxControlFramePayloadSize {
 		return errInvalidControlFrame
 	}
+	return c.writeControl(messageType, data, deadline)
+}
 
-	b0 := byte(messageType) | finalBit
-	b1 := byte(len(data))
-	if !c.isServer {
-		b1 |= maskBit
+// writeControl writes a control frame, including the frames of negotiated
+// extensions, as described for WriteControl.
+func (c *Conn) writeControl(messageType int, data []byte, deadline time.Time) error {
+	h := wsframe.Header{Fin: true, Opcode: wsframe.Opcode(messageType), Masked: !c.isServer, Length: int64(len(data))}
+	if h.Masked {
+		h.Key = c.newMaskKey()
 	}
-
 	buf := make([]byte, 0, maxFrameHeaderSize+maxControlFramePayloadSize)
-	buf = append(buf, b0, b1)
-
-	if c.isServer {
-		buf = append(buf, data...)
-	} else {
-		key := newMaskKey()
-		buf = append(buf, key[:]...)
-		buf = append(buf, data...)
-		maskBytes(key, 0, buf[6:])
+	buf = wsframe.AppendHeader(buf, h)
+	buf = append(buf, data...)
+	if h.Masked {
+		maskBytes(h.Key, 0, buf[len(buf)-len(data):])
 	}
 
+	if deadline.IsZero() && c.writeTimeout > 0 {
+		deadline = c.clock.Now().Add(c.writeTimeout)
+	}
 	if deadline.IsZero() {
 		// No timeout for zero time.
 		<-c.mu
 	} else {
-		d := time.Until(deadline)
+		d := deadline.Sub(c.clock.Now())
 		if d < 0 {
 			return errWriteTimeout
 		}
 		select {
 		case <-c.mu:
 		default:
-			timer := time.NewTimer(d)
+			timer := c.clock.NewTimer(d)
 			select {
 			case <-c.mu:
 				timer.Stop()
-			case <-timer.C:
+			case <-timer.C():
 				return errWriteTimeout
 			}
 		}
@@ -471,10 +557,16 @@ func (c *Conn) WriteControl(messageType int, data []byte, deadline time.Time) er
 	if err := c.conn.SetWriteDeadline(deadline); err != nil {
 		return c.writeFatal(err)
 	}
+	if d := c.dump.Load(); d != nil {
+		c.dumpWrittenFrames(d, buf, nil)
+	}
+	c.writePending.Store(int64(len(buf)))
+	defer c.writePending.Store(0)
 	if _, err = c.conn.Write(buf); err != nil {
 		return c.writeFatal(err)
 	}
 	if messageType == CloseMessage {
+		c.recordLocalClose(buf)
 		_ = c.writeFatal(ErrCloseSent)
 	}
 	return err
@@ -490,6 +582,9 @@ func (c *Conn) beginMessage(mw *messageWriter, messageType int) error {
 		c.writer = nil
 	}
 
+	c.beginCall()
+	defer c.endCall()
+
 	if !isControl(messageType) && !isData(messageType) {
 		return errBadWriteOpCode
 	}
@@ -503,7 +598,10 @@ func (c *Conn) beginMessage(mw *messageWriter, messageType int) error {
 
 	mw.c = c
 	mw.frameType = messageType
+	mw.messageType = messageType
+	mw.total = -1
 	mw.pos = maxFrameHeaderSize
+	mw.deadline = c.messageDeadline()
 
 	if c.writeBuf == nil {
 		wpd, ok := c.writePool.Get().(writePoolData)
@@ -512,6 +610,7 @@ func (c *Conn) beginMessage(mw *messageWriter, messageType int) error {
 		} else {
 			c.writeBuf = make([]byte, c.writeBufSize)
 		}
+		c.addMemory(memWriteBuffer, int64(len(c.writeBuf)))
 	}
 	return nil
 }
@@ -525,16 +624,39 @@ func (c *Conn) beginMessage(mw *messageWriter, messageType int) error {
 // All message types (TextMessage, BinaryMessage, CloseMessage, PingMessage and
 // PongMessage) are supported.
 func (c *Conn) NextWriter(messageType int) (io.WriteCloser, error) {
+	var (
+		w   io.WriteCloser
+		err error
+	)
+	if c.transformsOutbound() && isData(messageType) {
+		w, err = c.nextTransformWriter(messageType)
+	} else {
+		w, err = c.nextWriter(messageType, -1)
+	}
+	if t := c.teeWrites.Load(); t != nil && err == nil && isData(messageType) {
+		w = &teeWriteCloser{w: w, tee: t.w}
+	}
+	return w, err
+}
+
+func (c *Conn) nextWriter(messageType int, size int) (io.WriteCloser, error) {
 	var mw messageWriter
 	if err := c.beginMessage(&mw, messageType); err != nil {
 		return nil, err
 	}
 	c.writer = &mw
-	if c.newCompressionWriter != nil && c.enableWriteCompression && isData(messageType) {
-		w := c.newCompressionWriter(c.writer, c.compressionLevel)
+	if c.writeCompression(messageType, size) {
+		level := c.writeCompressionLevel(messageType, size)
+		w := c.newCompressionWriter(c.writer, level)
 		mw.compress = true
+		mw.compressed = true
+		mw.compressMemory = flateWriterMemory[level-minCompressionLevel]
+		c.addMemory(memCompression, mw.compressMemory)
 		c.writer = w
 	}
+	if c.trace != nil && c.trace.MessageWritten != nil && isData(messageType) {
+		c.writer = &tracedWriter{w: c.writer, mw: &mw, info: MessageInfo{Type: messageType}}
+	}
 	return c.writer, nil
 }
 
@@ -543,7 +665,16 @@ type messageWriter struct {
 	compress  bool // whether next call to flushFrame should set RSV1
 	pos       int  // end of data in writeBuf.
 	frameType int  // type of the current frame.
+	deadline  time.Time
 	err       error
+
+	compressed bool  // whether the message is compressed, for tracing
+	wireSize   int64 // payload bytes flushed, for tracing and progress
+
+	messageType int   // for progress
+	total       int64 // payload size for progress, or -1 if not known
+
+	compressMemory int64 // accounted memory of the compressor
 }
 
 func (w *messageWriter) endMessage(err error) error {
@@ -553,7 +684,10 @@ func (w *messageWriter) endMessage(err error) error {
 	c := w.c
 	w.err = err
 	c.writer = nil
+	c.writeBuffered.Store(0)
+	c.addMemory(memCompression, -w.compressMemory)
 	if c.writePool != nil {
+		c.addMemory(memWriteBuffer, -int64(len(c.writeBuf)))
 		c.writePool.Put(writePoolData{buf: c.writeBuf})
 		c.writeBuf = nil
 	}
@@ -565,78 +699,51 @@ func (w *messageWriter) endMessage(err error) error {
 func (w *messageWriter) flushFrame(final bool, extra []byte) error {
 	c := w.c
 	length := w.pos - maxFrameHeaderSize + len(extra)
+	w.wireSize += int64(length)
 
 	// Check for invalid control frames.
 	if isControl(w.frameType) &&
 		(!final || length > maxControlFramePayloadSize) {
 		return w.endMessage(errInvalidControlFrame)
 	}
-
-	b0 := byte(w.frameType)
-	if final {
-		b0 |= finalBit
-	}
-	if w.compress {
-		b0 |= rsv1Bit
-	}
-	w.compress = false
-
-	b1 := byte(0)
-	if !c.isServer {
-		b1 |= maskBit
-	}
-
-	// Assume that the frame starts at beginning of c.writeBuf.
-	framePos := 0
-	if c.isServer {
-		// Adjust up if mask not included in the header.
-		framePos = 4
+	if c.flow != nil && !isControl(w.frameType) {
+		return w.flushFlowFrames(final, extra)
 	}
 
-	switch {
-	case length >= 65536:
-		c.writeBuf[framePos] = b0
-		c.writeBuf[framePos+1] = b1 | 127
-		binary.BigEndian.PutUint64(c.writeBuf[framePos+2:], uint64(length))
-	case length > 125:
-		framePos += 6
-		c.writeBuf[framePos] = b0
-		c.writeBuf[framePos+1] = b1 | 126
-		binary.BigEndian.PutUint16(c.writeBuf[framePos+2:], uint16(length))
-	default:
-		framePos += 8
-		c.writeBuf[framePos] = b0
-		c.writeBuf[framePos+1] = b1 | byte(length)
+	h := wsframe.Header{
+		Fin:    final,
+		Rsv1:   w.compress,
+		Opcode: wsframe.Opcode(w.frameType),
+		Masked: !c.isServer,
+		Length: int64(length),
 	}
+	w.compress = false
 
-	if !c.isServer {
-		key := newMaskKey()
-		copy(c.writeBuf[maxFrameHeaderSize-4:], key[:])
-		maskBytes(key, 0, c.writeBuf[maxFrameHeaderSize:w.pos])
+	if h.Masked {
+		h.Key = c.newMaskKey()
+		maskBytes(h.Key, 0, c.writeBuf[maxFrameHeaderSize:w.pos])
 		if len(extra) > 0 {
 			return w.endMessage(c.writeFatal(errors.New("websocket: internal error, extra used in client mode")))
 		}
 	}
 
+	// The header ends at the start of the buffered payload.
+	framePos := maxFrameHeaderSize - wsframe.HeaderSize(h.Length, h.Masked)
+	wsframe.AppendHeader(c.writeBuf[framePos:framePos], h)
+
 	// Write the buffers to the connection with best-effort detection of
 	// concurrent writes. See the concurrency section in the package
 	// documentation for more info.
 
-	if c.isWriting {
-		panic("concurrent write to websocket connection")
-	}
-	c.isWriting = true
-
-	err := c.write(w.frameType, c.writeDeadline, c.writeBuf[framePos:w.pos], extra)
-
-	if !c.isWriting {
-		panic("concurrent write to websocket connection")
-	}
-	c.isWriting = false
+	c.writeBuffered.Store(0)
+	c.beginWrite()
+	err := c.write(w.frameType, w.deadline, c.writeBuf[framePos:w.pos], extra)
+	c.endWrite()
 
 	if err != nil {
 		return w.endMessage(err)
 	}
+	w.progressed(length)
 
 	if final {
 		_ = w.endMessage(errWriteClosed)
@@ -667,6 +774,8 @@ func (w *messageWriter) Write(p []byte) (int, error) {
 	if w.err != nil {
 		return 0, w.err
 	}
+	w.c.beginCall()
+	defer w.c.endCall()
 
 	if len(p) > 2*len(w.c.writeBuf) && w.c.isServer {
 		// Don't buffer large messages.
@@ -687,6 +796,7 @@ func (w *messageWriter) Write(p []byte) (int, error) {
 		w.pos += n
 		p = p[n:]
 	}
+	w.c.writeBuffered.Store(int64(w.pos - maxFrameHeaderSize))
 	return nn, nil
 }
 
@@ -694,6 +804,8 @@ func (w *messageWriter) WriteString(p string) (int, error) {
 	if w.err != nil {
 		return 0, w.err
 	}
+	w.c.beginCall()
+	defer w.c.endCall()
 
 	nn := len(p)
 	for len(p) > 0 {
@@ -705,6 +817,7 @@ func (w *messageWriter) WriteString(p string) (int, error) {
 		w.pos += n
 		p = p[n:]
 	}
+	w.c.writeBuffered.Store(int64(w.pos - maxFrameHeaderSize))
 	return nn, nil
 }
 
@@ -712,6 +825,8 @@ func (w *messageWriter) ReadFrom(r io.Reader) (nn int64, err error) {
 	if w.err != nil {
 		return 0, w.err
 	}
+	w.c.beginCall()
+	defer w.c.endCall()
 	for {
 		if w.pos == len(w.c.writeBuf) {
 			err = w.flushFrame(false, nil)
@@ -723,6 +838,7 @@ func (w *messageWriter) ReadFrom(r io.Reader) (nn int64, err error) {
 		n, err = r.Read(w.c.writeBuf[w.pos:])
 		w.pos += n
 		nn += int64(n)
+		w.c.writeBuffered.Store(int64(w.pos - maxFrameHeaderSize))
 		if err != nil {
 			if err == io.EOF {
 				err = nil
@@ -737,58 +853,176 @@ func (w *messageWriter) Close() error {
 	if w.err != nil {
 		return w.err
 	}
+	w.c.beginCall()
+	defer w.c.endCall()
 	return w.flushFrame(true, nil)
 }
 
+// writeCompression reports whether a message of the type and size, or -1
+// if the size is not known, is compressed.
+func (c *Conn) writeCompression(messageType int, size int) bool {
+	return c.newCompressionWriter != nil && c.enableWriteCompression && c.compressParams.compressible() &&
+		isData(messageType) && (c.compressionPolicy == nil || c.compressionPolicy(messageType, size))
+}
+
+// writeCompressionLevel returns the compression level of a compressed data
+// message.
+func (c *Conn) writeCompressionLevel(messageType int, size int) int {
+	if c.compressionLevelPolicy != nil {
+		if level := c.compressionLevelPolicy(messageType, size); isValidCompressionLevel(level) {
+			return level
+		}
+	}
+	return c.compressionLevel
+}
+
 // WritePreparedMessage writes prepared message into connection.
 func (c *Conn) WritePreparedMessage(pm *PreparedMessage) error {
+	if c.transformsOutbound() && isData(pm.messageType) {
+		return errPreparedTransform
+	}
+	compress := c.writeCompression(pm.messageType, len(pm.data))
+	if compress && !c.compressParams.shareable() {
+		// The compressed message depends on the previous messages.
+		err := c.writeMessage(pm.messageType, pm.data)
+		if err == nil {
+			c.teeWrite(pm.messageType, pm.data)
+		}
+		return err
+	}
+	level := c.compressionLevel
+	if compress {
+		level = c.writeCompressionLevel(pm.messageType, len(pm.data))
+	}
 	frameType, frameData, err := pm.frame(prepareKey{
 		isServer:         c.isServer,
-		compress:         c.newCompressionWriter != nil && c.enableWriteCompression && isData(pm.messageType),
-		compressionLevel: c.compressionLevel,
+		compress:         compress,
+		compressionLevel: level,
+		params:           c.compressParams,
 	})
 	if err != nil {
 		return err
 	}
-	if c.isWriting {
-		panic("concurrent write to websocket connection")
+	c.beginWrite()
+	err = c.write(frameType, c.messageDeadline(), frameData, nil)
+	c.endWrite()
+	if err == nil && c.trace != nil && c.trace.MessageWritten != nil && isData(frameType) {
+		c.trace.MessageWritten(c, MessageInfo{
+			Type:       frameType,
+			Size:       int64(len(pm.data)),
+			WireSize:   framesPayloadLen(frameData),
+			Compressed: frameData[0]&rsv1Bit != 0,
+		})
 	}
-	c.isWriting = true
-	err = c.write(frameType, c.writeDeadline, frameData, nil)
-	if !c.isWriting {
-		panic("concurrent write to websocket connection")
+	if err == nil {
+		c.teeWrite(pm.messageType, pm.data)
 	}
-	c.isWriting = false
 	return err
 }
 
 // WriteMessage is a helper method for getting a writer using NextWriter,
 // writing the message and closing the writer.
 func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	payload := data
+	if c.transformsOutbound() && isData(messageType) {
+		var ok bool
+		var err error
+		if payload, ok, err = c.transformOutbound(messageType, data); !ok || err != nil {
+			return err
+		}
+	}
+	err := c.writeMessage(messageType, payload)
+	if err == nil {
+		c.teeWrite(messageType, data)
+	}
+	return err
+}
 
-	if c.isServer && (c.newCompressionWriter == nil || !c.enableWriteCompression) {
+func (c *Conn) writeMessage(messageType int, data []byte) error {
+	if c.isServer && !c.writeCompression(messageType, len(data)) {
 		// Fast path with no allocations and single frame.
 
 		var mw messageWriter
 		if err := c.beginMessage(&mw, messageType); err != nil {
 			return err
 		}
+		size := len(data)
+		mw.total = int64(size)
+		c.beginCall()
 		n := copy(c.writeBuf[mw.pos:], data)
 		mw.pos += n
 		data = data[n:]
-		return mw.flushFrame(true, data)
+		err := mw.flushFrame(true, data)
+		c.endCall()
+		if err == nil && c.trace != nil && c.trace.MessageWritten != nil && isData(messageType) {
+			c.trace.MessageWritten(c, MessageInfo{Type: messageType, Size: int64(size), WireSize: int64(size)})
+		}
+		return err
 	}
 
-	w, err := c.NextWriter(messageType)
+	w, err := c.nextWriter(messageType, len(data))
 	if err != nil {
 		return err
 	}
+	mw, _ := w.(*messageWriter)
+	if tw, ok := w.(*tracedWriter); ok {
+		mw = tw.mw
+	}
+	if mw != nil && !mw.compressed {
+		mw.total = int64(len(data))
+	}
 	if _, err = w.Write(data); err != nil {
 		return err
 	}
 	return w.Close()
 }
 
+// WriteMessageFrom writes a data message with the payload read from r until
+// EOF and returns the number of payload bytes read. The payload is streamed
+// to the network in frames of the write buffer size, compressed when
+// compression is enabled, so the whole payload is never in memory, except
+// for connections with a payload transform or outbound middleware, which
+// see whole messages.
+//
+// A message cannot be abandoned once its first frame is sent. If reading
+// from r fails, WriteMessageFrom sends a close message with the code
+// CloseInternalServerErr, which ends the partial message, and returns the
+// read error. Later writes return ErrCloseSent.
+func (c *Conn) WriteMessageFrom(messageType int, r io.Reader) (int64, error) {
+	if !isData(messageType) {
+		return 0, errBadWriteOpCode
+	}
+	w, err := c.NextWriter(messageType)
+	if err != nil {
+		return 0, err
+	}
+	er := &errorReader{r: r}
+	n, err := io.Copy(w, er)
+	if er.err != nil {
+		_ = c.WriteControl(CloseMessage, FormatCloseMessage(CloseInternalServerErr, ""), c.clock.Now().Add(writeWait))
+		_ = w.Close()
+		return n, fmt.Errorf("websocket: read message payload: %w", er.err)
+	}
+	if err != nil {
+		return n, err
+	}
+	return n, w.Close()
+}
+
+// errorReader records the error other than io.EOF returned by a reader.
+type errorReader struct {
+	r   io.Reader
+	err error
+}
+
+func (r *errorReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
+
 // SetWriteDeadline sets the write deadline on the underlying network
 // connection. After a write has timed out, the websocket state is corrupt and
 // all future writes will return an error. A zero value for t means writes will
@@ -798,142 +1032,257 @@ func (c *Conn) SetWriteDeadline(t time.Time) error {
 	return nil
 }
 
+// SetDefaultWriteTimeout sets the time allowed to write a message when no
+// write deadline is set with SetWriteDeadline. The timeout starts when the
+// application begins the message with WriteMessage, WriteJSON,
+// WritePreparedMessage or NextWriter, and when WriteControl is called with
+// a zero deadline. A zero value for d means writes without a deadline do
+// not time out.
+func (c *Conn) SetDefaultWriteTimeout(d time.Duration) {
+	c.writeTimeout = d
+}
+
+// BufferedAmount returns the number of bytes accepted for sending but not yet
+// written to the network connection: the messages in a SendQueue, the
+// buffered part of the message being written, the frames blocked in a write
+// to the network connection and, for connections dialed in a browser, the
+// browser's WebSocket.bufferedAmount. Frame headers are included. Data
+// buffered by the compressor of a compressed message is not included.
+// Applications can compare BufferedAmount to a threshold to pause writing to
+// a slow peer.
+//
+// BufferedAmount is safe to call concurrently with the write methods.
+func (c *Conn) BufferedAmount() int {
+	n := c.writeQueued.Load() + c.writeBuffered.Load() + c.writePending.Load()
+	if b, ok := c.conn.(interface{ BufferedAmount() int }); ok {
+		n += int64(b.BufferedAmount())
+	}
+	if n > int64(maxInt) {
+		return maxInt
+	}
+	return int(n)
+}
+
+// messageDeadline returns the write deadline for a new message.
+func (c *Conn) messageDeadline() time.Time {
+	if c.writeDeadline.IsZero() && c.writeTimeout > 0 {
+		return c.clock.Now().Add(c.writeTimeout)
+	}
+	return c.writeDeadline
+}
+
 // Read methods
 
+// frameHeaderSize returns the size of a frame header from the second byte of
+// the header.
+func frameHeaderSize(b1 byte) int {
+	n := 2
+	switch b1 & 0x7f {
+	case 126:
+		n += 2
+	case 127:
+		n += 8
+	}
+	if b1&maskBit != 0 {
+		n += 4
+	}
+	return n
+}
+
 func (c *Conn) advanceFrame() (int, error) {
 	// 1. Skip remainder of previous frame.
 
-	if c.readRemaining > 0 {
+	if c.readInflated != nil {
+		c.readInflated = nil
+	} else if c.readRemaining > 0 {
 		if _, err := io.CopyN(io.Discard, c.br, c.readRemaining); err != nil {
 			return noFrame, err
 		}
 	}
 
-	// 2. Read and parse first two bytes of frame header.
-	// To aid debugging, collect and report all errors in the first two bytes
-	// of the header.
+	if c.flow != nil {
+		if err := c.flowGrant(); err != nil {
+			return noFrame, err
+		}
+	}
 
-	var errors []string
+	// 2. Read and parse the frame header. The first two bytes of the header
+	// give its size.
 
-	p, err := c.read(2)
+	n := 2
+	if p, err := c.br.Peek(2); err == nil {
+		n = frameHeaderSize(p[1])
+	}
+	p, err := c.read(n)
 	if err != nil {
 		return noFrame, err
 	}
+	if c.readActivity > 0 {
+		if err := c.extendReadDeadline(); err != nil {
+			return noFrame, err
+		}
+	}
+
+	h, _, err := wsframe.ParseHeader(p)
+	if err != nil {
+		// The header is complete, so the most significant bit of a 64-bit
+		// length is set.
+		return noFrame, c.reportViolation(ViolationReadLimit, ErrReadLimit)
+	}
+	// Keep the header for the dump because reading the payload can
+	// overwrite p.
+	var hdr [wsframe.MaxHeaderSize]byte
+	hdrLen := copy(hdr[:], p)
+
+	frameType := int(h.Opcode)
+	final := h.Fin
+	_ = c.setReadRemaining(h.Length) // will not fail because argument is >= 0
+
+	// 3. Check the header. To aid debugging, collect and report all errors
+	// in the header.
 
-	frameType := int(p[0] & 0xf)
-	final := p[0]&finalBit != 0
-	rsv1 := p[0]&rsv1Bit != 0
-	rsv2 := p[0]&rsv2Bit != 0
-	rsv3 := p[0]&rsv3Bit != 0
-	mask := p[1]&maskBit != 0
-	_ = c.setReadRemaining(int64(p[1] & 0x7f)) // will not fail because argument is >= 0
+	var errors []string
+	var violation ViolationKind // kind of the first error
+	fail := func(kind ViolationKind, message string) {
+		if len(errors) == 0 {
+			violation = kind
+		}
+		errors = append(errors, message)
+	}
 
 	c.readDecompress = false
-	if rsv1 {
+	c.readInflate = false
+	if h.Rsv1 {
 		if c.newDecompressionReader != nil {
 			c.readDecompress = true
-		} else {
-			errors = append(errors, "RSV1 set")
+		} else if c.deflateFrame != nil {
+			c.readInflate = true
+		} else if c.strictness&LenientReservedBits == 0 {
+			fail(ViolationReservedBits, "RSV1 set")
 		}
 	}
 
-	if rsv2 {
-		errors = append(errors, "RSV2 set")
+	if h.Rsv2 && c.strictness&LenientReservedBits == 0 {
+		fail(ViolationReservedBits, "RSV2 set")
 	}
 
-	if rsv3 {
-		errors = append(errors, "RSV3 set")
+	if h.Rsv3 && c.strictness&LenientReservedBits == 0 {
+		fail(ViolationReservedBits, "RSV3 set")
 	}
 
+	skip := false // skip a frame with a reserved opcode
+
 	switch frameType {
 	case CloseMessage, PingMessage, PongMessage:
 		if c.readRemaining > maxControlFramePayloadSize {
-			errors = append(errors, "len > 125 for control")
+			fail(ViolationControlFrame, "len > 125 for control")
 		}
 		if !final {
-			errors = append(errors, "FIN not set on control")
+			fail(ViolationControlFrame, "FIN not set on control")
 		}
 	case TextMessage, BinaryMessage:
 		if !c.readFinal {
-			errors = append(errors, "data before FIN")
+			fail(ViolationFragmentation, "data before FIN")
 		}
 		c.readFinal = final
 	case continuationFrame:
 		if c.readFinal {
-			errors = append(errors, "continuation after FIN")
+			fail(ViolationFragmentation, "continuation after FIN")
 		}
 		c.readFinal = final
 	default:
-		errors = append(errors, "bad opcode "+strconv.Itoa(frameType))
+		if frameType == creditFrame && c.flow != nil {
+			if c.readRemaining != 4 || !final {
+				fail(ViolationFlowControl, "bad credit frame")
+			}
+		} else if c.strictness&LenientOpcodes != 0 {
+			skip = true
+		} else {
+			fail(ViolationOpcode, "bad opcode "+strconv.Itoa(frameType))
+		}
 	}
 
-	if mask != c.isServer {
-		errors = append(errors, "bad MASK")
+	if h.Masked != c.isServer && c.strictness&LenientMask == 0 &&
+		!(c.isServer && c.useQuirk(QuirkUnmaskedFrames)) {
+		fail(ViolationMask, "bad MASK")
 	}
 
 	if len(errors) > 0 {
-		return noFrame, c.handleProtocolError(strings.Join(errors, ", "))
-	}
-
-	// 3. Read and parse frame length as per
-	// https://tools.ietf.org/html/rfc6455#section-5.2
-	//
-	// The length of the "Payload data", in bytes: if 0-125, that is the payload
-	// length.
-	// - If 126, the following 2 bytes interpreted as a 16-bit unsigned
-	// integer are the payload length.
-	// - If 127, the following 8 bytes interpreted as
-	// a 64-bit unsigned integer (the most significant bit MUST be 0) are the
-	// payload length. Multibyte length quantities are expressed in network byte
-	// order.
-
-	switch c.readRemaining {
-	case 126:
-		p, err := c.read(2)
-		if err != nil {
-			return noFrame, err
-		}
-
-		if err := c.setReadRemaining(int64(binary.BigEndian.Uint16(p))); err != nil {
-			return noFrame, err
-		}
-	case 127:
-		p, err := c.read(8)
-		if err != nil {
-			return noFrame, err
-		}
-
-		if err := c.setReadRemaining(int64(binary.BigEndian.Uint64(p))); err != nil {
-			return noFrame, err
-		}
+		return noFrame, c.handleProtocolError(violation, strings.Join(errors, ", "))
 	}
+	c.readMasked = h.Masked
 
 	// 4. Handle frame masking.
 
-	if mask {
+	if h.Masked {
 		c.readMaskPos = 0
-		p, err := c.read(len(c.readMaskKey))
-		if err != nil {
+		c.readMaskKey = h.Key
+	}
+
+	if d := c.dump.Load(); d != nil {
+		c.dumpReadFrame(d, hdr[:hdrLen])
+	}
+
+	if skip {
+		if _, err := io.CopyN(io.Discard, c.br, c.readRemaining); err != nil {
 			return noFrame, err
 		}
-		copy(c.readMaskKey[:], p)
+		_ = c.setReadRemaining(0) // will not fail because argument is >= 0
+		return noFrame, nil
 	}
 
 	// 5. For text and binary messages, enforce read limit and return.
 
 	if frameType == continuationFrame || frameType == TextMessage || frameType == BinaryMessage {
+		c.lastData.Store(c.clock.Now().UnixNano())
+
+		if c.flow != nil {
+			if err := c.flowReceive(); err != nil {
+				return noFrame, err
+			}
+		}
+
+		if c.readRateLimiter != nil || c.readDropping {
+			skip, err := c.limitRead(frameType)
+			if err != nil {
+				return noFrame, err
+			}
+			if skip {
+				return noFrame, nil
+			}
+		}
 
 		c.readLength += c.readRemaining
 		// Don't allow readLength to overflow in the presence of a large readRemaining
 		// counter.
 		if c.readLength < 0 {
-			return noFrame, ErrReadLimit
+			return noFrame, c.reportViolation(ViolationReadLimit, ErrReadLimit)
 		}
 
 		if c.readLimit > 0 && c.readLength > c.readLimit {
-			// Make a best effort to send a close message describing the problem.
-			_ = c.WriteControl(CloseMessage, FormatCloseMessage(CloseMessageTooBig, ""), time.Now().Add(writeWait))
-			return noFrame, ErrReadLimit
+			action := ReadLimitClose
+			if c.handleReadLimit != nil {
+				action = c.handleReadLimit(c.readLength)
+			}
+			switch action {
+			case ReadLimitAllow:
+			case ReadLimitSkip:
+				return noFrame, c.skipMessage(frameType)
+			default:
+				// Make a best effort to send a close message describing the problem.
+				_ = c.WriteControl(CloseMessage, FormatCloseMessage(CloseMessageTooBig, ""), c.clock.Now().Add(writeWait))
+				var err error = ErrReadLimit
+				if c.handleReadLimit != nil {
+					err = &ReadLimitError{Limit: c.readLimit, Size: c.readLength}
+				}
+				return noFrame, c.reportViolation(ViolationReadLimit, err)
+			}
+		}
+
+		if c.readInflate {
+			if err := c.inflateFrame(); err != nil {
+				return noFrame, err
+			}
 		}
 
 		return frameType, nil
@@ -948,7 +1297,7 @@ func (c *Conn) advanceFrame() (int, error) {
 		if err != nil {
 			return noFrame, err
 		}
-		if c.isServer {
+		if c.readMasked {
 			maskBytes(c.readMaskKey, 0, payload)
 		}
 	}
@@ -956,7 +1305,12 @@ func (c *Conn) advanceFrame() (int, error) {
 	// 7. Process control frame payload.
 
 	switch frameType {
+	case creditFrame:
+		if err := c.handleCredit(payload); err != nil {
+			return noFrame, err
+		}
 	case PongMessage:
+		c.lastPong.Store(c.clock.Now().UnixNano())
 		if err := c.handlePong(string(payload)); err != nil {
 			return noFrame, err
 		}
@@ -967,16 +1321,23 @@ func (c *Conn) advanceFrame() (int, error) {
 	case CloseMessage:
 		closeCode := CloseNoStatusReceived
 		closeText := ""
+		if len(payload) == 1 && !c.useQuirk(QuirkShortClose) {
+			return noFrame, c.handleProtocolError(ViolationCloseCode, "close payload of length 1")
+		}
 		if len(payload) >= 2 {
 			closeCode = int(binary.BigEndian.Uint16(payload))
 			if !isValidReceivedCloseCode(closeCode) {
-				return noFrame, c.handleProtocolError("bad close code " + strconv.Itoa(closeCode))
+				return noFrame, c.handleProtocolError(ViolationCloseCode, "bad close code "+strconv.Itoa(closeCode))
 			}
-			closeText = string(payload[2:])
-			if !utf8.ValidString(closeText) {
-				return noFrame, c.handleProtocolError("invalid utf8 payload in close frame")
+			closeText = string(c.trimCloseText(payload[2:]))
+			if !utf8.ValidString(closeText) && c.strictness&LenientCloseText == 0 {
+				return noFrame, c.handleProtocolError(ViolationCloseText, "invalid utf8 payload in close frame")
 			}
 		}
+		c.recordPeerClose(closeCode)
+		if c.trace != nil && c.trace.CloseReceived != nil {
+			c.trace.CloseReceived(c, closeCode, closeText)
+		}
 		if err := c.handleClose(closeCode, closeText); err != nil {
 			return noFrame, err
 		}
@@ -986,14 +1347,16 @@ func (c *Conn) advanceFrame() (int, error) {
 	return frameType, nil
 }
 
-func (c *Conn) handleProtocolError(message string) error {
+func (c *Conn) handleProtocolError(kind ViolationKind, message string) error {
+	c.recordProtocolError()
 	data := FormatCloseMessage(CloseProtocolError, message)
-	if len(data) > maxControlFramePayloadSize {
-		data = data[:maxControlFramePayloadSize]
-	}
 	// Make a best effor to send a close message describing the problem.
-	_ = c.WriteControl(CloseMessage, data, time.Now().Add(writeWait))
-	return errors.New("websocket: " + message)
+	_ = c.WriteControl(CloseMessage, data, c.clock.Now().Add(writeWait))
+	err := &ProtocolError{Kind: kind, Message: message}
+	if c.trace != nil && c.trace.ProtocolError != nil {
+		c.trace.ProtocolError(c, err)
+	}
+	return c.reportViolation(kind, err)
 }
 
 // NextReader returns the next data message received from the peer. The
@@ -1012,6 +1375,12 @@ func (c *Conn) NextReader() (messageType int, r io.Reader, err error) {
 		c.reader.Close()
 		c.reader = nil
 	}
+	c.releaseReadCompression()
+
+	if c.tracedReader != nil {
+		c.tracedReader.finish()
+		c.tracedReader = nil
+	}
 
 	c.messageReader = nil
 	c.readLength = 0
@@ -1019,17 +1388,48 @@ func (c *Conn) NextReader() (messageType int, r io.Reader, err error) {
 	for c.readErr == nil {
 		frameType, err := c.advanceFrame()
 		if err != nil {
-			c.readErr = err
+			c.readErr = c.contextError(err)
 			break
 		}
 
 		if frameType == TextMessage || frameType == BinaryMessage {
-			c.messageReader = &messageReader{c}
+			c.messageReader = &messageReader{c: c, messageType: frameType, total: -1}
+			if c.readFinal && !c.readInflate {
+				c.messageReader.total = c.readRemaining
+			}
 			c.reader = c.messageReader
 			if c.readDecompress {
 				c.reader = c.newDecompressionReader(c.reader)
+				c.readCompression = true
+				c.addMemory(memCompression, flateReaderMemory)
 			}
-			return frameType, c.reader, nil
+			if frameType == TextMessage && c.strictness&StrictTextUTF8 != 0 {
+				c.reader = &validUTF8Reader{c: c, r: c.reader}
+			}
+			var r io.Reader = c.reader
+			if (c.trace != nil && c.trace.MessageRead != nil) || c.observer != nil {
+				info := MessageInfo{Type: frameType, Compressed: c.readDecompress}
+				c.tracedReader = &tracedReader{c: c, mr: c.messageReader, r: c.reader, info: info}
+				r = c.tracedReader
+			}
+			if c.transformsInbound() {
+				r, err = c.transformInbound(frameType, r)
+				if err == errMessageDropped {
+					if c.tracedReader != nil {
+						c.tracedReader.finish()
+						c.tracedReader = nil
+					}
+					continue
+				}
+				if err != nil {
+					c.readErr = err
+					break
+				}
+			}
+			if t := c.teeReads.Load(); t != nil {
+				r = &teeReader{r: r, w: t.w}
+			}
+			return frameType, r, nil
 		}
 	}
 
@@ -1037,6 +1437,13 @@ func (c *Conn) NextReader() (messageType int, r io.Reader, err error) {
 	// tight loop on connection failure. To help application developers detect
 	// this error, panic on repeated reads to the failed connection.
 	c.readErrCount++
+	if c.readErrCount == 1 {
+		c.recordReadError(c.readErr)
+		c.observeReadError(c.readErr)
+		if c.flow != nil {
+			c.flow.close()
+		}
+	}
 	if c.readErrCount >= 1000 {
 		panic("repeated read on failed websocket connection")
 	}
@@ -1044,7 +1451,12 @@ func (c *Conn) NextReader() (messageType int, r io.Reader, err error) {
 	return noFrame, nil, c.readErr
 }
 
-type messageReader struct{ c *Conn }
+type messageReader struct {
+	c           *Conn
+	messageType int   // for progress
+	transferred int64 // payload bytes read, for progress
+	total       int64 // payload size for progress, or -1 if not known
+}
 
 func (r *messageReader) Read(b []byte) (int, error) {
 	c := r.c
@@ -1058,9 +1470,16 @@ func (r *messageReader) Read(b []byte) (int, error) {
 			if int64(len(b)) > c.readRemaining {
 				b = b[:c.readRemaining]
 			}
+			if c.readInflated != nil {
+				n := copy(b, c.readInflated)
+				c.readInflated = c.readInflated[n:]
+				_ = c.setReadRemaining(c.readRemaining - int64(n)) // n <= c.readRemaining
+				r.progressed(n)
+				return n, nil
+			}
 			n, err := c.br.Read(b)
 			c.readErr = err
-			if c.isServer {
+			if c.readMasked {
 				c.readMaskPos = maskBytes(c.readMaskKey, c.readMaskPos, b[:n])
 			}
 			rem := c.readRemaining
@@ -1069,18 +1488,27 @@ func (r *messageReader) Read(b []byte) (int, error) {
 			if c.readRemaining > 0 && c.readErr == io.EOF {
 				c.readErr = errUnexpectedEOF
 			}
+			c.readErr = c.contextError(c.readErr)
+			r.progressed(n)
 			return n, c.readErr
 		}
 
 		if c.readFinal {
 			c.messageReader = nil
+			c.releaseReadCompression()
 			return 0, io.EOF
 		}
 
 		frameType, err := c.advanceFrame()
 		switch {
 		case err != nil:
-			c.readErr = err
+			if e, ok := err.(*ReadLimitError); ok && e.Skipped {
+				// The rest of the message is skipped. The connection
+				// remains usable.
+				c.messageReader = nil
+				return 0, err
+			}
+			c.readErr = c.contextError(err)
 		case frameType == TextMessage || frameType == BinaryMessage:
 			c.readErr = errors.New("websocket: internal error, unexpected text or binary in Reader")
 		}
@@ -1097,29 +1525,157 @@ func (r *messageReader) Close() error {
 	return nil
 }
 
+// NextMessage is like NextReader and also returns the size of the message
+// payload when the size is known before reading the message. The size is
+// known when the message is a single frame and is not compressed or
+// transformed. Otherwise, size is -1. Applications can use the size to
+// allocate a buffer for the message or to reject a large message before
+// reading it.
+func (c *Conn) NextMessage() (messageType int, size int64, r io.Reader, err error) {
+	messageType, r, err = c.NextReader()
+	if err != nil {
+		return messageType, -1, nil, err
+	}
+	size = -1
+	if c.readFinal && !c.readDecompress && !c.readInflate && !c.transformsInbound() {
+		size = c.readRemaining
+	}
+	return messageType, size, r, nil
+}
+
 // ReadMessage is a helper method for getting a reader using NextReader and
-// reading from that reader to a buffer.
+// reading from that reader to a buffer. If the message does not fit in a
+// byte slice, ReadMessage returns ErrMessageTooLarge and the rest of the
+// message is discarded by the next read.
 func (c *Conn) ReadMessage() (messageType int, p []byte, err error) {
-	var r io.Reader
-	messageType, r, err = c.NextReader()
+	var (
+		size int64
+		r    io.Reader
+	)
+	messageType, size, r, err = c.NextMessage()
 	if err != nil {
 		return messageType, nil, err
 	}
-	p, err = io.ReadAll(r)
+	if size > int64(maxInt) {
+		return messageType, nil, ErrMessageTooLarge
+	}
+	p, err = readAllMax(r, maxInt)
 	return messageType, p, err
 }
 
+// readAllMax is io.ReadAll for a reader of at most max bytes. readAllMax
+// returns ErrMessageTooLarge when r has more bytes.
+func readAllMax(r io.Reader, max int) ([]byte, error) {
+	p, err := io.ReadAll(io.LimitReader(r, int64(max)))
+	if err != nil || len(p) < max {
+		return p, err
+	}
+	var b [1]byte
+	if n, _ := io.ReadFull(r, b[:]); n > 0 {
+		return nil, ErrMessageTooLarge
+	}
+	return p, nil
+}
+
+// ReadMessageChunks reads the next data message and calls f with the
+// payload in consecutive chunks of len(buf) bytes; only the last chunk can
+// be shorter. The chunks are read into buf, so that a message of any size,
+// including messages that do not fit in a byte slice, is delivered without
+// buffering it whole, and f must not retain a chunk after it returns. If
+// buf is empty, a buffer of 32 KiB is used. f is not called for an empty
+// message. ReadMessageChunks returns the message type and the number of
+// payload bytes delivered to f.
+//
+// If f returns an error, ReadMessageChunks returns the error and the rest of
+// the message is discarded by the next read.
+func (c *Conn) ReadMessageChunks(buf []byte, f func(chunk []byte) error) (messageType int, n int64, err error) {
+	var r io.Reader
+	messageType, r, err = c.NextReader()
+	if err != nil {
+		return messageType, 0, err
+	}
+	if len(buf) == 0 {
+		buf = make([]byte, 32*1024)
+	}
+	for {
+		m, err := io.ReadFull(r, buf)
+		if m > 0 {
+			if ferr := f(buf[:m]); ferr != nil {
+				return messageType, n, ferr
+			}
+			n += int64(m)
+		}
+		switch err {
+		case nil:
+		case io.EOF, io.ErrUnexpectedEOF:
+			return messageType, n, nil
+		default:
+			return messageType, n, err
+		}
+	}
+}
+
+// ReadMessageTo copies the next data message to w and returns the message
+// type and the number of payload bytes written to w. The message is copied
+// as it arrives, decompressed when compressed, so the whole payload is never
+// in memory, except for connections with a payload transform or inbound
+// middleware, which see whole messages. The read limit and the read limit
+// handler apply as for NextReader.
+//
+// If writing to w fails, ReadMessageTo returns the write error and the rest
+// of the message is discarded by the next read.
+func (c *Conn) ReadMessageTo(w io.Writer) (messageType int, n int64, err error) {
+	var r io.Reader
+	messageType, r, err = c.NextReader()
+	if err != nil {
+		return messageType, 0, err
+	}
+	n, err = io.Copy(w, r)
+	return messageType, n, err
+}
+
 // SetReadDeadline sets the read deadline on the underlying network connection.
 // After a read has timed out, the websocket connection state is corrupt and
 // all future reads will return an error. A zero value for t means reads will
 // not time out.
 func (c *Conn) SetReadDeadline(t time.Time) error {
+	var ns int64
+	if !t.IsZero() {
+		ns = t.UnixNano()
+	}
+	c.readDeadline.Store(ns)
 	return c.conn.SetReadDeadline(t)
 }
 
+// SetReadActivityTimeout extends the read deadline when a frame arrives
+// from the peer. On every frame, including control frames and the frames of
+// a fragmented message, the read deadline is moved to d after the arrival
+// of the frame unless the deadline is already later. Reads without a
+// deadline are not changed. Use SetReadActivityTimeout to keep a deadline
+// set with SetReadDeadline before a long message from tripping while the
+// message arrives. A zero value for d turns the extension off.
+func (c *Conn) SetReadActivityTimeout(d time.Duration) {
+	c.readActivity = d
+}
+
+// extendReadDeadline moves the read deadline as described in
+// SetReadActivityTimeout.
+func (c *Conn) extendReadDeadline() error {
+	deadline := c.readDeadline.Load()
+	if deadline == 0 {
+		return nil
+	}
+	t := c.clock.Now().Add(c.readActivity)
+	if t.UnixNano() <= deadline {
+		return nil
+	}
+	return c.SetReadDeadline(t)
+}
+
 // SetReadLimit sets the maximum size in bytes for a message read from the peer. If a
 // message exceeds the limit, the connection sends a close message to the peer
-// and returns ErrReadLimit to the application.
+// and returns ErrReadLimit to the application. Use SetReadLimitHandler to
+// skip or allow such messages instead.
 func (c *Conn) SetReadLimit(limit int64) {
 	c.readLimit = limit
 }
@@ -1148,7 +1704,7 @@ func (c *Conn) SetCloseHandler(h func(code int, text string) error) {
 		h = func(code int, text string) error {
 			message := FormatCloseMessage(code, "")
 			// Make a best effor to send the close message.
-			_ = c.WriteControl(CloseMessage, message, time.Now().Add(writeWait))
+			_ = c.WriteControl(CloseMessage, message, c.clock.Now().Add(writeWait))
 			return nil
 		}
 	}
@@ -1171,7 +1727,7 @@ func (c *Conn) SetPingHandler(h func(appData string) error) {
 	if h == nil {
 		h = func(message string) error {
 			// Make a best effort to send the pong message.
-			_ = c.WriteControl(PongMessage, []byte(message), time.Now().Add(writeWait))
+			_ = c.WriteControl(PongMessage, []byte(message), c.clock.Now().Add(writeWait))
 			return nil
 		}
 	}
@@ -1218,6 +1774,15 @@ func (c *Conn) EnableWriteCompression(enable bool) {
 	c.enableWriteCompression = enable
 }
 
+// SetCompressionPolicy sets the policy that selects the text and binary
+// messages that are compressed when write compression is enabled, for
+// example CompressTypes(TextMessage) to compress text messages only. A nil
+// policy compresses all messages. This function is a noop if compression
+// was not negotiated with the peer.
+func (c *Conn) SetCompressionPolicy(policy CompressionPolicy) {
+	c.compressionPolicy = policy
+}
+
 // SetCompressionLevel sets the flate compression level for subsequent text and
 // binary messages. This function is a noop if compression was not negotiated
 // with the peer. See the compress/flate package for a description of
@@ -1230,8 +1795,19 @@ func (c *Conn) SetCompressionLevel(level int) error {
 	return nil
 }
 
+// SetCompressionLevelPolicy sets the policy that selects the flate
+// compression level of each compressed text and binary message, overriding
+// the level set by SetCompressionLevel, to adapt the level at runtime. A nil
+// policy uses the level set by SetCompressionLevel. This function is a noop
+// if compression was not negotiated with the peer.
+func (c *Conn) SetCompressionLevelPolicy(policy CompressionLevelPolicy) {
+	c.compressionLevelPolicy = policy
+}
+
 // FormatCloseMessage formats closeCode and text as a WebSocket close message.
-// An empty message is returned for code CloseNoStatusReceived.
+// An empty message is returned for code CloseNoStatusReceived. Text longer
+// than the 123 bytes that fit in a control frame is truncated at a UTF-8
+// rune boundary, so that valid UTF-8 text stays valid.
 func FormatCloseMessage(closeCode int, text string) []byte {
 	if closeCode == CloseNoStatusReceived {
 		// Return empty message because it's illegal to send
@@ -1239,8 +1815,5 @@ func FormatCloseMessage(closeCode int, text string) []byte {
 		// checks for nil.
 		return []byte{}
 	}
-	buf := make([]byte, 2+len(text))
-	binary.BigEndian.PutUint16(buf, uint16(closeCode))
-	copy(buf[2:], text)
-	return buf
+	return wsframe.AppendClose(make([]byte, 0, 2+len(text)), closeCode, text)
 }
@@ -11,7 +11,9 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"os"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"testing/iotest"
@@ -417,6 +419,203 @@ func TestCloseFrameBeforeFinalMessageFrame(t *testing.T) {
 	}
 }
 
+func TestCloseWrite(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	if err := server.CloseWrite(CloseNormalClosure, "done"); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.WriteMessage(TextMessage, []byte("late")); err != ErrCloseSent {
+		t.Errorf("WriteMessage() error = %v, want %v", err, ErrCloseSent)
+	}
+	if err := server.CloseWrite(CloseNormalClosure, ""); err != ErrCloseSent {
+		t.Errorf("second CloseWrite() error = %v, want %v", err, ErrCloseSent)
+	}
+
+	// The server drains the messages sent before the peer's close.
+	client.WriteMessage(TextMessage, []byte("pending"))
+	if _, _, err := client.ReadMessage(); !IsCloseError(err, CloseNormalClosure) || err.(*CloseError).Text != "done" {
+		t.Errorf("client ReadMessage() error = %v, want close 1000 done", err)
+	}
+	if _, p, err := server.ReadMessage(); err != nil || string(p) != "pending" {
+		t.Errorf("ReadMessage() = %q, %v, want pending", p, err)
+	}
+	if _, _, err := server.ReadMessage(); !IsCloseError(err, CloseNormalClosure) {
+		t.Errorf("ReadMessage() error = %v, want close %d", err, CloseNormalClosure)
+	}
+}
+
+func TestWriteMessageFrom(t *testing.T) {
+	payload := bytes.Repeat([]byte("streamed payload "), 10000)
+	for _, compress := range []bool{false, true} {
+		client, server := (&PipeConfig{WriteBufferSize: 1024, EnableCompression: compress}).Pipe()
+		for _, c := range []*Conn{client, server} {
+			c.EnableWriteCompression(compress)
+			n, err := c.WriteMessageFrom(BinaryMessage, bytes.NewReader(payload))
+			if err != nil || n != int64(len(payload)) {
+				t.Fatalf("compress=%v: WriteMessageFrom() = %d, %v, want %d", compress, n, err, len(payload))
+			}
+		}
+		for _, c := range []*Conn{server, client} {
+			if _, p, err := c.ReadMessage(); err != nil || !bytes.Equal(p, payload) {
+				t.Errorf("compress=%v: ReadMessage() = %d bytes, %v, want %d bytes", compress, len(p), err, len(payload))
+			}
+		}
+		client.Close()
+		server.Close()
+	}
+
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	if _, err := client.WriteMessageFrom(PingMessage, strings.NewReader("")); err != errBadWriteOpCode {
+		t.Errorf("WriteMessageFrom(PingMessage) error = %v, want %v", err, errBadWriteOpCode)
+	}
+}
+
+func TestWriteMessageFromReadError(t *testing.T) {
+	client, server := (&PipeConfig{WriteBufferSize: 1024}).Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	errRead := errors.New("read error")
+	r := io.MultiReader(bytes.NewReader(make([]byte, 5000)), iotest.ErrReader(errRead))
+	if _, err := server.WriteMessageFrom(BinaryMessage, r); !errors.Is(err, errRead) {
+		t.Errorf("WriteMessageFrom() error = %v, want %v", err, errRead)
+	}
+	if err := server.WriteMessage(TextMessage, []byte("x")); err != ErrCloseSent {
+		t.Errorf("WriteMessage() error = %v, want %v", err, ErrCloseSent)
+	}
+	if _, _, err := client.ReadMessage(); !IsCloseError(err, CloseInternalServerErr) {
+		t.Errorf("ReadMessage() error = %v, want close %d", err, CloseInternalServerErr)
+	}
+}
+
+func TestReadMessageTo(t *testing.T) {
+	payload := bytes.Repeat([]byte("streamed payload "), 10000)
+	for _, compress := range []bool{false, true} {
+		client, server := (&PipeConfig{EnableCompression: compress}).Pipe()
+		client.WriteMessage(BinaryMessage, payload)
+		var buf bytes.Buffer
+		messageType, n, err := server.ReadMessageTo(&buf)
+		if err != nil || messageType != BinaryMessage || n != int64(len(payload)) || !bytes.Equal(buf.Bytes(), payload) {
+			t.Errorf("compress=%v: ReadMessageTo() = %d, %d, %v, want %d, %d", compress, messageType, n, err, BinaryMessage, len(payload))
+		}
+		client.Close()
+		server.Close()
+	}
+
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	server.SetReadLimit(100)
+	client.WriteMessage(BinaryMessage, payload)
+	if _, _, err := server.ReadMessageTo(io.Discard); !errors.Is(err, ErrReadLimit) {
+		t.Errorf("ReadMessageTo() error = %v, want %v", err, ErrReadLimit)
+	}
+}
+
+func TestReadMessageToWriteError(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	client.WriteMessage(TextMessage, []byte("first"))
+	client.WriteMessage(TextMessage, []byte("second"))
+
+	if _, _, err := server.ReadMessageTo(errorWriter{}); err == nil {
+		t.Error("ReadMessageTo() succeeded, want write error")
+	}
+	if _, p, err := server.ReadMessage(); err != nil || string(p) != "second" {
+		t.Errorf("ReadMessage() = %q, %v, want second", p, err)
+	}
+}
+
+func TestReadMessageChunks(t *testing.T) {
+	const chunkSize = 100
+	for _, size := range []int{0, 1, chunkSize, 10*chunkSize + 1, 10000} {
+		for _, compress := range []bool{false, true} {
+			client, server := (&PipeConfig{EnableCompression: compress}).Pipe()
+			client.EnableWriteCompression(compress)
+			payload := bytes.Repeat([]byte("x"), size)
+			client.WriteMessage(BinaryMessage, payload)
+
+			var got []byte
+			short := 0
+			messageType, n, err := server.ReadMessageChunks(make([]byte, chunkSize), func(chunk []byte) error {
+				if len(chunk) < chunkSize {
+					short++
+				}
+				got = append(got, chunk...)
+				return nil
+			})
+			if err != nil || messageType != BinaryMessage || n != int64(size) || !bytes.Equal(got, payload) {
+				t.Errorf("size=%d, compress=%v: ReadMessageChunks() = %d, %d, %v, want %d, %d", size, compress, messageType, n, err, BinaryMessage, size)
+			}
+			if short > 1 {
+				t.Errorf("size=%d, compress=%v: %d short chunks, want at most the last one", size, compress, short)
+			}
+			client.Close()
+			server.Close()
+		}
+	}
+}
+
+func TestReadMessageChunksError(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	client.WriteMessage(TextMessage, bytes.Repeat([]byte("first"), 100))
+	client.WriteMessage(TextMessage, []byte("second"))
+
+	errStop := errors.New("stop")
+	_, n, err := server.ReadMessageChunks(make([]byte, 10), func(chunk []byte) error { return errStop })
+	if err != errStop || n != 0 {
+		t.Errorf("ReadMessageChunks() = %d, %v, want 0, %v", n, err, errStop)
+	}
+	if _, p, err := server.ReadMessage(); err != nil || string(p) != "second" {
+		t.Errorf("ReadMessage() = %q, %v, want second", p, err)
+	}
+}
+
+func TestLargeFrameLength(t *testing.T) {
+	// A frame of 4 GiB + 1 bytes, truncated after 1000 bytes.
+	const length = 1<<32 + 1
+	frame := []byte{0x82, 127, 0, 0, 0, 1, 0, 0, 0, 1}
+	frame = append(frame, make([]byte, 1000)...)
+	client := newTestConn(bytes.NewReader(frame), nil, false)
+
+	_, size, _, err := client.NextMessage()
+	if err != nil || size != length {
+		t.Fatalf("NextMessage() = %d, %v, want %d", size, err, int64(length))
+	}
+	client = newTestConn(bytes.NewReader(frame), nil, false)
+	_, n, err := client.ReadMessageChunks(nil, func(chunk []byte) error { return nil })
+	if err != errUnexpectedEOF || n != 1000 {
+		t.Errorf("ReadMessageChunks() = %d, %v, want 1000, %v", n, err, errUnexpectedEOF)
+	}
+}
+
+func TestReadAllMax(t *testing.T) {
+	tests := []struct {
+		data string
+		max  int
+		err  error
+	}{
+		{"", 0, nil},
+		{"", 4, nil},
+		{"abcd", 4, nil},
+		{"abcde", 4, ErrMessageTooLarge},
+	}
+	for _, tt := range tests {
+		p, err := readAllMax(strings.NewReader(tt.data), tt.max)
+		if err != tt.err || (err == nil && string(p) != tt.data) {
+			t.Errorf("readAllMax(%q, %d) = %q, %v, want %v", tt.data, tt.max, p, err, tt.err)
+		}
+	}
+}
+
 func TestEOFWithinFrame(t *testing.T) {
 	const bufSize = 64
 
@@ -744,3 +943,124 @@ func TestFailedConnectionReadPanic(t *testing.T) {
 	}
 	t.Fatal("should not get here")
 }
+
+func TestCompressionNegotiated(t *testing.T) {
+	for _, enable := range []bool{false, true} {
+		client, server := (&PipeConfig{EnableCompression: enable}).Pipe()
+		if client.CompressionNegotiated() != enable || server.CompressionNegotiated() != enable {
+			t.Errorf("EnableCompression %t: CompressionNegotiated() = %t, %t", enable, client.CompressionNegotiated(), server.CompressionNegotiated())
+		}
+		client.Close()
+		server.Close()
+	}
+}
+
+func TestDefaultWriteTimeout(t *testing.T) {
+	writes := []struct {
+		name  string
+		write func(c *Conn) error
+	}{
+		{"WriteMessage", func(c *Conn) error { return c.WriteMessage(TextMessage, []byte("hello")) }},
+		{"WriteJSON", func(c *Conn) error { return c.WriteJSON("hello") }},
+		{"WriteControl", func(c *Conn) error { return c.WriteControl(PingMessage, nil, time.Time{}) }},
+	}
+	for _, tt := range writes {
+		// Nothing reads from the peer end of the pipe.
+		p1, p2 := net.Pipe()
+		c := newConn(p1, true, 1024, 1024, nil, nil, nil)
+		c.SetDefaultWriteTimeout(10 * time.Millisecond)
+		if err := tt.write(c); !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, os.ErrDeadlineExceeded)
+		}
+		p1.Close()
+		p2.Close()
+	}
+}
+
+func TestDefaultWriteTimeoutOverride(t *testing.T) {
+	p1, p2 := net.Pipe()
+	defer p1.Close()
+	defer p2.Close()
+	c := newConn(p1, true, 1024, 1024, nil, nil, nil)
+	c.SetDefaultWriteTimeout(time.Millisecond)
+	c.SetWriteDeadline(time.Now().Add(time.Minute))
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		io.Copy(io.Discard, p2)
+	}()
+	if err := c.WriteMessage(TextMessage, []byte("hello")); err != nil {
+		t.Errorf("WriteMessage() error = %v", err)
+	}
+}
+
+func TestBufferedAmount(t *testing.T) {
+	p1, p2 := net.Pipe()
+	defer p1.Close()
+	defer p2.Close()
+	c := newConn(p1, true, 1024, 1024, nil, nil, nil)
+
+	w, err := c.NextWriter(BinaryMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(w, "0123456789")
+	if n := c.BufferedAmount(); n != 10 {
+		t.Errorf("BufferedAmount() after Write = %d, want 10", n)
+	}
+
+	// Nothing reads from the peer end of the pipe, so Close blocks with the
+	// frame in flight.
+	done := make(chan error, 1)
+	go func() { done <- w.Close() }()
+	deadline := time.Now().Add(5 * time.Second)
+	for c.BufferedAmount() != 12 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := c.BufferedAmount(); n != 12 {
+		t.Errorf("BufferedAmount() during Close = %d, want 12", n)
+	}
+
+	if _, err := io.ReadFull(p2, make([]byte, 12)); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if n := c.BufferedAmount(); n != 0 {
+		t.Errorf("BufferedAmount() after Close = %d, want 0", n)
+	}
+}
+
+func TestNextMessageSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   PipeConfig
+		frames   []byte // written by the client with a zero masking key
+		message  string // written by the client with WriteMessage
+		wantSize int64
+		want     string
+	}{
+		{name: "single frame", message: "hello", wantSize: 5, want: "hello"},
+		{name: "empty", message: "", wantSize: 0, want: ""},
+		{name: "fragmented", frames: []byte{0x01, 0x82, 0, 0, 0, 0, 'a', 'b', 0x80, 0x81, 0, 0, 0, 0, 'c'}, wantSize: -1, want: "abc"},
+		{name: "compressed", config: PipeConfig{EnableCompression: true}, message: "hello", wantSize: -1, want: "hello"},
+	}
+	for _, tt := range tests {
+		client, server := tt.config.Pipe()
+		if tt.frames != nil {
+			client.NetConn().Write(tt.frames)
+		} else {
+			client.WriteMessage(TextMessage, []byte(tt.message))
+		}
+		mt, size, r, err := server.NextMessage()
+		if err != nil {
+			t.Fatalf("%s: NextMessage() error = %v", tt.name, err)
+		}
+		p, err := io.ReadAll(r)
+		if mt != TextMessage || size != tt.wantSize || string(p) != tt.want || err != nil {
+			t.Errorf("%s: got type %d, size %d, payload %q, error %v, want size %d and payload %q", tt.name, mt, size, p, err, tt.wantSize, tt.want)
+		}
+		client.Close()
+		server.Close()
+	}
+}
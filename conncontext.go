@@ -0,0 +1,131 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// contextCloseTimeout is the time allowed to send the close message when the
+// context bound by WithContext is done.
+const contextCloseTimeout = time.Second
+
+// contextBinding is a context bound to a connection by WithContext.
+type contextBinding struct {
+	ctx      context.Context
+	code     int
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	mu  sync.Mutex
+	err error // ctx.Err() after the connection is closed for the context
+}
+
+func (b *contextBinding) cancel() {
+	b.stopOnce.Do(func() { close(b.stop) })
+}
+
+func (b *contextBinding) doneErr() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.err
+}
+
+// WithContext binds the connection to ctx and returns c. When ctx is done,
+// the connection sends a close message with the code set by
+// SetContextCloseCode and closes the underlying network connection. Blocked
+// and later reads and writes return an error that wraps ctx.Err() and the
+// I/O error, so that errors.Is(err, context.Canceled) reports true for a
+// canceled context:
+//
+//	ctx, cancel := context.WithCancel(ctx)
+//	defer cancel()
+//	c.WithContext(ctx)
+//	for {
+//		_, p, err := c.ReadMessage()
+//		...
+//	}
+//
+// A later call to WithContext replaces the context. Closing the connection
+// with Close releases the context.
+func (c *Conn) WithContext(ctx context.Context) *Conn {
+	b := &contextBinding{ctx: ctx, code: c.contextCloseCode(), stop: make(chan struct{})}
+	if old := c.ctxBinding.Swap(b); old != nil {
+		old.cancel()
+	}
+	if ctx.Done() != nil {
+		go c.watchContext(b)
+	}
+	return c
+}
+
+// SetContextCloseCode sets the code of the close message sent when the
+// context bound by WithContext is done. The default code is
+// CloseGoingAway. Call SetContextCloseCode before WithContext.
+func (c *Conn) SetContextCloseCode(code int) {
+	c.ctxCloseCode = code
+}
+
+func (c *Conn) contextCloseCode() int {
+	if c.ctxCloseCode == 0 {
+		return CloseGoingAway
+	}
+	return c.ctxCloseCode
+}
+
+func (c *Conn) watchContext(b *contextBinding) {
+	select {
+	case <-b.stop:
+		return
+	case <-b.ctx.Done():
+		select {
+		case <-b.stop:
+			// Released before the context was done.
+			return
+		default:
+		}
+	}
+	b.mu.Lock()
+	b.err = b.ctx.Err()
+	b.mu.Unlock()
+
+	c.writeErrMu.Lock()
+	open := c.writeErr == nil
+	c.writeErrMu.Unlock()
+	if open {
+		_ = c.WriteControl(CloseMessage, FormatCloseMessage(b.code, ""), c.clock.Now().Add(contextCloseTimeout))
+	}
+	c.writeErrMu.Lock()
+	if c.writeErr == ErrCloseSent {
+		c.writeErr = c.contextError(c.writeErr)
+	}
+	c.writeErrMu.Unlock()
+	c.Close()
+}
+
+// stopContext releases the context bound by WithContext.
+func (c *Conn) stopContext() {
+	if b := c.ctxBinding.Load(); b != nil {
+		b.cancel()
+	}
+}
+
+// contextError wraps err with the error of the context bound by WithContext
+// if the connection was closed for the context.
+func (c *Conn) contextError(err error) error {
+	b := c.ctxBinding.Load()
+	if err == nil || b == nil {
+		return err
+	}
+	ctxErr := b.doneErr()
+	if ctxErr == nil || errors.Is(err, ctxErr) {
+		return err
+	}
+	return fmt.Errorf("websocket: %w: %w", ctxErr, err)
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithContextCancelRead(t *testing.T) {
+	tests := []struct {
+		code int // passed to SetContextCloseCode
+		want int
+	}{
+		{0, CloseGoingAway},
+		{CloseNormalClosure, CloseNormalClosure},
+		{4001, 4001},
+	}
+	for _, tt := range tests {
+		client, server := Pipe()
+		if tt.code != 0 {
+			server.SetContextCloseCode(tt.code)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		server.WithContext(ctx)
+
+		errc := make(chan error, 1)
+		go func() {
+			_, _, err := server.ReadMessage()
+			errc <- err
+		}()
+		cancel()
+
+		select {
+		case err := <-errc:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("code %d: ReadMessage() error = %v, want %v", tt.code, err, context.Canceled)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("code %d: ReadMessage() did not return", tt.code)
+		}
+		if _, _, err := client.ReadMessage(); !IsCloseError(err, tt.want) {
+			t.Errorf("code %d: client ReadMessage() error = %v, want close %d", tt.code, err, tt.want)
+		}
+		if err := server.WriteMessage(TextMessage, []byte("x")); !errors.Is(err, context.Canceled) {
+			t.Errorf("code %d: WriteMessage() error = %v, want %v", tt.code, err, context.Canceled)
+		}
+		client.Close()
+	}
+}
+
+func TestWithContextDeadline(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := server.WithContext(ctx).ReadMessage(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ReadMessage() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestWithContextClose(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	server.WithContext(ctx)
+	b := server.ctxBinding.Load()
+	server.Close()
+	cancel()
+	select {
+	case <-b.stop:
+	default:
+		t.Fatal("Close did not release the context")
+	}
+	if err := b.doneErr(); err != nil {
+		t.Errorf("context error after Close = %v, want nil", err)
+	}
+}
+
+func TestWithContextReplace(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	server.WithContext(ctx1)
+	server.WithContext(context.Background())
+	cancel1()
+	if err := server.WriteMessage(TextMessage, []byte("hello")); err != nil {
+		t.Fatalf("WriteMessage() error = %v", err)
+	}
+	if _, p, err := client.ReadMessage(); err != nil || string(p) != "hello" {
+		t.Errorf("ReadMessage() = %q, %v, want hello", p, err)
+	}
+}
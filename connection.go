@@ -0,0 +1,97 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"io"
+	"net"
+	"time"
+)
+
+// Connection is the set of Conn methods that applications use to exchange
+// messages. Application code that accepts a Connection instead of a *Conn
+// can be tested with a mock implementation.
+//
+// *Conn implements Connection. Methods added to Connection in later
+// versions of this package are also added to *Conn.
+type Connection interface {
+	// Subprotocol returns the negotiated protocol for the connection.
+	Subprotocol() string
+
+	// LocalAddr returns the local network address.
+	LocalAddr() net.Addr
+
+	// RemoteAddr returns the remote network address.
+	RemoteAddr() net.Addr
+
+	// NextReader returns the next data message received from the peer.
+	NextReader() (messageType int, r io.Reader, err error)
+
+	// ReadMessage reads the next data message from the peer.
+	ReadMessage() (messageType int, p []byte, err error)
+
+	// ReadJSON reads the next JSON-encoded message from the connection.
+	ReadJSON(v interface{}) error
+
+	// NextWriter returns a writer for the next message to send.
+	NextWriter(messageType int) (io.WriteCloser, error)
+
+	// WriteMessage writes a message with the given message type and payload.
+	WriteMessage(messageType int, data []byte) error
+
+	// WritePreparedMessage writes a prepared message.
+	WritePreparedMessage(pm *PreparedMessage) error
+
+	// WriteJSON writes the JSON encoding of v as a message.
+	WriteJSON(v interface{}) error
+
+	// WriteControl writes a control message with the given deadline.
+	WriteControl(messageType int, data []byte, deadline time.Time) error
+
+	// Close closes the underlying network connection without sending or
+	// waiting for a close message.
+	Close() error
+
+	// SetReadDeadline sets the read deadline on the network connection.
+	SetReadDeadline(t time.Time) error
+
+	// SetWriteDeadline sets the write deadline on the network connection.
+	SetWriteDeadline(t time.Time) error
+
+	// SetReadLimit sets the maximum size in bytes for a message read from
+	// the peer.
+	SetReadLimit(limit int64)
+
+	// CloseHandler returns the current close handler.
+	CloseHandler() func(code int, text string) error
+
+	// SetCloseHandler sets the handler for close messages received from
+	// the peer.
+	SetCloseHandler(h func(code int, text string) error)
+
+	// PingHandler returns the current ping handler.
+	PingHandler() func(appData string) error
+
+	// SetPingHandler sets the handler for ping messages received from the
+	// peer.
+	SetPingHandler(h func(appData string) error)
+
+	// PongHandler returns the current pong handler.
+	PongHandler() func(appData string) error
+
+	// SetPongHandler sets the handler for pong messages received from the
+	// peer.
+	SetPongHandler(h func(appData string) error)
+
+	// EnableWriteCompression enables and disables write compression of
+	// subsequent text and binary messages.
+	EnableWriteCompression(enable bool)
+
+	// SetCompressionLevel sets the flate compression level for subsequent
+	// text and binary messages.
+	SetCompressionLevel(level int) error
+}
+
+var _ Connection = (*Conn)(nil)
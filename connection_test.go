@@ -0,0 +1,57 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"errors"
+	"testing"
+)
+
+// echoOnce is application code written against Connection.
+func echoOnce(c Connection) error {
+	mt, p, err := c.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return c.WriteMessage(mt, p)
+}
+
+// mockConnection is a Connection that reads from a list of messages and
+// records the messages written.
+type mockConnection struct {
+	Connection // panics on methods that are not overridden
+	read       []string
+	written    []string
+}
+
+func (m *mockConnection) ReadMessage() (int, []byte, error) {
+	if len(m.read) == 0 {
+		return 0, nil, errors.New("no more messages")
+	}
+	p := m.read[0]
+	m.read = m.read[1:]
+	return TextMessage, []byte(p), nil
+}
+
+func (m *mockConnection) WriteMessage(messageType int, data []byte) error {
+	m.written = append(m.written, string(data))
+	return nil
+}
+
+func TestConnection(t *testing.T) {
+	m := &mockConnection{read: []string{"hello"}}
+	if err := echoOnce(m); err != nil || len(m.written) != 1 || m.written[0] != "hello" {
+		t.Errorf("echoOnce(mock) = %v, written %q", err, m.written)
+	}
+
+	client, server := Pipe()
+	client.WriteMessage(TextMessage, []byte("hello"))
+	if err := echoOnce(server); err != nil {
+		t.Fatal(err)
+	}
+	if _, p, err := client.ReadMessage(); err != nil || string(p) != "hello" {
+		t.Errorf("ReadMessage() = %q, %v", p, err)
+	}
+}
@@ -0,0 +1,18 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !plan9
+
+package websocket
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isConnReset reports whether err is a reset of the network connection by
+// the peer.
+func isConnReset(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET)
+}
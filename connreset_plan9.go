@@ -0,0 +1,13 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import "strings"
+
+// isConnReset reports whether err is a reset of the network connection by
+// the peer. Plan 9 has no error numbers; the error string is checked.
+func isConnReset(err error) bool {
+	return strings.Contains(err.Error(), "connection reset")
+}
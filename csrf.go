@@ -0,0 +1,124 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+)
+
+// Default names of the CSRF token cookie, header and query parameter.
+const (
+	DefaultCSRFCookieName = "csrf_token"
+	DefaultCSRFHeaderName = "X-CSRF-Token"
+	DefaultCSRFQueryParam = "csrf_token"
+)
+
+// CSRFCheck protects endpoints that authenticate with cookies against
+// cross-site WebSocket hijacking. It validates the Origin header and a
+// double-submit token: the page sets a random token in a cookie and sends
+// the same token in the handshake request. A page on another site cannot
+// read the cookie and therefore cannot send the token.
+//
+// Browsers cannot set headers on WebSocket handshake requests, so browser
+// clients send the token in a query parameter:
+//
+//	new WebSocket("wss://example.com/ws?csrf_token=" + encodeURIComponent(token))
+//
+// Use the CheckOrigin method as the Upgrader's CheckOrigin function:
+//
+//	check := &websocket.CSRFCheck{Origins: []string{"https://example.com"}}
+//	upgrader := websocket.Upgrader{CheckOrigin: check.CheckOrigin}
+type CSRFCheck struct {
+	// Origins lists the allowed origins, such as "https://example.com".
+	// Origins are compared without regard to case. If Origins is empty,
+	// the host of the origin must match the request host, as in the
+	// Upgrader's default check. Requests without an Origin header, which
+	// are not sent by browsers, are allowed if the token is valid.
+	Origins []string
+
+	// CookieName is the name of the cookie with the token. If CookieName
+	// is empty, DefaultCSRFCookieName is used.
+	CookieName string
+
+	// HeaderName is the name of the request header with the token. If
+	// HeaderName is empty, DefaultCSRFHeaderName is used.
+	HeaderName string
+
+	// QueryParam is the name of the query parameter with the token. If
+	// QueryParam is empty, DefaultCSRFQueryParam is used.
+	QueryParam string
+}
+
+// CheckOrigin returns true if the request origin is allowed and the request
+// carries the token from the cookie in the header or query parameter.
+func (c *CSRFCheck) CheckOrigin(r *http.Request) bool {
+	if !c.originAllowed(r) {
+		return false
+	}
+	cookie, err := r.Cookie(stringOrDefault(c.CookieName, DefaultCSRFCookieName))
+	if err != nil {
+		return false
+	}
+	token := CSRFToken(r, stringOrDefault(c.HeaderName, DefaultCSRFHeaderName), stringOrDefault(c.QueryParam, DefaultCSRFQueryParam))
+	return EqualCSRFTokens(cookie.Value, token)
+}
+
+func (c *CSRFCheck) originAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if len(c.Origins) == 0 {
+		u, err := url.Parse(origin)
+		return err == nil && equalASCIIFold(u.Host, r.Host)
+	}
+	for _, o := range c.Origins {
+		if equalASCIIFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func stringOrDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// CSRFToken returns the token carried by a request in the header or, if
+// the header is not set, in the query parameter. Empty names are skipped.
+func CSRFToken(r *http.Request, header, param string) string {
+	if header != "" {
+		if t := r.Header.Get(header); t != "" {
+			return t
+		}
+	}
+	if param != "" {
+		return r.URL.Query().Get(param)
+	}
+	return ""
+}
+
+// EqualCSRFTokens reports whether two tokens are equal and not empty. The
+// comparison takes constant time for tokens of equal length.
+func EqualCSRFTokens(a, b string) bool {
+	return a != "" && subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// NewCSRFToken returns a random token with 256 bits of entropy, encoded as
+// URL-safe base64 so that it can be used in cookies and query parameters.
+func NewCSRFToken() (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b[:]), nil
+}
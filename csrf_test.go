@@ -0,0 +1,68 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCSRFCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		check  CSRFCheck
+		url    string
+		origin string
+		cookie string
+		header string
+		ok     bool
+	}{
+		{"query", CSRFCheck{}, "/ws?csrf_token=abc", "http://example.com", "abc", "", true},
+		{"header", CSRFCheck{}, "/ws", "", "abc", "abc", true},
+		{"header before query", CSRFCheck{}, "/ws?csrf_token=abc", "", "abc", "xyz", false},
+		{"wrong token", CSRFCheck{}, "/ws?csrf_token=abd", "", "abc", "", false},
+		{"no token", CSRFCheck{}, "/ws", "", "abc", "", false},
+		{"no cookie", CSRFCheck{}, "/ws?csrf_token=abc", "", "", "", false},
+		{"empty token", CSRFCheck{}, "/ws?csrf_token=", "", "", "", false},
+		{"cross origin", CSRFCheck{}, "/ws?csrf_token=abc", "http://evil.com", "abc", "", false},
+		{"allowed origin", CSRFCheck{Origins: []string{"https://app.example.com"}}, "/ws?csrf_token=abc", "https://APP.example.com", "abc", "", true},
+		{"disallowed origin", CSRFCheck{Origins: []string{"https://app.example.com"}}, "/ws?csrf_token=abc", "http://example.com", "abc", "", false},
+		{"custom names", CSRFCheck{CookieName: "c", QueryParam: "t"}, "/ws?t=abc", "", "abc", "", true},
+		{"custom names default query", CSRFCheck{CookieName: "c", QueryParam: "t"}, "/ws?csrf_token=abc", "", "abc", "", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "http://example.com"+tt.url, nil)
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		if tt.cookie != "" {
+			r.AddCookie(&http.Cookie{Name: stringOrDefault(tt.check.CookieName, DefaultCSRFCookieName), Value: tt.cookie})
+		}
+		if tt.header != "" {
+			r.Header.Set(DefaultCSRFHeaderName, tt.header)
+		}
+		if got := tt.check.CheckOrigin(r); got != tt.ok {
+			t.Errorf("%s: CheckOrigin() = %v, want %v", tt.name, got, tt.ok)
+		}
+	}
+}
+
+func TestNewCSRFToken(t *testing.T) {
+	a, err := NewCSRFToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewCSRFToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a) != 43 || a == b {
+		t.Errorf("NewCSRFToken() = %q, %q", a, b)
+	}
+	if !EqualCSRFTokens(a, a) || EqualCSRFTokens(a, b) || EqualCSRFTokens("", "") {
+		t.Error("EqualCSRFTokens() returned wrong result")
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// checkWritesDefault is the default for EnableConcurrencyCheck. It is set
+// in builds with the websocketdebug tag.
+var checkWritesDefault = false
+
+// writeCheck records the writers of a connection for the concurrency check.
+type writeCheck struct {
+	mu     sync.Mutex
+	stack  string // stack of the writer, empty when no write is in progress
+	enable bool
+
+	// call is the stack of the goroutine in a write method call or nil. It
+	// is the in-use flag of the message writer.
+	call atomic.Pointer[string]
+}
+
+// EnableConcurrencyCheck enables or disables the check for concurrent writes
+// with diagnostics. When the check is enabled, the connection records the
+// stack of the goroutine in a call to a write method, from NextWriter and
+// WriteMessage to the methods of the writer returned by NextWriter, and of
+// the goroutine that is writing to the network connection. If a second
+// goroutine calls a write method while the call of the first goroutine is in
+// progress, the connection panics with the stacks of both goroutines. A
+// writer may be handed from one goroutine to another with proper
+// synchronization.
+//
+// Without the check, concurrent writes are detected on a best-effort basis
+// and reported without stacks. The check is expensive; enable it in tests
+// and while debugging. Builds with the websocketdebug tag enable the check
+// on all connections.
+//
+// EnableConcurrencyCheck must not be called concurrently with the write
+// methods.
+func (c *Conn) EnableConcurrencyCheck(enable bool) {
+	c.writeCheck.enable = enable
+}
+
+// beginCall marks the start of a write method call. It panics if a call of
+// another goroutine is in progress.
+func (c *Conn) beginCall() {
+	if !c.writeCheck.enable {
+		return
+	}
+	stack := currentStack()
+	wc := &c.writeCheck
+	if !wc.call.CompareAndSwap(nil, &stack) {
+		previous := "another goroutine"
+		if p := wc.call.Load(); p != nil {
+			previous = *p
+		}
+		panic(concurrentWriteReport(stack, previous))
+	}
+}
+
+// endCall marks the end of a write method call.
+func (c *Conn) endCall() {
+	if !c.writeCheck.enable {
+		return
+	}
+	c.writeCheck.call.Store(nil)
+}
+
+// beginWrite marks the start of a write to the network connection.
+func (c *Conn) beginWrite() {
+	if !c.writeCheck.enable {
+		if c.isWriting {
+			panic("concurrent write to websocket connection")
+		}
+		c.isWriting = true
+		return
+	}
+	stack := currentStack()
+	wc := &c.writeCheck
+	wc.mu.Lock()
+	previous := wc.stack
+	if previous == "" {
+		wc.stack = stack
+	}
+	wc.mu.Unlock()
+	if previous != "" {
+		panic(concurrentWriteReport(stack, previous))
+	}
+}
+
+// endWrite marks the end of a write to the network connection.
+func (c *Conn) endWrite() {
+	if !c.writeCheck.enable {
+		if !c.isWriting {
+			panic("concurrent write to websocket connection")
+		}
+		c.isWriting = false
+		return
+	}
+	wc := &c.writeCheck
+	wc.mu.Lock()
+	wc.stack = ""
+	wc.mu.Unlock()
+}
+
+func currentStack() string {
+	buf := make([]byte, 4096)
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// concurrentWriteReport formats the stacks of colliding writers in the
+// manner of the race detector.
+func concurrentWriteReport(current, previous string) string {
+	var b strings.Builder
+	b.WriteString("concurrent write to websocket connection\n\n")
+	b.WriteString("Write by ")
+	b.WriteString(current)
+	b.WriteString("\nPrevious write still in progress by ")
+	b.WriteString(previous)
+	return b.String()
+}
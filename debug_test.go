@@ -0,0 +1,154 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// holdWrite marks a write in progress on c, as a writer blocked in the
+// network connection would.
+func holdWrite(c *Conn) {
+	c.beginWrite()
+}
+
+func collide(c *Conn) (report string) {
+	defer func() {
+		report = fmt.Sprint(recover())
+	}()
+	c.WriteMessage(TextMessage, []byte("hello"))
+	return ""
+}
+
+func TestConcurrencyCheck(t *testing.T) {
+	tests := []struct {
+		enable bool
+		want   []string
+	}{
+		{false, []string{"concurrent write to websocket connection"}},
+		{true, []string{
+			"concurrent write to websocket connection\n\nWrite by goroutine ",
+			"websocket.collide(",
+			"Previous write still in progress by goroutine ",
+			"websocket.holdWrite(",
+		}},
+	}
+	for _, tt := range tests {
+		client, _ := Pipe()
+		client.EnableConcurrencyCheck(tt.enable)
+		done := make(chan struct{})
+		go func() {
+			holdWrite(client)
+			close(done)
+		}()
+		<-done
+		report := collide(client)
+		for _, want := range tt.want {
+			if !strings.Contains(report, want) {
+				t.Errorf("enable %v: report = %q, want %q", tt.enable, report, want)
+			}
+		}
+		if !tt.enable && strings.Contains(report, "Previous write") {
+			t.Errorf("enable %v: report has stacks", tt.enable)
+		}
+	}
+}
+
+func TestConcurrencyCheckSequential(t *testing.T) {
+	client, server := Pipe()
+	client.EnableConcurrencyCheck(true)
+	for i := 0; i < 3; i++ {
+		done := make(chan error)
+		go func() { done <- client.WriteMessage(TextMessage, []byte("x")) }()
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := server.ReadMessage(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestConcurrencyCheckMessage(t *testing.T) {
+	client, server := Pipe()
+	defer server.Close()
+	client.EnableConcurrencyCheck(true)
+	w, err := client.NextWriter(TextMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Hold a call to the writer in progress.
+	pr, pw := io.Pipe()
+	copied := make(chan error)
+	go func() {
+		_, err := io.Copy(w, pr)
+		copied <- err
+	}()
+	pw.Write([]byte("hello"))
+
+	reports := make(chan string)
+	go func() { reports <- collide(client) }()
+	go func() {
+		defer func() { reports <- fmt.Sprint(recover()) }()
+		w.Write([]byte("world"))
+	}()
+	for i := 0; i < 2; i++ {
+		report := <-reports
+		for _, want := range []string{
+			"concurrent write to websocket connection\n\nWrite by goroutine ",
+			"Previous write still in progress by goroutine ",
+			"io.Copy(",
+		} {
+			if !strings.Contains(report, want) {
+				t.Errorf("report = %q, want %q", report, want)
+			}
+		}
+	}
+
+	pw.Close()
+	if err := <-copied; err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, p, err := server.ReadMessage(); err != nil || string(p) != "hello" {
+		t.Fatalf("ReadMessage() = %q, %v, want hello", p, err)
+	}
+	if err := client.WriteMessage(TextMessage, []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConcurrencyCheckHandOff(t *testing.T) {
+	client, server := Pipe()
+	client.EnableConcurrencyCheck(true)
+	writers := make(chan io.WriteCloser)
+	go func() {
+		w, err := client.NextWriter(TextMessage)
+		if err != nil {
+			t.Error(err)
+			close(writers)
+			return
+		}
+		io.WriteString(w, "hel")
+		writers <- w
+	}()
+	w, ok := <-writers
+	if !ok {
+		return
+	}
+	io.WriteString(w, "lo")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, p, err := server.ReadMessage(); err != nil || string(p) != "hello" {
+		t.Errorf("ReadMessage() = %q, %v, want hello", p, err)
+	}
+}
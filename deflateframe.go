@@ -0,0 +1,113 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"io"
+)
+
+// deflateFrameExtension is the name of the legacy frame based deflate
+// extension offered by old WebKit browsers and some embedded clients.
+const deflateFrameExtension = "x-webkit-deflate-frame"
+
+// maxDeflateWindow is the size of the largest DEFLATE sliding window.
+const maxDeflateWindow = 1 << 15
+
+// maxInflatedFrameSize is the largest inflated frame when no read limit
+// applies. Frames are inflated whole, so a small frame must not expand to
+// unbounded memory.
+const maxInflatedFrameSize = 16 << 20
+
+var errInflatedFrameTooLarge = errors.New("websocket: inflated frame exceeds maximum size")
+
+// deflateFrameReader decodes frames compressed with the
+// x-webkit-deflate-frame extension. Unlike permessage-deflate, every frame
+// with RSV1 set is compressed on its own and ends with an empty stored block.
+// Peers may keep the compression context between frames, so the reader keeps
+// the last 32KB of output as the dictionary for the next frame.
+type deflateFrameReader struct {
+	window []byte
+}
+
+// offersDeflateFrame returns true if the extensions include
+// x-webkit-deflate-frame.
+func offersDeflateFrame(exts []map[string]string) bool {
+	for _, ext := range exts {
+		if ext[""] == deflateFrameExtension {
+			return true
+		}
+	}
+	return false
+}
+
+// inflateFrame replaces the payload of the current frame with the inflated
+// payload. The read limit applies to the inflated message size unless the
+// read limit handler allowed the compressed message. Without a read limit,
+// the inflated frame is limited to maxInflatedFrameSize.
+func (c *Conn) inflateFrame() error {
+	limited := c.readLimit > 0 && c.readLength <= c.readLimit
+	// The frame is inflated whole. Grow the payload as it arrives instead of
+	// allocating the length claimed by the peer.
+	if c.readRemaining > int64(maxInt) {
+		return ErrMessageTooLarge
+	}
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, c.br, c.readRemaining); err != nil {
+		if err == io.EOF {
+			err = errUnexpectedEOF
+		}
+		return err
+	}
+	payload := buf.Bytes()
+	if c.readMasked {
+		maskBytes(c.readMaskKey, 0, payload)
+		c.readMasked = false
+	}
+	c.readLength -= int64(len(payload))
+
+	const tail =
+	// Add the empty stored block removed by the peer.
+	"\x00\x00\xff\xff" +
+		// Add final block to squelch unexpected EOF error from flate reader.
+		"\x01\x00\x00\xff\xff"
+
+	countFlatePoolGet()
+	fr, _ := flateReaderPool.Get().(io.ReadCloser)
+	defer flateReaderPool.Put(fr)
+	mr := io.MultiReader(bytes.NewReader(payload), bytes.NewReader([]byte(tail)))
+	if err := fr.(flate.Resetter).Reset(mr, c.deflateFrame.window); err != nil {
+		return err
+	}
+	max := int64(maxInflatedFrameSize)
+	if limited && c.readLimit-c.readLength < max {
+		max = c.readLimit - c.readLength
+	}
+	p, err := readAllMax(io.LimitReader(fr, max+1), maxInt-len(c.deflateFrame.window))
+	if err != nil {
+		return err
+	}
+
+	c.readLength += int64(len(p))
+	if limited && c.readLength > c.readLimit {
+		_ = c.WriteControl(CloseMessage, FormatCloseMessage(CloseMessageTooBig, ""), c.clock.Now().Add(writeWait))
+		return c.reportViolation(ViolationReadLimit, ErrReadLimit)
+	}
+	if int64(len(p)) > max {
+		_ = c.WriteControl(CloseMessage, FormatCloseMessage(CloseMessageTooBig, ""), c.clock.Now().Add(writeWait))
+		return c.reportViolation(ViolationReadLimit, errInflatedFrameTooLarge)
+	}
+
+	w := append(c.deflateFrame.window, p...)
+	if len(w) > maxDeflateWindow {
+		w = append(c.deflateFrame.window[:0], w[len(w)-maxDeflateWindow:]...)
+	}
+	c.deflateFrame.window = w
+
+	c.readInflated = p
+	return c.setReadRemaining(int64(len(p)))
+}
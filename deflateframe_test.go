@@ -0,0 +1,151 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// deflateFrameWriter compresses frame payloads the way legacy clients do,
+// keeping the compression context between frames.
+type deflateFrameWriter struct {
+	buf bytes.Buffer
+	fw  *flate.Writer
+}
+
+func newDeflateFrameWriter() *deflateFrameWriter {
+	w := &deflateFrameWriter{}
+	w.fw, _ = flate.NewWriter(&w.buf, flate.BestCompression)
+	return w
+}
+
+// frame returns a client frame with a zero masking key and the compressed
+// payload.
+func (w *deflateFrameWriter) frame(b0 byte, payload string) []byte {
+	w.buf.Reset()
+	w.fw.Write([]byte(payload))
+	w.fw.Flush()
+	p := bytes.TrimSuffix(w.buf.Bytes(), []byte{0, 0, 0xff, 0xff})
+	return append([]byte{b0 | rsv1Bit, maskBit | byte(len(p)), 0, 0, 0, 0}, p...)
+}
+
+func TestDeflateFrameRead(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	server.deflateFrame = &deflateFrameReader{}
+
+	w := newDeflateFrameWriter()
+	var frames []byte
+	frames = append(frames, w.frame(0x81, "hello, world")...)
+	// The second frame refers to the first one.
+	frames = append(frames, w.frame(0x81, "hello, world")...)
+	// A fragmented message with an uncompressed continuation frame.
+	frames = append(frames, w.frame(0x01, "hello, ")...)
+	frames = append(frames, 0x80, 0x85, 0, 0, 0, 0, 'w', 'o', 'r', 'l', 'd')
+	go client.NetConn().Write(frames)
+
+	for i := 0; i < 3; i++ {
+		_, p, err := server.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(p) != "hello, world" {
+			t.Errorf("%d: ReadMessage() = %q, want %q", i, p, "hello, world")
+		}
+	}
+}
+
+func TestDeflateFrameReadLimit(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	server.deflateFrame = &deflateFrameReader{}
+	server.SetReadLimit(64)
+
+	w := newDeflateFrameWriter()
+	go client.NetConn().Write(w.frame(0x82, strings.Repeat("a", 65)))
+	if _, _, err := server.ReadMessage(); err != ErrReadLimit {
+		t.Errorf("ReadMessage() error = %v, want %v", err, ErrReadLimit)
+	}
+}
+
+func TestDeflateFrameLargeLength(t *testing.T) {
+	// The payload is not allocated before it arrives.
+	frame := []byte{0xc2, maskBit | 127, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 'a'}
+	server := newTestConn(bytes.NewReader(frame), nil, true)
+	server.deflateFrame = &deflateFrameReader{}
+	if _, _, err := server.ReadMessage(); err != errUnexpectedEOF {
+		t.Errorf("ReadMessage() error = %v, want %v", err, errUnexpectedEOF)
+	}
+}
+
+func TestDeflateFrameBomb(t *testing.T) {
+	// Without a read limit, a small frame does not inflate to unbounded
+	// memory.
+	var buf bytes.Buffer
+	fw, _ := flate.NewWriter(&buf, flate.BestCompression)
+	fw.Write(make([]byte, maxInflatedFrameSize+1))
+	fw.Flush()
+	p := bytes.TrimSuffix(buf.Bytes(), []byte{0, 0, 0xff, 0xff})
+	frame := []byte{0xc2, maskBit | 127, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint64(frame[2:10], uint64(len(p)))
+	frame = append(frame, p...)
+
+	var w bytes.Buffer
+	server := newTestConn(bytes.NewReader(frame), &w, true)
+	server.deflateFrame = &deflateFrameReader{}
+	if _, _, err := server.ReadMessage(); err != errInflatedFrameTooLarge {
+		t.Errorf("ReadMessage() error = %v, want %v", err, errInflatedFrameTooLarge)
+	}
+	if !bytes.HasPrefix(w.Bytes(), []byte{0x88, 2, 0x03, 0xf1}) {
+		t.Errorf("close frame = %x, want code %d", w.Bytes(), CloseMessageTooBig)
+	}
+}
+
+func TestDeflateFrameNegotiation(t *testing.T) {
+	tests := []struct {
+		enable bool
+		offer  string
+		want   string
+	}{
+		{true, "x-webkit-deflate-frame", "x-webkit-deflate-frame"},
+		{true, "x-webkit-deflate-frame; no_context_takeover", "x-webkit-deflate-frame"},
+		{true, "permessage-deflate; client_no_context_takeover, x-webkit-deflate-frame", "permessage-deflate; server_no_context_takeover; client_no_context_takeover"},
+		{false, "x-webkit-deflate-frame", ""},
+	}
+	for _, tt := range tests {
+		tt := tt
+		upgrader := Upgrader{EnableCompression: true, EnableDeflateFrame: tt.enable}
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer c.Close()
+			if got := c.deflateFrame != nil; got != (tt.want == deflateFrameExtension) {
+				t.Errorf("%q: deflate-frame negotiated = %v", tt.offer, got)
+			}
+		}))
+		c, resp, err := DefaultDialer.dial(context.Background(), makeWsProto(s.URL), nil, func(req *http.Request) {
+			req.Header.Set("Sec-WebSocket-Extensions", tt.offer)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.Header.Get("Sec-WebSocket-Extensions"); got != tt.want {
+			t.Errorf("%q: extensions = %q, want %q", tt.offer, got, tt.want)
+		}
+		c.Close()
+		s.Close()
+	}
+}
@@ -133,6 +133,11 @@
 // The Close and WriteControl methods can be called concurrently with all other
 // methods.
 //
+// Concurrent writes are detected on a best-effort basis. To find the
+// goroutines that write concurrently, call EnableConcurrencyCheck on the
+// connection or build with the websocketdebug tag. With the check enabled, a
+// colliding write panics with the stacks of both writers.
+//
 // Origin Considerations
 //
 // Web browsers allow Javascript applications to open a WebSocket connection to
@@ -224,4 +229,23 @@
 // more details refer to RFC 7692.
 //
 // Use of compression is experimental and may result in decreased performance.
+//
+// Errors
+//
+// The package reports failures with sentinel and typed errors. Use errors.Is
+// and errors.As to inspect them instead of matching error strings:
+//
+//  ErrBadHandshake  failed handshake response, returned by the dialer with the response
+//  HandshakeError   failed handshake request, errors.Is ErrBadHandshake
+//  ProtocolError    protocol violation by the peer, with the ViolationKind
+//  TimeoutError     write deadline exceeded, errors.Is os.ErrDeadlineExceeded
+//  ErrReadLimit     message larger than the read limit
+//  CloseError       close message received from the peer
+//
+// For example, to get the status of a failed handshake request:
+//
+//  var he websocket.HandshakeError
+//  if errors.As(err, &he) {
+//      log.Printf("handshake failed with status %d", he.Status)
+//  }
 package websocket
@@ -0,0 +1,157 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket/wsframe"
+)
+
+// frameDumper writes frames to a debug dump.
+type frameDumper struct {
+	mu         sync.Mutex // serializes dumps from the reading and writing goroutines
+	w          io.Writer
+	maxPayload int
+}
+
+// SetFrameDump starts dumping the frames that are read and written on the
+// connection to w. Each frame is dumped with a timestamp, the direction, the
+// decoded header fields and a hex and ASCII dump of the header and of at
+// most maxPayload bytes of the unmasked payload. If w is nil, SetFrameDump
+// stops dumping.
+//
+// SetFrameDump can be called concurrently with the read and write methods.
+// Dumping slows down the connection; it is intended for diagnosing interop
+// issues. Received payloads are dumped from the data that is already
+// buffered when the frame header is read and may be shorter than
+// maxPayload.
+func (c *Conn) SetFrameDump(w io.Writer, maxPayload int) {
+	if w == nil {
+		c.dump.Store(nil)
+		return
+	}
+	if maxPayload < 0 {
+		maxPayload = 0
+	}
+	c.dump.Store(&frameDumper{w: w, maxPayload: maxPayload})
+}
+
+// dumpReadFrame dumps a frame after advanceFrame reads the header.
+func (c *Conn) dumpReadFrame(d *frameDumper, hdr []byte) {
+	n := int64(d.maxPayload)
+	if n > c.readRemaining {
+		n = c.readRemaining
+	}
+	if b := int64(c.br.Buffered()); n > b {
+		n = b
+	}
+	payload, _ := c.br.Peek(int(n))
+	payload = append([]byte(nil), payload...)
+	if hdr[1]&maskBit != 0 {
+		maskBytes(c.readMaskKey, 0, payload)
+	}
+	d.dump(c.clock.Now(), "recv", hdr, c.readRemaining, payload)
+}
+
+// dumpWrittenFrames dumps the frames in the buffers passed to Conn.write.
+// The headers are in buf0, payloads can continue in buf1.
+func (c *Conn) dumpWrittenFrames(d *frameDumper, buf0, buf1 []byte) {
+	now := c.clock.Now()
+	for len(buf0) > 0 {
+		hdrLen, length, ok := parseFrameHeader(buf0)
+		if !ok {
+			return
+		}
+		hdr := buf0[:hdrLen]
+		n := int64(d.maxPayload)
+		if n > length {
+			n = length
+		}
+		payload := make([]byte, 0, n)
+		rest := buf0[hdrLen:]
+		if int64(len(rest)) > n {
+			rest = rest[:n]
+		}
+		payload = append(payload, rest...)
+		if more := int(n) - len(payload); more > 0 && more <= len(buf1) {
+			payload = append(payload, buf1[:more]...)
+		}
+		if hdr[1]&maskBit != 0 {
+			var key [4]byte
+			copy(key[:], hdr[hdrLen-4:])
+			maskBytes(key, 0, payload)
+		}
+		d.dump(now, "send", hdr, length, payload)
+		if int64(len(buf0)-hdrLen) <= length {
+			return
+		}
+		buf0 = buf0[int64(hdrLen)+length:]
+	}
+}
+
+// parseFrameHeader returns the length of the frame header at the start of p
+// and the payload length.
+func parseFrameHeader(p []byte) (hdrLen int, length int64, ok bool) {
+	h, n, err := wsframe.ParseHeader(p)
+	return n, h.Length, err == nil
+}
+
+var opcodeNames = map[int]string{
+	continuationFrame: "continuation",
+	TextMessage:       "text",
+	BinaryMessage:     "binary",
+	CloseMessage:      "close",
+	PingMessage:       "ping",
+	PongMessage:       "pong",
+}
+
+func (d *frameDumper) dump(now time.Time, dir string, hdr []byte, length int64, payload []byte) {
+	var b strings.Builder
+	b.WriteString(now.Format("15:04:05.000000"))
+	b.WriteString(" " + dir + " ")
+	opcode := int(hdr[0] & 0xf)
+	if name, ok := opcodeNames[opcode]; ok {
+		b.WriteString(name)
+	} else {
+		b.WriteString("opcode " + strconv.Itoa(opcode))
+	}
+	for _, f := range []struct {
+		bit  byte
+		name string
+	}{{finalBit, "fin"}, {rsv1Bit, "rsv1"}, {rsv2Bit, "rsv2"}, {rsv3Bit, "rsv3"}} {
+		if hdr[0]&f.bit != 0 {
+			b.WriteString(" " + f.name)
+		}
+	}
+	fmt.Fprintf(&b, " len=%d", length)
+	if hdr[1]&maskBit != 0 {
+		fmt.Fprintf(&b, " mask=%x", hdr[len(hdr)-4:])
+	}
+	b.WriteString("\n  header:\n")
+	writeIndentedDump(&b, hdr)
+	if len(payload) > 0 {
+		fmt.Fprintf(&b, "  payload (%d of %d bytes):\n", len(payload), length)
+		writeIndentedDump(&b, payload)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	io.WriteString(d.w, b.String())
+}
+
+func writeIndentedDump(b *strings.Builder, p []byte) {
+	s := bufio.NewScanner(strings.NewReader(hex.Dump(p)))
+	for s.Scan() {
+		b.WriteString("    " + s.Text() + "\n")
+	}
+}
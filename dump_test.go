@@ -0,0 +1,98 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFrameDump(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	var serverDump, clientDump bytes.Buffer
+	server.SetFrameDump(&serverDump, 4)
+	client.SetFrameDump(&clientDump, 16)
+
+	if err := client.WriteMessage(TextMessage, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := server.ReadMessage(); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.WriteMessage(BinaryMessage, bytes.Repeat([]byte("w"), 70000)); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := client.ReadMessage(); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.WriteControl(PingMessage, []byte("ping"), time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		dump string
+		want []string
+	}{
+		{"server", serverDump.String(), []string{
+			" recv text fin len=5 mask=",
+			"  payload (4 of 5 bytes):\n    00000000  68 65 6c 6c ",
+			"|hell|\n",
+			" send binary fin len=70000\n  header:\n    00000000  82 7f 00 00 00 00 00 01  11 70 ",
+			"  payload (4 of 70000 bytes):\n",
+			" send ping fin len=4\n",
+		}},
+		{"client", clientDump.String(), []string{
+			" send text fin len=5 mask=",
+			"  payload (5 of 5 bytes):\n    00000000  68 65 6c 6c 6f ",
+			"|hello|\n",
+			" recv binary fin len=70000\n",
+			"  payload (16 of 70000 bytes):\n",
+		}},
+	}
+	for _, tt := range tests {
+		for _, want := range tt.want {
+			if !strings.Contains(tt.dump, want) {
+				t.Errorf("%s dump does not contain %q:\n%s", tt.name, want, tt.dump)
+			}
+		}
+	}
+
+	server.SetFrameDump(nil, 0)
+	n := serverDump.Len()
+	if err := server.WriteMessage(TextMessage, []byte("more")); err != nil {
+		t.Fatal(err)
+	}
+	if serverDump.Len() != n {
+		t.Errorf("dump written after SetFrameDump(nil, 0)")
+	}
+}
+
+func TestDumpWrittenFrames(t *testing.T) {
+	var buf bytes.Buffer
+	d := &frameDumper{w: &buf, maxPayload: 3}
+	c := &Conn{clock: systemClock{}}
+	// A non-final text frame and a final continuation frame whose payload
+	// continues in the second buffer.
+	buf0 := []byte{0x01, 0x02, 'a', 'b', 0x80, 0x03, 'c'}
+	buf1 := []byte{'d', 'e'}
+	c.dumpWrittenFrames(d, buf0, buf1)
+	dump := buf.String()
+	for _, want := range []string{
+		" send text len=2\n",
+		"|ab|",
+		" send continuation fin len=3\n",
+		"|cde|",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("dump does not contain %q:\n%s", want, dump)
+		}
+	}
+}
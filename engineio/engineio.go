@@ -0,0 +1,300 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package engineio implements the WebSocket transport of the Engine.IO
+// protocol version 4, the transport layer used by Socket.IO.
+//
+// The Server type accepts Engine.IO clients that connect with the websocket
+// transport, for example Socket.IO clients created with the option
+// {transports: ["websocket"]}. The server sends the open packet, runs the
+// heartbeat and frames messages. Socket.IO packets are carried in the
+// messages read and written with the Socket type.
+//
+// The HTTP long-polling transport is not supported.
+//
+// The protocol is specified at https://socket.io/docs/v4/engine-io-protocol/.
+package engineio
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Packet types.
+const (
+	packetOpen    = '0'
+	packetClose   = '1'
+	packetPing    = '2'
+	packetPong    = '3'
+	packetMessage = '4'
+	packetUpgrade = '5'
+	packetNoop    = '6'
+)
+
+// Error codes returned to clients in handshake error responses.
+const (
+	errorUnknownTransport = 0
+	errorBadRequest       = 3
+)
+
+const (
+	defaultPingInterval = 25 * time.Second
+	defaultPingTimeout  = 20 * time.Second
+	defaultMaxPayload   = 1000000
+	writeWait           = 10 * time.Second
+)
+
+// ErrClosed is returned when the client closes the socket with a close
+// packet.
+var ErrClosed = errors.New("engineio: socket closed by client")
+
+// Server is an http.Handler that accepts Engine.IO websocket connections.
+type Server struct {
+	// Upgrader is used to upgrade the HTTP connection. If Upgrader is nil,
+	// a zero Upgrader is used.
+	Upgrader *websocket.Upgrader
+
+	// PingInterval specifies the interval between ping packets sent by the
+	// server. If zero, a default of 25 seconds is used.
+	PingInterval time.Duration
+
+	// PingTimeout specifies the time the client has to respond to a ping. If
+	// zero, a default of 20 seconds is used.
+	PingTimeout time.Duration
+
+	// MaxPayload specifies the maximum message size in bytes accepted from
+	// the client. If zero, a default of 1,000,000 bytes is used.
+	MaxPayload int64
+
+	// Handler is called for each socket in the goroutine serving the HTTP
+	// request. The socket is closed when Handler returns.
+	Handler func(s *Socket)
+}
+
+type openPacket struct {
+	SID          string   `json:"sid"`
+	Upgrades     []string `json:"upgrades"`
+	PingInterval int64    `json:"pingInterval"`
+	PingTimeout  int64    `json:"pingTimeout"`
+	MaxPayload   int64    `json:"maxPayload"`
+}
+
+func handshakeError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]interface{}{"code": code, "message": message})
+}
+
+func newSessionID() (string, error) {
+	p := make([]byte, 15)
+	if _, err := rand.Read(p); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(p), nil
+}
+
+// ServeHTTP upgrades the request to an Engine.IO socket and runs the
+// handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("EIO") != "4" {
+		handshakeError(w, errorBadRequest, "Unsupported protocol version")
+		return
+	}
+	if q.Get("transport") != "websocket" {
+		handshakeError(w, errorUnknownTransport, "Transport unknown")
+		return
+	}
+	if q.Get("sid") != "" {
+		// Upgrades from the polling transport are not supported.
+		handshakeError(w, errorBadRequest, "Session ID unknown")
+		return
+	}
+
+	sid, err := newSessionID()
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	upgrader := s.Upgrader
+	if upgrader == nil {
+		upgrader = &websocket.Upgrader{}
+	}
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	sock := &Socket{
+		ws:           ws,
+		id:           sid,
+		request:      r,
+		pingInterval: s.PingInterval,
+		pingTimeout:  s.PingTimeout,
+		done:         make(chan struct{}),
+	}
+	if sock.pingInterval == 0 {
+		sock.pingInterval = defaultPingInterval
+	}
+	if sock.pingTimeout == 0 {
+		sock.pingTimeout = defaultPingTimeout
+	}
+	maxPayload := s.MaxPayload
+	if maxPayload == 0 {
+		maxPayload = defaultMaxPayload
+	}
+	ws.SetReadLimit(maxPayload + 1)
+	defer sock.Close()
+
+	p, _ := json.Marshal(&openPacket{
+		SID:          sid,
+		Upgrades:     []string{},
+		PingInterval: int64(sock.pingInterval / time.Millisecond),
+		PingTimeout:  int64(sock.pingTimeout / time.Millisecond),
+		MaxPayload:   maxPayload,
+	})
+	if err := sock.writePacket(websocket.TextMessage, packetOpen, p); err != nil {
+		return
+	}
+	if err := sock.extendReadDeadline(); err != nil {
+		return
+	}
+	sock.ws.Go(sock.pingLoop)
+
+	if s.Handler != nil {
+		s.Handler(sock)
+	}
+}
+
+// Socket is an Engine.IO socket.
+//
+// Socket supports one concurrent reader. It is safe to call WriteMessage and
+// Close concurrently with other methods.
+type Socket struct {
+	ws           *websocket.Conn
+	id           string
+	request      *http.Request
+	pingInterval time.Duration
+	pingTimeout  time.Duration
+
+	writeMu sync.Mutex
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// ID returns the session ID sent to the client in the open packet.
+func (s *Socket) ID() string {
+	return s.id
+}
+
+// Request returns the HTTP request that opened the socket.
+func (s *Socket) Request() *http.Request {
+	return s.request
+}
+
+func (s *Socket) extendReadDeadline() error {
+	return s.ws.SetReadDeadline(time.Now().Add(s.pingInterval + s.pingTimeout))
+}
+
+func (s *Socket) pingLoop() {
+	ticker := time.NewTicker(s.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.writePacket(websocket.TextMessage, packetPing, nil); err != nil {
+				s.Close()
+				return
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *Socket) writePacket(messageType int, typ byte, data []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if err := s.ws.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+		return err
+	}
+	w, err := s.ws.NextWriter(messageType)
+	if err != nil {
+		return err
+	}
+	if messageType == websocket.TextMessage {
+		if _, err := w.Write([]byte{typ}); err != nil {
+			return err
+		}
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// ReadMessage reads the next message from the client. The messageType is
+// websocket.TextMessage or websocket.BinaryMessage. Heartbeat packets are
+// processed by ReadMessage; the application must read the socket to keep the
+// connection alive.
+//
+// ReadMessage returns ErrClosed when the client sends a close packet.
+func (s *Socket) ReadMessage() (messageType int, p []byte, err error) {
+	for {
+		messageType, p, err = s.ws.ReadMessage()
+		if err != nil {
+			return 0, nil, err
+		}
+		if messageType == websocket.BinaryMessage {
+			return messageType, p, nil
+		}
+		if len(p) == 0 {
+			continue
+		}
+		switch p[0] {
+		case packetMessage:
+			return messageType, p[1:], nil
+		case packetPong:
+			if err := s.extendReadDeadline(); err != nil {
+				return 0, nil, err
+			}
+		case packetPing:
+			// Respond to probes sent by clients that upgrade from polling.
+			if err := s.writePacket(websocket.TextMessage, packetPong, p[1:]); err != nil {
+				return 0, nil, err
+			}
+		case packetClose:
+			return 0, nil, ErrClosed
+		case packetUpgrade, packetNoop:
+		default:
+			return 0, nil, errors.New("engineio: unknown packet type " + string(p[:1]))
+		}
+	}
+}
+
+// WriteMessage writes a message to the client. The messageType is
+// websocket.TextMessage or websocket.BinaryMessage.
+func (s *Socket) WriteMessage(messageType int, data []byte) error {
+	return s.writePacket(messageType, packetMessage, data)
+}
+
+// Close sends a close packet and closes the connection.
+func (s *Socket) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		_ = s.writePacket(websocket.TextMessage, packetClose, nil)
+		err = s.ws.Close()
+	})
+	return err
+}
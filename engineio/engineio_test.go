@@ -0,0 +1,189 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package engineio
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func newTestServer(t *testing.T, s *Server) string {
+	hs := httptest.NewServer(s)
+	t.Cleanup(hs.Close)
+	return hs.URL
+}
+
+func dial(t *testing.T, url string) *websocket.Conn {
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(url, "http")+"/engine.io/?EIO=4&transport=websocket", nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { ws.Close() })
+	return ws
+}
+
+func readPacket(t *testing.T, ws *websocket.Conn) (int, string) {
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	mt, p, err := ws.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	return mt, string(p)
+}
+
+func echoHandler(s *Socket) {
+	for {
+		mt, p, err := s.ReadMessage()
+		if err != nil {
+			return
+		}
+		if err := s.WriteMessage(mt, p); err != nil {
+			return
+		}
+	}
+}
+
+func TestOpen(t *testing.T) {
+	url := newTestServer(t, &Server{
+		PingInterval: time.Second,
+		PingTimeout:  2 * time.Second,
+		Handler:      echoHandler,
+	})
+	ws := dial(t, url)
+
+	mt, p := readPacket(t, ws)
+	if mt != websocket.TextMessage || !strings.HasPrefix(p, "0") {
+		t.Fatalf("open packet = %d %q", mt, p)
+	}
+	var open openPacket
+	if err := json.Unmarshal([]byte(p[1:]), &open); err != nil {
+		t.Fatal(err)
+	}
+	if open.SID == "" || open.PingInterval != 1000 || open.PingTimeout != 2000 || open.MaxPayload != defaultMaxPayload {
+		t.Errorf("open packet = %+v", open)
+	}
+}
+
+func TestMessages(t *testing.T) {
+	url := newTestServer(t, &Server{Handler: echoHandler})
+	ws := dial(t, url)
+	readPacket(t, ws)
+
+	tests := []struct {
+		messageType int
+		send, want  string
+	}{
+		{websocket.TextMessage, `40`, `40`},
+		{websocket.TextMessage, `42["hello","world"]`, `42["hello","world"]`},
+		{websocket.TextMessage, `4`, `4`},
+		{websocket.BinaryMessage, "\x01\x02\x03", "\x01\x02\x03"},
+	}
+	for _, tt := range tests {
+		if err := ws.WriteMessage(tt.messageType, []byte(tt.send)); err != nil {
+			t.Fatal(err)
+		}
+		mt, p := readPacket(t, ws)
+		if mt != tt.messageType || p != tt.want {
+			t.Errorf("send %q: got %d %q, want %d %q", tt.send, mt, p, tt.messageType, tt.want)
+		}
+	}
+}
+
+func TestPing(t *testing.T) {
+	url := newTestServer(t, &Server{
+		PingInterval: 50 * time.Millisecond,
+		PingTimeout:  50 * time.Millisecond,
+		Handler:      echoHandler,
+	})
+
+	// A client that answers pings stays connected.
+	ws := dial(t, url)
+	readPacket(t, ws)
+	for i := 0; i < 4; i++ {
+		if _, p := readPacket(t, ws); p != "2" {
+			t.Fatalf("packet = %q, want ping", p)
+		}
+		if err := ws.WriteMessage(websocket.TextMessage, []byte("3")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A client that does not answer is disconnected.
+	ws = dial(t, url)
+	readPacket(t, ws)
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, p, err := ws.ReadMessage()
+		if err != nil {
+			break
+		}
+		if string(p) == "1" {
+			break
+		}
+	}
+}
+
+func TestProbe(t *testing.T) {
+	url := newTestServer(t, &Server{Handler: echoHandler})
+	ws := dial(t, url)
+	readPacket(t, ws)
+	if err := ws.WriteMessage(websocket.TextMessage, []byte("2probe")); err != nil {
+		t.Fatal(err)
+	}
+	if _, p := readPacket(t, ws); p != "3probe" {
+		t.Errorf("packet = %q, want 3probe", p)
+	}
+}
+
+func TestClientClose(t *testing.T) {
+	errc := make(chan error, 1)
+	url := newTestServer(t, &Server{Handler: func(s *Socket) {
+		_, _, err := s.ReadMessage()
+		errc <- err
+	}})
+	ws := dial(t, url)
+	readPacket(t, ws)
+	if err := ws.WriteMessage(websocket.TextMessage, []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != ErrClosed {
+		t.Errorf("ReadMessage() error = %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestHandshakeErrors(t *testing.T) {
+	url := newTestServer(t, &Server{Handler: echoHandler})
+	tests := []struct {
+		query string
+		code  int
+	}{
+		{"EIO=3&transport=websocket", errorBadRequest},
+		{"EIO=4&transport=polling", errorUnknownTransport},
+		{"EIO=4&transport=websocket&sid=abc", errorBadRequest},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(url + "/engine.io/?" + tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body struct {
+			Code int `json:"code"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusBadRequest || body.Code != tt.code {
+			t.Errorf("%s: status %d code %d, want %d %d", tt.query, resp.StatusCode, body.Code, http.StatusBadRequest, tt.code)
+		}
+	}
+}
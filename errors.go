@@ -0,0 +1,82 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"errors"
+	"os"
+)
+
+// HandshakeError describes an error with the handshake from the peer. The
+// server returns a HandshakeError when the request from the client is not a
+// valid handshake.
+//
+// errors.Is(err, ErrBadHandshake) reports true for a HandshakeError.
+type HandshakeError struct {
+	message string
+
+	// Status is the HTTP status of the error response, or zero if there is
+	// no response.
+	Status int
+
+	cause error // sentinel matched by Is, such as ErrUpgradeHeadersStripped
+}
+
+func (e HandshakeError) Error() string { return e.message }
+
+// Is reports whether target is ErrBadHandshake or the specific cause of the
+// error, such as ErrUpgradeHeadersStripped.
+func (e HandshakeError) Is(target error) bool {
+	return target == ErrBadHandshake || (e.cause != nil && target == e.cause)
+}
+
+// ErrUpgradeHeadersStripped is matched by the HandshakeError that a server
+// returns for a handshake request whose Connection or Upgrade header was
+// removed by an intermediary, such as a proxy that does not support
+// websockets or that forwarded the request with HTTP/1.0. The client
+// cannot open a websocket connection through the intermediary and should
+// fall back to another transport. See Upgrader.Fallback.
+var ErrUpgradeHeadersStripped = errors.New("websocket: upgrade headers removed by an intermediary")
+
+// ProtocolError is returned when the connection fails because the peer
+// violated the protocol. The close message sent to the peer describes the
+// violation.
+type ProtocolError struct {
+	// Kind classifies the violation.
+	Kind ViolationKind
+
+	// Message describes the violation.
+	Message string
+}
+
+func (e *ProtocolError) Error() string { return "websocket: " + e.Message }
+
+// TimeoutError is returned when a write does not complete before the write
+// deadline and when a phase of Dialer.DialContext exceeds its timeout. A
+// TimeoutError satisfies net.Error and errors.Is(err,
+// os.ErrDeadlineExceeded) reports true for a TimeoutError. The connection
+// is not usable for writing after a TimeoutError.
+type TimeoutError struct {
+	// Op is the operation that timed out, "write", "connect", "tls
+	// handshake" or "upgrade".
+	Op string
+}
+
+// Errors returned by Dialer.DialContext when a phase of the handshake
+// exceeds its timeout. See Dialer.ConnectTimeout.
+var (
+	ErrConnectTimeout      = &TimeoutError{Op: "connect"}
+	ErrTLSHandshakeTimeout = &TimeoutError{Op: "tls handshake"}
+	ErrUpgradeTimeout      = &TimeoutError{Op: "upgrade"}
+)
+
+func (e *TimeoutError) Error() string   { return "websocket: " + e.Op + " timeout" }
+func (e *TimeoutError) Timeout() bool   { return true }
+func (e *TimeoutError) Temporary() bool { return true }
+
+// Is reports whether target is os.ErrDeadlineExceeded.
+func (e *TimeoutError) Is(target error) bool {
+	return target == os.ErrDeadlineExceeded
+}
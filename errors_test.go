@@ -0,0 +1,86 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestHandshakeErrorFromServer(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	s.Server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "10")
+		http.Error(w, "busy", http.StatusServiceUnavailable)
+	})
+
+	_, resp, err := cstDialer.Dial(s.URL, nil)
+	if err != ErrBadHandshake {
+		t.Fatalf("Dial() error = %v, want %v", err, ErrBadHandshake)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("StatusCode = %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+	if got := resp.Header.Get("Retry-After"); got != "10" {
+		t.Errorf("Header.Get(Retry-After) = %q, want 10", got)
+	}
+}
+
+func TestHandshakeErrorFromUpgrader(t *testing.T) {
+	var err error
+	s := newServer(t)
+	defer s.Close()
+	s.Server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err = (&Upgrader{}).Upgrade(w, r, nil)
+	})
+	resp, geterr := http.Get(s.Server.URL)
+	if geterr != nil {
+		t.Fatal(geterr)
+	}
+	resp.Body.Close()
+
+	var he HandshakeError
+	if !errors.As(err, &he) || he.Status != http.StatusBadRequest {
+		t.Errorf("Upgrade() error = %#v, want HandshakeError with status %d", err, http.StatusBadRequest)
+	}
+	if !errors.Is(err, ErrBadHandshake) {
+		t.Errorf("errors.Is(%v, ErrBadHandshake) = false", err)
+	}
+}
+
+func TestProtocolError(t *testing.T) {
+	client, server := Pipe()
+	defer server.Close()
+	defer client.Close()
+	client.NetConn().Write([]byte{0x83, 0x80, 0, 0, 0, 0})
+	_, _, err := server.ReadMessage()
+	var pe *ProtocolError
+	if !errors.As(err, &pe) || pe.Kind != ViolationOpcode {
+		t.Errorf("ReadMessage() error = %#v, want *ProtocolError with kind %s", err, ViolationOpcode)
+	}
+}
+
+func TestTimeoutError(t *testing.T) {
+	client, server := Pipe()
+	defer server.Close()
+	defer client.Close()
+	err := client.WriteControl(PingMessage, nil, time.Now().Add(-time.Second))
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("errors.Is(%v, os.ErrDeadlineExceeded) = false", err)
+	}
+	var ne net.Error
+	if !errors.As(err, &ne) || !ne.Timeout() {
+		t.Errorf("WriteControl() error = %v, want timeout net.Error", err)
+	}
+	var te *TimeoutError
+	if !errors.As(err, &te) || te.Op != "write" {
+		t.Errorf("WriteControl() error = %#v, want *TimeoutError for write", err)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package events sends and receives named events over websocket
+// connections.
+//
+// An Emitter wraps a connection on each side. Emit sends an event with a
+// payload that is encoded as JSON and On registers a handler that receives
+// the payloads of an event decoded into the handler's argument type:
+//
+//	e := events.New(c)
+//	e.On("chat", func(m ChatMessage) error {
+//		return e.Emit("chat", m)
+//	})
+//	e.On("ping", func() { log.Print("ping") })
+//	err := e.Serve(ctx)
+//
+// Clients and servers negotiate the envelope format with the subprotocol
+// Subprotocol:
+//
+//	upgrader := websocket.Upgrader{Subprotocols: []string{events.Subprotocol}}
+//	dialer := websocket.Dialer{Subprotocols: []string{events.Subprotocol}}
+//
+// # Protocol
+//
+// An event is a text message with a JSON object of the event name and the
+// payload, {"event":"chat","data":{...}}. The data member is omitted for
+// events without a payload.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Subprotocol is the subprotocol of the event envelope format.
+const Subprotocol = "events.v1.json"
+
+var (
+	errBadMessage = &websocket.CloseError{Code: websocket.CloseInvalidFramePayloadData, Text: "events: malformed message"}
+	errNoEvent    = errors.New("events: empty event name")
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// envelope is the message of an event.
+type envelope struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+// handler calls a function registered with On.
+type handler struct {
+	fn  reflect.Value
+	arg reflect.Type // nil if the function has no argument
+}
+
+func (h *handler) call(data json.RawMessage) error {
+	var args []reflect.Value
+	if h.arg != nil {
+		v := reflect.New(h.arg)
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, v.Interface()); err != nil {
+				return fmt.Errorf("%w: %v", errBadMessage, err)
+			}
+		}
+		args = []reflect.Value{v.Elem()}
+	}
+	out := h.fn.Call(args)
+	if len(out) == 1 && !out[0].IsNil() {
+		return out[0].Interface().(error)
+	}
+	return nil
+}
+
+// Emitter sends and receives events on a connection. The methods of an
+// Emitter are safe for concurrent use.
+type Emitter struct {
+	ws *websocket.Conn
+
+	writeMu sync.Mutex
+
+	mu       sync.RWMutex
+	handlers map[string]*handler
+}
+
+// New returns an emitter on the connection ws.
+func New(ws *websocket.Conn) *Emitter {
+	return &Emitter{ws: ws, handlers: make(map[string]*handler)}
+}
+
+// Conn returns the connection of the emitter.
+func (e *Emitter) Conn() *websocket.Conn {
+	return e.ws
+}
+
+// On registers the handler of an event, replacing the previous handler.
+// The handler is a function with no argument or one argument of any type
+// that the payload decodes into with encoding/json, and returns nothing or
+// an error:
+//
+//	func()
+//	func(payload T)
+//	func(payload T) error
+//	func() error
+//
+// Handlers are called by Serve, one at a time. A handler error stops Serve.
+// On panics if handler is not one of the supported function types.
+func (e *Emitter) On(event string, handler interface{}) {
+	h := newHandler(handler)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.handlers[event] = h
+}
+
+// Off removes the handler of an event.
+func (e *Emitter) Off(event string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.handlers, event)
+}
+
+func newHandler(fn interface{}) *handler {
+	v := reflect.ValueOf(fn)
+	if !v.IsValid() {
+		panic("events: nil handler")
+	}
+	t := v.Type()
+	if t.Kind() != reflect.Func || t.NumIn() > 1 || t.NumOut() > 1 ||
+		(t.NumOut() == 1 && t.Out(0) != errorType) || v.IsNil() {
+		panic(fmt.Sprintf("events: unsupported handler type %T", fn))
+	}
+	h := &handler{fn: v}
+	if t.NumIn() == 1 {
+		h.arg = t.In(0)
+	}
+	return h
+}
+
+// Emit sends an event with the payload encoded as JSON. A nil payload sends
+// an event without a payload.
+func (e *Emitter) Emit(event string, payload interface{}) error {
+	if event == "" {
+		return errNoEvent
+	}
+	m := envelope{Event: event}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		m.Data = data
+	}
+	p, err := json.Marshal(&m)
+	if err != nil {
+		return err
+	}
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+	return e.ws.WriteMessage(websocket.TextMessage, p)
+}
+
+// Serve reads events from the connection and calls their handlers until ctx
+// is done, the connection ends or a handler returns an error. Events
+// without a handler are ignored. Serve closes the connection and returns
+// the error described in websocket.Conn.Serve.
+func (e *Emitter) Serve(ctx context.Context) error {
+	return e.ws.Serve(ctx, func(messageType int, r io.Reader) error {
+		if messageType != websocket.TextMessage {
+			return errBadMessage
+		}
+		var m envelope
+		if err := json.NewDecoder(r).Decode(&m); err != nil || m.Event == "" {
+			return errBadMessage
+		}
+		e.mu.RLock()
+		h := e.handlers[m.Event]
+		e.mu.RUnlock()
+		if h == nil {
+			return nil
+		}
+		return h.call(m.Data)
+	})
+}
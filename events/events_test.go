@@ -0,0 +1,131 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package events
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type chat struct {
+	User string `json:"user"`
+	Text string `json:"text"`
+}
+
+func TestEmitter(t *testing.T) {
+	upgrader := websocket.Upgrader{Subprotocols: []string{Subprotocol}}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		e := New(c)
+		e.On("chat", func(m chat) error {
+			m.Text = strings.ToUpper(m.Text)
+			return e.Emit("chat", m)
+		})
+		e.On("ping", func() {
+			e.Emit("pong", nil)
+		})
+		e.Serve(r.Context())
+	}))
+	defer s.Close()
+
+	dialer := websocket.Dialer{Subprotocols: []string{Subprotocol}}
+	c, _, err := dialer.Dial("ws"+strings.TrimPrefix(s.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Subprotocol() != Subprotocol {
+		t.Errorf("Subprotocol() = %q, want %q", c.Subprotocol(), Subprotocol)
+	}
+	e := New(c)
+	chats := make(chan chat, 1)
+	pongs := make(chan struct{}, 1)
+	e.On("chat", func(m chat) { chats <- m })
+	e.On("pong", func() error {
+		pongs <- struct{}{}
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- e.Serve(ctx) }()
+
+	if err := e.Emit("unknown", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Emit("chat", chat{User: "ann", Text: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case m := <-chats:
+		if m != (chat{User: "ann", Text: "HELLO"}) {
+			t.Errorf("chat = %+v", m)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no chat event")
+	}
+	if err := e.Emit("ping", nil); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-pongs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no pong event")
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Serve() error = %v, want context canceled", err)
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  int
+		msg  string
+	}{
+		{"binary", websocket.BinaryMessage, `{"event":"chat"}`},
+		{"not json", websocket.TextMessage, `chat`},
+		{"no event", websocket.TextMessage, `{"data":1}`},
+		{"bad payload", websocket.TextMessage, `{"event":"chat","data":"text"}`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := websocket.Pipe()
+			defer client.Close()
+			e := New(server)
+			e.On("chat", func(m chat) {})
+			client.WriteMessage(tt.typ, []byte(tt.msg))
+			if err := e.Serve(context.Background()); !errors.Is(err, errBadMessage) {
+				t.Errorf("Serve() error = %v, want %v", err, errBadMessage)
+			}
+			if _, _, err := client.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseInvalidFramePayloadData) {
+				t.Errorf("client ReadMessage() error = %v, want close 1007", err)
+			}
+		})
+	}
+}
+
+func TestOnPanics(t *testing.T) {
+	for _, h := range []interface{}{nil, 1, func(a, b int) {}, func() int { return 0 }, (func())(nil)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("On(%T) did not panic", h)
+				}
+			}()
+			New(nil).On("event", h)
+		}()
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"expvar"
+	"sync"
+	"sync/atomic"
+)
+
+// expvarStats holds the counters published by PublishExpvar. It is nil until
+// PublishExpvar is called, so that the counters cost nothing when they are
+// not used.
+var expvarStats atomic.Pointer[stats]
+
+var publishExpvarOnce sync.Once
+
+type stats struct {
+	active          expvar.Int
+	upgrades        expvar.Int
+	upgradeFailures expvar.Map
+	closes          expvar.Map
+	dials           expvar.Int
+	dialFailures    expvar.Int
+	flatePoolGets   expvar.Int
+	flatePoolMisses expvar.Int
+}
+
+// PublishExpvar publishes package-level counters with the expvar package as
+// a map named "websocket":
+//
+//	active_connections   connections opened by Upgrade or Dial and not closed
+//	upgrades             successful calls to Upgrade
+//	upgrade_failures     failed calls to Upgrade by reason, such as "origin"
+//	closes               closed connections by CloseReason, such as "peer 1001"
+//	dials                successful calls to Dial
+//	dial_failures        failed calls to Dial
+//	flate_pool_gets      compressors and decompressors taken from the pools
+//	flate_pool_misses    compressors and decompressors allocated
+//	flate_pool_hit_rate  fraction of flate_pool_gets reused from the pools
+//	memory_bytes         memory attributable to connections, see Memory
+//
+// Counting starts when PublishExpvar is first called. Subsequent calls have
+// no effect.
+func PublishExpvar() {
+	publishExpvarOnce.Do(func() {
+		s := &stats{}
+		m := new(expvar.Map)
+		m.Set("active_connections", &s.active)
+		m.Set("upgrades", &s.upgrades)
+		m.Set("upgrade_failures", &s.upgradeFailures)
+		m.Set("closes", &s.closes)
+		m.Set("dials", &s.dials)
+		m.Set("dial_failures", &s.dialFailures)
+		m.Set("flate_pool_gets", &s.flatePoolGets)
+		m.Set("flate_pool_misses", &s.flatePoolMisses)
+		m.Set("flate_pool_hit_rate", expvar.Func(func() interface{} {
+			gets := s.flatePoolGets.Value()
+			if gets == 0 {
+				return 0.0
+			}
+			return 1 - float64(s.flatePoolMisses.Value())/float64(gets)
+		}))
+		m.Set("memory_bytes", expvar.Func(func() interface{} {
+			return Memory().Total()
+		}))
+		expvar.Publish("websocket", m)
+		expvarStats.Store(s)
+	})
+}
+
+func countUpgrade(c *Conn) {
+	if s := expvarStats.Load(); s != nil {
+		s.upgrades.Add(1)
+		s.active.Add(1)
+		c.counted = 1
+	}
+}
+
+func countUpgradeFailure(kind string) {
+	if s := expvarStats.Load(); s != nil {
+		s.upgradeFailures.Add(kind, 1)
+	}
+}
+
+// countDial counts a call to Dial that returned c.
+func countDial(c *Conn) {
+	s := expvarStats.Load()
+	switch {
+	case s == nil:
+	case c == nil:
+		s.dialFailures.Add(1)
+	default:
+		s.dials.Add(1)
+		s.active.Add(1)
+		c.counted = 1
+	}
+}
+
+func countClose(c *Conn) {
+	if atomic.CompareAndSwapInt32(&c.counted, 1, 0) {
+		s := expvarStats.Load()
+		s.active.Add(-1)
+		s.closes.Add(c.CloseReason().String(), 1)
+	}
+}
+
+func countFlatePoolGet() {
+	if s := expvarStats.Load(); s != nil {
+		s.flatePoolGets.Add(1)
+	}
+}
+
+func countFlatePoolMiss() {
+	if s := expvarStats.Load(); s != nil {
+		s.flatePoolMisses.Add(1)
+	}
+}
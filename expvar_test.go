@@ -0,0 +1,94 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// expvarSnapshot returns the published counters.
+func expvarSnapshot(t *testing.T) map[string]interface{} {
+	t.Helper()
+	v := expvar.Get("websocket")
+	if v == nil {
+		t.Fatal("websocket variable not published")
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(v.String()), &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestPublishExpvar(t *testing.T) {
+	PublishExpvar()
+	PublishExpvar()
+
+	done := make(chan struct{}, 1)
+	upgrader := Upgrader{EnableCompression: true}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		c.ReadMessage()
+		c.Close()
+		done <- struct{}{}
+	}))
+	defer s.Close()
+
+	before := expvarSnapshot(t)
+	if _, _, err := DefaultDialer.Dial(makeWsProto(s.URL), http.Header{"Origin": {"http://other.example"}}); err == nil {
+		t.Fatal("Dial() succeeded, want error")
+	}
+	d := Dialer{EnableCompression: true}
+	c, _, err := d.Dial(makeWsProto(s.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.WriteMessage(TextMessage, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	during := expvarSnapshot(t)
+	c.Close()
+	c.Close()
+	after := expvarSnapshot(t)
+
+	delta := func(m map[string]interface{}, name string) float64 {
+		return m[name].(float64) - before[name].(float64)
+	}
+	tests := []struct {
+		name string
+		m    map[string]interface{}
+		want float64
+	}{
+		{"active_connections", during, 1},
+		{"active_connections", after, 0},
+		{"upgrades", after, 1},
+		{"dials", after, 1},
+		{"dial_failures", after, 1},
+		{"flate_pool_gets", after, 2},
+	}
+	for _, tt := range tests {
+		if got := delta(tt.m, tt.name); got != tt.want {
+			t.Errorf("%s changed by %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	failures := func(m map[string]interface{}) float64 {
+		n, _ := m["upgrade_failures"].(map[string]interface{})["origin"].(float64)
+		return n
+	}
+	if got := failures(after) - failures(before); got != 1 {
+		t.Errorf("origin upgrade failures changed by %v, want 1", got)
+	}
+	if rate := after["flate_pool_hit_rate"].(float64); rate < 0 || rate > 1 {
+		t.Errorf("flate_pool_hit_rate = %v", rate)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package filetransfer transfers files over websocket connections.
+//
+// A Sender splits a file into chunks with a CRC-32 checksum each and writes
+// them to the connection. A Receiver verifies the checksums, writes the
+// chunks to the destination and asks the sender to resend from the first
+// corrupt chunk. The transfer resumes after a reconnect: the application
+// calls Send again with the same file ID on the new connection and the
+// receiver's Open reports the number of bytes that it already has.
+//
+// The connection is used only by the transfer until Send and Receive
+// return. If a transfer fails, close the connection.
+//
+// # Protocol
+//
+// Control messages are text messages with a JSON object. The sender offers
+// a file with {"type":"offer","id":...,"name":...,"size":...} and the
+// receiver accepts it with {"type":"accept","offset":...}. A chunk is a
+// binary message of the 8 byte big-endian offset of the chunk, the 4 byte
+// big-endian CRC-32 (IEEE) of the data and the data. After the last chunk,
+// the sender sends {"type":"end"} and the receiver replies
+// {"type":"done"}. The receiver sends {"type":"resend","offset":...} to
+// request the chunks from the offset again. Either side sends
+// {"type":"error","error":...} to abort the transfer.
+package filetransfer
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/gorilla/websocket"
+)
+
+const chunkHeaderSize = 12
+
+// Message types.
+const (
+	typeOffer  = "offer"
+	typeAccept = "accept"
+	typeResend = "resend"
+	typeEnd    = "end"
+	typeDone   = "done"
+	typeError  = "error"
+)
+
+var (
+	errBadChunk   = errors.New("filetransfer: malformed chunk")
+	errBadOffset  = errors.New("filetransfer: offset out of range")
+	errBadMessage = errors.New("filetransfer: unexpected message")
+)
+
+// Offer describes a file offered by a Sender.
+type Offer struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// RemoteError is an error reported by the peer.
+type RemoteError struct {
+	Message string
+}
+
+func (e *RemoteError) Error() string {
+	return "filetransfer: peer error: " + e.Message
+}
+
+// message is a control message.
+type message struct {
+	Type   string `json:"type"`
+	*Offer        // set in offers
+	Offset int64  `json:"offset,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func writeMessage(c *websocket.Conn, m *message) error {
+	p, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return c.WriteMessage(websocket.TextMessage, p)
+}
+
+// readMessage reads a control message. Binary messages are returned as
+// chunks.
+func readMessage(c *websocket.Conn) (m *message, chunk []byte, err error) {
+	messageType, p, err := c.ReadMessage()
+	if err != nil {
+		return nil, nil, err
+	}
+	if messageType == websocket.BinaryMessage {
+		return nil, p, nil
+	}
+	m = &message{}
+	if err := json.Unmarshal(p, m); err != nil {
+		return nil, nil, fmt.Errorf("filetransfer: malformed message: %w", err)
+	}
+	if m.Type == typeError {
+		return nil, nil, &RemoteError{Message: m.Error}
+	}
+	return m, nil, nil
+}
+
+func appendChunk(b []byte, offset int64, data []byte) []byte {
+	b = binary.BigEndian.AppendUint64(b, uint64(offset))
+	b = binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(data))
+	return append(b, data...)
+}
+
+// parseChunk returns the offset and data of a chunk. ok is false if the
+// checksum does not match.
+func parseChunk(p []byte) (offset int64, data []byte, ok bool, err error) {
+	if len(p) < chunkHeaderSize {
+		return 0, nil, false, errBadChunk
+	}
+	offset = int64(binary.BigEndian.Uint64(p))
+	if offset < 0 {
+		return 0, nil, false, errBadChunk
+	}
+	sum := binary.BigEndian.Uint32(p[8:])
+	data = p[chunkHeaderSize:]
+	return offset, data, crc32.ChecksumIEEE(data) == sum, nil
+}
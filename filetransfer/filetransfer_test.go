@@ -0,0 +1,197 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package filetransfer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// memFile is an in-memory destination.
+type memFile struct {
+	mu sync.Mutex
+	b  []byte
+}
+
+func (f *memFile) WriteAt(p []byte, off int64) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if n := int(off) + len(p); n > len(f.b) {
+		f.b = append(f.b, make([]byte, n-len(f.b))...)
+	}
+	return copy(f.b[off:], p), nil
+}
+
+func (f *memFile) bytes() []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]byte(nil), f.b...)
+}
+
+// corruptFirstChunk flips a bit in the first binary message received.
+type corruptFirstChunk struct {
+	done bool
+}
+
+func (t *corruptFirstChunk) Outbound(messageType int, p []byte) ([]byte, error) {
+	return append([]byte(nil), p...), nil
+}
+
+func (t *corruptFirstChunk) Inbound(messageType int, p []byte) ([]byte, error) {
+	if messageType == websocket.BinaryMessage && !t.done {
+		t.done = true
+		p[len(p)-1] ^= 1
+	}
+	return p, nil
+}
+
+func testData(n int) []byte {
+	data := make([]byte, n)
+	rand.New(rand.NewSource(1)).Read(data)
+	return data
+}
+
+// transfer sends data with s and receives it into dst starting at offset.
+func transfer(t *testing.T, s *Sender, recv *websocket.Conn, send *websocket.Conn, data []byte, dst *memFile, offset int64) (Offer, error) {
+	t.Helper()
+	r := &Receiver{Open: func(o Offer) (w io.WriterAt, off int64, err error) {
+		return dst, offset, nil
+	}}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.Send(send, Offer{ID: "1", Name: "data.bin", Size: int64(len(data))}, bytes.NewReader(data))
+	}()
+	offer, err := r.Receive(recv)
+	if serr := <-errc; serr != nil && err == nil {
+		err = serr
+	}
+	return offer, err
+}
+
+func TestTransfer(t *testing.T) {
+	data := testData(100000)
+	tests := []struct {
+		name      string
+		offset    int64
+		transform websocket.PayloadTransform
+	}{
+		{"complete", 0, nil},
+		{"resume", 50000, nil},
+		{"corrupt chunk", 0, &corruptFirstChunk{}},
+		{"empty", 100000, nil},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := websocket.Pipe()
+			defer client.Close()
+			defer server.Close()
+			if tt.transform != nil {
+				server.SetPayloadTransform(tt.transform)
+			}
+			dst := &memFile{b: append([]byte(nil), data[:tt.offset]...)}
+			var first int64 = -1
+			s := &Sender{ChunkSize: 4096, OnProgress: func(offset, size int64) {
+				if first < 0 {
+					first = offset
+				}
+			}}
+			offer, err := transfer(t, s, server, client, data, dst, tt.offset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if offer.Name != "data.bin" || offer.Size != int64(len(data)) {
+				t.Errorf("offer = %+v", offer)
+			}
+			if !bytes.Equal(dst.bytes(), data) {
+				t.Error("received data differs from sent data")
+			}
+			if tt.offset < int64(len(data)) && first != tt.offset+4096 {
+				t.Errorf("first chunk ends at %d, want %d", first, tt.offset+4096)
+			}
+		})
+	}
+}
+
+func TestPause(t *testing.T) {
+	client, server := websocket.Pipe()
+	defer client.Close()
+	defer server.Close()
+	data := testData(10000)
+	var mu sync.Mutex
+	var progress int64
+	s := &Sender{ChunkSize: 1000, OnProgress: func(offset, size int64) {
+		mu.Lock()
+		progress = offset
+		mu.Unlock()
+	}}
+	s.Pause()
+	done := make(chan error, 1)
+	dst := &memFile{}
+	go func() {
+		_, err := transfer(t, s, server, client, data, dst, 0)
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	if progress != 0 {
+		t.Errorf("paused sender sent %d bytes", progress)
+	}
+	mu.Unlock()
+	s.Resume()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("transfer did not complete after Resume")
+	}
+	if !bytes.Equal(dst.bytes(), data) {
+		t.Error("received data differs from sent data")
+	}
+}
+
+func TestReject(t *testing.T) {
+	client, server := websocket.Pipe()
+	defer client.Close()
+	defer server.Close()
+	errc := make(chan error, 1)
+	go func() {
+		errc <- (&Sender{}).Send(client, Offer{ID: "1", Size: 1}, bytes.NewReader([]byte("x")))
+	}()
+	r := &Receiver{Open: func(Offer) (io.WriterAt, int64, error) {
+		return nil, 0, errors.New("disk full")
+	}}
+	if _, err := r.Receive(server); err == nil {
+		t.Error("Receive() returned nil error")
+	}
+	var re *RemoteError
+	if err := <-errc; !errors.As(err, &re) || re.Message != "disk full" {
+		t.Errorf("Send() error = %v, want remote error disk full", err)
+	}
+}
+
+func TestParseChunk(t *testing.T) {
+	p := appendChunk(nil, 42, []byte("data"))
+	off, data, ok, err := parseChunk(p)
+	if off != 42 || string(data) != "data" || !ok || err != nil {
+		t.Errorf("parseChunk() = %d, %q, %t, %v", off, data, ok, err)
+	}
+	p[len(p)-1] ^= 1
+	if _, _, ok, _ := parseChunk(p); ok {
+		t.Error("parseChunk() of corrupt chunk reported ok")
+	}
+	if _, _, _, err := parseChunk(p[:5]); err != errBadChunk {
+		t.Errorf("parseChunk() of short chunk error = %v, want %v", err, errBadChunk)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package filetransfer
+
+import (
+	"io"
+
+	"github.com/gorilla/websocket"
+)
+
+// Receiver receives files.
+type Receiver struct {
+	// Open is called with each offered file and returns the destination of
+	// the file and the number of bytes of the file that the destination
+	// already has from an interrupted transfer. The transfer continues at
+	// that offset. If Open returns an error, the offer is rejected.
+	Open func(offer Offer) (w io.WriterAt, offset int64, err error)
+
+	// OnProgress is called after each chunk is written to the destination
+	// with the offset of the end of the chunk and the size of the file.
+	OnProgress func(offset, size int64)
+}
+
+// Receive receives a file from c. Receive returns the offer of the file
+// after the complete file is written to the destination.
+func (r *Receiver) Receive(c *websocket.Conn) (Offer, error) {
+	m, _, err := readMessage(c)
+	if err != nil {
+		return Offer{}, err
+	}
+	if m == nil || m.Type != typeOffer || m.Offer == nil || m.Size < 0 {
+		return Offer{}, errBadMessage
+	}
+	offer := *m.Offer
+	w, offset, err := r.Open(offer)
+	if err == nil && (offset < 0 || offset > offer.Size) {
+		err = errBadOffset
+	}
+	if err != nil {
+		writeMessage(c, &message{Type: typeError, Error: err.Error()})
+		return offer, err
+	}
+	if err := writeMessage(c, &message{Type: typeAccept, Offset: offset}); err != nil {
+		return offer, err
+	}
+
+	// requested is the offset of the last resend request. Chunks before
+	// the requested chunk arrives are dropped.
+	requested := int64(-1)
+	resend := func() error {
+		if requested == offset {
+			return nil
+		}
+		requested = offset
+		return writeMessage(c, &message{Type: typeResend, Offset: offset})
+	}
+	for {
+		m, p, err := readMessage(c)
+		if err != nil {
+			return offer, err
+		}
+		if m != nil {
+			if m.Type != typeEnd {
+				return offer, errBadMessage
+			}
+			if offset == offer.Size {
+				return offer, writeMessage(c, &message{Type: typeDone})
+			}
+			// The sender has sent all chunks since the last request.
+			requested = -1
+			if err := resend(); err != nil {
+				return offer, err
+			}
+			continue
+		}
+
+		off, data, ok, err := parseChunk(p)
+		if err != nil {
+			return offer, err
+		}
+		if off != offset {
+			// A chunk sent before the sender received a resend request.
+			continue
+		}
+		if !ok {
+			if err := resend(); err != nil {
+				return offer, err
+			}
+			continue
+		}
+		if int64(len(data)) > offer.Size-offset {
+			return offer, errBadChunk
+		}
+		if _, err := w.WriteAt(data, off); err != nil {
+			writeMessage(c, &message{Type: typeError, Error: err.Error()})
+			return offer, err
+		}
+		offset += int64(len(data))
+		if r.OnProgress != nil {
+			r.OnProgress(offset, offer.Size)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package filetransfer
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultChunkSize = 64 << 10
+	defaultHighWater = 1 << 20
+
+	// backpressurePoll is the interval of checking the buffered amount of
+	// the connection while it is above the high water mark.
+	backpressurePoll = 10 * time.Millisecond
+)
+
+// Sender sends files. A Sender sends one file at a time. The configuration
+// must not be changed during a transfer.
+type Sender struct {
+	// ChunkSize is the maximum number of file bytes in a chunk. If ChunkSize
+	// is zero, 64 KiB is used.
+	ChunkSize int
+
+	// HighWater is the number of bytes buffered by the connection above
+	// which the sender waits before writing the next chunk. See
+	// websocket.Conn.BufferedAmount. If HighWater is zero, 1 MiB is used.
+	HighWater int
+
+	// OnProgress is called after each chunk is written with the offset of
+	// the end of the chunk and the size of the file.
+	OnProgress func(offset, size int64)
+
+	mu     sync.Mutex
+	paused chan struct{} // closed by Resume; nil if not paused
+}
+
+// Pause stops sending chunks until Resume is called. A paused transfer can
+// also be resumed on a new connection with Send.
+func (s *Sender) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.paused == nil {
+		s.paused = make(chan struct{})
+	}
+}
+
+// Resume continues a paused transfer.
+func (s *Sender) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.paused != nil {
+		close(s.paused)
+		s.paused = nil
+	}
+}
+
+func (s *Sender) pausedChan() chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+type reply struct {
+	m   *message
+	err error
+}
+
+// Send offers the file to the receiver on c and sends the file from r,
+// starting at the offset accepted by the receiver. Send returns when the
+// receiver has received the complete file.
+func (s *Sender) Send(c *websocket.Conn, offer Offer, r io.ReaderAt) error {
+	if err := writeMessage(c, &message{Type: typeOffer, Offer: &offer}); err != nil {
+		return err
+	}
+	m, _, err := readMessage(c)
+	if err != nil {
+		return err
+	}
+	if m == nil || m.Type != typeAccept || m.Offset < 0 || m.Offset > offer.Size {
+		return errBadMessage
+	}
+	offset := m.Offset
+
+	// Read the replies of the receiver while sending.
+	replies := make(chan reply, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			m, _, err := readMessage(c)
+			if err == nil && m == nil {
+				err = errBadMessage
+			}
+			select {
+			case replies <- reply{m, err}:
+			case <-stop:
+				return
+			}
+			if err != nil || m.Type == typeDone {
+				return
+			}
+		}
+	}()
+
+	// handle processes a reply and reports whether the transfer is done.
+	handle := func(rep reply) (bool, error) {
+		if rep.err != nil {
+			return false, rep.err
+		}
+		switch {
+		case rep.m.Type == typeDone && offset == offer.Size:
+			return true, nil
+		case rep.m.Type == typeResend && rep.m.Offset >= 0 && rep.m.Offset <= offset:
+			offset = rep.m.Offset
+			return false, nil
+		default:
+			return false, errBadMessage
+		}
+	}
+
+	chunkSize := s.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+	highWater := s.HighWater
+	if highWater <= 0 {
+		highWater = defaultHighWater
+	}
+	buf := make([]byte, chunkSize)
+	var chunk []byte
+	for {
+		if offset == offer.Size {
+			if err := writeMessage(c, &message{Type: typeEnd}); err != nil {
+				return err
+			}
+			done, err := handle(<-replies)
+			if done || err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Wait while the transfer is paused or the connection is backed up.
+		wait := s.pausedChan()
+		var poll <-chan time.Time
+		if wait == nil && c.BufferedAmount() > highWater {
+			poll = time.After(backpressurePoll)
+		}
+		if wait != nil || poll != nil {
+			select {
+			case rep := <-replies:
+				if _, err := handle(rep); err != nil {
+					return err
+				}
+			case <-wait:
+			case <-poll:
+			}
+			continue
+		}
+		select {
+		case rep := <-replies:
+			if _, err := handle(rep); err != nil {
+				return err
+			}
+			continue
+		default:
+		}
+
+		n := int64(chunkSize)
+		if rem := offer.Size - offset; rem < n {
+			n = rem
+		}
+		if k, err := r.ReadAt(buf[:n], offset); int64(k) < n {
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			writeMessage(c, &message{Type: typeError, Error: err.Error()})
+			return err
+		}
+		chunk = appendChunk(chunk[:0], offset, buf[:n])
+		if err := c.WriteMessage(websocket.BinaryMessage, chunk); err != nil {
+			return err
+		}
+		offset += n
+		if s.OnProgress != nil {
+			s.OnProgress(offset, offer.Size)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket/wsframe"
+)
+
+// flowControlExtension is the name of the flow control extension. Each peer
+// advertises its receive window in bytes with the window parameter:
+//
+//	Sec-WebSocket-Extensions: x-flow-control; window=65536
+//
+// A peer sends at most the receive window of the other peer in data frame
+// payload bytes until it receives credit. A receiver grants credit with a
+// credit frame, a control frame with opcode 0xB and a four byte big-endian
+// increment in bytes, after the application consumed the data.
+const flowControlExtension = "x-flow-control"
+
+// creditFrame is the opcode of the credit frames of the flow control
+// extension.
+const creditFrame = 11
+
+// maxFlowControlWindow is the largest window and the largest sum of the
+// credits of a peer.
+const maxFlowControlWindow = 1<<31 - 1
+
+var (
+	errInvalidFlowControl = errors.New("websocket: invalid flow control negotiation")
+	errFlowControlClosed  = errors.New("websocket: no flow control credit from a failed connection")
+)
+
+// flowControl is the state of the flow control extension of a connection.
+type flowControl struct {
+	// Receive side, used by the reading goroutine.
+	window   int64 // receive window advertised to the peer
+	recv     int64 // bytes the peer may send before the next grant
+	consumed int64 // bytes received since the last grant
+
+	// Send side.
+	mu     sync.Mutex
+	credit int64 // bytes that may be sent
+	closed bool
+	wake   chan struct{} // signaled when credit is added or on close
+}
+
+func newFlowControl(recvWindow, sendWindow int64) *flowControl {
+	return &flowControl{
+		window: recvWindow,
+		recv:   recvWindow,
+		credit: sendWindow,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// flowControlWindow returns the window configured by a dialer or upgrader
+// clamped to the largest window.
+func flowControlWindow(n int) int64 {
+	if int64(n) > maxFlowControlWindow {
+		return maxFlowControlWindow
+	}
+	return int64(n)
+}
+
+// flowControlOffer returns the extension offered or accepted with the
+// receive window.
+func flowControlOffer(window int64) string {
+	return flowControlExtension + "; window=" + strconv.FormatInt(window, 10)
+}
+
+// parseFlowControlWindow returns the window parameter of the extension.
+func parseFlowControlWindow(ext map[string]string) (int64, bool) {
+	n, err := strconv.ParseInt(ext["window"], 10, 64)
+	if err != nil || n <= 0 || n > maxFlowControlWindow {
+		return 0, false
+	}
+	return n, true
+}
+
+// FlowControlNegotiated reports whether the peers negotiated the flow
+// control extension in the handshake. With flow control, the writers wait
+// for credit from the peer once the peer's receive window is exhausted. The
+// credit is processed by the read methods like ping and pong messages, so
+// the application must read the connection concurrently with writing.
+func (c *Conn) FlowControlNegotiated() bool {
+	return c.flow != nil
+}
+
+// close wakes a writer waiting for credit that will never arrive.
+func (f *flowControl) close() {
+	f.mu.Lock()
+	f.closed = true
+	f.mu.Unlock()
+	f.signal()
+}
+
+func (f *flowControl) signal() {
+	select {
+	case f.wake <- struct{}{}:
+	default:
+	}
+}
+
+// addCredit adds the increment of a credit frame.
+func (f *flowControl) addCredit(n int64) bool {
+	f.mu.Lock()
+	ok := f.credit+n <= maxFlowControlWindow
+	if ok {
+		f.credit += n
+	}
+	f.mu.Unlock()
+	if ok {
+		f.signal()
+	}
+	return ok
+}
+
+// acquireCredit waits until there is credit and takes up to max bytes of it.
+func (c *Conn) acquireCredit(max int, deadline time.Time) (int, error) {
+	f := c.flow
+	var timer Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for {
+		f.mu.Lock()
+		if f.credit > 0 {
+			n := int64(max)
+			if n > f.credit {
+				n = f.credit
+			}
+			f.credit -= n
+			f.mu.Unlock()
+			return int(n), nil
+		}
+		closed := f.closed
+		f.mu.Unlock()
+
+		if closed {
+			c.writeErrMu.Lock()
+			err := c.writeErr
+			c.writeErrMu.Unlock()
+			if err == nil {
+				err = errFlowControlClosed
+			}
+			return 0, err
+		}
+		var timeout <-chan time.Time
+		if !deadline.IsZero() {
+			if timer == nil {
+				d := deadline.Sub(c.clock.Now())
+				if d <= 0 {
+					return 0, errWriteTimeout
+				}
+				timer = c.clock.NewTimer(d)
+			}
+			timeout = timer.C()
+		}
+		select {
+		case <-f.wake:
+		case <-timeout:
+			return 0, errWriteTimeout
+		}
+	}
+}
+
+// flushFlowFrames writes buffered data and extra as one or more frames that
+// fit the credit from the peer. It is the flushFrame of data frames with
+// flow control.
+func (w *messageWriter) flushFlowFrames(final bool, extra []byte) error {
+	c := w.c
+	bufs := [2][]byte{c.writeBuf[maxFrameHeaderSize:w.pos], extra}
+	var hdr [maxFrameHeaderSize]byte
+	for {
+		i := 0
+		if len(bufs[0]) == 0 {
+			i = 1
+		}
+		n := 0
+		if len(bufs[i]) > 0 {
+			var err error
+			if n, err = c.acquireCredit(len(bufs[i]), w.deadline); err != nil {
+				// Frames of the message may have been sent.
+				return w.endMessage(c.writeFatal(err))
+			}
+		}
+		p := bufs[i][:n]
+		bufs[i] = bufs[i][n:]
+		last := len(bufs[0])+len(bufs[1]) == 0
+
+		h := wsframe.Header{
+			Fin:    final && last,
+			Rsv1:   w.compress,
+			Opcode: wsframe.Opcode(w.frameType),
+			Masked: !c.isServer,
+			Length: int64(n),
+		}
+		if h.Masked {
+			h.Key = c.newMaskKey()
+			maskBytes(h.Key, 0, p)
+		}
+		w.compress = false
+
+		c.beginWrite()
+		err := c.write(w.frameType, w.deadline, wsframe.AppendHeader(hdr[:0], h), p)
+		c.endWrite()
+		if err != nil {
+			return w.endMessage(err)
+		}
+		w.progressed(n)
+		w.frameType = continuationFrame
+		if last {
+			break
+		}
+	}
+
+	c.writeBuffered.Store(0)
+	if final {
+		_ = w.endMessage(errWriteClosed)
+		return nil
+	}
+	w.pos = maxFrameHeaderSize
+	return nil
+}
+
+// flowReceive accounts a data frame of the current length against the
+// receive window.
+func (c *Conn) flowReceive() error {
+	f := c.flow
+	if c.readRemaining > f.recv {
+		return c.handleProtocolError(ViolationFlowControl, "flow control window exceeded")
+	}
+	f.recv -= c.readRemaining
+	f.consumed += c.readRemaining
+	return nil
+}
+
+// flowGrant sends credit for the data consumed by the application once it
+// is at least half of the receive window.
+func (c *Conn) flowGrant() error {
+	f := c.flow
+	if f.consumed == 0 || f.consumed < f.window/2 {
+		return nil
+	}
+	var p [4]byte
+	binary.BigEndian.PutUint32(p[:], uint32(f.consumed))
+	err := c.writeControl(creditFrame, p[:], c.clock.Now().Add(writeWait))
+	if err != nil {
+		if err == ErrCloseSent {
+			return nil
+		} else if e, ok := err.(net.Error); ok && e.Timeout() {
+			return nil
+		}
+		return err
+	}
+	f.recv += f.consumed
+	f.consumed = 0
+	return nil
+}
+
+// handleCredit processes the payload of a credit frame.
+func (c *Conn) handleCredit(payload []byte) error {
+	n := int64(binary.BigEndian.Uint32(payload))
+	if n == 0 {
+		return c.handleProtocolError(ViolationFlowControl, "zero credit")
+	}
+	if !c.flow.addCredit(n) {
+		return c.handleProtocolError(ViolationFlowControl, "credit exceeds maximum window")
+	}
+	return nil
+}
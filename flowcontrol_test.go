@@ -0,0 +1,209 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFlowControlNegotiation(t *testing.T) {
+	tests := []struct {
+		name           string
+		dialerWindow   int
+		upgraderWindow int
+		compression    bool
+		want           bool
+	}{
+		{"none", 0, 0, false, false},
+		{"dialer only", 1000, 0, false, false},
+		{"upgrader only", 0, 1000, false, false},
+		{"both", 1000, 2000, false, true},
+		{"both with compression", 1000, 2000, true, true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		serverConns := make(chan *Conn, 1)
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			u := Upgrader{FlowControlWindow: tt.upgraderWindow, EnableCompression: tt.compression}
+			c, err := u.Upgrade(w, r, nil)
+			if err != nil {
+				t.Errorf("%s: Upgrade() error = %v", tt.name, err)
+			}
+			serverConns <- c
+		}))
+		d := Dialer{FlowControlWindow: tt.dialerWindow, EnableCompression: tt.compression}
+		c, _, err := d.Dial(makeWsProto(s.URL), nil)
+		if err != nil {
+			t.Fatalf("%s: Dial() error = %v", tt.name, err)
+		}
+		sc := <-serverConns
+		if got := c.FlowControlNegotiated(); got != tt.want {
+			t.Errorf("%s: client FlowControlNegotiated() = %v, want %v", tt.name, got, tt.want)
+		}
+		if sc != nil {
+			if got := sc.FlowControlNegotiated(); got != tt.want {
+				t.Errorf("%s: server FlowControlNegotiated() = %v, want %v", tt.name, got, tt.want)
+			}
+			if tt.want && (sc.flow.credit != int64(tt.dialerWindow) || c.flow.credit != int64(tt.upgraderWindow)) {
+				t.Errorf("%s: credit = %d, %d, want %d, %d", tt.name, c.flow.credit, sc.flow.credit, tt.upgraderWindow, tt.dialerWindow)
+			}
+			sc.Close()
+		}
+		if got := c.CompressionNegotiated(); got != tt.compression {
+			t.Errorf("%s: CompressionNegotiated() = %v, want %v", tt.name, got, tt.compression)
+		}
+		c.Close()
+		s.Close()
+	}
+}
+
+// flowPipe returns connected connections with flow control.
+func flowPipe(window int64) (client, server *Conn) {
+	client, server = Pipe()
+	client.flow = newFlowControl(window, window)
+	server.flow = newFlowControl(window, window)
+	return client, server
+}
+
+func TestFlowControlWindowExhausted(t *testing.T) {
+	const window = 1000
+	client, server := flowPipe(window)
+	defer client.Close()
+	defer server.Close()
+
+	client.SetWriteDeadline(time.Now().Add(50 * time.Millisecond))
+	err := client.WriteMessage(BinaryMessage, make([]byte, 5*window))
+	var ne net.Error
+	if !errors.As(err, &ne) || !ne.Timeout() {
+		t.Fatalf("WriteMessage() error = %v, want timeout", err)
+	}
+
+	pb := server.conn.(*pipeConn).r
+	pb.mu.Lock()
+	sent := len(pb.buf)
+	pb.mu.Unlock()
+	if sent < window || sent > window+2*maxFrameHeaderSize {
+		t.Errorf("sent %d bytes, want the window of %d bytes and frame headers", sent, window)
+	}
+}
+
+func TestFlowControlCredit(t *testing.T) {
+	const window = 1000
+	tests := []struct {
+		name       string
+		fromServer bool
+		cfg        *PipeConfig
+	}{
+		{"client", false, nil},
+		{"server", true, nil},
+		{"compressed", false, &PipeConfig{EnableCompression: true}},
+	}
+	messages := [][]byte{
+		bytes.Repeat([]byte("a"), 5*window),
+		[]byte("hello"),
+		bytes.Repeat([]byte("b"), window),
+	}
+	for _, tt := range tests {
+		client, server := tt.cfg.Pipe()
+		client.flow = newFlowControl(window, window)
+		server.flow = newFlowControl(window, window)
+		client.EnableWriteCompression(true)
+		sender, receiver := client, server
+		if tt.fromServer {
+			sender, receiver = server, client
+		}
+
+		// Read the sending connection to process the credit frames.
+		readErr := make(chan error, 1)
+		go func() {
+			_, _, err := sender.NextReader()
+			readErr <- err
+		}()
+
+		writeErr := make(chan error, 1)
+		go func() {
+			for _, m := range messages {
+				if err := sender.WriteMessage(BinaryMessage, m); err != nil {
+					writeErr <- err
+					return
+				}
+			}
+			writeErr <- nil
+		}()
+
+		for _, m := range messages {
+			_, p, err := receiver.ReadMessage()
+			if err != nil || !bytes.Equal(p, m) {
+				t.Fatalf("%s: ReadMessage() = %d bytes, %v, want %d bytes", tt.name, len(p), err, len(m))
+			}
+		}
+		if err := <-writeErr; err != nil {
+			t.Fatalf("%s: WriteMessage() error = %v", tt.name, err)
+		}
+
+		receiver.WriteControl(CloseMessage, FormatCloseMessage(CloseNormalClosure, ""), time.Time{})
+		if err := <-readErr; !IsCloseError(err, CloseNormalClosure) {
+			t.Errorf("%s: sender NextReader() error = %v, want close error", tt.name, err)
+		}
+		client.Close()
+		server.Close()
+	}
+}
+
+func TestFlowControlViolation(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(c *Conn) error
+	}{
+		{"window exceeded", func(c *Conn) error {
+			return c.WriteMessage(BinaryMessage, make([]byte, 101))
+		}},
+		{"zero credit", func(c *Conn) error {
+			return c.writeControl(creditFrame, []byte{0, 0, 0, 0}, time.Time{})
+		}},
+		{"short credit", func(c *Conn) error {
+			return c.writeControl(creditFrame, []byte{0, 1}, time.Time{})
+		}},
+		{"credit overflow", func(c *Conn) error {
+			return c.writeControl(creditFrame, []byte{0x7f, 0xff, 0xff, 0xff}, time.Time{})
+		}},
+	}
+	for _, tt := range tests {
+		client, server := Pipe()
+		server.flow = newFlowControl(100, 100)
+		var kind ViolationKind
+		server.SetProtocolViolationHandler(func(v ProtocolViolation) { kind = v.Kind })
+		if err := tt.write(client); err != nil {
+			t.Fatalf("%s: write error = %v", tt.name, err)
+		}
+		_, _, err := server.ReadMessage()
+		var pe *ProtocolError
+		if !errors.As(err, &pe) || kind != ViolationFlowControl {
+			t.Errorf("%s: ReadMessage() error = %v, violation %q, want flow control violation", tt.name, err, kind)
+		}
+		client.Close()
+		server.Close()
+	}
+}
+
+func TestCreditFrameNotNegotiated(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	if err := client.writeControl(creditFrame, []byte{0, 0, 0, 1}, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err := server.ReadMessage()
+	var pe *ProtocolError
+	if !errors.As(err, &pe) || pe.Kind != ViolationOpcode {
+		t.Errorf("ReadMessage() error = %v, want opcode violation", err)
+	}
+}
@@ -7,3 +7,5 @@ retract (
 )
 
 require golang.org/x/net v0.26.0
+
+require golang.org/x/text v0.16.0 // indirect
@@ -0,0 +1,237 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphqlws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Client is a client connection for the graphql-transport-ws protocol.
+//
+// It is safe to call Client's methods concurrently.
+type Client struct {
+	ws *websocket.Conn
+
+	// AckPayload is the payload of the server's connection_ack message.
+	AckPayload json.RawMessage
+
+	writeMu sync.Mutex
+
+	mu     sync.Mutex
+	nextID uint64
+	subs   map[string]*Subscription
+	err    error
+}
+
+// NewClient initializes the protocol on ws and returns a client. The
+// connection should be dialed with the Subprotocol in Dialer.Subprotocols.
+//
+// NewClient sends a connection_init message with the given payload and
+// waits for the server's acknowledgement. The context deadline, if any,
+// bounds the wait.
+func NewClient(ctx context.Context, ws *websocket.Conn, payload json.RawMessage) (*Client, error) {
+	c := &Client{ws: ws, subs: make(map[string]*Subscription)}
+	if err := c.write(&message{Type: typeConnectionInit, Payload: payload}); err != nil {
+		return nil, err
+	}
+
+	deadline, _ := ctx.Deadline()
+	if err := ws.SetReadDeadline(deadline); err != nil {
+		return nil, err
+	}
+	for {
+		var m message
+		if err := ws.ReadJSON(&m); err != nil {
+			return nil, err
+		}
+		switch m.Type {
+		case typeConnectionAck:
+			if err := ws.SetReadDeadline(time.Time{}); err != nil {
+				return nil, err
+			}
+			c.AckPayload = m.Payload
+			ws.Go(c.readLoop)
+			return c, nil
+		case typePing:
+			if err := c.write(&message{Type: typePong, Payload: m.Payload}); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// Close closes the connection with a normal closure.
+func (c *Client) Close() error {
+	_ = c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(writeWait))
+	return c.ws.Close()
+}
+
+// Ping sends a ping message to the server.
+func (c *Client) Ping(payload json.RawMessage) error {
+	return c.write(&message{Type: typePing, Payload: payload})
+}
+
+func (c *Client) write(m *message) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.ws.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+		return err
+	}
+	return c.ws.WriteJSON(m)
+}
+
+func (c *Client) readLoop() {
+	var err error
+	for {
+		var m message
+		if err = c.ws.ReadJSON(&m); err != nil {
+			break
+		}
+		switch m.Type {
+		case typePing:
+			err = c.write(&message{Type: typePong, Payload: m.Payload})
+		case typeNext, typeError, typeComplete:
+			c.mu.Lock()
+			s := c.subs[m.ID]
+			if m.Type != typeNext {
+				delete(c.subs, m.ID)
+			}
+			c.mu.Unlock()
+			if s != nil {
+				s.deliver(&m)
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	c.mu.Lock()
+	c.err = err
+	subs := c.subs
+	c.subs = nil
+	c.mu.Unlock()
+	for _, s := range subs {
+		s.finish(err)
+	}
+}
+
+// Subscribe starts the operation on the server.
+func (c *Client) Subscribe(op *Operation) (*Subscription, error) {
+	payload, err := json.Marshal(op)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Subscription{c: c, results: make(chan json.RawMessage, 16), done: make(chan struct{})}
+	c.mu.Lock()
+	if c.subs == nil {
+		err := c.err
+		c.mu.Unlock()
+		return nil, err
+	}
+	c.nextID++
+	s.id = strconv.FormatUint(c.nextID, 10)
+	c.subs[s.id] = s
+	c.mu.Unlock()
+
+	if err := c.write(&message{Type: typeSubscribe, ID: s.id, Payload: payload}); err != nil {
+		c.mu.Lock()
+		delete(c.subs, s.id)
+		c.mu.Unlock()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Subscription is an operation started with Client.Subscribe.
+type Subscription struct {
+	c       *Client
+	id      string
+	results chan json.RawMessage
+
+	once sync.Once
+	done chan struct{}
+	err  error
+}
+
+// ID returns the operation ID.
+func (s *Subscription) ID() string {
+	return s.id
+}
+
+func (s *Subscription) deliver(m *message) {
+	switch m.Type {
+	case typeNext:
+		select {
+		case s.results <- m.Payload:
+		case <-s.done:
+		}
+	case typeError:
+		var errs Errors
+		if err := json.Unmarshal(m.Payload, &errs); err != nil || len(errs) == 0 {
+			errs = Errors{{Message: "invalid error payload"}}
+		}
+		s.finish(errs)
+	case typeComplete:
+		s.finish(io.EOF)
+	}
+}
+
+func (s *Subscription) finish(err error) {
+	s.once.Do(func() {
+		if err == nil {
+			err = errors.New("graphqlws: connection closed")
+		}
+		s.err = err
+		close(s.done)
+	})
+}
+
+// Next returns the next execution result. Next returns io.EOF when the
+// server completes the operation and an Errors value when the operation
+// fails.
+func (s *Subscription) Next(ctx context.Context) (json.RawMessage, error) {
+	select {
+	case r := <-s.results:
+		return r, nil
+	default:
+	}
+	select {
+	case r := <-s.results:
+		return r, nil
+	case <-s.done:
+		select {
+		case r := <-s.results:
+			return r, nil
+		default:
+		}
+		return nil, s.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close completes the operation. The server stops sending results.
+func (s *Subscription) Close() error {
+	c := s.c
+	c.mu.Lock()
+	_, active := c.subs[s.id]
+	delete(c.subs, s.id)
+	c.mu.Unlock()
+	s.finish(io.EOF)
+	if !active {
+		return nil
+	}
+	return c.write(&message{Type: typeComplete, ID: s.id})
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package graphqlws implements the GraphQL over WebSocket protocol
+// (graphql-transport-ws) used by Apollo, urql and other GraphQL clients.
+//
+// The protocol is specified at
+// https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md.
+//
+// The Upgrader must negotiate the protocol's subprotocol:
+//
+//	var upgrader = websocket.Upgrader{
+//	    Subprotocols: []string{graphqlws.Subprotocol},
+//	}
+//
+// The package does not execute GraphQL operations. Applications plug in an
+// executor with the Server Execute field.
+package graphqlws
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Subprotocol is the WebSocket subprotocol name of the protocol.
+const Subprotocol = "graphql-transport-ws"
+
+// Message types.
+const (
+	typeConnectionInit = "connection_init"
+	typeConnectionAck  = "connection_ack"
+	typePing           = "ping"
+	typePong           = "pong"
+	typeSubscribe      = "subscribe"
+	typeNext           = "next"
+	typeError          = "error"
+	typeComplete       = "complete"
+)
+
+// Close codes defined by the protocol.
+const (
+	CloseInternalServerError     = 4500
+	CloseBadRequest              = 4400
+	CloseUnauthorized            = 4401
+	CloseForbidden               = 4403
+	CloseInitTimeout             = 4408
+	CloseSubscriberAlreadyExists = 4409
+	CloseTooManyInitRequests     = 4429
+)
+
+type message struct {
+	Type    string          `json:"type"`
+	ID      string          `json:"id,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Operation is the payload of a subscribe message.
+type Operation struct {
+	OperationName string                 `json:"operationName,omitempty"`
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	Extensions    map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Error is a GraphQL error.
+type Error struct {
+	Message    string                 `json:"message"`
+	Locations  []Location             `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Location is a location in a GraphQL document.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Errors is a list of GraphQL errors. Errors is the payload of an error
+// message.
+type Errors []*Error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Message
+	}
+	return "graphqlws: " + strings.Join(msgs, "; ")
+}
+
+func toErrors(err error) Errors {
+	if e, ok := err.(Errors); ok {
+		return e
+	}
+	return Errors{{Message: err.Error()}}
+}
@@ -0,0 +1,222 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphqlws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultInitTimeout = 3 * time.Second
+	writeWait          = 10 * time.Second
+)
+
+// Server serves the graphql-transport-ws protocol on WebSocket connections.
+//
+// It is safe to call Server's methods concurrently.
+type Server struct {
+	// Execute executes the operation in a subscribe message. Execute sends
+	// execution results to the returned channel and closes the channel when
+	// the operation is complete. The context is canceled when the client
+	// completes the operation or the connection is closed.
+	//
+	// If Execute returns an error, the error is sent to the client in an
+	// error message. Return an Errors value to control the GraphQL errors
+	// sent to the client.
+	Execute func(ctx context.Context, op *Operation) (<-chan json.RawMessage, error)
+
+	// Init is called with the payload of the connection_init message. The
+	// returned payload is sent to the client in the connection_ack message.
+	// If Init returns an error, the connection is closed with
+	// CloseForbidden. If Init is nil, all connections are acknowledged.
+	Init func(ctx context.Context, payload json.RawMessage) (json.RawMessage, error)
+
+	// InitTimeout specifies the time allowed for the client to send the
+	// connection_init message. If zero, a default of three seconds is used.
+	InitTimeout time.Duration
+}
+
+type serverConn struct {
+	s      *Server
+	ws     *websocket.Conn
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	writeMu sync.Mutex
+
+	mu    sync.Mutex
+	init  bool
+	acked bool
+	subs  map[string]context.CancelFunc
+}
+
+// Serve serves the protocol on ws until the connection is closed. Serve
+// closes ws before returning.
+func (s *Server) Serve(ws *websocket.Conn) error {
+	c := &serverConn{s: s, ws: ws, subs: make(map[string]context.CancelFunc)}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	defer c.cancel()
+	defer ws.Close()
+
+	initTimeout := s.InitTimeout
+	if initTimeout == 0 {
+		initTimeout = defaultInitTimeout
+	}
+	timer := time.AfterFunc(initTimeout, func() {
+		c.mu.Lock()
+		init := c.init
+		c.mu.Unlock()
+		if !init {
+			c.close(CloseInitTimeout, "Connection initialisation timeout")
+		}
+	})
+	defer timer.Stop()
+
+	for {
+		_, p, err := ws.ReadMessage()
+		if err != nil {
+			return err
+		}
+		var m message
+		if err := json.Unmarshal(p, &m); err != nil {
+			c.close(CloseBadRequest, "Invalid message received")
+			return err
+		}
+		if err := c.handle(&m); err != nil {
+			return err
+		}
+	}
+}
+
+var errClosed = errors.New("graphqlws: connection closed by server")
+
+func (c *serverConn) handle(m *message) error {
+	switch m.Type {
+	case typeConnectionInit:
+		c.mu.Lock()
+		init := c.init
+		c.init = true
+		c.mu.Unlock()
+		if init {
+			c.close(CloseTooManyInitRequests, "Too many initialisation requests")
+			return errClosed
+		}
+		var payload json.RawMessage
+		if c.s.Init != nil {
+			var err error
+			payload, err = c.s.Init(c.ctx, m.Payload)
+			if err != nil {
+				c.close(CloseForbidden, "Forbidden")
+				return errClosed
+			}
+		}
+		if err := c.write(&message{Type: typeConnectionAck, Payload: payload}); err != nil {
+			return err
+		}
+		c.mu.Lock()
+		c.acked = true
+		c.mu.Unlock()
+	case typePing:
+		return c.write(&message{Type: typePong, Payload: m.Payload})
+	case typePong:
+	case typeSubscribe:
+		return c.subscribe(m)
+	case typeComplete:
+		c.mu.Lock()
+		cancel := c.subs[m.ID]
+		delete(c.subs, m.ID)
+		c.mu.Unlock()
+		if cancel != nil {
+			cancel()
+		}
+	default:
+		c.close(CloseBadRequest, "Invalid message received")
+		return errClosed
+	}
+	return nil
+}
+
+func (c *serverConn) subscribe(m *message) error {
+	var op Operation
+	if m.ID == "" || json.Unmarshal(m.Payload, &op) != nil {
+		c.close(CloseBadRequest, "Invalid message received")
+		return errClosed
+	}
+
+	c.mu.Lock()
+	if !c.acked {
+		c.mu.Unlock()
+		c.close(CloseUnauthorized, "Unauthorized")
+		return errClosed
+	}
+	if _, ok := c.subs[m.ID]; ok {
+		c.mu.Unlock()
+		c.close(CloseSubscriberAlreadyExists, "Subscriber for "+m.ID+" already exists")
+		return errClosed
+	}
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.subs[m.ID] = cancel
+	c.mu.Unlock()
+
+	go c.execute(ctx, m.ID, &op)
+	return nil
+}
+
+func (c *serverConn) execute(ctx context.Context, id string, op *Operation) {
+	defer func() {
+		c.mu.Lock()
+		cancel := c.subs[id]
+		delete(c.subs, id)
+		c.mu.Unlock()
+		if cancel != nil {
+			cancel()
+		}
+	}()
+
+	results, err := c.s.Execute(ctx, op)
+	if err != nil {
+		p, _ := json.Marshal(toErrors(err))
+		_ = c.write(&message{Type: typeError, ID: id, Payload: p})
+		return
+	}
+	for {
+		select {
+		case result, ok := <-results:
+			if !ok {
+				if ctx.Err() == nil {
+					_ = c.write(&message{Type: typeComplete, ID: id})
+				}
+				return
+			}
+			if err := c.write(&message{Type: typeNext, ID: id, Payload: result}); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			// Completed by the client or the connection is closed. Do not
+			// send a complete message.
+			return
+		}
+	}
+}
+
+func (c *serverConn) write(m *message) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.ws.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+		return err
+	}
+	return c.ws.WriteJSON(m)
+}
+
+func (c *serverConn) close(code int, text string) {
+	_ = c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(writeWait))
+	c.ws.Close()
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphqlws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func newTestServer(t *testing.T, s *Server) string {
+	upgrader := websocket.Upgrader{Subprotocols: []string{Subprotocol}}
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		s.Serve(ws)
+	}))
+	t.Cleanup(hs.Close)
+	return "ws" + strings.TrimPrefix(hs.URL, "http")
+}
+
+func dial(t *testing.T, url string) *websocket.Conn {
+	d := websocket.Dialer{Subprotocols: []string{Subprotocol}}
+	ws, _, err := d.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { ws.Close() })
+	if ws.Subprotocol() != Subprotocol {
+		t.Fatalf("Subprotocol() = %q, want %q", ws.Subprotocol(), Subprotocol)
+	}
+	return ws
+}
+
+var countServer = &Server{
+	Init: func(ctx context.Context, payload json.RawMessage) (json.RawMessage, error) {
+		if string(payload) != `{"token":"secret"}` {
+			return nil, errors.New("bad token")
+		}
+		return json.RawMessage(`{"ok":true}`), nil
+	},
+	Execute: func(ctx context.Context, op *Operation) (<-chan json.RawMessage, error) {
+		if op.Query == "bad" {
+			return nil, errors.New("syntax error")
+		}
+		n := int(op.Variables["n"].(float64))
+		ch := make(chan json.RawMessage)
+		go func() {
+			defer close(ch)
+			for i := 0; n < 0 || i < n; i++ {
+				select {
+				case ch <- json.RawMessage(`{"data":{"count":` + string(rune('0'+i%10)) + `}}`):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return ch, nil
+	},
+}
+
+func TestSubscribe(t *testing.T) {
+	ctx := context.Background()
+	c, err := NewClient(ctx, dial(t, newTestServer(t, countServer)), json.RawMessage(`{"token":"secret"}`))
+	if err != nil {
+		t.Fatalf("NewClient returned error %v", err)
+	}
+	defer c.Close()
+	if string(c.AckPayload) != `{"ok":true}` {
+		t.Errorf("AckPayload = %s, want %s", c.AckPayload, `{"ok":true}`)
+	}
+
+	s, err := c.Subscribe(&Operation{Query: "subscription { count }", Variables: map[string]interface{}{"n": 3}})
+	if err != nil {
+		t.Fatalf("Subscribe returned error %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		r, err := s.Next(ctx)
+		if err != nil {
+			t.Fatalf("Next returned error %v", err)
+		}
+		want := `{"data":{"count":` + string(rune('0'+i)) + `}}`
+		if string(r) != want {
+			t.Errorf("Next returned %s, want %s", r, want)
+		}
+	}
+	if _, err := s.Next(ctx); err != io.EOF {
+		t.Errorf("Next after complete returned error %v, want io.EOF", err)
+	}
+
+	s, err = c.Subscribe(&Operation{Query: "bad"})
+	if err != nil {
+		t.Fatalf("Subscribe returned error %v", err)
+	}
+	var errs Errors
+	if _, err := s.Next(ctx); !errors.As(err, &errs) || errs[0].Message != "syntax error" {
+		t.Errorf("Next returned error %v, want syntax error", err)
+	}
+
+	// Client completes an unbounded subscription.
+	s, err = c.Subscribe(&Operation{Query: "subscription { count }", Variables: map[string]interface{}{"n": -1}})
+	if err != nil {
+		t.Fatalf("Subscribe returned error %v", err)
+	}
+	if _, err := s.Next(ctx); err != nil {
+		t.Fatalf("Next returned error %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close returned error %v", err)
+	}
+	if err := c.Ping(nil); err != nil {
+		t.Fatalf("Ping returned error %v", err)
+	}
+}
+
+func expectClose(t *testing.T, ws *websocket.Conn, code int) {
+	t.Helper()
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, _, err := ws.ReadMessage()
+		if err == nil {
+			continue
+		}
+		if !websocket.IsCloseError(err, code) {
+			t.Errorf("read returned error %v, want close %d", err, code)
+		}
+		return
+	}
+}
+
+func TestServerCloseCodes(t *testing.T) {
+	url := newTestServer(t, &Server{Execute: countServer.Execute, InitTimeout: 50 * time.Millisecond})
+	var tests = []struct {
+		name     string
+		messages []string
+		code     int
+	}{
+		{"timeout", nil, CloseInitTimeout},
+		{"invalid", []string{`{`}, CloseBadRequest},
+		{"unknown type", []string{`{"type":"foo"}`}, CloseBadRequest},
+		{"unauthorized", []string{`{"type":"subscribe","id":"1","payload":{"query":"x"}}`}, CloseUnauthorized},
+		{"double init", []string{`{"type":"connection_init"}`, `{"type":"connection_init"}`}, CloseTooManyInitRequests},
+		{"duplicate id", []string{
+			`{"type":"connection_init"}`,
+			`{"type":"subscribe","id":"1","payload":{"query":"x","variables":{"n":-1}}}`,
+			`{"type":"subscribe","id":"1","payload":{"query":"x","variables":{"n":-1}}}`,
+		}, CloseSubscriberAlreadyExists},
+	}
+	for _, tt := range tests {
+		ws := dial(t, url)
+		for _, m := range tt.messages {
+			if err := ws.WriteMessage(websocket.TextMessage, []byte(m)); err != nil {
+				t.Fatalf("%s: WriteMessage: %v", tt.name, err)
+			}
+		}
+		expectClose(t, ws, tt.code)
+	}
+}
+
+func TestServerForbidden(t *testing.T) {
+	ws := dial(t, newTestServer(t, countServer))
+	if _, err := NewClient(context.Background(), ws, json.RawMessage(`{"token":"wrong"}`)); !websocket.IsCloseError(err, CloseForbidden) {
+		t.Errorf("NewClient returned error %v, want close %d", err, CloseForbidden)
+	}
+}
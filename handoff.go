@@ -0,0 +1,133 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bufio"
+	"errors"
+	"net"
+)
+
+var (
+	// ErrHandedOff is returned by the read and write methods of a
+	// connection after Handoff.
+	ErrHandedOff = errors.New("websocket: connection handed off")
+
+	errHandoffBusy         = errors.New("websocket: handoff during a message")
+	errHandoffDeflateFrame = errors.New("websocket: handoff of x-webkit-deflate-frame connection")
+)
+
+// HandoffState is the state of a connection that is handed off to another
+// process, for example during a restart without downtime. HandoffState can
+// be encoded with encoding/json.
+type HandoffState struct {
+	IsServer    bool   `json:"isServer"`
+	Subprotocol string `json:"subprotocol,omitempty"`
+	Version     string `json:"version,omitempty"`
+
+	// Compression reports whether permessage-deflate was negotiated. The
+	// package does not use context takeover, so no compressor state is
+	// handed off.
+	Compression bool `json:"compression,omitempty"`
+
+	// MaxWindowBits is the largest compression window accepted by the peer,
+	// or zero for the default.
+	MaxWindowBits int `json:"maxWindowBits,omitempty"`
+
+	ReadLimit int64 `json:"readLimit,omitempty"`
+
+	// Buffered is the data read from the network connection that the
+	// connection has not processed yet.
+	Buffered []byte `json:"buffered,omitempty"`
+}
+
+// Handoff detaches the connection from its network connection and returns
+// the state of the connection and the network connection. The other
+// process reconstructs the connection with NewConnFromHandoff. The
+// subpackage handoff passes connections between processes over a Unix
+// socket.
+//
+// Handoff must be called between messages: after the application has read
+// the last message to EOF, with no open writer and while no other goroutine
+// reads or writes. Connections that negotiated x-webkit-deflate-frame cannot
+// be handed off. After Handoff, the read and write methods return
+// ErrHandedOff. Do not call Close, which closes the network connection.
+//
+// The handlers, limits other than the read limit and other settings of the
+// connection are not part of the state. The application sets them again on
+// the reconstructed connection.
+func (c *Conn) Handoff() (*HandoffState, net.Conn, error) {
+	if c.readErr != nil {
+		return nil, nil, c.readErr
+	}
+	if c.readRemaining != 0 || !c.readFinal || c.readDropping || c.writer != nil {
+		return nil, nil, errHandoffBusy
+	}
+	if c.deflateFrame != nil {
+		return nil, nil, errHandoffDeflateFrame
+	}
+	state := &HandoffState{
+		IsServer:      c.isServer,
+		Subprotocol:   c.subprotocol,
+		Version:       c.version,
+		Compression:   c.newCompressionWriter != nil,
+		MaxWindowBits: c.compressParams.maxWindowBits,
+		ReadLimit:     c.readLimit,
+	}
+	if n := c.br.Buffered(); n > 0 {
+		p, _ := c.br.Peek(n)
+		state.Buffered = append([]byte(nil), p...)
+		c.br.Discard(n)
+	}
+	c.readErr = ErrHandedOff
+	c.writeErrMu.Lock()
+	c.writeErr = ErrHandedOff
+	c.writeErrMu.Unlock()
+	c.stopContext()
+	c.releaseMemory()
+	return state, c.conn, nil
+}
+
+// bufferedConn is a net.Conn that returns the buffered data before reading
+// from the connection.
+type bufferedConn struct {
+	net.Conn
+	buffered []byte
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	if len(c.buffered) > 0 {
+		n := copy(p, c.buffered)
+		c.buffered = c.buffered[n:]
+		return n, nil
+	}
+	return c.Conn.Read(p)
+}
+
+// NewConnFromHandoff returns a connection on netConn with the state of a
+// connection returned by Handoff. The buffer sizes are interpreted as in
+// Upgrader.
+func NewConnFromHandoff(netConn net.Conn, state *HandoffState, readBufferSize, writeBufferSize int) *Conn {
+	var br *bufio.Reader
+	if len(state.Buffered) > 0 {
+		if readBufferSize == 0 {
+			readBufferSize = defaultReadBufferSize
+		} else if readBufferSize < maxControlFramePayloadSize {
+			readBufferSize = maxControlFramePayloadSize
+		}
+		r := &bufferedConn{Conn: netConn, buffered: state.Buffered}
+		br = bufio.NewReaderSize(r, readBufferSize)
+	}
+	c := newConn(netConn, state.IsServer, readBufferSize, writeBufferSize, nil, br, nil)
+	c.subprotocol = state.Subprotocol
+	c.version = state.Version
+	if state.Compression {
+		c.newCompressionWriter = compressNoContextTakeover
+		c.newDecompressionReader = decompressNoContextTakeover
+		c.compressParams.maxWindowBits = state.MaxWindowBits
+	}
+	c.readLimit = state.ReadLimit
+	return c
+}
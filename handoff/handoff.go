@@ -0,0 +1,164 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build unix
+
+// Package handoff passes websocket connections between processes over a
+// Unix socket, so that a server can restart without dropping connections.
+//
+// The old process stops reading and writing, calls Send and exits. The new
+// process calls Receive and continues to serve the connections. Each
+// connection is handed off between messages; see websocket.Conn.Handoff.
+//
+// The Unix socket must preserve message boundaries. Use a socket of type
+// "unixpacket" or "unixgram", for example from net.ListenUnix("unixpacket",
+// addr) in the new process and net.DialUnix("unixpacket", nil, addr) in the
+// old process.
+package handoff
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"syscall"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxMessageSize is the maximum size of an encoded connection state.
+const maxMessageSize = 1 << 20
+
+var (
+	errBadMessage = errors.New("handoff: malformed message")
+	errNoFile     = errors.New("handoff: network connection does not support File")
+)
+
+// message is sent for each connection with the connection's file descriptor.
+// The last message has More set to false. If no connections are handed off,
+// a single message without State and file descriptor is sent.
+type message struct {
+	State *websocket.HandoffState `json:"state,omitempty"`
+	More  bool                    `json:"more,omitempty"`
+}
+
+type filer interface {
+	File() (*os.File, error)
+}
+
+// Send hands off the connections to the process on the other end of uc.
+// Send closes the network connections in this process after they are sent;
+// the connections remain open in the receiving process. If Send returns an
+// error, the connections not yet sent are left as they are.
+func Send(uc *net.UnixConn, conns []*websocket.Conn) error {
+	if len(conns) == 0 {
+		return send(uc, &message{}, nil)
+	}
+	for i, c := range conns {
+		fc, ok := c.NetConn().(filer)
+		if !ok {
+			return errNoFile
+		}
+		f, err := fc.File()
+		if err != nil {
+			return err
+		}
+		state, netConn, err := c.Handoff()
+		if err != nil {
+			f.Close()
+			return err
+		}
+		err = send(uc, &message{State: state, More: i < len(conns)-1}, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		netConn.Close()
+	}
+	return nil
+}
+
+func send(uc *net.UnixConn, m *message, f *os.File) error {
+	p, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	var oob []byte
+	if f != nil {
+		oob = syscall.UnixRights(int(f.Fd()))
+	}
+	_, _, err = uc.WriteMsgUnix(p, oob, nil)
+	return err
+}
+
+// Receive receives the connections sent with Send from the process on the
+// other end of uc. The buffer sizes are interpreted as in
+// websocket.Upgrader. The application sets the handlers and other settings
+// of the connections again.
+func Receive(uc *net.UnixConn, readBufferSize, writeBufferSize int) ([]*websocket.Conn, error) {
+	var conns []*websocket.Conn
+	fail := func(err error) ([]*websocket.Conn, error) {
+		for _, c := range conns {
+			c.NetConn().Close()
+		}
+		return nil, err
+	}
+	p := make([]byte, maxMessageSize)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	for {
+		n, oobn, _, _, err := uc.ReadMsgUnix(p, oob)
+		if err != nil {
+			return fail(err)
+		}
+		f, err := parseFile(oob[:oobn])
+		if err != nil {
+			return fail(err)
+		}
+		var m message
+		if err := json.Unmarshal(p[:n], &m); err != nil || (m.State == nil) != (f == nil) {
+			if f != nil {
+				f.Close()
+			}
+			return fail(errBadMessage)
+		}
+		if f != nil {
+			netConn, err := net.FileConn(f)
+			f.Close()
+			if err != nil {
+				return fail(err)
+			}
+			conns = append(conns, websocket.NewConnFromHandoff(netConn, m.State, readBufferSize, writeBufferSize))
+		}
+		if !m.More {
+			return conns, nil
+		}
+	}
+}
+
+// parseFile returns the file descriptor in the control message oob as a
+// file, or nil if there is none.
+func parseFile(oob []byte) (*os.File, error) {
+	if len(oob) == 0 {
+		return nil, nil
+	}
+	scms, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil {
+		return nil, err
+	}
+	var fds []int
+	for i := range scms {
+		rights, err := syscall.ParseUnixRights(&scms[i])
+		if err != nil {
+			return nil, err
+		}
+		fds = append(fds, rights...)
+	}
+	if len(fds) != 1 {
+		for _, fd := range fds {
+			syscall.Close(fd)
+		}
+		return nil, errBadMessage
+	}
+	return os.NewFile(uintptr(fds[0]), "websocket"), nil
+}
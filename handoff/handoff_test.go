@@ -0,0 +1,116 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build unix
+
+package handoff
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// socketPair returns two connected Unix sockets that preserve message
+// boundaries.
+func socketPair(t *testing.T) (*net.UnixConn, *net.UnixConn) {
+	t.Helper()
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_DGRAM, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conns [2]*net.UnixConn
+	for i, fd := range fds {
+		f := os.NewFile(uintptr(fd), "socketpair")
+		c, err := net.FileConn(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conns[i] = c.(*net.UnixConn)
+		t.Cleanup(func() { c.Close() })
+	}
+	return conns[0], conns[1]
+}
+
+func TestHandoff(t *testing.T) {
+	const n = 3
+	servers := make(chan *websocket.Conn, n)
+	upgrader := websocket.Upgrader{Subprotocols: []string{"chat"}}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		servers <- c
+	}))
+	defer s.Close()
+
+	var clients, old []*websocket.Conn
+	dialer := websocket.Dialer{Subprotocols: []string{"chat"}}
+	for i := 0; i < n; i++ {
+		c, _, err := dialer.Dial("ws"+strings.TrimPrefix(s.URL, "http"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		clients = append(clients, c)
+		old = append(old, <-servers)
+	}
+
+	// Messages sent before the handoff are read by the new process.
+	for i, c := range clients {
+		if err := c.WriteMessage(websocket.TextMessage, []byte(fmt.Sprint("before ", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a, b := socketPair(t)
+	errc := make(chan error, 1)
+	go func() { errc <- Send(a, old) }()
+	conns, err := Receive(b, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if len(conns) != n {
+		t.Fatalf("received %d connections, want %d", len(conns), n)
+	}
+
+	for i, c := range conns {
+		defer c.Close()
+		if c.Subprotocol() != "chat" {
+			t.Errorf("Subprotocol() = %q, want chat", c.Subprotocol())
+		}
+		if _, p, err := c.ReadMessage(); err != nil || string(p) != fmt.Sprint("before ", i) {
+			t.Fatalf("ReadMessage() = %q, %v", p, err)
+		}
+		if err := c.WriteMessage(websocket.TextMessage, []byte(fmt.Sprint("after ", i))); err != nil {
+			t.Fatal(err)
+		}
+		if _, p, err := clients[i].ReadMessage(); err != nil || string(p) != fmt.Sprint("after ", i) {
+			t.Fatalf("client ReadMessage() = %q, %v", p, err)
+		}
+	}
+}
+
+func TestHandoffNone(t *testing.T) {
+	a, b := socketPair(t)
+	if err := Send(a, nil); err != nil {
+		t.Fatal(err)
+	}
+	conns, err := Receive(b, 0, 0)
+	if err != nil || len(conns) != 0 {
+		t.Errorf("Receive() = %v, %v, want no connections", conns, err)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestHandoff(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		client, server := (&PipeConfig{Subprotocol: "chat", EnableCompression: compress}).Pipe()
+		server.SetReadLimit(1 << 20)
+		for _, m := range []string{"first", "second", "third"} {
+			if err := client.WriteMessage(TextMessage, []byte(m)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, p, err := server.ReadMessage(); err != nil || string(p) != "first" {
+			t.Fatalf("ReadMessage() = %q, %v", p, err)
+		}
+
+		state, netConn, err := server.Handoff()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := server.ReadMessage(); err != ErrHandedOff {
+			t.Errorf("ReadMessage() after Handoff error = %v, want %v", err, ErrHandedOff)
+		}
+		if err := server.WriteMessage(TextMessage, nil); err != ErrHandedOff {
+			t.Errorf("WriteMessage() after Handoff error = %v, want %v", err, ErrHandedOff)
+		}
+
+		// The state survives encoding as it does between processes.
+		p, err := json.Marshal(state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded HandoffState
+		if err := json.Unmarshal(p, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if !decoded.IsServer || decoded.Subprotocol != "chat" || decoded.Compression != compress || decoded.ReadLimit != 1<<20 {
+			t.Errorf("state = %+v", decoded)
+		}
+
+		c := NewConnFromHandoff(netConn, &decoded, 0, 0)
+		for _, want := range []string{"second", "third"} {
+			if _, p, err := c.ReadMessage(); err != nil || string(p) != want {
+				t.Fatalf("ReadMessage() = %q, %v, want %q", p, err, want)
+			}
+		}
+		if err := c.WriteMessage(TextMessage, []byte("reply")); err != nil {
+			t.Fatal(err)
+		}
+		if _, p, err := client.ReadMessage(); err != nil || string(p) != "reply" {
+			t.Errorf("client ReadMessage() = %q, %v", p, err)
+		}
+		if c.Subprotocol() != "chat" {
+			t.Errorf("Subprotocol() = %q, want chat", c.Subprotocol())
+		}
+		client.Close()
+		c.Close()
+	}
+}
+
+func TestHandoffBusy(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	w, err := server.NextWriter(TextMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := server.Handoff(); err != errHandoffBusy {
+		t.Errorf("Handoff() with open writer error = %v, want %v", err, errHandoffBusy)
+	}
+	w.Close()
+
+	if err := client.WriteMessage(TextMessage, []byte("message")); err != nil {
+		t.Fatal(err)
+	}
+	_, r, err := server.NextReader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Read(make([]byte, 3))
+	if _, _, err := server.Handoff(); err != errHandoffBusy {
+		t.Errorf("Handoff() during message error = %v, want %v", err, errHandoffBusy)
+	}
+}
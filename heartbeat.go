@@ -0,0 +1,214 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var errHeartbeatTimeout = errors.New("websocket: heartbeat timeout")
+
+const (
+	defaultHeartbeatMinInterval = 15 * time.Second
+	defaultHeartbeatMaxInterval = 2 * time.Minute
+	defaultHeartbeatMinTimeout  = 5 * time.Second
+
+	// heartbeatRTTFactor is the pong timeout in units of the smoothed RTT.
+	heartbeatRTTFactor = 4
+
+	// heartbeatTag prefixes the payload of the heartbeat's pings, followed
+	// by the time the ping was sent in nanoseconds.
+	heartbeatTag = "\x00wshb"
+)
+
+// Heartbeat pings idle connections to detect dead peers and adapts the ping
+// frequency to the traffic on the connection and the measured round trip
+// time:
+//
+//   - Data frames and pongs from the peer show that it is alive, so no pings
+//     are sent while traffic flows.
+//   - When the connection becomes idle, the first ping is sent after
+//     MinInterval. The interval doubles after each pong up to MaxInterval,
+//     which saves battery and bandwidth on long idle mobile connections.
+//   - The pong timeout is four times the smoothed RTT, and at least
+//     MinTimeout.
+//
+// A dead peer is detected at most MaxInterval plus the pong timeout after
+// its last activity. The connection is then closed and OnDead is called.
+//
+// The heartbeat relies on the application reading from the connection to
+// receive pongs, for example with a read pump. See Conn.StartReadPump.
+// A Heartbeat can be shared by connections and must not be modified after
+// the first call to Start.
+type Heartbeat struct {
+	// MinInterval is the idle time before the first ping. If MinInterval is
+	// zero, 15 seconds is used.
+	MinInterval time.Duration
+
+	// MaxInterval is the maximum idle time between pings. If MaxInterval is
+	// zero, 2 minutes is used.
+	MaxInterval time.Duration
+
+	// MinTimeout is the minimum time to wait for a pong. If MinTimeout is
+	// zero, 5 seconds is used.
+	MinTimeout time.Duration
+
+	// OnDead is called after the heartbeat closes a connection because the
+	// peer did not answer a ping or the ping could not be written.
+	OnDead func(c *Conn, err error)
+}
+
+func (h *Heartbeat) minInterval() time.Duration {
+	if h.MinInterval > 0 {
+		return h.MinInterval
+	}
+	return defaultHeartbeatMinInterval
+}
+
+func (h *Heartbeat) maxInterval() time.Duration {
+	if h.MaxInterval > 0 {
+		return h.MaxInterval
+	}
+	return defaultHeartbeatMaxInterval
+}
+
+func (h *Heartbeat) timeout(c *Conn) time.Duration {
+	timeout := h.MinTimeout
+	if timeout <= 0 {
+		timeout = defaultHeartbeatMinTimeout
+	}
+	if t := heartbeatRTTFactor * c.RTT(); t > timeout {
+		timeout = t
+	}
+	return timeout
+}
+
+// Start starts the heartbeat on c and returns a function that stops it.
+// Start wraps the pong handler of c, so call Start before reading from c and
+// after any call to SetPongHandler. Pongs that are not answers to the
+// heartbeat's pings are passed to the wrapped handler.
+func (h *Heartbeat) Start(c *Conn) (stop func()) {
+	pongs := make(chan struct{}, 1)
+	var outstanding atomic.Int64 // send time of the unanswered ping
+	handler := c.PongHandler()
+	c.SetPongHandler(func(appData string) error {
+		if len(appData) != len(heartbeatTag)+8 || !strings.HasPrefix(appData, heartbeatTag) {
+			return handler(appData)
+		}
+		ns := int64(binary.BigEndian.Uint64([]byte(appData[len(heartbeatTag):])))
+		if ns == 0 || !outstanding.CompareAndSwap(ns, 0) {
+			// The answer to a ping that timed out.
+			return nil
+		}
+		c.updateRTT(c.clock.Now().Sub(time.Unix(0, ns)))
+		select {
+		case pongs <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	done := make(chan struct{})
+	go h.run(c, pongs, &outstanding, done)
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+func (h *Heartbeat) run(c *Conn, pongs chan struct{}, outstanding *atomic.Int64, done chan struct{}) {
+	interval := h.minInterval()
+	alive := c.clock.Now() // the peer was last known to be alive
+	for {
+		// Wait until the connection is idle for interval.
+		for {
+			if last := c.Activity().last(); last.After(alive) {
+				// Traffic flows. Tighten the interval for the next idle
+				// period.
+				alive = last
+				interval = h.minInterval()
+			}
+			wait := alive.Add(interval).Sub(c.clock.Now())
+			if wait <= 0 {
+				break
+			}
+			if !sleepClock(c.clock, wait, done) {
+				return
+			}
+		}
+
+		sent := c.clock.Now()
+		payload := binary.BigEndian.AppendUint64([]byte(heartbeatTag), uint64(sent.UnixNano()))
+		outstanding.Store(sent.UnixNano())
+		timeout := h.timeout(c)
+		if err := c.WriteControl(PingMessage, payload, sent.Add(timeout)); err != nil {
+			h.dead(c, err)
+			return
+		}
+
+		timer := c.clock.NewTimer(timeout)
+		select {
+		case <-done:
+			timer.Stop()
+			return
+		case <-pongs:
+			timer.Stop()
+			alive = c.clock.Now()
+			if interval *= 2; interval > h.maxInterval() {
+				interval = h.maxInterval()
+			}
+		case <-timer.C():
+			if last := c.Activity().last(); last.After(sent) {
+				// The pong is late, but data shows that the peer is alive.
+				alive = last
+				continue
+			}
+			h.dead(c, errHeartbeatTimeout)
+			return
+		}
+	}
+}
+
+func (h *Heartbeat) dead(c *Conn, err error) {
+	c.Close()
+	if h.OnDead != nil {
+		h.OnDead(c, err)
+	}
+}
+
+// sleepClock waits for d on clock. It returns false if done is closed
+// first.
+func sleepClock(clock Clock, d time.Duration, done chan struct{}) bool {
+	timer := clock.NewTimer(d)
+	select {
+	case <-done:
+		timer.Stop()
+		return false
+	case <-timer.C():
+		return true
+	}
+}
+
+// RTT returns the smoothed round trip time measured by a Heartbeat, or zero
+// if no round trip was measured.
+func (c *Conn) RTT() time.Duration {
+	return time.Duration(c.rtt.Load())
+}
+
+// updateRTT adds a sample to the smoothed RTT with the weights of RFC 6298.
+func (c *Conn) updateRTT(sample time.Duration) {
+	if sample < 0 {
+		return
+	}
+	rtt := time.Duration(c.rtt.Load())
+	if rtt == 0 {
+		rtt = sample
+	} else {
+		rtt += (sample - rtt) / 8
+	}
+	c.rtt.Store(int64(rtt))
+}
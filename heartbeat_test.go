@@ -0,0 +1,136 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// readAll reads from c until an error and counts the pings received.
+func readAll(c *Conn, pings *int32) {
+	c.SetPingHandler(func(appData string) error {
+		atomic.AddInt32(pings, 1)
+		return c.WriteControl(PongMessage, []byte(appData), time.Time{})
+	})
+	go func() {
+		for {
+			if _, _, err := c.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+func TestHeartbeatBackoff(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	var pings, serverPings int32
+	readAll(client, &pings)
+	h := &Heartbeat{MinInterval: 10 * time.Millisecond, MaxInterval: 80 * time.Millisecond, MinTimeout: time.Second}
+	stop := h.Start(server)
+	readAll(server, &serverPings)
+	time.Sleep(400 * time.Millisecond)
+	stop()
+
+	// A fixed interval of 10ms would send about 40 pings. Doubling
+	// intervals send pings at about 10, 30, 70, 150, 230, 310 and 390ms.
+	if n := atomic.LoadInt32(&pings); n < 3 || n > 12 {
+		t.Errorf("%d pings in 400ms, want about 7", n)
+	}
+}
+
+func TestHeartbeatTraffic(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	var pings, serverPings int32
+	readAll(client, &pings)
+	h := &Heartbeat{MinInterval: 50 * time.Millisecond}
+	defer h.Start(server)()
+	readAll(server, &serverPings)
+	for start := time.Now(); time.Since(start) < 200*time.Millisecond; time.Sleep(5 * time.Millisecond) {
+		client.WriteMessage(TextMessage, []byte("data"))
+	}
+	if n := atomic.LoadInt32(&pings); n != 0 {
+		t.Errorf("%d pings while data flowed, want 0", n)
+	}
+}
+
+func TestHeartbeatDead(t *testing.T) {
+	// The client does not read, so it does not answer pings.
+	client, server := Pipe()
+	defer client.Close()
+	dead := make(chan error, 1)
+	h := &Heartbeat{
+		MinInterval: 10 * time.Millisecond,
+		MinTimeout:  20 * time.Millisecond,
+		OnDead:      func(c *Conn, err error) { dead <- err },
+	}
+	defer h.Start(server)()
+	var serverPings int32
+	readAll(server, &serverPings)
+	select {
+	case err := <-dead:
+		if err != errHeartbeatTimeout {
+			t.Errorf("OnDead error = %v, want %v", err, errHeartbeatTimeout)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("dead peer not detected")
+	}
+}
+
+func TestHeartbeatApplicationPongs(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	appPongs := make(chan string, 2)
+	server.SetPongHandler(func(appData string) error {
+		appPongs <- appData
+		return nil
+	})
+	h := &Heartbeat{MinInterval: time.Hour}
+	defer h.Start(server)()
+	var serverPings int32
+	readAll(server, &serverPings)
+
+	// Pongs of the application, including an 8 byte payload and the answer
+	// to a heartbeat ping that is not outstanding, do not count as answers.
+	stale := append([]byte(heartbeatTag), 0, 0, 0, 0, 0, 0, 0, 1)
+	for _, p := range [][]byte{[]byte("12345678"), stale, []byte("app")} {
+		client.WriteControl(PongMessage, p, time.Time{})
+	}
+	for _, want := range []string{"12345678", "app"} {
+		select {
+		case got := <-appPongs:
+			if got != want {
+				t.Errorf("application pong = %q, want %q", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("pong not passed to the application handler")
+		}
+	}
+	if rtt := server.RTT(); rtt != 0 {
+		t.Errorf("RTT() = %v, want 0", rtt)
+	}
+}
+
+func TestUpdateRTT(t *testing.T) {
+	var c Conn
+	c.updateRTT(80 * time.Millisecond)
+	if rtt := c.RTT(); rtt != 80*time.Millisecond {
+		t.Errorf("RTT() after first sample = %v, want 80ms", rtt)
+	}
+	c.updateRTT(160 * time.Millisecond)
+	if rtt := c.RTT(); rtt != 90*time.Millisecond {
+		t.Errorf("RTT() after second sample = %v, want 90ms", rtt)
+	}
+	c.updateRTT(-time.Second)
+	if rtt := c.RTT(); rtt != 90*time.Millisecond {
+		t.Errorf("RTT() after negative sample = %v, want 90ms", rtt)
+	}
+}
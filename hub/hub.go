@@ -0,0 +1,463 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package hub broadcasts messages to rooms of websocket connections.
+//
+// The connections of a Hub are spread over shards. Each shard has its own
+// lock and a worker goroutine, so a broadcast to a large room is fanned out
+// by all shards in parallel. A broadcast message is prepared once with
+// websocket.NewPreparedMessage and queued to each member, and a goroutine
+// per connection writes the queued messages. A connection whose queue is
+// full is closed as a slow consumer.
+//
+// The application reads from the connections and removes a connection from
+// the hub when a read fails:
+//
+//	h.Add(c)
+//	defer h.Remove(c)
+//	h.Join(c, "lobby")
+//	for {
+//		_, p, err := c.ReadMessage()
+//		if err != nil {
+//			return
+//		}
+//		h.Broadcast("lobby", p)
+//	}
+//
+// The hub tracks the presence of the connections in rooms. The OnJoin and
+// OnLeave hooks report the changes, Members lists the members of a room and
+// PresenceInterval enables periodic presence messages to the members.
+// Select finds connections by their labels.
+//
+// A Bridge propagates broadcasts to the hubs of other server instances. The
+// subpackages redisbridge and natsbridge implement Bridge with Redis
+// Pub/Sub and NATS.
+package hub
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const defaultQueueSize = 256
+
+var (
+	// ErrClosed is returned by the methods of a closed Hub.
+	ErrClosed = errors.New("hub: hub closed")
+
+	// ErrNotMember is returned when a connection was not added to the hub.
+	ErrNotMember = errors.New("hub: connection not in hub")
+)
+
+// Hub is a set of websocket connections grouped in rooms. The zero value is
+// a hub with the default configuration. The configuration must not be
+// changed after the first call to a method. The methods of Hub are safe for
+// concurrent use.
+type Hub struct {
+	// Shards is the number of shards. If Shards is zero,
+	// runtime.GOMAXPROCS(0) is used.
+	Shards int
+
+	// QueueSize is the number of broadcast messages queued for each
+	// connection. If QueueSize is zero, 256 is used.
+	QueueSize int
+
+	// MessageType is the type of broadcast messages. If MessageType is zero,
+	// websocket.TextMessage is used.
+	MessageType int
+
+	// Bridge propagates broadcasts to and from the hubs of other server
+	// instances. If Bridge is nil, broadcasts are local. The hub subscribes
+	// to the bridge on the first call to a method and closes the bridge in
+	// Close.
+	Bridge Bridge
+
+	// OnJoin and OnLeave are called when a connection joins and leaves a
+	// room, including when the connection is removed from the hub. The
+	// hooks can persist presence externally. The hooks are called without
+	// the hub's locks held.
+	OnJoin, OnLeave func(m Member)
+
+	// PresenceInterval is the interval of the presence messages broadcast
+	// to the members of each room. If PresenceInterval is zero, presence
+	// messages are not sent.
+	PresenceInterval time.Duration
+
+	// PresenceMessage returns the presence message of a room. If
+	// PresenceMessage is nil, the message is the JSON encoding of
+	// PresenceSync.
+	PresenceMessage func(room string, members []Member) []byte
+
+	initOnce sync.Once
+	initErr  error
+	shards   []*shard
+	next     atomic.Uint32
+	members  sync.Map // *websocket.Conn to *member
+
+	mu     sync.RWMutex // held for writing by Close
+	closed bool
+	done   chan struct{} // closed by Close
+}
+
+// Bridge propagates broadcasts between the hubs of server instances.
+type Bridge interface {
+	// Publish sends a broadcast to the other instances.
+	Publish(room string, msg []byte) error
+
+	// Subscribe starts calling handler with the broadcasts published by the
+	// other instances, not including the broadcasts published by this
+	// bridge. Subscribe is called once.
+	Subscribe(handler func(room string, msg []byte)) error
+
+	// Close stops the subscription and releases the resources of the
+	// bridge.
+	Close() error
+}
+
+type shard struct {
+	mu    sync.RWMutex
+	rooms map[string]map[*member]Member
+	jobs  chan job
+}
+
+type job struct {
+	room   string
+	pm     *websocket.PreparedMessage
+	filter func(*websocket.Conn) bool // nil for all members
+	wg     *sync.WaitGroup
+}
+
+type member struct {
+	conn    *websocket.Conn
+	shard   *shard
+	send    chan *websocket.PreparedMessage
+	rooms   map[string]struct{} // guarded by shard.mu
+	removed bool                // guarded by shard.mu
+	slow    atomic.Bool
+}
+
+func (h *Hub) init() error {
+	h.initOnce.Do(func() {
+		n := h.Shards
+		if n <= 0 {
+			n = runtime.GOMAXPROCS(0)
+		}
+		h.shards = make([]*shard, n)
+		for i := range h.shards {
+			s := &shard{
+				rooms: make(map[string]map[*member]Member),
+				jobs:  make(chan job, 16),
+			}
+			h.shards[i] = s
+			go s.run()
+		}
+		h.done = make(chan struct{})
+		if h.PresenceInterval > 0 {
+			go h.presenceLoop()
+		}
+		if h.Bridge != nil {
+			h.initErr = h.Bridge.Subscribe(h.receive)
+		}
+	})
+	return h.initErr
+}
+
+// Add adds c to the hub and starts the goroutine that writes broadcast
+// messages to c. If a write fails, the goroutine closes c. The application
+// must not write to c with other methods until c is removed from the hub.
+func (h *Hub) Add(c *websocket.Conn) error {
+	if err := h.init(); err != nil {
+		return err
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.closed {
+		return ErrClosed
+	}
+	size := h.QueueSize
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+	m := &member{
+		conn:  c,
+		shard: h.shards[int(h.next.Add(1))%len(h.shards)],
+		send:  make(chan *websocket.PreparedMessage, size),
+		rooms: make(map[string]struct{}),
+	}
+	if _, loaded := h.members.LoadOrStore(c, m); loaded {
+		return nil
+	}
+	go m.writeLoop()
+	return nil
+}
+
+// Remove removes c from the hub and from its rooms. The messages queued for
+// c are written before the write goroutine exits. Remove does not close c.
+func (h *Hub) Remove(c *websocket.Conn) {
+	v, ok := h.members.LoadAndDelete(c)
+	if !ok {
+		return
+	}
+	h.left(v.(*member).remove())
+}
+
+// Select returns the connections in the hub that have all of the labels.
+// See websocket.Conn.SetLabel. Select with no labels returns all
+// connections. Use Select to act on the connections of a tenant, plan or
+// region, for example to close them.
+func (h *Hub) Select(labels map[string]string) []*websocket.Conn {
+	var conns []*websocket.Conn
+	h.members.Range(func(k, v interface{}) bool {
+		c := k.(*websocket.Conn)
+		for key, value := range labels {
+			if c.Label(key) != value {
+				return true
+			}
+		}
+		conns = append(conns, c)
+		return true
+	})
+	return conns
+}
+
+func (h *Hub) member(c *websocket.Conn) (*member, error) {
+	v, ok := h.members.Load(c)
+	if !ok {
+		return nil, ErrNotMember
+	}
+	return v.(*member), nil
+}
+
+// Join adds c to the room with no metadata.
+func (h *Hub) Join(c *websocket.Conn, room string) error {
+	return h.JoinWithMeta(c, room, nil)
+}
+
+// JoinWithMeta adds c to the room with the metadata, such as the user's
+// name and status. If c is already in the room, JoinWithMeta replaces the
+// metadata and does not call OnJoin.
+func (h *Hub) JoinWithMeta(c *websocket.Conn, room string, meta map[string]string) error {
+	m, err := h.member(c)
+	if err != nil {
+		return err
+	}
+	s := m.shard
+	s.mu.Lock()
+	if m.removed {
+		s.mu.Unlock()
+		return ErrNotMember
+	}
+	members := s.rooms[room]
+	if members == nil {
+		members = make(map[*member]Member)
+		s.rooms[room] = members
+	}
+	p, joined := members[m]
+	if !joined {
+		p = Member{Conn: c, Room: room, Joined: time.Now()}
+	}
+	p.Meta = meta
+	members[m] = p
+	m.rooms[room] = struct{}{}
+	s.mu.Unlock()
+
+	if !joined && h.OnJoin != nil {
+		h.OnJoin(p)
+	}
+	return nil
+}
+
+// Leave removes c from the room.
+func (h *Hub) Leave(c *websocket.Conn, room string) error {
+	m, err := h.member(c)
+	if err != nil {
+		return err
+	}
+	s := m.shard
+	s.mu.Lock()
+	p, ok := s.leave(m, room)
+	s.mu.Unlock()
+	if ok {
+		h.left([]Member{p})
+	}
+	return nil
+}
+
+// left calls OnLeave for the members.
+func (h *Hub) left(members []Member) {
+	if h.OnLeave == nil {
+		return
+	}
+	for _, p := range members {
+		h.OnLeave(p)
+	}
+}
+
+// Broadcast queues msg to the members of the room and publishes msg to the
+// bridge. Broadcast returns when all shards have queued the message, so the
+// messages of sequential calls are written to each connection in order.
+func (h *Hub) Broadcast(room string, msg []byte) error {
+	if err := h.init(); err != nil {
+		return err
+	}
+	if err := h.broadcast(room, msg, nil); err != nil {
+		return err
+	}
+	if h.Bridge != nil {
+		return h.Bridge.Publish(room, msg)
+	}
+	return nil
+}
+
+// BroadcastFunc queues msg to the members of the room for which filter
+// returns true. The filter is called from the shard workers with the
+// shard's lock held and must not call the methods of the hub. The filter
+// cannot be applied by other instances, so BroadcastFunc does not publish
+// msg to the bridge.
+func (h *Hub) BroadcastFunc(room string, msg []byte, filter func(*websocket.Conn) bool) error {
+	if err := h.init(); err != nil {
+		return err
+	}
+	return h.broadcast(room, msg, filter)
+}
+
+// ExcludeSender returns a BroadcastFunc filter that excludes the sender.
+func ExcludeSender(sender *websocket.Conn) func(*websocket.Conn) bool {
+	return func(c *websocket.Conn) bool { return c != sender }
+}
+
+// receive broadcasts a message from the bridge.
+func (h *Hub) receive(room string, msg []byte) {
+	_ = h.broadcast(room, msg, nil)
+}
+
+// broadcast queues msg to the local members of the room that pass the
+// filter.
+func (h *Hub) broadcast(room string, msg []byte, filter func(*websocket.Conn) bool) error {
+	messageType := h.MessageType
+	if messageType == 0 {
+		messageType = websocket.TextMessage
+	}
+	pm, err := websocket.NewPreparedMessage(messageType, msg)
+	if err != nil {
+		return err
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.closed {
+		return ErrClosed
+	}
+	var wg sync.WaitGroup
+	wg.Add(len(h.shards))
+	for _, s := range h.shards {
+		s.jobs <- job{room: room, pm: pm, filter: filter, wg: &wg}
+	}
+	wg.Wait()
+	return nil
+}
+
+// Close removes all connections from the hub, stops the shard workers and
+// closes the bridge. Close does not close the connections.
+func (h *Hub) Close() error {
+	h.init()
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return ErrClosed
+	}
+	h.closed = true
+	close(h.done)
+	for _, s := range h.shards {
+		close(s.jobs)
+	}
+	var left []Member
+	h.members.Range(func(k, v interface{}) bool {
+		h.members.Delete(k)
+		left = append(left, v.(*member).remove()...)
+		return true
+	})
+	h.mu.Unlock()
+
+	h.left(left)
+	if h.Bridge != nil {
+		return h.Bridge.Close()
+	}
+	return nil
+}
+
+func (s *shard) run() {
+	for j := range s.jobs {
+		s.mu.RLock()
+		for m := range s.rooms[j.room] {
+			if j.filter != nil && !j.filter(m.conn) {
+				continue
+			}
+			select {
+			case m.send <- j.pm:
+			default:
+				// Close the slow consumer. The application's read fails
+				// and the application removes the connection.
+				if m.slow.CompareAndSwap(false, true) {
+					m.conn.Close()
+				}
+			}
+		}
+		s.mu.RUnlock()
+		j.wg.Done()
+	}
+}
+
+// leave removes m from the room and returns the presence of m in the room.
+// The shard's lock is held.
+func (s *shard) leave(m *member, room string) (Member, bool) {
+	members := s.rooms[room]
+	p, ok := members[m]
+	if !ok {
+		return Member{}, false
+	}
+	delete(m.rooms, room)
+	delete(members, m)
+	if len(members) == 0 {
+		delete(s.rooms, room)
+	}
+	return p, true
+}
+
+// remove removes m from its rooms and stops the write goroutine. remove
+// returns the presence of m in the rooms.
+func (m *member) remove() []Member {
+	s := m.shard
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m.removed {
+		return nil
+	}
+	m.removed = true
+	var left []Member
+	for room := range m.rooms {
+		if p, ok := s.leave(m, room); ok {
+			left = append(left, p)
+		}
+	}
+	close(m.send)
+	return left
+}
+
+func (m *member) writeLoop() {
+	var err error
+	for pm := range m.send {
+		if err != nil {
+			continue
+		}
+		if err = m.conn.WritePreparedMessage(pm); err != nil {
+			m.conn.Close()
+		}
+	}
+}
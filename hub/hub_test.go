@@ -0,0 +1,236 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// pair is a hub connection and the peer that reads its messages.
+type pair struct {
+	conn, peer *websocket.Conn
+}
+
+func newPairs(t *testing.T, h *Hub, n int) []pair {
+	t.Helper()
+	pairs := make([]pair, n)
+	for i := range pairs {
+		peer, conn := websocket.Pipe()
+		t.Cleanup(func() { peer.Close(); conn.Close() })
+		if err := h.Add(conn); err != nil {
+			t.Fatal(err)
+		}
+		pairs[i] = pair{conn: conn, peer: peer}
+	}
+	return pairs
+}
+
+func readString(t *testing.T, c *websocket.Conn) string {
+	t.Helper()
+	c.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, p, err := c.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	return string(p)
+}
+
+func TestBroadcast(t *testing.T) {
+	h := &Hub{Shards: 3}
+	defer h.Close()
+	pairs := newPairs(t, h, 10)
+	for i, p := range pairs {
+		room := "even"
+		if i%2 == 1 {
+			room = "odd"
+		}
+		if err := h.Join(p.conn, room); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, msg := range []string{"a", "b"} {
+		if err := h.Broadcast("even", []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h.Broadcast("odd", []byte("c"))
+	for i, p := range pairs {
+		want := []string{"c"}
+		if i%2 == 0 {
+			want = []string{"a", "b"}
+		}
+		for _, w := range want {
+			if got := readString(t, p.peer); got != w {
+				t.Errorf("conn %d read %q, want %q", i, got, w)
+			}
+		}
+	}
+
+	// Connections that left the room or the hub do not receive broadcasts.
+	h.Leave(pairs[0].conn, "even")
+	h.Remove(pairs[2].conn)
+	if err := h.Join(pairs[2].conn, "even"); err != ErrNotMember {
+		t.Errorf("Join() after Remove error = %v, want %v", err, ErrNotMember)
+	}
+	h.Broadcast("even", []byte("d"))
+	h.Broadcast("odd", []byte("e"))
+	for _, i := range []int{4, 6, 8} {
+		if got := readString(t, pairs[i].peer); got != "d" {
+			t.Errorf("conn %d read %q, want d", i, got)
+		}
+	}
+	for _, i := range []int{0, 2} {
+		pairs[i].peer.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+		if _, p, err := pairs[i].peer.ReadMessage(); err == nil {
+			t.Errorf("conn %d read %q after leaving", i, p)
+		}
+	}
+}
+
+func TestClose(t *testing.T) {
+	h := &Hub{}
+	pairs := newPairs(t, h, 2)
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Broadcast("room", nil); err != ErrClosed {
+		t.Errorf("Broadcast() after Close error = %v, want %v", err, ErrClosed)
+	}
+	if err := h.Add(pairs[0].conn); err != ErrClosed {
+		t.Errorf("Add() after Close error = %v, want %v", err, ErrClosed)
+	}
+	if err := h.Join(pairs[1].conn, "room"); err != ErrNotMember {
+		t.Errorf("Join() after Close error = %v, want %v", err, ErrNotMember)
+	}
+}
+
+func BenchmarkBroadcast(b *testing.B) {
+	h := &Hub{QueueSize: b.N + 1}
+	defer h.Close()
+	for i := 0; i < 1000; i++ {
+		peer, conn := websocket.Pipe()
+		defer peer.Close()
+		h.Add(conn)
+		h.Join(conn, "room")
+	}
+	msg := []byte("hello")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Broadcast("room", msg)
+	}
+}
+
+// memBridge connects the hubs of a test in memory.
+type memBridge struct {
+	peers   *[]*memBridge
+	handler func(room string, msg []byte)
+}
+
+func (b *memBridge) Publish(room string, msg []byte) error {
+	for _, p := range *b.peers {
+		if p != b && p.handler != nil {
+			p.handler(room, msg)
+		}
+	}
+	return nil
+}
+
+func (b *memBridge) Subscribe(handler func(room string, msg []byte)) error {
+	b.handler = handler
+	return nil
+}
+
+func (b *memBridge) Close() error { return nil }
+
+func TestBridge(t *testing.T) {
+	var peers []*memBridge
+	for i := 0; i < 2; i++ {
+		peers = append(peers, &memBridge{peers: &peers})
+	}
+	h1 := &Hub{Bridge: peers[0]}
+	defer h1.Close()
+	h2 := &Hub{Bridge: peers[1]}
+	defer h2.Close()
+	p1 := newPairs(t, h1, 1)[0]
+	p2 := newPairs(t, h2, 1)[0]
+	h1.Join(p1.conn, "room")
+	h2.Join(p2.conn, "room")
+
+	if err := h1.Broadcast("room", []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range []pair{p1, p2} {
+		if got := readString(t, p.peer); got != "hello" {
+			t.Errorf("hub %d conn read %q, want hello", i+1, got)
+		}
+	}
+}
+
+func TestBroadcastFunc(t *testing.T) {
+	h := &Hub{Shards: 2}
+	defer h.Close()
+	pairs := newPairs(t, h, 4)
+	admins := map[*websocket.Conn]bool{pairs[0].conn: true, pairs[1].conn: true, pairs[2].conn: true}
+	for _, p := range pairs {
+		h.Join(p.conn, "room")
+	}
+
+	// Send to the admins except the author.
+	sender := ExcludeSender(pairs[0].conn)
+	err := h.BroadcastFunc("room", []byte("a"), func(c *websocket.Conn) bool {
+		return sender(c) && admins[c]
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Broadcast("room", []byte("b"))
+	for i, p := range pairs {
+		want := []string{"a", "b"}
+		if i == 0 || i == 3 {
+			want = []string{"b"}
+		}
+		for _, w := range want {
+			if got := readString(t, p.peer); got != w {
+				t.Errorf("conn %d read %q, want %q", i, got, w)
+			}
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	h := &Hub{}
+	defer h.Close()
+	pairs := newPairs(t, h, 4)
+	for i, p := range pairs {
+		p.conn.SetLabel("tenant", []string{"acme", "globex"}[i%2])
+		p.conn.SetLabel("plan", []string{"free", "pro"}[i/2])
+	}
+	tests := []struct {
+		labels map[string]string
+		want   int
+	}{
+		{nil, 4},
+		{map[string]string{"tenant": "acme"}, 2},
+		{map[string]string{"tenant": "acme", "plan": "pro"}, 1},
+		{map[string]string{"tenant": "initech"}, 0},
+	}
+	for _, tt := range tests {
+		conns := h.Select(tt.labels)
+		if len(conns) != tt.want {
+			t.Errorf("Select(%v) returned %d connections, want %d", tt.labels, len(conns), tt.want)
+		}
+		for _, c := range conns {
+			for k, v := range tt.labels {
+				if c.Label(k) != v {
+					t.Errorf("Select(%v) returned connection with %s=%q", tt.labels, k, c.Label(k))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,380 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package natsbridge implements a hub.Bridge with NATS.
+//
+// All instances publish to one NATS subject. The payload of a message is
+// the decimal length of the room, a space, the room and the broadcast
+// message:
+//
+//	5 lobbyhello
+//
+// The bridge connects with echo disabled, so the server does not deliver
+// the bridge's own messages back to it.
+package natsbridge
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultAddr          = "localhost:4222"
+	defaultSubject       = "websocket-hub"
+	defaultTimeout       = 5 * time.Second
+	defaultRetryInterval = time.Second
+	defaultMaxMessage    = 1024 * 1024
+)
+
+var (
+	// ErrClosed is returned by the methods of a closed Bridge.
+	ErrClosed = errors.New("natsbridge: bridge closed")
+
+	errNotConnected = errors.New("natsbridge: not connected")
+	errBadMessage   = errors.New("natsbridge: malformed message")
+	errTooLarge     = errors.New("natsbridge: message too large")
+)
+
+// Bridge is a hub.Bridge that uses NATS. The zero value connects to a NATS
+// server on localhost. The configuration must not be changed after the
+// first call to a method.
+type Bridge struct {
+	// Addr is the host:port address of the NATS server. If Addr is empty,
+	// "localhost:4222" is used.
+	Addr string
+
+	// User and Password are sent in the CONNECT message if not empty.
+	User, Password string
+
+	// Subject is the NATS subject of the broadcasts. If Subject is empty,
+	// "websocket-hub" is used.
+	Subject string
+
+	// DialContext specifies the dial function for the connection to the
+	// server. If DialContext is nil, net.Dialer is used.
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// Timeout is the time limit for connecting to the server and for a
+	// publish. If Timeout is zero, 5 seconds is used.
+	Timeout time.Duration
+
+	// RetryInterval is the time to wait before reconnecting after the
+	// connection fails. If RetryInterval is zero, 1 second is used.
+	RetryInterval time.Duration
+
+	// MaxMessageSize is the largest payload of a NATS message, the encoded
+	// room and broadcast message, that the bridge publishes and receives.
+	// Larger messages received from the server are discarded. If
+	// MaxMessageSize is zero, 1 MiB is used.
+	MaxMessageSize int
+
+	initOnce sync.Once
+
+	mu     sync.Mutex // guards the fields below and writes to nc
+	nc     net.Conn   // nil while disconnected
+	closed bool
+	done   chan struct{}
+}
+
+func (b *Bridge) init() {
+	b.initOnce.Do(func() {
+		b.done = make(chan struct{})
+	})
+}
+
+func (b *Bridge) subject() string {
+	if b.Subject == "" {
+		return defaultSubject
+	}
+	return b.Subject
+}
+
+func (b *Bridge) timeout() time.Duration {
+	if b.Timeout <= 0 {
+		return defaultTimeout
+	}
+	return b.Timeout
+}
+
+func (b *Bridge) maxMessageSize() int {
+	if b.MaxMessageSize <= 0 {
+		return defaultMaxMessage
+	}
+	return b.MaxMessageSize
+}
+
+type connectOptions struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Protocol int    `json:"protocol"`
+	Echo     bool   `json:"echo"`
+	Name     string `json:"name"`
+	User     string `json:"user,omitempty"`
+	Password string `json:"pass,omitempty"`
+}
+
+// connect dials the server, subscribes to the subject and makes the
+// connection the current connection.
+func (b *Bridge) connect() (*bufio.Reader, error) {
+	addr := b.Addr
+	if addr == "" {
+		addr = defaultAddr
+	}
+	dial := b.DialContext
+	if dial == nil {
+		var d net.Dialer
+		dial = d.DialContext
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout())
+	defer cancel()
+	nc, err := dial(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	br, err := b.handshake(nc)
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		nc.Close()
+		return nil, ErrClosed
+	}
+	b.nc = nc
+	return br, nil
+}
+
+// handshake reads the server's INFO, sends CONNECT and SUB and waits for
+// the PONG to a PING, which confirms that the server accepted both.
+func (b *Bridge) handshake(nc net.Conn) (*bufio.Reader, error) {
+	nc.SetDeadline(time.Now().Add(b.timeout()))
+	br := bufio.NewReader(nc)
+	line, err := readLine(br)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return nil, errBadMessage
+	}
+	opts, err := json.Marshal(connectOptions{
+		Protocol: 1,
+		Name:     "websocket-hub",
+		User:     b.User,
+		Password: b.Password,
+	})
+	if err != nil {
+		return nil, err
+	}
+	cmd := fmt.Sprintf("CONNECT %s\r\nSUB %s 1\r\nPING\r\n", opts, b.subject())
+	if _, err := io.WriteString(nc, cmd); err != nil {
+		return nil, err
+	}
+	for {
+		line, err := readLine(br)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case line == "PONG":
+			nc.SetDeadline(time.Time{})
+			return br, nil
+		case strings.HasPrefix(line, "-ERR"):
+			return nil, fmt.Errorf("natsbridge: server error: %s", strings.TrimSpace(line[4:]))
+		}
+	}
+}
+
+// Publish publishes a broadcast to the other instances. Publish returns an
+// error while the bridge is disconnected.
+func (b *Bridge) Publish(room string, msg []byte) error {
+	b.init()
+	payload := appendPayload(nil, room, msg)
+	if len(payload) > b.maxMessageSize() {
+		return errTooLarge
+	}
+	var buf []byte
+	buf = append(buf, "PUB "...)
+	buf = append(buf, b.subject()...)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, int64(len(payload)), 10)
+	buf = append(buf, "\r\n"...)
+	buf = append(buf, payload...)
+	buf = append(buf, "\r\n"...)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+	if b.nc == nil {
+		return errNotConnected
+	}
+	b.nc.SetWriteDeadline(time.Now().Add(b.timeout()))
+	if _, err := b.nc.Write(buf); err != nil {
+		// The receive goroutine reconnects.
+		b.nc.Close()
+		return err
+	}
+	return nil
+}
+
+// Subscribe connects to the server and calls handler with the broadcasts of
+// the other instances from a goroutine. Subscribe returns an error if the
+// first connection fails. After a later failure, the bridge reconnects
+// until it is closed. Broadcasts published while the bridge is
+// disconnected are lost.
+func (b *Bridge) Subscribe(handler func(room string, msg []byte)) error {
+	b.init()
+	br, err := b.connect()
+	if err != nil {
+		return err
+	}
+	go b.receiveLoop(br, handler)
+	return nil
+}
+
+func (b *Bridge) receiveLoop(br *bufio.Reader, handler func(room string, msg []byte)) {
+	for br != nil {
+		b.receive(br, handler)
+		b.mu.Lock()
+		if b.nc != nil {
+			b.nc.Close()
+			b.nc = nil
+		}
+		b.mu.Unlock()
+		br = b.reconnect()
+	}
+}
+
+// reconnect connects to the server. It returns nil when the bridge is
+// closed.
+func (b *Bridge) reconnect() *bufio.Reader {
+	retry := b.RetryInterval
+	if retry <= 0 {
+		retry = defaultRetryInterval
+	}
+	for {
+		select {
+		case <-b.done:
+			return nil
+		case <-time.After(retry):
+		}
+		br, err := b.connect()
+		if err == ErrClosed {
+			return nil
+		}
+		if err == nil {
+			return br
+		}
+	}
+}
+
+// receive calls handler with the messages from br until a read fails.
+func (b *Bridge) receive(br *bufio.Reader, handler func(room string, msg []byte)) {
+	for {
+		line, err := readLine(br)
+		if err != nil {
+			return
+		}
+		switch {
+		case strings.HasPrefix(line, "MSG "):
+			// MSG <subject> <sid> [reply-to] <#bytes>
+			fields := strings.Fields(line)
+			if len(fields) < 4 {
+				return
+			}
+			n, err := strconv.Atoi(fields[len(fields)-1])
+			if err != nil || n < 0 {
+				return
+			}
+			if n > b.maxMessageSize() {
+				// Discard the payload without allocating it.
+				if _, err := io.CopyN(io.Discard, br, int64(n)+2); err != nil {
+					return
+				}
+				continue
+			}
+			p := make([]byte, n+2)
+			if _, err := io.ReadFull(br, p); err != nil {
+				return
+			}
+			room, msg, err := parsePayload(p[:n])
+			if err != nil {
+				continue
+			}
+			handler(room, msg)
+		case line == "PING":
+			b.mu.Lock()
+			if b.nc != nil {
+				b.nc.SetWriteDeadline(time.Now().Add(b.timeout()))
+				_, err = io.WriteString(b.nc, "PONG\r\n")
+			}
+			b.mu.Unlock()
+			if err != nil {
+				return
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return
+		}
+	}
+}
+
+// Close closes the connection and stops the subscription.
+func (b *Bridge) Close() error {
+	b.init()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+	b.closed = true
+	close(b.done)
+	if b.nc != nil {
+		b.nc.Close()
+		b.nc = nil
+	}
+	return nil
+}
+
+// readLine reads a protocol line without the CRLF.
+func readLine(br *bufio.Reader) (string, error) {
+	line, err := br.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), nil
+}
+
+func appendPayload(b []byte, room string, msg []byte) []byte {
+	b = strconv.AppendInt(b, int64(len(room)), 10)
+	b = append(b, ' ')
+	b = append(b, room...)
+	return append(b, msg...)
+}
+
+func parsePayload(p []byte) (room string, msg []byte, err error) {
+	i := bytes.IndexByte(p, ' ')
+	if i < 0 {
+		return "", nil, errBadMessage
+	}
+	n, err := strconv.Atoi(string(p[:i]))
+	p = p[i+1:]
+	if err != nil || n < 0 || n > len(p) {
+		return "", nil, errBadMessage
+	}
+	return string(p[:n]), p[n:], nil
+}
@@ -0,0 +1,258 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package natsbridge
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer is a NATS server that implements CONNECT, SUB, PUB and PING
+// for a single subscription per connection.
+type fakeServer struct {
+	ln   net.Listener
+	user string
+
+	mu    sync.Mutex
+	conns map[net.Conn]*fakeConn
+}
+
+type fakeConn struct {
+	echo    bool
+	subject string
+	sid     string
+	pongs   int // PONGs received in reply to the server's PINGs
+}
+
+func newFakeServer(t *testing.T, user string) *fakeServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeServer{ln: ln, user: user, conns: make(map[net.Conn]*fakeConn)}
+	t.Cleanup(func() { ln.Close(); s.dropAll() })
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns[nc] = &fakeConn{echo: true}
+			s.mu.Unlock()
+			go s.serve(nc)
+		}
+	}()
+	return s
+}
+
+func (s *fakeServer) dropAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for nc := range s.conns {
+		nc.Close()
+		delete(s.conns, nc)
+	}
+}
+
+// pingAll sends a PING to all connections.
+func (s *fakeServer) pingAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for nc := range s.conns {
+		io.WriteString(nc, "PING\r\n")
+	}
+}
+
+func (s *fakeServer) pongs() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, fc := range s.conns {
+		n += fc.pongs
+	}
+	return n
+}
+
+func (s *fakeServer) write(nc net.Conn, p string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	io.WriteString(nc, p)
+}
+
+func (s *fakeServer) serve(nc net.Conn) {
+	defer nc.Close()
+	s.write(nc, `INFO {"proto":1}`+"\r\n")
+	br := bufio.NewReader(nc)
+	for {
+		line, err := readLine(br)
+		if err != nil {
+			return
+		}
+		verb, args, _ := strings.Cut(line, " ")
+		s.mu.Lock()
+		fc := s.conns[nc]
+		s.mu.Unlock()
+		if fc == nil {
+			return
+		}
+		switch verb {
+		case "CONNECT":
+			var opts connectOptions
+			if err := json.Unmarshal([]byte(args), &opts); err != nil || opts.User != s.user {
+				s.write(nc, "-ERR 'Authorization Violation'\r\n")
+				return
+			}
+			s.mu.Lock()
+			fc.echo = opts.Echo
+			s.mu.Unlock()
+		case "SUB":
+			f := strings.Fields(args)
+			s.mu.Lock()
+			fc.subject, fc.sid = f[0], f[1]
+			s.mu.Unlock()
+		case "PUB":
+			f := strings.Fields(args)
+			n, _ := strconv.Atoi(f[1])
+			p := make([]byte, n+2)
+			if _, err := io.ReadFull(br, p); err != nil {
+				return
+			}
+			s.mu.Lock()
+			for sub, sfc := range s.conns {
+				if sfc.subject == f[0] && (sub != nc || sfc.echo) {
+					fmt.Fprintf(sub, "MSG %s %s %d\r\n%s", f[0], sfc.sid, n, p)
+				}
+			}
+			s.mu.Unlock()
+		case "PING":
+			s.write(nc, "PONG\r\n")
+		case "PONG":
+			s.mu.Lock()
+			fc.pongs++
+			s.mu.Unlock()
+		}
+	}
+}
+
+type received struct {
+	room, msg string
+}
+
+func subscribe(t *testing.T, b *Bridge) chan received {
+	t.Helper()
+	ch := make(chan received, 10)
+	if err := b.Subscribe(func(room string, msg []byte) {
+		ch <- received{room, string(msg)}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return ch
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	s := newFakeServer(t, "hub")
+	addr := s.ln.Addr().String()
+	a := &Bridge{Addr: addr, User: "hub"}
+	defer a.Close()
+	b := &Bridge{Addr: addr, User: "hub"}
+	defer b.Close()
+	chA, chB := subscribe(t, a), subscribe(t, b)
+
+	if err := a.Publish("lobby", []byte("hello\r\nworld")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-chB:
+		if want := (received{"lobby", "hello\r\nworld"}); got != want {
+			t.Errorf("received %v, want %v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("broadcast not received")
+	}
+	select {
+	case got := <-chA:
+		t.Errorf("publisher received its own broadcast %v", got)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// The bridges answer the server's pings.
+	s.pingAll()
+	for start := time.Now(); s.pongs() != 2; time.Sleep(time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("server received %d pongs, want 2", s.pongs())
+		}
+	}
+
+	bad := &Bridge{Addr: addr, User: "other"}
+	if err := bad.Subscribe(func(string, []byte) {}); err == nil {
+		t.Error("Subscribe() with wrong user returned nil error")
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	s := newFakeServer(t, "")
+	addr := s.ln.Addr().String()
+	a := &Bridge{Addr: addr, RetryInterval: 10 * time.Millisecond}
+	defer a.Close()
+	b := &Bridge{Addr: addr, RetryInterval: 10 * time.Millisecond}
+	defer b.Close()
+	subscribe(t, a)
+	ch := subscribe(t, b)
+
+	s.dropAll()
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		a.Publish("room", []byte("x"))
+		select {
+		case <-ch:
+			return
+		default:
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("bridges did not reconnect")
+		}
+	}
+}
+
+func TestParsePayload(t *testing.T) {
+	p := appendPayload(nil, "a room", []byte("msg"))
+	room, msg, err := parsePayload(p)
+	if room != "a room" || string(msg) != "msg" || err != nil {
+		t.Errorf("parsePayload(%q) = %q, %q, %v", p, room, msg, err)
+	}
+	for _, p := range []string{"", "room", "x room", "9 room"} {
+		if _, _, err := parsePayload([]byte(p)); err != errBadMessage {
+			t.Errorf("parsePayload(%q) error = %v, want %v", p, err, errBadMessage)
+		}
+	}
+}
+
+func TestMaxMessageSize(t *testing.T) {
+	b := &Bridge{MaxMessageSize: 8}
+	defer b.Close()
+	var got []received
+	stream := "MSG hub 1 4611686018427387904\r\n"
+	b.receive(bufio.NewReader(strings.NewReader(stream)), func(room string, msg []byte) {
+		got = append(got, received{room, string(msg)})
+	})
+	stream = "MSG hub 1 11\r\n1 a12345678\r\nMSG hub 1 5\r\n1 ahi\r\n"
+	b.receive(bufio.NewReader(strings.NewReader(stream)), func(room string, msg []byte) {
+		got = append(got, received{room, string(msg)})
+	})
+	if len(got) != 1 || got[0] != (received{"a", "hi"}) {
+		t.Errorf("received %v, want [{a hi}]", got)
+	}
+	if err := b.Publish("a", make([]byte, 8)); err != errTooLarge {
+		t.Errorf("Publish() error = %v, want %v", err, errTooLarge)
+	}
+}
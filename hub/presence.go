@@ -0,0 +1,108 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hub
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Member is the presence of a connection in a room.
+type Member struct {
+	Conn   *websocket.Conn
+	Room   string
+	Meta   map[string]string
+	Joined time.Time
+}
+
+// PresenceSync is the default presence message of a room.
+type PresenceSync struct {
+	Type    string              `json:"type"` // "presence"
+	Room    string              `json:"room"`
+	Members []map[string]string `json:"members"` // the metadata of the members
+}
+
+// Members returns the members of the room on this instance in the order
+// that they joined.
+func (h *Hub) Members(room string) []Member {
+	h.init()
+	var members []Member
+	for _, s := range h.shards {
+		s.mu.RLock()
+		for _, p := range s.rooms[room] {
+			members = append(members, p)
+		}
+		s.mu.RUnlock()
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].Joined.Before(members[j].Joined)
+	})
+	return members
+}
+
+// rooms returns the names of the rooms with members on this instance.
+func (h *Hub) rooms() []string {
+	seen := make(map[string]struct{})
+	var rooms []string
+	for _, s := range h.shards {
+		s.mu.RLock()
+		for room := range s.rooms {
+			if _, ok := seen[room]; !ok {
+				seen[room] = struct{}{}
+				rooms = append(rooms, room)
+			}
+		}
+		s.mu.RUnlock()
+	}
+	return rooms
+}
+
+func (h *Hub) presenceLoop() {
+	ticker := time.NewTicker(h.PresenceInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.done:
+			return
+		case <-ticker.C:
+			h.syncPresence()
+		}
+	}
+}
+
+// syncPresence broadcasts the presence message of each room to the local
+// members of the room.
+func (h *Hub) syncPresence() {
+	for _, room := range h.rooms() {
+		members := h.Members(room)
+		if len(members) == 0 {
+			continue
+		}
+		var msg []byte
+		if h.PresenceMessage != nil {
+			msg = h.PresenceMessage(room, members)
+		} else {
+			msg = presenceJSON(room, members)
+		}
+		if err := h.broadcast(room, msg, nil); err != nil {
+			return
+		}
+	}
+}
+
+func presenceJSON(room string, members []Member) []byte {
+	ps := PresenceSync{Type: "presence", Room: room, Members: make([]map[string]string, len(members))}
+	for i, p := range members {
+		ps.Members[i] = p.Meta
+		if p.Meta == nil {
+			ps.Members[i] = map[string]string{}
+		}
+	}
+	p, _ := json.Marshal(ps)
+	return p
+}
@@ -0,0 +1,110 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hub
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+// events records the presence hooks of a hub.
+type events struct {
+	mu  sync.Mutex
+	log []string
+}
+
+func (e *events) hooks(h *Hub) {
+	h.OnJoin = func(m Member) { e.add("join " + m.Room + " " + m.Meta["name"]) }
+	h.OnLeave = func(m Member) { e.add("leave " + m.Room + " " + m.Meta["name"]) }
+}
+
+func (e *events) add(s string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.log = append(e.log, s)
+}
+
+func (e *events) take() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	log := e.log
+	e.log = nil
+	return log
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func names(members []Member) []string {
+	var s []string
+	for _, m := range members {
+		s = append(s, m.Meta["name"])
+	}
+	return s
+}
+
+func TestPresence(t *testing.T) {
+	h := &Hub{Shards: 2}
+	var e events
+	e.hooks(h)
+	pairs := newPairs(t, h, 3)
+	for i, name := range []string{"ann", "bob", "cat"} {
+		if err := h.JoinWithMeta(pairs[i].conn, "lobby", map[string]string{"name": name}); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond) // order the join times
+	}
+	h.JoinWithMeta(pairs[0].conn, "lobby", map[string]string{"name": "ann", "status": "away"})
+	if got, want := e.take(), []string{"join lobby ann", "join lobby bob", "join lobby cat"}; !equal(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+	members := h.Members("lobby")
+	if got, want := names(members), []string{"ann", "bob", "cat"}; !equal(got, want) {
+		t.Errorf("Members() = %q, want %q", got, want)
+	}
+	if members[0].Meta["status"] != "away" {
+		t.Errorf("Members()[0].Meta = %v, want updated metadata", members[0].Meta)
+	}
+
+	h.Leave(pairs[1].conn, "lobby")
+	h.Leave(pairs[1].conn, "lobby")
+	h.Remove(pairs[2].conn)
+	if got, want := e.take(), []string{"leave lobby bob", "leave lobby cat"}; !equal(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+	h.Close()
+	if got, want := e.take(), []string{"leave lobby ann"}; !equal(got, want) {
+		t.Errorf("events after Close = %q, want %q", got, want)
+	}
+	if n := len(h.Members("lobby")); n != 0 {
+		t.Errorf("Members() after Close has %d members", n)
+	}
+}
+
+func TestPresenceSync(t *testing.T) {
+	h := &Hub{PresenceInterval: 10 * time.Millisecond}
+	defer h.Close()
+	p := newPairs(t, h, 1)[0]
+	h.JoinWithMeta(p.conn, "lobby", map[string]string{"name": "ann"})
+
+	var ps PresenceSync
+	if err := json.Unmarshal([]byte(readString(t, p.peer)), &ps); err != nil {
+		t.Fatal(err)
+	}
+	if ps.Type != "presence" || ps.Room != "lobby" || len(ps.Members) != 1 || ps.Members[0]["name"] != "ann" {
+		t.Errorf("presence message = %+v", ps)
+	}
+}
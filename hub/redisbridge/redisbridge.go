@@ -0,0 +1,410 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package redisbridge implements a hub.Bridge with Redis Pub/Sub.
+//
+// All instances publish to one Redis channel. A message on the channel is
+// the publishing bridge's random origin ID, a space, the decimal length of
+// the room, a space, the room and the broadcast message:
+//
+//	3f2a9c0d1e4b5a6f 5 lobbyhello
+//
+// A bridge ignores the messages with its own origin ID.
+package redisbridge
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultAddr          = "localhost:6379"
+	defaultChannel       = "websocket-hub"
+	defaultTimeout       = 5 * time.Second
+	defaultRetryInterval = time.Second
+	defaultMaxMessage    = 1024 * 1024
+
+	// maxArrayLen is the largest array reply. The replies read by the
+	// bridge have at most three elements.
+	maxArrayLen = 16
+)
+
+var (
+	// ErrClosed is returned by the methods of a closed Bridge.
+	ErrClosed = errors.New("redisbridge: bridge closed")
+
+	errBadReply = errors.New("redisbridge: malformed reply")
+	errTooLarge = errors.New("redisbridge: message too large")
+)
+
+// Bridge is a hub.Bridge that uses Redis Pub/Sub. The zero value connects
+// to a Redis server on localhost. The configuration must not be changed
+// after the first call to a method.
+type Bridge struct {
+	// Addr is the host:port address of the Redis server. If Addr is empty,
+	// "localhost:6379" is used.
+	Addr string
+
+	// Password is sent with the AUTH command if not empty.
+	Password string
+
+	// Channel is the Redis channel of the broadcasts. If Channel is empty,
+	// "websocket-hub" is used.
+	Channel string
+
+	// DialContext specifies the dial function for the connections to the
+	// server. If DialContext is nil, net.Dialer is used.
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// Timeout is the time limit for connecting to the server and for a
+	// publish. If Timeout is zero, 5 seconds is used.
+	Timeout time.Duration
+
+	// RetryInterval is the time to wait before resubscribing after the
+	// subscription connection fails. If RetryInterval is zero, 1 second is
+	// used.
+	RetryInterval time.Duration
+
+	// MaxMessageSize is the largest message on the Redis channel, the
+	// encoded origin, room and broadcast message, that the bridge publishes
+	// and receives. A larger message received from the server fails the
+	// subscription connection. If MaxMessageSize is zero, 1 MiB is used.
+	MaxMessageSize int
+
+	initOnce sync.Once
+	origin   string
+
+	mu     sync.Mutex
+	pub    *conn
+	sub    *conn
+	closed bool
+	done   chan struct{}
+}
+
+func (b *Bridge) init() {
+	b.initOnce.Do(func() {
+		var p [8]byte
+		if _, err := rand.Read(p[:]); err != nil {
+			panic(err)
+		}
+		b.origin = hex.EncodeToString(p[:])
+		b.done = make(chan struct{})
+	})
+}
+
+func (b *Bridge) channel() string {
+	if b.Channel == "" {
+		return defaultChannel
+	}
+	return b.Channel
+}
+
+func (b *Bridge) timeout() time.Duration {
+	if b.Timeout <= 0 {
+		return defaultTimeout
+	}
+	return b.Timeout
+}
+
+func (b *Bridge) maxMessageSize() int {
+	if b.MaxMessageSize <= 0 {
+		return defaultMaxMessage
+	}
+	return b.MaxMessageSize
+}
+
+// dial connects and authenticates to the server.
+func (b *Bridge) dial() (*conn, error) {
+	addr := b.Addr
+	if addr == "" {
+		addr = defaultAddr
+	}
+	dial := b.DialContext
+	if dial == nil {
+		var d net.Dialer
+		dial = d.DialContext
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout())
+	defer cancel()
+	nc, err := dial(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &conn{nc: nc, br: bufio.NewReader(nc), maxBulk: b.maxMessageSize()}
+	if b.Password != "" {
+		nc.SetDeadline(time.Now().Add(b.timeout()))
+		if _, err := c.do("AUTH", b.Password); err != nil {
+			nc.Close()
+			return nil, err
+		}
+		nc.SetDeadline(time.Time{})
+	}
+	return c, nil
+}
+
+// Publish publishes a broadcast to the other instances.
+func (b *Bridge) Publish(room string, msg []byte) error {
+	b.init()
+	payload := appendMessage(nil, b.origin, room, msg)
+	if len(payload) > b.maxMessageSize() {
+		return errTooLarge
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+	if b.pub == nil {
+		c, err := b.dial()
+		if err != nil {
+			return err
+		}
+		b.pub = c
+	}
+	b.pub.nc.SetDeadline(time.Now().Add(b.timeout()))
+	if _, err := b.pub.do("PUBLISH", b.channel(), string(payload)); err != nil {
+		// Reconnect on the next publish.
+		b.pub.nc.Close()
+		b.pub = nil
+		return err
+	}
+	return nil
+}
+
+// Subscribe subscribes to the channel and calls handler with the broadcasts
+// of the other instances from a goroutine. Subscribe returns an error if the
+// first subscription fails. After a later failure, the bridge resubscribes
+// until it is closed. Broadcasts published while the bridge is not
+// subscribed are lost.
+func (b *Bridge) Subscribe(handler func(room string, msg []byte)) error {
+	b.init()
+	c, err := b.subscribe()
+	if err != nil {
+		return err
+	}
+	go b.receiveLoop(c, handler)
+	return nil
+}
+
+// subscribe dials a connection and subscribes it to the channel.
+func (b *Bridge) subscribe() (*conn, error) {
+	c, err := b.dial()
+	if err != nil {
+		return nil, err
+	}
+	c.nc.SetDeadline(time.Now().Add(b.timeout()))
+	if _, err := c.do("SUBSCRIBE", b.channel()); err != nil {
+		c.nc.Close()
+		return nil, err
+	}
+	c.nc.SetDeadline(time.Time{})
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		c.nc.Close()
+		return nil, ErrClosed
+	}
+	b.sub = c
+	return c, nil
+}
+
+func (b *Bridge) receiveLoop(c *conn, handler func(room string, msg []byte)) {
+	for c != nil {
+		b.receive(c, handler)
+		c.nc.Close()
+		c = b.resubscribe()
+	}
+}
+
+// resubscribe subscribes a new connection. It returns nil when the bridge
+// is closed.
+func (b *Bridge) resubscribe() *conn {
+	retry := b.RetryInterval
+	if retry <= 0 {
+		retry = defaultRetryInterval
+	}
+	for {
+		select {
+		case <-b.done:
+			return nil
+		case <-time.After(retry):
+		}
+		c, err := b.subscribe()
+		if err == ErrClosed {
+			return nil
+		}
+		if err == nil {
+			return c
+		}
+	}
+}
+
+// receive calls handler with the messages on c until a read fails.
+func (b *Bridge) receive(c *conn, handler func(room string, msg []byte)) {
+	for {
+		v, err := c.read()
+		if err != nil {
+			return
+		}
+		// A message is the array ["message", channel, payload].
+		a, ok := v.([]interface{})
+		if !ok || len(a) != 3 {
+			continue
+		}
+		if kind, _ := a[0].([]byte); string(kind) != "message" {
+			continue
+		}
+		payload, _ := a[2].([]byte)
+		origin, room, msg, err := parseMessage(payload)
+		if err != nil || origin == b.origin {
+			continue
+		}
+		handler(room, msg)
+	}
+}
+
+// Close closes the connections and stops the subscription.
+func (b *Bridge) Close() error {
+	b.init()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+	b.closed = true
+	close(b.done)
+	if b.pub != nil {
+		b.pub.nc.Close()
+	}
+	if b.sub != nil {
+		b.sub.nc.Close()
+	}
+	return nil
+}
+
+func appendMessage(b []byte, origin, room string, msg []byte) []byte {
+	b = append(b, origin...)
+	b = append(b, ' ')
+	b = strconv.AppendInt(b, int64(len(room)), 10)
+	b = append(b, ' ')
+	b = append(b, room...)
+	return append(b, msg...)
+}
+
+func parseMessage(p []byte) (origin, room string, msg []byte, err error) {
+	i := bytes.IndexByte(p, ' ')
+	if i < 0 {
+		return "", "", nil, errBadReply
+	}
+	origin, p = string(p[:i]), p[i+1:]
+	i = bytes.IndexByte(p, ' ')
+	if i < 0 {
+		return "", "", nil, errBadReply
+	}
+	n, err := strconv.Atoi(string(p[:i]))
+	p = p[i+1:]
+	if err != nil || n < 0 || n > len(p) {
+		return "", "", nil, errBadReply
+	}
+	return origin, string(p[:n]), p[n:], nil
+}
+
+// conn is a connection that speaks the Redis serialization protocol.
+type conn struct {
+	nc      net.Conn
+	br      *bufio.Reader
+	maxBulk int // largest bulk string reply
+}
+
+// do sends a command and reads the reply.
+func (c *conn) do(args ...string) (interface{}, error) {
+	var buf []byte
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, "\r\n"...)
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, "\r\n"...)
+		buf = append(buf, arg...)
+		buf = append(buf, "\r\n"...)
+	}
+	if _, err := c.nc.Write(buf); err != nil {
+		return nil, err
+	}
+	return c.read()
+}
+
+// read reads a reply. Simple strings are returned as string, integers as
+// int64, bulk strings as []byte and arrays as []interface{}. An error reply
+// is returned as an error.
+func (c *conn) read() (interface{}, error) {
+	line, err := c.br.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errBadReply
+	}
+	kind, line := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return string(line), nil
+	case '-':
+		return nil, fmt.Errorf("redisbridge: server error: %s", line)
+	case ':':
+		n, err := strconv.ParseInt(string(line), 10, 64)
+		if err != nil {
+			return nil, errBadReply
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(string(line))
+		if err != nil || n < -1 {
+			return nil, errBadReply
+		}
+		if n == -1 {
+			return nil, nil
+		}
+		if n > c.maxBulk {
+			return nil, errTooLarge
+		}
+		p := make([]byte, n+2)
+		if _, err := io.ReadFull(c.br, p); err != nil {
+			return nil, err
+		}
+		return p[:n], nil
+	case '*':
+		n, err := strconv.Atoi(string(line))
+		if err != nil || n < -1 {
+			return nil, errBadReply
+		}
+		if n == -1 {
+			return nil, nil
+		}
+		if n > maxArrayLen {
+			return nil, errBadReply
+		}
+		a := make([]interface{}, n)
+		for i := range a {
+			if a[i], err = c.read(); err != nil {
+				return nil, err
+			}
+		}
+		return a, nil
+	default:
+		return nil, errBadReply
+	}
+}
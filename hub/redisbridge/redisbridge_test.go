@@ -0,0 +1,224 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package redisbridge
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer is a Redis server that implements AUTH, PUBLISH and SUBSCRIBE.
+type fakeServer struct {
+	ln       net.Listener
+	password string
+
+	mu    sync.Mutex
+	conns map[net.Conn]bool // true if subscribed
+}
+
+func newFakeServer(t *testing.T, password string) *fakeServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeServer{ln: ln, password: password, conns: make(map[net.Conn]bool)}
+	t.Cleanup(func() { ln.Close(); s.dropAll() })
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns[nc] = false
+			s.mu.Unlock()
+			go s.serve(nc)
+		}
+	}()
+	return s
+}
+
+func (s *fakeServer) dropAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for nc := range s.conns {
+		nc.Close()
+		delete(s.conns, nc)
+	}
+}
+
+func (s *fakeServer) write(nc net.Conn, reply string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	nc.Write([]byte(reply))
+}
+
+func (s *fakeServer) serve(nc net.Conn) {
+	c := &conn{nc: nc, br: bufio.NewReader(nc), maxBulk: defaultMaxMessage}
+	authed := s.password == ""
+	for {
+		v, err := c.read()
+		if err != nil {
+			nc.Close()
+			return
+		}
+		var args []string
+		for _, a := range v.([]interface{}) {
+			args = append(args, string(a.([]byte)))
+		}
+		switch {
+		case args[0] == "AUTH":
+			if args[1] != s.password {
+				s.write(nc, "-ERR invalid password\r\n")
+				continue
+			}
+			authed = true
+			s.write(nc, "+OK\r\n")
+		case !authed:
+			s.write(nc, "-NOAUTH Authentication required.\r\n")
+		case args[0] == "SUBSCRIBE":
+			s.mu.Lock()
+			s.conns[nc] = true
+			s.mu.Unlock()
+			s.write(nc, fmt.Sprintf("*3\r\n$9\r\nsubscribe\r\n$%d\r\n%s\r\n:1\r\n", len(args[1]), args[1]))
+		case args[0] == "PUBLISH":
+			msg := fmt.Sprintf("*3\r\n$7\r\nmessage\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(args[1]), args[1], len(args[2]), args[2])
+			s.mu.Lock()
+			n := 0
+			for sub, subscribed := range s.conns {
+				if subscribed {
+					sub.Write([]byte(msg))
+					n++
+				}
+			}
+			s.mu.Unlock()
+			s.write(nc, fmt.Sprintf(":%d\r\n", n))
+		default:
+			s.write(nc, "-ERR unknown command\r\n")
+		}
+	}
+}
+
+type received struct {
+	room, msg string
+}
+
+func subscribe(t *testing.T, b *Bridge) chan received {
+	t.Helper()
+	ch := make(chan received, 10)
+	if err := b.Subscribe(func(room string, msg []byte) {
+		ch <- received{room, string(msg)}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return ch
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	s := newFakeServer(t, "secret")
+	addr := s.ln.Addr().String()
+	a := &Bridge{Addr: addr, Password: "secret"}
+	defer a.Close()
+	b := &Bridge{Addr: addr, Password: "secret"}
+	defer b.Close()
+	chA, chB := subscribe(t, a), subscribe(t, b)
+
+	if err := a.Publish("lobby", []byte("hello world")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-chB:
+		if want := (received{"lobby", "hello world"}); got != want {
+			t.Errorf("received %v, want %v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("broadcast not received")
+	}
+	select {
+	case got := <-chA:
+		t.Errorf("publisher received its own broadcast %v", got)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	bad := &Bridge{Addr: addr, Password: "wrong"}
+	if err := bad.Publish("lobby", nil); err == nil {
+		t.Error("Publish() with wrong password returned nil error")
+	}
+}
+
+func TestResubscribe(t *testing.T) {
+	s := newFakeServer(t, "")
+	addr := s.ln.Addr().String()
+	a := &Bridge{Addr: addr}
+	defer a.Close()
+	b := &Bridge{Addr: addr, RetryInterval: 10 * time.Millisecond}
+	defer b.Close()
+	ch := subscribe(t, b)
+	if err := a.Publish("room", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	<-ch
+
+	// Both bridges reconnect after the server drops the connections.
+	s.dropAll()
+	if err := a.Publish("room", []byte("lost")); err == nil {
+		t.Fatal("Publish() on dropped connection returned nil error")
+	}
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if err := a.Publish("room", []byte("x")); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-ch:
+			return
+		default:
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("bridge did not resubscribe")
+		}
+	}
+}
+
+func TestParseMessage(t *testing.T) {
+	p := appendMessage(nil, "origin", "a room", []byte("msg"))
+	origin, room, msg, err := parseMessage(p)
+	if origin != "origin" || room != "a room" || string(msg) != "msg" || err != nil {
+		t.Errorf("parseMessage(%q) = %q, %q, %q, %v", p, origin, room, msg, err)
+	}
+	for _, p := range []string{"", "origin", "origin x room", "origin 9 room"} {
+		if _, _, _, err := parseMessage([]byte(p)); err != errBadReply {
+			t.Errorf("parseMessage(%q) error = %v, want %v", p, err, errBadReply)
+		}
+	}
+}
+
+func TestReadLimits(t *testing.T) {
+	tests := []struct {
+		reply string
+		err   error
+	}{
+		{"$4611686018427387904\r\n", errTooLarge},
+		{"$17\r\n", errTooLarge},
+		{"*4611686018427387904\r\n", errBadReply},
+		{"*1\r\n$17\r\n", errTooLarge},
+	}
+	for _, tt := range tests {
+		c := &conn{br: bufio.NewReader(strings.NewReader(tt.reply)), maxBulk: 16}
+		if _, err := c.read(); err != tt.err {
+			t.Errorf("read(%q) error = %v, want %v", tt.reply, err, tt.err)
+		}
+	}
+
+	b := &Bridge{MaxMessageSize: 16}
+	defer b.Close()
+	if err := b.Publish("room", make([]byte, 16)); err != errTooLarge {
+		t.Errorf("Publish() error = %v, want %v", err, errTooLarge)
+	}
+}
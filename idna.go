@@ -0,0 +1,48 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+var errIDNA = errors.New("websocket: invalid internationalized domain name")
+
+// asciiHostPort converts the domain name in hostPort to its ASCII form with
+// the IDNA lookup profile for DNS lookups, the TLS server name and the Host
+// header. IP literals and ASCII names are returned unchanged.
+func asciiHostPort(hostPort string) (string, error) {
+	if isASCII(hostPort) || strings.HasPrefix(hostPort, "[") {
+		return hostPort, nil
+	}
+	host, port := hostPort, ""
+	if i := strings.LastIndex(hostPort, ":"); i >= 0 {
+		host, port = hostPort[:i], hostPort[i:]
+	}
+	host, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return "", errIDNA
+	}
+	// The lookup profile does not check the DNS label length.
+	for _, label := range strings.Split(host, ".") {
+		if len(label) > 63 {
+			return "", errIDNA
+		}
+	}
+	return host + port, nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestASCIIHostPort(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"example.com", "example.com"},
+		{"example.com:8080", "example.com:8080"},
+		{"bücher.example", "xn--bcher-kva.example"},
+		{"BÜCHER.example:443", "xn--bcher-kva.example:443"},
+		{"münchen.de", "xn--mnchen-3ya.de"},
+		{"例え。テスト", "xn--r8jz45g.xn--zckzah"},
+		{"ドメイン名例.jp:7777", "xn--eckwd4c7cu47r2wf.jp:7777"},
+		{"[fe80::1%eth0]:80", "[fe80::1%eth0]:80"},
+		{"[::1]", "[::1]"},
+	}
+	for _, tt := range tests {
+		got, err := asciiHostPort(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("asciiHostPort(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := asciiHostPort(strings.Repeat("ü", 64) + ".example"); err != errIDNA {
+		t.Errorf("asciiHostPort(long label) error = %v, want %v", err, errIDNA)
+	}
+}
+
+func TestDialIDNAndZone(t *testing.T) {
+	hosts := make(chan string, 1)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts <- r.Host
+		if c, err := (&Upgrader{}).Upgrade(w, r, nil); err == nil {
+			c.Close()
+		}
+	}))
+	defer s.Close()
+
+	tests := []struct {
+		url, addr, host string
+	}{
+		{"ws://bücher.example:8080/", "xn--bcher-kva.example:8080", "xn--bcher-kva.example:8080"},
+		{"ws://b%C3%BCcher.example/", "xn--bcher-kva.example:80", "xn--bcher-kva.example"},
+		{"ws://[fe80::1%25eth0]:8080/", "[fe80::1%eth0]:8080", "[fe80::1]:8080"},
+	}
+	for _, tt := range tests {
+		var addr string
+		d := Dialer{NetDialContext: func(ctx context.Context, network, a string) (net.Conn, error) {
+			addr = a
+			return net.Dial(network, s.Listener.Addr().String())
+		}}
+		c, _, err := d.Dial(tt.url, nil)
+		if err != nil {
+			t.Errorf("%s: Dial() error = %v", tt.url, err)
+			continue
+		}
+		c.Close()
+		if host := <-hosts; addr != tt.addr || host != tt.host {
+			t.Errorf("%s: dialed %q with Host %q, want %q with Host %q", tt.url, addr, host, tt.addr, tt.host)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// IPFilter allows or denies handshake requests by the IP address of the
+// client. Set it as Upgrader.IPFilter to reject requests before the
+// handshake work is done.
+//
+// The client address is the remote address of the request. If the remote
+// address is a trusted proxy, the client address is taken from the
+// X-Forwarded-For header, skipping trusted proxies from the right, or from
+// the X-Real-IP header if X-Forwarded-For is not set.
+type IPFilter struct {
+	// Allow lists the allowed networks. If Allow is empty, all addresses
+	// that are not denied are allowed.
+	Allow []netip.Prefix
+
+	// Deny lists the denied networks. Deny takes precedence over Allow.
+	Deny []netip.Prefix
+
+	// TrustedProxies lists the networks of the proxies whose forwarding
+	// headers are trusted. If TrustedProxies is empty, the headers are
+	// ignored.
+	TrustedProxies []netip.Prefix
+}
+
+// ParsePrefixes parses networks in CIDR notation, such as "10.0.0.0/8", or
+// single addresses, such as "192.0.2.1" or "2001:db8::1".
+func ParsePrefixes(s ...string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(s))
+	for _, v := range s {
+		if !strings.Contains(v, "/") {
+			addr, err := netip.ParseAddr(v)
+			if err != nil {
+				return nil, err
+			}
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		p, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, p.Masked())
+	}
+	return prefixes, nil
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseIP(s string) (netip.Addr, bool) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(s))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	// Prefixes do not contain zoned addresses.
+	return addr.WithZone("").Unmap(), true
+}
+
+// ClientIP returns the IP address of the client of r. ClientIP returns
+// false if the address cannot be determined.
+func (f *IPFilter) ClientIP(r *http.Request) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, ok := parseIP(host)
+	if !ok || !containsAddr(f.TrustedProxies, addr) {
+		return addr, ok
+	}
+
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, ok := parseIP(hops[i])
+			if !ok {
+				// A malformed entry cannot be trusted. Use the last
+				// trusted address.
+				return addr, true
+			}
+			addr = hop
+			if !containsAddr(f.TrustedProxies, hop) {
+				break
+			}
+		}
+		return addr, true
+	}
+	if hop, ok := parseIP(r.Header.Get("X-Real-IP")); ok {
+		return hop, true
+	}
+	return addr, true
+}
+
+// Allowed returns true if the client of r is allowed.
+func (f *IPFilter) Allowed(r *http.Request) bool {
+	addr, ok := f.ClientIP(r)
+	if !ok {
+		return false
+	}
+	if containsAddr(f.Deny, addr) {
+		return false
+	}
+	return len(f.Allow) == 0 || containsAddr(f.Allow, addr)
+}
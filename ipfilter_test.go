@@ -0,0 +1,87 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func mustParsePrefixes(t *testing.T, s ...string) []netip.Prefix {
+	t.Helper()
+	p, err := ParsePrefixes(s...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestIPFilter(t *testing.T) {
+	f := &IPFilter{
+		Allow:          mustParsePrefixes(t, "192.0.2.0/24", "2001:db8::/32"),
+		Deny:           mustParsePrefixes(t, "192.0.2.66", "2001:db8::66"),
+		TrustedProxies: mustParsePrefixes(t, "10.0.0.0/8", "fe80::/10"),
+	}
+	tests := []struct {
+		name    string
+		remote  string
+		xff     []string
+		realIP  string
+		client  string
+		allowed bool
+	}{
+		{"direct", "192.0.2.1:1234", nil, "", "192.0.2.1", true},
+		{"direct ipv6", "[2001:db8::1]:1234", nil, "", "2001:db8::1", true},
+		{"mapped", "[::ffff:192.0.2.1]:1234", nil, "", "192.0.2.1", true},
+		{"denied", "192.0.2.66:1234", nil, "", "192.0.2.66", false},
+		{"zoned denied", "[2001:db8::66%eth0]:1234", nil, "", "2001:db8::66", false},
+		{"zoned trusted proxy", "[fe80::1%eth0]:1234", []string{"192.0.2.1"}, "", "192.0.2.1", true},
+		{"not allowed", "198.51.100.1:1234", nil, "", "198.51.100.1", false},
+		{"untrusted proxy", "198.51.100.1:1234", []string{"192.0.2.1"}, "", "198.51.100.1", false},
+		{"trusted proxy", "10.0.0.1:1234", []string{"192.0.2.1"}, "", "192.0.2.1", true},
+		{"proxy chain", "10.0.0.1:1234", []string{"198.51.100.9, 192.0.2.1", "10.0.0.2"}, "", "192.0.2.1", true},
+		{"spoofed denied", "10.0.0.1:1234", []string{"192.0.2.1, 192.0.2.66"}, "", "192.0.2.66", false},
+		{"all proxies", "10.0.0.1:1234", []string{"10.0.0.3"}, "", "10.0.0.3", false},
+		{"malformed", "10.0.0.1:1234", []string{"192.0.2.1, junk"}, "", "10.0.0.1", false},
+		{"real ip", "10.0.0.1:1234", nil, "192.0.2.7", "192.0.2.7", true},
+		{"untrusted real ip", "198.51.100.1:1234", nil, "192.0.2.7", "198.51.100.1", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tt.remote
+		for _, v := range tt.xff {
+			r.Header.Add("X-Forwarded-For", v)
+		}
+		if tt.realIP != "" {
+			r.Header.Set("X-Real-IP", tt.realIP)
+		}
+		if addr, ok := f.ClientIP(r); !ok || addr.String() != tt.client {
+			t.Errorf("%s: ClientIP() = %v, %v, want %s", tt.name, addr, ok, tt.client)
+		}
+		if got := f.Allowed(r); got != tt.allowed {
+			t.Errorf("%s: Allowed() = %v, want %v", tt.name, got, tt.allowed)
+		}
+	}
+
+	if _, err := ParsePrefixes("192.0.2.0/33"); err == nil {
+		t.Error("ParsePrefixes() accepted a bad prefix")
+	}
+}
+
+func TestUpgraderIPFilter(t *testing.T) {
+	upgrader := Upgrader{IPFilter: &IPFilter{Deny: mustParsePrefixes(t, "127.0.0.0/8", "::1")}}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c, err := upgrader.Upgrade(w, r, nil); err == nil {
+			c.Close()
+		}
+	}))
+	defer s.Close()
+	_, resp, err := DefaultDialer.Dial(makeWsProto(s.URL), nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Dial() = %v, %v, want status %d", resp, err, http.StatusForbidden)
+	}
+}
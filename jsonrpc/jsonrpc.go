@@ -0,0 +1,149 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package jsonrpc implements JSON-RPC 2.0 over WebSocket connections.
+//
+// The package provides a codec for the rpc package. Connections returned from
+// NewConn support requests, notifications, batches and error objects as
+// specified in https://www.jsonrpc.org/specification and interoperate with
+// JSON-RPC clients and servers in other languages.
+//
+// Request IDs are represented in rpc messages as the JSON encoding of the ID.
+// Request parameters and response results are JSON values.
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/gorilla/websocket"
+	"github.com/gorilla/websocket/rpc"
+)
+
+// Codec is an rpc.Codec for JSON-RPC 2.0 messages.
+var Codec rpc.Codec = codec{}
+
+// NewConn returns an rpc connection on ws that uses the JSON-RPC 2.0 codec.
+// The Codec field in config is ignored.
+func NewConn(ws *websocket.Conn, config *rpc.Config) *rpc.Conn {
+	var c rpc.Config
+	if config != nil {
+		c = *config
+	}
+	c.Codec = Codec
+	return rpc.NewConn(ws, &c)
+}
+
+const version = "2.0"
+
+type message struct {
+	Version string           `json:"jsonrpc"`
+	Method  *string          `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *rpc.Error       `json:"error,omitempty"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+}
+
+type codec struct{}
+
+func (codec) Decode(messageType int, p []byte) ([]*rpc.Message, bool, error) {
+	p = bytes.TrimSpace(p)
+	if len(p) > 0 && p[0] == '[' {
+		var raws []json.RawMessage
+		if err := json.Unmarshal(p, &raws); err != nil {
+			return nil, false, &rpc.Error{Code: rpc.CodeParseError, Message: err.Error()}
+		}
+		if len(raws) == 0 {
+			return nil, false, &rpc.Error{Code: rpc.CodeInvalidRequest, Message: "empty batch"}
+		}
+		msgs := make([]*rpc.Message, len(raws))
+		for i, raw := range raws {
+			msgs[i] = decodeMessage(raw)
+		}
+		return msgs, true, nil
+	}
+	if !json.Valid(p) {
+		return nil, false, &rpc.Error{Code: rpc.CodeParseError, Message: "invalid JSON"}
+	}
+	return []*rpc.Message{decodeMessage(p)}, false, nil
+}
+
+// decodeMessage decodes a single JSON-RPC object. Invalid requests are
+// returned as a request with the Error field set.
+func decodeMessage(p []byte) *rpc.Message {
+	var jm message
+	if err := json.Unmarshal(p, &jm); err != nil {
+		return &rpc.Message{Error: &rpc.Error{Code: rpc.CodeInvalidRequest, Message: err.Error()}}
+	}
+
+	m := &rpc.Message{}
+	if jm.ID != nil {
+		var id bytes.Buffer
+		if err := json.Compact(&id, *jm.ID); err == nil {
+			m.ID = id.String()
+		}
+	}
+
+	switch {
+	case jm.Version != version:
+		m.Error = &rpc.Error{Code: rpc.CodeInvalidRequest, Message: "missing or invalid jsonrpc version"}
+	case jm.Method != nil:
+		m.Method = *jm.Method
+		m.Payload = jm.Params
+		if m.Method == "" {
+			m.Error = &rpc.Error{Code: rpc.CodeInvalidRequest, Message: "empty method"}
+		}
+	case jm.Result != nil || jm.Error != nil:
+		m.Response = true
+		m.Payload = jm.Result
+		m.Error = jm.Error
+	default:
+		m.Error = &rpc.Error{Code: rpc.CodeInvalidRequest, Message: "missing method"}
+	}
+	return m
+}
+
+var null = json.RawMessage("null")
+
+func (codec) Encode(msgs []*rpc.Message, batch bool) (int, []byte, error) {
+	jms := make([]*message, len(msgs))
+	for i, m := range msgs {
+		jm := &message{Version: version}
+		if m.ID != "" {
+			id := json.RawMessage(m.ID)
+			jm.ID = &id
+		}
+		if m.Response {
+			if jm.ID == nil {
+				jm.ID = &null
+			}
+			if m.Error != nil {
+				jm.Error = m.Error
+			} else if len(m.Payload) == 0 {
+				jm.Result = null
+			} else {
+				jm.Result = m.Payload
+			}
+		} else {
+			method := m.Method
+			jm.Method = &method
+			jm.Params = m.Payload
+		}
+		jms[i] = jm
+	}
+
+	var v interface{} = jms
+	if !batch {
+		if len(jms) != 1 {
+			return 0, nil, &rpc.Error{Code: rpc.CodeInternalError, Message: "jsonrpc: multiple messages outside of batch"}
+		}
+		v = jms[0]
+	}
+	p, err := json.Marshal(v)
+	if err != nil {
+		return 0, nil, err
+	}
+	return websocket.TextMessage, p, nil
+}
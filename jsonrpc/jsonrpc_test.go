@@ -0,0 +1,141 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/gorilla/websocket/rpc"
+)
+
+func newTestServer(t *testing.T) string {
+	mux := rpc.NewServeMux()
+	mux.HandleFunc("subtract", func(ctx context.Context, req *rpc.Request) ([]byte, error) {
+		var args []int
+		if err := json.Unmarshal(req.Payload, &args); err != nil || len(args) != 2 {
+			return nil, &rpc.Error{Code: rpc.CodeInvalidParams, Message: "Invalid params"}
+		}
+		return json.Marshal(args[0] - args[1])
+	})
+	mux.HandleFunc("notify_hello", func(ctx context.Context, req *rpc.Request) ([]byte, error) {
+		return nil, nil
+	})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		c := NewConn(ws, &rpc.Config{Handler: mux})
+		<-c.Done()
+	}))
+	t.Cleanup(s.Close)
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+// Examples from the JSON-RPC 2.0 specification. An empty response indicates
+// that no response is expected.
+var specTests = []struct {
+	request  string
+	response string
+}{
+	{
+		`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}`,
+		`{"jsonrpc": "2.0", "result": 19, "id": 1}`,
+	},
+	{
+		`{"jsonrpc": "2.0", "method": "subtract", "params": [23, 42], "id": "abc"}`,
+		`{"jsonrpc": "2.0", "result": -19, "id": "abc"}`,
+	},
+	{
+		`{"jsonrpc": "2.0", "method": "foobar", "id": "1"}`,
+		`{"jsonrpc": "2.0", "error": {"code": -32601, "message": "method not found: foobar"}, "id": "1"}`,
+	},
+	{
+		`{"jsonrpc": "2.0", "method": "foobar, "params": "bar", "baz]`,
+		`{"jsonrpc": "2.0", "error": {"code": -32700, "message": "invalid JSON"}, "id": null}`,
+	},
+	{
+		`{"jsonrpc": "2.0", "method": 1, "params": "bar"}`,
+		`{"jsonrpc": "2.0", "error": {"code": -32600, "message": "json: cannot unmarshal number into Go struct field message.method of type string"}, "id": null}`,
+	},
+	{
+		`[]`,
+		`{"jsonrpc": "2.0", "error": {"code": -32600, "message": "empty batch"}, "id": null}`,
+	},
+	{
+		`[1, {"jsonrpc": "2.0", "method": "subtract", "params": [1, 2], "id": 2}, {"jsonrpc": "2.0", "method": "notify_hello"}]`,
+		`[{"jsonrpc": "2.0", "error": {"code": -32600, "message": "json: cannot unmarshal number into Go value of type jsonrpc.message"}, "id": null},
+		  {"jsonrpc": "2.0", "result": -1, "id": 2}]`,
+	},
+}
+
+func TestSpecExamples(t *testing.T) {
+	ws, _, err := websocket.DefaultDialer.Dial(newTestServer(t), nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer ws.Close()
+
+	for _, tt := range specTests {
+		if err := ws.WriteMessage(websocket.TextMessage, []byte(tt.request)); err != nil {
+			t.Fatalf("WriteMessage: %v", err)
+		}
+		ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, p, err := ws.ReadMessage()
+		if err != nil {
+			t.Fatalf("ReadMessage: %v", err)
+		}
+		var got, want interface{}
+		if err := json.Unmarshal(p, &got); err != nil {
+			t.Fatalf("response %s is not valid JSON: %v", p, err)
+		}
+		if err := json.Unmarshal([]byte(tt.response), &want); err != nil {
+			t.Fatalf("bad test response %s: %v", tt.response, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("request %s\n got %s\nwant %s", tt.request, p, tt.response)
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	ws, _, err := websocket.DefaultDialer.Dial(newTestServer(t), nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	c := NewConn(ws, nil)
+	defer c.Close()
+	ctx := context.Background()
+
+	result, err := c.Call(ctx, "subtract", []byte(`[42, 23]`))
+	if err != nil || string(result) != "19" {
+		t.Errorf("Call returned %s, %v, want 19", result, err)
+	}
+
+	calls := []*rpc.BatchCall{
+		{Method: "subtract", Payload: []byte(`[1, 2]`)},
+		{Method: "notify_hello", Notify: true},
+		{Method: "subtract", Payload: []byte(`{}`)},
+	}
+	if err := c.Batch(ctx, calls); err != nil {
+		t.Fatalf("Batch returned error %v", err)
+	}
+	if string(calls[0].Result) != "-1" || calls[0].Error != nil {
+		t.Errorf("calls[0] = %s, %v, want -1", calls[0].Result, calls[0].Error)
+	}
+	var e *rpc.Error
+	if !errors.As(calls[2].Error, &e) || e.Code != rpc.CodeInvalidParams {
+		t.Errorf("calls[2].Error = %v, want code %d", calls[2].Error, rpc.CodeInvalidParams)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import "sort"
+
+// SetLabel sets a label of the connection, such as the tenant, plan or
+// region of the peer. An empty value removes the label.
+//
+// Labels are added to the profiler labels of the connection and are used
+// by the wsmetrics and wslog packages and by hub.Hub.Select, so that all of
+// them can be sliced by the same dimensions. Set the labels before the
+// connection is used, preferably with Upgrader.Labels or Dialer.Labels.
+func (c *Conn) SetLabel(key, value string) {
+	c.labelsMu.Lock()
+	defer c.labelsMu.Unlock()
+	var old map[string]string
+	if p := c.labels.Load(); p != nil {
+		old = *p
+	}
+	labels := make(map[string]string, len(old)+1)
+	for k, v := range old {
+		labels[k] = v
+	}
+	if value == "" {
+		delete(labels, key)
+	} else {
+		labels[key] = value
+	}
+	c.labels.Store(&labels)
+}
+
+// setLabels sets the labels of the connection from a map.
+func (c *Conn) setLabels(labels map[string]string) {
+	for k, v := range labels {
+		c.SetLabel(k, v)
+	}
+}
+
+// Label returns the value of a label of the connection, or the empty string
+// if the label is not set.
+func (c *Conn) Label(key string) string {
+	if p := c.labels.Load(); p != nil {
+		return (*p)[key]
+	}
+	return ""
+}
+
+// Labels returns a copy of the labels of the connection.
+func (c *Conn) Labels() map[string]string {
+	labels := make(map[string]string)
+	if p := c.labels.Load(); p != nil {
+		for k, v := range *p {
+			labels[k] = v
+		}
+	}
+	return labels
+}
+
+// LabelKeys returns the keys of the labels of the connection in sorted
+// order.
+func (c *Conn) LabelKeys() []string {
+	p := c.labels.Load()
+	if p == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(*p))
+	for k := range *p {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
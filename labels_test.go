@@ -0,0 +1,72 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime/pprof"
+	"testing"
+)
+
+func TestLabels(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	server.SetLabel("tenant", "acme")
+	server.SetLabel("region", "eu")
+	server.SetLabel("plan", "free")
+	server.SetLabel("plan", "")
+
+	if got := server.Label("tenant"); got != "acme" {
+		t.Errorf("Label(tenant) = %q, want acme", got)
+	}
+	want := map[string]string{"tenant": "acme", "region": "eu"}
+	if got := server.Labels(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Labels() = %v, want %v", got, want)
+	}
+	if got := server.LabelKeys(); !reflect.DeepEqual(got, []string{"region", "tenant"}) {
+		t.Errorf("LabelKeys() = %v", got)
+	}
+	server.Labels()["tenant"] = "changed"
+	if got := server.Label("tenant"); got != "acme" {
+		t.Errorf("Label(tenant) after changing Labels() = %q, want acme", got)
+	}
+
+	ctx := pprof.WithLabels(context.Background(), server.ProfilerLabels())
+	if v, _ := pprof.Label(ctx, "tenant"); v != "acme" {
+		t.Errorf("profiler label tenant = %q, want acme", v)
+	}
+}
+
+func TestUpgraderLabels(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u := Upgrader{Labels: func(r *http.Request) map[string]string {
+			return map[string]string{"tenant": r.URL.Query().Get("tenant")}
+		}}
+		c, err := u.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		c.WriteMessage(TextMessage, []byte(c.Label("tenant")))
+	}))
+	defer s.Close()
+	d := Dialer{Labels: map[string]string{"region": "eu"}}
+	c, _, err := d.Dial(makeWsProto(s.URL)+"?tenant=acme", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if got := c.Label("region"); got != "eu" {
+		t.Errorf("client Label(region) = %q, want eu", got)
+	}
+	if _, p, err := c.ReadMessage(); err != nil || string(p) != "acme" {
+		t.Errorf("server label tenant = %q, %v, want acme", p, err)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"io"
+	"sync"
+)
+
+// maskSourceMu serializes reads from the mask key sources set with
+// SetMaskKeySource, which can be shared by connections and need not be safe
+// for concurrent use.
+var maskSourceMu sync.Mutex
+
+type maskKeySource struct{ r io.Reader }
+
+// SetMaskKeySource sets the source of the masking keys for the frames written
+// by a client connection. Each frame reads four bytes from r. If r is nil,
+// crypto/rand.Reader is used.
+//
+// Masking protects intermediaries from cache poisoning by scripts in
+// browsers. Clients that are not browsers, such as load generators, can use
+// a fast seeded generator like a math/rand.Rand, and tests can use
+// FixedMaskKeySource for reproducible frames. Reads from the sources set
+// with SetMaskKeySource are serialized, so a source can be shared by
+// connections without being safe for concurrent use.
+//
+// Server connections do not mask frames and ignore the source.
+func (c *Conn) SetMaskKeySource(r io.Reader) {
+	if r == nil {
+		c.maskSource.Store(nil)
+	} else {
+		c.maskSource.Store(&maskKeySource{r})
+	}
+}
+
+// newMaskKey returns the masking key for a frame.
+func (c *Conn) newMaskKey() [4]byte {
+	s := c.maskSource.Load()
+	if s == nil {
+		return newMaskKey()
+	}
+	var k [4]byte
+	maskSourceMu.Lock()
+	_, _ = io.ReadFull(s.r, k[:])
+	maskSourceMu.Unlock()
+	return k
+}
+
+// FixedMaskKeySource returns a mask key source for SetMaskKeySource that
+// returns key for every frame. It is intended for test vectors; a fixed key
+// defeats the purpose of masking.
+func FixedMaskKeySource(key [4]byte) io.Reader {
+	return fixedMaskKey(key)
+}
+
+type fixedMaskKey [4]byte
+
+func (k fixedMaskKey) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = k[i%4]
+	}
+	return len(p), nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// rawFrames writes a text message and a ping on client and returns the
+// bytes read by the server's network connection.
+func rawFrames(t *testing.T, source io.Reader) []byte {
+	t.Helper()
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	client.SetMaskKeySource(source)
+	if err := client.WriteMessage(TextMessage, []byte("hi")); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.WriteControl(PingMessage, []byte("p"), time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	p := make([]byte, 15)
+	if _, err := io.ReadFull(server.NetConn(), p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestMaskKeySource(t *testing.T) {
+	got := rawFrames(t, FixedMaskKeySource([4]byte{1, 2, 3, 4}))
+	want := []byte{
+		0x81, 0x82, 1, 2, 3, 4, 'h' ^ 1, 'i' ^ 2,
+		0x89, 0x81, 1, 2, 3, 4, 'p' ^ 1,
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("frames = %x, want %x", got, want)
+	}
+
+	a := rawFrames(t, rand.New(rand.NewSource(1)))
+	b := rawFrames(t, rand.New(rand.NewSource(1)))
+	if !bytes.Equal(a, b) {
+		t.Errorf("frames with the same seed differ: %x, %x", a, b)
+	}
+}
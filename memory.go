@@ -0,0 +1,163 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Kinds of accounted memory.
+const (
+	memReadBuffer = iota
+	memWriteBuffer
+	memCompression
+	memQueued
+	numMemKinds
+)
+
+// flateWriterMemory is the approximate memory allocated by a compressor for
+// each compression level from minCompressionLevel.
+var flateWriterMemory = [maxCompressionLevel - minCompressionLevel + 1]int64{
+	314000, 748000, 347000, 486000, 879000, 879000, 617000, 748000, 748000, 1010000, 1010000, 1010000,
+}
+
+// flateReaderMemory is the approximate memory allocated by a decompressor.
+const flateReaderMemory = 45000
+
+// MemoryUsage is the memory in bytes attributable to one connection or to all
+// connections.
+type MemoryUsage struct {
+	// ReadBuffer and WriteBuffer are the sizes of the I/O buffers. A write
+	// buffer taken from a BufferPool is counted while a message is written.
+	ReadBuffer  int64
+	WriteBuffer int64
+
+	// Compression is the approximate memory of the compressors and
+	// decompressors in use by the messages being written and read.
+	Compression int64
+
+	// Queued is the size of the messages waiting in a SendQueue.
+	Queued int64
+}
+
+// Total returns the sum of the memory usage.
+func (u MemoryUsage) Total() int64 {
+	return u.ReadBuffer + u.WriteBuffer + u.Compression + u.Queued
+}
+
+type memoryLimit struct {
+	limit    int64
+	exceeded func(MemoryUsage)
+	over     atomic.Bool
+}
+
+var (
+	memoryTotal [numMemKinds]atomic.Int64
+	memoryLim   atomic.Pointer[memoryLimit]
+)
+
+// Memory returns the memory attributable to all connections that are not
+// closed.
+func Memory() MemoryUsage {
+	return MemoryUsage{
+		ReadBuffer:  memoryTotal[memReadBuffer].Load(),
+		WriteBuffer: memoryTotal[memWriteBuffer].Load(),
+		Compression: memoryTotal[memCompression].Load(),
+		Queued:      memoryTotal[memQueued].Load(),
+	}
+}
+
+// SetMemoryLimit sets a limit on the memory attributable to all connections.
+// When the total memory usage exceeds the limit, exceeded is called in a new
+// goroutine with the usage. The application can then reject new connections
+// or close some of the existing connections. exceeded is called again after
+// the usage has dropped to the limit or below and exceeds it again.
+//
+// A limit less than or equal to zero removes the limit.
+func SetMemoryLimit(limit int64, exceeded func(MemoryUsage)) {
+	if limit <= 0 || exceeded == nil {
+		memoryLim.Store(nil)
+		return
+	}
+	memoryLim.Store(&memoryLimit{limit: limit, exceeded: exceeded})
+	checkMemoryLimit()
+}
+
+func checkMemoryLimit() {
+	l := memoryLim.Load()
+	if l == nil {
+		return
+	}
+	u := Memory()
+	if u.Total() <= l.limit {
+		l.over.Store(false)
+	} else if l.over.CompareAndSwap(false, true) {
+		go l.exceeded(u)
+	}
+}
+
+// memoryAccount is the memory attributable to a connection.
+type memoryAccount struct {
+	mu       sync.Mutex
+	usage    [numMemKinds]int64
+	released bool
+}
+
+// addMemory adds n bytes of the kind to the connection and to the total.
+func (c *Conn) addMemory(kind int, n int64) {
+	if n == 0 {
+		return
+	}
+	c.memory.mu.Lock()
+	if c.memory.released {
+		c.memory.mu.Unlock()
+		return
+	}
+	c.memory.usage[kind] += n
+	c.memory.mu.Unlock()
+	memoryTotal[kind].Add(n)
+	checkMemoryLimit()
+}
+
+// releaseMemory removes the memory of the connection from the total. The
+// memory of the connection is no longer accounted.
+func (c *Conn) releaseMemory() {
+	c.memory.mu.Lock()
+	if c.memory.released {
+		c.memory.mu.Unlock()
+		return
+	}
+	c.memory.released = true
+	usage := c.memory.usage
+	c.memory.usage = [numMemKinds]int64{}
+	c.memory.mu.Unlock()
+	for kind, n := range usage {
+		memoryTotal[kind].Add(-n)
+	}
+	checkMemoryLimit()
+}
+
+// Memory returns the memory attributable to the connection. The memory is
+// no longer accounted after Close.
+func (c *Conn) Memory() MemoryUsage {
+	c.memory.mu.Lock()
+	defer c.memory.mu.Unlock()
+	return MemoryUsage{
+		ReadBuffer:  c.memory.usage[memReadBuffer],
+		WriteBuffer: c.memory.usage[memWriteBuffer],
+		Compression: c.memory.usage[memCompression],
+		Queued:      c.memory.usage[memQueued],
+	}
+}
+
+// releaseReadCompression removes the decompressor of the current message
+// from the memory of the connection.
+func (c *Conn) releaseReadCompression() {
+	if c.readCompression {
+		c.readCompression = false
+		c.addMemory(memCompression, -flateReaderMemory)
+	}
+}
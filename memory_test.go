@@ -0,0 +1,153 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemory(t *testing.T) {
+	before := Memory().Total()
+	client, server := (&PipeConfig{ReadBufferSize: 2000, WriteBufferSize: 1000, EnableCompression: true}).Pipe()
+
+	want := MemoryUsage{ReadBuffer: 2000, WriteBuffer: 1000 + maxFrameHeaderSize}
+	if got := server.Memory(); got != want {
+		t.Errorf("Memory() = %+v, want %+v", got, want)
+	}
+	if got := Memory().Total() - before; got != 2*want.Total() {
+		t.Errorf("total changed by %d, want %d", got, 2*want.Total())
+	}
+
+	w, err := client.NextWriter(TextMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := client.Memory().Compression, flateWriterMemory[defaultCompressionLevel-minCompressionLevel]; got != want {
+		t.Errorf("Compression while writing = %d, want %d", got, want)
+	}
+	io.WriteString(w, "hello")
+	w.Close()
+	if got := client.Memory().Compression; got != 0 {
+		t.Errorf("Compression after writing = %d, want 0", got)
+	}
+
+	_, r, err := server.NextReader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := server.Memory().Compression; got != flateReaderMemory {
+		t.Errorf("Compression while reading = %d, want %d", got, flateReaderMemory)
+	}
+	io.ReadAll(r)
+	if got := server.Memory().Compression; got != 0 {
+		t.Errorf("Compression after reading = %d, want 0", got)
+	}
+
+	client.Close()
+	server.Close()
+	if got := server.Memory(); got != (MemoryUsage{}) {
+		t.Errorf("Memory() after Close = %+v, want zero", got)
+	}
+	if got := Memory().Total() - before; got != 0 {
+		t.Errorf("total changed by %d after Close, want 0", got)
+	}
+}
+
+func TestMemoryWriteBufferPool(t *testing.T) {
+	netConn, peer := net.Pipe()
+	defer peer.Close()
+	c := newConn(netConn, true, 1024, 1024, &sync.Pool{}, nil, nil)
+	defer c.Close()
+	if got := c.Memory().WriteBuffer; got != 0 {
+		t.Errorf("WriteBuffer before writing = %d, want 0", got)
+	}
+	w, err := c.NextWriter(TextMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := c.Memory().WriteBuffer, int64(1024+maxFrameHeaderSize); got != want {
+		t.Errorf("WriteBuffer while writing = %d, want %d", got, want)
+	}
+	go io.Copy(io.Discard, peer)
+	w.Close()
+	if got := c.Memory().WriteBuffer; got != 0 {
+		t.Errorf("WriteBuffer after writing = %d, want 0", got)
+	}
+}
+
+func TestMemoryQueued(t *testing.T) {
+	netConn, peer := net.Pipe()
+	defer peer.Close()
+	c := newConn(netConn, true, 1024, 1024, nil, nil, nil)
+	defer c.Close()
+
+	// The first message blocks the writer until the peer reads.
+	q := NewSendQueue(c, nil)
+	for _, m := range []string{"first", "second", "third"} {
+		if err := q.Send(PriorityBulk, TextMessage, []byte(m)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := c.Memory().Queued; got == 0 || got > int64(len("firstsecondthird")) {
+		t.Errorf("Queued = %d, want between 1 and %d", got, len("firstsecondthird"))
+	}
+	go io.Copy(io.Discard, peer)
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Memory().Queued; got != 0 {
+		t.Errorf("Queued after Close = %d, want 0", got)
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	exceeded := make(chan MemoryUsage, 1)
+	SetMemoryLimit(Memory().Total()+1000, func(u MemoryUsage) { exceeded <- u })
+	defer SetMemoryLimit(0, nil)
+
+	client, server := Pipe()
+	select {
+	case u := <-exceeded:
+		if u.ReadBuffer == 0 {
+			t.Errorf("exceeded called with %+v", u)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("exceeded not called")
+	}
+
+	client.Close()
+	server.Close()
+	client, server = Pipe()
+	defer client.Close()
+	defer server.Close()
+	select {
+	case <-exceeded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("exceeded not called after usage dropped below the limit")
+	}
+}
+
+func TestMemoryFailedDial(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer s.Close()
+
+	before := Memory().Total()
+	for i := 0; i < 10; i++ {
+		if _, _, err := DefaultDialer.Dial(makeWsProto(s.URL), nil); err != ErrBadHandshake {
+			t.Fatalf("Dial() error = %v, want %v", err, ErrBadHandshake)
+		}
+	}
+	if got := Memory().Total() - before; got != 0 {
+		t.Errorf("Memory().Total() changed by %d after failed dials, want 0", got)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import "errors"
+
+// errMessageDropped is returned internally when middleware drops a message.
+var errMessageDropped = errors.New("websocket: message dropped by middleware")
+
+// MessageHandler handles the payload of a data message.
+type MessageHandler func(messageType int, p []byte) error
+
+// MessageMiddleware wraps a MessageHandler with a concern such as metrics,
+// validation, audit logging or an application level payload encoding. The
+// middleware calls next to pass the message on, possibly with a modified
+// payload, or returns without calling next to drop the message:
+//
+//	func audit(next websocket.MessageHandler) websocket.MessageHandler {
+//		return func(messageType int, p []byte) error {
+//			log.Printf("message of %d bytes", len(p))
+//			return next(messageType, p)
+//		}
+//	}
+type MessageMiddleware func(next MessageHandler) MessageHandler
+
+// UseInbound appends middleware to the chain of the data messages read from
+// the connection. The first middleware sees a message first. Middleware sees
+// whole messages as described for PayloadTransform, after the payload
+// transform.
+//
+// A message that the middleware drops is skipped and the read methods
+// return the next message. An error fails the connection: the connection
+// sends a close message and the read methods return the error. The close
+// code is CloseInternalServerErr unless the error is or wraps a
+// *CloseError, whose code and text are sent, for example
+// CloseUnsupportedData for a message that fails validation.
+//
+// Call UseInbound before reading from the connection.
+func (c *Conn) UseInbound(m ...MessageMiddleware) {
+	c.inbound = append(c.inbound, m...)
+}
+
+// UseOutbound appends middleware to the chain of the data messages written
+// to the connection. The first middleware sees a message first. Middleware
+// sees whole messages as described for PayloadTransform, before the payload
+// transform. A message that the middleware drops is not written and the
+// write succeeds. An error is returned by the write method. Prepared
+// messages cannot be written while outbound middleware is set.
+//
+// Call UseOutbound before writing to the connection.
+func (c *Conn) UseOutbound(m ...MessageMiddleware) {
+	c.outbound = append(c.outbound, m...)
+}
+
+// runMiddleware passes a message through the chain and returns the
+// message passed to the end of the chain. ok is false if the message was
+// dropped.
+func runMiddleware(chain []MessageMiddleware, messageType int, p []byte) (q []byte, ok bool, err error) {
+	var h MessageHandler = func(messageType int, p []byte) error {
+		q, ok = p, true
+		return nil
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		h = chain[i](h)
+	}
+	if err := h(messageType, p); err != nil {
+		return nil, false, err
+	}
+	return q, ok, nil
+}
+
+// transformsInbound reports whether the payloads of the data messages read
+// from the connection are transformed.
+func (c *Conn) transformsInbound() bool {
+	return c.transform != nil || len(c.inbound) > 0
+}
+
+// transformsOutbound reports whether the payloads of the data messages
+// written to the connection are transformed.
+func (c *Conn) transformsOutbound() bool {
+	return c.transform != nil || len(c.outbound) > 0
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// tag returns middleware that appends s to the payload.
+func tag(s string) MessageMiddleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(messageType int, p []byte) error {
+			return next(messageType, append(p[:len(p):len(p)], s...))
+		}
+	}
+}
+
+// dropPrefix returns middleware that drops the messages with the prefix.
+func dropPrefix(prefix string) MessageMiddleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(messageType int, p []byte) error {
+			if bytes.HasPrefix(p, []byte(prefix)) {
+				return nil
+			}
+			return next(messageType, p)
+		}
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	client.UseOutbound(tag(" out1"), tag(" out2"))
+	server.UseInbound(tag(" in1"), tag(" in2"))
+
+	if err := client.WriteMessage(TextMessage, []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	w, err := client.NextWriter(TextMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(w, "b")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"a out1 out2 in1 in2", "b out1 out2 in1 in2"} {
+		if _, p, err := server.ReadMessage(); err != nil || string(p) != want {
+			t.Errorf("ReadMessage() = %q, %v, want %q", p, err, want)
+		}
+	}
+}
+
+func TestMiddlewareTransformOrder(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	client.SetPayloadTransform(newAEADTransform(t))
+	server.SetPayloadTransform(newAEADTransform(t))
+	client.UseOutbound(tag("!"))
+
+	// The server middleware sees the payload after the transform.
+	var seen string
+	server.UseInbound(func(next MessageHandler) MessageHandler {
+		return func(messageType int, p []byte) error {
+			seen = string(p)
+			return next(messageType, p)
+		}
+	})
+	client.WriteMessage(TextMessage, []byte("hi"))
+	if _, p, err := server.ReadMessage(); err != nil || string(p) != "hi!" || seen != "hi!" {
+		t.Errorf("ReadMessage() = %q, %v, middleware saw %q, want hi!", p, err, seen)
+	}
+}
+
+func TestMiddlewareDrop(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	client.UseOutbound(dropPrefix("secret"))
+	server.UseInbound(dropPrefix("noise"))
+
+	for _, m := range []string{"secret 1", "noise 1", "message", "noise 2"} {
+		if err := client.WriteMessage(TextMessage, []byte(m)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client.WriteMessage(TextMessage, []byte("last"))
+	for _, want := range []string{"message", "last"} {
+		if _, p, err := server.ReadMessage(); err != nil || string(p) != want {
+			t.Errorf("ReadMessage() = %q, %v, want %q", p, err, want)
+		}
+	}
+	if err := client.WritePreparedMessage(&PreparedMessage{messageType: TextMessage}); err != errPreparedTransform {
+		t.Errorf("WritePreparedMessage() error = %v, want %v", err, errPreparedTransform)
+	}
+}
+
+func TestMiddlewareError(t *testing.T) {
+	errInvalid := errors.New("invalid message")
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"error", errInvalid, CloseInternalServerErr},
+		{"close error", &CloseError{Code: CloseUnsupportedData, Text: "invalid"}, CloseUnsupportedData},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := Pipe()
+			defer client.Close()
+			defer server.Close()
+			server.UseInbound(func(next MessageHandler) MessageHandler {
+				return func(messageType int, p []byte) error {
+					if strings.HasPrefix(string(p), "bad") {
+						return tt.err
+					}
+					return next(messageType, p)
+				}
+			})
+			client.WriteMessage(TextMessage, []byte("bad"))
+			if _, _, err := server.ReadMessage(); !errors.Is(err, tt.err) {
+				t.Errorf("ReadMessage() error = %v, want %v", err, tt.err)
+			}
+			if _, _, err := client.ReadMessage(); !IsCloseError(err, tt.code) {
+				t.Errorf("client ReadMessage() error = %v, want close %d", err, tt.code)
+			}
+		})
+	}
+
+	// Outbound errors are returned by the write methods.
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	client.UseOutbound(func(next MessageHandler) MessageHandler {
+		return func(messageType int, p []byte) error { return errInvalid }
+	})
+	if err := client.WriteMessage(TextMessage, []byte("x")); err != errInvalid {
+		t.Errorf("WriteMessage() error = %v, want %v", err, errInvalid)
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// errPeerClosed records that the peer closed the channel.
+var errPeerClosed = errors.New("mux: channel closed by peer")
+
+type message struct {
+	messageType int
+	p           []byte
+}
+
+// Channel is a logical channel in a session.
+//
+// Channel supports one concurrent reader and multiple concurrent writers.
+type Channel struct {
+	sess *Session
+	key  channelKey
+	name string
+
+	writeMu sync.Mutex // keeps the fragments of a message together
+
+	mu         sync.Mutex
+	cond       sync.Cond
+	inbox      []message
+	partial    *message // message with more fragments to receive
+	buffered   int
+	consumed   int
+	sendWindow int
+	err        error
+}
+
+func newChannel(s *Session, key channelKey, name string) *Channel {
+	ch := &Channel{sess: s, key: key, name: name, sendWindow: s.window}
+	ch.cond.L = &ch.mu
+	return ch
+}
+
+// Name returns the name passed to Open.
+func (ch *Channel) Name() string {
+	return ch.name
+}
+
+// Session returns the session that carries the channel.
+func (ch *Channel) Session() *Session {
+	return ch.sess
+}
+
+// ReadMessage returns the next message on the channel. ReadMessage returns
+// io.EOF after the peer closes the channel and all messages are read.
+func (ch *Channel) ReadMessage() (messageType int, p []byte, err error) {
+	ch.mu.Lock()
+	for len(ch.inbox) == 0 && ch.err == nil {
+		ch.cond.Wait()
+	}
+	if len(ch.inbox) == 0 {
+		err := ch.err
+		ch.mu.Unlock()
+		if err == errPeerClosed {
+			err = io.EOF
+		}
+		return 0, nil, err
+	}
+	m := ch.inbox[0]
+	ch.inbox[0] = message{}
+	ch.inbox = ch.inbox[1:]
+	ch.buffered -= len(m.p)
+	ch.consumed += len(m.p)
+	var update int
+	if ch.consumed >= ch.sess.window/4 && ch.err == nil {
+		update = ch.consumed
+		ch.consumed = 0
+	}
+	ch.mu.Unlock()
+
+	if update > 0 {
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], uint32(update))
+		if err := ch.sess.writeFrame(frameWindow, ch.key, b[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+	return m.messageType, m.p, nil
+}
+
+// WriteMessage writes a message to the channel. The messageType is
+// websocket.TextMessage or websocket.BinaryMessage. WriteMessage blocks while
+// the peer's window for the channel is exhausted. Messages larger than the
+// fragment size of the session are written in fragments that take turns
+// with the messages of other channels. Messages larger than the maximum
+// message size of the session are not sent.
+func (ch *Channel) WriteMessage(messageType int, data []byte) error {
+	var typ byte
+	switch messageType {
+	case websocket.TextMessage:
+		typ = frameText
+	case websocket.BinaryMessage:
+		typ = frameBinary
+	default:
+		return errors.New("mux: unsupported message type")
+	}
+	if len(data) > ch.sess.maxMessage {
+		return ErrMessageTooLarge
+	}
+
+	ch.writeMu.Lock()
+	defer ch.writeMu.Unlock()
+
+	ch.mu.Lock()
+	for ch.sendWindow <= 0 && ch.err == nil {
+		ch.cond.Wait()
+	}
+	if err := ch.err; err != nil {
+		ch.mu.Unlock()
+		if err == errPeerClosed {
+			err = ErrClosed
+		}
+		return err
+	}
+	ch.sendWindow -= len(data)
+	ch.mu.Unlock()
+
+	size := ch.sess.fragmentSize
+	for {
+		n := len(data)
+		if size > 0 && n > size {
+			n = size
+		}
+		t := typ
+		if n < len(data) {
+			t |= frameMore
+		}
+		if err := ch.sess.writeFrame(t, ch.key, data[:n]); err != nil {
+			return err
+		}
+		data = data[n:]
+		if len(data) == 0 {
+			return nil
+		}
+
+		ch.mu.Lock()
+		err := ch.err
+		ch.mu.Unlock()
+		if err != nil {
+			// The peer ignores the fragments of a closed channel.
+			if err == errPeerClosed {
+				err = ErrClosed
+			}
+			return err
+		}
+	}
+}
+
+// Close closes the channel. Messages that are not read are discarded.
+func (ch *Channel) Close() error {
+	ch.mu.Lock()
+	if ch.err != nil {
+		ch.mu.Unlock()
+		return nil
+	}
+	ch.err = ErrClosed
+	ch.inbox = nil
+	ch.partial = nil
+	ch.cond.Broadcast()
+	ch.mu.Unlock()
+	ch.sess.remove(ch)
+	return ch.sess.writeFrame(frameClose, ch.key, nil)
+}
+
+// receive queues a message or a fragment of a message from the peer. The
+// argument more is set on all fragments except the last.
+func (ch *Channel) receive(messageType int, p []byte, more bool) error {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if ch.err != nil {
+		return nil
+	}
+	if ch.partial != nil {
+		if ch.partial.messageType != messageType {
+			return errProtocol
+		}
+		if len(ch.partial.p)+len(p) > ch.sess.maxMessage {
+			// The peer sent fragments beyond the maximum message size.
+			return errProtocol
+		}
+		p = append(ch.partial.p, p...)
+		ch.partial = nil
+	} else if len(p) > ch.sess.maxMessage {
+		return errProtocol
+	} else if ch.buffered >= ch.sess.window {
+		// The peer sent more than the window allows.
+		return errProtocol
+	}
+	if more {
+		ch.partial = &message{messageType, p}
+		return nil
+	}
+	ch.inbox = append(ch.inbox, message{messageType, p})
+	ch.buffered += len(p)
+	ch.cond.Broadcast()
+	return nil
+}
+
+func (ch *Channel) addWindow(n int) {
+	ch.mu.Lock()
+	ch.sendWindow += n
+	ch.cond.Broadcast()
+	ch.mu.Unlock()
+}
+
+// fail ends the channel with err. Queued messages can still be read.
+func (ch *Channel) fail(err error) {
+	ch.mu.Lock()
+	if ch.err == nil {
+		ch.err = err
+		ch.cond.Broadcast()
+	}
+	ch.mu.Unlock()
+}
@@ -0,0 +1,255 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestFlowControl(t *testing.T) {
+	const window = 1024
+	client, server := newTestSessions(t, &Config{Window: window}, &Config{Window: window})
+	c, _ := client.Open("slow")
+	s, _ := server.Accept()
+
+	// Fill the window. The next write blocks until the reader consumes data.
+	for sent := 0; sent < window; sent += 256 {
+		if err := c.WriteMessage(websocket.BinaryMessage, make([]byte, 256)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	written := make(chan error)
+	go func() {
+		written <- c.WriteMessage(websocket.BinaryMessage, []byte("more"))
+	}()
+	select {
+	case <-written:
+		t.Fatal("write did not block on exhausted window")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Other channels are not affected.
+	c2, _ := client.Open("fast")
+	s2, _ := server.Accept()
+	c2.WriteMessage(websocket.TextMessage, []byte("hi"))
+	if _, p, err := s2.ReadMessage(); err != nil || string(p) != "hi" {
+		t.Fatalf("ReadMessage() = %q, %v", p, err)
+	}
+
+	for i := 0; i < 4; i++ {
+		if _, p, err := s.ReadMessage(); err != nil || len(p) != 256 {
+			t.Fatalf("ReadMessage() = %d bytes, %v", len(p), err)
+		}
+	}
+	select {
+	case err := <-written:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("write did not resume after reads")
+	}
+	if _, p, err := s.ReadMessage(); err != nil || string(p) != "more" {
+		t.Errorf("ReadMessage() = %q, %v", p, err)
+	}
+}
+
+func TestChannelClose(t *testing.T) {
+	client, server := newTestSessions(t, nil, nil)
+	c, _ := client.Open("x")
+	s, _ := server.Accept()
+
+	c.WriteMessage(websocket.TextMessage, []byte("last"))
+	c.Close()
+
+	// Messages sent before close are delivered, then io.EOF.
+	if _, p, err := s.ReadMessage(); err != nil || string(p) != "last" {
+		t.Fatalf("ReadMessage() = %q, %v", p, err)
+	}
+	if _, _, err := s.ReadMessage(); err != io.EOF {
+		t.Errorf("ReadMessage() error = %v, want %v", err, io.EOF)
+	}
+	if err := s.WriteMessage(websocket.TextMessage, []byte("x")); err != ErrClosed {
+		t.Errorf("WriteMessage() on peer closed channel error = %v, want %v", err, ErrClosed)
+	}
+	if _, _, err := c.ReadMessage(); err != ErrClosed {
+		t.Errorf("ReadMessage() on closed channel error = %v, want %v", err, ErrClosed)
+	}
+	if err := c.WriteMessage(websocket.TextMessage, []byte("x")); err != ErrClosed {
+		t.Errorf("WriteMessage() on closed channel error = %v, want %v", err, ErrClosed)
+	}
+
+	// The session remains usable.
+	c2, _ := client.Open("y")
+	s2, _ := server.Accept()
+	c2.WriteMessage(websocket.TextMessage, []byte("ok"))
+	if _, p, err := s2.ReadMessage(); err != nil || string(p) != "ok" {
+		t.Errorf("ReadMessage() = %q, %v", p, err)
+	}
+}
+
+func TestUnsupportedMessageType(t *testing.T) {
+	client, _ := newTestSessions(t, nil, nil)
+	c, _ := client.Open("x")
+	if err := c.WriteMessage(websocket.PingMessage, nil); err == nil {
+		t.Error("WriteMessage(PingMessage) succeeded")
+	}
+}
+
+func TestFragments(t *testing.T) {
+	tests := []struct {
+		name         string
+		fragmentSize int
+		size         int
+	}{
+		{"small message", 1024, 100},
+		{"exact fragment", 1024, 1024},
+		{"several fragments", 1024, 10*1024 + 1},
+		{"larger than window", 1024, 4 * defaultWindow},
+		{"not fragmented", -1, 100 * 1024},
+	}
+	for _, tt := range tests {
+		config := &Config{FragmentSize: tt.fragmentSize}
+		client, server := newTestSessions(t, config, config)
+		c, _ := client.Open("bulk")
+		s, _ := server.Accept()
+		data := bytes.Repeat([]byte("0123456789"), tt.size/10+1)[:tt.size]
+		go c.WriteMessage(websocket.BinaryMessage, data)
+		mt, p, err := s.ReadMessage()
+		if err != nil || mt != websocket.BinaryMessage || !bytes.Equal(p, data) {
+			t.Errorf("%s: ReadMessage() = %d, %d bytes, %v, want %d bytes", tt.name, mt, len(p), err, len(data))
+		}
+	}
+}
+
+func TestMaxMessageSize(t *testing.T) {
+	config := &Config{FragmentSize: 1024, MaxMessageSize: 4096}
+	client, server := newTestSessions(t, config, config)
+	c, _ := client.Open("bulk")
+	if err := c.WriteMessage(websocket.BinaryMessage, make([]byte, 4097)); err != ErrMessageTooLarge {
+		t.Errorf("WriteMessage() error = %v, want %v", err, ErrMessageTooLarge)
+	}
+
+	// A peer that never sends the last fragment fails the session.
+	fragment := make([]byte, 1024)
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		if err := client.writeFrame(frameBinary|frameMore, c.key, fragment); err != nil {
+			break
+		}
+		select {
+		case <-server.Done():
+		default:
+			if time.Now().Before(deadline) {
+				continue
+			}
+			t.Fatal("session did not end")
+		}
+		break
+	}
+	<-server.Done()
+	if server.Err() != errProtocol {
+		t.Errorf("Err() = %v, want %v", server.Err(), errProtocol)
+	}
+}
+
+// waitTurns waits until n writers wait for the turn lock.
+func waitTurns(t *testing.T, l *turnLock, n int) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		l.mu.Lock()
+		waiting := len(l.waiters)
+		l.mu.Unlock()
+		if waiting == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d writers waiting, want %d", waiting, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestFragmentsInterleaved(t *testing.T) {
+	config := &Config{FragmentSize: 1024}
+	client, server := newTestSessions(t, config, config)
+	bulk, _ := client.Open("bulk")
+	sbulk, _ := server.Accept()
+	small, _ := client.Open("small")
+	ssmall, _ := server.Accept()
+
+	// Queue the writers in the order: large message, small message, and a
+	// turn that holds the large message after its first fragment.
+	big := make([]byte, 16*1024)
+	client.writeTurn.Lock()
+	bulkWritten := make(chan error, 1)
+	go func() { bulkWritten <- bulk.WriteMessage(websocket.BinaryMessage, big) }()
+	waitTurns(t, &client.writeTurn, 1)
+	smallWritten := make(chan error, 1)
+	go func() { smallWritten <- small.WriteMessage(websocket.TextMessage, []byte("hi")) }()
+	waitTurns(t, &client.writeTurn, 2)
+	hold := make(chan struct{})
+	go func() {
+		client.writeTurn.Lock()
+		<-hold
+		client.writeTurn.Unlock()
+	}()
+	waitTurns(t, &client.writeTurn, 3)
+	client.writeTurn.Unlock()
+
+	if err := <-smallWritten; err != nil {
+		t.Fatal(err)
+	}
+	if _, p, err := ssmall.ReadMessage(); err != nil || string(p) != "hi" {
+		t.Fatalf("ReadMessage() = %q, %v", p, err)
+	}
+	sbulk.mu.Lock()
+	partial, queued := sbulk.partial != nil, len(sbulk.inbox)
+	sbulk.mu.Unlock()
+	if !partial || queued != 0 {
+		t.Errorf("large message partial, queued = %v, %d, want true, 0", partial, queued)
+	}
+
+	close(hold)
+	if _, p, err := sbulk.ReadMessage(); err != nil || len(p) != len(big) {
+		t.Fatalf("ReadMessage() = %d bytes, %v, want %d bytes", len(p), err, len(big))
+	}
+	if err := <-bulkWritten; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTurnLock(t *testing.T) {
+	var l turnLock
+	var order []int
+	var mu sync.Mutex
+	l.Lock()
+	done := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		i := i
+		go func() {
+			l.Lock()
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			l.Unlock()
+			done <- struct{}{}
+		}()
+		// Wait until the goroutine waits for its turn.
+		waitTurns(t, &l, i+1)
+	}
+	l.Unlock()
+	for i := 0; i < 3; i++ {
+		<-done
+	}
+	if len(order) != 3 || order[0] != 0 || order[1] != 1 || order[2] != 2 {
+		t.Errorf("lock order = %v, want [0 1 2]", order)
+	}
+}
@@ -0,0 +1,382 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mux multiplexes logical channels over a single WebSocket
+// connection.
+//
+// Either end of a Session can open channels. Each channel carries text and
+// binary messages independently of the other channels and has its own flow
+// control window, so a slow reader on one channel does not stall the others.
+//
+//	sess := mux.NewSession(ws, nil)
+//	ch, err := sess.Open("events")
+//	...
+//	err = ch.WriteMessage(websocket.TextMessage, p)
+//
+// The peer accepts the channel:
+//
+//	ch, err := sess.Accept()
+//	...
+//	messageType, p, err := ch.ReadMessage()
+//
+// Frames are sent in binary websocket messages. Each frame starts with a
+// type byte and a 32 bit big-endian channel ID. The high bit of the type byte
+// is set when the sender opened the channel, so each end allocates channel
+// IDs independently.
+//
+// Messages larger than Config.FragmentSize are sent in fragments. The
+// second highest bit of the type byte is set on all fragments of a message
+// except the last. The session writes the fragments of concurrent messages
+// in turns, so a large message on one channel does not delay the messages
+// on the other channels until it is sent.
+package mux
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Frame types.
+const (
+	frameOpen   = 1
+	frameText   = 2
+	frameBinary = 3
+	frameClose  = 4
+	frameWindow = 5
+
+	frameOpener = 0x80
+	frameMore   = 0x40
+
+	headerSize = 5
+)
+
+const (
+	defaultWindow        = 256 * 1024
+	defaultAcceptBacklog = 16
+	defaultFragmentSize  = 32 * 1024
+	defaultMaxMessage    = 16 * 1024 * 1024
+)
+
+var (
+	// ErrClosed is returned when using a closed channel or session.
+	ErrClosed = errors.New("mux: closed")
+
+	// ErrMessageTooLarge is returned by Channel.WriteMessage for a message
+	// larger than Config.MaxMessageSize.
+	ErrMessageTooLarge = errors.New("mux: message too large")
+
+	errProtocol = errors.New("mux: protocol error")
+)
+
+// Config specifies options for a session. The zero value is a valid
+// configuration.
+type Config struct {
+	// Window is the number of message bytes the peer may send on a channel
+	// before the application reads them. If zero, a default of 256 KiB is
+	// used. Both ends should use the same value.
+	Window int
+
+	// AcceptBacklog is the number of opened channels waiting for Accept.
+	// Channels opened by the peer when the backlog is full are closed. If
+	// zero, a default of 16 is used.
+	AcceptBacklog int
+
+	// FragmentSize is the largest message payload in a frame. Larger
+	// messages are sent in fragments that are interleaved with the frames
+	// of other channels. If zero, a default of 32 KiB is used. If negative,
+	// messages are not fragmented, for peers that do not support
+	// fragments.
+	FragmentSize int
+
+	// MaxMessageSize is the largest message the peer may send on a channel
+	// and the largest message WriteMessage sends. The session fails with a
+	// protocol error when the fragments of a message from the peer exceed
+	// the size. If zero, a default of 16 MiB is used. Both ends should use
+	// the same value.
+	MaxMessageSize int
+}
+
+type channelKey struct {
+	id    uint32
+	local bool // opened by this end
+}
+
+// Session is a multiplexed websocket connection.
+//
+// The Session owns the read and write methods of the websocket connection.
+// Applications must not read or write the websocket connection directly.
+//
+// It is safe to call Session's methods concurrently.
+type Session struct {
+	ws           *websocket.Conn
+	window       int
+	fragmentSize int
+	maxMessage   int
+
+	writeTurn turnLock
+
+	mu       sync.Mutex
+	channels map[channelKey]*Channel
+	nextID   uint32
+	err      error
+
+	accept chan *Channel
+	done   chan struct{}
+}
+
+// NewSession returns a session on ws and starts reading from the
+// connection.
+func NewSession(ws *websocket.Conn, config *Config) *Session {
+	if config == nil {
+		config = &Config{}
+	}
+	s := &Session{
+		ws:       ws,
+		window:   config.Window,
+		channels: make(map[channelKey]*Channel),
+		done:     make(chan struct{}),
+	}
+	if s.window <= 0 {
+		s.window = defaultWindow
+	}
+	s.fragmentSize = config.FragmentSize
+	if s.fragmentSize == 0 {
+		s.fragmentSize = defaultFragmentSize
+	}
+	s.maxMessage = config.MaxMessageSize
+	if s.maxMessage <= 0 {
+		s.maxMessage = defaultMaxMessage
+	}
+	backlog := config.AcceptBacklog
+	if backlog <= 0 {
+		backlog = defaultAcceptBacklog
+	}
+	s.accept = make(chan *Channel, backlog)
+	ws.Go(s.readLoop)
+	return s
+}
+
+// WebSocket returns the underlying websocket connection.
+func (s *Session) WebSocket() *websocket.Conn {
+	return s.ws
+}
+
+// Open opens a channel with the given name. The name is passed to the peer
+// and is not interpreted by the package. Open does not wait for the peer to
+// accept the channel.
+func (s *Session) Open(name string) (*Channel, error) {
+	s.mu.Lock()
+	if s.err != nil {
+		err := s.err
+		s.mu.Unlock()
+		return nil, err
+	}
+	s.nextID++
+	key := channelKey{id: s.nextID, local: true}
+	ch := newChannel(s, key, name)
+	s.channels[key] = ch
+	s.mu.Unlock()
+
+	if err := s.writeFrame(frameOpen, key, []byte(name)); err != nil {
+		s.remove(ch)
+		return nil, err
+	}
+	return ch, nil
+}
+
+// Accept waits for and returns the next channel opened by the peer.
+func (s *Session) Accept() (*Channel, error) {
+	select {
+	case ch := <-s.accept:
+		return ch, nil
+	case <-s.done:
+		// Return channels that arrived before the session ended.
+		select {
+		case ch := <-s.accept:
+			return ch, nil
+		default:
+		}
+		return nil, s.Err()
+	}
+}
+
+// Close closes the session and all of its channels.
+func (s *Session) Close() error {
+	s.shutdown(ErrClosed)
+	return s.ws.Close()
+}
+
+// Done returns a channel that is closed when the session ends.
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the error that ended the session or nil if the session is
+// active.
+func (s *Session) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *Session) shutdown(err error) {
+	s.mu.Lock()
+	if s.err != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.err = err
+	channels := s.channels
+	s.channels = nil
+	s.mu.Unlock()
+	close(s.done)
+	for _, ch := range channels {
+		ch.fail(err)
+	}
+}
+
+func (s *Session) remove(ch *Channel) {
+	s.mu.Lock()
+	if s.channels[ch.key] == ch {
+		delete(s.channels, ch.key)
+	}
+	s.mu.Unlock()
+}
+
+func (s *Session) writeFrame(typ byte, key channelKey, payload []byte) error {
+	var hdr [headerSize]byte
+	hdr[0] = typ
+	if key.local {
+		hdr[0] |= frameOpener
+	}
+	binary.BigEndian.PutUint32(hdr[1:], key.id)
+
+	s.writeTurn.Lock()
+	defer s.writeTurn.Unlock()
+	w, err := s.ws.NextWriter(websocket.BinaryMessage)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(hdr[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(payload); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func (s *Session) readLoop() {
+	var err error
+	for err == nil {
+		var p []byte
+		_, p, err = s.ws.ReadMessage()
+		if err == nil {
+			err = s.handleFrame(p)
+		}
+	}
+	s.shutdown(err)
+	s.ws.Close()
+}
+
+func (s *Session) handleFrame(p []byte) error {
+	if len(p) < headerSize {
+		return errProtocol
+	}
+	typ := p[0] &^ (frameOpener | frameMore)
+	more := p[0]&frameMore != 0
+	// The opener bit is from the sender's point of view.
+	key := channelKey{id: binary.BigEndian.Uint32(p[1:]), local: p[0]&frameOpener == 0}
+	payload := p[headerSize:]
+	if typ < frameOpen || typ > frameWindow {
+		return errProtocol
+	}
+	if more && typ != frameText && typ != frameBinary {
+		return errProtocol
+	}
+
+	s.mu.Lock()
+	if s.channels == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	ch := s.channels[key]
+	if typ == frameOpen {
+		if ch != nil || key.local {
+			s.mu.Unlock()
+			return errProtocol
+		}
+		ch = newChannel(s, key, string(payload))
+		s.channels[key] = ch
+		s.mu.Unlock()
+		select {
+		case s.accept <- ch:
+		default:
+			// The backlog is full.
+			ch.Close()
+		}
+		return nil
+	}
+	s.mu.Unlock()
+
+	if ch == nil {
+		// The channel was closed locally. Discard frames in flight.
+		return nil
+	}
+	switch typ {
+	case frameText, frameBinary:
+		messageType := websocket.TextMessage
+		if typ == frameBinary {
+			messageType = websocket.BinaryMessage
+		}
+		return ch.receive(messageType, payload, more)
+	case frameWindow:
+		if len(payload) != 4 {
+			return errProtocol
+		}
+		ch.addWindow(int(binary.BigEndian.Uint32(payload)))
+	case frameClose:
+		s.remove(ch)
+		ch.fail(errPeerClosed)
+	}
+	return nil
+}
+
+// turnLock is a mutex that is granted in the order of the Lock calls. A
+// writer that locks it again for the next fragment of a message waits for
+// the writers that are already waiting.
+type turnLock struct {
+	mu      sync.Mutex
+	locked  bool
+	waiters []chan struct{}
+}
+
+func (l *turnLock) Lock() {
+	l.mu.Lock()
+	if !l.locked {
+		l.locked = true
+		l.mu.Unlock()
+		return
+	}
+	turn := make(chan struct{})
+	l.waiters = append(l.waiters, turn)
+	l.mu.Unlock()
+	<-turn
+}
+
+func (l *turnLock) Unlock() {
+	l.mu.Lock()
+	if len(l.waiters) > 0 {
+		// Hand the lock to the next waiter.
+		close(l.waiters[0])
+		l.waiters[0] = nil
+		l.waiters = l.waiters[1:]
+	} else {
+		l.locked = false
+	}
+	l.mu.Unlock()
+}
@@ -0,0 +1,213 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mux
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newTestSessions returns connected client and server sessions.
+func newTestSessions(t *testing.T, clientConfig, serverConfig *Config) (client, server *Session) {
+	sc := make(chan *Session, 1)
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		sc <- NewSession(ws, serverConfig)
+	}))
+	t.Cleanup(hs.Close)
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(hs.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = NewSession(ws, clientConfig)
+	server = <-sc
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return client, server
+}
+
+func TestOpenAccept(t *testing.T) {
+	client, server := newTestSessions(t, nil, nil)
+
+	// Both ends open channels; IDs are allocated independently.
+	c1, err := client.Open("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s1, err := server.Open("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := server.Accept()
+	if err != nil || a.Name() != "a" {
+		t.Fatalf("server Accept() = %v, %v", a, err)
+	}
+	b, err := client.Accept()
+	if err != nil || b.Name() != "b" {
+		t.Fatalf("client Accept() = %v, %v", b, err)
+	}
+
+	tests := []struct {
+		w, r *Channel
+		mt   int
+		data string
+	}{
+		{c1, a, websocket.TextMessage, "client to server"},
+		{a, c1, websocket.BinaryMessage, "server to client"},
+		{s1, b, websocket.TextMessage, "on the server's channel"},
+		{b, s1, websocket.TextMessage, ""},
+	}
+	for _, tt := range tests {
+		if err := tt.w.WriteMessage(tt.mt, []byte(tt.data)); err != nil {
+			t.Fatal(err)
+		}
+		mt, p, err := tt.r.ReadMessage()
+		if err != nil || mt != tt.mt || string(p) != tt.data {
+			t.Errorf("ReadMessage() = %d, %q, %v, want %d, %q", mt, p, err, tt.mt, tt.data)
+		}
+	}
+}
+
+func TestManyChannels(t *testing.T) {
+	const n = 20
+	client, server := newTestSessions(t, nil, &Config{AcceptBacklog: n})
+
+	go func() {
+		for {
+			ch, err := server.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				for {
+					mt, p, err := ch.ReadMessage()
+					if err != nil {
+						ch.Close()
+						return
+					}
+					ch.WriteMessage(mt, p)
+				}
+			}()
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ch, err := client.Open(fmt.Sprint(i))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer ch.Close()
+			for j := 0; j < 10; j++ {
+				want := fmt.Sprintf("%d-%d", i, j)
+				if err := ch.WriteMessage(websocket.TextMessage, []byte(want)); err != nil {
+					t.Error(err)
+					return
+				}
+				_, p, err := ch.ReadMessage()
+				if err != nil || string(p) != want {
+					t.Errorf("ReadMessage() = %q, %v, want %q", p, err, want)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestAcceptBacklog(t *testing.T) {
+	client, server := newTestSessions(t, nil, &Config{AcceptBacklog: 1})
+	c1, _ := client.Open("1")
+	c2, _ := client.Open("2")
+
+	// The second channel is rejected because nobody accepted the first.
+	if _, _, err := c2.ReadMessage(); err == nil {
+		t.Fatal("ReadMessage() on rejected channel succeeded")
+	}
+	ch, err := server.Accept()
+	if err != nil || ch.Name() != "1" {
+		t.Fatalf("Accept() = %v, %v", ch, err)
+	}
+	c1.WriteMessage(websocket.TextMessage, []byte("ok"))
+	if _, p, err := ch.ReadMessage(); err != nil || string(p) != "ok" {
+		t.Errorf("ReadMessage() = %q, %v", p, err)
+	}
+}
+
+func TestSessionClose(t *testing.T) {
+	client, server := newTestSessions(t, nil, nil)
+	c, _ := client.Open("x")
+	s, _ := server.Accept()
+
+	done := make(chan error)
+	go func() {
+		_, _, err := s.ReadMessage()
+		done <- err
+	}()
+	client.Close()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("ReadMessage() after session close succeeded")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ReadMessage() did not return")
+	}
+	if err := c.WriteMessage(websocket.TextMessage, nil); err != ErrClosed {
+		t.Errorf("WriteMessage() error = %v, want %v", err, ErrClosed)
+	}
+	if _, err := client.Open("y"); err != ErrClosed {
+		t.Errorf("Open() error = %v, want %v", err, ErrClosed)
+	}
+	<-server.Done()
+	if _, err := server.Accept(); err == nil {
+		t.Error("Accept() on closed session succeeded")
+	}
+}
+
+func TestProtocolErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame []byte
+	}{
+		{"short", []byte{frameText, 0, 0}},
+		{"unknown type", []byte{0x7f, 0, 0, 0, 1}},
+		{"open local ID", []byte{frameOpen, 0, 0, 0, 1}},
+		{"fragmented window", []byte{frameWindow | frameMore, 0, 0, 0, 1, 0, 0, 0, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newTestSessions(t, nil, nil)
+			client.writeTurn.Lock()
+			client.ws.WriteMessage(websocket.BinaryMessage, tt.frame)
+			client.writeTurn.Unlock()
+			select {
+			case <-server.Done():
+			case <-time.After(5 * time.Second):
+				t.Fatal("session did not end")
+			}
+			if server.Err() != errProtocol {
+				t.Errorf("Err() = %v, want %v", server.Err(), errProtocol)
+			}
+		})
+	}
+}
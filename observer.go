@@ -0,0 +1,64 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// Observer observes the lifecycle of a connection. The Dialer.Observer and
+// Upgrader.Observer fields set the observer of the connections that they
+// open.
+//
+// The methods are called by the goroutines that read, write and close the
+// connection and may be called concurrently.
+type Observer interface {
+	// OnOpen is called when the opening handshake succeeds, before Dial or
+	// Upgrade returns the connection.
+	OnOpen(c *Conn)
+
+	// OnMessage is called when a data message is read, with any of the read
+	// methods. See ConnTrace.MessageRead for when a message is read.
+	OnMessage(c *Conn, messageType int, size int64)
+
+	// OnError is called with the error that ends reading, unless it is a
+	// close error, and with the error that ends writing.
+	OnError(c *Conn, err error)
+
+	// OnClose is called once when the connection ends: when a close message
+	// is received, with the code and text of the message, or when reading
+	// fails or Close is called before that, with CloseAbnormalClosure.
+	OnClose(c *Conn, code int, text string)
+}
+
+// observeOpen sets the observer of c and calls OnOpen.
+func (c *Conn) observeOpen(o Observer) {
+	if o != nil {
+		c.observer = o
+		o.OnOpen(c)
+	}
+}
+
+// observeReadError reports the error that ends reading to the observer.
+func (c *Conn) observeReadError(err error) {
+	if c.observer == nil {
+		return
+	}
+	var ce *CloseError
+	if errors.As(err, &ce) {
+		c.observeClose(ce.Code, ce.Text)
+		return
+	}
+	c.observer.OnError(c, err)
+	c.observeClose(CloseAbnormalClosure, "")
+}
+
+// observeClose calls OnClose on the first call.
+func (c *Conn) observeClose(code int, text string) {
+	if c.observer != nil && atomic.CompareAndSwapInt32(&c.observerClosed, 0, 1) {
+		c.observer.OnClose(c, code, text)
+	}
+}
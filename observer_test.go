@@ -0,0 +1,98 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// observerLog is an Observer that records the calls.
+type observerLog struct {
+	traceLog
+}
+
+func (o *observerLog) OnOpen(c *Conn) { o.add("open") }
+
+func (o *observerLog) OnMessage(c *Conn, messageType int, size int64) {
+	o.add("message %d %d", messageType, size)
+}
+
+func (o *observerLog) OnError(c *Conn, err error) { o.add("error") }
+
+func (o *observerLog) OnClose(c *Conn, code int, text string) {
+	o.add("close %d %s", code, text)
+}
+
+func TestObserver(t *testing.T) {
+	var serverLog, clientLog observerLog
+	done := make(chan struct{})
+	upgrader := Upgrader{Observer: &serverLog}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		c.ReadMessage()
+		_, r2, _ := c.NextReader()
+		io.ReadAll(r2)
+		var v interface{}
+		c.ReadJSON(&v)
+		_, _, err = c.ReadMessage()
+		if !IsCloseError(err, CloseNormalClosure) {
+			t.Errorf("ReadMessage() error = %v, want close error", err)
+		}
+	}))
+	defer s.Close()
+
+	d := Dialer{Observer: &clientLog}
+	c, _, err := d.Dial(makeWsProto(s.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.WriteMessage(TextMessage, []byte("hello"))
+	c.WriteMessage(BinaryMessage, []byte("world!"))
+	c.WriteJSON([]int{1})
+	c.WriteControl(CloseMessage, FormatCloseMessage(CloseNormalClosure, "done"), time.Now().Add(time.Second))
+	<-done
+	if _, _, err := c.ReadMessage(); err == nil {
+		t.Fatal("ReadMessage() succeeded after server closed")
+	}
+	c.Close()
+
+	wantServer := []string{"open", "message 1 5", "message 2 6", "message 1 4", "close 1000 done"}
+	if got := serverLog.get(); !reflect.DeepEqual(got, wantServer) {
+		t.Errorf("server calls = %q, want %q", got, wantServer)
+	}
+	wantClient := []string{"open", "close 1000 "}
+	if got := clientLog.get(); !reflect.DeepEqual(got, wantClient) {
+		t.Errorf("client calls = %q, want %q", got, wantClient)
+	}
+}
+
+func TestObserverClose(t *testing.T) {
+	client, server := Pipe()
+	var o observerLog
+	client.observeOpen(&o)
+	server.Close()
+	if _, _, err := client.ReadMessage(); err == nil {
+		t.Fatal("ReadMessage() succeeded after peer closed")
+	}
+	client.Close()
+	client.Close()
+	if err := client.WriteMessage(TextMessage, []byte("hello")); err == nil {
+		t.Fatal("WriteMessage() succeeded after Close")
+	}
+	want := []string{"open", "close 1006 unexpected EOF", "error"}
+	if got := o.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,380 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DialerOption configures a Dialer. See NewDialer and Dialer.DialOptions.
+type DialerOption interface {
+	applyDialer(c *dialConfig)
+}
+
+// UpgraderOption configures an Upgrader. See NewUpgrader.
+type UpgraderOption interface {
+	applyUpgrader(u *Upgrader)
+}
+
+// Option configures both dialers and upgraders.
+type Option interface {
+	DialerOption
+	UpgraderOption
+}
+
+// dialConfig is the configuration of a dial.
+type dialConfig struct {
+	dialer *Dialer
+	header http.Header
+}
+
+type dialerOption func(c *dialConfig)
+
+func (f dialerOption) applyDialer(c *dialConfig) { f(c) }
+
+type upgraderOption func(u *Upgrader)
+
+func (f upgraderOption) applyUpgrader(u *Upgrader) { f(u) }
+
+type option struct {
+	dialer   func(d *Dialer)
+	upgrader func(u *Upgrader)
+}
+
+func (o option) applyDialer(c *dialConfig) { o.dialer(c.dialer) }
+func (o option) applyUpgrader(u *Upgrader) { o.upgrader(u) }
+
+// NewDialer returns a dialer with the fields of DefaultDialer changed by the
+// options. NewDialer ignores WithHeader.
+func NewDialer(opts ...DialerOption) *Dialer {
+	d := *DefaultDialer
+	c := dialConfig{dialer: &d}
+	for _, opt := range opts {
+		opt.applyDialer(&c)
+	}
+	return &d
+}
+
+// NewUpgrader returns an upgrader with the zero value fields changed by the
+// options.
+func NewUpgrader(opts ...UpgraderOption) *Upgrader {
+	u := &Upgrader{}
+	for _, opt := range opts {
+		opt.applyUpgrader(u)
+	}
+	return u
+}
+
+// DialOptions creates a new client connection like DialContext with a copy
+// of the dialer changed by the options. Use WithHeader to set the fields
+// of the request header.
+func (d *Dialer) DialOptions(ctx context.Context, urlStr string, opts ...DialerOption) (*Conn, *http.Response, error) {
+	if d == nil {
+		d = &nilDialer
+	}
+	dialer := *d
+	c := dialConfig{dialer: &dialer}
+	for _, opt := range opts {
+		opt.applyDialer(&c)
+	}
+	return dialer.DialContext(ctx, urlStr, c.header)
+}
+
+// Dial creates a new client connection with DefaultDialer changed by the
+// options:
+//
+//	c, _, err := websocket.Dial(ctx, "wss://example.com/ws",
+//		websocket.WithSubprotocols("chat"),
+//		websocket.WithHeader(http.Header{"Origin": {"https://example.com"}}))
+func Dial(ctx context.Context, urlStr string, opts ...DialerOption) (*Conn, *http.Response, error) {
+	return DefaultDialer.DialOptions(ctx, urlStr, opts...)
+}
+
+// Options for dialers and upgraders.
+
+// WithBufferSizes sets ReadBufferSize and WriteBufferSize.
+func WithBufferSizes(readBufferSize, writeBufferSize int) Option {
+	return option{
+		dialer:   func(d *Dialer) { d.ReadBufferSize, d.WriteBufferSize = readBufferSize, writeBufferSize },
+		upgrader: func(u *Upgrader) { u.ReadBufferSize, u.WriteBufferSize = readBufferSize, writeBufferSize },
+	}
+}
+
+// WithWriteBufferPool sets WriteBufferPool.
+func WithWriteBufferPool(pool BufferPool) Option {
+	return option{
+		dialer:   func(d *Dialer) { d.WriteBufferPool = pool },
+		upgrader: func(u *Upgrader) { u.WriteBufferPool = pool },
+	}
+}
+
+// WithHandshakeTimeout sets HandshakeTimeout.
+func WithHandshakeTimeout(timeout time.Duration) Option {
+	return option{
+		dialer:   func(d *Dialer) { d.HandshakeTimeout = timeout },
+		upgrader: func(u *Upgrader) { u.HandshakeTimeout = timeout },
+	}
+}
+
+// WithSubprotocols sets Subprotocols.
+func WithSubprotocols(protocols ...string) Option {
+	return option{
+		dialer:   func(d *Dialer) { d.Subprotocols = protocols },
+		upgrader: func(u *Upgrader) { u.Subprotocols = protocols },
+	}
+}
+
+// WithCompression sets EnableCompression.
+func WithCompression(enable bool) Option {
+	return option{
+		dialer:   func(d *Dialer) { d.EnableCompression = enable },
+		upgrader: func(u *Upgrader) { u.EnableCompression = enable },
+	}
+}
+
+// WithClock sets Clock.
+func WithClock(clock Clock) Option {
+	return option{
+		dialer:   func(d *Dialer) { d.Clock = clock },
+		upgrader: func(u *Upgrader) { u.Clock = clock },
+	}
+}
+
+// WithObserver sets Observer.
+func WithObserver(observer Observer) Option {
+	return option{
+		dialer:   func(d *Dialer) { d.Observer = observer },
+		upgrader: func(u *Upgrader) { u.Observer = observer },
+	}
+}
+
+// WithReadRateLimit sets ReadRateLimit.
+func WithReadRateLimit(limit *RateLimit) Option {
+	return option{
+		dialer:   func(d *Dialer) { d.ReadRateLimit = limit },
+		upgrader: func(u *Upgrader) { u.ReadRateLimit = limit },
+	}
+}
+
+// WithWriteThrottle sets WriteThrottle.
+func WithWriteThrottle(throttle *Throttle) Option {
+	return option{
+		dialer:   func(d *Dialer) { d.WriteThrottle = throttle },
+		upgrader: func(u *Upgrader) { u.WriteThrottle = throttle },
+	}
+}
+
+// WithProtocolViolationHandler sets OnProtocolViolation.
+func WithProtocolViolationHandler(h func(v ProtocolViolation)) Option {
+	return option{
+		dialer:   func(d *Dialer) { d.OnProtocolViolation = h },
+		upgrader: func(u *Upgrader) { u.OnProtocolViolation = h },
+	}
+}
+
+// WithStrictness sets Strictness.
+func WithStrictness(s Strictness) Option {
+	return option{
+		dialer:   func(d *Dialer) { d.Strictness = s },
+		upgrader: func(u *Upgrader) { u.Strictness = s },
+	}
+}
+
+// WithQuirks sets Quirks.
+func WithQuirks(q Quirk) Option {
+	return option{
+		dialer:   func(d *Dialer) { d.Quirks = q },
+		upgrader: func(u *Upgrader) { u.Quirks = q },
+	}
+}
+
+// WithDefaultWriteTimeout sets DefaultWriteTimeout.
+func WithDefaultWriteTimeout(timeout time.Duration) Option {
+	return option{
+		dialer:   func(d *Dialer) { d.DefaultWriteTimeout = timeout },
+		upgrader: func(u *Upgrader) { u.DefaultWriteTimeout = timeout },
+	}
+}
+
+// WithReadActivityTimeout sets ReadActivityTimeout.
+func WithReadActivityTimeout(timeout time.Duration) Option {
+	return option{
+		dialer:   func(d *Dialer) { d.ReadActivityTimeout = timeout },
+		upgrader: func(u *Upgrader) { u.ReadActivityTimeout = timeout },
+	}
+}
+
+// WithCompressionPolicy sets CompressionPolicy.
+func WithCompressionPolicy(policy CompressionPolicy) Option {
+	return option{
+		dialer:   func(d *Dialer) { d.CompressionPolicy = policy },
+		upgrader: func(u *Upgrader) { u.CompressionPolicy = policy },
+	}
+}
+
+// WithCompressionLevelPolicy sets CompressionLevelPolicy.
+func WithCompressionLevelPolicy(policy CompressionLevelPolicy) Option {
+	return option{
+		dialer:   func(d *Dialer) { d.CompressionLevelPolicy = policy },
+		upgrader: func(u *Upgrader) { u.CompressionLevelPolicy = policy },
+	}
+}
+
+// WithFlowControlWindow sets FlowControlWindow.
+func WithFlowControlWindow(window int) Option {
+	return option{
+		dialer:   func(d *Dialer) { d.FlowControlWindow = window },
+		upgrader: func(u *Upgrader) { u.FlowControlWindow = window },
+	}
+}
+
+// WithSpool sets Spool.
+func WithSpool(cfg *SpoolConfig) Option {
+	return option{
+		dialer:   func(d *Dialer) { d.Spool = cfg },
+		upgrader: func(u *Upgrader) { u.Spool = cfg },
+	}
+}
+
+// WithLabels sets Labels. The upgrader sets the same labels on every
+// connection; set Upgrader.Labels for labels computed from the request.
+func WithLabels(labels map[string]string) Option {
+	return option{
+		dialer:   func(d *Dialer) { d.Labels = labels },
+		upgrader: func(u *Upgrader) { u.Labels = func(r *http.Request) map[string]string { return labels } },
+	}
+}
+
+// WithSocketOptions sets SocketOptions.
+func WithSocketOptions(o *SocketOptions) Option {
+	return option{
+		dialer:   func(d *Dialer) { d.SocketOptions = o },
+		upgrader: func(u *Upgrader) { u.SocketOptions = o },
+	}
+}
+
+// Dialer options.
+
+// WithHeader adds the fields of header to the handshake request. Use
+// WithHeader with Dial and Dialer.DialOptions. NewDialer ignores
+// WithHeader.
+func WithHeader(header http.Header) DialerOption {
+	return dialerOption(func(c *dialConfig) {
+		if c.header == nil {
+			c.header = make(http.Header)
+		}
+		for k, vs := range header {
+			c.header[k] = append(c.header[k], vs...)
+		}
+	})
+}
+
+// WithNetDial sets NetDial.
+func WithNetDial(dial func(network, addr string) (net.Conn, error)) DialerOption {
+	return dialerOption(func(c *dialConfig) { c.dialer.NetDial = dial })
+}
+
+// WithNetDialContext sets NetDialContext.
+func WithNetDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) DialerOption {
+	return dialerOption(func(c *dialConfig) { c.dialer.NetDialContext = dial })
+}
+
+// WithNetDialTLSContext sets NetDialTLSContext.
+func WithNetDialTLSContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) DialerOption {
+	return dialerOption(func(c *dialConfig) { c.dialer.NetDialTLSContext = dial })
+}
+
+// WithProxy sets Proxy.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) DialerOption {
+	return dialerOption(func(c *dialConfig) { c.dialer.Proxy = proxy })
+}
+
+// WithTLSConfig sets TLSClientConfig.
+func WithTLSConfig(config *tls.Config) DialerOption {
+	return dialerOption(func(c *dialConfig) { c.dialer.TLSClientConfig = config })
+}
+
+// WithJar sets Jar.
+func WithJar(jar http.CookieJar) DialerOption {
+	return dialerOption(func(c *dialConfig) { c.dialer.Jar = jar })
+}
+
+// WithVersion sets Version.
+func WithVersion(version string) DialerOption {
+	return dialerOption(func(c *dialConfig) { c.dialer.Version = version })
+}
+
+// WithMaskKeySource sets MaskKeySource.
+func WithMaskKeySource(r io.Reader) DialerOption {
+	return dialerOption(func(c *dialConfig) { c.dialer.MaskKeySource = r })
+}
+
+// WithProxyPool sets ProxyPool.
+func WithProxyPool(pool *ProxyPool) DialerOption {
+	return dialerOption(func(c *dialConfig) { c.dialer.ProxyPool = pool })
+}
+
+// WithPhaseTimeouts sets ConnectTimeout, TLSHandshakeTimeout and
+// UpgradeTimeout.
+func WithPhaseTimeouts(connect, tlsHandshake, upgrade time.Duration) DialerOption {
+	return dialerOption(func(c *dialConfig) {
+		c.dialer.ConnectTimeout = connect
+		c.dialer.TLSHandshakeTimeout = tlsHandshake
+		c.dialer.UpgradeTimeout = upgrade
+	})
+}
+
+// Upgrader options.
+
+// WithErrorHandler sets Error.
+func WithErrorHandler(h func(w http.ResponseWriter, r *http.Request, status int, reason error)) UpgraderOption {
+	return upgraderOption(func(u *Upgrader) { u.Error = h })
+}
+
+// WithCheckOrigin sets CheckOrigin.
+func WithCheckOrigin(check func(r *http.Request) bool) UpgraderOption {
+	return upgraderOption(func(u *Upgrader) { u.CheckOrigin = check })
+}
+
+// WithAuthenticate sets Authenticate.
+func WithAuthenticate(auth func(r *http.Request) (claims interface{}, err error)) UpgraderOption {
+	return upgraderOption(func(u *Upgrader) { u.Authenticate = auth })
+}
+
+// WithIPFilter sets IPFilter.
+func WithIPFilter(f *IPFilter) UpgraderOption {
+	return upgraderOption(func(u *Upgrader) { u.IPFilter = f })
+}
+
+// WithDeflateFrame sets EnableDeflateFrame.
+func WithDeflateFrame(enable bool) UpgraderOption {
+	return upgraderOption(func(u *Upgrader) { u.EnableDeflateFrame = enable })
+}
+
+// WithVersions sets Versions.
+func WithVersions(versions ...string) UpgraderOption {
+	return upgraderOption(func(u *Upgrader) { u.Versions = versions })
+}
+
+// WithResponseHeader sets ResponseHeader.
+func WithResponseHeader(f func(r *http.Request, n Negotiation, header http.Header) error) UpgraderOption {
+	return upgraderOption(func(u *Upgrader) { u.ResponseHeader = f })
+}
+
+// WithAccessLog sets AccessLog.
+func WithAccessLog(log func(e AccessLogEntry)) UpgraderOption {
+	return upgraderOption(func(u *Upgrader) { u.AccessLog = log })
+}
+
+// WithFallback sets Fallback.
+func WithFallback(h http.Handler) UpgraderOption {
+	return upgraderOption(func(u *Upgrader) { u.Fallback = h })
+}
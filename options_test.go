@@ -0,0 +1,90 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewDialer(t *testing.T) {
+	d := NewDialer(
+		WithBufferSizes(512, 1024),
+		WithHandshakeTimeout(time.Second),
+		WithSubprotocols("chat"),
+		WithCompression(true),
+		WithStrictness(Strict),
+		WithVersion(Version13),
+		WithHeader(http.Header{"Origin": {"http://example.com"}}),
+		WithLabels(map[string]string{"tenant": "a"}),
+	)
+	if d.ReadBufferSize != 512 || d.WriteBufferSize != 1024 || d.HandshakeTimeout != time.Second ||
+		len(d.Subprotocols) != 1 || !d.EnableCompression || d.Strictness != Strict || d.Version != Version13 ||
+		d.Labels["tenant"] != "a" {
+		t.Errorf("NewDialer() = %+v", d)
+	}
+	if d.Proxy == nil {
+		t.Error("NewDialer() did not start from DefaultDialer")
+	}
+}
+
+func TestNewUpgrader(t *testing.T) {
+	u := NewUpgrader(
+		WithBufferSizes(512, 1024),
+		WithSubprotocols("chat"),
+		WithQuirks(QuirkShortClose),
+		WithDeflateFrame(true),
+		WithVersions(Version13),
+		WithDefaultWriteTimeout(time.Second),
+		WithCheckOrigin(func(r *http.Request) bool { return true }),
+		WithAccessLog(func(e AccessLogEntry) {}),
+		WithFallback(http.NotFoundHandler()),
+		WithLabels(map[string]string{"tenant": "a"}),
+		WithResponseHeader(func(r *http.Request, n Negotiation, header http.Header) error { return nil }),
+	)
+	if u.ReadBufferSize != 512 || u.WriteBufferSize != 1024 || len(u.Subprotocols) != 1 ||
+		u.Quirks != QuirkShortClose || !u.EnableDeflateFrame || len(u.Versions) != 1 || u.CheckOrigin == nil ||
+		u.DefaultWriteTimeout != time.Second || u.AccessLog == nil || u.Fallback == nil ||
+		u.Labels == nil || u.Labels(nil)["tenant"] != "a" || u.ResponseHeader == nil {
+		t.Errorf("NewUpgrader() = %+v", u)
+	}
+}
+
+func TestDialOptions(t *testing.T) {
+	origins := make(chan string, 1)
+	upgrader := NewUpgrader(WithSubprotocols("chat"), WithCheckOrigin(func(r *http.Request) bool { return true }))
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origins <- r.Header.Get("Origin")
+		if c, err := upgrader.Upgrade(w, r, nil); err == nil {
+			c.Close()
+		}
+	}))
+	defer s.Close()
+
+	d := NewDialer(WithSubprotocols("other"))
+	c, _, err := d.DialOptions(context.Background(), makeWsProto(s.URL),
+		WithSubprotocols("chat"),
+		WithHeader(http.Header{"Origin": {"http://example.com"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if c.Subprotocol() != "chat" || <-origins != "http://example.com" {
+		t.Errorf("Subprotocol() = %q, want chat", c.Subprotocol())
+	}
+	if len(d.Subprotocols) != 1 || d.Subprotocols[0] != "other" {
+		t.Errorf("DialOptions() changed the dialer: %v", d.Subprotocols)
+	}
+
+	c2, _, err := Dial(context.Background(), makeWsProto(s.URL), WithSubprotocols("chat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-origins
+	c2.Close()
+}
@@ -0,0 +1,185 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// PipeConfig specifies the negotiated state of connections created by
+// PipeConfig.Pipe. The zero value is a valid configuration.
+type PipeConfig struct {
+	// ReadBufferSize and WriteBufferSize specify I/O buffer sizes in bytes.
+	// If a buffer size is zero, a default size of 4096 is used.
+	ReadBufferSize, WriteBufferSize int
+
+	// Subprotocol is the subprotocol reported by Conn.Subprotocol.
+	Subprotocol string
+
+	// EnableCompression specifies that the connections negotiated
+	// per-message compression (RFC 7692).
+	EnableCompression bool
+
+	// Clock specifies the clock for the connections' deadlines, including
+	// the read deadlines enforced by the in-memory buffers. If Clock is nil,
+	// the system clock is used.
+	Clock Clock
+}
+
+// Pipe returns two connected connections backed by in-memory buffers. There
+// is no opening handshake. The client connection masks the frames it sends
+// and the server connection expects masked frames, as on a network
+// connection.
+//
+// Writes to the underlying buffers do not block, so a test can write on one
+// connection and then read on the other from the same goroutine. Write
+// deadlines have no effect.
+func (pc *PipeConfig) Pipe() (client, server *Conn) {
+	if pc == nil {
+		pc = &PipeConfig{}
+	}
+	clock := clockOrSystem(pc.Clock)
+	a, b := newPipeConns(clock)
+	client = newConn(a, false, pc.ReadBufferSize, pc.WriteBufferSize, nil, nil, nil)
+	server = newConn(b, true, pc.ReadBufferSize, pc.WriteBufferSize, nil, nil, nil)
+	for _, c := range []*Conn{client, server} {
+		c.clock = clock
+		c.subprotocol = pc.Subprotocol
+		if pc.EnableCompression {
+			c.newCompressionWriter = compressNoContextTakeover
+			c.newDecompressionReader = decompressNoContextTakeover
+		}
+	}
+	return client, server
+}
+
+// Pipe returns two connected connections backed by in-memory buffers. It is
+// shorthand for (*PipeConfig).Pipe with a nil configuration.
+func Pipe() (client, server *Conn) {
+	return (*PipeConfig)(nil).Pipe()
+}
+
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string { return "pipe" }
+func (pipeAddr) String() string  { return "pipe" }
+
+// pipeBuffer is one direction of a pipe.
+type pipeBuffer struct {
+	mu       sync.Mutex
+	buf      []byte
+	eof      bool // the writer closed
+	closed   bool // the reader closed
+	deadline time.Time
+	ready    chan struct{}
+	clock    Clock
+}
+
+func (pb *pipeBuffer) signal() {
+	select {
+	case pb.ready <- struct{}{}:
+	default:
+	}
+}
+
+// pipeConn is a net.Conn over two unbounded in-memory buffers.
+type pipeConn struct {
+	r, w *pipeBuffer
+}
+
+func newPipeConns(clock Clock) (net.Conn, net.Conn) {
+	x := &pipeBuffer{ready: make(chan struct{}, 1), clock: clock}
+	y := &pipeBuffer{ready: make(chan struct{}, 1), clock: clock}
+	return &pipeConn{r: x, w: y}, &pipeConn{r: y, w: x}
+}
+
+func (pc *pipeConn) Read(p []byte) (int, error) {
+	pb := pc.r
+	for {
+		pb.mu.Lock()
+		switch {
+		case pb.closed:
+			pb.mu.Unlock()
+			return 0, net.ErrClosed
+		case len(pb.buf) > 0:
+			n := copy(p, pb.buf)
+			pb.buf = pb.buf[n:]
+			pb.mu.Unlock()
+			return n, nil
+		case pb.eof:
+			pb.mu.Unlock()
+			return 0, io.EOF
+		}
+		deadline := pb.deadline
+		pb.mu.Unlock()
+
+		if deadline.IsZero() {
+			<-pb.ready
+			continue
+		}
+		d := deadline.Sub(pb.clock.Now())
+		if d <= 0 {
+			return 0, os.ErrDeadlineExceeded
+		}
+		t := pb.clock.NewTimer(d)
+		select {
+		case <-pb.ready:
+			t.Stop()
+		case <-t.C():
+		}
+	}
+}
+
+func (pc *pipeConn) Write(p []byte) (int, error) {
+	pb := pc.w
+	pb.mu.Lock()
+	if pb.eof {
+		pb.mu.Unlock()
+		return 0, net.ErrClosed
+	}
+	if !pb.closed {
+		pb.buf = append(pb.buf, p...)
+	}
+	pb.mu.Unlock()
+	pb.signal()
+	return len(p), nil
+}
+
+func (pc *pipeConn) Close() error {
+	pc.r.mu.Lock()
+	pc.r.closed = true
+	pc.r.buf = nil
+	pc.r.mu.Unlock()
+	pc.r.signal()
+
+	pc.w.mu.Lock()
+	pc.w.eof = true
+	pc.w.mu.Unlock()
+	pc.w.signal()
+	return nil
+}
+
+func (pc *pipeConn) LocalAddr() net.Addr  { return pipeAddr{} }
+func (pc *pipeConn) RemoteAddr() net.Addr { return pipeAddr{} }
+
+func (pc *pipeConn) SetDeadline(t time.Time) error {
+	return pc.SetReadDeadline(t)
+}
+
+func (pc *pipeConn) SetReadDeadline(t time.Time) error {
+	pc.r.mu.Lock()
+	pc.r.deadline = t
+	pc.r.mu.Unlock()
+	pc.r.signal()
+	return nil
+}
+
+func (pc *pipeConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
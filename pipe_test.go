@@ -0,0 +1,111 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestPipeRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *PipeConfig
+	}{
+		{"default", nil},
+		{"compression", &PipeConfig{EnableCompression: true}},
+		{"small buffers", &PipeConfig{ReadBufferSize: 16, WriteBufferSize: 16}},
+	}
+	messages := [][]byte{
+		nil,
+		[]byte("hello"),
+		bytes.Repeat([]byte("abcdefgh"), 100000),
+	}
+	for _, tt := range tests {
+		client, server := tt.cfg.Pipe()
+		client.EnableWriteCompression(true)
+		server.EnableWriteCompression(true)
+		for _, m := range messages {
+			for _, c := range [][2]*Conn{{client, server}, {server, client}} {
+				if err := c[0].WriteMessage(BinaryMessage, m); err != nil {
+					t.Fatalf("%s: WriteMessage() error = %v", tt.name, err)
+				}
+				mt, p, err := c[1].ReadMessage()
+				if err != nil || mt != BinaryMessage || !bytes.Equal(p, m) {
+					t.Fatalf("%s: ReadMessage() = %d, %d bytes, %v, want %d bytes", tt.name, mt, len(p), err, len(m))
+				}
+			}
+		}
+		client.Close()
+		server.Close()
+	}
+}
+
+func TestPipeCompressed(t *testing.T) {
+	client, server := (&PipeConfig{EnableCompression: true}).Pipe()
+	var frames bytes.Buffer
+	if err := client.WriteMessage(TextMessage, bytes.Repeat([]byte("a"), 1000)); err != nil {
+		t.Fatal(err)
+	}
+	pb := server.conn.(*pipeConn).r
+	pb.mu.Lock()
+	frames.Write(pb.buf)
+	pb.mu.Unlock()
+	if frames.Len() == 0 || frames.Bytes()[0]&rsv1Bit == 0 {
+		t.Fatalf("first frame header = %x, want RSV1 set", frames.Bytes()[:1])
+	}
+	if frames.Len() >= 1000 {
+		t.Errorf("compressed frame is %d bytes", frames.Len())
+	}
+	if _, p, err := server.ReadMessage(); err != nil || len(p) != 1000 {
+		t.Errorf("ReadMessage() = %d bytes, %v", len(p), err)
+	}
+}
+
+func TestPipeSubprotocol(t *testing.T) {
+	client, server := (&PipeConfig{Subprotocol: "chat"}).Pipe()
+	if client.Subprotocol() != "chat" || server.Subprotocol() != "chat" {
+		t.Errorf("Subprotocol() = %q, %q", client.Subprotocol(), server.Subprotocol())
+	}
+	if client.LocalAddr().Network() != "pipe" {
+		t.Errorf("LocalAddr().Network() = %q", client.LocalAddr().Network())
+	}
+}
+
+func TestPipeReadDeadline(t *testing.T) {
+	client, _ := Pipe()
+	client.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	_, _, err := client.ReadMessage()
+	var ne net.Error
+	if !errors.Is(err, os.ErrDeadlineExceeded) || !errors.As(err, &ne) || !ne.Timeout() {
+		t.Errorf("ReadMessage() error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestPipeClose(t *testing.T) {
+	client, server := Pipe()
+	if err := client.WriteMessage(CloseMessage, FormatCloseMessage(CloseGoingAway, "bye")); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := server.ReadMessage(); !IsCloseError(err, CloseGoingAway) {
+		t.Errorf("server ReadMessage() error = %v, want close %d", err, CloseGoingAway)
+	}
+	if _, _, err := client.ReadMessage(); !IsCloseError(err, CloseGoingAway) {
+		t.Errorf("client ReadMessage() error = %v, want close %d", err, CloseGoingAway)
+	}
+
+	client, server = Pipe()
+	server.Close()
+	if _, _, err := client.ReadMessage(); !IsUnexpectedCloseError(err) {
+		t.Errorf("ReadMessage() after peer close error = %v, want abnormal closure", err)
+	}
+	if _, _, err := server.ReadMessage(); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("ReadMessage() after Close error = %v, want %v", err, net.ErrClosed)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// Profiler label keys set by Conn.Go and Conn.DoWithProfilerLabels.
+const (
+	ProfilerLabelRemoteAddr  = "websocket.remote_addr"
+	ProfilerLabelSubprotocol = "websocket.subprotocol"
+	ProfilerLabelTag         = "websocket.tag"
+)
+
+// SetProfilerTag sets an application-provided tag, such as a tenant name,
+// that is added to the profiler labels of the connection.
+func (c *Conn) SetProfilerTag(tag string) {
+	c.profilerTag.Store(&tag)
+}
+
+// ProfilerLabels returns the profiler labels of the connection: the remote
+// address, the negotiated subprotocol, the tag set by SetProfilerTag and the
+// labels set by SetLabel under their own keys. Labels with empty values are
+// omitted.
+func (c *Conn) ProfilerLabels() pprof.LabelSet {
+	args := make([]string, 0, 6)
+	if p := c.labels.Load(); p != nil {
+		for k, v := range *p {
+			args = append(args, k, v)
+		}
+	}
+	if addr := c.RemoteAddr(); addr != nil {
+		args = append(args, ProfilerLabelRemoteAddr, addr.String())
+	}
+	if c.subprotocol != "" {
+		args = append(args, ProfilerLabelSubprotocol, c.subprotocol)
+	}
+	if tag := c.profilerTag.Load(); tag != nil && *tag != "" {
+		args = append(args, ProfilerLabelTag, *tag)
+	}
+	return pprof.Labels(args...)
+}
+
+// DoWithProfilerLabels calls f with a copy of ctx carrying the profiler
+// labels of the connection and sets the labels on the calling goroutine
+// while f runs. CPU profiles of work done by f can then be sliced by
+// connection.
+func (c *Conn) DoWithProfilerLabels(ctx context.Context, f func(context.Context)) {
+	pprof.Do(ctx, c.ProfilerLabels(), f)
+}
+
+// Go calls f in a new goroutine that carries the profiler labels of the
+// connection. Packages that run goroutines on behalf of a connection, such
+// as read loops and keepalive timers, start them with Go.
+func (c *Conn) Go(f func()) {
+	labels := c.ProfilerLabels()
+	go pprof.Do(context.Background(), labels, func(context.Context) { f() })
+}
@@ -0,0 +1,50 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+)
+
+func TestProfilerLabels(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	server.subprotocol = "chat"
+	server.SetProfilerTag("tenant-1")
+
+	want := map[string]string{
+		ProfilerLabelRemoteAddr:  server.RemoteAddr().String(),
+		ProfilerLabelSubprotocol: "chat",
+		ProfilerLabelTag:         "tenant-1",
+	}
+	check := func(name string, ctx context.Context) {
+		for k, v := range want {
+			if got, _ := pprof.Label(ctx, k); got != v {
+				t.Errorf("%s: label %s = %q, want %q", name, k, got, v)
+			}
+		}
+	}
+
+	server.DoWithProfilerLabels(context.Background(), func(ctx context.Context) {
+		check("DoWithProfilerLabels", ctx)
+	})
+
+	ran := make(chan struct{})
+	server.Go(func() { close(ran) })
+	<-ran
+
+	// Labels with empty values are omitted.
+	var keys []string
+	pprof.ForLabels(pprof.WithLabels(context.Background(), client.ProfilerLabels()), func(k, v string) bool {
+		keys = append(keys, k)
+		return true
+	})
+	if len(keys) != 1 || keys[0] != ProfilerLabelRemoteAddr {
+		t.Errorf("client labels = %v, want [%s]", keys, ProfilerLabelRemoteAddr)
+	}
+}
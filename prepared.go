@@ -6,21 +6,29 @@ package websocket
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"net"
 	"sync"
 	"time"
 )
 
+var errFragmentControl = errors.New("websocket: control messages cannot be fragmented")
+
 // PreparedMessage caches on the wire representations of a message payload.
 // Use PreparedMessage to efficiently send a message payload to multiple
 // connections. PreparedMessage is especially useful when compression is used
 // because the CPU and memory expensive compression operation can be executed
-// once for a given set of compression options.
+// once for a given set of compression options and negotiated compression
+// parameters. Messages written to connections with compression context
+// takeover depend on the previous messages on the connection; they are
+// compressed for each connection.
 type PreparedMessage struct {
-	messageType int
-	data        []byte
-	mu          sync.Mutex
-	frames      map[prepareKey]*preparedFrame
+	messageType  int
+	data         []byte
+	fragmentSize int // maximum frame payload size, or zero
+	mu           sync.Mutex
+	frames       map[prepareKey]*preparedFrame
 }
 
 // prepareKey defines a unique set of options to cache prepared frames in PreparedMessage.
@@ -28,6 +36,7 @@ type prepareKey struct {
 	isServer         bool
 	compress         bool
 	compressionLevel int
+	params           compressionParams // negotiated compression parameters
 }
 
 // preparedFrame contains data in wire representation.
@@ -59,6 +68,74 @@ func NewPreparedMessage(messageType int, data []byte) (*PreparedMessage, error)
 	return pm, nil
 }
 
+// PreparedMessageWriter builds a PreparedMessage from a payload that is
+// written in parts, for example a large static asset read from a file:
+//
+//	w := websocket.NewPreparedMessageWriter(websocket.BinaryMessage)
+//	w.FragmentSize = 64 << 10
+//	if _, err := io.Copy(w, f); err != nil {
+//		...
+//	}
+//	pm, err := w.PreparedMessage()
+type PreparedMessageWriter struct {
+	// FragmentSize is the maximum number of payload bytes in a frame. The
+	// frames of a larger message are prepared once for all connections
+	// instead of being fragmented by each connection. If FragmentSize is
+	// zero, the message is framed as by NewPreparedMessage. Control
+	// messages cannot be fragmented.
+	FragmentSize int
+
+	messageType int
+	buf         bytes.Buffer
+}
+
+// NewPreparedMessageWriter returns a writer for the payload of a prepared
+// message of the type.
+func NewPreparedMessageWriter(messageType int) *PreparedMessageWriter {
+	return &PreparedMessageWriter{messageType: messageType}
+}
+
+// Write appends p to the payload.
+func (w *PreparedMessageWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+// ReadFrom appends the data read from r until EOF to the payload.
+func (w *PreparedMessageWriter) ReadFrom(r io.Reader) (int64, error) {
+	return w.buf.ReadFrom(r)
+}
+
+// PreparedMessage returns the prepared message of the payload written so
+// far. The writer must not be used after PreparedMessage returns.
+func (w *PreparedMessageWriter) PreparedMessage() (*PreparedMessage, error) {
+	if w.FragmentSize > 0 && !isData(w.messageType) && w.buf.Len() > w.FragmentSize {
+		return nil, errFragmentControl
+	}
+	pm := &PreparedMessage{
+		messageType:  w.messageType,
+		frames:       make(map[prepareKey]*preparedFrame),
+		data:         w.buf.Bytes(),
+		fragmentSize: w.FragmentSize,
+	}
+	if _, _, err := pm.frame(prepareKey{isServer: true, compress: false}); err != nil {
+		return nil, err
+	}
+	return pm, nil
+}
+
+// NewPreparedMessageFromReader returns a PreparedMessage with the payload
+// read from r until EOF. Messages larger than fragmentSize are prepared as
+// frames of at most fragmentSize payload bytes, see
+// PreparedMessageWriter.FragmentSize.
+func NewPreparedMessageFromReader(messageType int, r io.Reader, fragmentSize int) (*PreparedMessage, error) {
+	w := NewPreparedMessageWriter(messageType)
+	w.FragmentSize = fragmentSize
+	if _, err := w.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return w.PreparedMessage()
+}
+
 func (pm *PreparedMessage) frame(key prepareKey) (int, []byte, error) {
 	pm.mu.Lock()
 	frame, ok := pm.frames[key]
@@ -81,18 +158,67 @@ func (pm *PreparedMessage) frame(key prepareKey) (int, []byte, error) {
 			mu:                     mu,
 			isServer:               key.isServer,
 			compressionLevel:       key.compressionLevel,
+			compressParams:         key.params,
 			enableWriteCompression: true,
 			writeBuf:               make([]byte, defaultWriteBufferSize+maxFrameHeaderSize),
 		}
 		if key.compress {
 			c.newCompressionWriter = compressNoContextTakeover
 		}
-		err = c.WriteMessage(pm.messageType, pm.data)
+		if pm.fragmentSize > 0 && len(pm.data) > pm.fragmentSize {
+			err = pm.writeFragments(c)
+		} else {
+			err = c.WriteMessage(pm.messageType, pm.data)
+		}
 		frame.data = nc.buf.Bytes()
 	})
 	return pm.messageType, frame.data, err
 }
 
+// writeFragments writes the message to c in frames of at most
+// fragmentSize payload bytes.
+func (pm *PreparedMessage) writeFragments(c *Conn) error {
+	c.writeBuf = make([]byte, pm.fragmentSize+maxFrameHeaderSize)
+	if c.newCompressionWriter != nil {
+		// Limit the writes of the compressor to the message writer, which
+		// writes large writes of servers as single frames.
+		c.newCompressionWriter = func(w io.WriteCloser, level int) io.WriteCloser {
+			return compressNoContextTakeover(&chunkWriter{WriteCloser: w, n: pm.fragmentSize}, level)
+		}
+	}
+	w, err := c.NextWriter(pm.messageType)
+	if err != nil {
+		return err
+	}
+	if _, err := (&chunkWriter{WriteCloser: w, n: pm.fragmentSize}).Write(pm.data); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// chunkWriter writes to the underlying writer in writes of at most n bytes.
+type chunkWriter struct {
+	io.WriteCloser
+	n int
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	nn := 0
+	for len(p) > 0 {
+		q := p
+		if len(q) > w.n {
+			q = q[:w.n]
+		}
+		n, err := w.WriteCloser.Write(q)
+		nn += n
+		if err != nil {
+			return nn, err
+		}
+		p = p[n:]
+	}
+	return nn, nil
+}
+
 type prepareConn struct {
 	buf bytes.Buffer
 	net.Conn
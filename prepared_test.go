@@ -79,3 +79,120 @@ func TestPreparedMessage(t *testing.T) {
 		}
 	}
 }
+
+func TestPreparedMessageCompressionParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     compressionParams
+		compressed bool // the message is written compressed
+		shared     bool // the compressed frame is cached in the message
+	}{
+		{"default", compressionParams{}, true, true},
+		{"context takeover", compressionParams{contextTakeover: true}, true, false},
+		{"small window", compressionParams{maxWindowBits: 10}, false, false},
+	}
+	data := bytes.Repeat([]byte("this is a test "), 10)
+	for _, tt := range tests {
+		pm, err := NewPreparedMessage(TextMessage, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		c := newTestConn(nil, &buf, true)
+		c.newCompressionWriter = compressNoContextTakeover
+		c.compressParams = tt.params
+		if err := c.WritePreparedMessage(pm); err != nil {
+			t.Fatal(err)
+		}
+		if compressed := buf.Bytes()[0]&rsv1Bit != 0; compressed != tt.compressed {
+			t.Errorf("%s: compressed = %t, want %t", tt.name, compressed, tt.compressed)
+		}
+		_, shared := pm.frames[prepareKey{isServer: true, compress: true, compressionLevel: defaultCompressionLevel, params: tt.params}]
+		if shared != tt.shared {
+			t.Errorf("%s: shared = %t, want %t", tt.name, shared, tt.shared)
+		}
+
+		r := newTestConn(&buf, nil, false)
+		r.newDecompressionReader = decompressNoContextTakeover
+		if _, p, err := r.ReadMessage(); err != nil || !bytes.Equal(p, data) {
+			t.Errorf("%s: ReadMessage() = %q, %v", tt.name, p, err)
+		}
+	}
+}
+
+func TestPreparedMessageFragments(t *testing.T) {
+	data := make([]byte, 10000)
+	rand.New(rand.NewSource(1)).Read(data)
+	tests := []struct {
+		name         string
+		isServer     bool
+		compress     bool
+		fragmentSize int
+		maxFrames    int
+		minFrames    int
+	}{
+		{"server", true, false, 1000, 10, 10},
+		{"client", false, false, 3000, 4, 4},
+		{"small message", true, false, 20000, 1, 1},
+		{"compressed", true, true, 1000, 11, 2},
+	}
+	for _, tt := range tests {
+		// Build the payload in parts.
+		w := NewPreparedMessageWriter(BinaryMessage)
+		w.FragmentSize = tt.fragmentSize
+		w.Write(data[:100])
+		w.ReadFrom(bytes.NewReader(data[100:]))
+		pm, err := w.PreparedMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		c := newTestConn(nil, &buf, tt.isServer)
+		if tt.compress {
+			c.newCompressionWriter = compressNoContextTakeover
+		}
+		if err := c.WritePreparedMessage(pm); err != nil {
+			t.Fatal(err)
+		}
+
+		frames := 0
+		for p := buf.Bytes(); ; {
+			hdr, length, ok := parseFrameHeader(p)
+			if !ok {
+				break
+			}
+			if length > int64(tt.fragmentSize) {
+				t.Errorf("%s: frame of %d bytes, want at most %d", tt.name, length, tt.fragmentSize)
+			}
+			frames++
+			p = p[int64(hdr)+length:]
+		}
+		if frames < tt.minFrames || frames > tt.maxFrames {
+			t.Errorf("%s: %d frames, want %d to %d", tt.name, frames, tt.minFrames, tt.maxFrames)
+		}
+
+		r := newTestConn(&buf, nil, !tt.isServer)
+		r.newDecompressionReader = decompressNoContextTakeover
+		if _, p, err := r.ReadMessage(); err != nil || !bytes.Equal(p, data) {
+			t.Errorf("%s: ReadMessage() error = %v, equal = %t", tt.name, err, bytes.Equal(p, data))
+		}
+	}
+}
+
+func TestNewPreparedMessageFromReader(t *testing.T) {
+	pm, err := NewPreparedMessageFromReader(TextMessage, bytes.NewReader([]byte("hello")), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := newTestConn(nil, &buf, true).WritePreparedMessage(pm); err != nil {
+		t.Fatal(err)
+	}
+	if _, p, err := newTestConn(&buf, nil, false).ReadMessage(); err != nil || string(p) != "hello" {
+		t.Errorf("ReadMessage() = %q, %v", p, err)
+	}
+	if _, err := NewPreparedMessageFromReader(PingMessage, bytes.NewReader([]byte("hello")), 2); err != errFragmentControl {
+		t.Errorf("NewPreparedMessageFromReader(PingMessage) error = %v, want %v", err, errFragmentControl)
+	}
+}
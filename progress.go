@@ -0,0 +1,55 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+// Progress is the progress of reading or writing a data message.
+type Progress struct {
+	// MessageType is TextMessage or BinaryMessage.
+	MessageType int
+
+	// Transferred is the number of payload bytes of the message read from
+	// or written to the network connection. For compressed messages, the
+	// bytes are counted before decompression and after compression.
+	Transferred int64
+
+	// Total is the payload size of the message, or -1 if the size is not
+	// known. The size is known for messages written with WriteMessage
+	// without compression and for messages read in a single frame.
+	Total int64
+}
+
+// SetReadProgressHandler sets the handler that is called as the payload of
+// a data message is read from the network connection. The handler is called
+// from the message reader's Read method. Set a nil handler to stop
+// reporting progress.
+func (c *Conn) SetReadProgressHandler(h func(p Progress)) {
+	c.readProgress = h
+}
+
+// SetWriteProgressHandler sets the handler that is called each time a frame
+// of a data message is written to the network connection. The handler is
+// called from the write methods. Set a nil handler to stop reporting
+// progress.
+func (c *Conn) SetWriteProgressHandler(h func(p Progress)) {
+	c.writeProgress = h
+}
+
+// progressed reports n more bytes read by r.
+func (r *messageReader) progressed(n int) {
+	r.transferred += int64(n)
+	if n == 0 || r.c.readProgress == nil {
+		return
+	}
+	r.c.readProgress(Progress{MessageType: r.messageType, Transferred: r.transferred, Total: r.total})
+}
+
+// progressed reports a flushed frame of n payload bytes.
+func (w *messageWriter) progressed(n int) {
+	c := w.c
+	if n == 0 || c.writeProgress == nil || !isData(w.messageType) {
+		return
+	}
+	c.writeProgress(Progress{MessageType: w.messageType, Transferred: w.wireSize, Total: w.total})
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	client, server := (&PipeConfig{WriteBufferSize: 1024}).Pipe()
+	data := bytes.Repeat([]byte("x"), 10000)
+
+	var writes, reads []Progress
+	server.SetWriteProgressHandler(func(p Progress) { writes = append(writes, p) })
+	client.SetReadProgressHandler(func(p Progress) { reads = append(reads, p) })
+
+	tests := []struct {
+		name  string
+		write func() error
+		total int64
+	}{
+		{"WriteMessage", func() error { return server.WriteMessage(BinaryMessage, data) }, 10000},
+		{"NextWriter", func() error {
+			w, err := server.NextWriter(BinaryMessage)
+			if err != nil {
+				return err
+			}
+			for i := 0; i < len(data); i += 1000 {
+				w.Write(data[i : i+1000])
+			}
+			return w.Close()
+		}, -1},
+	}
+	for _, tt := range tests {
+		writes, reads = nil, nil
+		if err := tt.write(); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		_, r, err := client.NextReader()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.Copy(io.Discard, r); err != nil {
+			t.Fatal(err)
+		}
+		for side, events := range map[string][]Progress{"write": writes, "read": reads} {
+			if len(events) == 0 {
+				t.Errorf("%s: no %s progress", tt.name, side)
+				continue
+			}
+			last := events[len(events)-1]
+			if last.Transferred != 10000 || last.MessageType != BinaryMessage {
+				t.Errorf("%s: last %s progress = %+v, want 10000 binary bytes", tt.name, side, last)
+			}
+			for i, p := range events {
+				if p.Total != tt.total {
+					t.Errorf("%s: %s progress total = %d, want %d", tt.name, side, p.Total, tt.total)
+				}
+				if i > 0 && p.Transferred <= events[i-1].Transferred {
+					t.Errorf("%s: %s progress not increasing: %+v", tt.name, side, events)
+					break
+				}
+			}
+		}
+		if tt.total == -1 && len(writes) < 2 {
+			t.Errorf("%s: %d write progress events, want one per frame", tt.name, len(writes))
+		}
+	}
+
+	// Control messages do not report progress.
+	writes = nil
+	server.WriteMessage(PingMessage, nil)
+	if len(writes) != 0 {
+		t.Errorf("ping reported write progress %+v", writes)
+	}
+}
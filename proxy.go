@@ -9,15 +9,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
-	"errors"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
-	"strings"
 
 	"golang.org/x/net/proxy"
 )
 
+// maxProxyErrorBody is the maximum size of the body of a rejected CONNECT
+// request that is read to reuse the connection to the proxy.
+const maxProxyErrorBody = 4096
+
 type netDialerFunc func(ctx context.Context, network, addr string) (net.Conn, error)
 
 func (fn netDialerFunc) Dial(network, addr string) (net.Conn, error) {
@@ -28,9 +31,9 @@ func (fn netDialerFunc) DialContext(ctx context.Context, network, addr string) (
 	return fn(ctx, network, addr)
 }
 
-func proxyFromURL(proxyURL *url.URL, forwardDial netDialerFunc) (netDialerFunc, error) {
+func proxyFromURL(proxyURL *url.URL, forwardDial netDialerFunc, pool *ProxyPool, pipeline bool) (netDialerFunc, error) {
 	if proxyURL.Scheme == "http" {
-		return (&httpProxyDialer{proxyURL: proxyURL, forwardDial: forwardDial}).DialContext, nil
+		return (&httpProxyDialer{proxyURL: proxyURL, forwardDial: forwardDial, pool: pool, pipeline: pipeline}).DialContext, nil
 	}
 	dialer, err := proxy.FromURL(proxyURL, forwardDial)
 	if err != nil {
@@ -47,14 +50,28 @@ func proxyFromURL(proxyURL *url.URL, forwardDial netDialerFunc) (netDialerFunc,
 type httpProxyDialer struct {
 	proxyURL    *url.URL
 	forwardDial netDialerFunc
+	pool        *ProxyPool // optional pool of idle proxy connections
+	pipeline    bool       // don't wait for the CONNECT response
 }
 
 func (hpd *httpProxyDialer) DialContext(ctx context.Context, network string, addr string) (net.Conn, error) {
 	hostPort, _ := hostPortNoPort(hpd.proxyURL)
-	conn, err := hpd.forwardDial(ctx, network, hostPort)
-	if err != nil {
-		return nil, err
+	conn := hpd.pool.get(hostPort)
+	if conn != nil {
+		if deadline, ok := ctx.Deadline(); ok {
+			if err := conn.SetDeadline(deadline); err != nil {
+				conn.Close()
+				return nil, err
+			}
+		}
+	} else {
+		var err error
+		conn, err = hpd.forwardDial(ctx, network, hostPort)
+		if err != nil {
+			return nil, err
+		}
 	}
+	hpd.pool.refill(network, hostPort, hpd.forwardDial)
 
 	connectHeader := make(http.Header)
 	if user := hpd.proxyURL.User; user != nil {
@@ -77,6 +94,10 @@ func (hpd *httpProxyDialer) DialContext(ctx context.Context, network string, add
 		return nil, err
 	}
 
+	if hpd.pipeline {
+		return &pipelinedConn{Conn: conn, br: bufio.NewReader(conn), req: connectReq}, nil
+	}
+
 	// Read response. It's OK to use and discard buffered reader here because
 	// the remote server does not speak until spoken to.
 	br := bufio.NewReader(conn)
@@ -93,13 +114,21 @@ func (hpd *httpProxyDialer) DialContext(ctx context.Context, network string, add
 	// http.ReadResponse to inspect trailers or read another response from the
 	// buffered reader. The call to resp.Body.Close() does not release
 	// resources.
+	if resp.StatusCode != http.StatusOK && hpd.pool != nil && !resp.Close &&
+		resp.ContentLength >= 0 && resp.ContentLength <= maxProxyErrorBody {
+		// Keep the connection if the proxy left it open for another
+		// request, for example after asking for credentials.
+		if _, err := io.Copy(io.Discard, resp.Body); err == nil && br.Buffered() == 0 {
+			hpd.pool.put(hostPort, conn)
+			return nil, connectError(resp)
+		}
+	}
 	br.Reset(bytes.NewReader(nil))
 	_ = resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	if err := connectError(resp); err != nil {
 		_ = conn.Close()
-		f := strings.SplitN(resp.Status, " ", 2)
-		return nil, errors.New(f[1])
+		return nil, err
 	}
 	return conn, nil
 }
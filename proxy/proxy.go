@@ -0,0 +1,312 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package proxy implements a WebSocket reverse proxy.
+//
+// ReverseProxy is the WebSocket counterpart of httputil.ReverseProxy. It
+// dials the upstream server, upgrades the client's request and forwards
+// messages in both directions. Ping, pong and close messages are forwarded
+// with their payloads, so close codes reach the other side.
+//
+//	target, _ := url.Parse("ws://backend.internal:8080")
+//	http.Handle("/ws", proxy.NewSingleHostReverseProxy(target))
+package proxy
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Direction is the direction in which a message is forwarded.
+type Direction int
+
+const (
+	// Upstream is the direction from the client to the upstream server.
+	Upstream Direction = iota
+
+	// Downstream is the direction from the upstream server to the client.
+	Downstream
+)
+
+func (d Direction) String() string {
+	if d == Upstream {
+		return "upstream"
+	}
+	return "downstream"
+}
+
+// ErrDropMessage is returned from ModifyMessage to drop the message.
+var ErrDropMessage = errors.New("proxy: drop message")
+
+const (
+	writeWait        = 10 * time.Second
+	closeGracePeriod = time.Second
+)
+
+// ReverseProxy is an http.Handler that proxies websocket connections to an
+// upstream server.
+type ReverseProxy struct {
+	// Director modifies the upstream request. The request is a clone of the
+	// incoming request with the hop-by-hop and websocket handshake headers
+	// removed. Director must set the URL to the upstream websocket URL. If
+	// req.Host is not empty, it is sent as the Host header.
+	Director func(req *http.Request)
+
+	// Dialer dials the upstream server. If Dialer is nil,
+	// websocket.DefaultDialer is used. Dialer.Subprotocols should be empty so
+	// that the client's subprotocols are forwarded.
+	Dialer *websocket.Dialer
+
+	// Upgrader upgrades the incoming request. If Upgrader is nil, an
+	// Upgrader that accepts all origins is used; the Origin header is
+	// forwarded to the upstream server for checking. Upgrader.Subprotocols
+	// should be nil so that the subprotocol selected by the upstream server
+	// is used.
+	Upgrader *websocket.Upgrader
+
+	// ModifyResponse modifies the upstream handshake response. The response
+	// headers, except hop-by-hop and websocket handshake headers, are sent to
+	// the client. If ModifyResponse returns an error, ErrorHandler is called.
+	ModifyResponse func(resp *http.Response) error
+
+	// ModifyMessage inspects or modifies a data message before it is
+	// forwarded. The returned data is forwarded. If ModifyMessage returns
+	// ErrDropMessage, the message is dropped. Other errors close both
+	// connections with the close code 1011 (internal server error). If
+	// ModifyMessage is nil, messages are streamed without buffering.
+	ModifyMessage func(req *http.Request, dir Direction, messageType int, data []byte) ([]byte, error)
+
+	// ErrorHandler replies to the client when the upstream connection cannot
+	// be established. If ErrorHandler is nil, the status code of the upstream
+	// handshake response or 502 (Bad Gateway) is sent.
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// NewSingleHostReverseProxy returns a ReverseProxy that proxies to target.
+// The request path is appended to the target path. An http or https target
+// scheme is changed to ws or wss.
+func NewSingleHostReverseProxy(target *url.URL) *ReverseProxy {
+	return &ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = target.Scheme
+			switch target.Scheme {
+			case "http":
+				req.URL.Scheme = "ws"
+			case "https":
+				req.URL.Scheme = "wss"
+			}
+			req.URL.Host = target.Host
+			req.URL.Path = singleJoiningSlash(target.Path, req.URL.Path)
+			req.URL.RawPath = ""
+			if target.RawQuery == "" || req.URL.RawQuery == "" {
+				req.URL.RawQuery = target.RawQuery + req.URL.RawQuery
+			} else {
+				req.URL.RawQuery = target.RawQuery + "&" + req.URL.RawQuery
+			}
+		},
+	}
+}
+
+func singleJoiningSlash(a, b string) string {
+	aslash := strings.HasSuffix(a, "/")
+	bslash := strings.HasPrefix(b, "/")
+	switch {
+	case aslash && bslash:
+		return a + b[1:]
+	case !aslash && !bslash:
+		return a + "/" + b
+	}
+	return a + b
+}
+
+// removedHeaders are not forwarded in either direction. Keys are in
+// canonical form.
+var removedHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Connection",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+	"Sec-Websocket-Key",
+	"Sec-Websocket-Version",
+	"Sec-Websocket-Extensions",
+	"Sec-Websocket-Accept",
+}
+
+func removeHeaders(h http.Header) {
+	for _, f := range h["Connection"] {
+		for _, k := range strings.Split(f, ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				h.Del(k)
+			}
+		}
+	}
+	for _, k := range removedHeaders {
+		h.Del(k)
+	}
+}
+
+func (p *ReverseProxy) error(w http.ResponseWriter, r *http.Request, resp *http.Response, err error) {
+	if p.ErrorHandler != nil {
+		p.ErrorHandler(w, r, err)
+		return
+	}
+	status := http.StatusBadGateway
+	if resp != nil && resp.StatusCode != http.StatusSwitchingProtocols && resp.StatusCode >= 400 {
+		status = resp.StatusCode
+	}
+	http.Error(w, http.StatusText(status), status)
+}
+
+// ServeHTTP proxies the websocket connection. The upstream connection is
+// established before the client's request is upgraded.
+func (p *ReverseProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !websocket.IsWebSocketUpgrade(r) {
+		http.Error(w, "proxy: not a websocket handshake", http.StatusBadRequest)
+		return
+	}
+
+	out := r.Clone(r.Context())
+	removeHeaders(out.Header)
+	if ip, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		if prior := out.Header.Values("X-Forwarded-For"); len(prior) > 0 {
+			ip = strings.Join(prior, ", ") + ", " + ip
+		}
+		out.Header.Set("X-Forwarded-For", ip)
+	}
+	out.Header.Set("X-Forwarded-Host", r.Host)
+	if r.TLS != nil {
+		out.Header.Set("X-Forwarded-Proto", "https")
+	} else {
+		out.Header.Set("X-Forwarded-Proto", "http")
+	}
+	if p.Director != nil {
+		p.Director(out)
+	}
+	if out.Host != "" {
+		out.Header.Set("Host", out.Host)
+	}
+
+	dialer := p.Dialer
+	if dialer == nil {
+		dialer = websocket.DefaultDialer
+	}
+	upstream, resp, err := dialer.DialContext(r.Context(), out.URL.String(), out.Header)
+	if err != nil {
+		p.error(w, r, resp, err)
+		return
+	}
+	defer upstream.Close()
+	if p.ModifyResponse != nil {
+		if err := p.ModifyResponse(resp); err != nil {
+			p.error(w, r, nil, err)
+			return
+		}
+	}
+
+	header := resp.Header.Clone()
+	removeHeaders(header)
+	upgrader := p.Upgrader
+	if upgrader == nil {
+		upgrader = &websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+	}
+	client, err := upgrader.Upgrade(w, r, header)
+	if err != nil {
+		return
+	}
+	defer client.Close()
+
+	forwardControl(client, upstream)
+	forwardControl(upstream, client)
+	errc := make(chan error, 2)
+	client.Go(func() { errc <- p.pump(r, Upstream, client, upstream) })
+	client.Go(func() { errc <- p.pump(r, Downstream, upstream, client) })
+	<-errc
+
+	// Give the other direction time to complete the close handshake.
+	deadline := time.Now().Add(closeGracePeriod)
+	client.SetReadDeadline(deadline)
+	upstream.SetReadDeadline(deadline)
+	<-errc
+}
+
+// forwardControl forwards ping and pong messages received on src to dst.
+func forwardControl(src, dst *websocket.Conn) {
+	src.SetPingHandler(func(data string) error {
+		dst.WriteControl(websocket.PingMessage, []byte(data), time.Now().Add(writeWait))
+		return nil
+	})
+	src.SetPongHandler(func(data string) error {
+		dst.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(writeWait))
+		return nil
+	})
+}
+
+// pump forwards data messages from src to dst until src ends and then
+// forwards the close message.
+func (p *ReverseProxy) pump(r *http.Request, dir Direction, src, dst *websocket.Conn) error {
+	for {
+		err := p.forwardMessage(r, dir, src, dst)
+		if err == nil {
+			continue
+		}
+		var ce *websocket.CloseError
+		switch {
+		case errors.As(err, &ce):
+			if ce.Code != websocket.CloseAbnormalClosure && ce.Code != websocket.CloseTLSHandshake {
+				dst.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(ce.Code, ce.Text),
+					time.Now().Add(writeWait))
+			}
+		case errors.Is(err, errModify):
+			msg := websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "")
+			dst.WriteControl(websocket.CloseMessage, msg, time.Now().Add(writeWait))
+			src.WriteControl(websocket.CloseMessage, msg, time.Now().Add(writeWait))
+		}
+		return err
+	}
+}
+
+var errModify = errors.New("proxy: ModifyMessage failed")
+
+func (p *ReverseProxy) forwardMessage(r *http.Request, dir Direction, src, dst *websocket.Conn) error {
+	if p.ModifyMessage == nil {
+		messageType, rd, err := src.NextReader()
+		if err != nil {
+			return err
+		}
+		w, err := dst.NextWriter(messageType)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, rd); err != nil {
+			return err
+		}
+		return w.Close()
+	}
+
+	messageType, data, err := src.ReadMessage()
+	if err != nil {
+		return err
+	}
+	data, err = p.ModifyMessage(r, dir, messageType, data)
+	if err == ErrDropMessage {
+		return nil
+	}
+	if err != nil {
+		return errors.Join(errModify, err)
+	}
+	return dst.WriteMessage(messageType, data)
+}
@@ -0,0 +1,190 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package proxy
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newUpstream starts an upstream server. Text messages starting with
+// "close " close the connection with the rest of the message as the reason
+// and code 4001. Other messages are echoed. The request headers are sent to
+// headers.
+func newUpstream(t *testing.T, headers chan<- http.Header) *url.URL {
+	upgrader := websocket.Upgrader{Subprotocols: []string{"chat"}}
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/forbidden" {
+			http.Error(w, "no", http.StatusForbidden)
+			return
+		}
+		if headers != nil {
+			headers <- r.Header.Clone()
+		}
+		ws, err := upgrader.Upgrade(w, r, http.Header{"Set-Cookie": {"a=b"}})
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		for {
+			mt, p, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			if reason, ok := strings.CutPrefix(string(p), "close "); ok {
+				ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(4001, reason))
+				continue
+			}
+			ws.WriteMessage(mt, p)
+		}
+	}))
+	t.Cleanup(hs.Close)
+	u, _ := url.Parse(hs.URL)
+	return u
+}
+
+func dialProxy(t *testing.T, p *ReverseProxy, path string, header http.Header) (*websocket.Conn, *http.Response, error) {
+	hs := httptest.NewServer(p)
+	t.Cleanup(hs.Close)
+	d := websocket.Dialer{Subprotocols: []string{"chat"}}
+	ws, resp, err := d.Dial("ws"+strings.TrimPrefix(hs.URL, "http")+path, header)
+	if ws != nil {
+		t.Cleanup(func() { ws.Close() })
+	}
+	return ws, resp, err
+}
+
+func TestProxy(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	p := NewSingleHostReverseProxy(newUpstream(t, headers))
+	ws, resp, err := dialProxy(t, p, "/echo", http.Header{"X-Custom": {"1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := <-headers
+	if h.Get("X-Custom") != "1" || h.Get("X-Forwarded-For") != "127.0.0.1" || h.Get("X-Forwarded-Proto") != "http" {
+		t.Errorf("upstream request headers = %v", h)
+	}
+	if ws.Subprotocol() != "chat" {
+		t.Errorf("Subprotocol() = %q, want chat", ws.Subprotocol())
+	}
+	if resp.Header.Get("Set-Cookie") != "a=b" {
+		t.Errorf("Set-Cookie = %q, want a=b", resp.Header.Get("Set-Cookie"))
+	}
+
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for _, m := range []struct {
+		mt   int
+		data []byte
+	}{
+		{websocket.TextMessage, []byte("hello")},
+		{websocket.BinaryMessage, bytes.Repeat([]byte{1, 2, 3}, 100000)},
+	} {
+		ws.WriteMessage(m.mt, m.data)
+		mt, p, err := ws.ReadMessage()
+		if err != nil || mt != m.mt || !bytes.Equal(p, m.data) {
+			t.Fatalf("ReadMessage() = %d, %d bytes, %v", mt, len(p), err)
+		}
+	}
+
+	// Pings are forwarded to the upstream server, which answers with a
+	// pong.
+	pong := make(chan string, 1)
+	ws.SetPongHandler(func(data string) error {
+		pong <- data
+		return nil
+	})
+	ws.WriteControl(websocket.PingMessage, []byte("p1"), time.Now().Add(time.Second))
+	go ws.ReadMessage()
+	select {
+	case data := <-pong:
+		if data != "p1" {
+			t.Errorf("pong = %q, want p1", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no pong")
+	}
+}
+
+func TestProxyCloseCode(t *testing.T) {
+	p := NewSingleHostReverseProxy(newUpstream(t, nil))
+	ws, _, err := dialProxy(t, p, "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	ws.WriteMessage(websocket.TextMessage, []byte("close bye"))
+	_, _, err = ws.ReadMessage()
+	if !websocket.IsCloseError(err, 4001) || err.(*websocket.CloseError).Text != "bye" {
+		t.Errorf("ReadMessage() error = %v, want close 4001 bye", err)
+	}
+}
+
+func TestProxyModifyMessage(t *testing.T) {
+	p := NewSingleHostReverseProxy(newUpstream(t, nil))
+	p.ModifyMessage = func(r *http.Request, dir Direction, mt int, data []byte) ([]byte, error) {
+		switch string(data) {
+		case "drop":
+			return nil, ErrDropMessage
+		case "fail":
+			return nil, errors.New("bad message")
+		}
+		if dir == Downstream {
+			return bytes.ToUpper(data), nil
+		}
+		return data, nil
+	}
+	ws, _, err := dialProxy(t, p, "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	ws.WriteMessage(websocket.TextMessage, []byte("drop"))
+	ws.WriteMessage(websocket.TextMessage, []byte("hello"))
+	if _, p, err := ws.ReadMessage(); err != nil || string(p) != "HELLO" {
+		t.Fatalf("ReadMessage() = %q, %v, want HELLO", p, err)
+	}
+	ws.WriteMessage(websocket.TextMessage, []byte("fail"))
+	if _, _, err := ws.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseInternalServerErr) {
+		t.Errorf("ReadMessage() error = %v, want close 1011", err)
+	}
+}
+
+func TestProxyUpstreamError(t *testing.T) {
+	u := newUpstream(t, nil)
+	p := NewSingleHostReverseProxy(u)
+	if _, resp, err := dialProxy(t, p, "/forbidden", nil); err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Dial forbidden: %v, want status 403", err)
+	}
+
+	u.Host = "127.0.0.1:1"
+	if _, resp, err := dialProxy(t, NewSingleHostReverseProxy(u), "/", nil); err == nil || resp == nil || resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("Dial unreachable: %v, want status 502", err)
+	}
+}
+
+func TestSingleJoiningSlash(t *testing.T) {
+	tests := []struct{ a, b, want string }{
+		{"", "/x", "/x"},
+		{"/base", "/x", "/base/x"},
+		{"/base/", "/x", "/base/x"},
+		{"/base/", "x", "/base/x"},
+		{"/base", "x", "/base/x"},
+	}
+	for _, tt := range tests {
+		if got := singleJoiningSlash(tt.a, tt.b); got != tt.want {
+			t.Errorf("singleJoiningSlash(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProxyPool keeps connections to HTTP proxies ready for CONNECT requests to
+// cut the connection setup latency of clients that open many connections
+// through the same proxy. Set the same pool on the dialers that share the
+// proxies.
+//
+// A connection becomes a tunnel to the server when the proxy accepts the
+// CONNECT request, so tunnels are never reused. The pool keeps connections
+// that the proxy left open after rejecting a CONNECT request, for example
+// with http.StatusProxyAuthRequired, and connections dialed in advance to
+// keep MinIdle connections ready.
+//
+// A ProxyPool is safe for concurrent use.
+type ProxyPool struct {
+	// MinIdle is the number of idle connections to keep ready for each
+	// proxy. The pool dials new connections in the background after a
+	// dial through the proxy. If MinIdle is zero, connections are not
+	// dialed in advance.
+	MinIdle int
+
+	// MaxIdle is the maximum number of idle connections for each proxy. If
+	// MaxIdle is zero, the larger of MinIdle and 2 is used.
+	MaxIdle int
+
+	// IdleTimeout is the maximum time that a connection stays idle in the
+	// pool. If IdleTimeout is zero, 90 seconds is used.
+	IdleTimeout time.Duration
+
+	// DialTimeout is the maximum time of a dial in the background. If
+	// DialTimeout is zero, 30 seconds is used.
+	DialTimeout time.Duration
+
+	// Pipeline specifies if the dialer starts the TLS handshake of wss
+	// connections without waiting for the response to the CONNECT
+	// request. Pipelining saves a round trip to the proxy. The TLS
+	// handshake fails with the error of the CONNECT request if the proxy
+	// rejects the request. Requests for ws URLs are never pipelined
+	// because the proxy would read the handshake request as the next
+	// request on the connection.
+	Pipeline bool
+
+	mu      sync.Mutex
+	idle    map[string][]idleProxyConn
+	dialing map[string]int
+	ctx     context.Context // context of background dials, canceled by CloseIdle
+	cancel  context.CancelFunc
+}
+
+type idleProxyConn struct {
+	conn net.Conn
+	t    time.Time
+}
+
+const (
+	defaultMaxIdleProxyConns = 2
+	defaultProxyIdleTimeout  = 90 * time.Second
+	defaultProxyDialTimeout  = 30 * time.Second
+)
+
+func (p *ProxyPool) maxIdle() int {
+	if p.MaxIdle > 0 {
+		return p.MaxIdle
+	}
+	if p.MinIdle > defaultMaxIdleProxyConns {
+		return p.MinIdle
+	}
+	return defaultMaxIdleProxyConns
+}
+
+func (p *ProxyPool) idleTimeout() time.Duration {
+	if p.IdleTimeout > 0 {
+		return p.IdleTimeout
+	}
+	return defaultProxyIdleTimeout
+}
+
+func (p *ProxyPool) dialTimeout() time.Duration {
+	if p.DialTimeout > 0 {
+		return p.DialTimeout
+	}
+	return defaultProxyDialTimeout
+}
+
+// get returns an idle connection to the proxy at addr or nil.
+func (p *ProxyPool) get(addr string) net.Conn {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	conns := p.idle[addr]
+	for len(conns) > 0 {
+		ic := conns[len(conns)-1]
+		conns = conns[:len(conns)-1]
+		p.idle[addr] = conns
+		if time.Since(ic.t) < p.idleTimeout() {
+			return ic.conn
+		}
+		ic.conn.Close()
+	}
+	return nil
+}
+
+// put adds an idle connection to the proxy at addr to the pool. The
+// connection is closed if the pool is full.
+func (p *ProxyPool) put(addr string, conn net.Conn) {
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.putLocked(addr, conn)
+}
+
+// putDialed adds a connection dialed in the background with ctx to the
+// pool. The connection is closed if CloseIdle canceled the dial.
+func (p *ProxyPool) putDialed(ctx context.Context, addr string, conn net.Conn) {
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ctx.Err() != nil {
+		conn.Close()
+		return
+	}
+	p.putLocked(addr, conn)
+}
+
+func (p *ProxyPool) putLocked(addr string, conn net.Conn) {
+	if len(p.idle[addr]) >= p.maxIdle() {
+		conn.Close()
+		return
+	}
+	if p.idle == nil {
+		p.idle = make(map[string][]idleProxyConn)
+	}
+	p.idle[addr] = append(p.idle[addr], idleProxyConn{conn: conn, t: time.Now()})
+}
+
+// refill dials connections to the proxy at addr in the background until
+// the pool has MinIdle idle connections.
+func (p *ProxyPool) refill(network, addr string, dial netDialerFunc) {
+	if p == nil || p.MinIdle <= 0 {
+		return
+	}
+	p.mu.Lock()
+	n := p.MinIdle - len(p.idle[addr]) - p.dialing[addr]
+	if n > 0 {
+		if p.dialing == nil {
+			p.dialing = make(map[string]int)
+		}
+		p.dialing[addr] += n
+		if p.ctx == nil {
+			p.ctx, p.cancel = context.WithCancel(context.Background())
+		}
+	}
+	ctx := p.ctx
+	p.mu.Unlock()
+	for i := 0; i < n; i++ {
+		go func() {
+			dialCtx, cancel := context.WithTimeout(ctx, p.dialTimeout())
+			conn, err := dial(dialCtx, network, addr)
+			cancel()
+			p.mu.Lock()
+			p.dialing[addr]--
+			p.mu.Unlock()
+			if err == nil {
+				p.putDialed(ctx, addr, conn)
+			}
+		}()
+	}
+}
+
+// CloseIdle closes the idle connections in the pool and cancels the dials
+// in the background.
+func (p *ProxyPool) CloseIdle() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	if p.cancel != nil {
+		p.cancel()
+		p.ctx, p.cancel = nil, nil
+	}
+	p.mu.Unlock()
+	for _, conns := range idle {
+		for _, ic := range conns {
+			ic.conn.Close()
+		}
+	}
+}
+
+// pipelinedConn is a connection to a proxy with a CONNECT request in
+// flight. The first read consumes the response to the request.
+type pipelinedConn struct {
+	net.Conn
+	br   *bufio.Reader
+	req  *http.Request
+	once sync.Once
+	err  error
+}
+
+func (c *pipelinedConn) Read(p []byte) (int, error) {
+	c.once.Do(func() {
+		resp, err := http.ReadResponse(c.br, c.req)
+		if err != nil {
+			c.err = err
+			return
+		}
+		// The body of a successful response is empty. Don't close the
+		// body of other responses because Close reads the body.
+		if c.err = connectError(resp); c.err == nil {
+			_ = resp.Body.Close()
+		}
+	})
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.br.Read(p)
+}
+
+// connectError returns an error for a response to a CONNECT request that is
+// not successful.
+func connectError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	f := strings.SplitN(resp.Status, " ", 2)
+	if len(f) < 2 {
+		return errors.New(resp.Status)
+	}
+	return errors.New(f[1])
+}
@@ -0,0 +1,223 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProxyPoolReuseAfterReject(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+
+	var mu sync.Mutex
+	conns := make(map[string]bool)
+	origHandler := s.Server.Config.Handler
+	s.Server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodConnect {
+			mu.Lock()
+			conns[r.RemoteAddr] = true
+			mu.Unlock()
+			if r.Header.Get("Proxy-Authorization") == "" {
+				w.Header().Set("Proxy-Authenticate", "Basic")
+				http.Error(w, "no credentials", http.StatusProxyAuthRequired)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		origHandler.ServeHTTP(w, r)
+	})
+
+	pool := &ProxyPool{}
+	defer pool.CloseIdle()
+	purl, _ := url.Parse(s.Server.URL)
+	d := cstDialer
+	d.ProxyPool = pool
+	d.Proxy = http.ProxyURL(purl)
+	if _, _, err := d.Dial(s.URL, nil); err == nil || err.Error() != "Proxy Authentication Required" {
+		t.Fatalf("Dial() error = %v, want Proxy Authentication Required", err)
+	}
+
+	purl.User = url.UserPassword("username", "password")
+	ws, _, err := d.Dial(s.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	sendRecv(t, ws)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(conns) != 1 {
+		t.Errorf("proxy connections = %v, want 1", conns)
+	}
+}
+
+func TestProxyPoolMinIdle(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+
+	connects := make(chan string, 2)
+	origHandler := s.Server.Config.Handler
+	s.Server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodConnect {
+			connects <- r.RemoteAddr
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		origHandler.ServeHTTP(w, r)
+	})
+
+	var mu sync.Mutex
+	var dialed []string
+	pool := &ProxyPool{MinIdle: 1, MaxIdle: 1}
+	defer pool.CloseIdle()
+	purl, _ := url.Parse(s.Server.URL)
+	d := cstDialer
+	d.ProxyPool = pool
+	d.Proxy = http.ProxyURL(purl)
+	d.NetDialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		c, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+		if err == nil {
+			mu.Lock()
+			dialed = append(dialed, c.LocalAddr().String())
+			mu.Unlock()
+		}
+		return c, err
+	}
+	idle := func() int {
+		pool.mu.Lock()
+		defer pool.mu.Unlock()
+		return len(pool.idle[purl.Host])
+	}
+
+	ws, _, err := d.Dial(s.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.Close()
+	<-connects
+	for idle() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The second dial uses the connection dialed in advance.
+	ws, _, err = d.Dial(s.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	sendRecv(t, ws)
+	mu.Lock()
+	defer mu.Unlock()
+	if got := <-connects; len(dialed) < 2 || got != dialed[1] {
+		t.Errorf("second CONNECT from %s, want %v", got, dialed)
+	}
+}
+
+func TestProxyPoolDialCancel(t *testing.T) {
+	errs := make(chan error, 2)
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		<-ctx.Done()
+		errs <- ctx.Err()
+		return nil, ctx.Err()
+	}
+
+	// Background dials time out.
+	pool := &ProxyPool{MinIdle: 1, DialTimeout: time.Millisecond}
+	pool.refill("tcp", "proxy.example:8080", dial)
+	if err := <-errs; err != context.DeadlineExceeded {
+		t.Errorf("dial error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// CloseIdle cancels background dials.
+	pool = &ProxyPool{MinIdle: 1}
+	pool.refill("tcp", "proxy.example:8080", dial)
+	pool.CloseIdle()
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("dial error = %v, want %v", err, context.Canceled)
+	}
+
+	var nilPool *ProxyPool
+	nilPool.CloseIdle()
+}
+
+// pipelineProxy is a CONNECT proxy that fails requests that are not
+// pipelined with the TLS handshake.
+func pipelineProxy(t *testing.T, status int) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				br := bufio.NewReader(c)
+				req, err := http.ReadRequest(br)
+				if err != nil || req.Method != http.MethodConnect {
+					return
+				}
+				// The TLS client hello must arrive before the response.
+				c.SetReadDeadline(time.Now().Add(time.Second))
+				if _, err := br.Peek(1); err != nil {
+					t.Errorf("TLS handshake was not pipelined: %v", err)
+					return
+				}
+				c.SetReadDeadline(time.Time{})
+				if status != http.StatusOK {
+					io.WriteString(c, "HTTP/1.1 403 Forbidden\r\nContent-Length: 0\r\n\r\n")
+					return
+				}
+				target, err := net.Dial("tcp", req.Host)
+				if err != nil {
+					return
+				}
+				defer target.Close()
+				io.WriteString(c, "HTTP/1.1 200 Connection established\r\n\r\n")
+				go io.Copy(target, br)
+				io.Copy(c, target)
+			}()
+		}
+	}()
+	return l
+}
+
+func TestProxyPoolPipeline(t *testing.T) {
+	s := newTLSServer(t)
+	defer s.Close()
+
+	for _, status := range []int{http.StatusOK, http.StatusForbidden} {
+		l := pipelineProxy(t, status)
+		d := cstDialer
+		d.TLSClientConfig = &tls.Config{RootCAs: rootCAs(t, s.Server)}
+		d.ProxyPool = &ProxyPool{Pipeline: true}
+		d.Proxy = http.ProxyURL(&url.URL{Scheme: "http", Host: l.Addr().String()})
+		ws, _, err := d.Dial(s.URL, nil)
+		switch {
+		case status == http.StatusOK && err != nil:
+			t.Errorf("Dial() error = %v", err)
+		case status == http.StatusOK:
+			sendRecv(t, ws)
+			ws.Close()
+		case err == nil || err.Error() != "Forbidden":
+			t.Errorf("Dial() error = %v, want Forbidden", err)
+		}
+		l.Close()
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Quirk is a set of workarounds for known-broken peers. Unlike Strictness,
+// which relaxes whole classes of checks, each quirk tolerates one specific
+// bug and is reported to the ConnTrace.QuirkTriggered hook the first time
+// the peer relies on it, so that the broken peers can be found and fixed:
+//
+//	upgrader.Quirks = websocket.QuirkUnmaskedFrames | websocket.QuirkShortClose
+type Quirk uint
+
+const (
+	// QuirkUnmaskedFrames accepts unmasked frames from clients.
+	QuirkUnmaskedFrames Quirk = 1 << iota
+
+	// QuirkShortClose accepts close messages with a 1 byte payload as
+	// close messages without a status code. Without this quirk, the
+	// connection fails with CloseProtocolError.
+	QuirkShortClose
+
+	// QuirkCloseTrailingBytes ignores the bytes after the first NUL in the
+	// reason of a close message, for peers that send the reason from a
+	// fixed size buffer.
+	QuirkCloseTrailingBytes
+)
+
+var quirkNames = []string{"unmasked_frames", "short_close", "close_trailing_bytes"}
+
+// String returns the names of the quirks in q separated by "|".
+func (q Quirk) String() string {
+	var names []string
+	for i, name := range quirkNames {
+		if q&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
+
+// SetQuirks sets the workarounds for bugs of the peer.
+func (c *Conn) SetQuirks(q Quirk) {
+	c.quirks = q
+}
+
+// useQuirk returns true if the quirk q is enabled and reports the first use
+// of q to the QuirkTriggered hook.
+func (c *Conn) useQuirk(q Quirk) bool {
+	if c.quirks&q == 0 {
+		return false
+	}
+	if c.quirksUsed&q == 0 {
+		c.quirksUsed |= q
+		if c.trace != nil && c.trace.QuirkTriggered != nil {
+			c.trace.QuirkTriggered(c, q)
+		}
+	}
+	return true
+}
+
+// trimCloseText removes the bytes after the first NUL in the reason of a
+// close message if QuirkCloseTrailingBytes is enabled.
+func (c *Conn) trimCloseText(p []byte) []byte {
+	if i := bytes.IndexByte(p, 0); i >= 0 && c.useQuirk(QuirkCloseTrailingBytes) {
+		p = p[:i]
+	}
+	return p
+}
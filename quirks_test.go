@@ -0,0 +1,71 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQuirks(t *testing.T) {
+	tests := []struct {
+		name   string
+		quirks Quirk
+		frame  []byte
+		err    string
+		used   []Quirk
+	}{
+		{"unmasked", 0, []byte{0x81, 0x01, 'a'}, "websocket: bad MASK", nil},
+		{"unmasked quirk", QuirkUnmaskedFrames, []byte{0x81, 0x01, 'a', 0x81, 0x01, 'a'}, "", []Quirk{QuirkUnmaskedFrames}},
+		{"short close", 0, []byte{0x88, 0x81, 0, 0, 0, 0, 3}, "websocket: close payload of length 1", nil},
+		{"short close quirk", QuirkShortClose, []byte{0x88, 0x81, 0, 0, 0, 0, 3}, "websocket: close 1005 (no status)", []Quirk{QuirkShortClose}},
+		{"trailing bytes", QuirkShortClose, []byte{0x88, 0x86, 0, 0, 0, 0, 3, 0xe8, 'o', 'k', 0, 0xff}, "websocket: invalid utf8 payload in close frame", nil},
+		{"trailing bytes quirk", QuirkCloseTrailingBytes, []byte{0x88, 0x86, 0, 0, 0, 0, 3, 0xe8, 'o', 'k', 0, 0xff}, "websocket: close 1000 (normal): ok", []Quirk{QuirkCloseTrailingBytes}},
+		{"all quirks", QuirkUnmaskedFrames | QuirkShortClose, []byte{0x88, 0x01, 3}, "websocket: close 1005 (no status)", []Quirk{QuirkUnmaskedFrames, QuirkShortClose}},
+	}
+	for _, tt := range tests {
+		client, server := Pipe()
+		server.SetQuirks(tt.quirks)
+		var used []Quirk
+		server.trace = &ConnTrace{QuirkTriggered: func(c *Conn, q Quirk) { used = append(used, q) }}
+		go client.NetConn().Write(append(tt.frame, 0x81, 0x81, 0, 0, 0, 0, 'b'))
+		_, p, err := server.ReadMessage()
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: ReadMessage() error = %v", tt.name, err)
+		case tt.err == "" && string(p) != "a":
+			t.Errorf("%s: ReadMessage() = %q, want %q", tt.name, p, "a")
+		case tt.err != "" && (err == nil || err.Error() != tt.err):
+			t.Errorf("%s: ReadMessage() error = %v, want %s", tt.name, err, tt.err)
+		}
+		if tt.err == "" {
+			// Quirks are reported once per connection.
+			if _, _, err := server.ReadMessage(); err != nil {
+				t.Errorf("%s: second ReadMessage() error = %v", tt.name, err)
+			}
+		}
+		if !reflect.DeepEqual(used, tt.used) {
+			t.Errorf("%s: triggered quirks = %v, want %v", tt.name, used, tt.used)
+		}
+		client.Close()
+		server.Close()
+	}
+}
+
+func TestQuirkString(t *testing.T) {
+	tests := []struct {
+		q    Quirk
+		want string
+	}{
+		{0, "none"},
+		{QuirkShortClose, "short_close"},
+		{QuirkUnmaskedFrames | QuirkCloseTrailingBytes, "unmasked_frames|close_trailing_bytes"},
+	}
+	for _, tt := range tests {
+		if got := tt.q.String(); got != tt.want {
+			t.Errorf("%d.String() = %q, want %q", tt.q, got, tt.want)
+		}
+	}
+}
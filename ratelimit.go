@@ -0,0 +1,200 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// ErrRateLimit is returned when the peer exceeds the read rate limit set for
+// the connection and the limit's action is RateLimitClose.
+var ErrRateLimit = errors.New("websocket: read rate limit exceeded")
+
+// RateLimitAction specifies what a connection does when the peer exceeds the
+// read rate limit.
+type RateLimitAction int
+
+const (
+	// RateLimitDelay delays reading from the network connection until the
+	// peer is within the limit again. The peer is slowed down by TCP flow
+	// control.
+	RateLimitDelay RateLimitAction = iota
+
+	// RateLimitDrop discards messages that start while the peer exceeds the
+	// limit. The frames of discarded messages are read and skipped.
+	RateLimitDrop
+
+	// RateLimitClose sends a close message with ClosePolicyViolation to
+	// the peer and returns ErrRateLimit to the application.
+	RateLimitClose
+)
+
+// RateLimit limits the rate of data messages and payload bytes read from the
+// peer with token buckets. The rates are enforced when the frame headers are
+// read, before the payload is buffered by the application.
+type RateLimit struct {
+	// MessagesPerSecond is the sustained rate of data messages. Zero means
+	// no limit.
+	MessagesPerSecond float64
+
+	// MessageBurst is the number of messages that can be read at once after
+	// a quiet period. If MessageBurst is zero, MessagesPerSecond rounded up
+	// is used.
+	MessageBurst int
+
+	// BytesPerSecond is the sustained rate of data message payload bytes.
+	// Zero means no limit.
+	BytesPerSecond float64
+
+	// ByteBurst is the number of payload bytes that can be read at once
+	// after a quiet period. If ByteBurst is zero, BytesPerSecond rounded up
+	// is used. A frame larger than ByteBurst is accepted when the bucket is
+	// full and leaves the bucket in debt.
+	ByteBurst int
+
+	// Action specifies what happens when the peer exceeds the limit.
+	Action RateLimitAction
+}
+
+// tokenBucket is a token bucket that can go into debt.
+type tokenBucket struct {
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	b := float64(burst)
+	if b <= 0 {
+		b = math.Ceil(rate)
+	}
+	return &tokenBucket{rate: rate, burst: b, tokens: b, last: now}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if d := now.Sub(b.last); d > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+d.Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// allow reports whether n tokens are available. A request larger than the
+// burst is allowed when the bucket is full.
+func (b *tokenBucket) allow(n float64) bool {
+	return b.tokens >= math.Min(n, b.burst)
+}
+
+// wait returns the time until n tokens are available.
+func (b *tokenBucket) wait(n float64) time.Duration {
+	need := math.Min(n, b.burst) - b.tokens
+	if need <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(need / b.rate * float64(time.Second)))
+}
+
+type readRateLimiter struct {
+	action   RateLimitAction
+	messages *tokenBucket
+	bytes    *tokenBucket
+}
+
+// SetReadRateLimit limits the rate of data messages and payload bytes read
+// from the peer. A nil limit removes the limit. The token buckets start
+// full.
+func (c *Conn) SetReadRateLimit(limit *RateLimit) {
+	if limit == nil {
+		c.readRateLimiter = nil
+		return
+	}
+	now := c.clock.Now()
+	l := &readRateLimiter{
+		action:   limit.Action,
+		messages: newTokenBucket(limit.MessagesPerSecond, limit.MessageBurst, now),
+		bytes:    newTokenBucket(limit.BytesPerSecond, limit.ByteBurst, now),
+	}
+	if l.messages == nil && l.bytes == nil {
+		l = nil
+	}
+	c.readRateLimiter = l
+}
+
+// limitRead applies the read rate limit to a data frame whose header was
+// just read. It returns true if the frame must be skipped because its
+// message is dropped.
+func (c *Conn) limitRead(frameType int) (skip bool, err error) {
+	l := c.readRateLimiter
+	if frameType == continuationFrame && c.readDropping {
+		c.readDropping = !c.readFinal
+		return true, nil
+	}
+	if l == nil {
+		return false, nil
+	}
+
+	var msgs float64
+	if frameType != continuationFrame {
+		msgs = 1
+	}
+	size := float64(c.readRemaining)
+
+	for {
+		now := c.clock.Now()
+		var d time.Duration
+		if b := l.messages; b != nil && msgs > 0 {
+			b.refill(now)
+			if !b.allow(msgs) {
+				d = b.wait(msgs)
+			}
+		}
+		if b := l.bytes; b != nil {
+			b.refill(now)
+			if !b.allow(size) {
+				if w := b.wait(size); w > d {
+					d = w
+				}
+			}
+		}
+		if d == 0 {
+			break
+		}
+
+		switch {
+		case l.action == RateLimitClose:
+			_ = c.WriteControl(CloseMessage, FormatCloseMessage(ClosePolicyViolation, "rate limit exceeded"), c.clock.Now().Add(writeWait))
+			return false, c.reportViolation(ViolationRateLimit, ErrRateLimit)
+		case l.action == RateLimitDrop && frameType != continuationFrame:
+			// The payload was still sent by the peer. Charge the bytes so
+			// that a peer flooding the connection stays over the limit.
+			if l.bytes != nil {
+				l.bytes.tokens -= size
+			}
+			c.readDropping = !c.readFinal
+			return true, nil
+		case l.action == RateLimitDrop:
+			// The message was accepted by its first frame. Let it complete
+			// and drop the following messages instead.
+		default:
+			t := c.clock.NewTimer(d)
+			<-t.C()
+			continue
+		}
+		break
+	}
+
+	if l.messages != nil {
+		l.messages.tokens -= msgs
+	}
+	if l.bytes != nil {
+		l.bytes.tokens -= size
+	}
+	return false, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReadRateLimitDrop(t *testing.T) {
+	clock := newFakeClock()
+	client, server := (&PipeConfig{Clock: clock}).Pipe()
+	defer client.Close()
+	defer server.Close()
+	server.SetReadRateLimit(&RateLimit{MessagesPerSecond: 1, MessageBurst: 2, Action: RateLimitDrop})
+	server.SetPingHandler(func(string) error {
+		clock.mu.Lock()
+		clock.now = clock.now.Add(time.Second)
+		clock.mu.Unlock()
+		return nil
+	})
+
+	client.WriteMessage(TextMessage, []byte("a"))
+	client.WriteMessage(TextMessage, []byte("b"))
+	// A fragmented message with a zero masking key.
+	client.NetConn().Write([]byte{0x01, 0x81, 0, 0, 0, 0, 'c', 0x80, 0x81, 0, 0, 0, 0, 'c'})
+	client.WriteControl(PingMessage, nil, time.Time{})
+	client.WriteMessage(TextMessage, []byte("d"))
+
+	for _, want := range []string{"a", "b", "d"} {
+		_, p, err := server.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(p) != want {
+			t.Errorf("ReadMessage() = %q, want %q", p, want)
+		}
+	}
+}
+
+func TestReadRateLimitClose(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	server.SetReadRateLimit(&RateLimit{BytesPerSecond: 4, Action: RateLimitClose})
+
+	client.WriteMessage(BinaryMessage, []byte("abcd"))
+	client.WriteMessage(BinaryMessage, []byte("e"))
+	if _, _, err := server.ReadMessage(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := server.ReadMessage(); err != ErrRateLimit {
+		t.Fatalf("ReadMessage() error = %v, want %v", err, ErrRateLimit)
+	}
+	if _, _, err := client.ReadMessage(); !IsCloseError(err, ClosePolicyViolation) {
+		t.Errorf("client ReadMessage() error = %v, want close %d", err, ClosePolicyViolation)
+	}
+	server.Close()
+	if got, want := server.CloseReason().String(), "local 1008 rate_limit"; got != want {
+		t.Errorf("CloseReason() = %q, want %q", got, want)
+	}
+}
+
+func TestReadRateLimitDelay(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit RateLimit
+		wait  time.Duration
+	}{
+		{"messages", RateLimit{MessagesPerSecond: 2, MessageBurst: 1}, 500 * time.Millisecond},
+		{"bytes", RateLimit{BytesPerSecond: 4}, time.Second},
+	}
+	for _, tt := range tests {
+		clock := newFakeClock()
+		client, server := (&PipeConfig{Clock: clock}).Pipe()
+		server.SetReadRateLimit(&tt.limit)
+
+		client.WriteMessage(TextMessage, []byte("abcd"))
+		client.WriteMessage(TextMessage, []byte("efgh"))
+		if _, _, err := server.ReadMessage(); err != nil {
+			t.Fatal(err)
+		}
+		done := make(chan error, 1)
+		go func() {
+			_, _, err := server.ReadMessage()
+			done <- err
+		}()
+		clock.advance(t, tt.wait-time.Millisecond)
+		select {
+		case err := <-done:
+			t.Errorf("%s: ReadMessage() returned %v before the limit allowed", tt.name, err)
+		case <-time.After(10 * time.Millisecond):
+		}
+		clock.advance(t, time.Millisecond)
+		if err := <-done; err != nil {
+			t.Errorf("%s: ReadMessage() error = %v", tt.name, err)
+		}
+		client.Close()
+		server.Close()
+	}
+}
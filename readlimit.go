@@ -0,0 +1,83 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import "strconv"
+
+// ReadLimitAction specifies what happens to a message that exceeds the read
+// limit.
+type ReadLimitAction int
+
+const (
+	// ReadLimitClose sends a close message with CloseMessageTooBig to the
+	// peer and fails the connection. This is the behavior without a read
+	// limit handler.
+	ReadLimitClose ReadLimitAction = iota
+
+	// ReadLimitSkip discards the message and keeps the connection open. If
+	// the message was not yet returned by NextReader, NextReader continues
+	// with the next message. Otherwise the reader returns a
+	// *ReadLimitError with Skipped set.
+	ReadLimitSkip
+
+	// ReadLimitAllow reads the frame despite the limit. The handler is
+	// called again for each following frame of the message.
+	ReadLimitAllow
+)
+
+// ReadLimitError is returned when a message exceeds the read limit and a
+// read limit handler is set. errors.Is(err, ErrReadLimit) reports true for
+// a *ReadLimitError.
+type ReadLimitError struct {
+	// Limit is the read limit.
+	Limit int64
+
+	// Size is the size of the message read so far, including the declared
+	// length of the frame that exceeded the limit.
+	Size int64
+
+	// Skipped is true if the message was skipped by ReadLimitSkip and the
+	// connection remains usable.
+	Skipped bool
+}
+
+func (e *ReadLimitError) Error() string {
+	s := "websocket: read limit exceeded: message size " + strconv.FormatInt(e.Size, 10) +
+		" > " + strconv.FormatInt(e.Limit, 10)
+	if e.Skipped {
+		s += ", message skipped"
+	}
+	return s
+}
+
+// Is reports whether target is ErrReadLimit.
+func (e *ReadLimitError) Is(target error) bool {
+	return target == ErrReadLimit
+}
+
+// SetReadLimitHandler sets the handler that decides what happens to a
+// message that exceeds the limit set by SetReadLimit. The handler is called
+// from the reading goroutine with the size of the message read so far,
+// including the declared length of the current frame, before the frame's
+// payload is read.
+//
+// If a handler is set, errors for messages that exceed the read limit are
+// of type *ReadLimitError. If h is nil, the connection fails with
+// ErrReadLimit.
+func (c *Conn) SetReadLimitHandler(h func(size int64) ReadLimitAction) {
+	c.handleReadLimit = h
+}
+
+// skipMessage skips the rest of the message of the current frame. The
+// payload is discarded by the next call to advanceFrame.
+func (c *Conn) skipMessage(frameType int) error {
+	size := c.readLength
+	c.readLength = 0
+	c.readDropping = !c.readFinal
+	if frameType == continuationFrame {
+		return &ReadLimitError{Limit: c.readLimit, Size: size, Skipped: true}
+	}
+	return nil
+}
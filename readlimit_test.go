@@ -0,0 +1,69 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReadLimitHandler(t *testing.T) {
+	// A message fragmented as "abc" and "def" followed by "ok", masked with
+	// a zero key.
+	frames := []byte{
+		0x01, 0x83, 0, 0, 0, 0, 'a', 'b', 'c',
+		0x80, 0x83, 0, 0, 0, 0, 'd', 'e', 'f',
+		0x81, 0x82, 0, 0, 0, 0, 'o', 'k',
+	}
+	tests := []struct {
+		name   string
+		limit  int64
+		action ReadLimitAction
+		want   []string // messages or errors
+		sizes  []int64
+	}{
+		{"skip first frame", 2, ReadLimitSkip, []string{"ok"}, []int64{3}},
+		{"skip continuation", 4, ReadLimitSkip,
+			[]string{"websocket: read limit exceeded: message size 6 > 4, message skipped", "ok"}, []int64{6}},
+		{"allow", 2, ReadLimitAllow, []string{"abcdef", "ok"}, []int64{3, 6}},
+		{"close", 4, ReadLimitClose,
+			[]string{"websocket: read limit exceeded: message size 6 > 4", "websocket: read limit exceeded: message size 6 > 4"}, []int64{6}},
+	}
+	for _, tt := range tests {
+		client, server := Pipe()
+		server.SetReadLimit(tt.limit)
+		var sizes []int64
+		server.SetReadLimitHandler(func(size int64) ReadLimitAction {
+			sizes = append(sizes, size)
+			return tt.action
+		})
+		client.NetConn().Write(frames)
+		for i, want := range tt.want {
+			_, p, err := server.ReadMessage()
+			got := string(p)
+			if err != nil {
+				got = err.Error()
+				if !errors.Is(err, ErrReadLimit) {
+					t.Errorf("%s: error %v is not ErrReadLimit", tt.name, err)
+				}
+			}
+			if got != want {
+				t.Errorf("%s: message %d = %q, want %q", tt.name, i, got, want)
+			}
+		}
+		if len(sizes) != len(tt.sizes) {
+			t.Errorf("%s: handler called with %v, want %v", tt.name, sizes, tt.sizes)
+		} else {
+			for i := range sizes {
+				if sizes[i] != tt.sizes[i] {
+					t.Errorf("%s: handler called with %v, want %v", tt.name, sizes, tt.sizes)
+					break
+				}
+			}
+		}
+		client.Close()
+		server.Close()
+	}
+}
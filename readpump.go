@@ -0,0 +1,175 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Message is a data message read by a read pump.
+type Message struct {
+	// Type is TextMessage or BinaryMessage.
+	Type int
+
+	// Data is the message payload.
+	Data []byte
+}
+
+// ReadPumpOverflow specifies what a read pump started by StartReadPump does
+// with a message when the channel of messages is full.
+type ReadPumpOverflow int
+
+const (
+	// ReadPumpDropOldest drops the oldest message in the channel to deliver
+	// the new message.
+	ReadPumpDropOldest ReadPumpOverflow = iota
+
+	// ReadPumpClose closes the connection with CloseTryAgainLater and stops
+	// the pump with ErrReadPumpOverflow.
+	ReadPumpClose
+)
+
+// ErrReadPumpOverflow is returned by ReadPump.Err when a pump with the
+// ReadPumpClose policy stopped because the channel of messages was full.
+var ErrReadPumpOverflow = errors.New("websocket: read pump overflow")
+
+// ReadPump is a goroutine that reads from a connection on behalf of the
+// application. The pump reads continuously, so pings are answered and
+// pongs and close messages are processed by the connection's handlers
+// while the application is busy with other work. See Conn.StartReadPump.
+type ReadPump struct {
+	c        *Conn
+	messages chan Message
+	overflow ReadPumpOverflow
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+	err      error
+}
+
+// StartReadPump starts a read pump that delivers the data messages read
+// from the connection to the channel returned by the pump's Messages method.
+// The channel has room for buffer messages; a buffer less than one is
+// increased to one. The pump does not wait for the application to receive
+// from a full channel, so that pings are answered while the application is
+// busy. Instead, the overflow policy drops the oldest message or closes the
+// connection.
+//
+// The application must not call the read methods of the connection after
+// starting a read pump. The ping, pong and close handlers are called from
+// the pump goroutine. StartReadPump must be called at most once.
+func (c *Conn) StartReadPump(buffer int, overflow ReadPumpOverflow) *ReadPump {
+	if buffer < 1 {
+		buffer = 1
+	}
+	p := newReadPump(c)
+	p.messages = make(chan Message, buffer)
+	p.overflow = overflow
+	go p.run(c, p.send)
+	return p
+}
+
+// StartReadPumpFunc starts a read pump that calls handler with each data
+// message read from the connection. The pump does not read while handler
+// runs, so handler should return promptly. The rules of StartReadPump
+// apply.
+func (c *Conn) StartReadPumpFunc(handler func(m Message)) *ReadPump {
+	p := newReadPump(c)
+	go p.run(c, func(m Message) bool {
+		handler(m)
+		return true
+	})
+	return p
+}
+
+func newReadPump(c *Conn) *ReadPump {
+	return &ReadPump{c: c, stop: make(chan struct{}), done: make(chan struct{})}
+}
+
+func (p *ReadPump) run(c *Conn, deliver func(m Message) bool) {
+	defer close(p.done)
+	if p.messages != nil {
+		defer close(p.messages)
+	}
+	for {
+		messageType, data, err := c.ReadMessage()
+		select {
+		case <-p.stop:
+			return
+		default:
+		}
+		if err != nil {
+			p.err = err
+			return
+		}
+		if !deliver(Message{Type: messageType, Data: data}) {
+			return
+		}
+	}
+}
+
+// send delivers m to the channel of messages. It returns false if the pump
+// must stop.
+func (p *ReadPump) send(m Message) bool {
+	for {
+		select {
+		case p.messages <- m:
+			return true
+		case <-p.stop:
+			return false
+		default:
+		}
+		if p.overflow == ReadPumpClose {
+			_ = p.c.WriteControl(CloseMessage, FormatCloseMessage(CloseTryAgainLater, ""), p.c.clock.Now().Add(writeWait))
+			p.err = ErrReadPumpOverflow
+			return false
+		}
+		// Drop the oldest message unless the application received it
+		// meanwhile.
+		select {
+		case <-p.messages:
+		default:
+		}
+	}
+}
+
+// Stop stops the pump and waits for it to stop. A read in progress is
+// interrupted with a read deadline in the past, so the connection is not
+// usable for reading after Stop. Stop does not close the connection. For a
+// pump started by StartReadPumpFunc, Stop waits for the handler to return
+// and must not be called from the handler.
+func (p *ReadPump) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+		_ = p.c.SetReadDeadline(time.Unix(1, 0))
+	})
+	<-p.done
+}
+
+// Messages returns the channel of messages for a pump started by
+// StartReadPump. The channel is closed when the pump stops. Messages
+// returns nil for a pump started by StartReadPumpFunc.
+func (p *ReadPump) Messages() <-chan Message {
+	return p.messages
+}
+
+// Done returns a channel that is closed when the pump stops.
+func (p *ReadPump) Done() <-chan struct{} {
+	return p.done
+}
+
+// Err returns the error that stopped the pump, for example a *CloseError
+// when the peer closed the connection or ErrReadPumpOverflow. Err returns
+// nil while the pump is running and after Stop.
+func (p *ReadPump) Err() error {
+	select {
+	case <-p.done:
+		return p.err
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReadPump(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	pongs := make(chan string, 1)
+	client.SetPongHandler(func(appData string) error {
+		pongs <- appData
+		return nil
+	})
+	go func() {
+		for {
+			if _, _, err := client.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	p := server.StartReadPump(1, ReadPumpDropOldest)
+	if p.Err() != nil {
+		t.Errorf("Err() while running = %v", p.Err())
+	}
+	client.WriteMessage(TextMessage, []byte("a"))
+	client.WriteMessage(BinaryMessage, []byte("b"))
+	client.WriteControl(PingMessage, []byte("ping"), time.Time{})
+
+	for _, want := range []Message{{TextMessage, []byte("a")}, {BinaryMessage, []byte("b")}} {
+		m := <-p.Messages()
+		if m.Type != want.Type || string(m.Data) != string(want.Data) {
+			t.Errorf("message = %d %q, want %d %q", m.Type, m.Data, want.Type, want.Data)
+		}
+	}
+	select {
+	case appData := <-pongs:
+		if appData != "ping" {
+			t.Errorf("pong = %q, want ping", appData)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ping not answered")
+	}
+
+	client.WriteMessage(CloseMessage, FormatCloseMessage(CloseNormalClosure, ""))
+	if _, ok := <-p.Messages(); ok {
+		t.Error("Messages() not closed after close message")
+	}
+	<-p.Done()
+	if !IsCloseError(p.Err(), CloseNormalClosure) {
+		t.Errorf("Err() = %v, want close %d", p.Err(), CloseNormalClosure)
+	}
+}
+
+func TestReadPumpOverflow(t *testing.T) {
+	for _, overflow := range []ReadPumpOverflow{ReadPumpDropOldest, ReadPumpClose} {
+		client, server := Pipe()
+		pongs := make(chan string, 1)
+		client.SetPongHandler(func(appData string) error {
+			pongs <- appData
+			return nil
+		})
+		clientErr := make(chan error, 1)
+		go func() {
+			for {
+				if _, _, err := client.ReadMessage(); err != nil {
+					clientErr <- err
+					return
+				}
+			}
+		}()
+
+		p := server.StartReadPump(1, overflow)
+		for _, data := range []string{"a", "b", "c"} {
+			client.WriteMessage(TextMessage, []byte(data))
+		}
+		switch overflow {
+		case ReadPumpDropOldest:
+			// The pump answers pings while the channel is full.
+			client.WriteControl(PingMessage, []byte("ping"), time.Time{})
+			select {
+			case <-pongs:
+			case <-time.After(5 * time.Second):
+				t.Fatal("ping not answered")
+			}
+			if m := <-p.Messages(); string(m.Data) != "c" {
+				t.Errorf("message = %q, want c", m.Data)
+			}
+		case ReadPumpClose:
+			<-p.Done()
+			if p.Err() != ErrReadPumpOverflow {
+				t.Errorf("Err() = %v, want %v", p.Err(), ErrReadPumpOverflow)
+			}
+			if err := <-clientErr; !IsCloseError(err, CloseTryAgainLater) {
+				t.Errorf("client error = %v, want close %d", err, CloseTryAgainLater)
+			}
+		}
+		client.Close()
+		server.Close()
+	}
+}
+
+func TestReadPumpStop(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	p := server.StartReadPump(1, ReadPumpDropOldest)
+	client.WriteMessage(TextMessage, []byte("a"))
+	client.WriteMessage(TextMessage, []byte("b"))
+	p.Stop()
+	for range p.Messages() {
+	}
+	if p.Err() != nil {
+		t.Errorf("Err() after Stop = %v, want nil", p.Err())
+	}
+	p.Stop()
+}
+
+func TestReadPumpFunc(t *testing.T) {
+	client, server := Pipe()
+	defer server.Close()
+
+	var got []string
+	p := server.StartReadPumpFunc(func(m Message) { got = append(got, string(m.Data)) })
+	if p.Messages() != nil {
+		t.Error("Messages() != nil for a handler pump")
+	}
+	client.WriteMessage(TextMessage, []byte("a"))
+	client.WriteMessage(TextMessage, []byte("b"))
+	client.Close()
+
+	select {
+	case <-p.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("pump did not stop")
+	}
+	if p.Err() == nil {
+		t.Error("Err() = nil after the peer closed")
+	}
+	if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("handler got %q, want [a b]", got)
+	}
+}
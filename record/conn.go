@@ -0,0 +1,161 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package record
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// splitter splits one direction of a connection into frames.
+type splitter struct {
+	mu        sync.Mutex
+	handshake bool // the HTTP message of the opening handshake is pending
+	buf       []byte
+}
+
+var crlfcrlf = []byte("\r\n\r\n")
+
+// feed adds p to the stream and returns the frames that it completes.
+func (s *splitter) feed(p []byte) [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf = append(s.buf, p...)
+	if s.handshake {
+		i := bytes.Index(s.buf, crlfcrlf)
+		if i < 0 {
+			return nil
+		}
+		s.buf = s.buf[i+len(crlfcrlf):]
+		s.handshake = false
+	}
+	var frames [][]byte
+	for {
+		n, _, ok := frameLen(s.buf)
+		if !ok {
+			break
+		}
+		frames = append(frames, s.buf[:n:n])
+		s.buf = s.buf[n:]
+	}
+	if len(s.buf) == 0 {
+		s.buf = nil
+	}
+	return frames
+}
+
+// Conn is a network connection that records the websocket frames sent and
+// received on it.
+//
+// The connection must start with the HTTP opening handshake, as the
+// connections returned by a dialer and accepted from a listener do. The
+// handshake is not recorded.
+type Conn struct {
+	net.Conn
+
+	w      *Writer
+	closer io.Closer
+	start  time.Time
+
+	in, out splitter
+
+	closeOnce sync.Once
+
+	mu  sync.Mutex
+	err error
+}
+
+// NewConn returns a connection that records the frames sent and received on
+// c to w. If w implements io.Closer, w is closed when the connection is
+// closed.
+func NewConn(c net.Conn, w io.Writer) *Conn {
+	rc := &Conn{
+		Conn:  c,
+		w:     NewWriter(w),
+		start: time.Now(),
+		in:    splitter{handshake: true},
+		out:   splitter{handshake: true},
+	}
+	rc.closer, _ = w.(io.Closer)
+	return rc
+}
+
+func (c *Conn) record(dir Direction, s *splitter, p []byte) {
+	c.mu.Lock()
+	failed := c.err != nil
+	c.mu.Unlock()
+	if failed {
+		return
+	}
+	t := time.Since(c.start)
+	for _, data := range s.feed(p) {
+		if err := c.w.WriteFrame(&Frame{Time: t, Dir: dir, Data: data}); err != nil {
+			c.mu.Lock()
+			c.err = err
+			c.mu.Unlock()
+			return
+		}
+	}
+}
+
+// Read reads from the connection and records the frames it completes.
+func (c *Conn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		c.record(Received, &c.in, p[:n])
+	}
+	return n, err
+}
+
+// Write writes to the connection and records the frames it completes.
+func (c *Conn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	if n > 0 {
+		c.record(Sent, &c.out, p[:n])
+	}
+	return n, err
+}
+
+// Close closes the connection and the recording.
+func (c *Conn) Close() error {
+	err := c.Conn.Close()
+	if c.closer != nil {
+		c.closeOnce.Do(func() { c.closer.Close() })
+	}
+	return err
+}
+
+// Err returns the first error from writing the recording. Recording stops
+// after an error; the connection is not affected.
+func (c *Conn) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Listener is a listener that records the accepted connections.
+type Listener struct {
+	net.Listener
+
+	// Create returns the destination of the recording for an accepted
+	// connection. If Create returns nil, the connection is not recorded.
+	Create func(c net.Conn) io.Writer
+}
+
+// Accept waits for and returns the next connection.
+func (l *Listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	w := l.Create(c)
+	if w == nil {
+		return c, nil
+	}
+	return NewConn(c, w), nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package record
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// recording is a recording destination that signals when it is closed.
+type recording struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	closed chan struct{}
+}
+
+func newRecording() *recording {
+	return &recording{closed: make(chan struct{})}
+}
+
+func (r *recording) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buf.Write(p)
+}
+
+func (r *recording) Close() error {
+	close(r.closed)
+	return nil
+}
+
+func (r *recording) frames(t *testing.T) []*Frame {
+	t.Helper()
+	select {
+	case <-r.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("recording not closed")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	frames, err := ReadAll(bytes.NewReader(r.buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return frames
+}
+
+func echo(w http.ResponseWriter, r *http.Request) {
+	c, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer c.Close()
+	for {
+		mt, p, err := c.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.WriteMessage(mt, p)
+	}
+}
+
+// newServer starts an echo server that records its connections to the
+// returned channel.
+func newServer(t *testing.T) (*httptest.Server, chan *recording) {
+	recordings := make(chan *recording, 4)
+	s := httptest.NewUnstartedServer(http.HandlerFunc(echo))
+	s.Listener = &Listener{Listener: s.Listener, Create: func(net.Conn) io.Writer {
+		r := newRecording()
+		recordings <- r
+		return r
+	}}
+	s.Start()
+	t.Cleanup(s.Close)
+	return s, recordings
+}
+
+type summary struct {
+	dir     Direction
+	opcode  int
+	payload string
+}
+
+func summarize(t *testing.T, frames []*Frame) []summary {
+	t.Helper()
+	var s []summary
+	for _, f := range frames {
+		p, err := f.Payload()
+		if err != nil {
+			t.Fatal(err)
+		}
+		s = append(s, summary{f.Dir, f.Opcode(), string(p)})
+	}
+	return s
+}
+
+func TestRecord(t *testing.T) {
+	s, recordings := newServer(t)
+	client := newRecording()
+	d := websocket.Dialer{
+		NetDialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			c, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return NewConn(c, client), nil
+		},
+	}
+	ws, _, err := d.Dial("ws"+strings.TrimPrefix(s.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.WriteMessage(websocket.TextMessage, []byte("hello"))
+	if _, p, err := ws.ReadMessage(); err != nil || string(p) != "hello" {
+		t.Fatalf("ReadMessage() = %q, %v", p, err)
+	}
+	ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	ws.ReadMessage()
+	ws.Close()
+
+	closeFrame := string(websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	want := []summary{
+		{Sent, websocket.TextMessage, "hello"},
+		{Received, websocket.TextMessage, "hello"},
+		{Sent, websocket.CloseMessage, closeFrame},
+		{Received, websocket.CloseMessage, closeFrame},
+	}
+	got := summarize(t, client.frames(t))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("client recording = %v, want %v", got, want)
+	}
+
+	for i := range want {
+		want[i].dir = 1 - want[i].dir
+	}
+	got = summarize(t, (<-recordings).frames(t))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("server recording = %v, want %v", got, want)
+	}
+}
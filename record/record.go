@@ -0,0 +1,240 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package record records the frames of websocket connections and plays a
+// recorded peer back against code under test.
+//
+// A recording is captured by wrapping the network connection below a
+// websocket connection. On the client, wrap the connection returned by the
+// dialer:
+//
+//	d := websocket.Dialer{
+//		NetDialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+//			c, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+//			if err != nil {
+//				return nil, err
+//			}
+//			f, err := os.Create("session.rec")
+//			if err != nil {
+//				c.Close()
+//				return nil, err
+//			}
+//			return record.NewConn(c, f), nil
+//		},
+//	}
+//
+// On the server, wrap the listener with a Listener.
+//
+// A recording is a sequence of frames with the direction, the time since the
+// recording started and the bytes of the frame as they were sent on the
+// network. The opening handshake is not recorded. Recordings are stored as
+// one JSON object per line:
+//
+//	{"t":1520000,"dir":"recv","data":"gYVP+7TmJ57YiiA="}
+//
+// A Player sends the frames that the recording endpoint received, with the
+// recorded timing, to a connection to the code under test. Frames are sent
+// exactly as recorded, including the masking key and any protocol errors,
+// so bugs that depend on the byte stream from the peer can be reproduced.
+package record
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Direction is the direction of a frame relative to the recording endpoint.
+type Direction int
+
+const (
+	// Sent is a frame sent by the recording endpoint.
+	Sent Direction = iota
+
+	// Received is a frame received by the recording endpoint.
+	Received
+)
+
+func (d Direction) String() string {
+	switch d {
+	case Sent:
+		return "sent"
+	case Received:
+		return "recv"
+	}
+	return fmt.Sprintf("Direction(%d)", int(d))
+}
+
+// Frame is a recorded frame.
+type Frame struct {
+	// Time is the time since the recording started.
+	Time time.Duration
+
+	// Dir is the direction of the frame.
+	Dir Direction
+
+	// Data is the frame as sent on the network: the header, the masking key
+	// if any and the masked payload.
+	Data []byte
+}
+
+var errShortFrame = errors.New("record: short frame")
+
+// Fin reports whether the FIN bit is set in the frame header.
+func (f *Frame) Fin() bool {
+	return len(f.Data) > 0 && f.Data[0]&0x80 != 0
+}
+
+// Opcode returns the opcode in the frame header.
+func (f *Frame) Opcode() int {
+	if len(f.Data) == 0 {
+		return -1
+	}
+	return int(f.Data[0] & 0xf)
+}
+
+// Payload returns the unmasked payload of the frame.
+func (f *Frame) Payload() ([]byte, error) {
+	n, hdr, ok := frameLen(f.Data)
+	if !ok || n != len(f.Data) {
+		return nil, errShortFrame
+	}
+	p := append([]byte(nil), f.Data[hdr:]...)
+	if f.Data[1]&0x80 != 0 {
+		key := f.Data[hdr-4 : hdr]
+		for i := range p {
+			p[i] ^= key[i&3]
+		}
+	}
+	return p, nil
+}
+
+// frameLen returns the length of the frame at the start of b and the length
+// of its header including the masking key. The frame is complete if ok is
+// true.
+func frameLen(b []byte) (n, hdr int, ok bool) {
+	if len(b) < 2 {
+		return 0, 0, false
+	}
+	hdr = 2
+	size := uint64(b[1] & 0x7f)
+	switch size {
+	case 126:
+		hdr += 2
+		if len(b) < hdr {
+			return 0, 0, false
+		}
+		size = uint64(binary.BigEndian.Uint16(b[2:]))
+	case 127:
+		hdr += 8
+		if len(b) < hdr {
+			return 0, 0, false
+		}
+		size = binary.BigEndian.Uint64(b[2:])
+	}
+	if b[1]&0x80 != 0 {
+		hdr += 4
+	}
+	if len(b) < hdr || size > uint64(len(b)-hdr) {
+		return 0, hdr, false
+	}
+	return hdr + int(size), hdr, true
+}
+
+type jsonFrame struct {
+	Time int64  `json:"t"`
+	Dir  string `json:"dir"`
+	Data []byte `json:"data"`
+}
+
+// Writer writes a recording. Writer is safe for concurrent use.
+type Writer struct {
+	mu  sync.Mutex
+	w   io.Writer
+	err error
+}
+
+// NewWriter returns a writer that writes a recording to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// WriteFrame writes a frame to the recording. Each frame is written to the
+// underlying writer with a single call to Write.
+func (w *Writer) WriteFrame(f *Frame) error {
+	p, err := json.Marshal(&jsonFrame{Time: int64(f.Time), Dir: f.Dir.String(), Data: f.Data})
+	if err != nil {
+		return err
+	}
+	p = append(p, '\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	_, w.err = w.w.Write(p)
+	return w.err
+}
+
+// Reader reads a recording.
+type Reader struct {
+	br   *bufio.Reader
+	line int
+}
+
+// NewReader returns a reader that reads a recording from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{br: bufio.NewReader(r)}
+}
+
+// Next returns the next frame in the recording. At the end of the recording,
+// Next returns io.EOF.
+func (r *Reader) Next() (*Frame, error) {
+	for {
+		line, err := r.br.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			return nil, err
+		}
+		r.line++
+		if len(line) == 0 || line[0] == '\n' {
+			continue
+		}
+		var jf jsonFrame
+		if err := json.Unmarshal(line, &jf); err != nil {
+			return nil, fmt.Errorf("record: line %d: %v", r.line, err)
+		}
+		f := &Frame{Time: time.Duration(jf.Time), Data: jf.Data}
+		switch jf.Dir {
+		case "sent":
+			f.Dir = Sent
+		case "recv":
+			f.Dir = Received
+		default:
+			return nil, fmt.Errorf("record: line %d: unknown direction %q", r.line, jf.Dir)
+		}
+		return f, nil
+	}
+}
+
+// ReadAll reads a recording from r.
+func ReadAll(r io.Reader) ([]*Frame, error) {
+	rr := NewReader(r)
+	var frames []*Frame
+	for {
+		f, err := rr.Next()
+		if err == io.EOF {
+			return frames, nil
+		}
+		if err != nil {
+			return frames, err
+		}
+		frames = append(frames, f)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package record
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadWrite(t *testing.T) {
+	frames := []*Frame{
+		{Time: 0, Dir: Sent, Data: []byte{0x81, 0x02, 'h', 'i'}},
+		{Time: 1500 * time.Microsecond, Dir: Received, Data: []byte{0x89, 0x80, 1, 2, 3, 4}},
+	}
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, f := range frames {
+		if err := w.WriteFrame(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := strings.Count(buf.String(), "\n"); n != len(frames) {
+		t.Errorf("recording has %d lines, want %d", n, len(frames))
+	}
+	got, err := ReadAll(&buf)
+	if err != nil || !reflect.DeepEqual(got, frames) {
+		t.Errorf("ReadAll() = %v, %v, want %v", got, err, frames)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`{"t":0,"dir":"up","data":""}`, `line 1: unknown direction "up"`},
+		{"\n{", "line 2:"},
+	}
+	for _, tt := range tests {
+		_, err := NewReader(strings.NewReader(tt.in)).Next()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Next(%q) error = %v, want %q", tt.in, err, tt.want)
+		}
+	}
+	if _, err := NewReader(strings.NewReader("\n\n")).Next(); err != io.EOF {
+		t.Errorf("Next() error = %v, want EOF", err)
+	}
+}
+
+func TestFrame(t *testing.T) {
+	tests := []struct {
+		data    []byte
+		fin     bool
+		opcode  int
+		payload string
+		err     error
+	}{
+		{[]byte{0x81, 0x02, 'h', 'i'}, true, 1, "hi", nil},
+		{[]byte{0x02, 0x82, 1, 2, 3, 4, 'h' ^ 1, 'i' ^ 2}, false, 2, "hi", nil},
+		{append([]byte{0x82, 126, 0, 200}, make([]byte, 200)...), true, 2, string(make([]byte, 200)), nil},
+		{[]byte{0x81, 0x03, 'h', 'i'}, true, 1, "", errShortFrame},
+	}
+	for _, tt := range tests {
+		f := &Frame{Data: tt.data}
+		p, err := f.Payload()
+		if f.Fin() != tt.fin || f.Opcode() != tt.opcode || string(p) != tt.payload || err != tt.err {
+			t.Errorf("frame %x: fin %v, opcode %d, payload %q, %v, want %v, %d, %q, %v",
+				tt.data, f.Fin(), f.Opcode(), p, err, tt.fin, tt.opcode, tt.payload, tt.err)
+		}
+	}
+}
+
+func TestSplitter(t *testing.T) {
+	// The stream includes masked frames, whose headers are split by the
+	// smaller sizes.
+	stream := "GET / HTTP/1.1\r\nHost: x\r\n\r\n\x81\x02hi\x81\x82\x01\x02\x03\x04ij\x82\x80\x01\x02\x03\x04\x82\x00\x88\x02\x03"
+	for size := 1; size <= len(stream); size++ {
+		s := splitter{handshake: true}
+		var got []string
+		for i := 0; i < len(stream); i += size {
+			end := i + size
+			if end > len(stream) {
+				end = len(stream)
+			}
+			for _, f := range s.feed([]byte(stream[i:end])) {
+				got = append(got, string(f))
+			}
+		}
+		want := []string{"\x81\x02hi", "\x81\x82\x01\x02\x03\x04ij", "\x82\x80\x01\x02\x03\x04", "\x82\x00"}
+		if !reflect.DeepEqual(got, want) || len(s.buf) != 3 {
+			t.Errorf("size %d: frames = %q, buffered %d, want %q, buffered 3", size, got, len(s.buf), want)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Player plays the recorded peer of a connection.
+type Player struct {
+	// Frames is the recording. The player sends the frames that the
+	// recording endpoint received.
+	Frames []*Frame
+
+	// Speed is the playback speed relative to the recording. If Speed is
+	// zero, the frames are sent with the recorded timing. Use math.Inf(1) to
+	// send the frames without delay.
+	Speed float64
+}
+
+// Play sends the recorded frames to conn and reads the frames that the code
+// under test sends. The opening handshake on conn must be complete.
+//
+// Play returns the frames read from conn, with direction Received and the
+// time since the start of playback, when conn reaches end of file after all
+// frames are sent. If ctx is done first, Play returns the frames read so far
+// and the context's error. Play does not close conn.
+func (p *Player) Play(ctx context.Context, conn net.Conn) ([]*Frame, error) {
+	start := time.Now()
+	var got []*Frame
+	var readErr error
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+		s := splitter{}
+		buf := make([]byte, 4096)
+		for {
+			n, err := conn.Read(buf)
+			t := time.Since(start)
+			for _, data := range s.feed(buf[:n]) {
+				got = append(got, &Frame{Time: t, Dir: Received, Data: data})
+			}
+			if err != nil {
+				readErr = err
+				return
+			}
+		}
+	}()
+
+	speed := p.Speed
+	if speed == 0 {
+		speed = 1
+	}
+	var origin time.Duration
+	if len(p.Frames) > 0 {
+		origin = p.Frames[0].Time
+	}
+	err := func() error {
+		for _, f := range p.Frames {
+			if f.Dir != Received {
+				continue
+			}
+			if d := time.Duration(float64(f.Time-origin)/speed) - time.Since(start); d > 0 {
+				t := time.NewTimer(d)
+				select {
+				case <-t.C:
+				case <-ctx.Done():
+					t.Stop()
+					return ctx.Err()
+				case <-readDone:
+					t.Stop()
+					return readErr
+				}
+			}
+			if _, err := conn.Write(f.Data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+
+	if err == nil {
+		select {
+		case <-readDone:
+			err = readErr
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	conn.SetReadDeadline(time.Now())
+	<-readDone
+	if err == io.EOF {
+		err = nil
+	}
+	return got, err
+}
+
+// Dial opens a websocket connection to the server at urlStr and returns the
+// network connection after the opening handshake, for use with Player.Play.
+// No data after the handshake response is read from the connection.
+func Dial(ctx context.Context, urlStr string, header http.Header) (net.Conn, error) {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, err
+	}
+	var secure bool
+	switch u.Scheme {
+	case "ws":
+	case "wss":
+		secure = true
+	default:
+		return nil, fmt.Errorf("record: bad scheme %q", u.Scheme)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		if secure {
+			addr = net.JoinHostPort(u.Hostname(), "443")
+		} else {
+			addr = net.JoinHostPort(u.Hostname(), "80")
+		}
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if secure {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	if err := handshake(conn, u, header); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// maxResponseHeader is the maximum size of the handshake response header.
+const maxResponseHeader = 64 << 10
+
+func handshake(conn net.Conn, u *url.URL, header http.Header) error {
+	key := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "GET %s HTTP/1.1\r\nHost: %s\r\n", u.RequestURI(), u.Host)
+	b.WriteString("Upgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Version: 13\r\n")
+	fmt.Fprintf(&b, "Sec-WebSocket-Key: %s\r\n", base64.StdEncoding.EncodeToString(key))
+	header.Write(&b)
+	b.WriteString("\r\n")
+	if _, err := conn.Write(b.Bytes()); err != nil {
+		return err
+	}
+
+	// Read the response one byte at a time to leave the frames that follow
+	// it on the connection.
+	var resp []byte
+	one := make([]byte, 1)
+	for !bytes.HasSuffix(resp, crlfcrlf) {
+		if len(resp) >= maxResponseHeader {
+			return errors.New("record: handshake response too large")
+		}
+		if _, err := io.ReadFull(conn, one); err != nil {
+			return unexpectedEOF(err)
+		}
+		resp = append(resp, one[0])
+	}
+	r, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(resp)), nil)
+	if err != nil {
+		return err
+	}
+	if r.StatusCode != http.StatusSwitchingProtocols {
+		return fmt.Errorf("record: bad handshake: %s", r.Status)
+	}
+	return nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package record
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func dial(t *testing.T, url string) net.Conn {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := Dial(ctx, url, http.Header{"User-Agent": {"record-test"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestPlay(t *testing.T) {
+	s, recordings := newServer(t)
+	url := "ws" + strings.TrimPrefix(s.URL, "http")
+
+	// Record a session on the server.
+	ws, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.WriteMessage(websocket.BinaryMessage, []byte{1, 2, 3})
+	ws.ReadMessage()
+	ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(4000, "done"))
+	ws.ReadMessage()
+	ws.Close()
+	recorded := (<-recordings).frames(t)
+
+	// Play the client back against the server.
+	p := &Player{Frames: recorded, Speed: math.Inf(1)}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got, err := p.Play(ctx, dial(t, url))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []summary
+	for _, f := range recorded {
+		if f.Dir == Sent {
+			p, _ := f.Payload()
+			want = append(want, summary{Received, f.Opcode(), string(p)})
+		}
+	}
+	if g := summarize(t, got); !reflect.DeepEqual(g, want) {
+		t.Errorf("Play() = %v, want %v", g, want)
+	}
+	<-recordings
+}
+
+func TestPlayTiming(t *testing.T) {
+	s, _ := newServer(t)
+	url := "ws" + strings.TrimPrefix(s.URL, "http")
+	text := []byte{0x81, 0x81, 0, 0, 0, 0, 'x'}
+	close := []byte{0x88, 0x82, 0, 0, 0, 0, 0x03, 0xe8}
+	p := &Player{
+		Frames: []*Frame{
+			{Time: time.Second, Dir: Received, Data: text},
+			{Time: time.Second, Dir: Sent, Data: []byte{0x81, 0x01, 'x'}},
+			{Time: time.Second + 100*time.Millisecond, Dir: Received, Data: close},
+		},
+		Speed: 2,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got, err := p.Play(ctx, dial(t, url))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[1].Opcode() != websocket.CloseMessage {
+		t.Fatalf("Play() = %d frames, want text and close", len(got))
+	}
+	if got[1].Time < 50*time.Millisecond {
+		t.Errorf("close reply at %v, want at least 50ms", got[1].Time)
+	}
+
+	// The server waits for more frames after the recording ends.
+	p.Frames = p.Frames[:1]
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	got, err = p.Play(ctx, dial(t, url))
+	if err != context.DeadlineExceeded || len(got) != 1 {
+		t.Errorf("Play() = %d frames, %v, want 1 frame, %v", len(got), err, context.DeadlineExceeded)
+	}
+}
+
+func TestDialBadHandshake(t *testing.T) {
+	s := httptest.NewServer(http.NotFoundHandler())
+	defer s.Close()
+	_, err := Dial(context.Background(), "ws"+strings.TrimPrefix(s.URL, "http"), nil)
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Dial() error = %v, want bad handshake", err)
+	}
+}
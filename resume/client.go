@@ -0,0 +1,284 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package resume
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+var errNotConnected = errors.New("resume: not connected")
+
+// Client is the client of resumable sessions. Call Connect to start a
+// session and again to resume it after a read or write fails. The methods
+// of Client are safe for concurrent use, but only one goroutine may read at
+// a time.
+//
+// If QueueSize is set, messages written while the client is disconnected
+// are kept in an offline queue and sent in order when Connect completes.
+type Client struct {
+	// URL is the websocket URL of the server.
+	URL string
+
+	// Dialer is used to dial the server. If Dialer is nil,
+	// websocket.DefaultDialer is used.
+	Dialer *websocket.Dialer
+
+	// Header specifies additional request headers such as credentials.
+	Header http.Header
+
+	// Authenticate is called by Connect on each new connection before the
+	// queued messages are sent. Authenticate can write credentials to ws.
+	// Authenticate must not read from ws. If Authenticate returns an error,
+	// the connection is closed and Connect returns the error.
+	Authenticate func(ctx context.Context, ws *websocket.Conn) error
+
+	// QueueSize is the maximum number of messages in the offline queue. If
+	// QueueSize is zero, messages are not queued and WriteMessage returns
+	// an error while the client is disconnected.
+	QueueSize int
+
+	// Overflow specifies the message that is dropped when the offline
+	// queue is full.
+	Overflow OverflowPolicy
+
+	// OnDrop is called with each message that is dropped because the
+	// offline queue is full.
+	OnDrop func(m QueuedMessage)
+
+	// QueueStore persists the offline queue. If QueueStore is nil, the
+	// queue is kept in memory only.
+	QueueStore QueueStore
+
+	writeMu sync.Mutex // serializes writes to ws
+
+	mu       sync.Mutex
+	ws       *websocket.Conn
+	flushing bool // ws is set, but the queue is not yet sent
+	token    string
+	lastSeq  uint64
+	queue    offlineQueue
+}
+
+// Connect connects to the server and resumes the session of the previous
+// connection, if any. Connect reports whether the session was resumed. If
+// the session was not resumed, the messages that the client missed are
+// lost and the application should restore its state from the server.
+//
+// After the connection is established, Connect calls Authenticate and
+// sends the messages in the offline queue. Messages written meanwhile are
+// queued behind them.
+func (c *Client) Connect(ctx context.Context) (resumed bool, err error) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return false, err
+	}
+	c.mu.Lock()
+	if c.QueueSize > 0 {
+		if err := c.loadQueue(); err != nil {
+			c.mu.Unlock()
+			return false, err
+		}
+	}
+	token, lastSeq := c.token, c.lastSeq
+	if old := c.ws; old != nil {
+		old.Close()
+		c.ws = nil
+	}
+	c.mu.Unlock()
+
+	if token != "" {
+		q := u.Query()
+		q.Set(sessionParam, token)
+		q.Set(seqParam, strconv.FormatUint(lastSeq, 10))
+		u.RawQuery = q.Encode()
+	}
+	d := c.Dialer
+	if d == nil {
+		d = websocket.DefaultDialer
+	}
+	ws, _, err := d.DialContext(ctx, u.String(), c.Header)
+	if err != nil {
+		return false, err
+	}
+
+	// The first message carries the session token.
+	_, p, err := ws.ReadMessage()
+	if err == nil {
+		var seq uint64
+		seq, p, err = parseEnvelope(p)
+		if err == nil && seq != 0 {
+			err = errBadEnvelope
+		}
+	}
+	if err != nil {
+		ws.Close()
+		return false, err
+	}
+
+	c.mu.Lock()
+	resumed = token != "" && string(p) == token
+	if !resumed {
+		c.token = string(p)
+		c.lastSeq = 0
+	}
+	c.ws = ws
+	c.flushing = true
+	c.mu.Unlock()
+
+	if c.Authenticate != nil {
+		if err := c.Authenticate(ctx, ws); err != nil {
+			c.disconnect(ws)
+			return resumed, err
+		}
+	}
+	return resumed, c.flush(ws)
+}
+
+// flush sends the queued messages on ws.
+func (c *Client) flush(ws *websocket.Conn) error {
+	for {
+		c.mu.Lock()
+		if c.ws != ws {
+			c.mu.Unlock()
+			return errNotConnected
+		}
+		if len(c.queue.msgs) == 0 {
+			c.flushing = false
+			c.mu.Unlock()
+			return nil
+		}
+		m := c.queue.msgs[0]
+		c.mu.Unlock()
+
+		c.writeMu.Lock()
+		err := ws.WriteMessage(m.Type, m.Data)
+		c.writeMu.Unlock()
+		if err != nil {
+			c.disconnect(ws)
+			return err
+		}
+		c.mu.Lock()
+		err = c.dequeue()
+		c.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// disconnect closes ws and clears it if it is the current connection.
+func (c *Client) disconnect(ws *websocket.Conn) {
+	c.mu.Lock()
+	if c.ws == ws {
+		c.ws = nil
+	}
+	c.mu.Unlock()
+	ws.Close()
+}
+
+// Token returns the token of the session, or "" before the first Connect.
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+func (c *Client) conn() *websocket.Conn {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ws
+}
+
+// ReadMessage reads the next message of the session. Messages replayed by
+// the server that the client already received are skipped.
+func (c *Client) ReadMessage() (messageType int, p []byte, err error) {
+	ws := c.conn()
+	if ws == nil {
+		return 0, nil, errNotConnected
+	}
+	for {
+		messageType, p, err = ws.ReadMessage()
+		if err != nil {
+			return 0, nil, err
+		}
+		var seq uint64
+		if seq, p, err = parseEnvelope(p); err != nil {
+			return 0, nil, err
+		}
+		c.mu.Lock()
+		dup := seq <= c.lastSeq
+		if !dup {
+			c.lastSeq = seq
+		}
+		c.mu.Unlock()
+		if !dup {
+			return messageType, p, nil
+		}
+	}
+}
+
+// WriteMessage writes a message to the server on the current connection.
+// While the client is disconnected, WriteMessage adds the message to the
+// offline queue. If the write fails, the connection is closed, the message
+// is queued and WriteMessage returns the error. Messages from the client
+// are not replayed by the server.
+func (c *Client) WriteMessage(messageType int, data []byte) error {
+	c.mu.Lock()
+	ws := c.ws
+	if ws == nil || c.flushing {
+		return c.queueMessage(messageType, data, errNotConnected)
+	}
+	c.mu.Unlock()
+
+	c.writeMu.Lock()
+	err := ws.WriteMessage(messageType, data)
+	c.writeMu.Unlock()
+	if err != nil {
+		c.disconnect(ws)
+		c.mu.Lock()
+		_ = c.queueMessage(messageType, data, err)
+	}
+	return err
+}
+
+// queueMessage adds a message to the offline queue, unlocks the client and
+// reports the dropped message. If the queue is disabled, queueMessage
+// returns errNoQueue.
+func (c *Client) queueMessage(messageType int, data []byte, errNoQueue error) error {
+	if c.QueueSize <= 0 {
+		c.mu.Unlock()
+		return errNoQueue
+	}
+	if err := c.loadQueue(); err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	dropped, err := c.enqueue(messageType, data)
+	c.mu.Unlock()
+	if dropped != nil && c.OnDrop != nil {
+		c.OnDrop(*dropped)
+	}
+	return err
+}
+
+// Close closes the connection. The session can be resumed with Connect
+// until the server's session timeout expires. The offline queue is kept.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ws == nil {
+		return nil
+	}
+	err := c.ws.Close()
+	c.ws = nil
+	return err
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package resume
+
+import "errors"
+
+// ErrQueueFull is returned by Client.WriteMessage when the offline queue is
+// full and the overflow policy is DropNewest.
+var ErrQueueFull = errors.New("resume: offline queue full")
+
+// OverflowPolicy specifies the message that is dropped when a message is
+// written while the offline queue is full.
+type OverflowPolicy int
+
+const (
+	// DropOldest drops the oldest queued message to queue the new message.
+	DropOldest OverflowPolicy = iota
+
+	// DropNewest drops the new message.
+	DropNewest
+)
+
+// QueuedMessage is a message in the offline queue of a Client.
+type QueuedMessage struct {
+	// ID identifies the message in the QueueStore. IDs increase in the
+	// order that messages are queued.
+	ID   uint64
+	Type int
+	Data []byte
+}
+
+// QueueStore persists the offline queue of a Client, so that queued
+// messages survive a restart of the client. The methods are called with the
+// client's lock held and must not call the client.
+type QueueStore interface {
+	// Save is called when a message is queued.
+	Save(m QueuedMessage) error
+
+	// Remove is called when a queued message is sent or dropped.
+	Remove(id uint64) error
+
+	// Load returns the messages saved by a previous Client in the order of
+	// their IDs. Load is called by the first Connect or WriteMessage.
+	Load() ([]QueuedMessage, error)
+}
+
+// offlineQueue is the offline queue of a Client. The client's lock is held
+// by the methods.
+type offlineQueue struct {
+	msgs   []QueuedMessage
+	nextID uint64
+	loaded bool
+}
+
+func (c *Client) loadQueue() error {
+	q := &c.queue
+	if q.loaded {
+		return nil
+	}
+	q.loaded = true
+	q.nextID = 1
+	if c.QueueStore == nil {
+		return nil
+	}
+	msgs, err := c.QueueStore.Load()
+	if err != nil {
+		q.loaded = false
+		return err
+	}
+	q.msgs = msgs
+	if n := len(msgs); n > 0 {
+		q.nextID = msgs[n-1].ID + 1
+	}
+	return nil
+}
+
+// enqueue adds a message to the queue and returns the dropped message, if
+// any.
+func (c *Client) enqueue(messageType int, data []byte) (dropped *QueuedMessage, err error) {
+	q := &c.queue
+	m := QueuedMessage{ID: q.nextID, Type: messageType, Data: append([]byte(nil), data...)}
+	if len(q.msgs) >= c.QueueSize {
+		if c.Overflow == DropNewest {
+			return &m, ErrQueueFull
+		}
+		old := q.msgs[0]
+		if c.QueueStore != nil {
+			if err := c.QueueStore.Remove(old.ID); err != nil {
+				return nil, err
+			}
+		}
+		q.msgs[0] = QueuedMessage{}
+		q.msgs = q.msgs[1:]
+		dropped = &old
+	}
+	if c.QueueStore != nil {
+		if err := c.QueueStore.Save(m); err != nil {
+			return dropped, err
+		}
+	}
+	q.nextID++
+	q.msgs = append(q.msgs, m)
+	return dropped, nil
+}
+
+// dequeue removes the first queued message after it is sent.
+func (c *Client) dequeue() error {
+	q := &c.queue
+	m := q.msgs[0]
+	q.msgs[0] = QueuedMessage{}
+	q.msgs = q.msgs[1:]
+	if c.QueueStore != nil {
+		return c.QueueStore.Remove(m.ID)
+	}
+	return nil
+}
+
+// Queued returns the number of messages in the offline queue.
+func (c *Client) Queued() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.queue.msgs)
+}
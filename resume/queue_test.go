@@ -0,0 +1,159 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package resume
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type memQueueStore struct {
+	mu   sync.Mutex
+	msgs []QueuedMessage
+}
+
+func (s *memQueueStore) Save(m QueuedMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.msgs = append(s.msgs, m)
+	return nil
+}
+
+func (s *memQueueStore) Remove(id uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, m := range s.msgs {
+		if m.ID == id {
+			s.msgs = append(s.msgs[:i], s.msgs[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *memQueueStore) Load() ([]QueuedMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]QueuedMessage(nil), s.msgs...), nil
+}
+
+// newRecordingServer returns the URL of a server that sends the messages
+// received from clients to the returned channel.
+func newRecordingServer(t *testing.T) (string, chan string) {
+	s := &Server{}
+	received := make(chan string, 10)
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := s.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		for {
+			_, p, err := session.ReadMessage()
+			if err != nil {
+				return
+			}
+			received <- string(p)
+		}
+	}))
+	t.Cleanup(hs.Close)
+	return "ws" + strings.TrimPrefix(hs.URL, "http"), received
+}
+
+func expectReceived(t *testing.T, received chan string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		select {
+		case got := <-received:
+			if got != w {
+				t.Errorf("server received %q, want %q", got, w)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("server did not receive %q", w)
+		}
+	}
+}
+
+func TestOfflineQueue(t *testing.T) {
+	u, received := newRecordingServer(t)
+	var dropped []string
+	c := &Client{
+		URL:       u,
+		QueueSize: 2,
+		OnDrop:    func(m QueuedMessage) { dropped = append(dropped, string(m.Data)) },
+		Authenticate: func(ctx context.Context, ws *websocket.Conn) error {
+			return ws.WriteMessage(websocket.TextMessage, []byte("auth"))
+		},
+	}
+	defer c.Close()
+
+	for _, msg := range []string{"a", "b", "c"} {
+		if err := c.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+			t.Fatalf("WriteMessage(%q) error = %v", msg, err)
+		}
+	}
+	if len(dropped) != 1 || dropped[0] != "a" {
+		t.Errorf("dropped %q, want [a]", dropped)
+	}
+	if n := c.Queued(); n != 2 {
+		t.Errorf("Queued() = %d, want 2", n)
+	}
+
+	if _, err := c.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := c.Queued(); n != 0 {
+		t.Errorf("Queued() after Connect = %d, want 0", n)
+	}
+	c.WriteMessage(websocket.TextMessage, []byte("d"))
+	expectReceived(t, received, "auth", "b", "c", "d")
+}
+
+func TestOfflineQueueDropNewest(t *testing.T) {
+	var dropped []string
+	c := &Client{
+		QueueSize: 1,
+		Overflow:  DropNewest,
+		OnDrop:    func(m QueuedMessage) { dropped = append(dropped, string(m.Data)) },
+	}
+	c.WriteMessage(websocket.TextMessage, []byte("a"))
+	if err := c.WriteMessage(websocket.TextMessage, []byte("b")); err != ErrQueueFull {
+		t.Errorf("WriteMessage() to full queue error = %v, want %v", err, ErrQueueFull)
+	}
+	if len(dropped) != 1 || dropped[0] != "b" {
+		t.Errorf("dropped %q, want [b]", dropped)
+	}
+
+	// Without a queue, writes fail while disconnected.
+	if err := (&Client{}).WriteMessage(websocket.TextMessage, nil); err != errNotConnected {
+		t.Errorf("WriteMessage() without queue error = %v, want %v", err, errNotConnected)
+	}
+}
+
+func TestOfflineQueueStore(t *testing.T) {
+	u, received := newRecordingServer(t)
+	store := &memQueueStore{}
+	c := &Client{URL: u, QueueSize: 10, QueueStore: store}
+	c.WriteMessage(websocket.TextMessage, []byte("a"))
+	c.WriteMessage(websocket.TextMessage, []byte("b"))
+
+	// A new client, such as after a restart, sends the stored messages.
+	c = &Client{URL: u, QueueSize: 10, QueueStore: store}
+	defer c.Close()
+	c.WriteMessage(websocket.TextMessage, []byte("c"))
+	if _, err := c.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	expectReceived(t, received, "a", "b", "c")
+	if msgs, _ := store.Load(); len(msgs) != 0 {
+		t.Errorf("store has %d messages after flush, want 0", len(msgs))
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package resume adds resumable sessions to websocket connections.
+//
+// A session outlives the connections that carry it. The Server issues a
+// session token when a client connects and keeps the last messages sent in
+// the session. When the connection drops, the Client reconnects with the
+// token and the sequence number of the last message it received, and the
+// server replays the messages that the client missed:
+//
+//	s := &resume.Server{BufferSize: 256}
+//	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+//		session, err := s.Upgrade(w, r)
+//		if err != nil {
+//			return
+//		}
+//		if !session.Resumed() {
+//			go publish(session) // calls session.WriteMessage
+//		}
+//		for {
+//			if _, _, err := session.ReadMessage(); err != nil {
+//				return
+//			}
+//		}
+//	})
+//
+// Only messages from the server to the client are replayed. A session
+// cannot be resumed if the messages that the client missed are no longer
+// buffered; the server then starts a new session and the client's Connect
+// reports that the session was not resumed.
+//
+// Messages from the client can be kept in an offline queue while the
+// client is disconnected. Connect sends them in order after the connection
+// is established. See Client.QueueSize.
+//
+// # Protocol
+//
+// The client sends the token and the last sequence number in the "session"
+// and "seq" query parameters, so that browsers can resume sessions. The
+// server prefixes each data message with the decimal sequence number of the
+// message and a space. The first message on each connection has sequence
+// number 0 and carries the session token.
+package resume
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+)
+
+const (
+	sessionParam = "session"
+	seqParam     = "seq"
+)
+
+var errBadEnvelope = errors.New("resume: message without sequence number")
+
+// appendEnvelope appends the message with sequence number seq to b.
+func appendEnvelope(b []byte, seq uint64, data []byte) []byte {
+	b = strconv.AppendUint(b, seq, 10)
+	b = append(b, ' ')
+	return append(b, data...)
+}
+
+// parseEnvelope returns the sequence number and data of a message.
+func parseEnvelope(p []byte) (seq uint64, data []byte, err error) {
+	i := bytes.IndexByte(p, ' ')
+	if i < 0 {
+		return 0, nil, errBadEnvelope
+	}
+	seq, err = strconv.ParseUint(string(p[:i]), 10, 64)
+	if err != nil {
+		return 0, nil, errBadEnvelope
+	}
+	return seq, p[i+1:], nil
+}
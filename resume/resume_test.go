@@ -0,0 +1,154 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package resume
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestEnvelope(t *testing.T) {
+	p := appendEnvelope(nil, 42, []byte("hello world"))
+	if string(p) != "42 hello world" {
+		t.Errorf("appendEnvelope() = %q", p)
+	}
+	seq, data, err := parseEnvelope(p)
+	if seq != 42 || string(data) != "hello world" || err != nil {
+		t.Errorf("parseEnvelope(%q) = %d, %q, %v", p, seq, data, err)
+	}
+	for _, p := range []string{"", "42", "x hello", "-1 hello"} {
+		if _, _, err := parseEnvelope([]byte(p)); err != errBadEnvelope {
+			t.Errorf("parseEnvelope(%q) error = %v, want %v", p, err, errBadEnvelope)
+		}
+	}
+}
+
+// waitDetached waits until the session has no connection.
+func waitDetached(t *testing.T, s *Session) {
+	t.Helper()
+	for start := time.Now(); s.Conn() != nil; time.Sleep(time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("session not detached")
+		}
+	}
+}
+
+func readString(t *testing.T, c *Client) string {
+	t.Helper()
+	_, p, err := c.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	return string(p)
+}
+
+func TestResume(t *testing.T) {
+	s := &Server{BufferSize: 4}
+	sessions := make(chan *Session, 1)
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := s.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		sessions <- session
+		for {
+			if _, _, err := session.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer hs.Close()
+
+	ctx := context.Background()
+	c := &Client{URL: "ws" + strings.TrimPrefix(hs.URL, "http")}
+	defer c.Close()
+	if resumed, err := c.Connect(ctx); err != nil || resumed {
+		t.Fatalf("Connect() = %t, %v, want new session", resumed, err)
+	}
+	session := <-sessions
+	if c.Token() != session.Token() {
+		t.Errorf("client token %q, want %q", c.Token(), session.Token())
+	}
+	session.WriteMessage(websocket.TextMessage, []byte("a"))
+	if got := readString(t, c); got != "a" {
+		t.Errorf("ReadMessage() = %q, want a", got)
+	}
+
+	// Messages sent while the client is disconnected are replayed.
+	c.Close()
+	waitDetached(t, session)
+	if err := session.WriteMessage(websocket.TextMessage, []byte("b")); err == nil {
+		t.Error("WriteMessage() while disconnected returned nil error")
+	}
+	session.WriteMessage(websocket.BinaryMessage, []byte("c"))
+	if resumed, err := c.Connect(ctx); err != nil || !resumed {
+		t.Fatalf("Connect() = %t, %v, want resumed session", resumed, err)
+	}
+	if got := <-sessions; got != session || !session.Resumed() {
+		t.Fatal("server did not resume the session")
+	}
+	for _, want := range []string{"b", "c"} {
+		if got := readString(t, c); got != want {
+			t.Errorf("ReadMessage() = %q, want %q", got, want)
+		}
+	}
+
+	// The session cannot be resumed when the buffer lost messages.
+	c.Close()
+	waitDetached(t, session)
+	for i := 0; i < 5; i++ {
+		session.WriteMessage(websocket.TextMessage, []byte("lost"))
+	}
+	token := c.Token()
+	if resumed, err := c.Connect(ctx); err != nil || resumed {
+		t.Fatalf("Connect() = %t, %v, want new session", resumed, err)
+	}
+	if got := <-sessions; got == session || c.Token() == token {
+		t.Error("server resumed a session with lost messages")
+	}
+	if err := session.WriteMessage(websocket.TextMessage, []byte("x")); err != ErrSessionClosed {
+		t.Errorf("WriteMessage() on replaced session error = %v, want %v", err, ErrSessionClosed)
+	}
+}
+
+func TestSessionTimeout(t *testing.T) {
+	s := &Server{SessionTimeout: 10 * time.Millisecond}
+	sessions := make(chan *Session, 1)
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := s.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		sessions <- session
+		session.ReadMessage()
+	}))
+	defer hs.Close()
+
+	c := &Client{URL: "ws" + strings.TrimPrefix(hs.URL, "http")}
+	if _, err := c.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	session := <-sessions
+	c.Close()
+	for start := time.Now(); ; time.Sleep(time.Millisecond) {
+		if err := session.WriteMessage(websocket.TextMessage, nil); err == ErrSessionClosed {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("session did not expire")
+		}
+	}
+	if resumed, err := c.Connect(context.Background()); err != nil || resumed {
+		t.Errorf("Connect() after timeout = %t, %v, want new session", resumed, err)
+	}
+	<-sessions
+	c.Close()
+}
@@ -0,0 +1,301 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package resume
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultBufferSize     = 128
+	defaultSessionTimeout = 2 * time.Minute
+)
+
+// ErrSessionClosed is returned by the methods of a closed Session.
+var ErrSessionClosed = errors.New("resume: session closed")
+
+var errDisconnected = errors.New("resume: client disconnected")
+
+// Server upgrades requests to connections of resumable sessions.
+type Server struct {
+	// Upgrader is used to upgrade requests. If Upgrader is nil, a zero
+	// Upgrader is used.
+	Upgrader *websocket.Upgrader
+
+	// BufferSize is the number of messages kept for replay in each session.
+	// If BufferSize is zero, 128 is used.
+	BufferSize int
+
+	// SessionTimeout is the time that a session without a connection is
+	// kept for the client to resume it. If SessionTimeout is zero, 2
+	// minutes is used.
+	SessionTimeout time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+func (s *Server) bufferSize() int {
+	if s.BufferSize > 0 {
+		return s.BufferSize
+	}
+	return defaultBufferSize
+}
+
+func (s *Server) sessionTimeout() time.Duration {
+	if s.SessionTimeout > 0 {
+		return s.SessionTimeout
+	}
+	return defaultSessionTimeout
+}
+
+// Upgrade upgrades the request and returns its session. The session is
+// resumed if the request has the token of a session that buffers all the
+// messages that the client missed. Otherwise a new session is started.
+//
+// The messages that the client missed are written before Upgrade returns.
+func (s *Server) Upgrade(w http.ResponseWriter, r *http.Request) (*Session, error) {
+	u := s.Upgrader
+	if u == nil {
+		u = &websocket.Upgrader{}
+	}
+	q := r.URL.Query()
+	token := q.Get(sessionParam)
+	lastSeq, err := strconv.ParseUint(q.Get(seqParam), 10, 64)
+	if err != nil {
+		token = ""
+	}
+
+	ws, err := u.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	session := s.sessions[token]
+	if session != nil && !session.canResume(lastSeq) {
+		session.mu.Lock()
+		session.end()
+		session.mu.Unlock()
+		delete(s.sessions, token)
+		session = nil
+	}
+	resumed := session != nil
+	if !resumed {
+		if token, err = newToken(); err != nil {
+			s.mu.Unlock()
+			ws.Close()
+			return nil, err
+		}
+		session = &Session{server: s, token: token, nextSeq: 1}
+		if s.sessions == nil {
+			s.sessions = make(map[string]*Session)
+		}
+		s.sessions[token] = session
+		lastSeq = 0
+	}
+	s.mu.Unlock()
+
+	if err := session.attach(ws, lastSeq, resumed); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// remove removes the session from the server.
+func (s *Server) remove(session *Session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessions[session.token] == session {
+		delete(s.sessions, session.token)
+	}
+}
+
+func newToken() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+type bufferedMessage struct {
+	seq         uint64
+	messageType int
+	data        []byte
+}
+
+// Session is a resumable session. The methods of Session are safe for
+// concurrent use, but only one goroutine may read at a time.
+type Session struct {
+	server *Server
+	token  string
+
+	mu      sync.Mutex
+	ws      *websocket.Conn // nil when disconnected
+	buf     []bufferedMessage
+	nextSeq uint64
+	resumed bool
+	closed  bool
+	expire  *time.Timer
+}
+
+// Token returns the session token.
+func (s *Session) Token() string {
+	return s.token
+}
+
+// Resumed reports whether the last connection resumed the session.
+func (s *Session) Resumed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.resumed
+}
+
+// canResume reports whether the buffer has the messages after lastSeq. The
+// server's mutex is held.
+func (s *Session) canResume(lastSeq uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || lastSeq >= s.nextSeq {
+		return false
+	}
+	oldest := s.nextSeq
+	if len(s.buf) > 0 {
+		oldest = s.buf[0].seq
+	}
+	return lastSeq+1 >= oldest
+}
+
+// attach makes ws the connection of the session and replays the messages
+// after lastSeq.
+func (s *Session) attach(ws *websocket.Conn, lastSeq uint64, resumed bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		ws.Close()
+		return ErrSessionClosed
+	}
+	if s.expire != nil {
+		s.expire.Stop()
+		s.expire = nil
+	}
+	if s.ws != nil {
+		s.ws.Close()
+	}
+	s.ws = ws
+	s.resumed = resumed
+	if err := ws.WriteMessage(websocket.TextMessage, appendEnvelope(nil, 0, []byte(s.token))); err != nil {
+		s.detachLocked(ws)
+		return err
+	}
+	for _, m := range s.buf {
+		if m.seq <= lastSeq {
+			continue
+		}
+		if err := ws.WriteMessage(m.messageType, appendEnvelope(nil, m.seq, m.data)); err != nil {
+			s.detachLocked(ws)
+			return err
+		}
+	}
+	return nil
+}
+
+// detachLocked closes ws and, if it is the connection of the session,
+// starts the session timeout. The session's mutex is held.
+func (s *Session) detachLocked(ws *websocket.Conn) {
+	ws.Close()
+	if s.ws != ws {
+		return
+	}
+	s.ws = nil
+	if !s.closed {
+		s.expire = time.AfterFunc(s.server.sessionTimeout(), s.Close)
+	}
+}
+
+// Conn returns the current connection of the session, or nil if the client
+// is disconnected.
+func (s *Session) Conn() *websocket.Conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ws
+}
+
+// WriteMessage sends a data message in the session. The message is
+// buffered for replay and written to the current connection. If the client
+// is disconnected or the write fails, WriteMessage returns an error, and
+// the message is replayed when the client resumes the session.
+func (s *Session) WriteMessage(messageType int, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrSessionClosed
+	}
+	m := bufferedMessage{seq: s.nextSeq, messageType: messageType, data: append([]byte(nil), data...)}
+	s.nextSeq++
+	s.buf = append(s.buf, m)
+	if n := len(s.buf) - s.server.bufferSize(); n > 0 {
+		copy(s.buf, s.buf[n:])
+		s.buf = s.buf[:len(s.buf)-n]
+	}
+	if s.ws == nil {
+		return errDisconnected
+	}
+	ws := s.ws
+	if err := ws.WriteMessage(messageType, appendEnvelope(nil, m.seq, m.data)); err != nil {
+		s.detachLocked(ws)
+		return err
+	}
+	return nil
+}
+
+// ReadMessage reads a data message from the current connection. When the
+// read fails, the connection is detached from the session and the session
+// waits for the client to resume it.
+func (s *Session) ReadMessage() (messageType int, p []byte, err error) {
+	ws := s.Conn()
+	if ws == nil {
+		return 0, nil, errDisconnected
+	}
+	messageType, p, err = ws.ReadMessage()
+	if err != nil {
+		s.mu.Lock()
+		s.detachLocked(ws)
+		s.mu.Unlock()
+	}
+	return messageType, p, err
+}
+
+// Close ends the session and closes its connection. The session cannot be
+// resumed after Close.
+func (s *Session) Close() {
+	s.mu.Lock()
+	s.end()
+	s.mu.Unlock()
+	s.server.remove(s)
+}
+
+// end closes the session. The session's mutex is held.
+func (s *Session) end() {
+	s.closed = true
+	s.buf = nil
+	if s.expire != nil {
+		s.expire.Stop()
+		s.expire = nil
+	}
+	if s.ws != nil {
+		s.ws.Close()
+		s.ws = nil
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/gorilla/websocket"
+)
+
+// Message is an RPC message exchanged over a websocket connection.
+type Message struct {
+	// Response is true if the message is a response to a request.
+	Response bool
+
+	// ID correlates a response with its request. A request with an empty ID
+	// is a notification and does not receive a response.
+	ID string
+
+	// Method is the name of the method invoked by a request.
+	Method string
+
+	// Payload is the encoded request parameters or response result.
+	Payload []byte
+
+	// Error is set in a response to a failed request. A codec sets Error on a
+	// request to report that the request is invalid.
+	Error *Error
+}
+
+// A Codec converts between websocket messages and RPC messages.
+//
+// A websocket message carries a single RPC message or, for codecs that
+// support it, a batch of RPC messages. The batch flag returned from Decode is
+// passed back to Encode when writing the responses to the batch.
+type Codec interface {
+	// Decode decodes the data message p to RPC messages. If Decode returns an
+	// error, the connection replies with an error response.
+	Decode(messageType int, p []byte) (msgs []*Message, batch bool, err error)
+
+	// Encode encodes msgs to a websocket data message.
+	Encode(msgs []*Message, batch bool) (messageType int, p []byte, err error)
+}
+
+// JSONCodec is the default codec. Each RPC message is encoded as a JSON object
+// in a text message:
+//
+//	{"id": "1", "method": "add", "payload": [1, 2]}
+//	{"id": "1", "payload": 3}
+//	{"id": "2", "error": {"code": -32601, "message": "method not found"}}
+//
+// Payloads must be valid JSON. Batches are not supported.
+var JSONCodec Codec = jsonCodec{}
+
+type jsonCodec struct{}
+
+type jsonMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+var errBatchNotSupported = errors.New("rpc: batches not supported by codec")
+
+func (jsonCodec) Decode(messageType int, p []byte) ([]*Message, bool, error) {
+	var jm jsonMessage
+	if err := json.Unmarshal(p, &jm); err != nil {
+		return nil, false, &Error{Code: CodeParseError, Message: err.Error()}
+	}
+	m := &Message{
+		Response: jm.Method == "",
+		ID:       jm.ID,
+		Method:   jm.Method,
+		Payload:  jm.Payload,
+		Error:    jm.Error,
+	}
+	return []*Message{m}, false, nil
+}
+
+func (jsonCodec) Encode(msgs []*Message, batch bool) (int, []byte, error) {
+	if batch || len(msgs) != 1 {
+		return 0, nil, errBatchNotSupported
+	}
+	m := msgs[0]
+	jm := jsonMessage{
+		ID:      m.ID,
+		Method:  m.Method,
+		Payload: json.RawMessage(m.Payload),
+		Error:   m.Error,
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(&jm); err != nil {
+		return 0, nil, err
+	}
+	return websocket.TextMessage, buf.Bytes(), nil
+}
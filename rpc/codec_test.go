@@ -0,0 +1,51 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+var jsonCodecTests = []*Message{
+	{ID: "1", Method: "add", Payload: []byte(`[1,2]`)},
+	{Method: "event", Payload: []byte(`{"x":1}`)},
+	{Response: true, ID: "1", Payload: []byte(`3`)},
+	{Response: true, ID: "2", Error: &Error{Code: CodeMethodNotFound, Message: "method not found"}},
+}
+
+func TestJSONCodec(t *testing.T) {
+	for _, m := range jsonCodecTests {
+		mt, p, err := JSONCodec.Encode([]*Message{m}, false)
+		if err != nil {
+			t.Errorf("Encode(%+v) returned error %v", m, err)
+			continue
+		}
+		if mt != websocket.TextMessage {
+			t.Errorf("Encode(%+v) returned message type %d, want %d", m, mt, websocket.TextMessage)
+		}
+		msgs, batch, err := JSONCodec.Decode(mt, p)
+		if err != nil || batch || len(msgs) != 1 {
+			t.Errorf("Decode(%s) returned %v, %v, %v", p, msgs, batch, err)
+			continue
+		}
+		if !reflect.DeepEqual(msgs[0], m) {
+			t.Errorf("Decode(%s) returned %+v, want %+v", p, msgs[0], m)
+		}
+	}
+}
+
+func TestJSONCodecErrors(t *testing.T) {
+	if _, _, err := JSONCodec.Decode(websocket.TextMessage, []byte(`{`)); err == nil {
+		t.Error("Decode of invalid JSON did not return an error")
+	} else if e, ok := err.(*Error); !ok || e.Code != CodeParseError {
+		t.Errorf("Decode of invalid JSON returned %v, want code %d", err, CodeParseError)
+	}
+	if _, _, err := JSONCodec.Encode(jsonCodecTests[:2], true); err == nil {
+		t.Error("Encode of batch did not return an error")
+	}
+}
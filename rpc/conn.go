@@ -0,0 +1,436 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package rpc implements correlated request and response messaging over a
+// WebSocket connection.
+//
+// Both ends of a connection can issue calls and serve requests. A client
+// typically only calls methods:
+//
+//	ws, _, err := websocket.DefaultDialer.Dial(url, nil)
+//	...
+//	c := rpc.NewConn(ws, nil)
+//	defer c.Close()
+//	result, err := c.Call(ctx, "add", []byte(`[1, 2]`))
+//
+// A server registers handlers for methods:
+//
+//	mux := rpc.NewServeMux()
+//	mux.HandleFunc("add", add)
+//	ws, err := upgrader.Upgrade(w, r, nil)
+//	...
+//	c := rpc.NewConn(ws, &rpc.Config{Handler: mux})
+//	<-c.Done()
+package rpc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ErrClosed is returned from calls on a closed connection.
+var ErrClosed = errors.New("rpc: connection closed")
+
+const defaultWriteTimeout = 10 * time.Second
+
+// Config specifies options for an RPC connection. The zero value is a valid
+// configuration.
+type Config struct {
+	// Codec converts between websocket messages and RPC messages. If Codec is
+	// nil, JSONCodec is used.
+	Codec Codec
+
+	// Handler responds to requests from the peer. If Handler is nil, requests
+	// fail with CodeMethodNotFound.
+	Handler Handler
+
+	// Timeout specifies a time limit for calls. The limit applies in addition
+	// to any deadline on the call context. Zero means no limit.
+	Timeout time.Duration
+
+	// MaxInFlight limits the number of calls waiting for a response. Calls
+	// over the limit block until a slot is available or the call context is
+	// done. Zero means no limit.
+	MaxInFlight int
+
+	// MaxHandlers limits the number of handlers running concurrently. The
+	// requests in a batch count individually. When the limit is reached, the
+	// connection stops reading from the peer until a handler returns. Zero
+	// means no limit.
+	MaxHandlers int
+
+	// WriteTimeout specifies the time allowed to write a message to the
+	// peer. The connection fails when a write times out. If zero, a default
+	// of 10 seconds is used.
+	WriteTimeout time.Duration
+}
+
+// Conn is an RPC endpoint on a websocket connection.
+//
+// The Conn owns the read and write methods of the websocket connection.
+// Applications must not read or write the websocket connection directly.
+//
+// It is safe to call Conn's methods concurrently.
+type Conn struct {
+	ws           *websocket.Conn
+	codec        Codec
+	handler      Handler
+	timeout      time.Duration
+	writeTimeout time.Duration
+	inFlight     chan struct{}
+	handlers     chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[string]chan *Message
+	err     error
+}
+
+// NewConn returns an RPC endpoint on ws and starts a goroutine to read
+// messages from the peer. A nil config is equivalent to the zero Config.
+func NewConn(ws *websocket.Conn, config *Config) *Conn {
+	if config == nil {
+		config = &Config{}
+	}
+	c := &Conn{
+		ws:      ws,
+		codec:   config.Codec,
+		handler: config.Handler,
+		timeout: config.Timeout,
+		pending: make(map[string]chan *Message),
+	}
+	if c.codec == nil {
+		c.codec = JSONCodec
+	}
+	c.writeTimeout = config.WriteTimeout
+	if c.writeTimeout <= 0 {
+		c.writeTimeout = defaultWriteTimeout
+	}
+	if config.MaxInFlight > 0 {
+		c.inFlight = make(chan struct{}, config.MaxInFlight)
+	}
+	if config.MaxHandlers > 0 {
+		c.handlers = make(chan struct{}, config.MaxHandlers)
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	c.ws.Go(c.readLoop)
+	return c
+}
+
+// WebSocket returns the underlying websocket connection.
+func (c *Conn) WebSocket() *websocket.Conn {
+	return c.ws
+}
+
+// Close closes the underlying websocket connection. Pending calls return
+// ErrClosed.
+func (c *Conn) Close() error {
+	c.shutdown(ErrClosed)
+	return c.ws.Close()
+}
+
+// Done returns a channel that's closed when the connection stops reading
+// from the peer.
+func (c *Conn) Done() <-chan struct{} {
+	return c.ctx.Done()
+}
+
+// Err returns the error that stopped the connection or nil if the connection
+// is active.
+func (c *Conn) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *Conn) shutdown(err error) {
+	c.mu.Lock()
+	if c.err == nil {
+		c.err = err
+	}
+	c.pending = nil
+	c.mu.Unlock()
+	c.cancel()
+}
+
+// Call invokes method on the peer and waits for the response. The returned
+// error is an *Error if the peer responded with an error.
+func (c *Conn) Call(ctx context.Context, method string, payload []byte) ([]byte, error) {
+	call := &BatchCall{Method: method, Payload: payload}
+	if err := c.do(ctx, []*BatchCall{call}, false); err != nil {
+		return nil, err
+	}
+	return call.Result, call.Error
+}
+
+// BatchCall is a call sent in a batch with the Batch method.
+type BatchCall struct {
+	Method  string
+	Payload []byte
+
+	// Notify specifies that the call is a notification.
+	Notify bool
+
+	// Result and Error are set from the peer's response to the call.
+	Result []byte
+	Error  error
+}
+
+// Batch sends the calls to the peer in a single websocket message and waits
+// for the responses. The connection codec must support batches.
+//
+// Batch returns an error if the batch could not be sent or the responses
+// were not received. Errors reported by the peer for individual calls are
+// stored in the Error field of the corresponding call.
+func (c *Conn) Batch(ctx context.Context, calls []*BatchCall) error {
+	return c.do(ctx, calls, true)
+}
+
+func (c *Conn) do(ctx context.Context, calls []*BatchCall, batch bool) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	if c.inFlight != nil {
+		select {
+		case c.inFlight <- struct{}{}:
+			defer func() { <-c.inFlight }()
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.ctx.Done():
+			return c.Err()
+		}
+	}
+
+	msgs := make([]*Message, len(calls))
+	ch := make(chan *Message, len(calls))
+	waiting := make(map[string]*BatchCall)
+
+	c.mu.Lock()
+	if c.pending == nil {
+		err := c.err
+		c.mu.Unlock()
+		return err
+	}
+	for i, call := range calls {
+		msgs[i] = &Message{Method: call.Method, Payload: call.Payload}
+		if !call.Notify {
+			c.nextID++
+			id := strconv.FormatUint(c.nextID, 10)
+			msgs[i].ID = id
+			c.pending[id] = ch
+			waiting[id] = call
+		}
+	}
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		if c.pending != nil {
+			for id := range waiting {
+				delete(c.pending, id)
+			}
+		}
+		c.mu.Unlock()
+	}()
+
+	if err := c.write(ctx, msgs, batch); err != nil {
+		return err
+	}
+
+	for len(waiting) > 0 {
+		select {
+		case m := <-ch:
+			call := waiting[m.ID]
+			delete(waiting, m.ID)
+			if m.Error != nil {
+				call.Error = m.Error
+			} else {
+				call.Result = m.Payload
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.ctx.Done():
+			return c.Err()
+		}
+	}
+	return nil
+}
+
+// Notify sends a request to the peer that does not receive a response.
+func (c *Conn) Notify(ctx context.Context, method string, payload []byte) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	return c.write(ctx, []*Message{{Method: method, Payload: payload}}, false)
+}
+
+func (c *Conn) write(ctx context.Context, msgs []*Message, batch bool) error {
+	messageType, p, err := c.codec.Encode(msgs, batch)
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// The deadline of ctx is not used because a write that times out
+	// breaks the connection for all callers.
+	if err := c.ws.SetWriteDeadline(time.Now().Add(c.writeTimeout)); err != nil {
+		return err
+	}
+	return c.ws.WriteMessage(messageType, p)
+}
+
+func (c *Conn) readLoop() {
+	for {
+		messageType, p, err := c.ws.ReadMessage()
+		if err != nil {
+			c.shutdown(err)
+			return
+		}
+
+		msgs, batch, err := c.codec.Decode(messageType, p)
+		if err != nil {
+			m := &Message{Response: true, Error: toError(err)}
+			if err := c.write(c.ctx, []*Message{m}, false); err != nil {
+				c.ws.Close()
+			}
+			continue
+		}
+
+		var requests []*Message
+		for _, m := range msgs {
+			if m.Response {
+				c.deliver(m)
+			} else {
+				requests = append(requests, m)
+			}
+		}
+		if len(requests) > 0 && !c.serve(requests, batch) {
+			return
+		}
+	}
+}
+
+func (c *Conn) deliver(m *Message) {
+	c.mu.Lock()
+	ch := c.pending[m.ID]
+	delete(c.pending, m.ID)
+	c.mu.Unlock()
+	if ch != nil {
+		ch <- m
+	}
+}
+
+// serve starts the handlers for requests and a goroutine that writes the
+// responses. serve waits for a handler slot before each handler is started
+// and returns false if the connection stops while waiting.
+func (c *Conn) serve(requests []*Message, batch bool) bool {
+	responses := make([]*Message, len(requests))
+	var wg sync.WaitGroup
+	for i, m := range requests {
+		if c.handlers != nil {
+			select {
+			case c.handlers <- struct{}{}:
+			case <-c.ctx.Done():
+				// The connection stopped. The responses of the started
+				// handlers are not sent.
+				return false
+			}
+		}
+		wg.Add(1)
+		go func(i int, m *Message) {
+			defer wg.Done()
+			if c.handlers != nil {
+				defer func() { <-c.handlers }()
+			}
+			responses[i] = c.handle(m)
+		}(i, m)
+	}
+	go func() {
+		wg.Wait()
+		c.respond(responses, batch)
+	}()
+	return true
+}
+
+// respond writes the responses to the peer.
+func (c *Conn) respond(responses []*Message, batch bool) {
+	var out []*Message
+	for _, r := range responses {
+		if r != nil {
+			out = append(out, r)
+		}
+	}
+	if len(out) == 0 {
+		return
+	}
+	if !batch {
+		for _, r := range out {
+			if err := c.write(c.ctx, []*Message{r}, false); err != nil {
+				c.ws.Close()
+				return
+			}
+		}
+		return
+	}
+	if err := c.write(c.ctx, out, true); err != nil {
+		c.ws.Close()
+	}
+}
+
+// handle runs the handler for a single request and returns the response or
+// nil if the request is a notification. A panic in the handler is reported
+// to the peer as an internal error.
+func (c *Conn) handle(m *Message) (r *Message) {
+	defer func() {
+		if v := recover(); v != nil && m.ID != "" {
+			r = &Message{Response: true, ID: m.ID, Error: &Error{Code: CodeInternalError, Message: "internal error"}}
+		}
+	}()
+
+	if m.Error != nil {
+		// The codec rejected the request.
+		return &Message{Response: true, ID: m.ID, Error: m.Error}
+	}
+
+	var (
+		result []byte
+		err    error
+	)
+	if c.handler == nil {
+		err = &Error{Code: CodeMethodNotFound, Message: "method not found: " + m.Method}
+	} else {
+		result, err = c.handler.ServeRPC(c.ctx, &Request{Conn: c, ID: m.ID, Method: m.Method, Payload: m.Payload})
+	}
+
+	if m.ID == "" {
+		return nil
+	}
+	r = &Message{Response: true, ID: m.ID}
+	if err != nil {
+		r.Error = toError(err)
+	} else {
+		r.Payload = result
+	}
+	return r
+}
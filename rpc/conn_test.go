@@ -0,0 +1,240 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newTestConns returns a pair of connected RPC endpoints. The server endpoint
+// uses the given configuration.
+func newTestConns(t *testing.T, serverConfig, clientConfig *Config) (client, server *Conn) {
+	t.Helper()
+	ch := make(chan *Conn, 1)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Upgrade: %v", err)
+			return
+		}
+		ch <- NewConn(ws, serverConfig)
+	}))
+	t.Cleanup(s.Close)
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(s.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	client = NewConn(ws, clientConfig)
+	server = <-ch
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return client, server
+}
+
+func testMux() *ServeMux {
+	mux := NewServeMux()
+	mux.HandleFunc("add", func(ctx context.Context, req *Request) ([]byte, error) {
+		var args []int
+		if err := json.Unmarshal(req.Payload, &args); err != nil {
+			return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+		}
+		sum := 0
+		for _, a := range args {
+			sum += a
+		}
+		return json.Marshal(sum)
+	})
+	mux.HandleFunc("fail", func(ctx context.Context, req *Request) ([]byte, error) {
+		return nil, errors.New("failed")
+	})
+	mux.HandleFunc("panic", func(ctx context.Context, req *Request) ([]byte, error) {
+		panic("handler bug")
+	})
+	mux.HandleFunc("block", func(ctx context.Context, req *Request) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	return mux
+}
+
+func TestCall(t *testing.T) {
+	client, _ := newTestConns(t, &Config{Handler: testMux()}, nil)
+	ctx := context.Background()
+
+	result, err := client.Call(ctx, "add", []byte(`[1, 2, 3]`))
+	if err != nil {
+		t.Fatalf("Call returned error %v", err)
+	}
+	if strings.TrimSpace(string(result)) != "6" {
+		t.Errorf("Call returned %s, want 6", result)
+	}
+
+	var tests = []struct {
+		method  string
+		payload string
+		code    int
+	}{
+		{"add", `"x"`, CodeInvalidParams},
+		{"fail", ``, CodeInternalError},
+		{"panic", ``, CodeInternalError},
+		{"missing", ``, CodeMethodNotFound},
+	}
+	for _, tt := range tests {
+		_, err := client.Call(ctx, tt.method, []byte(tt.payload))
+		var e *Error
+		if !errors.As(err, &e) || e.Code != tt.code {
+			t.Errorf("Call(%q) returned error %v, want code %d", tt.method, err, tt.code)
+		}
+	}
+}
+
+func TestCallConcurrent(t *testing.T) {
+	client, _ := newTestConns(t, &Config{Handler: testMux(), MaxHandlers: 2}, &Config{MaxInFlight: 3})
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p, _ := json.Marshal([]int{i, i})
+			result, err := client.Call(context.Background(), "add", p)
+			if err != nil {
+				t.Errorf("Call returned error %v", err)
+				return
+			}
+			var sum int
+			if err := json.Unmarshal(result, &sum); err != nil || sum != 2*i {
+				t.Errorf("Call returned %s, %v, want %d", result, err, 2*i)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// batchCodec encodes the messages and the batch flag as JSON.
+type batchCodec struct{}
+
+type batchMessage struct {
+	Batch bool
+	Msgs  []*Message
+}
+
+func (batchCodec) Decode(messageType int, p []byte) ([]*Message, bool, error) {
+	var m batchMessage
+	err := json.Unmarshal(p, &m)
+	return m.Msgs, m.Batch, err
+}
+
+func (batchCodec) Encode(msgs []*Message, batch bool) (int, []byte, error) {
+	p, err := json.Marshal(&batchMessage{Batch: batch, Msgs: msgs})
+	return websocket.TextMessage, p, err
+}
+
+func TestMaxHandlersBatch(t *testing.T) {
+	const maxHandlers = 2
+	var mu sync.Mutex
+	running, peak := 0, 0
+	mux := NewServeMux()
+	mux.HandleFunc("slow", func(ctx context.Context, req *Request) ([]byte, error) {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return []byte(`true`), nil
+	})
+	client, _ := newTestConns(t, &Config{Codec: batchCodec{}, Handler: mux, MaxHandlers: maxHandlers}, &Config{Codec: batchCodec{}})
+	calls := make([]*BatchCall, 6)
+	for i := range calls {
+		calls[i] = &BatchCall{Method: "slow"}
+	}
+	if err := client.Batch(context.Background(), calls); err != nil {
+		t.Fatal(err)
+	}
+	for _, call := range calls {
+		if call.Error != nil {
+			t.Errorf("call returned error %v", call.Error)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if peak > maxHandlers {
+		t.Errorf("%d handlers ran concurrently, want at most %d", peak, maxHandlers)
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	client, _ := newTestConns(t, &Config{Handler: testMux()}, &Config{Timeout: 50 * time.Millisecond})
+	_, err := client.Call(context.Background(), "block", nil)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Call returned error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	received := make(chan string, 1)
+	mux := NewServeMux()
+	mux.HandleFunc("event", func(ctx context.Context, req *Request) ([]byte, error) {
+		received <- string(req.Payload) + req.ID
+		return []byte(`"ignored"`), nil
+	})
+	client, _ := newTestConns(t, &Config{Handler: mux}, nil)
+	if err := client.Notify(context.Background(), "event", []byte(`"hello"`)); err != nil {
+		t.Fatalf("Notify returned error %v", err)
+	}
+	select {
+	case s := <-received:
+		if s != `"hello"` {
+			t.Errorf("handler received %s, want %s", s, `"hello"`)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for notification")
+	}
+}
+
+func TestBidirectional(t *testing.T) {
+	client, server := newTestConns(t, &Config{Handler: testMux()}, &Config{Handler: testMux()})
+	for _, c := range []*Conn{client, server} {
+		result, err := c.Call(context.Background(), "add", []byte(`[2, 2]`))
+		if err != nil || strings.TrimSpace(string(result)) != "4" {
+			t.Errorf("Call returned %s, %v, want 4", result, err)
+		}
+	}
+}
+
+func TestCallClosed(t *testing.T) {
+	client, _ := newTestConns(t, &Config{Handler: testMux()}, nil)
+	errs := make(chan error, 1)
+	go func() {
+		_, err := client.Call(context.Background(), "block", nil)
+		errs <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	client.Close()
+	if err := <-errs; err != ErrClosed {
+		t.Errorf("Call returned error %v, want %v", err, ErrClosed)
+	}
+	if _, err := client.Call(context.Background(), "add", nil); err != ErrClosed {
+		t.Errorf("Call after close returned error %v, want %v", err, ErrClosed)
+	}
+	<-client.Done()
+}
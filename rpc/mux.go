@@ -0,0 +1,114 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+)
+
+// Error codes. The values match the codes defined by the JSON-RPC 2.0
+// specification.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Error is an error response to a request.
+//
+// Handlers return an *Error to control the code and data sent to the peer.
+// Other errors returned from a handler are sent to the peer with code
+// CodeInternalError.
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return "rpc: error " + strconv.Itoa(e.Code) + ": " + e.Message
+}
+
+func toError(err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+	return &Error{Code: CodeInternalError, Message: err.Error()}
+}
+
+// Request is a request received from the peer.
+type Request struct {
+	// Conn is the connection on which the request was received.
+	Conn *Conn
+
+	// ID is the request ID. The ID is empty for notifications.
+	ID string
+
+	// Method is the name of the invoked method.
+	Method string
+
+	// Payload is the encoded request parameters.
+	Payload []byte
+}
+
+// A Handler responds to RPC requests.
+//
+// The context passed to ServeRPC is canceled when the connection is closed.
+// The result returned for a notification is discarded.
+type Handler interface {
+	ServeRPC(ctx context.Context, req *Request) (result []byte, err error)
+}
+
+// HandlerFunc adapts a function to the Handler interface.
+type HandlerFunc func(ctx context.Context, req *Request) ([]byte, error)
+
+// ServeRPC calls f(ctx, req).
+func (f HandlerFunc) ServeRPC(ctx context.Context, req *Request) ([]byte, error) {
+	return f(ctx, req)
+}
+
+// ServeMux dispatches requests to the handler registered for the request
+// method. Requests for unregistered methods fail with CodeMethodNotFound.
+//
+// It is safe to call ServeMux's methods concurrently.
+type ServeMux struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+// NewServeMux allocates and returns a new ServeMux.
+func NewServeMux() *ServeMux {
+	return &ServeMux{handlers: make(map[string]Handler)}
+}
+
+// Handle registers the handler for the given method. Handle replaces any
+// handler previously registered for the method.
+func (mux *ServeMux) Handle(method string, h Handler) {
+	mux.mu.Lock()
+	mux.handlers[method] = h
+	mux.mu.Unlock()
+}
+
+// HandleFunc registers the handler function for the given method.
+func (mux *ServeMux) HandleFunc(method string, f func(ctx context.Context, req *Request) ([]byte, error)) {
+	mux.Handle(method, HandlerFunc(f))
+}
+
+// ServeRPC dispatches the request to the handler registered for the request
+// method.
+func (mux *ServeMux) ServeRPC(ctx context.Context, req *Request) ([]byte, error) {
+	mux.mu.RLock()
+	h := mux.handlers[req.Method]
+	mux.mu.RUnlock()
+	if h == nil {
+		return nil, &Error{Code: CodeMethodNotFound, Message: "method not found: " + req.Method}
+	}
+	return h.ServeRPC(ctx, req)
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"errors"
+	"sync"
+)
+
+// Priority is the class of a message in a SendQueue.
+type Priority int
+
+// Priority classes from the most to the least urgent.
+const (
+	// PriorityControl is for messages that manage the connection, such as
+	// pings and close messages.
+	PriorityControl Priority = iota
+
+	// PriorityRealtime is for small, latency sensitive messages, such as
+	// presence and typing events.
+	PriorityRealtime
+
+	// PriorityBulk is for large transfers, such as the chunks of a file.
+	PriorityBulk
+
+	numPriorities
+)
+
+const defaultStarvationLimit = 8
+
+var (
+	// ErrQueueFull is returned by SendQueue.Send when the class of the
+	// message has SendQueueConfig.MaxQueued messages queued.
+	ErrQueueFull = errors.New("websocket: send queue full")
+
+	errQueueClosed = errors.New("websocket: send queue closed")
+	errBadPriority = errors.New("websocket: bad priority")
+)
+
+// SendQueueConfig specifies the configuration of a SendQueue. The zero
+// value is a valid configuration.
+type SendQueueConfig struct {
+	// StarvationLimit is the number of messages of more urgent classes that
+	// may be sent while a message of a class waits. The waiting message is
+	// sent next when the limit is reached. If StarvationLimit is zero, 8 is
+	// used.
+	StarvationLimit int
+
+	// MaxQueued is the maximum number of messages queued in each class. If
+	// MaxQueued is zero, the number is not limited.
+	MaxQueued int
+}
+
+type queuedMessage struct {
+	messageType int
+	data        []byte
+}
+
+// SendQueue writes messages to a connection from a goroutine in the order of
+// their priority classes, so that queued bulk messages do not delay urgent
+// messages. Messages of the same class are written in the order that they
+// are queued. The bytes of queued messages are included in
+// Conn.BufferedAmount.
+//
+// The application must not write to the connection with other methods
+// while the queue is running. The methods of SendQueue are safe for
+// concurrent use.
+type SendQueue struct {
+	c       *Conn
+	limit   int
+	max     int
+	mu      sync.Mutex
+	cond    *sync.Cond
+	classes [numPriorities][]queuedMessage
+	skipped [numPriorities]int // more urgent messages sent while the class waited
+	closing bool
+	err     error
+	done    chan struct{}
+}
+
+// NewSendQueue starts a send queue for c. A nil config is the zero
+// configuration.
+func NewSendQueue(c *Conn, config *SendQueueConfig) *SendQueue {
+	if config == nil {
+		config = &SendQueueConfig{}
+	}
+	q := &SendQueue{
+		c:     c,
+		limit: config.StarvationLimit,
+		max:   config.MaxQueued,
+		done:  make(chan struct{}),
+	}
+	if q.limit <= 0 {
+		q.limit = defaultStarvationLimit
+	}
+	q.cond = sync.NewCond(&q.mu)
+	go q.run()
+	return q
+}
+
+// Send queues a message with the priority. Send returns the error that
+// stopped the queue, if any.
+func (q *SendQueue) Send(p Priority, messageType int, data []byte) error {
+	if p < 0 || p >= numPriorities {
+		return errBadPriority
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.err != nil {
+		return q.err
+	}
+	if q.closing {
+		return errQueueClosed
+	}
+	if q.max > 0 && len(q.classes[p]) >= q.max {
+		return ErrQueueFull
+	}
+	q.classes[p] = append(q.classes[p], queuedMessage{messageType: messageType, data: data})
+	q.c.writeQueued.Add(int64(len(data)))
+	q.c.addMemory(memQueued, int64(len(data)))
+	q.cond.Signal()
+	return nil
+}
+
+// Len returns the number of queued messages with the priority.
+func (q *SendQueue) Len(p Priority) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.classes[p])
+}
+
+// Close stops accepting messages and waits until the queued messages are
+// written. Close returns the error that stopped the queue, if any.
+func (q *SendQueue) Close() error {
+	q.mu.Lock()
+	q.closing = true
+	q.cond.Signal()
+	q.mu.Unlock()
+	<-q.done
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.err
+}
+
+// next removes the next message from the queue. The mutex is held.
+func (q *SendQueue) next() (queuedMessage, bool) {
+	// Serve the least urgent starved class, otherwise the most urgent
+	// class with messages.
+	chosen := Priority(-1)
+	for p := numPriorities - 1; p >= 0; p-- {
+		if len(q.classes[p]) > 0 && q.skipped[p] >= q.limit {
+			chosen = p
+			break
+		}
+	}
+	if chosen < 0 {
+		for p := Priority(0); p < numPriorities; p++ {
+			if len(q.classes[p]) > 0 {
+				chosen = p
+				break
+			}
+		}
+	}
+	if chosen < 0 {
+		return queuedMessage{}, false
+	}
+	for p := chosen + 1; p < numPriorities; p++ {
+		if len(q.classes[p]) > 0 {
+			q.skipped[p]++
+		}
+	}
+	q.skipped[chosen] = 0
+	m := q.classes[chosen][0]
+	q.classes[chosen][0] = queuedMessage{}
+	q.classes[chosen] = q.classes[chosen][1:]
+	return m, true
+}
+
+func (q *SendQueue) run() {
+	defer close(q.done)
+	for {
+		q.mu.Lock()
+		m, ok := q.next()
+		for !ok && !q.closing {
+			q.cond.Wait()
+			m, ok = q.next()
+		}
+		q.mu.Unlock()
+		if !ok {
+			return
+		}
+
+		err := q.c.WriteMessage(m.messageType, m.data)
+		q.c.writeQueued.Add(-int64(len(m.data)))
+		q.c.addMemory(memQueued, -int64(len(m.data)))
+		if err != nil {
+			q.mu.Lock()
+			q.err = err
+			for p := range q.classes {
+				for _, m := range q.classes[p] {
+					q.c.writeQueued.Add(-int64(len(m.data)))
+					q.c.addMemory(memQueued, -int64(len(m.data)))
+				}
+				q.classes[p] = nil
+			}
+			q.mu.Unlock()
+			return
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// waitQueued waits until the queue holds n messages with the priority.
+func waitQueued(t *testing.T, q *SendQueue, p Priority, n int) {
+	t.Helper()
+	for start := time.Now(); q.Len(p) != n; time.Sleep(time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("Len(%d) = %d, want %d", p, q.Len(p), n)
+		}
+	}
+}
+
+func TestSendQueueOrder(t *testing.T) {
+	p1, p2 := net.Pipe()
+	defer p1.Close()
+	defer p2.Close()
+	wc := newConn(p1, true, 1024, 1024, nil, nil, nil)
+	rc := newConn(p2, false, 1024, 1024, nil, nil, nil)
+
+	q := NewSendQueue(wc, &SendQueueConfig{StarvationLimit: 2})
+
+	// Nothing reads from the pipe, so the writer blocks in the write of b0
+	// while the other messages are queued.
+	q.Send(PriorityBulk, BinaryMessage, []byte("b0"))
+	waitQueued(t, q, PriorityBulk, 0)
+	for _, m := range []struct {
+		p    Priority
+		data string
+	}{
+		{PriorityBulk, "b1"},
+		{PriorityBulk, "b2"},
+		{PriorityBulk, "b3"},
+		{PriorityRealtime, "r1"},
+		{PriorityRealtime, "r2"},
+		{PriorityRealtime, "r3"},
+		{PriorityControl, "c1"},
+	} {
+		if err := q.Send(m.p, TextMessage, []byte(m.data)); err != nil {
+			t.Fatalf("Send(%d, %q) error = %v", m.p, m.data, err)
+		}
+	}
+	if n := wc.BufferedAmount(); n < 14 {
+		t.Errorf("BufferedAmount() = %d, want at least 14", n)
+	}
+
+	// b1 waits for two more urgent messages, then b2 for two more.
+	want := []string{"b0", "c1", "r1", "b1", "r2", "r3", "b2", "b3"}
+	for _, w := range want {
+		_, p, err := rc.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(p) != w {
+			t.Errorf("ReadMessage() = %q, want %q", p, w)
+		}
+	}
+	if err := q.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if n := wc.BufferedAmount(); n != 0 {
+		t.Errorf("BufferedAmount() after Close = %d, want 0", n)
+	}
+	if err := q.Send(PriorityBulk, TextMessage, nil); err != errQueueClosed {
+		t.Errorf("Send() after Close error = %v, want %v", err, errQueueClosed)
+	}
+}
+
+func TestSendQueueFull(t *testing.T) {
+	p1, p2 := net.Pipe()
+	defer p2.Close()
+	wc := newConn(p1, true, 1024, 1024, nil, nil, nil)
+	q := NewSendQueue(wc, &SendQueueConfig{MaxQueued: 1})
+
+	q.Send(PriorityBulk, TextMessage, []byte("a"))
+	waitQueued(t, q, PriorityBulk, 0)
+	if err := q.Send(PriorityBulk, TextMessage, []byte("b")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := q.Send(PriorityBulk, TextMessage, []byte("c")); err != ErrQueueFull {
+		t.Errorf("Send() to full class error = %v, want %v", err, ErrQueueFull)
+	}
+	if err := q.Send(PriorityRealtime, TextMessage, []byte("d")); err != nil {
+		t.Errorf("Send() to other class error = %v", err)
+	}
+	if err := q.Send(numPriorities, TextMessage, nil); err != errBadPriority {
+		t.Errorf("Send() with bad priority error = %v, want %v", err, errBadPriority)
+	}
+
+	// A write error stops the queue and discards the queued messages.
+	p1.Close()
+	if err := q.Close(); err == nil {
+		t.Error("Close() after write error returned nil error")
+	}
+	if n := wc.BufferedAmount(); n != 0 {
+		t.Errorf("BufferedAmount() after write error = %d, want 0", n)
+	}
+	if err := q.Send(PriorityBulk, TextMessage, nil); err == nil || errors.Is(err, errQueueClosed) {
+		t.Errorf("Send() after write error = %v, want write error", err)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+)
+
+// PanicError is the error of a handler that panicked in Serve.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value interface{}
+
+	// Stack is the stack trace of the goroutine at the time of the panic.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("websocket: handler panic: %v", e.Value)
+}
+
+// ServeError describes why Serve returned.
+type ServeError struct {
+	// Reason is the reason why the connection ended.
+	Reason CloseReason
+
+	// Err is the error that ended the read loop: the read error, such as a
+	// *CloseError when the peer closed the connection or an error that
+	// wraps the context's error, the error returned by the handler or a
+	// *PanicError.
+	Err error
+}
+
+func (e *ServeError) Error() string {
+	return "websocket: serve ended (" + e.Reason.String() + "): " + e.Err.Error()
+}
+
+func (e *ServeError) Unwrap() error { return e.Err }
+
+// Serve reads the data messages from the connection and calls handler with
+// each message until ctx is done, the peer closes the connection, reading
+// fails or handler returns an error. Control messages are processed by the
+// connection's handlers while Serve reads. Serve closes the connection and
+// returns a *ServeError that describes why it ended:
+//
+//	err := c.Serve(ctx, func(messageType int, r io.Reader) error {
+//		...
+//	})
+//	if err.(*ServeError).Reason.Initiator == InitiatorError {
+//		log.Print(err)
+//	}
+//
+// The error unwraps to the cause, for use with errors.Is and errors.As.
+//
+// If handler returns an error, Serve sends a close message before closing
+// the connection. The close code is CloseInternalServerErr unless the error
+// is or wraps a *CloseError, whose code and text are sent. A panic in
+// handler is recovered and reported as a *PanicError with the close code
+// CloseInternalServerErr. The rest of a message that handler does not read
+// is discarded.
+//
+// When ctx is done, Serve closes the connection as described in
+// WithContext. Serve replaces the context bound by WithContext. The
+// application must not call the read methods while Serve runs; other
+// goroutines can write to the connection.
+func (c *Conn) Serve(ctx context.Context, handler func(messageType int, r io.Reader) error) error {
+	c.WithContext(ctx)
+	handlerFailed, err := c.serve(handler)
+	if handlerFailed {
+		code, text := CloseInternalServerErr, ""
+		var ce *CloseError
+		if errors.As(err, &ce) {
+			code, text = ce.Code, ce.Text
+		}
+		_ = c.WriteControl(CloseMessage, FormatCloseMessage(code, text), c.clock.Now().Add(writeWait))
+	}
+	c.Close()
+	return &ServeError{Reason: c.CloseReason(), Err: err}
+}
+
+// serve runs the read loop of Serve. handlerFailed reports whether err was
+// returned by handler.
+func (c *Conn) serve(handler func(messageType int, r io.Reader) error) (handlerFailed bool, err error) {
+	for {
+		messageType, r, err := c.NextReader()
+		if err != nil {
+			return false, err
+		}
+		if err := callHandler(handler, messageType, r); err != nil {
+			return true, err
+		}
+	}
+}
+
+// callHandler calls handler and converts a panic to a *PanicError.
+func callHandler(handler func(messageType int, r io.Reader) error, messageType int, r io.Reader) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return handler(messageType, r)
+}
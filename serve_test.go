@@ -0,0 +1,133 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// serveCloseCode returns the code of the *CloseError wrapped by err.
+func serveCloseCode(err error) int {
+	var ce *CloseError
+	if errors.As(err, &ce) {
+		return ce.Code
+	}
+	return 0
+}
+
+func TestServe(t *testing.T) {
+	errHandler := errors.New("handler error")
+	tests := []struct {
+		name       string
+		handle     func(p []byte) error
+		peerClose  bool // the peer closes after the first message
+		cancel     bool // the context is canceled after the first message
+		checkErr   func(err error) bool
+		initiator  string
+		clientCode int // code of the close message received by the client
+	}{
+		{
+			name:       "peer close",
+			peerClose:  true,
+			checkErr:   func(err error) bool { return serveCloseCode(err) == CloseNormalClosure },
+			initiator:  InitiatorPeer,
+			clientCode: CloseNormalClosure,
+		},
+		{
+			name:       "handler error",
+			handle:     func(p []byte) error { return errHandler },
+			checkErr:   func(err error) bool { return errors.Is(err, errHandler) },
+			initiator:  InitiatorLocal,
+			clientCode: CloseInternalServerErr,
+		},
+		{
+			name:       "handler close error",
+			handle:     func(p []byte) error { return &CloseError{Code: 4000, Text: "bad request"} },
+			checkErr:   func(err error) bool { return serveCloseCode(err) == 4000 },
+			initiator:  InitiatorLocal,
+			clientCode: 4000,
+		},
+		{
+			name:       "panic",
+			handle:     func(p []byte) error { panic("boom") },
+			checkErr:   func(err error) bool { var pe *PanicError; return errors.As(err, &pe) && pe.Value == "boom" },
+			initiator:  InitiatorLocal,
+			clientCode: CloseInternalServerErr,
+		},
+		{
+			name:       "context canceled",
+			cancel:     true,
+			checkErr:   func(err error) bool { return errors.Is(err, context.Canceled) },
+			initiator:  InitiatorLocal,
+			clientCode: CloseGoingAway,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := Pipe()
+			defer client.Close()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			received := make(chan string, 1)
+			errc := make(chan error, 1)
+			go func() {
+				errc <- server.Serve(ctx, func(messageType int, r io.Reader) error {
+					p, err := io.ReadAll(r)
+					if err != nil {
+						return err
+					}
+					received <- string(p)
+					if tt.handle != nil {
+						return tt.handle(p)
+					}
+					return nil
+				})
+			}()
+
+			if err := client.WriteMessage(TextMessage, []byte("hello")); err != nil {
+				t.Fatal(err)
+			}
+			if got := <-received; got != "hello" {
+				t.Errorf("handler received %q, want hello", got)
+			}
+			switch {
+			case tt.peerClose:
+				client.WriteMessage(CloseMessage, FormatCloseMessage(CloseNormalClosure, ""))
+			case tt.cancel:
+				cancel()
+			}
+
+			var err error
+			select {
+			case err = <-errc:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Serve did not return")
+			}
+			var se *ServeError
+			if !errors.As(err, &se) {
+				t.Fatalf("Serve() error = %v, want *ServeError", err)
+			}
+			if !tt.checkErr(err) {
+				t.Errorf("Serve() error = %v", err)
+			}
+			if se.Reason.Initiator != tt.initiator {
+				t.Errorf("Reason = %v, want initiator %s", se.Reason, tt.initiator)
+			}
+			if tt.peerClose {
+				return
+			}
+			client.SetReadDeadline(time.Now().Add(5 * time.Second))
+			if _, _, err := client.ReadMessage(); !IsCloseError(err, tt.clientCode) {
+				t.Errorf("client ReadMessage() error = %v, want close %d", err, tt.clientCode)
+			}
+		})
+	}
+}
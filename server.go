@@ -6,6 +6,8 @@ package websocket
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"net"
 	"net/http"
 	"net/url"
@@ -13,13 +15,6 @@ import (
 	"time"
 )
 
-// HandshakeError describes an error with the handshake from the peer.
-type HandshakeError struct {
-	message string
-}
-
-func (e HandshakeError) Error() string { return e.message }
-
 // Upgrader specifies parameters for upgrading an HTTP connection to a
 // WebSocket connection.
 //
@@ -71,19 +66,187 @@ type Upgrader struct {
 	// guarantee that compression will be supported. Currently only "no context
 	// takeover" modes are supported.
 	EnableCompression bool
+
+	// Clock specifies the clock for the handshake timeout and for the
+	// connection's deadlines. If Clock is nil, the system clock is used.
+	Clock Clock
+
+	// Observer observes the lifecycle of the connections opened by the
+	// upgrader.
+	Observer Observer
+
+	// ReadRateLimit limits the rate of messages and bytes read from the
+	// peer on the connections opened by the upgrader. See
+	// Conn.SetReadRateLimit.
+	ReadRateLimit *RateLimit
+
+	// WriteThrottle limits the rate of bytes written to the connections
+	// opened by the upgrader. The throttle is shared by the connections, so it
+	// limits their total rate. See Conn.SetWriteThrottle.
+	WriteThrottle *Throttle
+
+	// OnProtocolViolation is called when a connection opened by the upgrader
+	// fails because the peer violated the protocol. See
+	// Conn.SetProtocolViolationHandler.
+	OnProtocolViolation func(v ProtocolViolation)
+
+	// Strictness selects how strictly the connections opened by the upgrader
+	// check the frames read from the peer. See Conn.SetStrictness.
+	Strictness Strictness
+
+	// Quirks enables workarounds for bugs of the peers of the connections
+	// opened by the upgrader. See Conn.SetQuirks.
+	Quirks Quirk
+
+	// Authenticate authenticates the handshake request before the
+	// connection is hijacked, for example by validating a JWT taken from
+	// the request with BearerToken, CookieToken, QueryToken or
+	// SubprotocolToken. The returned claims are attached to the
+	// connection's context, see Conn.Context and AuthClaims.
+	//
+	// If Authenticate returns an error, the handshake is rejected. An
+	// *AuthError specifies the response. Other errors are reported with
+	// http.StatusUnauthorized through the Error function.
+	Authenticate func(r *http.Request) (claims interface{}, err error)
+
+	// IPFilter allows or denies requests by the IP address of the client.
+	// Denied requests are rejected with http.StatusForbidden before the
+	// other handshake checks. If IPFilter is nil, all addresses are
+	// allowed.
+	IPFilter *IPFilter
+
+	// EnableDeflateFrame specifies if the server should accept the legacy
+	// x-webkit-deflate-frame extension from clients that do not offer
+	// permessage-deflate. The server inflates compressed frames from the
+	// client and writes uncompressed frames. The read limit applies to the
+	// inflated message size.
+	EnableDeflateFrame bool
+
+	// Versions specifies the accepted values of the Sec-WebSocket-Version
+	// header in order of preference. Versions other than Version13 must be
+	// registered with RegisterVersion. If Versions is empty, only
+	// Version13 is accepted.
+	Versions []string
+
+	// DefaultWriteTimeout is the time allowed to write a message on the
+	// connections opened by the upgrader when the application does not set
+	// a write deadline. See Conn.SetDefaultWriteTimeout.
+	DefaultWriteTimeout time.Duration
+
+	// ReadActivityTimeout extends the read deadline of the connections
+	// opened by the upgrader when frames arrive. See
+	// Conn.SetReadActivityTimeout.
+	ReadActivityTimeout time.Duration
+
+	// CompressionPolicy selects the messages compressed by the connections
+	// opened by the upgrader when compression is negotiated. See
+	// Conn.SetCompressionPolicy.
+	CompressionPolicy CompressionPolicy
+
+	// CompressionLevelPolicy selects the compression level of the messages
+	// compressed by the connections opened by the upgrader. See
+	// Conn.SetCompressionLevelPolicy.
+	CompressionLevelPolicy CompressionLevelPolicy
+
+	// FlowControlWindow enables the x-flow-control extension on the
+	// connections opened by the upgrader when the client offers it. The
+	// window is the number of bytes the client may send before the
+	// application consumes them. If FlowControlWindow is zero, the extension
+	// is not negotiated. See Conn.FlowControlNegotiated.
+	FlowControlWindow int
+
+	// Spool configures the spooling of large messages to temporary files on
+	// the connections opened by the upgrader. See Conn.SetSpool.
+	Spool *SpoolConfig
+
+	// ResponseHeader is called after the negotiation of the handshake and
+	// before the connection is hijacked, to add response headers that
+	// depend on the negotiated values, such as a session or tracing header.
+	// The header holds a copy of the responseHeader argument of Upgrade
+	// that the function can change. The function must not set the
+	// Sec-WebSocket-Extensions header. If the function returns an error,
+	// the handshake is rejected with http.StatusInternalServerError.
+	ResponseHeader func(r *http.Request, n Negotiation, header http.Header) error
+
+	// Labels returns the labels of the connection upgraded from the request,
+	// such as the tenant of an authenticated client. See Conn.SetLabel.
+	Labels func(r *http.Request) map[string]string
+
+	// SocketOptions are the options of the TCP connections upgraded by the
+	// upgrader. If SocketOptions is nil, the connections are not changed.
+	SocketOptions *SocketOptions
+
+	// AccessLog is called after every upgrade, successful or not, with the
+	// request, the negotiated values and the outcome, to log websocket
+	// endpoints like HTTP handlers. The function is called before Upgrade
+	// returns and must not use the connection.
+	AccessLog func(e AccessLogEntry)
+
+	// Fallback handles the handshake requests whose Connection or Upgrade
+	// header was removed by an intermediary, see
+	// IsStrippedWebSocketUpgrade, for example by serving a long-polling
+	// transport to the client. Upgrade calls Fallback instead of replying
+	// with an error response and returns a HandshakeError with zero Status
+	// that matches ErrUpgradeHeadersStripped. If Fallback is nil, Upgrade
+	// replies with an error response. The request method and origin are
+	// checked before Fallback is called.
+	Fallback http.Handler
 }
 
-func (u *Upgrader) returnError(w http.ResponseWriter, r *http.Request, status int, reason string) (*Conn, error) {
-	err := HandshakeError{reason}
+// returnError replies to a failed handshake. The kind is a short description
+// of the failure for the expvar counters.
+func (u *Upgrader) returnError(w http.ResponseWriter, r *http.Request, status int, kind, reason string) (*Conn, error) {
+	return u.returnHandshakeError(w, r, kind, HandshakeError{message: reason, Status: status})
+}
+
+// returnHandshakeError replies to a failed handshake with the status of err.
+func (u *Upgrader) returnHandshakeError(w http.ResponseWriter, r *http.Request, kind string, err HandshakeError) (*Conn, error) {
+	countUpgradeFailure(kind)
 	if u.Error != nil {
-		u.Error(w, r, status, err)
+		u.Error(w, r, err.Status, err)
 	} else {
-		w.Header().Set("Sec-Websocket-Version", "13")
-		http.Error(w, http.StatusText(status), status)
+		w.Header().Set("Sec-Websocket-Version", strings.Join(u.versions(), ", "))
+		http.Error(w, http.StatusText(err.Status), err.Status)
 	}
 	return nil, err
 }
 
+// rejectStripped rejects a handshake request whose upgrade headers were
+// removed by an intermediary, or passes it to the Fallback handler.
+func (u *Upgrader) rejectStripped(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	reason := "websocket: the Connection or Upgrade header of the handshake request was removed by an intermediary"
+	if !r.ProtoAtLeast(1, 1) {
+		reason += " that forwarded the request with " + r.Proto
+	}
+	reason += "; use a fallback transport or configure the intermediary to forward websocket upgrades"
+	err := HandshakeError{message: reason, cause: ErrUpgradeHeadersStripped}
+	if u.Fallback != nil {
+		countUpgradeFailure("stripped")
+		u.Fallback.ServeHTTP(w, r)
+		return nil, err
+	}
+	err.Status = http.StatusBadRequest
+	if tokenListContainsValue(r.Header, "Connection", "upgrade") {
+		w.Header().Set("Upgrade", "websocket")
+		err.Status = http.StatusUpgradeRequired
+	}
+	return u.returnHandshakeError(w, r, "stripped", err)
+}
+
+// handshakeStatus returns the status of the handshake response for the
+// result of Upgrade.
+func handshakeStatus(err error) int {
+	if err == nil {
+		return http.StatusSwitchingProtocols
+	}
+	// Errors after the connection is hijacked have no response.
+	var he HandshakeError
+	if errors.As(err, &he) {
+		return he.Status
+	}
+	return 0
+}
+
 // checkSameOrigin returns true if the origin is not set or is equal to the request host.
 func checkSameOrigin(r *http.Request) bool {
 	origin := r.Header["Origin"]
@@ -97,6 +260,23 @@ func checkSameOrigin(r *http.Request) bool {
 	return equalASCIIFold(u.Host, r.Host)
 }
 
+// permessageDeflateResponse is the extension accepted by a server that
+// negotiates permessage-deflate.
+const permessageDeflateResponse = "permessage-deflate; server_no_context_takeover; client_no_context_takeover"
+
+// Negotiation is the result of the negotiation of an opening handshake.
+type Negotiation struct {
+	// Subprotocol is the selected subprotocol, or empty if none.
+	Subprotocol string
+
+	// Extensions is the value of the Sec-WebSocket-Extensions response
+	// header, or empty if no extension was accepted.
+	Extensions string
+
+	// Version is the protocol version of the connection.
+	Version string
+}
+
 func (u *Upgrader) selectSubprotocol(r *http.Request, responseHeader http.Header) string {
 	if u.Subprotocols != nil {
 		clientProtocols := Subprotocols(r)
@@ -122,48 +302,103 @@ func (u *Upgrader) selectSubprotocol(r *http.Request, responseHeader http.Header
 // If the upgrade fails, then Upgrade replies to the client with an HTTP error
 // response.
 func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request, responseHeader http.Header) (*Conn, error) {
+	var start time.Time
+	if u.AccessLog != nil {
+		start = clockOrSystem(u.Clock).Now()
+	}
+	trace := ContextConnTrace(r.Context())
+	if trace != nil && trace.HandshakeStart != nil {
+		trace.HandshakeStart(r)
+	}
+	c, err := u.upgrade(w, r, responseHeader)
+	if trace != nil {
+		if c != nil {
+			c.trace = trace
+		}
+		if trace.HandshakeDone != nil {
+			trace.HandshakeDone(c, handshakeStatus(err), err)
+		}
+	}
+	if u.AccessLog != nil {
+		u.logAccess(r, c, err, start)
+	}
+	return c, err
+}
+
+func (u *Upgrader) upgrade(w http.ResponseWriter, r *http.Request, responseHeader http.Header) (*Conn, error) {
 	const badHandshake = "websocket: the client is not using the websocket protocol: "
 
+	if u.IPFilter != nil && !u.IPFilter.Allowed(r) {
+		return u.returnError(w, r, http.StatusForbidden, "ip", "websocket: client address not allowed by Upgrader.IPFilter")
+	}
+
+	checkOrigin := u.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = checkSameOrigin
+	}
+	const (
+		methodNotGET     = badHandshake + "request method is not GET"
+		originNotAllowed = "websocket: request origin not allowed by Upgrader.CheckOrigin"
+	)
+
+	if IsStrippedWebSocketUpgrade(r) {
+		// Only requests that are acceptable apart from the upgrade headers
+		// are passed to the fallback.
+		if r.Method != http.MethodGet {
+			return u.returnError(w, r, http.StatusMethodNotAllowed, "method", methodNotGET)
+		}
+		if !checkOrigin(r) {
+			return u.returnError(w, r, http.StatusForbidden, "origin", originNotAllowed)
+		}
+		return u.rejectStripped(w, r)
+	}
+
 	if !tokenListContainsValue(r.Header, "Connection", "upgrade") {
-		return u.returnError(w, r, http.StatusBadRequest, badHandshake+"'upgrade' token not found in 'Connection' header")
+		return u.returnError(w, r, http.StatusBadRequest, "connection", badHandshake+"'upgrade' token not found in 'Connection' header")
 	}
 
 	if !tokenListContainsValue(r.Header, "Upgrade", "websocket") {
 		w.Header().Set("Upgrade", "websocket")
-		return u.returnError(w, r, http.StatusUpgradeRequired, badHandshake+"'websocket' token not found in 'Upgrade' header")
+		return u.returnError(w, r, http.StatusUpgradeRequired, "upgrade", badHandshake+"'websocket' token not found in 'Upgrade' header")
 	}
 
 	if r.Method != http.MethodGet {
-		return u.returnError(w, r, http.StatusMethodNotAllowed, badHandshake+"request method is not GET")
+		return u.returnError(w, r, http.StatusMethodNotAllowed, "method", methodNotGET)
 	}
 
-	if !tokenListContainsValue(r.Header, "Sec-Websocket-Version", "13") {
-		return u.returnError(w, r, http.StatusBadRequest, "websocket: unsupported version: 13 not found in 'Sec-Websocket-Version' header")
+	version, ok := u.selectVersion(r)
+	if !ok {
+		return u.returnError(w, r, http.StatusBadRequest, "version", u.unsupportedVersion())
 	}
 
 	if _, ok := responseHeader["Sec-Websocket-Extensions"]; ok {
-		return u.returnError(w, r, http.StatusInternalServerError, "websocket: application specific 'Sec-WebSocket-Extensions' headers are unsupported")
+		return u.returnError(w, r, http.StatusInternalServerError, "extensions", "websocket: application specific 'Sec-WebSocket-Extensions' headers are unsupported")
 	}
 
-	checkOrigin := u.CheckOrigin
-	if checkOrigin == nil {
-		checkOrigin = checkSameOrigin
-	}
 	if !checkOrigin(r) {
-		return u.returnError(w, r, http.StatusForbidden, "websocket: request origin not allowed by Upgrader.CheckOrigin")
+		return u.returnError(w, r, http.StatusForbidden, "origin", originNotAllowed)
 	}
 
 	challengeKey := r.Header.Get("Sec-Websocket-Key")
 	if !isValidChallengeKey(challengeKey) {
-		return u.returnError(w, r, http.StatusBadRequest, "websocket: not a websocket handshake: 'Sec-WebSocket-Key' header must be Base64 encoded value of 16-byte in length")
+		return u.returnError(w, r, http.StatusBadRequest, "key", "websocket: not a websocket handshake: 'Sec-WebSocket-Key' header must be Base64 encoded value of 16-byte in length")
+	}
+
+	var claims interface{}
+	if u.Authenticate != nil {
+		var err error
+		if claims, err = u.authenticate(w, r); err != nil {
+			return nil, err
+		}
 	}
 
 	subprotocol := u.selectSubprotocol(r, responseHeader)
 
 	// Negotiate PMCE
-	var compress bool
+	var compress, deflateFrame bool
+	exts := parseExtensions(r.Header)
 	if u.EnableCompression {
-		for _, ext := range parseExtensions(r.Header) {
+		for _, ext := range exts {
 			if ext[""] != "permessage-deflate" {
 				continue
 			}
@@ -171,10 +406,50 @@ func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request, responseHeade
 			break
 		}
 	}
+	if u.EnableDeflateFrame && !compress {
+		deflateFrame = offersDeflateFrame(exts)
+	}
+	var flow *flowControl
+	if u.FlowControlWindow > 0 {
+		for _, ext := range exts {
+			if ext[""] != flowControlExtension {
+				continue
+			}
+			if window, ok := parseFlowControlWindow(ext); ok {
+				flow = newFlowControl(flowControlWindow(u.FlowControlWindow), window)
+			}
+			break
+		}
+	}
+	var extensions []string
+	switch {
+	case compress:
+		extensions = append(extensions, permessageDeflateResponse)
+	case deflateFrame:
+		extensions = append(extensions, deflateFrameExtension)
+	}
+	if flow != nil {
+		extensions = append(extensions, flowControlOffer(flow.window))
+	}
+
+	if u.ResponseHeader != nil {
+		n := Negotiation{Subprotocol: subprotocol, Extensions: strings.Join(extensions, ", "), Version: version.Name}
+		h := responseHeader.Clone()
+		if h == nil {
+			h = make(http.Header)
+		}
+		if err := u.ResponseHeader(r, n, h); err != nil {
+			return u.returnError(w, r, http.StatusInternalServerError, "response_header", "websocket: response header: "+err.Error())
+		}
+		if _, ok := h["Sec-Websocket-Extensions"]; ok {
+			return u.returnError(w, r, http.StatusInternalServerError, "extensions", "websocket: application specific 'Sec-WebSocket-Extensions' headers are unsupported")
+		}
+		responseHeader = h
+	}
 
 	netConn, brw, err := http.NewResponseController(w).Hijack()
 	if err != nil {
-		return u.returnError(w, r, http.StatusInternalServerError,
+		return u.returnError(w, r, http.StatusInternalServerError, "hijack",
 			"websocket: hijack: "+err.Error())
 	}
 
@@ -183,6 +458,7 @@ func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request, responseHeade
 	// function.
 	defer func() {
 		if netConn != nil {
+			countUpgradeFailure("io")
 			// It's safe to ignore the error from Close() because this code is
 			// only executed when returning a more important error to the
 			// application.
@@ -190,6 +466,10 @@ func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request, responseHeade
 		}
 	}()
 
+	if err := u.SocketOptions.apply(netConn); err != nil {
+		return nil, err
+	}
+
 	var br *bufio.Reader
 	if u.ReadBufferSize == 0 && brw.Reader.Size() > 256 {
 		// Use hijacked buffered reader as the connection reader.
@@ -211,12 +491,32 @@ func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request, responseHeade
 	}
 
 	c := newConn(netConn, true, u.ReadBufferSize, u.WriteBufferSize, u.WriteBufferPool, br, writeBuf)
+	// Remove the buffers of the connection from the memory total when
+	// returning an error.
+	defer func() {
+		if netConn != nil {
+			c.releaseMemory()
+		}
+	}()
+	c.ctx = valuesContext{r.Context()}
+	if claims != nil {
+		c.ctx = context.WithValue(c.ctx, claimsKey{}, claims)
+	}
 	c.subprotocol = subprotocol
+	c.extensions = strings.Join(extensions, ", ")
+	c.clock = clockOrSystem(u.Clock)
+	if u.Labels != nil {
+		c.setLabels(u.Labels(r))
+	}
 
 	if compress {
 		c.newCompressionWriter = compressNoContextTakeover
 		c.newDecompressionReader = decompressNoContextTakeover
 	}
+	if deflateFrame {
+		c.deflateFrame = &deflateFrameReader{}
+	}
+	c.flow = flow
 
 	// Use larger of hijacked buffer and connection write buffer for header.
 	p := buf
@@ -233,8 +533,10 @@ func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request, responseHeade
 		p = append(p, c.subprotocol...)
 		p = append(p, "\r\n"...)
 	}
-	if compress {
-		p = append(p, "Sec-WebSocket-Extensions: permessage-deflate; server_no_context_takeover; client_no_context_takeover\r\n"...)
+	if len(extensions) > 0 {
+		p = append(p, "Sec-WebSocket-Extensions: "...)
+		p = append(p, strings.Join(extensions, ", ")...)
+		p = append(p, "\r\n"...)
 	}
 	for k, vs := range responseHeader {
 		if k == "Sec-Websocket-Protocol" {
@@ -257,7 +559,7 @@ func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request, responseHeade
 	p = append(p, "\r\n"...)
 
 	if u.HandshakeTimeout > 0 {
-		if err := netConn.SetWriteDeadline(time.Now().Add(u.HandshakeTimeout)); err != nil {
+		if err := netConn.SetWriteDeadline(c.clock.Now().Add(u.HandshakeTimeout)); err != nil {
 			return nil, err
 		}
 	} else {
@@ -280,6 +582,21 @@ func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request, responseHeade
 	// closing the network connection.
 	netConn = nil
 
+	c.SetReadRateLimit(u.ReadRateLimit)
+	c.SetWriteThrottle(u.WriteThrottle)
+	c.SetProtocolViolationHandler(u.OnProtocolViolation)
+	c.SetStrictness(u.Strictness)
+	c.SetQuirks(u.Quirks)
+	c.SetDefaultWriteTimeout(u.DefaultWriteTimeout)
+	c.SetReadActivityTimeout(u.ReadActivityTimeout)
+	c.SetCompressionPolicy(u.CompressionPolicy)
+	c.SetCompressionLevelPolicy(u.CompressionLevelPolicy)
+	c.SetSpool(u.Spool)
+	// The version is applied last so that its adjustments are not
+	// overwritten by the options of the upgrader.
+	c.useVersion(version)
+	countUpgrade(c)
+	c.observeOpen(u.Observer)
 	return c, nil
 }
 
@@ -346,6 +663,17 @@ func IsWebSocketUpgrade(r *http.Request) bool {
 		tokenListContainsValue(r.Header, "Upgrade", "websocket")
 }
 
+// IsStrippedWebSocketUpgrade returns true if the request looks like a
+// WebSocket handshake whose Connection or Upgrade header was removed by an
+// intermediary: the request has the Sec-WebSocket-Key header of a handshake
+// but does not request an upgrade. Proxies that do not support WebSocket,
+// or that forward requests with HTTP/1.0, remove these hop-by-hop headers.
+// Handlers can use IsStrippedWebSocketUpgrade to route such requests to a
+// fallback transport. See also Upgrader.Fallback.
+func IsStrippedWebSocketUpgrade(r *http.Request) bool {
+	return r.Header.Get("Sec-Websocket-Key") != "" && !IsWebSocketUpgrade(r)
+}
+
 type brNetConn struct {
 	br *bufio.Reader
 	net.Conn
@@ -8,6 +8,7 @@ import (
 	"bufio"
 	"bytes"
 	"errors"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -56,6 +57,106 @@ func TestIsWebSocketUpgrade(t *testing.T) {
 	}
 }
 
+var isStrippedWebSocketUpgradeTests = []struct {
+	ok bool
+	h  http.Header
+}{
+	{false, http.Header{}},
+	{false, http.Header{"Connection": {"upgrade"}, "Upgrade": {"websocket"}, "Sec-Websocket-Key": {"key"}}},
+	{true, http.Header{"Sec-Websocket-Key": {"key"}, "Sec-Websocket-Version": {"13"}}},
+	{true, http.Header{"Connection": {"keep-alive"}, "Upgrade": {"websocket"}, "Sec-Websocket-Key": {"key"}}},
+	{true, http.Header{"Connection": {"upgrade"}, "Sec-Websocket-Key": {"key"}}},
+	{false, http.Header{"Connection": {"upgrade"}, "Sec-Websocket-Version": {"13"}}},
+}
+
+func TestIsStrippedWebSocketUpgrade(t *testing.T) {
+	for _, tt := range isStrippedWebSocketUpgradeTests {
+		ok := IsStrippedWebSocketUpgrade(&http.Request{Header: tt.h})
+		if tt.ok != ok {
+			t.Errorf("IsStrippedWebSocketUpgrade(%v) returned %v, want %v", tt.h, ok, tt.ok)
+		}
+	}
+}
+
+func TestUpgradeStrippedHeaders(t *testing.T) {
+	tests := []struct {
+		name       string
+		proto      string
+		connection string
+		fallback   bool
+		status     int
+	}{
+		{"connection removed", "HTTP/1.1", "", false, http.StatusBadRequest},
+		{"upgrade removed", "HTTP/1.1", "upgrade", false, http.StatusUpgradeRequired},
+		{"HTTP/1.0", "HTTP/1.0", "", false, http.StatusBadRequest},
+		{"fallback", "HTTP/1.1", "", true, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+		req.Proto = tt.proto
+		req.ProtoMajor, req.ProtoMinor, _ = http.ParseHTTPVersion(tt.proto)
+		if tt.connection != "" {
+			req.Header.Set("Connection", tt.connection)
+		}
+		req.Header.Set("Sec-Websocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		req.Header.Set("Sec-Websocket-Version", "13")
+
+		var upgrader Upgrader
+		if tt.fallback {
+			upgrader.Fallback = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, "fallback")
+			})
+		}
+		recorder := httptest.NewRecorder()
+		_, err := upgrader.Upgrade(recorder, req, nil)
+		var he HandshakeError
+		if !errors.Is(err, ErrUpgradeHeadersStripped) || !errors.Is(err, ErrBadHandshake) || !errors.As(err, &he) {
+			t.Fatalf("%s: Upgrade() error = %v, want %v", tt.name, err, ErrUpgradeHeadersStripped)
+		}
+		if recorder.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, recorder.Code, tt.status)
+		}
+		if tt.fallback {
+			if he.Status != 0 || recorder.Body.String() != "fallback" {
+				t.Errorf("%s: error status, body = %d, %q, want 0, fallback", tt.name, he.Status, recorder.Body.String())
+			}
+		} else if he.Status != tt.status {
+			t.Errorf("%s: error status = %d, want %d", tt.name, he.Status, tt.status)
+		}
+		if got := strings.Contains(err.Error(), "HTTP/1.0"); got != (tt.proto == "HTTP/1.0") {
+			t.Errorf("%s: error %q mentions HTTP/1.0 = %v", tt.name, err, got)
+		}
+	}
+}
+
+func TestUpgradeStrippedHeadersChecks(t *testing.T) {
+	// The method and origin are checked before the fallback is called.
+	tests := []struct {
+		name   string
+		method string
+		origin string
+		status int
+	}{
+		{"method", http.MethodPost, "", http.StatusMethodNotAllowed},
+		{"origin", http.MethodGet, "http://other.example", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "http://example.com", nil)
+		req.Header.Set("Sec-Websocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		upgrader := Upgrader{Fallback: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("%s: fallback called", tt.name)
+		})}
+		recorder := httptest.NewRecorder()
+		_, err := upgrader.Upgrade(recorder, req, nil)
+		if err == nil || errors.Is(err, ErrUpgradeHeadersStripped) || recorder.Code != tt.status {
+			t.Errorf("%s: Upgrade() error = %v, status = %d, want status %d", tt.name, err, recorder.Code, tt.status)
+		}
+	}
+}
+
 func TestSubProtocolSelection(t *testing.T) {
 	upgrader := Upgrader{
 		Subprotocols: []string{"foo", "bar", "baz"},
@@ -169,3 +270,64 @@ func TestHijack_NotSupported(t *testing.T) {
 		t.Fatalf("got err=%T and status_code=%d", err, recorder.Code)
 	}
 }
+
+func TestUpgraderResponseHeader(t *testing.T) {
+	errSession := errors.New("no session")
+	tests := []struct {
+		name     string
+		compress bool
+		err      error
+		want     string // X-Negotiated header, or empty if the handshake fails
+	}{
+		{"subprotocol", false, nil, "chat||13"},
+		{"compression", true, nil, "chat|" + permessageDeflateResponse + "|13"},
+		{"error", false, errSession, ""},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			responseHeader := http.Header{"X-Static": {"1"}}
+			u := Upgrader{
+				Subprotocols:      []string{"chat"},
+				EnableCompression: true,
+				ResponseHeader: func(r *http.Request, n Negotiation, h http.Header) error {
+					if h.Get("X-Static") != "1" {
+						t.Errorf("header = %v, want X-Static", h)
+					}
+					h.Set("X-Negotiated", n.Subprotocol+"|"+n.Extensions+"|"+n.Version)
+					return tt.err
+				},
+			}
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				c, err := u.Upgrade(w, r, responseHeader)
+				if err != nil {
+					return
+				}
+				c.Close()
+			}))
+			defer s.Close()
+
+			d := Dialer{Subprotocols: []string{"chat"}, EnableCompression: tt.compress}
+			c, resp, err := d.Dial(makeWsProto(s.URL), nil)
+			if tt.err != nil {
+				if err == nil || resp == nil || resp.StatusCode != http.StatusInternalServerError {
+					t.Errorf("Dial() error = %v, want status 500", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.Close()
+			if got := resp.Header.Get("X-Negotiated"); got != tt.want {
+				t.Errorf("X-Negotiated = %q, want %q", got, tt.want)
+			}
+			if resp.Header.Get("X-Static") != "1" {
+				t.Error("X-Static header missing")
+			}
+			if _, ok := responseHeader["X-Negotiated"]; ok {
+				t.Error("ResponseHeader changed the responseHeader argument of Upgrade")
+			}
+		})
+	}
+}
@@ -0,0 +1,428 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sockjs implements a fallback server for clients that cannot
+// complete a WebSocket upgrade, for example because a proxy strips the
+// Upgrade header.
+//
+// The server speaks the SockJS protocol on the websocket, xhr-streaming and
+// xhr-polling transports, so the stock sockjs-client library can be used in
+// browsers. Whatever the transport, the application handles each client
+// through a Session with ReadMessage and WriteMessage methods, like a
+// websocket connection.
+//
+//	s := &sockjs.Server{
+//		Prefix:  "/echo",
+//		Handler: func(sess *sockjs.Session) {
+//			for {
+//				mt, p, err := sess.ReadMessage()
+//				if err != nil {
+//					return
+//				}
+//				sess.WriteMessage(mt, p)
+//			}
+//		},
+//	}
+//	http.Handle("/echo/", s)
+//
+// HTTP transports use a session ID chosen by the client. Sessions are kept
+// in the Server's memory, so all requests for a session must reach the same
+// process.
+//
+// The protocol is described at https://github.com/sockjs/sockjs-protocol.
+package sockjs
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultHeartbeatDelay  = 25 * time.Second
+	defaultDisconnectDelay = 5 * time.Second
+	defaultResponseLimit   = 128 * 1024
+	defaultMaxMessageSize  = 1024 * 1024
+	writeWait              = 10 * time.Second
+)
+
+// Server is an http.Handler that serves SockJS clients. The Server must not
+// be copied after first use.
+type Server struct {
+	// Prefix is the path under which the server is mounted. The prefix is
+	// removed from request paths before routing.
+	Prefix string
+
+	// Handler is called in a new goroutine for each session. The session is
+	// closed when Handler returns.
+	Handler func(sess *Session)
+
+	// Upgrader is used to upgrade requests on the websocket transport. If
+	// Upgrader is nil, a zero Upgrader is used.
+	Upgrader *websocket.Upgrader
+
+	// DisableWebsocket disables the websocket transport. The setting is
+	// reported to clients in the info response.
+	DisableWebsocket bool
+
+	// HeartbeatDelay specifies the time after which a heartbeat frame is sent
+	// to an idle client. If zero, a default of 25 seconds is used.
+	HeartbeatDelay time.Duration
+
+	// DisconnectDelay specifies how long a session is kept when no receiving
+	// request is attached. If zero, a default of 5 seconds is used.
+	DisconnectDelay time.Duration
+
+	// ResponseLimit specifies the number of bytes sent in a streaming
+	// response before the response is ended and the client reconnects. If
+	// zero, a default of 128 KiB is used.
+	ResponseLimit int
+
+	// MaxMessageSize specifies the largest request body on the xhr_send
+	// transport and the largest websocket message read from a client. A
+	// session that receives a larger body or message is closed with the
+	// code 1009. MaxMessageSize also bounds the size of the received
+	// messages that the session buffers for ReadMessage. A client that sends
+	// more waits until the application reads. If zero, a default of 1 MiB
+	// is used.
+	MaxMessageSize int
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+func (s *Server) heartbeatDelay() time.Duration {
+	if s.HeartbeatDelay > 0 {
+		return s.HeartbeatDelay
+	}
+	return defaultHeartbeatDelay
+}
+
+func (s *Server) disconnectDelay() time.Duration {
+	if s.DisconnectDelay > 0 {
+		return s.DisconnectDelay
+	}
+	return defaultDisconnectDelay
+}
+
+func (s *Server) responseLimit() int {
+	if s.ResponseLimit > 0 {
+		return s.ResponseLimit
+	}
+	return defaultResponseLimit
+}
+
+func (s *Server) maxMessageSize() int {
+	if s.MaxMessageSize > 0 {
+		return s.MaxMessageSize
+	}
+	return defaultMaxMessageSize
+}
+
+// ServeHTTP routes the request to the info endpoint or a transport.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, s.Prefix)
+	switch path {
+	case "", "/":
+		w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+		io.WriteString(w, "Welcome to SockJS!\n")
+		return
+	case "/info":
+		s.serveInfo(w, r)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(parts) != 3 || !validID(parts[0]) || !validID(parts[1]) {
+		http.NotFound(w, r)
+		return
+	}
+	id, transport := parts[1], parts[2]
+	switch transport {
+	case "websocket":
+		if s.DisableWebsocket {
+			http.NotFound(w, r)
+			return
+		}
+		s.serveWebsocket(w, r)
+		return
+	case "xhr", "xhr_streaming", "xhr_send":
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	setCORS(w, r)
+	if r.Method == http.MethodOptions {
+		servePreflight(w)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "OPTIONS, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	switch transport {
+	case "xhr":
+		s.serveXHR(w, r, id, false)
+	case "xhr_streaming":
+		s.serveXHR(w, r, id, true)
+	case "xhr_send":
+		s.serveSend(w, r, id)
+	}
+}
+
+// validID reports whether s is a valid server or session ID path segment.
+func validID(s string) bool {
+	return s != "" && !strings.Contains(s, ".")
+}
+
+func setCORS(w http.ResponseWriter, r *http.Request) {
+	h := w.Header()
+	if origin := r.Header.Get("Origin"); origin != "" && origin != "null" {
+		h.Set("Access-Control-Allow-Origin", origin)
+		h.Set("Access-Control-Allow-Credentials", "true")
+	} else {
+		h.Set("Access-Control-Allow-Origin", "*")
+	}
+	if v := r.Header.Get("Access-Control-Request-Headers"); v != "" {
+		h.Set("Access-Control-Allow-Headers", v)
+	}
+}
+
+func servePreflight(w http.ResponseWriter) {
+	h := w.Header()
+	h.Set("Access-Control-Allow-Methods", "OPTIONS, POST")
+	h.Set("Access-Control-Max-Age", "31536000")
+	h.Set("Cache-Control", "public, max-age=31536000")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type info struct {
+	Websocket    bool     `json:"websocket"`
+	CookieNeeded bool     `json:"cookie_needed"`
+	Origins      []string `json:"origins"`
+	Entropy      uint32   `json:"entropy"`
+}
+
+func (s *Server) serveInfo(w http.ResponseWriter, r *http.Request) {
+	setCORS(w, r)
+	switch r.Method {
+	case http.MethodOptions:
+		w.Header().Set("Access-Control-Allow-Methods", "OPTIONS, GET")
+		w.Header().Set("Access-Control-Max-Age", "31536000")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case http.MethodGet:
+	default:
+		w.Header().Set("Allow", "OPTIONS, GET")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
+	json.NewEncoder(w).Encode(&info{
+		Websocket: !s.DisableWebsocket,
+		Origins:   []string{"*:*"},
+		Entropy:   rand.Uint32(),
+	})
+}
+
+// session returns the session with the given ID. If create is true and the
+// session does not exist, a new session is registered and started. The
+// returned isNew reports whether the session was created.
+func (s *Server) session(id string, r *http.Request, create bool) (sess *Session, isNew bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sess := s.sessions[id]; sess != nil || !create {
+		return sess, false
+	}
+	if s.sessions == nil {
+		s.sessions = make(map[string]*Session)
+	}
+	sess = newSession(s, id, r)
+	s.sessions[id] = sess
+	s.start(sess)
+	return sess, true
+}
+
+func (s *Server) remove(sess *Session) {
+	s.mu.Lock()
+	if s.sessions[sess.id] == sess {
+		delete(s.sessions, sess.id)
+	}
+	s.mu.Unlock()
+}
+
+func (s *Server) start(sess *Session) {
+	go func() {
+		defer sess.Close()
+		if s.Handler != nil {
+			s.Handler(sess)
+		}
+	}()
+}
+
+// frameWriter writes frames to a receiving transport.
+type frameWriter interface {
+	writeFrame(frame string) error
+}
+
+// httpFrameWriter writes newline terminated frames to an HTTP response.
+type httpFrameWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	n       int
+}
+
+func (fw *httpFrameWriter) writeFrame(frame string) error {
+	n, err := io.WriteString(fw.w, frame+"\n")
+	fw.n += n
+	if err != nil {
+		return err
+	}
+	if fw.flusher != nil {
+		fw.flusher.Flush()
+	}
+	return nil
+}
+
+// streamingPrelude is sent at the start of xhr-streaming responses to defeat
+// buffering in old browsers.
+var streamingPrelude = strings.Repeat("h", 2048)
+
+func (s *Server) serveXHR(w http.ResponseWriter, r *http.Request, id string, streaming bool) {
+	w.Header().Set("Content-Type", "application/javascript; charset=UTF-8")
+	w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
+	fw := &httpFrameWriter{w: w}
+	fw.flusher, _ = w.(http.Flusher)
+
+	sess, isNew := s.session(id, r, true)
+	if streaming {
+		if err := fw.writeFrame(streamingPrelude); err != nil {
+			return
+		}
+	}
+	if isNew {
+		// The creating request is attached to the session.
+		defer sess.detach()
+		if err := fw.writeFrame(frameOpen); err != nil || !streaming {
+			return
+		}
+	} else if !sess.attach() {
+		fw.writeFrame(closeFrame(2010, "Another connection still open"))
+		return
+	} else {
+		defer sess.detach()
+	}
+
+	limit := 0
+	if streaming {
+		limit = s.responseLimit()
+	}
+	sess.serveFrames(r.Context().Done(), fw, func() bool {
+		return !streaming || fw.n >= limit
+	})
+}
+
+func (s *Server) serveSend(w http.ResponseWriter, r *http.Request, id string) {
+	sess, _ := s.session(id, r, false)
+	if sess == nil {
+		http.NotFound(w, r)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(s.maxMessageSize())))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			sess.CloseWithReason(1009, "Message too big")
+			http.Error(w, "Payload too large.", http.StatusRequestEntityTooLarge)
+		}
+		return
+	}
+	if len(body) == 0 {
+		http.Error(w, "Payload expected.", http.StatusInternalServerError)
+		return
+	}
+	var messages []string
+	if err := json.Unmarshal(body, &messages); err != nil {
+		http.Error(w, "Broken JSON encoding.", http.StatusInternalServerError)
+		return
+	}
+	sess.receive(r.Context().Done(), messages)
+	w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// wsFrameWriter writes frames as websocket text messages.
+type wsFrameWriter struct {
+	ws *websocket.Conn
+}
+
+func (fw wsFrameWriter) writeFrame(frame string) error {
+	if err := fw.ws.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+		return err
+	}
+	return fw.ws.WriteMessage(websocket.TextMessage, []byte(frame))
+}
+
+func (s *Server) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	upgrader := s.Upgrader
+	if upgrader == nil {
+		upgrader = &websocket.Upgrader{}
+	}
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+	ws.SetReadLimit(int64(s.maxMessageSize()))
+
+	// Websocket sessions are not registered because no other request can
+	// refer to them.
+	sess := newSession(s, "", r)
+	fw := wsFrameWriter{ws}
+	if err := fw.writeFrame(frameOpen); err != nil {
+		sess.closeLocal()
+		return
+	}
+	s.start(sess)
+
+	go func() {
+		for {
+			_, p, err := ws.ReadMessage()
+			if errors.Is(err, websocket.ErrReadLimit) {
+				sess.CloseWithReason(1009, "Message too big")
+				return
+			}
+			if err != nil {
+				break
+			}
+			if len(p) == 0 {
+				continue
+			}
+			var messages []string
+			if p[0] == '[' {
+				err = json.Unmarshal(p, &messages)
+			} else {
+				var m string
+				err = json.Unmarshal(p, &m)
+				messages = []string{m}
+			}
+			if err != nil || !sess.receive(nil, messages) {
+				break
+			}
+		}
+		sess.closeLocal()
+	}()
+
+	sess.serveFrames(nil, fw, func() bool { return false })
+}
@@ -0,0 +1,301 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sockjs
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func echo(sess *Session) {
+	for {
+		mt, p, err := sess.ReadMessage()
+		if err != nil {
+			return
+		}
+		if string(p) == "close" {
+			sess.CloseWithReason(3001, "bye")
+			return
+		}
+		if err := sess.WriteMessage(mt, p); err != nil {
+			return
+		}
+	}
+}
+
+func newTestServer(t *testing.T, s *Server) string {
+	s.Prefix = "/echo"
+	if s.Handler == nil {
+		s.Handler = echo
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/echo/", s)
+	hs := httptest.NewServer(mux)
+	t.Cleanup(hs.Close)
+	return hs.URL + "/echo"
+}
+
+func post(t *testing.T, url, body string) (int, string) {
+	resp, err := http.Post(url, "text/plain", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	p, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(p)
+}
+
+func TestInfo(t *testing.T) {
+	url := newTestServer(t, &Server{})
+	resp, err := http.Get(url + "/info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var v info
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	if !v.Websocket || v.CookieNeeded || len(v.Origins) != 1 {
+		t.Errorf("info = %+v", v)
+	}
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+	}
+}
+
+func TestRouting(t *testing.T) {
+	url := newTestServer(t, &Server{})
+	tests := []struct {
+		method, path string
+		status       int
+	}{
+		{"GET", "", http.StatusOK},
+		{"GET", "/", http.StatusOK},
+		{"GET", "/a/b/xhr", http.StatusMethodNotAllowed},
+		{"OPTIONS", "/a/b/xhr", http.StatusNoContent},
+		{"POST", "/a/b/unknown", http.StatusNotFound},
+		{"POST", "/a/b.c/xhr", http.StatusNotFound},
+		{"POST", "/a//xhr", http.StatusNotFound},
+		{"POST", "/a/b/c/xhr", http.StatusNotFound},
+		{"POST", "/a/missing/xhr_send", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, url+tt.path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s %s: status %d, want %d", tt.method, tt.path, resp.StatusCode, tt.status)
+		}
+	}
+}
+
+func TestXHRPolling(t *testing.T) {
+	url := newTestServer(t, &Server{}) + "/000/poll"
+
+	steps := []struct {
+		path, body string
+		status     int
+		want       string
+	}{
+		{"/xhr", "", http.StatusOK, "o\n"},
+		{"/xhr_send", `["a"]`, http.StatusNoContent, ""},
+		{"/xhr", "", http.StatusOK, "a[\"a\"]\n"},
+		{"/xhr_send", ``, http.StatusInternalServerError, "Payload expected.\n"},
+		{"/xhr_send", `[1`, http.StatusInternalServerError, "Broken JSON encoding.\n"},
+		{"/xhr_send", `["close"]`, http.StatusNoContent, ""},
+		{"/xhr", "", http.StatusOK, "c[3001,\"bye\"]\n"},
+		{"/xhr", "", http.StatusOK, "c[3001,\"bye\"]\n"},
+	}
+	for _, st := range steps {
+		status, body := post(t, url+st.path, st.body)
+		if status != st.status || body != st.want {
+			t.Fatalf("POST %s %q: got %d %q, want %d %q", st.path, st.body, status, body, st.status, st.want)
+		}
+	}
+}
+
+func TestXHRPollingHeartbeat(t *testing.T) {
+	url := newTestServer(t, &Server{HeartbeatDelay: 10 * time.Millisecond}) + "/000/hb"
+	post(t, url+"/xhr", "")
+	if _, body := post(t, url+"/xhr", ""); body != "h\n" {
+		t.Errorf("body = %q, want heartbeat", body)
+	}
+}
+
+func TestAnotherConnection(t *testing.T) {
+	url := newTestServer(t, &Server{}) + "/000/busy"
+	post(t, url+"/xhr", "")
+
+	done := make(chan string)
+	go func() {
+		_, body := post(t, url+"/xhr", "")
+		done <- body
+	}()
+	// Wait for the first poll to attach.
+	time.Sleep(50 * time.Millisecond)
+	if _, body := post(t, url+"/xhr", ""); body != "c[2010,\"Another connection still open\"]\n" {
+		t.Errorf("second poll = %q", body)
+	}
+	post(t, url+"/xhr_send", `["x"]`)
+	if body := <-done; body != "a[\"x\"]\n" {
+		t.Errorf("first poll = %q", body)
+	}
+}
+
+func TestSessionTimeout(t *testing.T) {
+	closed := make(chan error, 1)
+	s := &Server{
+		DisconnectDelay: 10 * time.Millisecond,
+		Handler: func(sess *Session) {
+			_, _, err := sess.ReadMessage()
+			closed <- err
+		},
+	}
+	url := newTestServer(t, s) + "/000/gone"
+	post(t, url+"/xhr", "")
+	select {
+	case err := <-closed:
+		if err != ErrClosed {
+			t.Errorf("ReadMessage() error = %v, want %v", err, ErrClosed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("session did not expire")
+	}
+	if status, _ := post(t, url+"/xhr_send", `["x"]`); status != http.StatusNotFound {
+		t.Errorf("send to expired session: status %d, want %d", status, http.StatusNotFound)
+	}
+}
+
+func TestXHRStreaming(t *testing.T) {
+	url := newTestServer(t, &Server{ResponseLimit: 4096}) + "/000/stream"
+	resp, err := http.Post(url+"/xhr_streaming", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	br := bufio.NewReader(resp.Body)
+	readFrame := func() string {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSuffix(line, "\n")
+	}
+
+	if f := readFrame(); f != streamingPrelude {
+		t.Fatalf("prelude = %q", f)
+	}
+	if f := readFrame(); f != "o" {
+		t.Fatalf("frame = %q, want open", f)
+	}
+	for _, m := range []string{"one", "two"} {
+		post(t, url+"/xhr_send", `["`+m+`"]`)
+		if f, want := readFrame(), `a["`+m+`"]`; f != want {
+			t.Fatalf("frame = %q, want %q", f, want)
+		}
+	}
+
+	// The response ends after the limit is reached.
+	post(t, url+"/xhr_send", `["`+strings.Repeat("x", 4096)+`"]`)
+	readFrame()
+	if _, err := br.ReadString('\n'); err != io.EOF {
+		t.Errorf("read after limit: %v, want EOF", err)
+	}
+}
+
+func TestWebsocket(t *testing.T) {
+	url := newTestServer(t, &Server{})
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(url, "http")+"/000/ws/websocket", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	readFrame := func() string {
+		ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, p, err := ws.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(p)
+	}
+
+	if f := readFrame(); f != "o" {
+		t.Fatalf("frame = %q, want open", f)
+	}
+
+	// The client sends an array of messages or a single message. Echoed
+	// messages may be split across frames.
+	ws.WriteMessage(websocket.TextMessage, []byte(`["a","b"]`))
+	ws.WriteMessage(websocket.TextMessage, []byte(`"c"`))
+	var got []string
+	for len(got) < 3 {
+		f := readFrame()
+		var messages []string
+		if !strings.HasPrefix(f, "a") || json.Unmarshal([]byte(f[1:]), &messages) != nil {
+			t.Fatalf("frame = %q, want messages", f)
+		}
+		got = append(got, messages...)
+	}
+	if strings.Join(got, ",") != "a,b,c" {
+		t.Errorf("messages = %q, want a, b, c", got)
+	}
+
+	ws.WriteMessage(websocket.TextMessage, []byte(`["close"]`))
+	if f := readFrame(); f != `c[3001,"bye"]` {
+		t.Errorf("frame = %q, want close", f)
+	}
+}
+
+func TestMessageTooLarge(t *testing.T) {
+	url := newTestServer(t, &Server{MaxMessageSize: 16})
+	post(t, url+"/000/big/xhr", "")
+	if status, _ := post(t, url+"/000/big/xhr_send", `["`+strings.Repeat("x", 16)+`"]`); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("xhr_send status = %d, want %d", status, http.StatusRequestEntityTooLarge)
+	}
+	if _, body := post(t, url+"/000/big/xhr", ""); body != "c[1009,\"Message too big\"]\n" {
+		t.Errorf("poll after large send = %q, want close 1009", body)
+	}
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(url, "http")+"/000/ws/websocket", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	ws.ReadMessage()
+	ws.WriteMessage(websocket.TextMessage, []byte(`["`+strings.Repeat("x", 16)+`"]`))
+	for {
+		_, p, err := ws.ReadMessage()
+		if websocket.IsCloseError(err, websocket.CloseMessageTooBig) || string(p) == `c[1009,"Message too big"]` {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ReadMessage() error = %v, want close 1009", err)
+		}
+	}
+}
+
+func TestWebsocketDisabled(t *testing.T) {
+	url := newTestServer(t, &Server{DisableWebsocket: true})
+	_, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(url, "http")+"/000/ws/websocket", nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("Dial: %v, want 404", err)
+	}
+}
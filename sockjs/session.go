@@ -0,0 +1,276 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sockjs
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var (
+	// ErrClosed is returned when reading or writing a closed session.
+	ErrClosed = errors.New("sockjs: session closed")
+
+	errBinary = errors.New("sockjs: binary messages are not supported")
+)
+
+const (
+	frameOpen      = "o"
+	frameHeartbeat = "h"
+)
+
+func closeFrame(code int, reason string) string {
+	p, _ := json.Marshal([]interface{}{code, reason})
+	return "c" + string(p)
+}
+
+func messageFrame(messages []string) string {
+	p, _ := json.Marshal(messages)
+	return "a" + string(p)
+}
+
+// Session is a client session on one of the server's transports.
+//
+// Session supports one concurrent reader and multiple concurrent writers.
+type Session struct {
+	server  *Server
+	id      string
+	request *http.Request
+
+	mu         sync.Mutex
+	inbox      []string
+	inboxSize  int
+	inboxSpace chan struct{} // closed when inbox messages are read
+	outbox     []string
+	closed     bool
+	closeFrame string
+	receiving  bool
+	generation int
+	timer      *time.Timer
+
+	readReady  chan struct{}
+	writeReady chan struct{}
+}
+
+func newSession(s *Server, id string, r *http.Request) *Session {
+	return &Session{
+		server:     s,
+		id:         id,
+		request:    r,
+		receiving:  true,
+		readReady:  make(chan struct{}, 1),
+		writeReady: make(chan struct{}, 1),
+	}
+}
+
+// ID returns the session ID chosen by the client. The ID is empty for
+// sessions on the websocket transport.
+func (sess *Session) ID() string {
+	return sess.id
+}
+
+// Request returns the HTTP request that created the session.
+func (sess *Session) Request() *http.Request {
+	return sess.request
+}
+
+func signal(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
+
+// ReadMessage returns the next message from the client. The messageType is
+// always websocket.TextMessage. ReadMessage returns ErrClosed after the
+// session is closed and all received messages are read.
+func (sess *Session) ReadMessage() (messageType int, p []byte, err error) {
+	for {
+		sess.mu.Lock()
+		if len(sess.inbox) > 0 {
+			m := sess.inbox[0]
+			sess.inbox[0] = ""
+			sess.inbox = sess.inbox[1:]
+			sess.inboxSize -= len(m)
+			sess.wakeReceivers()
+			sess.mu.Unlock()
+			return websocket.TextMessage, []byte(m), nil
+		}
+		closed := sess.closed
+		sess.mu.Unlock()
+		if closed {
+			return 0, nil, ErrClosed
+		}
+		<-sess.readReady
+	}
+}
+
+// WriteMessage queues a message for the client. The messageType must be
+// websocket.TextMessage. Invalid UTF-8 in data is replaced with U+FFFD.
+func (sess *Session) WriteMessage(messageType int, data []byte) error {
+	if messageType != websocket.TextMessage {
+		return errBinary
+	}
+	sess.mu.Lock()
+	if sess.closed {
+		sess.mu.Unlock()
+		return ErrClosed
+	}
+	sess.outbox = append(sess.outbox, string(data))
+	sess.mu.Unlock()
+	signal(sess.writeReady)
+	return nil
+}
+
+// Close closes the session with the close code 3000.
+func (sess *Session) Close() error {
+	return sess.CloseWithReason(3000, "Go away!")
+}
+
+// CloseWithReason closes the session and sends the code and reason to the
+// client. Messages queued before the call are sent before the close frame.
+func (sess *Session) CloseWithReason(code int, reason string) error {
+	sess.close(closeFrame(code, reason))
+	return nil
+}
+
+// closeLocal closes the session after the client went away.
+func (sess *Session) closeLocal() {
+	sess.close(closeFrame(1002, "Connection interrupted"))
+}
+
+func (sess *Session) close(frame string) {
+	sess.mu.Lock()
+	if !sess.closed {
+		sess.closed = true
+		sess.closeFrame = frame
+	}
+	sess.wakeReceivers()
+	sess.mu.Unlock()
+	signal(sess.readReady)
+	signal(sess.writeReady)
+}
+
+// wakeReceivers wakes the receive calls that wait for space in the inbox.
+// The caller must hold sess.mu.
+func (sess *Session) wakeReceivers() {
+	if sess.inboxSpace != nil {
+		close(sess.inboxSpace)
+		sess.inboxSpace = nil
+	}
+}
+
+// receive adds messages from the client to the inbox. If the inbox holds
+// more than the server's MaxMessageSize, receive waits until the
+// application reads messages or done is closed. It returns false if the
+// messages were dropped.
+func (sess *Session) receive(done <-chan struct{}, messages []string) bool {
+	n := 0
+	for _, m := range messages {
+		n += len(m)
+	}
+	for {
+		sess.mu.Lock()
+		if sess.closed {
+			sess.mu.Unlock()
+			return false
+		}
+		if sess.inboxSize == 0 || sess.inboxSize+n <= sess.server.maxMessageSize() {
+			sess.inbox = append(sess.inbox, messages...)
+			sess.inboxSize += n
+			sess.mu.Unlock()
+			signal(sess.readReady)
+			return true
+		}
+		if sess.inboxSpace == nil {
+			sess.inboxSpace = make(chan struct{})
+		}
+		space := sess.inboxSpace
+		sess.mu.Unlock()
+		select {
+		case <-space:
+		case <-done:
+			return false
+		}
+	}
+}
+
+// attach attaches a receiving request to the session. It returns false if
+// another request is attached.
+func (sess *Session) attach() bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if sess.receiving {
+		return false
+	}
+	sess.receiving = true
+	sess.generation++
+	if sess.timer != nil {
+		sess.timer.Stop()
+	}
+	return true
+}
+
+// detach detaches the receiving request and starts the disconnect timer.
+func (sess *Session) detach() {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.receiving = false
+	sess.generation++
+	gen := sess.generation
+	sess.timer = time.AfterFunc(sess.server.disconnectDelay(), func() {
+		sess.mu.Lock()
+		expired := sess.generation == gen
+		sess.mu.Unlock()
+		if expired {
+			sess.closeLocal()
+			sess.server.remove(sess)
+		}
+	})
+}
+
+// serveFrames writes frames to fw until the session is closed, done is
+// closed or end returns true after a frame is written.
+func (sess *Session) serveFrames(done <-chan struct{}, fw frameWriter, end func() bool) {
+	heartbeatDelay := sess.server.heartbeatDelay()
+	heartbeat := time.NewTimer(heartbeatDelay)
+	defer heartbeat.Stop()
+	for {
+		var frame string
+		sess.mu.Lock()
+		closing := false
+		switch {
+		case len(sess.outbox) > 0:
+			frame = messageFrame(sess.outbox)
+			sess.outbox = nil
+		case sess.closed:
+			frame = sess.closeFrame
+			closing = true
+		}
+		sess.mu.Unlock()
+
+		if frame == "" {
+			select {
+			case <-sess.writeReady:
+				continue
+			case <-heartbeat.C:
+				frame = frameHeartbeat
+			case <-done:
+				return
+			}
+		} else if !heartbeat.Stop() {
+			<-heartbeat.C
+		}
+		if err := fw.writeFrame(frame); err != nil || closing || end() {
+			return
+		}
+		heartbeat.Reset(heartbeatDelay)
+	}
+}
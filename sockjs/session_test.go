@@ -0,0 +1,103 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sockjs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type recordingWriter struct {
+	frames []string
+	err    error
+}
+
+func (w *recordingWriter) writeFrame(frame string) error {
+	w.frames = append(w.frames, frame)
+	return w.err
+}
+
+func TestSessionMessages(t *testing.T) {
+	sess := newSession(&Server{}, "s", nil)
+	sess.receive(nil, []string{"a", "b"})
+	for _, want := range []string{"a", "b"} {
+		mt, p, err := sess.ReadMessage()
+		if err != nil || mt != websocket.TextMessage || string(p) != want {
+			t.Fatalf("ReadMessage() = %d, %q, %v, want %q", mt, p, err, want)
+		}
+	}
+
+	if err := sess.WriteMessage(websocket.BinaryMessage, []byte{1}); err != errBinary {
+		t.Errorf("WriteMessage(binary) error = %v, want %v", err, errBinary)
+	}
+	sess.WriteMessage(websocket.TextMessage, []byte("x"))
+	sess.WriteMessage(websocket.TextMessage, []byte("y\xff"))
+	sess.Close()
+	if err := sess.WriteMessage(websocket.TextMessage, []byte("z")); err != ErrClosed {
+		t.Errorf("WriteMessage after close error = %v, want %v", err, ErrClosed)
+	}
+	if _, _, err := sess.ReadMessage(); err != ErrClosed {
+		t.Errorf("ReadMessage after close error = %v, want %v", err, ErrClosed)
+	}
+
+	var w recordingWriter
+	sess.serveFrames(nil, &w, func() bool { return false })
+	want := []string{"a[\"x\",\"y�\"]", "c[3000,\"Go away!\"]"}
+	if len(w.frames) != len(want) || w.frames[0] != want[0] || w.frames[1] != want[1] {
+		t.Errorf("frames = %q, want %q", w.frames, want)
+	}
+}
+
+func TestServeFramesWriteError(t *testing.T) {
+	sess := newSession(&Server{HeartbeatDelay: time.Millisecond}, "s", nil)
+	w := recordingWriter{err: errors.New("broken")}
+	sess.serveFrames(nil, &w, func() bool { return false })
+	if len(w.frames) != 1 || w.frames[0] != frameHeartbeat {
+		t.Errorf("frames = %q, want one heartbeat", w.frames)
+	}
+}
+
+func TestReadMessageBlocks(t *testing.T) {
+	sess := newSession(&Server{}, "s", nil)
+	done := make(chan string)
+	go func() {
+		_, p, _ := sess.ReadMessage()
+		done <- string(p)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	sess.receive(nil, []string{"late"})
+	if got := <-done; got != "late" {
+		t.Errorf("ReadMessage() = %q, want late", got)
+	}
+}
+
+func TestReceiveWaitsForSpace(t *testing.T) {
+	sess := newSession(&Server{MaxMessageSize: 4}, "s", nil)
+	if !sess.receive(nil, []string{"abc"}) {
+		t.Fatal("receive() dropped messages")
+	}
+	received := make(chan bool)
+	go func() { received <- sess.receive(nil, []string{"de"}) }()
+	select {
+	case <-received:
+		t.Fatal("receive() did not wait for space in the inbox")
+	case <-time.After(10 * time.Millisecond):
+	}
+	sess.ReadMessage()
+	if !<-received {
+		t.Error("receive() dropped messages after a read")
+	}
+
+	// Closing the session releases waiting receivers.
+	go func() { received <- sess.receive(nil, []string{"fghi"}) }()
+	time.Sleep(10 * time.Millisecond)
+	sess.Close()
+	if <-received {
+		t.Error("receive() on closed session added messages")
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+// SocketOptions are the options of the TCP connections of a dialer or an
+// upgrader. The options are applied to the connection to the peer, or to
+// the proxy when the dialer connects through a proxy, before the opening
+// handshake. Options are not applied to connections that are not TCP
+// connections, such as Unix domain socket connections.
+type SocketOptions struct {
+	// Nagle enables Nagle's algorithm on the connection. If Nagle is false,
+	// TCP_NODELAY is set, which is also the default of the net package, and
+	// small writes such as control frames are sent without delay.
+	Nagle bool
+
+	// KeepAlive is the interval of TCP keep-alive probes. If KeepAlive is
+	// zero, the keep-alive setting of the connection is not changed. If
+	// KeepAlive is negative, keep-alive probes are disabled.
+	KeepAlive time.Duration
+
+	// KeepAliveCount is the number of unanswered keep-alive probes before
+	// the connection is dropped. If KeepAliveCount is zero, the system
+	// default is used. KeepAliveCount is supported on Linux, the BSDs and
+	// Darwin.
+	KeepAliveCount int
+
+	// ReceiveBufferSize and SendBufferSize are the sizes in bytes of the
+	// socket's receive and send buffers, SO_RCVBUF and SO_SNDBUF. If a size
+	// is zero, the system default is used.
+	ReceiveBufferSize, SendBufferSize int
+
+	// Control is called with the network connection after the options
+	// above are applied, for settings not covered by SocketOptions. An
+	// error from Control fails the handshake. Control is called for all
+	// network connections, including the ones that are not TCP connections.
+	Control func(conn net.Conn) error
+}
+
+var errKeepAliveCount = errors.New("websocket: keep-alive count is not supported on this platform")
+
+// apply applies the options to the network connection conn.
+func (o *SocketOptions) apply(conn net.Conn) error {
+	if o == nil {
+		return nil
+	}
+	if tc := tcpConn(conn); tc != nil {
+		if err := o.applyTCP(tc); err != nil {
+			return err
+		}
+	}
+	if o.Control != nil {
+		return o.Control(conn)
+	}
+	return nil
+}
+
+func (o *SocketOptions) applyTCP(tc *net.TCPConn) error {
+	if err := tc.SetNoDelay(!o.Nagle); err != nil {
+		return err
+	}
+	switch {
+	case o.KeepAlive > 0:
+		if err := tc.SetKeepAlive(true); err != nil {
+			return err
+		}
+		if err := tc.SetKeepAlivePeriod(o.KeepAlive); err != nil {
+			return err
+		}
+	case o.KeepAlive < 0:
+		if err := tc.SetKeepAlive(false); err != nil {
+			return err
+		}
+	}
+	if o.KeepAliveCount > 0 {
+		if err := setKeepAliveCount(tc, o.KeepAliveCount); err != nil {
+			return err
+		}
+	}
+	if o.ReceiveBufferSize > 0 {
+		if err := tc.SetReadBuffer(o.ReceiveBufferSize); err != nil {
+			return err
+		}
+	}
+	if o.SendBufferSize > 0 {
+		if err := tc.SetWriteBuffer(o.SendBufferSize); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tcpConn returns the TCP connection of conn or nil if conn is not a TCP
+// connection. Connections that wrap a network connection, such as
+// *tls.Conn, are unwrapped with their NetConn method.
+func tcpConn(conn net.Conn) *net.TCPConn {
+	for conn != nil {
+		switch c := conn.(type) {
+		case *net.TCPConn:
+			return c
+		case interface{ NetConn() net.Conn }:
+			conn = c.NetConn()
+		default:
+			return nil
+		}
+	}
+	return nil
+}
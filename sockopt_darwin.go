@@ -0,0 +1,9 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+// tcpKeepCnt is TCP_KEEPCNT, which the syscall package does not define on
+// all Darwin architectures.
+const tcpKeepCnt = 0x102
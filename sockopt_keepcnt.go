@@ -0,0 +1,11 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux || dragonfly || freebsd || netbsd
+
+package websocket
+
+import "syscall"
+
+const tcpKeepCnt = syscall.TCP_KEEPCNT
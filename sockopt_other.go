@@ -0,0 +1,13 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd
+
+package websocket
+
+import "net"
+
+func setKeepAliveCount(tc *net.TCPConn, n int) error {
+	return errKeepAliveCount
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSocketOptions(t *testing.T) {
+	serverConns := make(chan net.Conn, 1)
+	u := Upgrader{SocketOptions: &SocketOptions{
+		KeepAlive:         time.Minute,
+		ReceiveBufferSize: 64 << 10,
+		Control: func(conn net.Conn) error {
+			serverConns <- conn
+			return nil
+		},
+	}}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := u.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		c.Close()
+	}))
+	defer s.Close()
+
+	var clientConn net.Conn
+	d := Dialer{SocketOptions: &SocketOptions{
+		Nagle:          true,
+		KeepAlive:      -1,
+		SendBufferSize: 64 << 10,
+		Control: func(conn net.Conn) error {
+			clientConn = conn
+			return nil
+		},
+	}}
+	ws, _, err := d.Dial(makeWsProto(s.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.Close()
+	if tcpConn(clientConn) == nil {
+		t.Errorf("client Control called with %T, want TCP connection", clientConn)
+	}
+	if conn := <-serverConns; tcpConn(conn) == nil {
+		t.Errorf("server Control called with %T, want TCP connection", conn)
+	}
+}
+
+func TestSocketOptionsControlError(t *testing.T) {
+	errControl := errors.New("control error")
+	upgraded := make(chan error, 1)
+	u := Upgrader{SocketOptions: &SocketOptions{
+		Control: func(conn net.Conn) error { return errControl },
+	}}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := u.Upgrade(w, r, nil)
+		upgraded <- err
+	}))
+	defer s.Close()
+
+	if _, _, err := DefaultDialer.Dial(makeWsProto(s.URL), nil); err == nil {
+		t.Error("Dial succeeded, want error")
+	}
+	if err := <-upgraded; err != errControl {
+		t.Errorf("Upgrade() error = %v, want %v", err, errControl)
+	}
+
+	d := Dialer{SocketOptions: &SocketOptions{
+		Control: func(conn net.Conn) error { return errControl },
+	}}
+	if _, _, err := d.Dial(makeWsProto(s.URL), nil); err != errControl {
+		t.Errorf("Dial() error = %v, want %v", err, errControl)
+	}
+}
+
+func TestTCPConn(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	p1, p2 := net.Pipe()
+	defer p1.Close()
+	defer p2.Close()
+
+	tests := []struct {
+		name string
+		conn net.Conn
+		want bool
+	}{
+		{"tcp", conn, true},
+		{"tls", tls.Client(conn, &tls.Config{}), true},
+		{"pipe", p1, false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		if got := tcpConn(tt.conn) != nil; got != tt.want {
+			t.Errorf("%s: tcpConn() != nil is %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
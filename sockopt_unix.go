@@ -0,0 +1,26 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux || darwin || dragonfly || freebsd || netbsd
+
+package websocket
+
+import (
+	"net"
+	"syscall"
+)
+
+func setKeepAliveCount(tc *net.TCPConn, n int) error {
+	rc, err := tc.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var serr error
+	if err := rc.Control(func(fd uintptr) {
+		serr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_TCP, tcpKeepCnt, n)
+	}); err != nil {
+		return err
+	}
+	return serr
+}
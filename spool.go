@@ -0,0 +1,244 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+)
+
+const defaultSpoolThreshold = 1 << 20
+
+var errSpoolClosed = errors.New("websocket: spool writer closed")
+
+// SpoolConfig configures the spooling of large messages to temporary files
+// by ReadMessageSpooled and NextSpoolWriter.
+type SpoolConfig struct {
+	// Threshold is the size in bytes above which a payload is moved from
+	// memory to a temporary file. If Threshold is zero, 1 MiB is used.
+	Threshold int64
+
+	// Dir is the directory of the temporary files. If Dir is empty,
+	// os.TempDir is used.
+	Dir string
+}
+
+// SetSpool sets the configuration of ReadMessageSpooled and
+// NextSpoolWriter. If cfg is nil, the default configuration is used.
+func (c *Conn) SetSpool(cfg *SpoolConfig) {
+	c.spool = cfg
+}
+
+// spoolBuffer holds a payload in memory up to the threshold and in a
+// temporary file beyond.
+type spoolBuffer struct {
+	threshold int64
+	dir       string
+	mem       bytes.Buffer
+	file      *os.File
+	size      int64
+}
+
+func (c *Conn) newSpoolBuffer() *spoolBuffer {
+	b := &spoolBuffer{threshold: defaultSpoolThreshold}
+	if cfg := c.spool; cfg != nil {
+		if cfg.Threshold > 0 {
+			b.threshold = cfg.Threshold
+		}
+		b.dir = cfg.Dir
+	}
+	return b
+}
+
+func (b *spoolBuffer) Write(p []byte) (int, error) {
+	if b.file == nil && b.size+int64(len(p)) > b.threshold {
+		f, err := os.CreateTemp(b.dir, "websocket-spool-*")
+		if err != nil {
+			return 0, err
+		}
+		b.file = f
+		if _, err := f.Write(b.mem.Bytes()); err != nil {
+			return 0, err
+		}
+		b.mem = bytes.Buffer{}
+	}
+	var n int
+	var err error
+	if b.file != nil {
+		n, err = b.file.Write(p)
+	} else {
+		n, err = b.mem.Write(p)
+	}
+	b.size += int64(n)
+	return n, err
+}
+
+// readerAt returns the spooled payload.
+func (b *spoolBuffer) readerAt() io.ReaderAt {
+	if b.file != nil {
+		return b.file
+	}
+	return bytes.NewReader(b.mem.Bytes())
+}
+
+// remove deletes the temporary file.
+func (b *spoolBuffer) remove() error {
+	b.mem = bytes.Buffer{}
+	if b.file == nil {
+		return nil
+	}
+	err := b.file.Close()
+	if err1 := os.Remove(b.file.Name()); err == nil {
+		err = err1
+	}
+	b.file = nil
+	return err
+}
+
+// SpooledMessage is the payload of a message read by ReadMessageSpooled.
+// The payload is in memory or, if it is larger than the spool threshold, in
+// a temporary file. The application must call Close to remove the file.
+type SpooledMessage struct {
+	buf *spoolBuffer
+	r   *io.SectionReader
+}
+
+// Read implements io.Reader.
+func (m *SpooledMessage) Read(p []byte) (int, error) { return m.r.Read(p) }
+
+// Seek implements io.Seeker.
+func (m *SpooledMessage) Seek(offset int64, whence int) (int64, error) {
+	return m.r.Seek(offset, whence)
+}
+
+// ReadAt implements io.ReaderAt.
+func (m *SpooledMessage) ReadAt(p []byte, off int64) (int, error) { return m.r.ReadAt(p, off) }
+
+// Size returns the size of the payload in bytes.
+func (m *SpooledMessage) Size() int64 { return m.r.Size() }
+
+// Spilled reports whether the payload is in a temporary file.
+func (m *SpooledMessage) Spilled() bool { return m.buf.file != nil }
+
+// Close releases the payload and removes the temporary file, if any.
+func (m *SpooledMessage) Close() error {
+	return m.buf.remove()
+}
+
+// ReadMessageSpooled reads the next data message like ReadMessage and
+// returns the payload as an io.ReadSeeker. Payloads larger than the spool
+// threshold are written to a temporary file as they arrive, so that a
+// connection sized for small messages can receive an occasional very large
+// message without holding it in memory. See SetSpool. The read limit and
+// the read limit handler apply as for NextReader.
+//
+// The application must close the returned message. If reading the message
+// or writing the file fails, the file is removed and ReadMessageSpooled
+// returns the error.
+func (c *Conn) ReadMessageSpooled() (messageType int, m *SpooledMessage, err error) {
+	var r io.Reader
+	messageType, r, err = c.NextReader()
+	if err != nil {
+		return messageType, nil, err
+	}
+	b := c.newSpoolBuffer()
+	if _, err := io.Copy(b, r); err != nil {
+		_ = b.remove()
+		return messageType, nil, err
+	}
+	return messageType, &SpooledMessage{buf: b, r: io.NewSectionReader(b.readerAt(), 0, b.size)}, nil
+}
+
+// SpoolWriter is the writer returned by NextSpoolWriter.
+type SpoolWriter struct {
+	c           *Conn
+	messageType int
+	buf         *spoolBuffer
+	closed      bool
+}
+
+// NextSpoolWriter returns a writer for a data message that is collected in
+// memory or, beyond the spool threshold, in a temporary file, and sent when
+// the writer is closed. See SetSpool. Unlike the writers returned by
+// NextWriter, a SpoolWriter does not hold the connection while the
+// application produces the payload, other messages can be written
+// meanwhile, and the message can be abandoned with Abort before any of it
+// is sent. The message is sent with the size known, for example to the
+// compression policy.
+func (c *Conn) NextSpoolWriter(messageType int) (*SpoolWriter, error) {
+	if !isData(messageType) {
+		return nil, errBadWriteOpCode
+	}
+	return &SpoolWriter{c: c, messageType: messageType, buf: c.newSpoolBuffer()}, nil
+}
+
+// Write adds p to the payload.
+func (w *SpoolWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errSpoolClosed
+	}
+	n, err := w.buf.Write(p)
+	if err != nil {
+		w.Abort()
+	}
+	return n, err
+}
+
+// Close sends the message and removes the temporary file, if any. The
+// message is streamed from the file, except for connections with a payload
+// transform or outbound middleware, which see whole messages.
+func (w *SpoolWriter) Close() error {
+	if w.closed {
+		return errSpoolClosed
+	}
+	w.closed = true
+	c, b := w.c, w.buf
+	defer b.remove()
+
+	r := io.NewSectionReader(b.readerAt(), 0, b.size)
+	if c.transformsOutbound() {
+		p, err := readAllMax(r, maxInt)
+		if err != nil {
+			return err
+		}
+		return c.WriteMessage(w.messageType, p)
+	}
+	if b.file == nil {
+		return c.WriteMessage(w.messageType, b.mem.Bytes())
+	}
+	size := -1
+	if b.size <= int64(maxInt) {
+		size = int(b.size)
+	}
+	sw, err := c.nextWriter(w.messageType, size)
+	if err != nil {
+		return err
+	}
+	mw, _ := sw.(*messageWriter)
+	if tw, ok := sw.(*tracedWriter); ok {
+		mw = tw.mw
+	}
+	if mw != nil && !mw.compressed {
+		mw.total = b.size
+	}
+	if t := c.teeWrites.Load(); t != nil {
+		sw = &teeWriteCloser{w: sw, tee: t.w}
+	}
+	if _, err := io.Copy(sw, r); err != nil {
+		return err
+	}
+	return sw.Close()
+}
+
+// Abort abandons the message and removes the temporary file, if any.
+func (w *SpoolWriter) Abort() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.buf.remove()
+}
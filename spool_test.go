@@ -0,0 +1,191 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+)
+
+// spoolFiles returns the number of files in dir.
+func spoolFiles(t *testing.T, dir string) int {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(entries)
+}
+
+func TestReadMessageSpooled(t *testing.T) {
+	const threshold = 1000
+	tests := []struct {
+		name    string
+		size    int
+		spilled bool
+	}{
+		{"empty", 0, false},
+		{"threshold", threshold, false},
+		{"spilled", 10 * threshold, true},
+	}
+	for _, compress := range []bool{false, true} {
+		for _, tt := range tests {
+			dir := t.TempDir()
+			client, server := (&PipeConfig{EnableCompression: compress}).Pipe()
+			client.EnableWriteCompression(compress)
+			server.SetSpool(&SpoolConfig{Threshold: threshold, Dir: dir})
+			payload := bytes.Repeat([]byte("0123456789"), tt.size/10)
+			client.WriteMessage(BinaryMessage, payload)
+
+			messageType, m, err := server.ReadMessageSpooled()
+			if err != nil || messageType != BinaryMessage {
+				t.Fatalf("%s, compress=%v: ReadMessageSpooled() = %d, %v", tt.name, compress, messageType, err)
+			}
+			if m.Size() != int64(tt.size) || m.Spilled() != tt.spilled {
+				t.Errorf("%s, compress=%v: Size(), Spilled() = %d, %v, want %d, %v", tt.name, compress, m.Size(), m.Spilled(), tt.size, tt.spilled)
+			}
+			if n := spoolFiles(t, dir); n != map[bool]int{false: 0, true: 1}[tt.spilled] {
+				t.Errorf("%s, compress=%v: %d files in spool directory", tt.name, compress, n)
+			}
+			if p, err := io.ReadAll(m); err != nil || !bytes.Equal(p, payload) {
+				t.Errorf("%s, compress=%v: payload = %d bytes, %v, want %d bytes", tt.name, compress, len(p), err, tt.size)
+			}
+			if tt.size > 0 {
+				if _, err := m.Seek(-5, io.SeekEnd); err != nil {
+					t.Fatal(err)
+				}
+				if p, _ := io.ReadAll(m); string(p) != "56789" {
+					t.Errorf("%s, compress=%v: payload after Seek = %q, want 56789", tt.name, compress, p)
+				}
+			}
+			if err := m.Close(); err != nil {
+				t.Errorf("%s, compress=%v: Close() error = %v", tt.name, compress, err)
+			}
+			if n := spoolFiles(t, dir); n != 0 {
+				t.Errorf("%s, compress=%v: %d files in spool directory after Close", tt.name, compress, n)
+			}
+			client.Close()
+			server.Close()
+		}
+	}
+}
+
+func TestReadMessageSpooledError(t *testing.T) {
+	dir := t.TempDir()
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	server.SetSpool(&SpoolConfig{Threshold: 10, Dir: dir})
+	server.SetReadLimit(100)
+	client.WriteMessage(BinaryMessage, make([]byte, 1000))
+
+	if _, _, err := server.ReadMessageSpooled(); !errors.Is(err, ErrReadLimit) {
+		t.Errorf("ReadMessageSpooled() error = %v, want %v", err, ErrReadLimit)
+	}
+	if n := spoolFiles(t, dir); n != 0 {
+		t.Errorf("%d files in spool directory after error", n)
+	}
+}
+
+func TestSpoolWriter(t *testing.T) {
+	const threshold = 1000
+	for _, compress := range []bool{false, true} {
+		for _, size := range []int{0, threshold, 10 * threshold} {
+			dir := t.TempDir()
+			client, server := (&PipeConfig{EnableCompression: compress}).Pipe()
+			client.EnableWriteCompression(compress)
+			client.SetSpool(&SpoolConfig{Threshold: threshold, Dir: dir})
+			var tee bytes.Buffer
+			client.TeeWrites(&tee)
+			payload := bytes.Repeat([]byte("0123456789"), size/10)
+
+			w, err := client.NextSpoolWriter(TextMessage)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write(payload[:size/2])
+			// Other messages can be written while the payload is collected.
+			client.WriteMessage(TextMessage, []byte("first"))
+			w.Write(payload[size/2:])
+			if n := spoolFiles(t, dir); n != map[bool]int{false: 0, true: 1}[size > threshold] {
+				t.Errorf("size=%d, compress=%v: %d files in spool directory", size, compress, n)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("size=%d, compress=%v: Close() error = %v", size, compress, err)
+			}
+			if n := spoolFiles(t, dir); n != 0 {
+				t.Errorf("size=%d, compress=%v: %d files in spool directory after Close", size, compress, n)
+			}
+			if _, err := w.Write([]byte("x")); err != errSpoolClosed {
+				t.Errorf("size=%d, compress=%v: Write() after Close error = %v, want %v", size, compress, err, errSpoolClosed)
+			}
+
+			for _, want := range [][]byte{[]byte("first"), payload} {
+				_, p, err := server.ReadMessage()
+				if err != nil || !bytes.Equal(p, want) {
+					t.Fatalf("size=%d, compress=%v: ReadMessage() = %d bytes, %v, want %d bytes", size, compress, len(p), err, len(want))
+				}
+			}
+			if got, want := tee.String(), "first"+string(payload); got != want {
+				t.Errorf("size=%d, compress=%v: tee = %d bytes, want %d bytes", size, compress, len(got), len(want))
+			}
+			client.Close()
+			server.Close()
+		}
+	}
+}
+
+func TestSpoolWriterAbort(t *testing.T) {
+	dir := t.TempDir()
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	client.SetSpool(&SpoolConfig{Threshold: 10, Dir: dir})
+
+	if _, err := client.NextSpoolWriter(PingMessage); err != errBadWriteOpCode {
+		t.Errorf("NextSpoolWriter(PingMessage) error = %v, want %v", err, errBadWriteOpCode)
+	}
+	w, err := client.NextSpoolWriter(BinaryMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(make([]byte, 100))
+	if err := w.Abort(); err != nil {
+		t.Fatalf("Abort() error = %v", err)
+	}
+	if n := spoolFiles(t, dir); n != 0 {
+		t.Errorf("%d files in spool directory after Abort", n)
+	}
+	if err := w.Close(); err != errSpoolClosed {
+		t.Errorf("Close() after Abort error = %v, want %v", err, errSpoolClosed)
+	}
+
+	client.WriteMessage(TextMessage, []byte("next"))
+	if _, p, err := server.ReadMessage(); err != nil || string(p) != "next" {
+		t.Errorf("ReadMessage() = %q, %v, want next", p, err)
+	}
+}
+
+func TestSpoolWriterTransform(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	client.SetPayloadTransform(newAEADTransform(t))
+	server.SetPayloadTransform(newAEADTransform(t))
+	client.SetSpool(&SpoolConfig{Threshold: 10, Dir: t.TempDir()})
+
+	payload := bytes.Repeat([]byte("x"), 100)
+	w, _ := client.NextSpoolWriter(BinaryMessage)
+	w.Write(payload)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, p, err := server.ReadMessage(); err != nil || !bytes.Equal(p, payload) {
+		t.Errorf("ReadMessage() = %d bytes, %v, want %d bytes", len(p), err, len(payload))
+	}
+}
@@ -0,0 +1,324 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stomp
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Acknowledgement modes for subscriptions.
+const (
+	AckAuto             = "auto"
+	AckClient           = "client"
+	AckClientIndividual = "client-individual"
+)
+
+// ClientConfig specifies options for the client role.
+type ClientConfig struct {
+	// Host is the value of the host header. If Host is empty, the host of
+	// the websocket connection's remote address is used.
+	Host string
+
+	// Login and Passcode are the credentials sent to the server.
+	Login, Passcode string
+
+	// Header specifies additional CONNECT frame headers.
+	Header Header
+
+	// SendHeartBeat is the smallest interval at which the client can send
+	// heart-beats. Zero means the client cannot send heart-beats.
+	SendHeartBeat time.Duration
+
+	// ReceiveHeartBeat is the desired interval between heart-beats from the
+	// server. Zero means the client does not want to receive heart-beats.
+	ReceiveHeartBeat time.Duration
+}
+
+// ServerError is returned when the server sends an ERROR frame.
+type ServerError struct {
+	Frame *Frame
+}
+
+func (e *ServerError) Error() string {
+	msg := e.Frame.Header.Get("message")
+	if msg == "" {
+		msg = string(e.Frame.Body)
+	}
+	return "stomp: server error: " + msg
+}
+
+// Client is the client role of a STOMP connection.
+//
+// It is safe to call Client's methods concurrently.
+type Client struct {
+	conn *Conn
+
+	// Connected is the CONNECTED frame received from the server.
+	Connected *Frame
+
+	mu       sync.Mutex
+	nextID   uint64
+	subs     map[string]*Subscription
+	receipts map[string]chan struct{}
+	err      error
+}
+
+// Connect sends a CONNECT frame on ws and waits for the CONNECTED frame from
+// the server. The websocket connection should be dialed with Subprotocol in
+// Dialer.Subprotocols.
+func Connect(ws *websocket.Conn, config *ClientConfig) (*Client, error) {
+	if config == nil {
+		config = &ClientConfig{}
+	}
+	host := config.Host
+	if host == "" {
+		host = ws.RemoteAddr().String()
+	}
+
+	f := NewFrame(CommandConnect,
+		"accept-version", "1.2",
+		"host", host,
+		"heart-beat", formatHeartBeat(config.SendHeartBeat, config.ReceiveHeartBeat))
+	if config.Login != "" {
+		f.Header.Add("login", config.Login)
+		f.Header.Add("passcode", config.Passcode)
+	}
+	f.Header = append(f.Header, config.Header...)
+
+	conn := newConn(ws)
+	if err := conn.WriteFrame(f); err != nil {
+		return nil, err
+	}
+	reply, err := conn.ReadFrame()
+	if err != nil {
+		return nil, err
+	}
+	switch reply.Command {
+	case CommandConnected:
+	case CommandError:
+		conn.Close()
+		return nil, &ServerError{reply}
+	default:
+		conn.Close()
+		return nil, errors.New("stomp: unexpected " + reply.Command + " frame")
+	}
+	if v := reply.Header.Get("version"); v != "1.2" {
+		conn.Close()
+		return nil, errors.New("stomp: unsupported server version " + strconv.Quote(v))
+	}
+	sx, sy, err := parseHeartBeat(reply.Header.Get("heart-beat"))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.startHeartBeat(negotiate(config.SendHeartBeat, sy), negotiate(sx, config.ReceiveHeartBeat))
+
+	c := &Client{
+		conn:      conn,
+		Connected: reply,
+		subs:      make(map[string]*Subscription),
+		receipts:  make(map[string]chan struct{}),
+	}
+	ws.Go(c.readLoop)
+	return c, nil
+}
+
+func (c *Client) readLoop() {
+	var err error
+	for {
+		var f *Frame
+		f, err = c.conn.ReadFrame()
+		if err != nil {
+			break
+		}
+		switch f.Command {
+		case CommandMessage:
+			c.mu.Lock()
+			s := c.subs[f.Header.Get("subscription")]
+			c.mu.Unlock()
+			if s != nil {
+				select {
+				case s.c <- f:
+				case <-s.done:
+				}
+			}
+		case CommandReceipt:
+			c.mu.Lock()
+			ch := c.receipts[f.Header.Get("receipt-id")]
+			delete(c.receipts, f.Header.Get("receipt-id"))
+			c.mu.Unlock()
+			if ch != nil {
+				close(ch)
+			}
+		case CommandError:
+			err = &ServerError{f}
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	c.mu.Lock()
+	c.err = err
+	subs := c.subs
+	c.subs = nil
+	c.receipts = nil
+	c.mu.Unlock()
+	for _, s := range subs {
+		// The read loop is the only sender on the channel.
+		close(s.c)
+	}
+	c.conn.Close()
+}
+
+// Err returns the error that terminated the connection or nil if the
+// connection is active.
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *Client) newID() string {
+	c.nextID++
+	return strconv.FormatUint(c.nextID, 10)
+}
+
+// Send sends body to the destination. The header argument specifies
+// additional SEND frame headers such as content-type.
+func (c *Client) Send(destination string, body []byte, header Header) error {
+	f := NewFrame(CommandSend, "destination", destination)
+	f.Header = append(f.Header, header...)
+	f.Body = body
+	return c.conn.WriteFrame(f)
+}
+
+// Subscribe subscribes to the destination with the acknowledgement mode ack.
+// If ack is empty, AckAuto is used.
+func (c *Client) Subscribe(destination, ack string) (*Subscription, error) {
+	if ack == "" {
+		ack = AckAuto
+	}
+	s := &Subscription{client: c, c: make(chan *Frame, 16), done: make(chan struct{})}
+	c.mu.Lock()
+	if c.subs == nil {
+		err := c.err
+		c.mu.Unlock()
+		return nil, err
+	}
+	s.id = c.newID()
+	c.subs[s.id] = s
+	c.mu.Unlock()
+
+	if err := c.conn.WriteFrame(NewFrame(CommandSubscribe, "id", s.id, "destination", destination, "ack", ack)); err != nil {
+		c.mu.Lock()
+		delete(c.subs, s.id)
+		c.mu.Unlock()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Ack acknowledges consumption of a message received on a subscription with
+// the client or client-individual acknowledgement mode.
+func (c *Client) Ack(message *Frame) error {
+	return c.conn.WriteFrame(NewFrame(CommandAck, "id", message.Header.Get("ack")))
+}
+
+// Nack tells the server that the message was not consumed.
+func (c *Client) Nack(message *Frame) error {
+	return c.conn.WriteFrame(NewFrame(CommandNack, "id", message.Header.Get("ack")))
+}
+
+// SendWithReceipt writes the frame with a receipt header and waits for the
+// server's receipt or the timeout.
+func (c *Client) SendWithReceipt(f *Frame, timeout time.Duration) error {
+	ch := make(chan struct{})
+	c.mu.Lock()
+	if c.receipts == nil {
+		err := c.err
+		c.mu.Unlock()
+		return err
+	}
+	id := c.newID()
+	c.receipts[id] = ch
+	c.mu.Unlock()
+
+	f.Header.Set("receipt", id)
+	if err := c.conn.WriteFrame(f); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-ch:
+		return nil
+	case <-c.conn.done:
+		if err := c.Err(); err != nil {
+			return err
+		}
+		return errClosed
+	case <-timer.C:
+		return errors.New("stomp: timeout waiting for receipt")
+	}
+}
+
+var errClosed = errors.New("stomp: connection closed")
+
+// Disconnect gracefully disconnects from the server: Disconnect waits for
+// the server to acknowledge the DISCONNECT frame and then closes the
+// connection.
+func (c *Client) Disconnect() error {
+	err := c.SendWithReceipt(NewFrame(CommandDisconnect), writeWait)
+	c.conn.Close()
+	return err
+}
+
+// Close closes the connection without disconnecting.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Subscription is a subscription created with Client.Subscribe.
+type Subscription struct {
+	client *Client
+	id     string
+	c      chan *Frame
+
+	once sync.Once
+	done chan struct{} // closed on Unsubscribe
+}
+
+// ID returns the subscription ID.
+func (s *Subscription) ID() string {
+	return s.id
+}
+
+// C returns the channel of MESSAGE frames received for the subscription.
+// The channel is closed when the connection ends. Messages are not delivered
+// to the channel after Unsubscribe is called.
+func (s *Subscription) C() <-chan *Frame {
+	return s.c
+}
+
+// Unsubscribe ends the subscription.
+func (s *Subscription) Unsubscribe() error {
+	c := s.client
+	c.mu.Lock()
+	_, active := c.subs[s.id]
+	delete(c.subs, s.id)
+	c.mu.Unlock()
+	if !active {
+		return nil
+	}
+	s.once.Do(func() { close(s.done) })
+	return c.conn.WriteFrame(NewFrame(CommandUnsubscribe, "id", s.id))
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stomp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newTestServer starts a server that echoes SEND frames to the subscribers
+// of the destination.
+func newTestServer(t *testing.T, config *ServerConfig) string {
+	upgrader := websocket.Upgrader{Subprotocols: []string{Subprotocol}}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		c, err := Accept(ws, config)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		for {
+			f, err := c.ReadFrame()
+			if err != nil {
+				return
+			}
+			switch f.Command {
+			case CommandSend:
+				for _, sub := range c.Subscriptions(f.Header.Get("destination")) {
+					if _, err := c.SendMessage(sub.ID, f.Body, Header{{"content-type", "text/plain"}}); err != nil {
+						t.Errorf("SendMessage returned error %v", err)
+					}
+				}
+			case CommandDisconnect:
+				c.Receipt(f)
+				return
+			default:
+				c.Receipt(f)
+			}
+		}
+	}))
+	t.Cleanup(s.Close)
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+func dial(t *testing.T, url string) *websocket.Conn {
+	d := websocket.Dialer{Subprotocols: []string{Subprotocol}}
+	ws, _, err := d.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	return ws
+}
+
+func TestClientServer(t *testing.T) {
+	url := newTestServer(t, &ServerConfig{Server: "test/1.0", SendHeartBeat: 20 * time.Millisecond, ReceiveHeartBeat: 20 * time.Millisecond})
+	c, err := Connect(dial(t, url), &ClientConfig{Host: "test", SendHeartBeat: 20 * time.Millisecond, ReceiveHeartBeat: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Connect returned error %v", err)
+	}
+	if s := c.Connected.Header.Get("server"); s != "test/1.0" {
+		t.Errorf("server header = %q, want test/1.0", s)
+	}
+
+	sub, err := c.Subscribe("/topic/a", AckClientIndividual)
+	if err != nil {
+		t.Fatalf("Subscribe returned error %v", err)
+	}
+	// Wait for the subscription to be registered.
+	if err := c.SendWithReceipt(NewFrame(CommandBegin, "transaction", "t1"), time.Second); err != nil {
+		t.Fatalf("SendWithReceipt returned error %v", err)
+	}
+
+	// Wait long enough for heart-beats to keep the connection alive.
+	time.Sleep(100 * time.Millisecond)
+
+	if err := c.Send("/topic/a", []byte("hello\nworld"), nil); err != nil {
+		t.Fatalf("Send returned error %v", err)
+	}
+	select {
+	case m := <-sub.C():
+		if string(m.Body) != "hello\nworld" || m.Header.Get("subscription") != sub.ID() || m.Header.Get("ack") == "" {
+			t.Errorf("received %+v", m)
+		}
+		if err := c.Ack(m); err != nil {
+			t.Errorf("Ack returned error %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for message")
+	}
+
+	if err := sub.Unsubscribe(); err != nil {
+		t.Errorf("Unsubscribe returned error %v", err)
+	}
+	if err := c.Disconnect(); err != nil {
+		t.Errorf("Disconnect returned error %v", err)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	url := newTestServer(t, &ServerConfig{
+		Authenticate: func(f *Frame) error {
+			if f.Header.Get("login") != "guest" || f.Header.Get("passcode") != "guest" {
+				return errors.New("bad credentials")
+			}
+			return nil
+		},
+	})
+	_, err := Connect(dial(t, url), &ClientConfig{Login: "guest", Passcode: "wrong"})
+	var se *ServerError
+	if !errors.As(err, &se) || se.Frame.Header.Get("message") != "bad credentials" {
+		t.Errorf("Connect returned error %v, want bad credentials", err)
+	}
+	c, err := Connect(dial(t, url), &ClientConfig{Login: "guest", Passcode: "guest"})
+	if err != nil {
+		t.Fatalf("Connect returned error %v", err)
+	}
+	c.Close()
+}
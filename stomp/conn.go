@@ -0,0 +1,169 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package stomp implements STOMP 1.2 over WebSocket connections.
+//
+// The package provides the client role (Connect) for talking to brokers that
+// expose STOMP over WebSocket, such as ActiveMQ and RabbitMQ Web-STOMP, and
+// the server role (Accept) for applications that accept STOMP clients. Both
+// roles handle frame encoding, header escaping, heart-beat negotiation and
+// subscription bookkeeping.
+//
+// Each frame is sent in a separate text message. Received messages may carry
+// multiple frames and heart-beat EOLs.
+//
+// The specification is at https://stomp.github.io/stomp-specification-1.2.html.
+package stomp
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Subprotocol is the WebSocket subprotocol name for STOMP 1.2.
+const Subprotocol = "v12.stomp"
+
+const writeWait = 10 * time.Second
+
+// heartBeatTolerance is the multiple of the negotiated receive interval to
+// wait for data from the peer before considering the connection dead.
+const heartBeatTolerance = 2
+
+// Conn handles framing and heart-beating on a websocket connection. Conn is
+// used by the Client and ServerConn types.
+//
+// Conn supports one concurrent reader. It is safe to call WriteFrame and Close
+// concurrently with other methods.
+type Conn struct {
+	ws *websocket.Conn
+
+	frames      []*Frame
+	readTimeout time.Duration
+
+	writeMu sync.Mutex
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newConn(ws *websocket.Conn) *Conn {
+	return &Conn{ws: ws, done: make(chan struct{})}
+}
+
+// ReadFrame reads the next frame from the peer. Heart-beats are consumed.
+func (c *Conn) ReadFrame() (*Frame, error) {
+	for len(c.frames) == 0 {
+		if c.readTimeout > 0 {
+			if err := c.ws.SetReadDeadline(time.Now().Add(c.readTimeout)); err != nil {
+				return nil, err
+			}
+		}
+		_, p, err := c.ws.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+		frames, err := parseFrames(p)
+		if err != nil {
+			return nil, err
+		}
+		c.frames = frames
+	}
+	f := c.frames[0]
+	c.frames[0] = nil
+	c.frames = c.frames[1:]
+	return f, nil
+}
+
+// WriteFrame writes a frame to the peer.
+func (c *Conn) WriteFrame(f *Frame) error {
+	return c.write(appendFrame(nil, f))
+}
+
+func (c *Conn) write(p []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.ws.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+		return err
+	}
+	return c.ws.WriteMessage(websocket.TextMessage, p)
+}
+
+// Close stops heart-beating and closes the websocket connection.
+func (c *Conn) Close() error {
+	c.closeOnce.Do(func() { close(c.done) })
+	return c.ws.Close()
+}
+
+// WebSocket returns the underlying websocket connection.
+func (c *Conn) WebSocket() *websocket.Conn {
+	return c.ws
+}
+
+var heartBeatEOL = []byte{'\n'}
+
+// startHeartBeat starts sending heart-beats at the given interval and sets
+// the read timeout for the expected receive interval. Zero disables the
+// corresponding direction.
+func (c *Conn) startHeartBeat(send, receive time.Duration) {
+	c.readTimeout = receive * heartBeatTolerance
+	if send <= 0 {
+		return
+	}
+	c.ws.Go(func() {
+		ticker := time.NewTicker(send)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := c.write(heartBeatEOL); err != nil {
+					return
+				}
+			case <-c.done:
+				return
+			}
+		}
+	})
+}
+
+var errBadHeartBeat = errors.New("stomp: malformed heart-beat header")
+
+// parseHeartBeat parses a heart-beat header value. An empty value means no
+// heart-beating.
+func parseHeartBeat(s string) (x, y time.Duration, err error) {
+	if s == "" {
+		return 0, 0, nil
+	}
+	xs, ys, ok := strings.Cut(s, ",")
+	if !ok {
+		return 0, 0, errBadHeartBeat
+	}
+	xn, err1 := strconv.ParseUint(strings.TrimSpace(xs), 10, 32)
+	yn, err2 := strconv.ParseUint(strings.TrimSpace(ys), 10, 32)
+	if err1 != nil || err2 != nil {
+		return 0, 0, errBadHeartBeat
+	}
+	return time.Duration(xn) * time.Millisecond, time.Duration(yn) * time.Millisecond, nil
+}
+
+func formatHeartBeat(x, y time.Duration) string {
+	return strconv.FormatInt(int64(x/time.Millisecond), 10) + "," + strconv.FormatInt(int64(y/time.Millisecond), 10)
+}
+
+// negotiate returns the interval for heart-beats sent from one side given
+// the sender's offer and the receiver's desired interval. Zero means no
+// heart-beats.
+func negotiate(offer, desire time.Duration) time.Duration {
+	if offer == 0 || desire == 0 {
+		return 0
+	}
+	if offer > desire {
+		return offer
+	}
+	return desire
+}
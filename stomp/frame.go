@@ -0,0 +1,275 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stomp
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Client and server frame commands defined in STOMP 1.2.
+const (
+	CommandConnect     = "CONNECT"
+	CommandStomp       = "STOMP"
+	CommandSend        = "SEND"
+	CommandSubscribe   = "SUBSCRIBE"
+	CommandUnsubscribe = "UNSUBSCRIBE"
+	CommandAck         = "ACK"
+	CommandNack        = "NACK"
+	CommandBegin       = "BEGIN"
+	CommandCommit      = "COMMIT"
+	CommandAbort       = "ABORT"
+	CommandDisconnect  = "DISCONNECT"
+
+	CommandConnected = "CONNECTED"
+	CommandMessage   = "MESSAGE"
+	CommandReceipt   = "RECEIPT"
+	CommandError     = "ERROR"
+)
+
+// HeaderField is a frame header.
+type HeaderField struct {
+	Key, Value string
+}
+
+// Header is the ordered list of headers in a frame. If a header is repeated,
+// the first value is used.
+type Header []HeaderField
+
+// Get returns the first value for the key or "" if the key is not present.
+func (h Header) Get(key string) string {
+	v, _ := h.Lookup(key)
+	return v
+}
+
+// Lookup returns the first value for the key and whether the key is present.
+func (h Header) Lookup(key string) (string, bool) {
+	for _, f := range h {
+		if f.Key == key {
+			return f.Value, true
+		}
+	}
+	return "", false
+}
+
+// Add appends the header.
+func (h *Header) Add(key, value string) {
+	*h = append(*h, HeaderField{key, value})
+}
+
+// Set replaces all values for the key with value.
+func (h *Header) Set(key, value string) {
+	h.Del(key)
+	h.Add(key, value)
+}
+
+// Del removes all values for the key.
+func (h *Header) Del(key string) {
+	fields := (*h)[:0]
+	for _, f := range *h {
+		if f.Key != key {
+			fields = append(fields, f)
+		}
+	}
+	*h = fields
+}
+
+// Frame is a STOMP frame.
+type Frame struct {
+	Command string
+	Header  Header
+	Body    []byte
+}
+
+// NewFrame returns a frame with the command and headers. The headers are
+// specified as alternating keys and values.
+func NewFrame(command string, keyValues ...string) *Frame {
+	f := &Frame{Command: command}
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		f.Header.Add(keyValues[i], keyValues[i+1])
+	}
+	return f
+}
+
+// escapeHeaders reports whether header values are escaped for the command.
+// Escaping is not used in the CONNECT and CONNECTED frames for backward
+// compatibility with STOMP 1.0.
+func escapeHeaders(command string) bool {
+	return command != CommandConnect && command != CommandConnected
+}
+
+var headerEscaper = strings.NewReplacer("\\", "\\\\", "\r", "\\r", "\n", "\\n", ":", "\\c")
+
+// appendFrame appends the wire representation of f to p.
+func appendFrame(p []byte, f *Frame) []byte {
+	escape := escapeHeaders(f.Command)
+	p = append(p, f.Command...)
+	p = append(p, '\n')
+	hasLength := false
+	for _, h := range f.Header {
+		if h.Key == "content-length" {
+			hasLength = true
+		}
+		if escape {
+			p = append(p, headerEscaper.Replace(h.Key)...)
+			p = append(p, ':')
+			p = append(p, headerEscaper.Replace(h.Value)...)
+		} else {
+			p = append(p, h.Key...)
+			p = append(p, ':')
+			p = append(p, h.Value...)
+		}
+		p = append(p, '\n')
+	}
+	if !hasLength && len(f.Body) > 0 {
+		p = append(p, "content-length:"...)
+		p = strconv.AppendInt(p, int64(len(f.Body)), 10)
+		p = append(p, '\n')
+	}
+	p = append(p, '\n')
+	p = append(p, f.Body...)
+	p = append(p, 0)
+	return p
+}
+
+var (
+	errMalformedFrame = errors.New("stomp: malformed frame")
+	errBadEscape      = errors.New("stomp: undefined escape sequence in header")
+)
+
+// parseFrames parses the frames in p. Heart-beat EOLs before and between
+// frames are skipped.
+func parseFrames(p []byte) ([]*Frame, error) {
+	var frames []*Frame
+	for {
+		p = skipEOLs(p)
+		if len(p) == 0 {
+			return frames, nil
+		}
+		f, rest, err := parseFrame(p)
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, f)
+		p = rest
+	}
+}
+
+func skipEOLs(p []byte) []byte {
+	for len(p) > 0 {
+		switch {
+		case p[0] == '\n':
+			p = p[1:]
+		case len(p) > 1 && p[0] == '\r' && p[1] == '\n':
+			p = p[2:]
+		default:
+			return p
+		}
+	}
+	return p
+}
+
+// nextLine returns the line at the start of p without the EOL and the
+// remainder of p.
+func nextLine(p []byte) (line, rest []byte, ok bool) {
+	i := bytes.IndexByte(p, '\n')
+	if i < 0 {
+		return nil, nil, false
+	}
+	line = p[:i]
+	if len(line) > 0 && line[len(line)-1] == '\r' {
+		line = line[:len(line)-1]
+	}
+	return line, p[i+1:], true
+}
+
+func parseFrame(p []byte) (*Frame, []byte, error) {
+	line, p, ok := nextLine(p)
+	if !ok || len(line) == 0 {
+		return nil, nil, errMalformedFrame
+	}
+	f := &Frame{Command: string(line)}
+	escape := escapeHeaders(f.Command)
+
+	contentLength := -1
+	for {
+		line, p, ok = nextLine(p)
+		if !ok {
+			return nil, nil, errMalformedFrame
+		}
+		if len(line) == 0 {
+			break
+		}
+		i := bytes.IndexByte(line, ':')
+		if i < 0 {
+			return nil, nil, errMalformedFrame
+		}
+		key, value := string(line[:i]), string(line[i+1:])
+		if escape {
+			var err error
+			if key, err = unescape(key); err != nil {
+				return nil, nil, err
+			}
+			if value, err = unescape(value); err != nil {
+				return nil, nil, err
+			}
+		}
+		if key == "content-length" && contentLength < 0 {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return nil, nil, errMalformedFrame
+			}
+			contentLength = n
+		}
+		f.Header.Add(key, value)
+	}
+
+	if contentLength >= 0 {
+		if len(p) < contentLength+1 || p[contentLength] != 0 {
+			return nil, nil, errMalformedFrame
+		}
+		f.Body = p[:contentLength]
+		return f, p[contentLength+1:], nil
+	}
+	i := bytes.IndexByte(p, 0)
+	if i < 0 {
+		return nil, nil, errMalformedFrame
+	}
+	f.Body = p[:i]
+	return f, p[i+1:], nil
+}
+
+func unescape(s string) (string, error) {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		if i >= len(s) {
+			return "", errBadEscape
+		}
+		switch s[i] {
+		case 'r':
+			b.WriteByte('\r')
+		case 'n':
+			b.WriteByte('\n')
+		case 'c':
+			b.WriteByte(':')
+		case '\\':
+			b.WriteByte('\\')
+		default:
+			return "", errBadEscape
+		}
+	}
+	return b.String(), nil
+}
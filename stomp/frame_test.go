@@ -0,0 +1,114 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stomp
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var frameTests = []struct {
+	f    *Frame
+	wire string
+}{
+	{NewFrame(CommandDisconnect), "DISCONNECT\n\n\x00"},
+	{
+		&Frame{Command: CommandSend, Header: Header{{"destination", "/q"}}, Body: []byte("hi")},
+		"SEND\ndestination:/q\ncontent-length:2\n\nhi\x00",
+	},
+	{
+		&Frame{Command: CommandSend, Header: Header{{"a:b", "c\nd\\e\r"}, {"content-length", "3"}}, Body: []byte("x\x00y")},
+		"SEND\na\\cb:c\\nd\\\\e\\r\ncontent-length:3\n\nx\x00y\x00",
+	},
+	{NewFrame(CommandConnect, "login", "a:b"), "CONNECT\nlogin:a:b\n\n\x00"},
+}
+
+func TestFrameRoundTrip(t *testing.T) {
+	for _, tt := range frameTests {
+		wire := string(appendFrame(nil, tt.f))
+		if wire != tt.wire {
+			t.Errorf("appendFrame(%+v) = %q, want %q", tt.f, wire, tt.wire)
+		}
+		frames, err := parseFrames([]byte(wire))
+		if err != nil || len(frames) != 1 {
+			t.Errorf("parseFrames(%q) returned %v, %v", wire, frames, err)
+			continue
+		}
+		f := frames[0]
+		if f.Command != tt.f.Command || string(f.Body) != string(tt.f.Body) {
+			t.Errorf("parseFrames(%q) returned %+v, want %+v", wire, f, tt.f)
+		}
+		for _, h := range tt.f.Header {
+			if v := f.Header.Get(h.Key); v != h.Value {
+				t.Errorf("parseFrames(%q) header %q = %q, want %q", wire, h.Key, v, h.Value)
+			}
+		}
+	}
+}
+
+func TestParseFrames(t *testing.T) {
+	frames, err := parseFrames([]byte("\n\r\nMESSAGE\r\nk:1\r\nk:2\r\n\r\nbody\x00\nRECEIPT\nreceipt-id:7\n\n\x00\n"))
+	if err != nil {
+		t.Fatalf("parseFrames returned error %v", err)
+	}
+	if len(frames) != 2 {
+		t.Fatalf("parseFrames returned %d frames, want 2", len(frames))
+	}
+	if frames[0].Command != CommandMessage || frames[0].Header.Get("k") != "1" || string(frames[0].Body) != "body" {
+		t.Errorf("frames[0] = %+v", frames[0])
+	}
+	if frames[1].Command != CommandReceipt || frames[1].Header.Get("receipt-id") != "7" {
+		t.Errorf("frames[1] = %+v", frames[1])
+	}
+
+	for _, bad := range []string{
+		"SEND\n\nno terminator",
+		"SEND\nnocolon\n\n\x00",
+		"SEND\nk:\\t\n\n\x00",
+		"SEND\ncontent-length:5\n\nab\x00",
+	} {
+		if _, err := parseFrames([]byte(bad)); err == nil {
+			t.Errorf("parseFrames(%q) did not return an error", bad)
+		}
+	}
+}
+
+func TestHeader(t *testing.T) {
+	var h Header
+	h.Add("a", "1")
+	h.Add("b", "2")
+	h.Add("a", "3")
+	if v := h.Get("a"); v != "1" {
+		t.Errorf("Get(a) = %q, want 1", v)
+	}
+	h.Set("a", "4")
+	if !reflect.DeepEqual(h, Header{{"b", "2"}, {"a", "4"}}) {
+		t.Errorf("after Set, h = %v", h)
+	}
+	h.Del("b")
+	if _, ok := h.Lookup("b"); ok {
+		t.Errorf("after Del, Lookup(b) found value")
+	}
+}
+
+func TestHeartBeat(t *testing.T) {
+	x, y, err := parseHeartBeat("100, 200")
+	if err != nil || x != 100*time.Millisecond || y != 200*time.Millisecond {
+		t.Errorf("parseHeartBeat returned %v, %v, %v", x, y, err)
+	}
+	if _, _, err := parseHeartBeat("100"); err == nil {
+		t.Error("parseHeartBeat(100) did not return an error")
+	}
+	if s := formatHeartBeat(time.Second, 0); s != "1000,0" {
+		t.Errorf("formatHeartBeat = %q, want 1000,0", s)
+	}
+	if d := negotiate(100*time.Millisecond, 300*time.Millisecond); d != 300*time.Millisecond {
+		t.Errorf("negotiate = %v, want 300ms", d)
+	}
+	if d := negotiate(0, 300*time.Millisecond); d != 0 {
+		t.Errorf("negotiate = %v, want 0", d)
+	}
+}
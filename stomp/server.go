@@ -0,0 +1,219 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stomp
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ServerConfig specifies options for the server role.
+type ServerConfig struct {
+	// Authenticate is called with the CONNECT frame. If Authenticate returns
+	// an error, the error is sent to the client in an ERROR frame and the
+	// connection is closed. If Authenticate is nil, all clients are accepted.
+	Authenticate func(connect *Frame) error
+
+	// Server is the value of the server header in the CONNECTED frame.
+	Server string
+
+	// SendHeartBeat is the smallest interval at which the server can send
+	// heart-beats. Zero means the server cannot send heart-beats.
+	SendHeartBeat time.Duration
+
+	// ReceiveHeartBeat is the desired interval between heart-beats from the
+	// client. Zero means the server does not want to receive heart-beats.
+	ReceiveHeartBeat time.Duration
+}
+
+// ServerSubscription describes a subscription created by a client.
+type ServerSubscription struct {
+	ID          string
+	Destination string
+	Ack         string
+}
+
+// ServerConn is the server role of a STOMP connection.
+//
+// ServerConn supports one concurrent reader. It is safe to call the other
+// methods concurrently.
+type ServerConn struct {
+	conn *Conn
+
+	// Connect is the CONNECT frame received from the client.
+	Connect *Frame
+
+	mu     sync.Mutex
+	subs   map[string]ServerSubscription
+	nextID uint64
+}
+
+// Accept reads the CONNECT frame from ws and replies with a CONNECTED frame.
+// The Upgrader should include Subprotocol in Upgrader.Subprotocols.
+func Accept(ws *websocket.Conn, config *ServerConfig) (*ServerConn, error) {
+	if config == nil {
+		config = &ServerConfig{}
+	}
+	conn := newConn(ws)
+	fail := func(err error) (*ServerConn, error) {
+		f := NewFrame(CommandError, "message", err.Error())
+		_ = conn.WriteFrame(f)
+		conn.Close()
+		return nil, err
+	}
+
+	f, err := conn.ReadFrame()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if f.Command != CommandConnect && f.Command != CommandStomp {
+		return fail(errors.New("expected CONNECT frame"))
+	}
+	if !acceptsVersion(f.Header.Get("accept-version"), "1.2") {
+		return fail(errors.New("supported protocol versions are 1.2"))
+	}
+	cx, cy, err := parseHeartBeat(f.Header.Get("heart-beat"))
+	if err != nil {
+		return fail(err)
+	}
+	if config.Authenticate != nil {
+		if err := config.Authenticate(f); err != nil {
+			return fail(err)
+		}
+	}
+
+	reply := NewFrame(CommandConnected,
+		"version", "1.2",
+		"heart-beat", formatHeartBeat(config.SendHeartBeat, config.ReceiveHeartBeat))
+	if config.Server != "" {
+		reply.Header.Add("server", config.Server)
+	}
+	if err := conn.WriteFrame(reply); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.startHeartBeat(negotiate(config.SendHeartBeat, cy), negotiate(cx, config.ReceiveHeartBeat))
+	return &ServerConn{conn: conn, Connect: f, subs: make(map[string]ServerSubscription)}, nil
+}
+
+func acceptsVersion(header, version string) bool {
+	for _, v := range strings.Split(header, ",") {
+		if strings.TrimSpace(v) == version {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadFrame reads the next frame from the client. SUBSCRIBE and UNSUBSCRIBE
+// frames update the connection's subscriptions before they are returned to
+// the application. ReadFrame does not send receipts; use Receipt.
+func (c *ServerConn) ReadFrame() (*Frame, error) {
+	f, err := c.conn.ReadFrame()
+	if err != nil {
+		return nil, err
+	}
+	switch f.Command {
+	case CommandSubscribe:
+		id := f.Header.Get("id")
+		dest := f.Header.Get("destination")
+		if id == "" || dest == "" {
+			return nil, c.Error("SUBSCRIBE requires id and destination headers", f)
+		}
+		ack := f.Header.Get("ack")
+		if ack == "" {
+			ack = AckAuto
+		}
+		c.mu.Lock()
+		c.subs[id] = ServerSubscription{ID: id, Destination: dest, Ack: ack}
+		c.mu.Unlock()
+	case CommandUnsubscribe:
+		c.mu.Lock()
+		delete(c.subs, f.Header.Get("id"))
+		c.mu.Unlock()
+	}
+	return f, nil
+}
+
+// Subscriptions returns the client's active subscriptions to destination.
+func (c *ServerConn) Subscriptions(destination string) []ServerSubscription {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var subs []ServerSubscription
+	for _, s := range c.subs {
+		if s.Destination == destination {
+			subs = append(subs, s)
+		}
+	}
+	return subs
+}
+
+// SendMessage sends a MESSAGE frame for the subscription with the given ID.
+// The header argument specifies additional headers such as content-type.
+// SendMessage returns the message-id header sent with the message.
+func (c *ServerConn) SendMessage(subscriptionID string, body []byte, header Header) (string, error) {
+	c.mu.Lock()
+	s, ok := c.subs[subscriptionID]
+	c.nextID++
+	id := strconv.FormatUint(c.nextID, 10)
+	c.mu.Unlock()
+	if !ok {
+		return "", errors.New("stomp: no subscription " + strconv.Quote(subscriptionID))
+	}
+
+	f := NewFrame(CommandMessage, "subscription", s.ID, "message-id", id, "destination", s.Destination)
+	if s.Ack != AckAuto {
+		f.Header.Add("ack", id)
+	}
+	f.Header = append(f.Header, header...)
+	f.Body = body
+	return id, c.conn.WriteFrame(f)
+}
+
+// Receipt sends a RECEIPT frame if the client requested a receipt for f.
+func (c *ServerConn) Receipt(f *Frame) error {
+	id, ok := f.Header.Lookup("receipt")
+	if !ok {
+		return nil
+	}
+	return c.conn.WriteFrame(NewFrame(CommandReceipt, "receipt-id", id))
+}
+
+// Error sends an ERROR frame with the message and closes the connection. If
+// cause is not nil, the frame includes a receipt-id header for the cause's
+// receipt and the cause frame is included in the body.
+func (c *ServerConn) Error(message string, cause *Frame) error {
+	f := NewFrame(CommandError, "message", message)
+	if cause != nil {
+		if id, ok := cause.Header.Lookup("receipt"); ok {
+			f.Header.Add("receipt-id", id)
+		}
+		f.Header.Add("content-type", "text/plain")
+		f.Body = appendFrame(nil, cause)
+		f.Body = f.Body[:len(f.Body)-1]
+	}
+	err := c.conn.WriteFrame(f)
+	c.conn.Close()
+	if err != nil {
+		return err
+	}
+	return errors.New("stomp: " + message)
+}
+
+// WriteFrame writes a frame to the client.
+func (c *ServerConn) WriteFrame(f *Frame) error {
+	return c.conn.WriteFrame(f)
+}
+
+// Close closes the connection.
+func (c *ServerConn) Close() error {
+	return c.conn.Close()
+}
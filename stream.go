@@ -0,0 +1,202 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// StreamConfig specifies the role and the negotiated state of a connection
+// created by NewStreamConn.
+type StreamConfig struct {
+	// IsServer specifies the role of the connection. A client connection
+	// masks the frames it sends and expects unmasked frames from the peer.
+	// A server connection expects masked frames and sends unmasked frames.
+	IsServer bool
+
+	// ReadBufferSize and WriteBufferSize specify I/O buffer sizes in bytes.
+	// If a buffer size is zero, a default size of 4096 is used.
+	ReadBufferSize, WriteBufferSize int
+
+	// WriteBufferPool is a pool of buffers for write operations as
+	// described for Upgrader.WriteBufferPool.
+	WriteBufferPool BufferPool
+
+	// Subprotocol is the subprotocol reported by Conn.Subprotocol.
+	Subprotocol string
+
+	// EnableCompression specifies that the peers agreed to per-message
+	// compression (RFC 7692) without context takeover.
+	EnableCompression bool
+
+	// Clock specifies the clock for the connection's deadlines. If Clock is
+	// nil, the system clock is used.
+	Clock Clock
+}
+
+// NewStreamConn creates a connection over the transport rwc without an
+// opening handshake. Use NewStreamConn when the peers agree by other means
+// that rwc carries WebSocket frames, as with a QUIC stream, a virtio socket
+// or an in-process pipe.
+//
+// To run the opening handshake over rwc, use NewClientConn with
+// StreamNetConn(rwc) on the client and Upgrader.UpgradeStream on the server.
+func NewStreamConn(rwc io.ReadWriteCloser, cfg StreamConfig) *Conn {
+	c := newConn(StreamNetConn(rwc), cfg.IsServer, cfg.ReadBufferSize, cfg.WriteBufferSize, cfg.WriteBufferPool, nil, nil)
+	c.clock = clockOrSystem(cfg.Clock)
+	c.subprotocol = cfg.Subprotocol
+	if cfg.EnableCompression {
+		c.newCompressionWriter = compressNoContextTakeover
+		c.newDecompressionReader = decompressNoContextTakeover
+	}
+	return c
+}
+
+// StreamNetConn adapts rwc to the net.Conn interface. If rwc is a net.Conn,
+// StreamNetConn returns rwc.
+//
+// The returned connection delegates LocalAddr, RemoteAddr, SetDeadline,
+// SetReadDeadline and SetWriteDeadline to rwc when rwc has those methods.
+// Otherwise the addresses have the network "stream" and setting a deadline
+// has no effect.
+func StreamNetConn(rwc io.ReadWriteCloser) net.Conn {
+	if c, ok := rwc.(net.Conn); ok {
+		return c
+	}
+	return &streamConn{ReadWriteCloser: rwc}
+}
+
+type streamConn struct {
+	io.ReadWriteCloser
+}
+
+func (c *streamConn) LocalAddr() net.Addr {
+	if a, ok := c.ReadWriteCloser.(interface{ LocalAddr() net.Addr }); ok {
+		return a.LocalAddr()
+	}
+	return streamAddr{}
+}
+
+func (c *streamConn) RemoteAddr() net.Addr {
+	if a, ok := c.ReadWriteCloser.(interface{ RemoteAddr() net.Addr }); ok {
+		return a.RemoteAddr()
+	}
+	return streamAddr{}
+}
+
+func (c *streamConn) SetDeadline(t time.Time) error {
+	if d, ok := c.ReadWriteCloser.(interface{ SetDeadline(time.Time) error }); ok {
+		return d.SetDeadline(t)
+	}
+	if err := c.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.SetWriteDeadline(t)
+}
+
+func (c *streamConn) SetReadDeadline(t time.Time) error {
+	if d, ok := c.ReadWriteCloser.(interface{ SetReadDeadline(time.Time) error }); ok {
+		return d.SetReadDeadline(t)
+	}
+	return nil
+}
+
+func (c *streamConn) SetWriteDeadline(t time.Time) error {
+	if d, ok := c.ReadWriteCloser.(interface{ SetWriteDeadline(time.Time) error }); ok {
+		return d.SetWriteDeadline(t)
+	}
+	return nil
+}
+
+type streamAddr struct{}
+
+func (streamAddr) Network() string { return "stream" }
+func (streamAddr) String() string  { return "stream" }
+
+// UpgradeStream reads the opening handshake request from rwc and upgrades
+// rwc to the WebSocket protocol as described for Upgrade. The request is
+// returned whenever it was read, so that the application can inspect it
+// after a failed handshake.
+//
+// If the upgrade fails, UpgradeStream replies to the client with an HTTP
+// error response and closes rwc.
+func (u *Upgrader) UpgradeStream(rwc io.ReadWriteCloser, responseHeader http.Header) (*Conn, *http.Request, error) {
+	netConn := StreamNetConn(rwc)
+	if u.HandshakeTimeout > 0 {
+		deadline := clockOrSystem(u.Clock).Now().Add(u.HandshakeTimeout)
+		if err := netConn.SetReadDeadline(deadline); err != nil {
+			netConn.Close()
+			return nil, nil, err
+		}
+	}
+	br := bufio.NewReader(netConn)
+	r, err := http.ReadRequest(br)
+	if err != nil {
+		netConn.Close()
+		return nil, nil, fmt.Errorf("websocket: read handshake request: %w", err)
+	}
+	if u.HandshakeTimeout > 0 {
+		if err := netConn.SetReadDeadline(time.Time{}); err != nil {
+			netConn.Close()
+			return nil, r, err
+		}
+	}
+	r.RemoteAddr = netConn.RemoteAddr().String()
+
+	w := &streamResponseWriter{conn: netConn, br: br, bw: bufio.NewWriter(netConn), header: make(http.Header)}
+	c, err := u.Upgrade(w, r, responseHeader)
+	if err != nil {
+		if !w.hijacked {
+			w.bw.Flush()
+			netConn.Close()
+		}
+		return nil, r, err
+	}
+	return c, r, nil
+}
+
+// streamResponseWriter is the http.ResponseWriter for UpgradeStream. It
+// writes error responses with "Connection: close" and hijacks to the
+// stream.
+type streamResponseWriter struct {
+	conn        net.Conn
+	br          *bufio.Reader
+	bw          *bufio.Writer
+	header      http.Header
+	wroteHeader bool
+	hijacked    bool
+}
+
+func (w *streamResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *streamResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.header.Set("Connection", "close")
+	fmt.Fprintf(w.bw, "HTTP/1.1 %03d %s\r\n", status, http.StatusText(status))
+	w.header.Write(w.bw)
+	w.bw.WriteString("\r\n")
+}
+
+func (w *streamResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.bw.Write(p)
+}
+
+func (w *streamResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return w.conn, bufio.NewReadWriter(w.br, w.bw), nil
+}
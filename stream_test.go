@@ -0,0 +1,186 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// testStream is one end of an in-process stream that is not a net.Conn.
+type testStream struct {
+	r *io.PipeReader
+	w *io.PipeWriter
+}
+
+func newTestStreams() (a, b *testStream) {
+	ar, bw := io.Pipe()
+	br, aw := io.Pipe()
+	return &testStream{r: ar, w: aw}, &testStream{r: br, w: bw}
+}
+
+func (s *testStream) Read(p []byte) (int, error)  { return s.r.Read(p) }
+func (s *testStream) Write(p []byte) (int, error) { return s.w.Write(p) }
+
+func (s *testStream) Close() error {
+	s.r.Close()
+	return s.w.Close()
+}
+
+func TestNewStreamConn(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  StreamConfig
+	}{
+		{"default", StreamConfig{}},
+		{"subprotocol", StreamConfig{Subprotocol: "chat"}},
+		{"compression", StreamConfig{EnableCompression: true}},
+		{"small buffers", StreamConfig{ReadBufferSize: 16, WriteBufferSize: 16}},
+	}
+	message := bytes.Repeat([]byte("hello "), 1000)
+	for _, tt := range tests {
+		a, b := newTestStreams()
+		clientCfg, serverCfg := tt.cfg, tt.cfg
+		serverCfg.IsServer = true
+		client := NewStreamConn(a, clientCfg)
+		server := NewStreamConn(b, serverCfg)
+		client.EnableWriteCompression(true)
+		server.EnableWriteCompression(true)
+		if got := server.Subprotocol(); got != tt.cfg.Subprotocol {
+			t.Errorf("%s: Subprotocol() = %q, want %q", tt.name, got, tt.cfg.Subprotocol)
+		}
+		if got := client.RemoteAddr().Network(); got != "stream" {
+			t.Errorf("%s: RemoteAddr().Network() = %q, want stream", tt.name, got)
+		}
+
+		done := make(chan error, 1)
+		go func() { done <- echoOnce(server) }()
+		if err := client.WriteMessage(TextMessage, message); err != nil {
+			t.Fatalf("%s: WriteMessage() error = %v", tt.name, err)
+		}
+		mt, p, err := client.ReadMessage()
+		if err != nil || mt != TextMessage || !bytes.Equal(p, message) {
+			t.Fatalf("%s: ReadMessage() = %d, %d bytes, %v, want %d bytes", tt.name, mt, len(p), err, len(message))
+		}
+		if err := <-done; err != nil {
+			t.Fatalf("%s: server error = %v", tt.name, err)
+		}
+		client.Close()
+		server.Close()
+	}
+}
+
+func TestNewStreamConnRoleMismatch(t *testing.T) {
+	a, b := newTestStreams()
+	c1 := NewStreamConn(a, StreamConfig{})
+	c2 := NewStreamConn(b, StreamConfig{})
+	defer c1.Close()
+	defer c2.Close()
+
+	go func() {
+		c1.WriteMessage(TextMessage, []byte("hello"))
+		// Read the close message sent in response to the protocol error.
+		c1.ReadMessage()
+	}()
+	_, _, err := c2.ReadMessage()
+	var pe *ProtocolError
+	if !errors.As(err, &pe) {
+		t.Fatalf("ReadMessage() error = %v, want protocol error for masked frame to client", err)
+	}
+}
+
+func TestStreamNetConn(t *testing.T) {
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+	if got := StreamNetConn(c1); got != c1 {
+		t.Errorf("StreamNetConn(net.Conn) = %v, want the argument", got)
+	}
+
+	a, _ := newTestStreams()
+	nc := StreamNetConn(a)
+	if got := nc.LocalAddr().String(); got != "stream" {
+		t.Errorf("LocalAddr() = %q, want stream", got)
+	}
+	if err := nc.SetDeadline(time.Now()); err != nil {
+		t.Errorf("SetDeadline() error = %v", err)
+	}
+}
+
+func TestUpgradeStream(t *testing.T) {
+	a, b := newTestStreams()
+	upgrader := Upgrader{Subprotocols: []string{"chat"}, EnableCompression: true}
+
+	type result struct {
+		r   *http.Request
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		c, r, err := upgrader.UpgradeStream(b, http.Header{"X-Test": {"1"}})
+		if err == nil {
+			err = echoOnce(c)
+			c.Close()
+		}
+		done <- result{r, err}
+	}()
+
+	u := &url.URL{Scheme: "ws", Host: "stream.example", Path: "/chat"}
+	d := &Dialer{Subprotocols: []string{"chat"}, EnableCompression: true}
+	client, resp, err := NewClientConn(StreamNetConn(a), u, ClientConfig{Dialer: d})
+	if err != nil {
+		t.Fatalf("NewClientConn() error = %v", err)
+	}
+	defer client.Close()
+	if got := resp.Header.Get("X-Test"); got != "1" {
+		t.Errorf("response header X-Test = %q, want 1", got)
+	}
+	if got := client.Subprotocol(); got != "chat" {
+		t.Errorf("Subprotocol() = %q, want chat", got)
+	}
+	if err := client.WriteMessage(TextMessage, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if _, p, err := client.ReadMessage(); err != nil || string(p) != "hello" {
+		t.Fatalf("ReadMessage() = %q, %v, want hello", p, err)
+	}
+	res := <-done
+	if res.err != nil {
+		t.Fatalf("UpgradeStream() error = %v", res.err)
+	}
+	if res.r.URL.Path != "/chat" || res.r.RemoteAddr != "stream" {
+		t.Errorf("request path, remote address = %q, %q, want /chat, stream", res.r.URL.Path, res.r.RemoteAddr)
+	}
+}
+
+func TestUpgradeStreamError(t *testing.T) {
+	a, b := newTestStreams()
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := (&Upgrader{}).UpgradeStream(b, nil)
+		done <- err
+	}()
+
+	go io.WriteString(a, "GET / HTTP/1.1\r\nHost: stream.example\r\n\r\n")
+	resp, err := http.ReadResponse(bufio.NewReader(a), nil)
+	if err != nil {
+		t.Fatalf("ReadResponse() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest || !resp.Close {
+		t.Errorf("response status, close = %d, %v, want %d, true", resp.StatusCode, resp.Close, http.StatusBadRequest)
+	}
+	var he HandshakeError
+	if err := <-done; !errors.As(err, &he) {
+		t.Errorf("UpgradeStream() error = %v, want HandshakeError", err)
+	}
+}
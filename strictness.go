@@ -0,0 +1,114 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// Strictness selects how strictly a connection enforces RFC 6455 on the
+// frames read from the peer. The zero value is the default behavior of
+// the package. Each bit adds or relaxes one check, so that gateways in front
+// of sloppy legacy clients can relax only the checks that the clients break:
+//
+//	upgrader.Strictness = websocket.LenientCloseText | websocket.LenientMask
+type Strictness uint
+
+const (
+	// StrictTextUTF8 fails the connection with CloseInvalidFramePayloadData
+	// when a text message is not valid UTF-8. The reader returned by
+	// NextReader returns an error when it reaches the invalid bytes.
+	StrictTextUTF8 Strictness = 1 << iota
+
+	// LenientReservedBits ignores RSV bits that are set without a
+	// negotiated extension.
+	LenientReservedBits
+
+	// LenientOpcodes skips frames with reserved opcodes.
+	LenientOpcodes
+
+	// LenientCloseText accepts close messages whose reason is not valid
+	// UTF-8.
+	LenientCloseText
+
+	// LenientMask accepts masked frames from a server and unmasked frames
+	// from a client.
+	LenientMask
+)
+
+// Strictness profiles.
+const (
+	// Strict enables all the additional checks.
+	Strict = StrictTextUTF8
+
+	// Lenient relaxes all the checks that can be relaxed.
+	Lenient = LenientReservedBits | LenientOpcodes | LenientCloseText | LenientMask
+)
+
+// SetStrictness sets how strictly the connection checks the frames read
+// from the peer.
+func (c *Conn) SetStrictness(s Strictness) {
+	c.strictness = s
+}
+
+var errInvalidUTF8 = &ProtocolError{Kind: ViolationTextUTF8, Message: "invalid utf8 payload in text message"}
+
+// validUTF8Reader fails the connection when a text message is not valid
+// UTF-8. The bytes of an incomplete rune at the end of a read are kept until
+// the next read.
+type validUTF8Reader struct {
+	c   *Conn
+	r   io.ReadCloser
+	buf [utf8.UTFMax]byte
+	n   int // number of bytes in buf
+}
+
+func (r *validUTF8Reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	b := p[:n]
+	valid := true
+	if r.n > 0 {
+		// Complete the pending rune.
+		for len(b) > 0 && !utf8.FullRune(r.buf[:r.n]) {
+			r.buf[r.n] = b[0]
+			r.n++
+			b = b[1:]
+		}
+		if utf8.FullRune(r.buf[:r.n]) {
+			valid = utf8.Valid(r.buf[:r.n])
+			r.n = 0
+		}
+	}
+	if valid && r.n == 0 {
+		// Keep an incomplete rune at the end.
+		i := len(b)
+		for j := len(b) - 1; j >= 0 && j >= len(b)-utf8.UTFMax; j-- {
+			if utf8.RuneStart(b[j]) {
+				if !utf8.FullRune(b[j:]) {
+					i = j
+				}
+				break
+			}
+		}
+		valid = utf8.Valid(b[:i])
+		r.n = copy(r.buf[:], b[i:])
+	}
+	if valid && err == io.EOF && r.n > 0 {
+		valid = false
+	}
+	if !valid {
+		c := r.c
+		_ = c.WriteControl(CloseMessage, FormatCloseMessage(CloseInvalidFramePayloadData, ""), c.clock.Now().Add(writeWait))
+		c.recordProtocolError()
+		c.readErr = c.reportViolation(ViolationTextUTF8, errInvalidUTF8)
+		return 0, c.readErr
+	}
+	return n, err
+}
+
+func (r *validUTF8Reader) Close() error {
+	return r.r.Close()
+}
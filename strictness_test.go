@@ -0,0 +1,103 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestStrictness(t *testing.T) {
+	tests := []struct {
+		name       string
+		strictness Strictness
+		frames     []byte // written by the client, masked with a zero key
+		want       string // message read by the server, or the error
+	}{
+		{"rsv2", 0, []byte{0xa1, 0x81, 0, 0, 0, 0, 'a'}, "websocket: RSV2 set"},
+		{"rsv2 lenient", LenientReservedBits, []byte{0xa1, 0x81, 0, 0, 0, 0, 'a'}, "a"},
+		{"opcode", 0, []byte{0x83, 0x80, 0, 0, 0, 0}, "websocket: bad opcode 3"},
+		{"opcode lenient", LenientOpcodes, []byte{0x83, 0x81, 0, 0, 0, 0, 'x', 0x81, 0x81, 0, 0, 0, 0, 'a'}, "a"},
+		{"opcode lenient in message", LenientOpcodes, []byte{0x01, 0x81, 0, 0, 0, 0, 'a', 0x8b, 0x81, 0, 0, 0, 0, 'x', 0x80, 0x81, 0, 0, 0, 0, 'b'}, "ab"},
+		{"close text", 0, []byte{0x88, 0x83, 0, 0, 0, 0, 0x03, 0xe8, 0xff}, "websocket: invalid utf8 payload in close frame"},
+		{"close text lenient", LenientCloseText, []byte{0x88, 0x83, 0, 0, 0, 0, 0x03, 0xe8, 0xff}, "websocket: close 1000 (normal): \xff"},
+		{"unmasked", 0, []byte{0x81, 0x01, 'a'}, "websocket: bad MASK"},
+		{"unmasked lenient", Lenient, []byte{0x81, 0x01, 'a'}, "a"},
+		{"invalid text", 0, []byte{0x81, 0x81, 0, 0, 0, 0, 0xff}, "\xff"},
+		{"invalid text strict", Strict, []byte{0x81, 0x81, 0, 0, 0, 0, 0xff}, errInvalidUTF8.Error()},
+		{"split rune strict", Strict, []byte{0x01, 0x81, 0, 0, 0, 0, 0xc3, 0x80, 0x81, 0, 0, 0, 0, 0xa9}, "é"},
+		{"truncated rune strict", Strict, []byte{0x81, 0x81, 0, 0, 0, 0, 0xc3}, errInvalidUTF8.Error()},
+		{"binary strict", Strict, []byte{0x82, 0x81, 0, 0, 0, 0, 0xff}, "\xff"},
+	}
+	for _, tt := range tests {
+		client, server := Pipe()
+		server.SetStrictness(tt.strictness)
+		client.NetConn().Write(tt.frames)
+		_, p, err := server.ReadMessage()
+		got := string(p)
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+		client.Close()
+		server.Close()
+	}
+}
+
+func TestStrictTextUTF8Close(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	server.SetStrictness(Strict)
+	var kind ViolationKind
+	server.SetProtocolViolationHandler(func(v ProtocolViolation) { kind = v.Kind })
+	client.WriteMessage(TextMessage, []byte("ok\xff"))
+	if _, _, err := server.ReadMessage(); err != errInvalidUTF8 {
+		t.Fatalf("ReadMessage() error = %v, want %v", err, errInvalidUTF8)
+	}
+	if _, _, err := server.NextReader(); err != errInvalidUTF8 {
+		t.Errorf("NextReader() error = %v, want %v", err, errInvalidUTF8)
+	}
+	if kind != ViolationTextUTF8 {
+		t.Errorf("violation = %q, want %q", kind, ViolationTextUTF8)
+	}
+	if _, _, err := client.ReadMessage(); !IsCloseError(err, CloseInvalidFramePayloadData) {
+		t.Errorf("client ReadMessage() error = %v, want close %d", err, CloseInvalidFramePayloadData)
+	}
+}
+
+func TestValidUTF8Reader(t *testing.T) {
+	tests := []struct {
+		s     string
+		valid bool
+	}{
+		{"", true},
+		{"hello", true},
+		{"héllo wörld 世界 🙂", true},
+		{"\xff", false},
+		{"abc\xe4\xb8", false},
+		{"\xed\xa0\x80", false}, // surrogate half
+		{"🙂\xf0\x9f", false},
+		{"\xc3\xa9\x80", false},
+	}
+	for _, tt := range tests {
+		for _, oneByte := range []bool{false, true} {
+			_, server := Pipe()
+			var r io.Reader = &validUTF8Reader{c: server, r: io.NopCloser(strings.NewReader(tt.s))}
+			if oneByte {
+				r = iotest.OneByteReader(r)
+			}
+			_, err := io.ReadAll(r)
+			if (err == nil) != tt.valid {
+				t.Errorf("%q (one byte %v): error = %v, want valid %v", tt.s, oneByte, err, tt.valid)
+			}
+			server.Close()
+		}
+	}
+}
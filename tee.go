@@ -0,0 +1,93 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import "io"
+
+// tee is a writer set by TeeReads or TeeWrites.
+type tee struct {
+	w io.Writer
+}
+
+// TeeReads copies the payloads of the data messages read from the
+// connection to w as the application reads them, after decompression and
+// the payload transform and inbound middleware, if any. Payloads are
+// copied without delimiters; a message that the application does not read
+// to the end is copied partially. Errors returned by w are ignored. If w is
+// nil, TeeReads stops copying.
+//
+// TeeReads can be called concurrently with the read and write methods. It
+// applies to the readers returned by NextReader after the call, including
+// the readers used by ReadMessage, ReadMessageTo, ReadMessageChunks and
+// ReadJSON. Use TeeReads for compliance recording or debugging without
+// wrapping every read in the application; SetFrameDump shows the frames on
+// the network instead.
+func (c *Conn) TeeReads(w io.Writer) {
+	if w == nil {
+		c.teeReads.Store(nil)
+		return
+	}
+	c.teeReads.Store(&tee{w: w})
+}
+
+// TeeWrites copies the payloads of the data messages written to the
+// connection to w, before compression and the payload transform and
+// outbound middleware, if any. Payloads are copied as the connection
+// accepts them and without delimiters. Errors returned by w are ignored. If
+// w is nil, TeeWrites stops copying.
+//
+// TeeWrites can be called concurrently with the read and write methods. It
+// applies to WriteMessage, WritePreparedMessage and the writers returned by
+// NextWriter after the call, including the writers used by
+// WriteMessageFrom and WriteJSON. The writer passed to both TeeReads and
+// TeeWrites must be safe for concurrent use when the connection is read
+// and written concurrently.
+func (c *Conn) TeeWrites(w io.Writer) {
+	if w == nil {
+		c.teeWrites.Store(nil)
+		return
+	}
+	c.teeWrites.Store(&tee{w: w})
+}
+
+// teeWrite copies the payload of a data message written with WriteMessage
+// or WritePreparedMessage.
+func (c *Conn) teeWrite(messageType int, p []byte) {
+	if t := c.teeWrites.Load(); t != nil && isData(messageType) {
+		_, _ = t.w.Write(p)
+	}
+}
+
+// teeReader copies the payload read by the application.
+type teeReader struct {
+	r io.Reader
+	w io.Writer
+}
+
+func (r *teeReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		_, _ = r.w.Write(p[:n])
+	}
+	return n, err
+}
+
+// teeWriteCloser copies the payload accepted by a message writer.
+type teeWriteCloser struct {
+	w   io.WriteCloser
+	tee io.Writer
+}
+
+func (w *teeWriteCloser) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	if n > 0 {
+		_, _ = w.tee.Write(p[:n])
+	}
+	return n, err
+}
+
+func (w *teeWriteCloser) Close() error {
+	return w.w.Close()
+}
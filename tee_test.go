@@ -0,0 +1,134 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestTeeWrites(t *testing.T) {
+	pm, err := NewPreparedMessage(TextMessage, []byte("prepared"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		write func(c *Conn) error
+	}{
+		{"WriteMessage", func(c *Conn) error {
+			return c.WriteMessage(TextMessage, []byte("hello"))
+		}},
+		{"NextWriter", func(c *Conn) error {
+			w, err := c.NextWriter(BinaryMessage)
+			if err != nil {
+				return err
+			}
+			io.WriteString(w, "hel")
+			io.WriteString(w, "lo")
+			return w.Close()
+		}},
+		{"WriteMessageFrom", func(c *Conn) error {
+			_, err := c.WriteMessageFrom(BinaryMessage, strings.NewReader("hello"))
+			return err
+		}},
+		{"WriteJSON", func(c *Conn) error {
+			return c.WriteJSON("hello")
+		}},
+		{"WritePreparedMessage", func(c *Conn) error {
+			return c.WritePreparedMessage(pm)
+		}},
+		{"ping", func(c *Conn) error {
+			return c.WriteMessage(PingMessage, []byte("ping"))
+		}},
+	}
+	want := map[string]string{
+		"WriteJSON":            "\"hello\"\n",
+		"WritePreparedMessage": "prepared",
+		"ping":                 "",
+	}
+	for _, compress := range []bool{false, true} {
+		for _, tt := range tests {
+			client, server := (&PipeConfig{EnableCompression: compress}).Pipe()
+			client.EnableWriteCompression(compress)
+			var buf bytes.Buffer
+			client.TeeWrites(&buf)
+			if err := tt.write(client); err != nil {
+				t.Fatalf("%s: write error = %v", tt.name, err)
+			}
+			w, ok := want[tt.name]
+			if !ok {
+				w = "hello"
+			}
+			if got := buf.String(); got != w {
+				t.Errorf("%s, compress=%v: tee = %q, want %q", tt.name, compress, got, w)
+			}
+			client.Close()
+			server.Close()
+		}
+	}
+}
+
+func TestTeeReads(t *testing.T) {
+	client, server := (&PipeConfig{EnableCompression: true}).Pipe()
+	defer client.Close()
+	defer server.Close()
+	client.EnableWriteCompression(true)
+	var buf bytes.Buffer
+	server.TeeReads(&buf)
+
+	messages := []string{"first", strings.Repeat("second ", 100), "\"third\"\n", "fourth"}
+	for _, m := range messages {
+		client.WriteMessage(TextMessage, []byte(m))
+	}
+	if _, _, err := server.ReadMessage(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := server.ReadMessageTo(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	var s string
+	if err := server.ReadJSON(&s); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), strings.Join(messages[:3], ""); got != want {
+		t.Errorf("tee = %q, want %q", got, want)
+	}
+
+	server.TeeReads(nil)
+	if _, p, err := server.ReadMessage(); err != nil || string(p) != "fourth" {
+		t.Fatalf("ReadMessage() = %q, %v, want fourth", p, err)
+	}
+	if got, want := buf.String(), strings.Join(messages[:3], ""); got != want {
+		t.Errorf("tee after TeeReads(nil) = %q, want %q", got, want)
+	}
+}
+
+func TestTeeTransform(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	client.SetPayloadTransform(newAEADTransform(t))
+	server.SetPayloadTransform(newAEADTransform(t))
+	var sent, received bytes.Buffer
+	client.TeeWrites(&sent)
+	server.TeeReads(&received)
+
+	client.WriteMessage(TextMessage, []byte("hello, "))
+	w, _ := client.NextWriter(TextMessage)
+	io.WriteString(w, "world")
+	w.Close()
+	for i := 0; i < 2; i++ {
+		if _, _, err := server.ReadMessage(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The tees see the plaintext of the application.
+	if sent.String() != "hello, world" || received.String() != "hello, world" {
+		t.Errorf("tee = %q, %q, want the plaintext", sent.String(), received.String())
+	}
+}
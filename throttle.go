@@ -0,0 +1,146 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Throttle limits the rate of bytes written to one or more connections. A
+// Throttle set on a single connection limits that connection; a Throttle
+// shared by several connections limits their total rate, for example to
+// keep bulk transfers from saturating an uplink.
+//
+// A Throttle is safe for concurrent use. Its limit can be changed while
+// connections are writing.
+type Throttle struct {
+	mu     sync.Mutex
+	bucket tokenBucket
+}
+
+// NewThrottle returns a throttle that limits writes to bytesPerSecond with
+// bursts of up to burst bytes. See SetLimit.
+func NewThrottle(bytesPerSecond float64, burst int) *Throttle {
+	t := &Throttle{}
+	t.SetLimit(bytesPerSecond, burst)
+	return t
+}
+
+// SetLimit sets the rate in bytes per second and the burst size in bytes.
+// If burst is zero, bytesPerSecond rounded up is used. A rate of zero
+// removes the limit.
+func (t *Throttle) SetLimit(bytesPerSecond float64, burst int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := float64(burst)
+	if b <= 0 {
+		b = math.Ceil(bytesPerSecond)
+	}
+	t.bucket.rate = bytesPerSecond
+	t.bucket.burst = b
+	t.bucket.tokens = math.Min(t.bucket.tokens, b)
+}
+
+// Limit returns the rate and burst size set by SetLimit.
+func (t *Throttle) Limit() (bytesPerSecond float64, burst int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.bucket.rate, int(t.bucket.burst)
+}
+
+// chunk returns the maximum number of bytes to write at once, or zero if
+// the throttle is not limited.
+func (t *Throttle) chunk() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.bucket.rate <= 0 {
+		return 0
+	}
+	return int(math.Max(1, t.bucket.burst))
+}
+
+// reserve takes n bytes from the throttle and returns the time to wait
+// before writing them.
+func (t *Throttle) reserve(now time.Time, n int) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := &t.bucket
+	if b.rate <= 0 {
+		return 0
+	}
+	if b.last.IsZero() {
+		b.tokens, b.last = b.burst, now
+	}
+	b.refill(now)
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(-b.tokens / b.rate * float64(time.Second)))
+}
+
+// cancel returns n bytes taken by reserve.
+func (t *Throttle) cancel(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.bucket.tokens = math.Min(t.bucket.burst, t.bucket.tokens+float64(n))
+}
+
+// SetWriteThrottle limits the rate of bytes written to the connection by the
+// given throttles. Frames are written in chunks that wait for every
+// throttle. Control frames written by WriteControl are not throttled. Calling
+// SetWriteThrottle with no arguments removes the throttles.
+//
+// To change the limits at run time, call SetLimit on the throttles.
+func (c *Conn) SetWriteThrottle(throttles ...*Throttle) {
+	var ts []*Throttle
+	for _, t := range throttles {
+		if t != nil {
+			ts = append(ts, t)
+		}
+	}
+	<-c.mu
+	c.writeThrottles = ts
+	c.mu <- struct{}{}
+}
+
+// writeThrottled writes bufs in chunks allowed by the write throttles. The
+// caller holds c.mu.
+func (c *Conn) writeThrottled(deadline time.Time, bufs ...[]byte) error {
+	for _, p := range bufs {
+		for len(p) > 0 {
+			n := len(p)
+			for _, t := range c.writeThrottles {
+				if m := t.chunk(); m > 0 && m < n {
+					n = m
+				}
+			}
+			now := c.clock.Now()
+			var wait time.Duration
+			for _, t := range c.writeThrottles {
+				if d := t.reserve(now, n); d > wait {
+					wait = d
+				}
+			}
+			if wait > 0 {
+				if !deadline.IsZero() && now.Add(wait).After(deadline) {
+					for _, t := range c.writeThrottles {
+						t.cancel(n)
+					}
+					return errWriteTimeout
+				}
+				timer := c.clock.NewTimer(wait)
+				<-timer.C()
+			}
+			if _, err := c.conn.Write(p[:n]); err != nil {
+				return err
+			}
+			p = p[n:]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWriteThrottle(t *testing.T) {
+	clock := newFakeClock()
+	client, server := (&PipeConfig{Clock: clock}).Pipe()
+	defer client.Close()
+	defer server.Close()
+	throttle := NewThrottle(10, 0)
+	server.SetWriteThrottle(throttle)
+
+	// The first frame of 2 header and 8 payload bytes fits in the burst.
+	if err := server.WriteMessage(BinaryMessage, bytes.Repeat([]byte("a"), 8)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next frame waits for the bucket to refill, in two chunks.
+	done := make(chan error, 1)
+	go func() { done <- server.WriteMessage(BinaryMessage, bytes.Repeat([]byte("b"), 18)) }()
+	clock.advance(t, time.Second)
+	clock.advance(t, time.Second-time.Millisecond)
+	select {
+	case err := <-done:
+		t.Fatalf("WriteMessage() returned %v before the throttle allowed", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.advance(t, time.Millisecond)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []int{8, 18} {
+		_, p, err := client.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(p) != want {
+			t.Errorf("len(ReadMessage()) = %d, want %d", len(p), want)
+		}
+	}
+
+	// Control frames are not throttled.
+	if err := server.WriteControl(PingMessage, nil, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A write that cannot complete before the deadline fails.
+	server.SetWriteDeadline(clock.Now().Add(time.Second / 2))
+	if err := server.WriteMessage(BinaryMessage, bytes.Repeat([]byte("c"), 18)); !errors.Is(err, errWriteTimeout) {
+		t.Errorf("WriteMessage() error = %v, want %v", err, errWriteTimeout)
+	}
+}
+
+func TestWriteThrottleShared(t *testing.T) {
+	clock := newFakeClock()
+	throttle := NewThrottle(10, 0)
+	_, server1 := (&PipeConfig{Clock: clock}).Pipe()
+	_, server2 := (&PipeConfig{Clock: clock}).Pipe()
+	server1.SetWriteThrottle(throttle)
+	server2.SetWriteThrottle(throttle)
+
+	if err := server1.WriteMessage(TextMessage, []byte("12345678")); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- server2.WriteMessage(TextMessage, []byte("12345678")) }()
+	clock.advance(t, time.Second)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// Removing the limit lets writes through without waiting.
+	throttle.SetLimit(0, 0)
+	if rate, burst := throttle.Limit(); rate != 0 || burst != 0 {
+		t.Errorf("Limit() = %v, %v, want 0, 0", rate, burst)
+	}
+	for i := 0; i < 3; i++ {
+		if err := server1.WriteMessage(TextMessage, []byte("12345678")); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
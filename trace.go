@@ -0,0 +1,229 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// ConnTrace is a set of hooks to run at stages of the life of a websocket
+// connection. Any particular hook may be nil. Read and write hooks may be
+// called concurrently from the reading and the writing goroutine.
+//
+// The Dialer uses the trace in the context passed to DialContext. The
+// Upgrader uses the trace in the request context, which a middleware can
+// set. The connection keeps the trace of its handshake.
+type ConnTrace struct {
+	// HandshakeStart is called before the opening handshake. On the client,
+	// req is the request to send and the hook may add headers to it. On the
+	// server, req is the request received.
+	HandshakeStart func(req *http.Request)
+
+	// HandshakeDone is called after the opening handshake with the
+	// connection and the response status, or with a nil connection, the
+	// status of the response if any and the error if the handshake failed.
+	HandshakeDone func(c *Conn, status int, err error)
+
+	// MessageRead is called when the application has read a data message
+	// to the end or, if the application stops reading the message early,
+	// when the application gets the next reader.
+	MessageRead func(c *Conn, info MessageInfo)
+
+	// MessageWritten is called after a data message is written.
+	MessageWritten func(c *Conn, info MessageInfo)
+
+	// ProtocolError is called when the peer violates the protocol, after
+	// the close message describing the violation is sent.
+	ProtocolError func(c *Conn, err error)
+
+	// CloseReceived is called when a close message is received from the
+	// peer, before the close handler.
+	CloseReceived func(c *Conn, code int, text string)
+
+	// Closed is called when Close is called on the connection.
+	Closed func(c *Conn)
+
+	// QuirkTriggered is called the first time a connection tolerates a
+	// bug of the peer with one of the quirks enabled by Conn.SetQuirks.
+	QuirkTriggered func(c *Conn, q Quirk)
+}
+
+// MessageInfo describes a message for ConnTrace hooks.
+type MessageInfo struct {
+	// Type is TextMessage or BinaryMessage.
+	Type int
+
+	// Size is the size of the payload in bytes.
+	Size int64
+
+	// WireSize is the size of the payload on the network, after
+	// compression.
+	WireSize int64
+
+	// Compressed reports whether the message was compressed.
+	Compressed bool
+}
+
+type connTraceKey struct{}
+
+// WithConnTrace returns a context based on ctx that runs the hooks in trace.
+// Hooks already in ctx are called after the hooks in trace.
+func WithConnTrace(ctx context.Context, trace *ConnTrace) context.Context {
+	if trace == nil {
+		panic("nil trace")
+	}
+	if old := ContextConnTrace(ctx); old != nil {
+		trace = composeConnTrace(trace, old)
+	}
+	return context.WithValue(ctx, connTraceKey{}, trace)
+}
+
+// ContextConnTrace returns the ConnTrace associated with ctx or nil.
+func ContextConnTrace(ctx context.Context) *ConnTrace {
+	trace, _ := ctx.Value(connTraceKey{}).(*ConnTrace)
+	return trace
+}
+
+func composeConnTrace(t, old *ConnTrace) *ConnTrace {
+	c := *t
+	if old.HandshakeStart != nil {
+		if f := t.HandshakeStart; f != nil {
+			c.HandshakeStart = func(req *http.Request) { f(req); old.HandshakeStart(req) }
+		} else {
+			c.HandshakeStart = old.HandshakeStart
+		}
+	}
+	if old.HandshakeDone != nil {
+		if f := t.HandshakeDone; f != nil {
+			c.HandshakeDone = func(conn *Conn, status int, err error) { f(conn, status, err); old.HandshakeDone(conn, status, err) }
+		} else {
+			c.HandshakeDone = old.HandshakeDone
+		}
+	}
+	if old.MessageRead != nil {
+		if f := t.MessageRead; f != nil {
+			c.MessageRead = func(conn *Conn, info MessageInfo) { f(conn, info); old.MessageRead(conn, info) }
+		} else {
+			c.MessageRead = old.MessageRead
+		}
+	}
+	if old.MessageWritten != nil {
+		if f := t.MessageWritten; f != nil {
+			c.MessageWritten = func(conn *Conn, info MessageInfo) { f(conn, info); old.MessageWritten(conn, info) }
+		} else {
+			c.MessageWritten = old.MessageWritten
+		}
+	}
+	if old.ProtocolError != nil {
+		if f := t.ProtocolError; f != nil {
+			c.ProtocolError = func(conn *Conn, err error) { f(conn, err); old.ProtocolError(conn, err) }
+		} else {
+			c.ProtocolError = old.ProtocolError
+		}
+	}
+	if old.CloseReceived != nil {
+		if f := t.CloseReceived; f != nil {
+			c.CloseReceived = func(conn *Conn, code int, text string) { f(conn, code, text); old.CloseReceived(conn, code, text) }
+		} else {
+			c.CloseReceived = old.CloseReceived
+		}
+	}
+	if old.Closed != nil {
+		if f := t.Closed; f != nil {
+			c.Closed = func(conn *Conn) { f(conn); old.Closed(conn) }
+		} else {
+			c.Closed = old.Closed
+		}
+	}
+	if old.QuirkTriggered != nil {
+		if f := t.QuirkTriggered; f != nil {
+			c.QuirkTriggered = func(conn *Conn, q Quirk) { f(conn, q); old.QuirkTriggered(conn, q) }
+		} else {
+			c.QuirkTriggered = old.QuirkTriggered
+		}
+	}
+	return &c
+}
+
+// tracedReader reports a message to the MessageRead hook and the observer
+// when the application reads it to the end or, if the application stops
+// reading early, when the application gets the next reader.
+type tracedReader struct {
+	c    *Conn
+	mr   *messageReader
+	r    io.Reader
+	info MessageInfo
+	done bool
+}
+
+func (r *tracedReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.info.Size += int64(n)
+	c := r.c
+	switch {
+	case err == io.EOF:
+		r.finish()
+	case !r.info.Compressed && c.messageReader == r.mr && c.readFinal && c.readRemaining == 0:
+		// Report uncompressed messages as soon as the payload is read,
+		// for readers such as json.Decoder that do not read to EOF.
+		r.finish()
+	}
+	return n, err
+}
+
+func (r *tracedReader) finish() {
+	if r.done {
+		return
+	}
+	r.done = true
+	c := r.c
+	r.info.WireSize = c.readLength
+	if c.trace != nil && c.trace.MessageRead != nil {
+		c.trace.MessageRead(c, r.info)
+	}
+	if c.observer != nil {
+		c.observer.OnMessage(c, r.info.Type, r.info.Size)
+	}
+}
+
+// tracedWriter reports a message to the MessageWritten hook when the
+// application closes the writer.
+type tracedWriter struct {
+	w    io.WriteCloser
+	mw   *messageWriter
+	info MessageInfo
+}
+
+func (w *tracedWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.info.Size += int64(n)
+	return n, err
+}
+
+func (w *tracedWriter) Close() error {
+	err := w.w.Close()
+	if err == nil {
+		w.info.WireSize = w.mw.wireSize
+		w.info.Compressed = w.mw.compressed
+		w.mw.c.trace.MessageWritten(w.mw.c, w.info)
+	}
+	return err
+}
+
+// framesPayloadLen returns the total payload length of the well-formed frames
+// in p.
+func framesPayloadLen(p []byte) int64 {
+	var n int64
+	for {
+		hdr, length, ok := parseFrameHeader(p)
+		if !ok {
+			return n
+		}
+		n += length
+		p = p[int64(hdr)+length:]
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// traceLog records the calls to the hooks of a ConnTrace.
+type traceLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *traceLog) add(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, fmt.Sprintf(format, args...))
+}
+
+func (l *traceLog) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.events...)
+}
+
+func (l *traceLog) trace() *ConnTrace {
+	return &ConnTrace{
+		HandshakeStart: func(req *http.Request) {
+			l.add("start %s", req.Header.Get("Trace-Id"))
+		},
+		HandshakeDone: func(c *Conn, status int, err error) {
+			l.add("done %t %d %v", c != nil, status, err)
+		},
+		MessageRead: func(c *Conn, info MessageInfo) {
+			l.add("read %d %d %t", info.Type, info.Size, info.Compressed)
+		},
+		MessageWritten: func(c *Conn, info MessageInfo) {
+			l.add("written %d %d %t", info.Type, info.Size, info.Compressed)
+		},
+		Closed: func(c *Conn) {
+			l.add("closed")
+		},
+	}
+}
+
+func TestConnTrace(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		var serverLog, clientLog traceLog
+		done := make(chan struct{})
+		upgrader := Upgrader{EnableCompression: compress}
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer close(done)
+			r = r.WithContext(WithConnTrace(r.Context(), serverLog.trace()))
+			c, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer c.Close()
+			mt, p, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			c.WriteMessage(mt, p)
+		}))
+
+		ctx := WithConnTrace(context.Background(), clientLog.trace())
+		ctx = WithConnTrace(ctx, &ConnTrace{
+			HandshakeStart: func(req *http.Request) {
+				req.Header.Set("Trace-Id", "abc")
+			},
+		})
+		d := Dialer{EnableCompression: compress}
+		c, _, err := d.DialContext(ctx, makeWsProto(s.URL), nil)
+		if err != nil {
+			t.Fatalf("compress=%t: Dial() error = %v", compress, err)
+		}
+		if err := c.WriteMessage(TextMessage, []byte("hello")); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := c.ReadMessage(); err != nil {
+			t.Fatal(err)
+		}
+		c.Close()
+		<-done
+		s.Close()
+
+		wantServer := []string{
+			"start abc",
+			"done true 101 <nil>",
+			fmt.Sprintf("read 1 5 %t", compress),
+			fmt.Sprintf("written 1 5 %t", compress),
+			"closed",
+		}
+		if got := serverLog.get(); !reflect.DeepEqual(got, wantServer) {
+			t.Errorf("compress=%t: server events = %q, want %q", compress, got, wantServer)
+		}
+		wantClient := []string{
+			"start abc",
+			"done true 101 <nil>",
+			fmt.Sprintf("written 1 5 %t", compress),
+			fmt.Sprintf("read 1 5 %t", compress),
+			"closed",
+		}
+		if got := clientLog.get(); !reflect.DeepEqual(got, wantClient) {
+			t.Errorf("compress=%t: client events = %q, want %q", compress, got, wantClient)
+		}
+	}
+}
+
+func TestConnTraceHandshakeError(t *testing.T) {
+	var serverLog, clientLog traceLog
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(WithConnTrace(r.Context(), serverLog.trace()))
+		var upgrader Upgrader
+		upgrader.Upgrade(w, r, nil)
+	}))
+	defer s.Close()
+
+	ctx := WithConnTrace(context.Background(), clientLog.trace())
+	_, _, err := DefaultDialer.DialContext(ctx, makeWsProto(s.URL), http.Header{"Origin": {"http://other.example"}})
+	if !errors.Is(err, ErrBadHandshake) {
+		t.Fatalf("Dial() error = %v, want %v", err, ErrBadHandshake)
+	}
+	if got := clientLog.get(); len(got) != 2 || got[1] != "done false 403 "+ErrBadHandshake.Error() {
+		t.Errorf("client events = %q", got)
+	}
+	if got := serverLog.get(); len(got) != 2 || !strings.HasPrefix(got[1], "done false 403 websocket: request origin not allowed") {
+		t.Errorf("server events = %q", got)
+	}
+}
+
+func TestConnTraceMessages(t *testing.T) {
+	tests := []struct {
+		name     string
+		compress bool
+		write    func(c *Conn, p []byte) error
+	}{
+		{"WriteMessage", false, func(c *Conn, p []byte) error {
+			return c.WriteMessage(BinaryMessage, p)
+		}},
+		{"NextWriter", true, func(c *Conn, p []byte) error {
+			w, err := c.NextWriter(BinaryMessage)
+			if err != nil {
+				return err
+			}
+			w.Write(p[:10])
+			w.Write(p[10:])
+			return w.Close()
+		}},
+		{"WritePreparedMessage", false, func(c *Conn, p []byte) error {
+			pm, err := NewPreparedMessage(BinaryMessage, p)
+			if err != nil {
+				return err
+			}
+			return c.WritePreparedMessage(pm)
+		}},
+		{"WritePreparedMessage compressed", true, func(c *Conn, p []byte) error {
+			pm, err := NewPreparedMessage(BinaryMessage, p)
+			if err != nil {
+				return err
+			}
+			return c.WritePreparedMessage(pm)
+		}},
+	}
+	p := bytes.Repeat([]byte("abcd"), 10000)
+	for _, tt := range tests {
+		client, server := (&PipeConfig{EnableCompression: true}).Pipe()
+		client.EnableWriteCompression(tt.compress)
+		server.EnableWriteCompression(tt.compress)
+		var written, read []MessageInfo
+		for _, c := range []*Conn{client, server} {
+			c.trace = &ConnTrace{
+				MessageWritten: func(c *Conn, info MessageInfo) { written = append(written, info) },
+				MessageRead:    func(c *Conn, info MessageInfo) { read = append(read, info) },
+			}
+		}
+		for _, c := range [][2]*Conn{{client, server}, {server, client}} {
+			if err := tt.write(c[0], p); err != nil {
+				t.Fatalf("%s: write error = %v", tt.name, err)
+			}
+			if err := c[0].WriteControl(PingMessage, nil, time.Time{}); err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := c[1].ReadMessage(); err != nil {
+				t.Fatalf("%s: ReadMessage() error = %v", tt.name, err)
+			}
+		}
+		if len(written) != 2 || len(read) != 2 {
+			t.Fatalf("%s: got %d written and %d read, want 2 each", tt.name, len(written), len(read))
+		}
+		for i := range written {
+			w, r := written[i], read[i]
+			if w.Size != int64(len(p)) || r.Size != w.Size {
+				t.Errorf("%s: size written %d, read %d, want %d", tt.name, w.Size, r.Size, len(p))
+			}
+			if w.WireSize != r.WireSize || w.Compressed != tt.compress || r.Compressed != tt.compress {
+				t.Errorf("%s: written %+v, read %+v", tt.name, w, r)
+			}
+			if tt.compress != (w.WireSize < w.Size) {
+				t.Errorf("%s: wire size %d for size %d", tt.name, w.WireSize, w.Size)
+			}
+		}
+		client.Close()
+		server.Close()
+	}
+}
+
+func TestWithConnTraceCompose(t *testing.T) {
+	var got []string
+	hook := func(name string) *ConnTrace {
+		return &ConnTrace{Closed: func(c *Conn) { got = append(got, name) }}
+	}
+	ctx := WithConnTrace(context.Background(), hook("first"))
+	ctx = WithConnTrace(ctx, &ConnTrace{})
+	ctx = WithConnTrace(ctx, hook("second"))
+	ContextConnTrace(ctx).Closed(nil)
+	if want := []string{"second", "first"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("hooks called %q, want %q", got, want)
+	}
+	if ContextConnTrace(context.Background()) != nil {
+		t.Error("ContextConnTrace(background) != nil")
+	}
+}
+
+func TestConnTraceCloseReceived(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	var got []string
+	server.trace = &ConnTrace{CloseReceived: func(c *Conn, code int, text string) {
+		got = append(got, fmt.Sprintf("%d %s", code, text))
+	}}
+	if err := client.WriteMessage(CloseMessage, FormatCloseMessage(CloseGoingAway, "bye")); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := server.ReadMessage(); !IsCloseError(err, CloseGoingAway) {
+		t.Fatalf("ReadMessage() error = %v, want close error", err)
+	}
+	if want := []string{"1001 bye"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CloseReceived calls = %q, want %q", got, want)
+	}
+}
+
+func TestConnTraceProtocolError(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	var got []error
+	server.trace = &ConnTrace{ProtocolError: func(c *Conn, err error) {
+		got = append(got, err)
+	}}
+	// A masked frame with the reserved opcode 3.
+	if _, err := client.NetConn().Write([]byte{0x83, 0x80, 0, 0, 0, 0}); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err := server.ReadMessage()
+	if err == nil || len(got) != 1 || got[0] != err {
+		t.Fatalf("ReadMessage() error = %v, ProtocolError calls = %v", err, got)
+	}
+	if _, _, err := client.ReadMessage(); !IsCloseError(err, CloseProtocolError) {
+		t.Errorf("client ReadMessage() error = %v, want protocol error close", err)
+	}
+}
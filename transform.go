@@ -0,0 +1,145 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var errPreparedTransform = errors.New("websocket: prepared messages cannot be written with a payload transform or outbound middleware")
+
+// PayloadTransform transforms the payloads of data messages, for example to
+// encrypt and authenticate them end to end with an AEAD cipher. Outbound
+// and Inbound must be inverses of each other. Control messages are not
+// transformed.
+//
+// Transforms see whole messages: the payload written with NextWriter is
+// buffered until the writer is closed, and the payload of a received
+// message is read completely, subject to the read limit, before
+// NextReader returns.
+//
+// Per-message compression applies to the transformed payloads. Encrypted
+// payloads do not compress, so disable compression with
+// EnableWriteCompression(false) when the transform encrypts.
+type PayloadTransform interface {
+	// Outbound returns the payload to send for the payload p of a message
+	// written by the application. The transform must not retain p.
+	Outbound(messageType int, p []byte) ([]byte, error)
+
+	// Inbound returns the payload to return to the application for the
+	// payload p of a received message. An error fails the connection: the
+	// connection sends a close message with CloseInvalidFramePayloadData
+	// and NextReader returns the error.
+	Inbound(messageType int, p []byte) ([]byte, error)
+}
+
+// SetPayloadTransform sets the transform for the payloads of the data
+// messages written and read on the connection. A nil transform removes the
+// transform. Prepared messages cannot be written while a transform is set.
+func (c *Conn) SetPayloadTransform(t PayloadTransform) {
+	c.transform = t
+}
+
+// transformInbound reads a message and returns a reader of the payload
+// returned by the transform and the inbound middleware. transformInbound
+// returns errMessageDropped if the middleware dropped the message.
+func (c *Conn) transformInbound(messageType int, r io.Reader) (io.Reader, error) {
+	p, err := readAllMax(r, maxInt)
+	if err != nil {
+		return nil, err
+	}
+	if c.transform != nil {
+		p, err = c.transform.Inbound(messageType, p)
+		if err != nil {
+			_ = c.WriteControl(CloseMessage, FormatCloseMessage(CloseInvalidFramePayloadData, ""), c.clock.Now().Add(writeWait))
+			return nil, err
+		}
+	}
+	if len(c.inbound) > 0 {
+		var ok bool
+		p, ok, err = runMiddleware(c.inbound, messageType, p)
+		if err != nil {
+			code, text := CloseInternalServerErr, ""
+			var ce *CloseError
+			if errors.As(err, &ce) {
+				code, text = ce.Code, ce.Text
+			}
+			_ = c.WriteControl(CloseMessage, FormatCloseMessage(code, text), c.clock.Now().Add(writeWait))
+			return nil, fmt.Errorf("websocket: inbound middleware: %w", err)
+		}
+		if !ok {
+			return nil, errMessageDropped
+		}
+	}
+	return bytes.NewReader(p), nil
+}
+
+// transformOutbound returns the payload to send for the payload p of a
+// message written by the application. ok is false if the outbound
+// middleware dropped the message.
+func (c *Conn) transformOutbound(messageType int, p []byte) (q []byte, ok bool, err error) {
+	if len(c.outbound) > 0 {
+		if p, ok, err = runMiddleware(c.outbound, messageType, p); !ok || err != nil {
+			return nil, false, err
+		}
+	}
+	if c.transform != nil {
+		if p, err = c.transform.Outbound(messageType, p); err != nil {
+			return nil, false, err
+		}
+	}
+	return p, true, nil
+}
+
+func (c *Conn) nextTransformWriter(messageType int) (io.WriteCloser, error) {
+	if c.writer != nil {
+		c.writer.Close()
+		c.writer = nil
+	}
+	c.writeErrMu.Lock()
+	err := c.writeErr
+	c.writeErrMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	w := &transformWriter{c: c, messageType: messageType}
+	c.writer = w
+	return w, nil
+}
+
+// transformWriter buffers a message and writes the transformed payload when
+// closed.
+type transformWriter struct {
+	c           *Conn
+	messageType int
+	buf         bytes.Buffer
+	closed      bool
+}
+
+func (w *transformWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errWriteClosed
+	}
+	return w.buf.Write(p)
+}
+
+func (w *transformWriter) Close() error {
+	if w.closed {
+		return errWriteClosed
+	}
+	w.closed = true
+	c := w.c
+	if c.writer == w {
+		c.writer = nil
+	}
+	p, ok, err := c.transformOutbound(w.messageType, w.buf.Bytes())
+	if !ok || err != nil {
+		return err
+	}
+	return c.writeMessage(w.messageType, p)
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+// aeadTransform encrypts payloads with AES-GCM and a counter nonce.
+type aeadTransform struct {
+	aead cipher.AEAD
+	seq  uint64
+}
+
+func newAEADTransform(t *testing.T) *aeadTransform {
+	block, err := aes.NewCipher(make([]byte, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &aeadTransform{aead: aead}
+}
+
+func (a *aeadTransform) Outbound(messageType int, p []byte) ([]byte, error) {
+	nonce := make([]byte, a.aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce, a.seq)
+	a.seq++
+	return a.aead.Seal(nonce, nonce, p, []byte{byte(messageType)}), nil
+}
+
+func (a *aeadTransform) Inbound(messageType int, p []byte) ([]byte, error) {
+	n := a.aead.NonceSize()
+	if len(p) < n {
+		return nil, errors.New("short message")
+	}
+	return a.aead.Open(nil, p[:n], p[n:], []byte{byte(messageType)})
+}
+
+func TestPayloadTransform(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		client, server := (&PipeConfig{EnableCompression: compress}).Pipe()
+		client.SetPayloadTransform(newAEADTransform(t))
+		server.SetPayloadTransform(newAEADTransform(t))
+
+		if err := client.WriteMessage(TextMessage, []byte("hello")); err != nil {
+			t.Fatal(err)
+		}
+		w, err := server.NextWriter(BinaryMessage)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, "wor")
+		io.WriteString(w, "ld")
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte("x")); err != errWriteClosed {
+			t.Errorf("Write() after Close() error = %v, want %v", err, errWriteClosed)
+		}
+
+		for _, tt := range []struct {
+			c    *Conn
+			mt   int
+			want string
+		}{{server, TextMessage, "hello"}, {client, BinaryMessage, "world"}} {
+			mt, p, err := tt.c.ReadMessage()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mt != tt.mt || string(p) != tt.want {
+				t.Errorf("compress %v: ReadMessage() = %d, %q, want %d, %q", compress, mt, p, tt.mt, tt.want)
+			}
+		}
+
+		pm, err := NewPreparedMessage(TextMessage, []byte("x"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := client.WritePreparedMessage(pm); err != errPreparedTransform {
+			t.Errorf("WritePreparedMessage() error = %v, want %v", err, errPreparedTransform)
+		}
+		client.Close()
+		server.Close()
+	}
+}
+
+func TestPayloadTransformWire(t *testing.T) {
+	client, server := Pipe()
+	defer client.Close()
+	defer server.Close()
+	client.SetPayloadTransform(newAEADTransform(t))
+	server.SetPayloadTransform(newAEADTransform(t))
+
+	// The peer without the transform sees ciphertext.
+	server.SetPayloadTransform(nil)
+	client.WriteMessage(TextMessage, []byte("secret"))
+	_, p, err := server.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(p, []byte("secret")) {
+		t.Errorf("payload %q contains the plaintext", p)
+	}
+
+	// A message that fails authentication fails the connection.
+	server.SetPayloadTransform(newAEADTransform(t))
+	client.SetPayloadTransform(nil)
+	client.WriteMessage(TextMessage, []byte("forged"))
+	if _, _, err := server.ReadMessage(); err == nil {
+		t.Fatal("ReadMessage() returned no error for a forged message")
+	}
+	if _, _, err := client.ReadMessage(); !IsCloseError(err, CloseInvalidFramePayloadData) {
+		t.Errorf("client ReadMessage() error = %v, want close %d", err, CloseInvalidFramePayloadData)
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package transport
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// LongPoll is a receive-only transport using HTTP long-polling.
+//
+// A client starts a session with a request that has no session query
+// parameter. Each response is a JSON object:
+//
+//	{"session": "...", "messages": [{"type": "text", "data": "..."}], "closed": false}
+//
+// The client polls again with the session ID in the session query
+// parameter. Binary message data is base64 encoded. A response with closed
+// set to true is the last response for the session.
+//
+// Messages are delivered at most once: messages in a response that fails to
+// reach the client are lost.
+type LongPoll struct {
+	server *Server
+	id     string
+
+	mu         sync.Mutex
+	queue      []pollMessage
+	closed     bool
+	polling    bool
+	generation int
+	timer      *time.Timer
+	ready      chan struct{}
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+type pollMessage struct {
+	Type string `json:"type"`
+	Data string `json:"data"`
+}
+
+type pollResponse struct {
+	Session  string        `json:"session"`
+	Messages []pollMessage `json:"messages"`
+	Closed   bool          `json:"closed"`
+}
+
+// ID returns the session ID.
+func (t *LongPoll) ID() string {
+	return t.id
+}
+
+// ReadMessage blocks until the session is closed and then returns
+// ErrClosed.
+func (t *LongPoll) ReadMessage() (messageType int, p []byte, err error) {
+	<-t.done
+	return 0, nil, ErrClosed
+}
+
+// WriteMessage queues a message for the next poll.
+func (t *LongPoll) WriteMessage(messageType int, data []byte) error {
+	var m pollMessage
+	switch messageType {
+	case websocket.TextMessage:
+		m = pollMessage{Type: "text", Data: string(data)}
+	case websocket.BinaryMessage:
+		m = pollMessage{Type: "binary", Data: base64.StdEncoding.EncodeToString(data)}
+	default:
+		return errMessageType
+	}
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return ErrClosed
+	}
+	t.queue = append(t.queue, m)
+	t.mu.Unlock()
+	t.signal()
+	return nil
+}
+
+// Close closes the session. Queued messages are delivered to the next poll
+// before the client is told that the session is closed.
+func (t *LongPoll) Close() error {
+	t.closeOnce.Do(func() {
+		t.mu.Lock()
+		t.closed = true
+		t.mu.Unlock()
+		close(t.done)
+		t.signal()
+	})
+	return nil
+}
+
+func (t *LongPoll) signal() {
+	select {
+	case t.ready <- struct{}{}:
+	default:
+	}
+}
+
+// expireAfter closes and unregisters the session if no poll arrives
+// within d. The caller must hold t.mu.
+func (t *LongPoll) expireAfter(d time.Duration) {
+	t.generation++
+	gen := t.generation
+	t.timer = time.AfterFunc(d, func() {
+		t.mu.Lock()
+		expired := t.generation == gen
+		t.mu.Unlock()
+		if expired {
+			t.Close()
+			t.server.removeSession(t)
+		}
+	})
+}
+
+func newSessionID() (string, error) {
+	p := make([]byte, 16)
+	if _, err := rand.Read(p); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(p), nil
+}
+
+func (s *Server) newSession() (*LongPoll, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+	t := &LongPoll{
+		server:  s,
+		id:      id,
+		polling: true,
+		ready:   make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	s.mu.Lock()
+	if s.sessions == nil {
+		s.sessions = make(map[string]*LongPoll)
+	}
+	s.sessions[id] = t
+	s.mu.Unlock()
+	go func() {
+		defer t.Close()
+		s.handle(t)
+	}()
+	return t, nil
+}
+
+func (s *Server) removeSession(t *LongPoll) {
+	s.mu.Lock()
+	if s.sessions[t.id] == t {
+		delete(s.sessions, t.id)
+	}
+	s.mu.Unlock()
+}
+
+func (s *Server) servePoll(w http.ResponseWriter, r *http.Request) {
+	var t *LongPoll
+	if id := r.URL.Query().Get("session"); id == "" {
+		var err error
+		if t, err = s.newSession(); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	} else {
+		s.mu.Lock()
+		t = s.sessions[id]
+		s.mu.Unlock()
+		if t == nil {
+			http.Error(w, "transport: unknown session", http.StatusNotFound)
+			return
+		}
+		t.mu.Lock()
+		if t.polling {
+			t.mu.Unlock()
+			http.Error(w, "transport: session is already polling", http.StatusConflict)
+			return
+		}
+		t.polling = true
+		t.generation++
+		t.timer.Stop()
+		t.mu.Unlock()
+	}
+
+	timer := time.NewTimer(durationOrDefault(s.PollTimeout, defaultPollTimeout))
+	defer timer.Stop()
+	resp := pollResponse{Session: t.id, Messages: []pollMessage{}}
+	for {
+		t.mu.Lock()
+		if len(t.queue) > 0 || t.closed {
+			resp.Messages = append(resp.Messages, t.queue...)
+			resp.Closed = t.closed
+			t.queue = nil
+			t.mu.Unlock()
+			break
+		}
+		t.mu.Unlock()
+		select {
+		case <-t.ready:
+			continue
+		case <-timer.C:
+		case <-r.Context().Done():
+		}
+		break
+	}
+
+	t.mu.Lock()
+	t.polling = false
+	if resp.Closed {
+		t.generation++
+	} else {
+		t.expireAfter(durationOrDefault(s.SessionTimeout, defaultSessionTimeout))
+	}
+	t.mu.Unlock()
+	if resp.Closed {
+		s.removeSession(t)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(&resp)
+}
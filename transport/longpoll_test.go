@@ -0,0 +1,110 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func poll(t *testing.T, url, session string) (int, *pollResponse) {
+	if session != "" {
+		url += "?session=" + session
+	}
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	var pr pollResponse
+	if err := json.NewDecoder(resp.Body).Decode(&pr); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, &pr
+}
+
+func TestLongPoll(t *testing.T) {
+	next := make(chan string)
+	url := newTestServer(t, &Server{
+		PollTimeout: 50 * time.Millisecond,
+		Handler: func(t Transport) {
+			t.WriteMessage(websocket.BinaryMessage, []byte{0, 1, 2})
+			for m := range next {
+				t.WriteMessage(websocket.TextMessage, []byte(m))
+			}
+		},
+	})
+
+	_, pr := poll(t, url, "")
+	if pr.Session == "" || pr.Closed || len(pr.Messages) != 1 || pr.Messages[0] != (pollMessage{"binary", "AAEC"}) {
+		t.Fatalf("first poll = %+v", pr)
+	}
+	session := pr.Session
+
+	// A poll without messages times out.
+	if _, pr := poll(t, url, session); len(pr.Messages) != 0 || pr.Closed {
+		t.Fatalf("idle poll = %+v", pr)
+	}
+
+	go func() { next <- "hello" }()
+	if _, pr := poll(t, url, session); len(pr.Messages) != 1 || pr.Messages[0] != (pollMessage{"text", "hello"}) {
+		t.Fatalf("poll = %+v", pr)
+	}
+
+	close(next)
+	if _, pr := poll(t, url, session); !pr.Closed {
+		t.Fatalf("poll after close = %+v", pr)
+	}
+	if status, _ := poll(t, url, session); status != http.StatusNotFound {
+		t.Errorf("poll closed session: status %d, want %d", status, http.StatusNotFound)
+	}
+}
+
+func TestLongPollConflict(t *testing.T) {
+	url := newTestServer(t, &Server{PollTimeout: time.Second, Handler: greeter("x")})
+	_, pr := poll(t, url, "")
+
+	done := make(chan struct{})
+	go func() {
+		poll(t, url, pr.Session)
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if status, _ := poll(t, url, pr.Session); status != http.StatusConflict {
+		t.Errorf("concurrent poll: status %d, want %d", status, http.StatusConflict)
+	}
+	<-done
+}
+
+func TestLongPollSessionTimeout(t *testing.T) {
+	closed := make(chan error, 1)
+	url := newTestServer(t, &Server{
+		SessionTimeout: 10 * time.Millisecond,
+		Handler: func(t Transport) {
+			t.WriteMessage(websocket.TextMessage, []byte("x"))
+			_, _, err := t.ReadMessage()
+			closed <- err
+		},
+	})
+	_, pr := poll(t, url, "")
+	select {
+	case err := <-closed:
+		if err != ErrClosed {
+			t.Errorf("ReadMessage() error = %v, want %v", err, ErrClosed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("session did not expire")
+	}
+	if status, _ := poll(t, url, pr.Session); status != http.StatusNotFound {
+		t.Errorf("poll expired session: status %d, want %d", status, http.StatusNotFound)
+	}
+}
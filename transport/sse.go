@@ -0,0 +1,123 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package transport
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// SSE is a receive-only transport using Server-Sent Events.
+//
+// Text messages are sent as events with the default type. Binary messages
+// are sent base64 encoded in events with the type "binary".
+type SSE struct {
+	w         http.ResponseWriter
+	rc        *http.ResponseController
+	r         *http.Request
+	keepAlive time.Duration
+
+	mu sync.Mutex
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewSSE starts an event stream response. NewSSE must be called from the
+// handler goroutine for the request; the stream ends when the handler
+// returns.
+func NewSSE(w http.ResponseWriter, r *http.Request) (*SSE, error) {
+	t := &SSE{w: w, rc: http.NewResponseController(w), r: r, done: make(chan struct{})}
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if err := t.rc.Flush(); err != nil {
+		return nil, errors.New("transport: response does not support flushing")
+	}
+	return t, nil
+}
+
+// ReadMessage blocks until the client disconnects or the transport is
+// closed and then returns ErrClosed.
+func (t *SSE) ReadMessage() (messageType int, p []byte, err error) {
+	select {
+	case <-t.done:
+	case <-t.r.Context().Done():
+	}
+	return 0, nil, ErrClosed
+}
+
+// WriteMessage sends a message event to the client.
+func (t *SSE) WriteMessage(messageType int, data []byte) error {
+	var b []byte
+	switch messageType {
+	case websocket.TextMessage:
+		for _, line := range strings.Split(string(data), "\n") {
+			b = append(b, "data: "...)
+			b = append(b, strings.TrimSuffix(line, "\r")...)
+			b = append(b, '\n')
+		}
+	case websocket.BinaryMessage:
+		b = append(b, "event: binary\ndata: "...)
+		b = append(b, base64.StdEncoding.EncodeToString(data)...)
+		b = append(b, '\n')
+	default:
+		return errMessageType
+	}
+	b = append(b, '\n')
+	return t.write(b)
+}
+
+func (t *SSE) write(p []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	select {
+	case <-t.done:
+		return ErrClosed
+	default:
+	}
+	t.rc.SetWriteDeadline(time.Now().Add(writeWait))
+	if _, err := t.w.Write(p); err != nil {
+		return err
+	}
+	return t.rc.Flush()
+}
+
+var keepAliveComment = []byte(":\n\n")
+
+func (t *SSE) keepAliveLoop() {
+	ticker := time.NewTicker(t.keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := t.write(keepAliveComment); err != nil {
+				return
+			}
+		case <-t.done:
+			return
+		case <-t.r.Context().Done():
+			return
+		}
+	}
+}
+
+// Close ends the stream. Close does not return until pending writes
+// complete.
+func (t *SSE) Close() error {
+	t.closeOnce.Do(func() {
+		t.mu.Lock()
+		close(t.done)
+		t.mu.Unlock()
+	})
+	return nil
+}
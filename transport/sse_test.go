@@ -0,0 +1,83 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package transport
+
+import (
+	"bufio"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestSSE(t *testing.T) {
+	url := newTestServer(t, &Server{
+		KeepAlive: 20 * time.Millisecond,
+		Handler: func(t Transport) {
+			t.WriteMessage(websocket.TextMessage, []byte("one"))
+			t.WriteMessage(websocket.TextMessage, []byte("two\r\nlines"))
+			t.WriteMessage(websocket.BinaryMessage, []byte{0, 1, 2})
+			t.ReadMessage()
+		},
+	})
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	br := bufio.NewReader(resp.Body)
+	var events []string
+	for len(events) < 4 {
+		var lines []string
+		for {
+			line, err := br.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			if line == "\n" {
+				break
+			}
+			lines = append(lines, strings.TrimSuffix(line, "\n"))
+		}
+		events = append(events, strings.Join(lines, "|"))
+	}
+	want := []string{
+		"data: one",
+		"data: two|data: lines",
+		"event: binary|data: AAEC",
+		":",
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event %d = %q, want %q", i, events[i], want[i])
+		}
+	}
+}
+
+func TestSSEWriteAfterClose(t *testing.T) {
+	errc := make(chan error, 1)
+	url := newTestServer(t, &Server{Handler: func(t Transport) {
+		t.Close()
+		errc <- t.WriteMessage(websocket.TextMessage, []byte("x"))
+	}})
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if err := <-errc; err != ErrClosed {
+		t.Errorf("WriteMessage() error = %v, want %v", err, ErrClosed)
+	}
+}
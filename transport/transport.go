@@ -0,0 +1,149 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package transport abstracts the connection to a client so that
+// applications work with clients that cannot use WebSocket.
+//
+// The websocket Conn is the primary implementation of the Transport
+// interface. The Server type also accepts receive-only clients using
+// Server-Sent Events or HTTP long-polling and presents them as a Transport:
+//
+//	s := &transport.Server{
+//		Handler: func(t transport.Transport) {
+//			for n := range updates {
+//				if err := t.WriteMessage(websocket.TextMessage, n); err != nil {
+//					return
+//				}
+//			}
+//		},
+//	}
+//	http.Handle("/updates", s)
+//
+// On the receive-only transports, ReadMessage blocks until the client goes
+// away or the transport is closed.
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Transport is a message oriented connection to a client.
+type Transport interface {
+	// ReadMessage reads the next message from the client.
+	ReadMessage() (messageType int, p []byte, err error)
+
+	// WriteMessage writes a message to the client. The messageType is
+	// websocket.TextMessage or websocket.BinaryMessage.
+	WriteMessage(messageType int, data []byte) error
+
+	// Close closes the transport.
+	Close() error
+}
+
+var _ Transport = (*websocket.Conn)(nil)
+
+var (
+	// ErrClosed is returned when using a closed transport.
+	ErrClosed = errors.New("transport: closed")
+
+	errMessageType = errors.New("transport: unsupported message type")
+)
+
+const (
+	defaultKeepAlive      = 15 * time.Second
+	defaultPollTimeout    = 30 * time.Second
+	defaultSessionTimeout = 60 * time.Second
+	writeWait             = 10 * time.Second
+)
+
+// Server is an http.Handler that accepts clients on the websocket, SSE or
+// long-polling transport and calls Handler with the resulting Transport.
+//
+// Requests with a websocket upgrade use the websocket transport. Requests
+// that accept text/event-stream use SSE. All other requests are long-polling
+// requests. The Server must not be copied after first use.
+type Server struct {
+	// Handler is called for each client. The transport is closed when
+	// Handler returns.
+	Handler func(t Transport)
+
+	// Upgrader is used for the websocket transport. If Upgrader is nil, a
+	// zero Upgrader is used.
+	Upgrader *websocket.Upgrader
+
+	// KeepAlive specifies the interval between comments sent on idle SSE
+	// streams. If zero, a default of 15 seconds is used.
+	KeepAlive time.Duration
+
+	// PollTimeout specifies how long a poll waits for messages. If zero, a
+	// default of 30 seconds is used.
+	PollTimeout time.Duration
+
+	// SessionTimeout specifies how long a long-polling session is kept
+	// between polls. If zero, a default of 60 seconds is used.
+	SessionTimeout time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*LongPoll
+}
+
+func durationOrDefault(d, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
+
+// ServeHTTP selects the transport for the request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case websocket.IsWebSocketUpgrade(r):
+		upgrader := s.Upgrader
+		if upgrader == nil {
+			upgrader = &websocket.Upgrader{}
+		}
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		s.handle(ws)
+	case acceptsEventStream(r):
+		t, err := NewSSE(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		t.keepAlive = durationOrDefault(s.KeepAlive, defaultKeepAlive)
+		go t.keepAliveLoop()
+		defer t.Close()
+		s.handle(t)
+	default:
+		s.servePoll(w, r)
+	}
+}
+
+func (s *Server) handle(t Transport) {
+	if s.Handler != nil {
+		s.Handler(t)
+	}
+}
+
+func acceptsEventStream(r *http.Request) bool {
+	for _, v := range r.Header.Values("Accept") {
+		for _, t := range strings.Split(v, ",") {
+			t, _, _ = strings.Cut(t, ";")
+			if strings.TrimSpace(t) == "text/event-stream" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func newTestServer(t *testing.T, s *Server) string {
+	hs := httptest.NewServer(s)
+	t.Cleanup(hs.Close)
+	return hs.URL
+}
+
+// greeter writes the messages to the client and waits for the client to go
+// away.
+func greeter(messages ...string) func(Transport) {
+	return func(t Transport) {
+		for _, m := range messages {
+			if err := t.WriteMessage(websocket.TextMessage, []byte(m)); err != nil {
+				return
+			}
+		}
+		t.ReadMessage()
+	}
+}
+
+func TestWebsocketTransport(t *testing.T) {
+	url := newTestServer(t, &Server{Handler: greeter("hello")})
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(url, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, p, err := ws.ReadMessage()
+	if err != nil || string(p) != "hello" {
+		t.Fatalf("ReadMessage() = %q, %v, want hello", p, err)
+	}
+}
+
+func TestAcceptsEventStream(t *testing.T) {
+	tests := []struct {
+		accept []string
+		want   bool
+	}{
+		{nil, false},
+		{[]string{"application/json"}, false},
+		{[]string{"text/event-stream"}, true},
+		{[]string{"text/html, text/event-stream;q=0.9"}, true},
+		{[]string{"text/html", "text/event-stream"}, true},
+		{[]string{"text/event-streams"}, false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		for _, v := range tt.accept {
+			r.Header.Add("Accept", v)
+		}
+		if got := acceptsEventStream(r); got != tt.want {
+			t.Errorf("acceptsEventStream(%q) = %v, want %v", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestServerClosesTransport(t *testing.T) {
+	url := newTestServer(t, &Server{Handler: func(Transport) {}})
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var buf [1]byte
+	if n, _ := resp.Body.Read(buf[:]); n != 0 {
+		t.Errorf("read %d bytes from closed stream", n)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/websocket"
+)
+
+// Client dials tunnels through a Server.
+type Client struct {
+	// URL is the websocket URL of the server.
+	URL string
+
+	// Dialer is used to dial the server. If Dialer is nil,
+	// websocket.DefaultDialer is used.
+	Dialer *websocket.Dialer
+
+	// Header specifies additional request headers such as credentials.
+	Header http.Header
+}
+
+// DialContext opens a tunnel to the target address. If target is not empty,
+// it is sent in the "target" query parameter. The returned connection reads
+// and writes the tunneled stream.
+func (c *Client) DialContext(ctx context.Context, target string) (net.Conn, error) {
+	ws, err := c.dial(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	return NewConn(ws), nil
+}
+
+func (c *Client) dial(ctx context.Context, target string) (*websocket.Conn, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, err
+	}
+	if target != "" {
+		q := u.Query()
+		q.Set("target", target)
+		u.RawQuery = q.Encode()
+	}
+	d := c.Dialer
+	if d == nil {
+		d = websocket.DefaultDialer
+	}
+	ws, resp, err := d.DialContext(ctx, u.String(), c.Header)
+	if errors.Is(err, websocket.ErrBadHandshake) && resp != nil {
+		return nil, errors.New("tunnel: server responded with " + resp.Status)
+	}
+	return ws, err
+}
+
+// Serve accepts connections on ln and forwards each connection through a new
+// tunnel to target. Serve returns when ln.Accept fails. Connections for which
+// the tunnel cannot be opened are closed.
+func (c *Client) Serve(ln net.Listener, target string) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			ws, err := c.dial(context.Background(), target)
+			if err != nil {
+				conn.Close()
+				return
+			}
+			Join(ws, conn)
+		}()
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tunnel
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// Server is an http.Handler that tunnels websocket connections to TCP
+// targets.
+type Server struct {
+	// Upgrader is used to upgrade requests. If Upgrader is nil, a zero
+	// Upgrader is used. The target is dialed after the upgrader checked the
+	// request, so the Upgrader's IPFilter, CheckOrigin and Authenticate
+	// checks run before a connection to the target is opened.
+	Upgrader *websocket.Upgrader
+
+	// Target returns the TCP address to dial for the request. If Target is
+	// nil, the address is taken from the "target" query parameter.
+	Target func(r *http.Request) (string, error)
+
+	// AllowedTargets lists the addresses that clients may connect to. The
+	// host and port of the target and of the allowed addresses are
+	// resolved before they are compared, and the matching IP address is
+	// dialed.
+	//
+	// If AllowedTargets is nil, all targets are allowed and the server is an
+	// open proxy for the clients that pass the Upgrader's checks.
+	AllowedTargets []string
+
+	// DialContext dials the target. If DialContext is nil, net.Dialer is used.
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// ErrorLog is called with the error that ended a tunnel or that failed
+	// the dial of the target. If ErrorLog is nil, errors are ignored.
+	ErrorLog func(r *http.Request, err error)
+}
+
+// targetError is a failure to open the target, reported to the client
+// with an HTTP status.
+type targetError struct {
+	status int
+	msg    string
+}
+
+func (e *targetError) Error() string { return e.msg }
+
+// resolve returns the IP addresses and port of addr.
+func resolve(ctx context.Context, addr string) ([]netip.AddrPort, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	p, err := net.DefaultResolver.LookupPort(ctx, "tcp", port)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]netip.AddrPort, len(ips))
+	for i, ip := range ips {
+		addrs[i] = netip.AddrPortFrom(ip.Unmap(), uint16(p))
+	}
+	return addrs, nil
+}
+
+// allowed returns the address to dial for addr and whether addr is allowed.
+func (s *Server) allowed(ctx context.Context, addr string) (string, bool) {
+	if s.AllowedTargets == nil {
+		return addr, true
+	}
+	targets, err := resolve(ctx, addr)
+	if err != nil {
+		return "", false
+	}
+	for _, a := range s.AllowedTargets {
+		allowed, err := resolve(ctx, a)
+		if err != nil {
+			continue
+		}
+		for _, t := range targets {
+			for _, x := range allowed {
+				if t == x {
+					return t.String(), true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// dial opens the connection to the target of the request.
+func (s *Server) dial(r *http.Request) (net.Conn, error) {
+	var addr string
+	if s.Target != nil {
+		var err error
+		if addr, err = s.Target(r); err != nil {
+			return nil, &targetError{http.StatusBadRequest, err.Error()}
+		}
+	} else {
+		addr = r.URL.Query().Get("target")
+	}
+	if addr == "" {
+		return nil, &targetError{http.StatusBadRequest, "tunnel: missing target"}
+	}
+	addr, ok := s.allowed(r.Context(), addr)
+	if !ok {
+		return nil, &targetError{http.StatusForbidden, "tunnel: target not allowed"}
+	}
+
+	dial := s.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	c, err := dial(r.Context(), "tcp", addr)
+	if err != nil {
+		if s.ErrorLog != nil {
+			s.ErrorLog(r, err)
+		}
+		// The dial error is not sent to the client because it describes
+		// the network behind the server.
+		return nil, &targetError{http.StatusBadGateway, "tunnel: cannot connect to target"}
+	}
+	return c, nil
+}
+
+// ServeHTTP upgrades the request, dials the target and joins the
+// connections. The target is dialed from the Upgrader's ResponseHeader
+// hook, after the handshake checks and before the connection is hijacked,
+// so that failures are reported to the client with an HTTP status.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	upgrader := s.Upgrader
+	if upgrader == nil {
+		upgrader = &websocket.Upgrader{}
+	}
+
+	var (
+		c       net.Conn
+		failure *targetError
+	)
+	u := *upgrader
+	u.ResponseHeader = func(r *http.Request, n websocket.Negotiation, header http.Header) error {
+		if upgrader.ResponseHeader != nil {
+			if err := upgrader.ResponseHeader(r, n, header); err != nil {
+				return err
+			}
+		}
+		var err error
+		if c, err = s.dial(r); err != nil {
+			failure = err.(*targetError)
+			return err
+		}
+		return nil
+	}
+	u.Error = func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+		switch {
+		case failure != nil:
+			http.Error(w, failure.msg, failure.status)
+		case upgrader.Error != nil:
+			upgrader.Error(w, r, status, reason)
+		default:
+			versions := upgrader.Versions
+			if len(versions) == 0 {
+				versions = []string{websocket.Version13}
+			}
+			w.Header().Set("Sec-Websocket-Version", strings.Join(versions, ", "))
+			http.Error(w, http.StatusText(status), status)
+		}
+	}
+	ws, err := u.Upgrade(w, r, nil)
+	if err != nil {
+		if c != nil {
+			c.Close()
+		}
+		return
+	}
+	if err := Join(ws, c); err != nil && s.ErrorLog != nil {
+		s.ErrorLog(r, err)
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tunnel
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newTCPServer starts a TCP server that runs handle for each connection.
+func newTCPServer(t *testing.T, handle func(net.Conn)) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				handle(c)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func echo(c net.Conn) { io.Copy(c, c) }
+
+func newTestServer(t *testing.T, s *Server) *Client {
+	hs := httptest.NewServer(s)
+	t.Cleanup(hs.Close)
+	return &Client{URL: "ws" + strings.TrimPrefix(hs.URL, "http")}
+}
+
+func TestTunnel(t *testing.T) {
+	target := newTCPServer(t, echo)
+	client := newTestServer(t, &Server{})
+
+	c, err := client.DialContext(context.Background(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.SetDeadline(time.Now().Add(5 * time.Second))
+	for _, s := range []string{"hello", strings.Repeat("x", 3*bufferSize)} {
+		if _, err := c.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+		p := make([]byte, len(s))
+		if _, err := io.ReadFull(c, p); err != nil {
+			t.Fatal(err)
+		}
+		if string(p) != s {
+			t.Fatalf("read %d bytes that differ from the %d bytes written", len(p), len(s))
+		}
+	}
+}
+
+func TestTargetCloses(t *testing.T) {
+	target := newTCPServer(t, func(c net.Conn) {
+		io.WriteString(c, "bye")
+	})
+	client := newTestServer(t, &Server{})
+	c, err := client.DialContext(context.Background(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.SetDeadline(time.Now().Add(5 * time.Second))
+	p, err := io.ReadAll(c)
+	if err != nil || string(p) != "bye" {
+		t.Errorf("ReadAll() = %q, %v, want bye", p, err)
+	}
+}
+
+func TestClientCloses(t *testing.T) {
+	closed := make(chan error, 1)
+	target := newTCPServer(t, func(c net.Conn) {
+		_, err := io.Copy(io.Discard, c)
+		closed <- err
+	})
+	client := newTestServer(t, &Server{})
+	c, err := client.DialContext(context.Background(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Write([]byte("data"))
+	c.Close()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Errorf("target read error = %v, want EOF", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("target connection was not closed")
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	target := newTCPServer(t, echo)
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	unreachable := ln.Addr().String()
+	ln.Close()
+
+	tests := []struct {
+		name   string
+		server *Server
+		target string
+		status string
+	}{
+		{"missing target", &Server{}, "", "400"},
+		{"not allowed", &Server{AllowedTargets: []string{"example.com:80"}}, target, "403"},
+		{"dial failure", &Server{}, unreachable, "502"},
+	}
+	for _, tt := range tests {
+		client := newTestServer(t, tt.server)
+		_, err := client.DialContext(context.Background(), tt.target)
+		if err == nil || !strings.Contains(err.Error(), tt.status) {
+			t.Errorf("%s: DialContext() error = %v, want status %s", tt.name, err, tt.status)
+		}
+	}
+
+	// Allowed targets are served. Addresses are resolved before they are
+	// compared.
+	_, port, _ := net.SplitHostPort(target)
+	for _, allowed := range []string{target, "localhost:" + port} {
+		client := newTestServer(t, &Server{AllowedTargets: []string{allowed}})
+		c, err := client.DialContext(context.Background(), net.JoinHostPort("localhost", port))
+		if err != nil {
+			t.Fatalf("allowed %s: %v", allowed, err)
+		}
+		c.Close()
+	}
+}
+
+func TestServerDialError(t *testing.T) {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	unreachable := ln.Addr().String()
+	ln.Close()
+
+	logged := make(chan error, 1)
+	s := &Server{ErrorLog: func(r *http.Request, err error) { logged <- err }}
+	hs := httptest.NewServer(s)
+	defer hs.Close()
+	u := "ws" + strings.TrimPrefix(hs.URL, "http") + "?target=" + url.QueryEscape(unreachable)
+	_, resp, err := websocket.DefaultDialer.Dial(u, nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusBadGateway {
+		t.Fatalf("Dial() = %v, %v, want status %d", resp, err, http.StatusBadGateway)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if strings.Contains(string(body), unreachable) {
+		t.Errorf("response body = %q, want no dial error", body)
+	}
+	select {
+	case <-logged:
+	default:
+		t.Error("dial error not logged")
+	}
+}
+
+func TestServerChecksBeforeDial(t *testing.T) {
+	target := newTCPServer(t, echo)
+	dialed := false
+	s := &Server{
+		Upgrader: &websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return false }},
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed = true
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}
+	client := newTestServer(t, s)
+	_, err := client.DialContext(context.Background(), target)
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("DialContext() error = %v, want status 403", err)
+	}
+	if dialed {
+		t.Error("target dialed for a rejected request")
+	}
+}
+
+func TestServe(t *testing.T) {
+	target := newTCPServer(t, echo)
+	client := newTestServer(t, &Server{})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go client.Serve(ln, target)
+
+	c, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.SetDeadline(time.Now().Add(5 * time.Second))
+	io.WriteString(c, "ping")
+	p := make([]byte, 4)
+	if _, err := io.ReadFull(c, p); err != nil || string(p) != "ping" {
+		t.Errorf("read %q, %v, want ping", p, err)
+	}
+}
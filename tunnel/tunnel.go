@@ -0,0 +1,171 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package tunnel carries TCP connections over WebSocket connections.
+//
+// The Server type accepts websocket connections, dials a TCP target for each
+// connection and copies data in both directions. The Client type dials
+// tunnels through a Server and can forward connections accepted on a local
+// listener:
+//
+//	c := &tunnel.Client{URL: "wss://gateway.example.com/tunnel"}
+//	ln, err := net.Listen("tcp", "localhost:5432")
+//	...
+//	err = c.Serve(ln, "db.internal:5432")
+//
+// Stream data is sent in binary messages. A normal close of the websocket
+// connection ends the TCP connection and the end of the TCP stream closes
+// the websocket connection.
+package tunnel
+
+import (
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	bufferSize       = 32 * 1024
+	writeWait        = 10 * time.Second
+	closeGracePeriod = time.Second
+)
+
+// Join copies data between ws and c until either side ends and then closes
+// both connections. Join returns nil when the connections end normally.
+func Join(ws *websocket.Conn, c net.Conn) error {
+	fromWS := make(chan error, 1)
+	fromConn := make(chan error, 1)
+	ws.Go(func() { fromWS <- copyFromWebSocket(c, ws) })
+	ws.Go(func() { fromConn <- copyToWebSocket(ws, c) })
+
+	var err error
+	select {
+	case err = <-fromWS:
+		c.Close()
+		<-fromConn
+	case err = <-fromConn:
+		// The close message was sent. Wait briefly for the peer's reply.
+		ws.SetReadDeadline(time.Now().Add(closeGracePeriod))
+		<-fromWS
+		c.Close()
+	}
+	ws.Close()
+	return err
+}
+
+// copyFromWebSocket copies messages from ws to c.
+func copyFromWebSocket(c net.Conn, ws *websocket.Conn) error {
+	for {
+		_, r, err := ws.NextReader()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return nil
+			}
+			return err
+		}
+		if _, err := io.Copy(c, r); err != nil {
+			return err
+		}
+	}
+}
+
+// copyToWebSocket copies data from c to ws and sends a close message when c
+// reaches EOF.
+func copyToWebSocket(ws *websocket.Conn, c net.Conn) error {
+	buf := make([]byte, bufferSize)
+	for {
+		n, err := c.Read(buf)
+		if n > 0 {
+			ws.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := ws.WriteMessage(websocket.BinaryMessage, buf[:n]); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			err = ws.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+				time.Now().Add(writeWait))
+			if err == websocket.ErrCloseSent {
+				err = nil
+			}
+			return err
+		}
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				// Join closed c after the websocket connection ended.
+				return nil
+			}
+			ws.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseInternalServerErr, ""),
+				time.Now().Add(writeWait))
+			return err
+		}
+	}
+}
+
+// NewConn returns a net.Conn that reads and writes the stream carried by
+// ws. Each Write sends one binary message. Close sends a close message and
+// closes ws.
+func NewConn(ws *websocket.Conn) net.Conn {
+	return &conn{ws: ws}
+}
+
+type conn struct {
+	ws *websocket.Conn
+	r  io.Reader
+}
+
+func (c *conn) Read(p []byte) (int, error) {
+	for {
+		if c.r == nil {
+			_, r, err := c.ws.NextReader()
+			if err != nil {
+				if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+					err = io.EOF
+				}
+				return 0, err
+			}
+			c.r = r
+		}
+		n, err := c.r.Read(p)
+		if err == io.EOF {
+			c.r = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (c *conn) Write(p []byte) (int, error) {
+	if err := c.ws.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (c *conn) Close() error {
+	c.ws.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(writeWait))
+	return c.ws.Close()
+}
+
+func (c *conn) LocalAddr() net.Addr  { return c.ws.LocalAddr() }
+func (c *conn) RemoteAddr() net.Addr { return c.ws.RemoteAddr() }
+
+func (c *conn) SetDeadline(t time.Time) error {
+	if err := c.ws.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.ws.SetWriteDeadline(t)
+}
+
+func (c *conn) SetReadDeadline(t time.Time) error  { return c.ws.SetReadDeadline(t) }
+func (c *conn) SetWriteDeadline(t time.Time) error { return c.ws.SetWriteDeadline(t) }
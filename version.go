@@ -0,0 +1,131 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Version13 is the Sec-WebSocket-Version of RFC 6455.
+const Version13 = "13"
+
+// ProtocolVersion describes a value of the Sec-WebSocket-Version header and
+// the adjustments to the framing of the connections that use it. Versions
+// other than Version13 are registered with RegisterVersion, for example
+// private versions used on closed networks.
+type ProtocolVersion struct {
+	// Name is the value of the Sec-WebSocket-Version header.
+	Name string
+
+	// Configure adjusts a new connection for the version before the
+	// connection is returned to the application, for example by calling
+	// SetStrictness or SetPayloadTransform. Configure runs after the
+	// options of the dialer or upgrader are applied. If Configure is nil,
+	// the connection uses the framing of RFC 6455.
+	Configure func(c *Conn)
+}
+
+var (
+	versionsMu sync.RWMutex
+	versions   = map[string]ProtocolVersion{Version13: {Name: Version13}}
+)
+
+var (
+	errVersionName    = errors.New("websocket: version name must be a token")
+	errVersionExists  = errors.New("websocket: version already registered")
+	errVersionUnknown = errors.New("websocket: unknown version")
+)
+
+// RegisterVersion registers a protocol version for Upgrader.Versions and
+// Dialer.Version. The name must be a token and must not be registered
+// already.
+func RegisterVersion(v ProtocolVersion) error {
+	if token, rest := nextToken(v.Name); token == "" || rest != "" {
+		return errVersionName
+	}
+	versionsMu.Lock()
+	defer versionsMu.Unlock()
+	if _, ok := versions[v.Name]; ok {
+		return errVersionExists
+	}
+	versions[v.Name] = v
+	return nil
+}
+
+// unregisterVersion removes a registered protocol version.
+func unregisterVersion(name string) {
+	versionsMu.Lock()
+	defer versionsMu.Unlock()
+	delete(versions, name)
+}
+
+// LookupVersion returns the registered protocol version with the given
+// name.
+func LookupVersion(name string) (ProtocolVersion, bool) {
+	versionsMu.RLock()
+	defer versionsMu.RUnlock()
+	v, ok := versions[name]
+	return v, ok
+}
+
+// Version returns the Sec-WebSocket-Version negotiated in the handshake.
+func (c *Conn) Version() string {
+	if c.version == "" {
+		return Version13
+	}
+	return c.version
+}
+
+// useVersion sets the negotiated version and applies its adjustments.
+func (c *Conn) useVersion(v ProtocolVersion) {
+	c.version = v.Name
+	if v.Configure != nil {
+		v.Configure(c)
+	}
+}
+
+// versions returns the versions accepted by the upgrader in order of
+// preference.
+func (u *Upgrader) versions() []string {
+	if len(u.Versions) == 0 {
+		return []string{Version13}
+	}
+	return u.Versions
+}
+
+// selectVersion returns the most preferred registered version offered by
+// the client.
+func (u *Upgrader) selectVersion(r *http.Request) (ProtocolVersion, bool) {
+	for _, name := range u.versions() {
+		if !tokenListContainsValue(r.Header, "Sec-Websocket-Version", name) {
+			continue
+		}
+		if v, ok := LookupVersion(name); ok {
+			return v, true
+		}
+	}
+	return ProtocolVersion{}, false
+}
+
+// version returns the version requested by the dialer.
+func (d *Dialer) version() (ProtocolVersion, error) {
+	if d.Version == "" {
+		return ProtocolVersion{Name: Version13}, nil
+	}
+	v, ok := LookupVersion(d.Version)
+	if !ok {
+		return ProtocolVersion{}, errVersionUnknown
+	}
+	return v, nil
+}
+
+// unsupportedVersion returns the reason for a handshake with no accepted
+// version.
+func (u *Upgrader) unsupportedVersion() string {
+	return "websocket: unsupported version: " + strings.Join(u.versions(), ", ") + " not found in 'Sec-Websocket-Version' header"
+}
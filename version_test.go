@@ -0,0 +1,97 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRegisterVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"test-reg", nil},
+		{"test-reg", errVersionExists},
+		{Version13, errVersionExists},
+		{"", errVersionName},
+		{"a b", errVersionName},
+	}
+	t.Cleanup(func() { unregisterVersion("test-reg") })
+	for _, tt := range tests {
+		if err := RegisterVersion(ProtocolVersion{Name: tt.name}); err != tt.err {
+			t.Errorf("RegisterVersion(%q) = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+	if _, ok := LookupVersion("test-reg"); !ok {
+		t.Error("LookupVersion() did not find the registered version")
+	}
+}
+
+func TestVersionNegotiation(t *testing.T) {
+	err := RegisterVersion(ProtocolVersion{Name: "test-private", Configure: func(c *Conn) {
+		c.SetStrictness(StrictTextUTF8)
+		c.SetDefaultWriteTimeout(time.Minute)
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { unregisterVersion("test-private") })
+	tests := []struct {
+		accept  []string
+		version string
+		status  int
+		want    string
+	}{
+		{nil, "", http.StatusSwitchingProtocols, Version13},
+		{nil, "test-private", http.StatusBadRequest, "13"},
+		{[]string{"test-private", Version13}, "", http.StatusSwitchingProtocols, Version13},
+		{[]string{"test-private", Version13}, "test-private", http.StatusSwitchingProtocols, "test-private"},
+		{[]string{"test-private"}, "", http.StatusBadRequest, "test-private"},
+		{[]string{"test-unregistered", Version13}, "", http.StatusSwitchingProtocols, Version13},
+	}
+	for _, tt := range tests {
+		conns := make(chan *Conn, 1)
+		// The version overrides the options.
+		upgrader := Upgrader{Versions: tt.accept, DefaultWriteTimeout: time.Second}
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c, err := upgrader.Upgrade(w, r, nil)
+			conns <- c
+			if err == nil {
+				c.ReadMessage()
+			}
+		}))
+		d := Dialer{Version: tt.version, DefaultWriteTimeout: time.Second}
+		c, resp, _ := d.Dial(makeWsProto(s.URL), nil)
+		sc := <-conns
+		switch {
+		case resp == nil || resp.StatusCode != tt.status:
+			t.Errorf("%v %q: response = %v, want status %d", tt.accept, tt.version, resp, tt.status)
+		case c != nil:
+			if c.Version() != tt.want || sc.Version() != tt.want {
+				t.Errorf("%v %q: Version() = %q, %q, want %q", tt.accept, tt.version, c.Version(), sc.Version(), tt.want)
+			}
+			if got := sc.strictness != 0 && sc.writeTimeout == time.Minute; got != (tt.want == "test-private") {
+				t.Errorf("%v %q: version was not configured", tt.accept, tt.version)
+			}
+			if got := c.writeTimeout == time.Minute; got != (tt.want == "test-private") {
+				t.Errorf("%v %q: client version was not configured", tt.accept, tt.version)
+			}
+			c.Close()
+		default:
+			if got := resp.Header.Get("Sec-Websocket-Version"); got != tt.want {
+				t.Errorf("%v %q: Sec-Websocket-Version = %q, want %q", tt.accept, tt.version, got, tt.want)
+			}
+		}
+		s.Close()
+	}
+
+	if _, _, err := (&Dialer{Version: "test-unregistered"}).Dial("ws://example.com/", nil); err != errVersionUnknown {
+		t.Errorf("Dial() error = %v, want %v", err, errVersionUnknown)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import "net"
+
+// ViolationKind classifies the violations that make a connection fail.
+type ViolationKind string
+
+// Kinds of protocol violations.
+const (
+	ViolationReservedBits  ViolationKind = "reserved_bits" // RSV bits set without a negotiated extension
+	ViolationOpcode        ViolationKind = "opcode"        // reserved opcode
+	ViolationControlFrame  ViolationKind = "control_frame" // fragmented or oversized control frame
+	ViolationFragmentation ViolationKind = "fragmentation" // bad sequence of data and continuation frames
+	ViolationMask          ViolationKind = "mask"          // masked frame from a server or unmasked frame from a client
+	ViolationCloseCode     ViolationKind = "close_code"    // invalid close code
+	ViolationCloseText     ViolationKind = "close_text"    // close reason is not valid UTF-8
+	ViolationTextUTF8      ViolationKind = "text_utf8"     // text message is not valid UTF-8 with StrictTextUTF8
+	ViolationReadLimit     ViolationKind = "read_limit"    // message or frame larger than the read limit
+	ViolationRateLimit     ViolationKind = "rate_limit"    // read rate limit exceeded with RateLimitClose
+	ViolationFlowControl   ViolationKind = "flow_control"  // data beyond the flow control window or a bad credit frame
+)
+
+// ProtocolViolation describes a violation by the peer that made the
+// connection fail.
+type ProtocolViolation struct {
+	// Kind classifies the violation. If a frame has several violations,
+	// Kind is the first one found.
+	Kind ViolationKind
+
+	// RemoteAddr is the network address of the peer. Behind a proxy, this
+	// is the address of the proxy.
+	RemoteAddr net.Addr
+
+	// Err is the error returned to the application.
+	Err error
+}
+
+// SetProtocolViolationHandler sets the handler that is called when the
+// connection fails because the peer violated the protocol, for example by
+// setting reserved bits, sending an invalid close code or exceeding the
+// read limit. The handler is called from the reading goroutine before the
+// error is returned by NextReader. Handlers can feed the violations to a
+// ban list.
+//
+// The default handler does nothing.
+func (c *Conn) SetProtocolViolationHandler(h func(v ProtocolViolation)) {
+	c.handleViolation = h
+}
+
+// reportViolation calls the protocol violation handler and returns err.
+func (c *Conn) reportViolation(kind ViolationKind, err error) error {
+	if c.handleViolation != nil {
+		c.handleViolation(ProtocolViolation{Kind: kind, RemoteAddr: c.RemoteAddr(), Err: err})
+	}
+	return err
+}
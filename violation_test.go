@@ -0,0 +1,58 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import "testing"
+
+func TestProtocolViolationHandler(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame []byte // written by the client with a zero masking key
+		want  ViolationKind
+	}{
+		{"rsv2", []byte{0xa1, 0x80, 0, 0, 0, 0}, ViolationReservedBits},
+		{"opcode", []byte{0x83, 0x80, 0, 0, 0, 0}, ViolationOpcode},
+		{"fragmented ping", []byte{0x09, 0x80, 0, 0, 0, 0}, ViolationControlFrame},
+		{"continuation", []byte{0x80, 0x80, 0, 0, 0, 0}, ViolationFragmentation},
+		{"unmasked", []byte{0x81, 0x00}, ViolationMask},
+		{"first of several", []byte{0xa3, 0x00}, ViolationReservedBits},
+		{"close code", []byte{0x88, 0x82, 0, 0, 0, 0, 0x03, 0xe7}, ViolationCloseCode},
+		{"close text", []byte{0x88, 0x83, 0, 0, 0, 0, 0x03, 0xe8, 0xff}, ViolationCloseText},
+		{"read limit", []byte{0x81, 0x83, 0, 0, 0, 0, 'a', 'b', 'c'}, ViolationReadLimit},
+	}
+	for _, tt := range tests {
+		client, server := Pipe()
+		server.SetReadLimit(2)
+		var got []ProtocolViolation
+		server.SetProtocolViolationHandler(func(v ProtocolViolation) { got = append(got, v) })
+		client.NetConn().Write(tt.frame)
+		_, _, err := server.ReadMessage()
+		if len(got) != 1 {
+			t.Errorf("%s: handler called %d times, want 1", tt.name, len(got))
+		} else {
+			v := got[0]
+			if v.Kind != tt.want || v.RemoteAddr != server.RemoteAddr() || v.Err != err {
+				t.Errorf("%s: got %+v, want kind %s, address %v and error %v", tt.name, v, tt.want, server.RemoteAddr(), err)
+			}
+		}
+		client.Close()
+		server.Close()
+	}
+}
+
+func TestProtocolViolationHandlerNotCalled(t *testing.T) {
+	client, server := Pipe()
+	defer server.Close()
+	called := false
+	server.SetProtocolViolationHandler(func(ProtocolViolation) { called = true })
+	client.WriteMessage(CloseMessage, FormatCloseMessage(CloseNormalClosure, ""))
+	client.Close()
+	if _, _, err := server.ReadMessage(); err == nil {
+		t.Fatal("ReadMessage() returned no error")
+	}
+	if called {
+		t.Error("handler called for a normal close")
+	}
+}
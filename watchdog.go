@@ -0,0 +1,167 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"sync"
+	"time"
+)
+
+// Activity holds the times of the last messages received on a connection.
+// A zero time means that no such message was received.
+type Activity struct {
+	// LastData is the time the last data frame was received.
+	LastData time.Time
+
+	// LastPong is the time the last pong was received.
+	LastPong time.Time
+}
+
+// last returns the later of the times.
+func (a Activity) last() time.Time {
+	if a.LastPong.After(a.LastData) {
+		return a.LastPong
+	}
+	return a.LastData
+}
+
+// Activity returns the times of the last data frame and the last pong
+// received on the connection.
+func (c *Conn) Activity() Activity {
+	var a Activity
+	if t := c.lastData.Load(); t != 0 {
+		a.LastData = time.Unix(0, t)
+	}
+	if t := c.lastPong.Load(); t != 0 {
+		a.LastPong = time.Unix(0, t)
+	}
+	return a
+}
+
+// Watchdog closes connections that received neither a pong nor data for a
+// time window. Peers that vanish without closing the TCP connection, such
+// as mobile clients behind a NAT that dropped its mapping, are detected
+// when the application pings them and the pongs stop.
+//
+// The watchdog relies on the application or the peer to produce traffic:
+// it does not send pings. A connection must be read from for its activity
+// to be recorded.
+//
+// A Watchdog is safe for concurrent use. The zero value is not usable; set
+// Window before the first call to Add.
+type Watchdog struct {
+	// Window is the time after which a connection without activity is
+	// closed. Activity is a pong or a data frame received from the peer.
+	Window time.Duration
+
+	// Interval is the time between checks. If Interval is zero, Window/4
+	// is used.
+	Interval time.Duration
+
+	// OnDead is called from the watchdog goroutine after the watchdog
+	// closes a connection, with the activity times of the connection.
+	OnDead func(c *Conn, a Activity)
+
+	// Clock specifies the clock for the checks. If Clock is nil, the system
+	// clock is used.
+	Clock Clock
+
+	mu    sync.Mutex
+	conns map[*Conn]time.Time // time added
+	stop  chan struct{}
+}
+
+// Add starts watching c. A connection without activity is closed a Window
+// after it is added. The watchdog stops watching c when it closes c. Call
+// Remove when the application closes c.
+func (w *Watchdog) Add(c *Conn) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conns == nil {
+		w.conns = make(map[*Conn]time.Time)
+	}
+	w.conns[c] = w.clock().Now()
+	if w.stop == nil {
+		w.stop = make(chan struct{})
+		go w.run(w.stop)
+	}
+}
+
+// Remove stops watching c.
+func (w *Watchdog) Remove(c *Conn) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.conns, c)
+}
+
+// Stop stops the watchdog goroutine and forgets the watched connections.
+// The connections are not closed. Add restarts the watchdog.
+func (w *Watchdog) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stop != nil {
+		close(w.stop)
+		w.stop = nil
+	}
+	w.conns = nil
+}
+
+func (w *Watchdog) clock() Clock {
+	if w.Clock == nil {
+		return systemClock{}
+	}
+	return w.Clock
+}
+
+func (w *Watchdog) interval() time.Duration {
+	if w.Interval > 0 {
+		return w.Interval
+	}
+	return w.Window / 4
+}
+
+func (w *Watchdog) run(stop chan struct{}) {
+	for {
+		timer := w.clock().NewTimer(w.interval())
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+		w.check()
+	}
+}
+
+// check closes the connections that are inactive for the window.
+func (w *Watchdog) check() {
+	now := w.clock().Now()
+	type deadConn struct {
+		c *Conn
+		a Activity
+	}
+	var dead []deadConn
+	w.mu.Lock()
+	for c, added := range w.conns {
+		a := c.Activity()
+		last := a.last()
+		if last.Before(added) {
+			last = added
+		}
+		if now.Sub(last) < w.Window {
+			continue
+		}
+		dead = append(dead, deadConn{c, a})
+		delete(w.conns, c)
+	}
+	w.mu.Unlock()
+
+	for _, d := range dead {
+		d.c.Close()
+		if w.OnDead != nil {
+			w.OnDead(d.c, d.a)
+		}
+	}
+}
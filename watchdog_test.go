@@ -0,0 +1,86 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websocket
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatchdog(t *testing.T) {
+	clock := newFakeClock()
+	config := PipeConfig{Clock: clock}
+	idleClient, idle := config.Pipe()
+	defer idleClient.Close()
+	activeClient, active := config.Pipe()
+	defer activeClient.Close()
+	defer active.Close()
+
+	type deadConn struct {
+		c *Conn
+		a Activity
+	}
+	dead := make(chan deadConn, 2)
+	w := &Watchdog{
+		Window:   time.Minute,
+		Interval: 10 * time.Second,
+		Clock:    clock,
+		OnDead:   func(c *Conn, a Activity) { dead <- deadConn{c, a} },
+	}
+	defer w.Stop()
+	w.Add(idle)
+	w.Add(active)
+
+	for i := 0; i < 3; i++ {
+		clock.advance(t, 10*time.Second)
+	}
+	activeClient.WriteMessage(TextMessage, []byte("hello"))
+	if _, _, err := active.ReadMessage(); err != nil {
+		t.Fatal(err)
+	}
+	lastData := clock.Now()
+	for i := 0; i < 3; i++ {
+		clock.advance(t, 10*time.Second)
+	}
+
+	select {
+	case d := <-dead:
+		if d.c != idle || !d.a.LastData.IsZero() || !d.a.LastPong.IsZero() {
+			t.Errorf("OnDead(%p, %+v), want idle connection %p without activity", d.c, d.a, idle)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("idle connection not reported")
+	}
+	if _, _, err := idle.ReadMessage(); err == nil {
+		t.Error("idle connection not closed")
+	}
+
+	// The active connection dies a window after its last data frame.
+	for i := 0; i < 3; i++ {
+		clock.advance(t, 10*time.Second)
+	}
+	select {
+	case d := <-dead:
+		if d.c != active || !d.a.LastData.Equal(lastData) {
+			t.Errorf("OnDead(%p, %+v), want active connection %p with last data at %v", d.c, d.a, active, lastData)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("active connection not reported")
+	}
+}
+
+func TestActivityPong(t *testing.T) {
+	clock := newFakeClock()
+	client, server := (&PipeConfig{Clock: clock}).Pipe()
+	defer client.Close()
+	defer server.Close()
+	clock.now = clock.now.Add(time.Hour)
+	client.WriteControl(PongMessage, nil, time.Time{})
+	client.WriteMessage(CloseMessage, FormatCloseMessage(CloseNormalClosure, ""))
+	server.ReadMessage()
+	if a := server.Activity(); !a.LastPong.Equal(clock.Now()) || !a.LastData.IsZero() {
+		t.Errorf("Activity() = %+v, want pong at %v", a, clock.Now())
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websockettest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Clock is a fake websocket.Clock. Time stands still until the test calls
+// Advance.
+//
+//	clock := websockettest.NewClock(time.Now())
+//	client, server := (&websocket.PipeConfig{Clock: clock}).Pipe()
+//	client.SetReadDeadline(clock.Now().Add(time.Minute))
+//	go func() {
+//		clock.BlockUntil(1)
+//		clock.Advance(time.Minute)
+//	}()
+//	_, _, err := client.ReadMessage() // deadline exceeded
+type Clock struct {
+	mu     sync.Mutex
+	cond   sync.Cond
+	now    time.Time
+	timers []*clockTimer
+}
+
+var _ websocket.Clock = (*Clock)(nil)
+
+type clockTimer struct {
+	clock *Clock
+	when  time.Time
+	c     chan time.Time
+}
+
+// NewClock returns a clock set to t.
+func NewClock(t time.Time) *Clock {
+	c := &Clock{now: t}
+	c.cond.L = &c.mu
+	return c
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer creates a timer that fires when the clock is advanced by at least
+// d.
+func (c *Clock) NewTimer(d time.Duration) websocket.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &clockTimer{clock: c, when: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+	return t
+}
+
+// Advance advances the clock by d and fires the timers that expire.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	timers := c.timers[:0]
+	for _, t := range c.timers {
+		if t.when.After(c.now) {
+			timers = append(timers, t)
+		} else {
+			t.c <- c.now
+		}
+	}
+	c.timers = timers
+	c.cond.Broadcast()
+}
+
+// BlockUntil blocks until at least n timers are waiting to fire. Use it to
+// advance the clock after the code under test starts waiting.
+func (c *Clock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+func (t *clockTimer) C() <-chan time.Time { return t.c }
+
+func (t *clockTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, u := range c.timers {
+		if u == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
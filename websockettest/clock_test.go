@@ -0,0 +1,55 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websockettest
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestClockTimers(t *testing.T) {
+	start := time.Unix(1000, 0)
+	c := NewClock(start)
+	t1 := c.NewTimer(time.Second)
+	t2 := c.NewTimer(2 * time.Second)
+	c.BlockUntil(2)
+	if !t2.Stop() || t2.Stop() {
+		t.Error("Stop() results wrong")
+	}
+	c.Advance(time.Second)
+	select {
+	case now := <-t1.C():
+		if !now.Equal(start.Add(time.Second)) {
+			t.Errorf("timer fired at %v", now)
+		}
+	default:
+		t.Error("timer did not fire")
+	}
+	if t1.Stop() {
+		t.Error("Stop() = true after the timer fired")
+	}
+	select {
+	case <-c.NewTimer(0).C():
+	default:
+		t.Error("zero duration timer did not fire")
+	}
+}
+
+func TestClockPipe(t *testing.T) {
+	clock := NewClock(time.Now())
+	client, _ := (&websocket.PipeConfig{Clock: clock}).Pipe()
+	client.SetReadDeadline(clock.Now().Add(time.Minute))
+	go func() {
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute)
+	}()
+	if _, _, err := client.ReadMessage(); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("ReadMessage() error = %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+}
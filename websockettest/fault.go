@@ -0,0 +1,312 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websockettest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// FaultFrame is a frame written to a FaultConn. Faults modify the frame
+// before it is written to the network.
+type FaultFrame struct {
+	// Index is the index of the frame on the connection, starting at zero
+	// for the first frame after the opening handshake.
+	Index int
+
+	// Data is the bytes to write: the frame header, the masking key if any
+	// and the masked payload.
+	Data []byte
+
+	// Delay is the time to wait before writing Data.
+	Delay time.Duration
+
+	// Disconnect specifies that the connection is closed after writing Data.
+	Disconnect bool
+
+	masked bool
+	opcode int
+	fin    bool
+}
+
+// Opcode returns the opcode of the original frame.
+func (f *FaultFrame) Opcode() int { return f.opcode }
+
+// Fin reports whether the original frame is the final frame of a message.
+func (f *FaultFrame) Fin() bool { return f.fin }
+
+// A Fault modifies frames written to a FaultConn.
+type Fault func(f *FaultFrame)
+
+// Delay delays frames by d.
+func Delay(d time.Duration) Fault {
+	return func(f *FaultFrame) { f.Delay += d }
+}
+
+// Truncate writes the first n bytes of frames and then disconnects.
+func Truncate(n int) Fault {
+	return func(f *FaultFrame) {
+		if n < len(f.Data) {
+			f.Data = f.Data[:n]
+		}
+		f.Disconnect = true
+	}
+}
+
+// Disconnect closes the connection instead of writing frames. Apply it to a
+// frame that is not the final frame of a message to disconnect in the
+// middle of the message.
+func Disconnect() Fault {
+	return func(f *FaultFrame) {
+		f.Data = nil
+		f.Disconnect = true
+	}
+}
+
+// CorruptMask inverts the MASK bit of frames. The peer reads the masking key
+// of a masked frame as payload, or the start of the payload of an unmasked
+// frame as the masking key.
+func CorruptMask() Fault {
+	return func(f *FaultFrame) {
+		if len(f.Data) > 1 {
+			f.Data = append([]byte(nil), f.Data...)
+			f.Data[1] ^= 0x80
+		}
+	}
+}
+
+// CorruptLength sets the payload length in the header of frames to n
+// without changing the payload.
+func CorruptLength(n uint64) Fault {
+	return func(f *FaultFrame) {
+		hdr, ok := headerLen(f.Data)
+		if !ok {
+			return
+		}
+		var b []byte
+		b = append(b, f.Data[0])
+		b = appendLength(b, f.Data[1]&0x80, n)
+		if f.Data[1]&0x80 != 0 {
+			b = append(b, f.Data[hdr-4:hdr]...)
+		}
+		f.Data = append(b, f.Data[hdr:]...)
+	}
+}
+
+// Control writes a control frame with the given message type and payload
+// before frames. The control frame is masked with a zero key if the frame
+// is masked. The payload is not checked against the protocol limits.
+func Control(messageType int, data []byte) Fault {
+	return func(f *FaultFrame) {
+		b := []byte{0x80 | byte(messageType)}
+		var mask byte
+		if f.masked {
+			mask = 0x80
+		}
+		b = appendLength(b, mask, uint64(len(data)))
+		if f.masked {
+			b = append(b, 0, 0, 0, 0)
+		}
+		b = append(b, data...)
+		f.Data = append(b, f.Data...)
+	}
+}
+
+// When applies faults to the frames for which cond returns true.
+func When(cond func(f *FaultFrame) bool, faults ...Fault) Fault {
+	return func(f *FaultFrame) {
+		if cond(f) {
+			for _, fault := range faults {
+				fault(f)
+			}
+		}
+	}
+}
+
+// Nth applies faults to the frame with index n.
+func Nth(n int, faults ...Fault) Fault {
+	return When(func(f *FaultFrame) bool { return f.Index == n }, faults...)
+}
+
+func appendLength(b []byte, mask byte, n uint64) []byte {
+	switch {
+	case n <= 125:
+		return append(b, mask|byte(n))
+	case n <= 0xffff:
+		b = append(b, mask|126, 0, 0)
+		binary.BigEndian.PutUint16(b[len(b)-2:], uint16(n))
+		return b
+	default:
+		b = append(b, mask|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(b[len(b)-8:], n)
+		return b
+	}
+}
+
+// headerLen returns the length of the header, including the masking key, of
+// the frame at the start of b.
+func headerLen(b []byte) (int, bool) {
+	if len(b) < 2 {
+		return 0, false
+	}
+	hdr := 2
+	switch b[1] & 0x7f {
+	case 126:
+		hdr += 2
+	case 127:
+		hdr += 8
+	}
+	if b[1]&0x80 != 0 {
+		hdr += 4
+	}
+	return hdr, len(b) >= hdr
+}
+
+// frameLen returns the length of the frame at the start of b.
+func frameLen(b []byte) (int, bool) {
+	hdr, ok := headerLen(b)
+	if !ok {
+		return 0, false
+	}
+	var size uint64
+	switch b[1] & 0x7f {
+	case 126:
+		size = uint64(binary.BigEndian.Uint16(b[2:]))
+	case 127:
+		size = binary.BigEndian.Uint64(b[2:])
+	default:
+		size = uint64(b[1] & 0x7f)
+	}
+	if size > uint64(len(b)-hdr) {
+		return 0, false
+	}
+	return hdr + int(size), true
+}
+
+var crlfcrlf = []byte("\r\n\r\n")
+
+// FaultConn is a network connection that injects faults into the websocket
+// frames written to it. The HTTP message of the opening handshake is
+// written unmodified.
+//
+// The connections of a Server are FaultConns. Use InjectFaults to set the
+// faults of a websocket connection.
+type FaultConn struct {
+	net.Conn
+
+	mu        sync.Mutex
+	faults    []Fault
+	handshake bool
+	buf       []byte
+	index     int
+	closed    bool
+}
+
+// NewFaultConn returns a connection that injects faults into the frames
+// written to c. The connection must start with the opening handshake.
+func NewFaultConn(c net.Conn) *FaultConn {
+	return &FaultConn{Conn: c, handshake: true}
+}
+
+// SetFaults sets the faults applied to the frames that are written after the
+// call. Each fault is applied in order to each frame.
+func (c *FaultConn) SetFaults(faults ...Fault) {
+	c.mu.Lock()
+	c.faults = faults
+	c.mu.Unlock()
+}
+
+// Write writes p to the connection. Frames are written when they are
+// complete.
+func (c *FaultConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, net.ErrClosed
+	}
+	c.buf = append(c.buf, p...)
+	if c.handshake {
+		i := bytes.Index(c.buf, crlfcrlf)
+		if i < 0 {
+			return len(p), nil
+		}
+		n := i + len(crlfcrlf)
+		if _, err := c.Conn.Write(c.buf[:n]); err != nil {
+			return 0, err
+		}
+		c.buf = c.buf[n:]
+		c.handshake = false
+	}
+	for {
+		n, ok := frameLen(c.buf)
+		if !ok {
+			break
+		}
+		f := &FaultFrame{
+			Index:  c.index,
+			Data:   c.buf[:n:n],
+			masked: c.buf[1]&0x80 != 0,
+			opcode: int(c.buf[0] & 0xf),
+			fin:    c.buf[0]&0x80 != 0,
+		}
+		c.buf = c.buf[n:]
+		c.index++
+		for _, fault := range c.faults {
+			fault(f)
+		}
+		if f.Delay > 0 {
+			time.Sleep(f.Delay)
+		}
+		if len(f.Data) > 0 {
+			if _, err := c.Conn.Write(f.Data); err != nil {
+				return 0, err
+			}
+		}
+		if f.Disconnect {
+			c.closed = true
+			c.Conn.Close()
+			return 0, net.ErrClosed
+		}
+	}
+	if len(c.buf) == 0 {
+		c.buf = nil
+	}
+	return len(p), nil
+}
+
+// InjectFaults sets the faults applied to the frames written by c. The
+// connection c must be a client or server connection of a Server.
+func InjectFaults(c *websocket.Conn, faults ...Fault) {
+	nc := c.NetConn()
+	for {
+		if fc, ok := nc.(*FaultConn); ok {
+			fc.SetFaults(faults...)
+			return
+		}
+		u, ok := nc.(interface{ NetConn() net.Conn })
+		if !ok {
+			panic("websockettest: InjectFaults on a connection without a FaultConn")
+		}
+		nc = u.NetConn()
+	}
+}
+
+// faultListener wraps accepted connections in FaultConns.
+type faultListener struct {
+	net.Listener
+}
+
+func (l faultListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return NewFaultConn(c), nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websockettest
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// readResults returns a handler that sends the result of reading one
+// message to the returned channel: the error text or the size of the
+// message.
+func readResults() (func(*websocket.Conn), chan string) {
+	results := make(chan string, 1)
+	return func(c *websocket.Conn) {
+		_, p, err := c.ReadMessage()
+		if err != nil {
+			results <- err.Error()
+			return
+		}
+		results <- fmt.Sprintf("%d bytes", len(p))
+	}, results
+}
+
+func TestClientFaults(t *testing.T) {
+	big := bytes.Repeat([]byte("x"), 10000)
+	tests := []struct {
+		name  string
+		fault Fault
+		data  []byte
+		want  string
+	}{
+		{"none", nil, []byte("hello"), "5 bytes"},
+		{"delay", Delay(time.Millisecond), []byte("hello"), "5 bytes"},
+		{"truncate", Truncate(4), []byte("hello"), "unexpected EOF"},
+		{"mask", CorruptMask(), []byte("hello"), "bad MASK"},
+		{"length", CorruptLength(3), []byte("hello"), "3 bytes"},
+		{"control", Control(websocket.CloseMessage, []byte{0x03, 0xe8}), []byte("hello"), "close 1000"},
+		{"mid-message", When(func(f *FaultFrame) bool { return f.Opcode() == 0 }, Disconnect()), big, "unexpected EOF"},
+	}
+	for _, tt := range tests {
+		handler, results := readResults()
+		_, c := NewServer(t, handler)
+		if tt.fault != nil {
+			InjectFaults(c, tt.fault)
+		}
+		w, _ := c.NextWriter(websocket.BinaryMessage)
+		for p := tt.data; len(p) > 0; p = p[len(p)/2+1:] {
+			w.Write(p[:len(p)/2+1])
+		}
+		w.Close()
+		if got := <-results; !strings.Contains(got, tt.want) {
+			t.Errorf("%s: ReadMessage() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	pings := make(chan string, 2)
+	_, c := NewServer(t, func(c *websocket.Conn) {
+		InjectFaults(c, Nth(1, Control(websocket.PingMessage, []byte("p"))))
+		c.WriteMessage(websocket.TextMessage, []byte("a"))
+		c.WriteMessage(websocket.TextMessage, []byte("b"))
+		c.ReadMessage()
+	})
+	c.SetPingHandler(func(data string) error {
+		pings <- data
+		return nil
+	})
+	var got []string
+	for i := 0; i < 2; i++ {
+		_, p, err := c.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(p))
+	}
+	if !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("messages = %q", got)
+	}
+	if p := <-pings; p != "p" {
+		t.Errorf("ping = %q, want %q", p, "p")
+	}
+}
+
+func TestDelay(t *testing.T) {
+	_, c := NewServer(t, func(c *websocket.Conn) { c.ReadMessage() })
+	InjectFaults(c, Delay(50*time.Millisecond))
+	start := time.Now()
+	c.WriteMessage(websocket.TextMessage, nil)
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Errorf("WriteMessage() took %v, want at least 50ms", d)
+	}
+}
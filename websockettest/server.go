@@ -0,0 +1,178 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package websockettest provides utilities for testing websocket code.
+//
+// NewServer starts a server that runs a handler for each connection and
+// returns a client connection to it:
+//
+//	func TestEcho(t *testing.T) {
+//		s, c := websockettest.NewServer(t, func(c *websocket.Conn) {
+//			for {
+//				mt, p, err := c.ReadMessage()
+//				if err != nil {
+//					return
+//				}
+//				c.WriteMessage(mt, p)
+//			}
+//		})
+//		...
+//	}
+//
+// When the test ends, the client connections are closed and the server
+// checks that the handlers return. Handlers that are still running after
+// HandlerTimeout, and handlers that panic, fail the test.
+//
+// The network connections of a Server are FaultConns. InjectFaults sets
+// faults, such as delayed writes, truncated frames and disconnects in the
+// middle of a message, on a client or server connection.
+package websockettest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// HandlerTimeout is the time that handlers have to return after the test
+// ends.
+var HandlerTimeout = 5 * time.Second
+
+// Server is a websocket server for tests.
+type Server struct {
+	// URL is the websocket URL of the server, for example
+	// "ws://127.0.0.1:1234".
+	URL string
+
+	// HTTP is the underlying HTTP test server.
+	HTTP *httptest.Server
+
+	// Dialer is used by Dial. The default dialer wraps the network
+	// connections in FaultConns.
+	Dialer *websocket.Dialer
+
+	t        testing.TB
+	upgrader *websocket.Upgrader
+	handler  func(*websocket.Conn)
+	handlers sync.WaitGroup
+
+	mu      sync.Mutex
+	clients []*websocket.Conn
+	running int
+}
+
+// NewServer starts a server that calls handler for each connection and
+// returns the server and a client connection to it. The server connection is
+// closed when handler returns. The server is closed when the test ends.
+func NewServer(t testing.TB, handler func(*websocket.Conn)) (*Server, *websocket.Conn) {
+	t.Helper()
+	return NewServerUpgrader(t, &websocket.Upgrader{}, handler)
+}
+
+// NewServerUpgrader is like NewServer but uses the given upgrader.
+func NewServerUpgrader(t testing.TB, upgrader *websocket.Upgrader, handler func(*websocket.Conn)) (*Server, *websocket.Conn) {
+	t.Helper()
+	s := &Server{
+		Dialer: &websocket.Dialer{
+			HandshakeTimeout: 5 * time.Second,
+			NetDialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				c, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+				if err != nil {
+					return nil, err
+				}
+				return NewFaultConn(c), nil
+			},
+		},
+		t:        t,
+		upgrader: upgrader,
+		handler:  handler,
+	}
+	s.HTTP = httptest.NewUnstartedServer(http.HandlerFunc(s.serveHTTP))
+	s.HTTP.Listener = faultListener{s.HTTP.Listener}
+	s.HTTP.Start()
+	s.URL = "ws" + strings.TrimPrefix(s.HTTP.URL, "http")
+	t.Cleanup(s.Close)
+	return s, s.Dial(nil)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	c, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.t.Errorf("websockettest: upgrade: %v", err)
+		return
+	}
+	s.handlers.Add(1)
+	s.mu.Lock()
+	s.running++
+	s.mu.Unlock()
+	defer func() {
+		if v := recover(); v != nil {
+			s.t.Errorf("websockettest: handler panic: %v\n%s", v, debug.Stack())
+		}
+		c.Close()
+		s.mu.Lock()
+		s.running--
+		s.mu.Unlock()
+		s.handlers.Done()
+	}()
+	s.handler(c)
+}
+
+// Dial returns a new client connection to the server. The test fails if the
+// connection cannot be established. The connection is closed when the test
+// ends.
+func (s *Server) Dial(header http.Header) *websocket.Conn {
+	s.t.Helper()
+	c, _, err := s.Dialer.Dial(s.URL, header)
+	if err != nil {
+		s.t.Fatalf("websockettest: dial: %v", err)
+	}
+	s.mu.Lock()
+	s.clients = append(s.clients, c)
+	s.mu.Unlock()
+	return c
+}
+
+// Close closes the client connections, waits for the handlers to return and
+// shuts down the server. Close is called automatically when the test ends.
+func (s *Server) Close() {
+	s.mu.Lock()
+	clients := s.clients
+	s.clients = nil
+	s.mu.Unlock()
+	for _, c := range clients {
+		c.Close()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.handlers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(HandlerTimeout):
+		s.mu.Lock()
+		n := s.running
+		s.mu.Unlock()
+		s.t.Errorf("websockettest: %s still running %v after the client connections were closed", plural(n, "handler"), HandlerTimeout)
+	}
+	s.HTTP.Close()
+}
+
+func plural(n int, s string) string {
+	if n == 1 {
+		return "1 " + s
+	}
+	return fmt.Sprintf("%d %ss", n, s)
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package websockettest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func echo(c *websocket.Conn) {
+	for {
+		mt, p, err := c.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.WriteMessage(mt, p)
+	}
+}
+
+func TestEcho(t *testing.T) {
+	s, c := NewServer(t, echo)
+	if !strings.HasPrefix(s.URL, "ws://") {
+		t.Errorf("URL = %q", s.URL)
+	}
+	for _, c := range []*websocket.Conn{c, s.Dial(nil)} {
+		c.WriteMessage(websocket.TextMessage, []byte("hello"))
+		if _, p, err := c.ReadMessage(); err != nil || string(p) != "hello" {
+			t.Errorf("ReadMessage() = %q, %v", p, err)
+		}
+	}
+}
+
+// recorder records failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	mu       sync.Mutex
+	failures []string
+	cleanups []func()
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.mu.Lock()
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+	r.mu.Unlock()
+}
+
+func (r *recorder) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+func (r *recorder) runCleanups() []string {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.failures
+}
+
+func TestHandlerChecks(t *testing.T) {
+	defer func(d time.Duration) { HandlerTimeout = d }(HandlerTimeout)
+	HandlerTimeout = 50 * time.Millisecond
+
+	release := make(chan struct{})
+	defer close(release)
+	tests := []struct {
+		name    string
+		handler func(*websocket.Conn)
+		want    string
+	}{
+		{"returns", echo, ""},
+		{"leaks", func(*websocket.Conn) { <-release }, "1 handler still running"},
+		{"panics", func(c *websocket.Conn) {
+			c.ReadMessage()
+			panic("boom")
+		}, "handler panic: boom"},
+	}
+	for _, tt := range tests {
+		r := &recorder{TB: t}
+		NewServer(r, tt.handler)
+		failures := r.runCleanups()
+		if tt.want == "" {
+			if len(failures) != 0 {
+				t.Errorf("%s: failures = %q, want none", tt.name, failures)
+			}
+			continue
+		}
+		if len(failures) != 1 || !strings.Contains(failures[0], tt.want) {
+			t.Errorf("%s: failures = %q, want %q", tt.name, failures, tt.want)
+		}
+	}
+}
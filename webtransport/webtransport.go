@@ -0,0 +1,220 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package webtransport presents WebTransport sessions with the message API
+// of websocket connections.
+//
+// The package does not implement WebTransport. The application establishes
+// the session with a WebTransport implementation and adapts it to the
+// Session interface. Conn then carries websocket style messages on a
+// bidirectional stream and exposes the session's datagrams, so a server can
+// offer websocket and WebTransport endpoints with one code path:
+//
+//	c, err := webtransport.Accept(ctx, sess)
+//	...
+//	for {
+//		messageType, p, err := c.ReadMessage()
+//		...
+//	}
+//
+// Conn implements the transport.Transport interface.
+//
+// Each message is sent on the stream as a type byte, a uvarint length and
+// the payload. The message types are those of the websocket package. Close
+// messages carry a close code and reason as in the websocket protocol.
+package webtransport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Session is a WebTransport session.
+type Session interface {
+	// OpenStream opens a bidirectional stream.
+	OpenStream(ctx context.Context) (io.ReadWriteCloser, error)
+
+	// AcceptStream waits for a bidirectional stream opened by the peer.
+	AcceptStream(ctx context.Context) (io.ReadWriteCloser, error)
+
+	// SendDatagram sends an unreliable datagram.
+	SendDatagram(p []byte) error
+
+	// ReceiveDatagram waits for a datagram from the peer.
+	ReceiveDatagram(ctx context.Context) ([]byte, error)
+
+	// CloseWithError closes the session with an application error code.
+	CloseWithError(code uint32, reason string) error
+}
+
+var (
+	errMessageType  = errors.New("webtransport: unsupported message type")
+	errReadLimit    = errors.New("webtransport: read limit exceeded")
+	errCloseSent    = errors.New("webtransport: close sent")
+	errBadCloseData = errors.New("webtransport: invalid close message")
+)
+
+// Conn is a message connection on a WebTransport session.
+//
+// Conn supports one concurrent reader and one concurrent writer, like
+// websocket.Conn. Close can be called concurrently with other methods.
+type Conn struct {
+	sess   Session
+	stream io.ReadWriteCloser
+	br     *bufio.Reader
+
+	readLimit int64
+
+	writeMu   sync.Mutex
+	closeSent bool
+
+	closeOnce sync.Once
+}
+
+// Dial opens the message stream on a session established by the client.
+func Dial(ctx context.Context, sess Session) (*Conn, error) {
+	stream, err := sess.OpenStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newConn(sess, stream), nil
+}
+
+// Accept accepts the message stream on a session established by the
+// server. The first bidirectional stream opened by the client is the message
+// stream.
+func Accept(ctx context.Context, sess Session) (*Conn, error) {
+	stream, err := sess.AcceptStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newConn(sess, stream), nil
+}
+
+func newConn(sess Session, stream io.ReadWriteCloser) *Conn {
+	return &Conn{sess: sess, stream: stream, br: bufio.NewReader(stream)}
+}
+
+// Session returns the underlying session.
+func (c *Conn) Session() Session {
+	return c.sess
+}
+
+// SetReadLimit sets the maximum size in bytes for a message read from the
+// peer. If a message exceeds the limit, ReadMessage returns an error. Zero
+// means no limit.
+func (c *Conn) SetReadLimit(limit int64) {
+	c.readLimit = limit
+}
+
+// ReadMessage reads the next message from the stream. If the peer sends a
+// close message, ReadMessage returns a *websocket.CloseError.
+func (c *Conn) ReadMessage() (messageType int, p []byte, err error) {
+	t, err := c.br.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	n, err := binary.ReadUvarint(c.br)
+	if err != nil {
+		return 0, nil, unexpectedEOF(err)
+	}
+	limit := uint64(math.MaxInt64)
+	if c.readLimit > 0 {
+		limit = uint64(c.readLimit)
+	}
+	if n > limit {
+		c.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseMessageTooBig, ""))
+		return 0, nil, errReadLimit
+	}
+	// Grow the payload as it arrives instead of allocating the length
+	// claimed by the peer.
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, c.br, int64(n)); err != nil {
+		return 0, nil, unexpectedEOF(err)
+	}
+	p = buf.Bytes()
+
+	switch messageType = int(t); messageType {
+	case websocket.TextMessage, websocket.BinaryMessage:
+		return messageType, p, nil
+	case websocket.CloseMessage:
+		ce := &websocket.CloseError{Code: websocket.CloseNoStatusReceived}
+		if len(p) == 1 {
+			return 0, nil, errBadCloseData
+		}
+		if len(p) >= 2 {
+			ce.Code = int(binary.BigEndian.Uint16(p))
+			ce.Text = string(p[2:])
+		}
+		// Reply to the close message as the websocket protocol does.
+		c.WriteMessage(websocket.CloseMessage, p)
+		return 0, nil, ce
+	default:
+		return 0, nil, errMessageType
+	}
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// WriteMessage writes a message to the stream. The messageType is
+// websocket.TextMessage, websocket.BinaryMessage or websocket.CloseMessage.
+// Ping and pong messages are not supported because QUIC has its own
+// keepalive mechanism.
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	switch messageType {
+	case websocket.TextMessage, websocket.BinaryMessage, websocket.CloseMessage:
+	default:
+		return errMessageType
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return errCloseSent
+	}
+	if messageType == websocket.CloseMessage {
+		c.closeSent = true
+	}
+	b := make([]byte, 1+binary.MaxVarintLen64, 1+binary.MaxVarintLen64+len(data))
+	b[0] = byte(messageType)
+	b = b[:1+binary.PutUvarint(b[1:], uint64(len(data)))]
+	b = append(b, data...)
+	_, err := c.stream.Write(b)
+	return err
+}
+
+// WriteDatagram sends an unreliable, unordered datagram.
+func (c *Conn) WriteDatagram(p []byte) error {
+	return c.sess.SendDatagram(p)
+}
+
+// ReadDatagram waits for a datagram from the peer.
+func (c *Conn) ReadDatagram(ctx context.Context) ([]byte, error) {
+	return c.sess.ReceiveDatagram(ctx)
+}
+
+// Close sends a normal closure close message if no close message was sent,
+// closes the stream and closes the session.
+func (c *Conn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		c.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		c.stream.Close()
+		err = c.sess.CloseWithError(0, "")
+	})
+	return err
+}
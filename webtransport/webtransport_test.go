@@ -0,0 +1,177 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webtransport
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/gorilla/websocket/transport"
+)
+
+var _ transport.Transport = (*Conn)(nil)
+
+// fakeSession is an in-memory session. Streams opened on one end are
+// accepted on the other.
+type fakeSession struct {
+	streams   chan io.ReadWriteCloser
+	peer      *fakeSession
+	datagrams chan []byte
+	closed    chan uint32
+}
+
+func newFakeSessions() (client, server *fakeSession) {
+	client = &fakeSession{streams: make(chan io.ReadWriteCloser, 1), datagrams: make(chan []byte, 8), closed: make(chan uint32, 1)}
+	server = &fakeSession{streams: make(chan io.ReadWriteCloser, 1), datagrams: make(chan []byte, 8), closed: make(chan uint32, 1)}
+	client.peer, server.peer = server, client
+	return client, server
+}
+
+func (s *fakeSession) OpenStream(ctx context.Context) (io.ReadWriteCloser, error) {
+	a, b := net.Pipe()
+	s.peer.streams <- b
+	return a, nil
+}
+
+func (s *fakeSession) AcceptStream(ctx context.Context) (io.ReadWriteCloser, error) {
+	select {
+	case st := <-s.streams:
+		return st, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *fakeSession) SendDatagram(p []byte) error {
+	s.peer.datagrams <- append([]byte(nil), p...)
+	return nil
+}
+
+func (s *fakeSession) ReceiveDatagram(ctx context.Context) ([]byte, error) {
+	select {
+	case p := <-s.datagrams:
+		return p, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *fakeSession) CloseWithError(code uint32, reason string) error {
+	s.closed <- code
+	return nil
+}
+
+func newTestConns(t *testing.T) (client, server *Conn) {
+	cs, ss := newFakeSessions()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client, err := Dial(ctx, cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err = Accept(ctx, ss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, server
+}
+
+func TestMessages(t *testing.T) {
+	client, server := newTestConns(t)
+	messages := []struct {
+		mt   int
+		data []byte
+	}{
+		{websocket.TextMessage, []byte("hello")},
+		{websocket.BinaryMessage, nil},
+		{websocket.BinaryMessage, make([]byte, 70000)},
+	}
+	go func() {
+		for _, m := range messages {
+			client.WriteMessage(m.mt, m.data)
+		}
+	}()
+	for _, m := range messages {
+		mt, p, err := server.ReadMessage()
+		if err != nil || mt != m.mt || len(p) != len(m.data) {
+			t.Fatalf("ReadMessage() = %d, %d bytes, %v, want %d, %d bytes", mt, len(p), err, m.mt, len(m.data))
+		}
+	}
+	if err := client.WriteMessage(websocket.PingMessage, nil); err != errMessageType {
+		t.Errorf("WriteMessage(PingMessage) error = %v, want %v", err, errMessageType)
+	}
+}
+
+func TestClose(t *testing.T) {
+	client, server := newTestConns(t)
+	done := make(chan error, 2)
+	go func() {
+		done <- client.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(4000, "bye"))
+		// The reply to the close message.
+		_, _, err := client.ReadMessage()
+		done <- err
+	}()
+	_, _, err := server.ReadMessage()
+	if !websocket.IsCloseError(err, 4000) || err.(*websocket.CloseError).Text != "bye" {
+		t.Fatalf("ReadMessage() error = %v, want close 4000", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; !websocket.IsCloseError(err, 4000) {
+		t.Errorf("client ReadMessage() error = %v, want close 4000", err)
+	}
+	if err := client.WriteMessage(websocket.TextMessage, nil); err != errCloseSent {
+		t.Errorf("WriteMessage after close error = %v, want %v", err, errCloseSent)
+	}
+}
+
+func TestReadLimit(t *testing.T) {
+	client, server := newTestConns(t)
+	server.SetReadLimit(10)
+	go client.WriteMessage(websocket.BinaryMessage, make([]byte, 11))
+	go client.ReadMessage()
+	if _, _, err := server.ReadMessage(); err != errReadLimit {
+		t.Errorf("ReadMessage() error = %v, want %v", err, errReadLimit)
+	}
+}
+
+type readStream struct {
+	io.Reader
+	io.Writer
+}
+
+func (readStream) Close() error { return nil }
+
+func TestLargeMessageLength(t *testing.T) {
+	// The payload is not allocated before it arrives.
+	stream := readStream{strings.NewReader("\x02\xff\xff\xff\xff\xff\xff\xff\xff\x01abc"), io.Discard}
+	c := newConn(nil, stream)
+	if _, _, err := c.ReadMessage(); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadMessage() error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestDatagrams(t *testing.T) {
+	client, server := newTestConns(t)
+	if err := client.WriteDatagram([]byte("d")); err != nil {
+		t.Fatal(err)
+	}
+	p, err := server.ReadDatagram(context.Background())
+	if err != nil || string(p) != "d" {
+		t.Errorf("ReadDatagram() = %q, %v", p, err)
+	}
+
+	go server.ReadMessage()
+	client.Close()
+	if code := <-client.Session().(*fakeSession).closed; code != 0 {
+		t.Errorf("CloseWithError code = %d, want 0", code)
+	}
+}
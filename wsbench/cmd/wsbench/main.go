@@ -0,0 +1,70 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command wsbench load tests a websocket echo endpoint.
+//
+// Usage:
+//
+//	wsbench [flags] url
+//
+// For example, to open 1000 connections over ten seconds and send one 512
+// byte message per second on each connection for a minute:
+//
+//	wsbench -c 1000 -ramp 10s -d 1m -rate 1 -size 512 ws://localhost:8080/echo
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/gorilla/websocket"
+	"github.com/gorilla/websocket/wsbench"
+)
+
+var (
+	connections = flag.Int("c", 1, "number of connections")
+	rampUp      = flag.Duration("ramp", 0, "time over which the connections are opened")
+	duration    = flag.Duration("d", 0, "time to send messages after the ramp-up (default 10s)")
+	rate        = flag.Float64("rate", 0, "messages per second per connection; 0 sends after each echo")
+	size        = flag.Int("size", 64, "message size in bytes")
+	text        = flag.Bool("text", false, "send text messages instead of binary messages")
+	compress    = flag.Bool("compress", false, "negotiate per-message compression")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: wsbench [flags] url\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg := &wsbench.Config{
+		URL:               flag.Arg(0),
+		Connections:       *connections,
+		RampUp:            *rampUp,
+		Duration:          *duration,
+		Rate:              *rate,
+		PayloadSize:       *size,
+		EnableCompression: *compress,
+	}
+	if *text {
+		cfg.MessageType = websocket.TextMessage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	res, err := wsbench.Run(ctx, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	res.WriteReport(os.Stdout)
+}
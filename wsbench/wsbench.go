@@ -0,0 +1,414 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package wsbench load tests websocket servers.
+//
+// Run opens a number of connections to an echo endpoint, sends messages on
+// each connection and measures the round trip time of each message:
+//
+//	res, err := wsbench.Run(ctx, &wsbench.Config{
+//		URL:         "ws://localhost:8080/echo",
+//		Connections: 1000,
+//		RampUp:      10 * time.Second,
+//		Duration:    time.Minute,
+//		Rate:        1,
+//		PayloadSize: 512,
+//	})
+//	...
+//	res.WriteReport(os.Stdout)
+//
+// The server must echo each message back on the same connection. The
+// command in the cmd/wsbench directory runs Run from the command line.
+package wsbench
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Config configures a load test.
+type Config struct {
+	// URL is the websocket URL of the echo endpoint.
+	URL string
+
+	// Header is the request header sent in the handshake.
+	Header http.Header
+
+	// Dialer is the dialer for the connections. If Dialer is nil, a copy of
+	// websocket.DefaultDialer is used.
+	Dialer *websocket.Dialer
+
+	// Connections is the number of connections. If Connections is zero, one
+	// connection is used.
+	Connections int
+
+	// RampUp is the time over which the connections are opened, at evenly
+	// spaced intervals.
+	RampUp time.Duration
+
+	// Duration is the time to send messages after the ramp-up. If Duration
+	// is zero, ten seconds is used.
+	Duration time.Duration
+
+	// Rate is the number of messages per second sent on each connection. If
+	// Rate is zero, each connection sends the next message when it receives
+	// the echo of the previous message.
+	Rate float64
+
+	// PayloadSize is the size of the messages in bytes. Messages start with
+	// a timestamp of 8 bytes, or 16 hex digits in text messages, which is
+	// the minimum size.
+	PayloadSize int
+
+	// MessageType is websocket.TextMessage or websocket.BinaryMessage. If
+	// MessageType is zero, binary messages are sent.
+	MessageType int
+
+	// EnableCompression specifies that the connections negotiate
+	// per-message compression.
+	EnableCompression bool
+}
+
+// Result is the result of a load test.
+type Result struct {
+	// Connections is the number of connections that were opened.
+	Connections int
+
+	// Sent is the number of messages sent.
+	Sent int64
+
+	// Received is the number of echoed messages received.
+	Received int64
+
+	// Bytes is the number of payload bytes received.
+	Bytes int64
+
+	// Elapsed is the duration of the test.
+	Elapsed time.Duration
+
+	// Errors counts the errors by kind, such as "dial: status 503" or
+	// "read: close 1006".
+	Errors map[string]int
+
+	latencies []time.Duration // sorted
+}
+
+// Latency returns the p-th percentile of the message round trip times, for
+// 0 <= p <= 100. Latency returns zero if no messages were received.
+func (r *Result) Latency(p float64) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+	i := int(p / 100 * float64(len(r.latencies)-1))
+	if i < 0 {
+		i = 0
+	} else if i >= len(r.latencies) {
+		i = len(r.latencies) - 1
+	}
+	return r.latencies[i]
+}
+
+// WriteReport writes a summary of the result to w.
+func (r *Result) WriteReport(w io.Writer) error {
+	secs := r.Elapsed.Seconds()
+	if secs == 0 {
+		secs = 1
+	}
+	fmt.Fprintf(w, "connections: %d\n", r.Connections)
+	fmt.Fprintf(w, "messages:    %d sent, %d received (%.1f/s)\n", r.Sent, r.Received, float64(r.Received)/secs)
+	fmt.Fprintf(w, "throughput:  %.1f KiB/s\n", float64(r.Bytes)/1024/secs)
+	fmt.Fprintf(w, "latency:     p50 %v, p90 %v, p99 %v, max %v\n",
+		r.Latency(50), r.Latency(90), r.Latency(99), r.Latency(100))
+	kinds := make([]string, 0, len(r.Errors))
+	for k := range r.Errors {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	for _, k := range kinds {
+		fmt.Fprintf(w, "error:       %s: %d\n", k, r.Errors[k])
+	}
+	_, err := fmt.Fprintf(w, "elapsed:     %v\n", r.Elapsed)
+	return err
+}
+
+// Run runs a load test. Run returns an error if the configuration is
+// invalid; failures of connections are counted in the result. Run returns
+// early with the partial result if ctx is done.
+func Run(ctx context.Context, cfg *Config) (*Result, error) {
+	n := cfg.Connections
+	if n <= 0 {
+		n = 1
+	}
+	duration := cfg.Duration
+	if duration == 0 {
+		duration = 10 * time.Second
+	}
+	mt := cfg.MessageType
+	switch mt {
+	case 0:
+		mt = websocket.BinaryMessage
+	case websocket.BinaryMessage, websocket.TextMessage:
+	default:
+		return nil, errors.New("wsbench: invalid message type")
+	}
+	size := cfg.PayloadSize
+	if minSize := timestampLen(mt); size < minSize {
+		size = minSize
+	}
+	var d websocket.Dialer
+	if cfg.Dialer != nil {
+		d = *cfg.Dialer
+	} else {
+		d = *websocket.DefaultDialer
+	}
+	if cfg.EnableCompression {
+		d.EnableCompression = true
+	}
+
+	start := time.Now()
+	ctx, cancel := context.WithDeadline(ctx, start.Add(cfg.RampUp+duration))
+	defer cancel()
+
+	res := &Result{Errors: map[string]int{}}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		delay := time.Duration(int64(cfg.RampUp) * int64(i) / int64(n))
+		wg.Add(1)
+		go func(delay time.Duration) {
+			defer wg.Done()
+			t := time.NewTimer(delay)
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				t.Stop()
+				return
+			}
+			s := runConn(ctx, &d, cfg, mt, size)
+			mu.Lock()
+			defer mu.Unlock()
+			if s.connected {
+				res.Connections++
+			}
+			res.Sent += s.sent
+			res.Received += int64(len(s.latencies))
+			res.Bytes += s.bytes
+			res.latencies = append(res.latencies, s.latencies...)
+			if s.err != "" {
+				res.Errors[s.err]++
+			}
+		}(delay)
+	}
+	wg.Wait()
+	res.Elapsed = time.Since(start)
+	sort.Slice(res.latencies, func(i, j int) bool { return res.latencies[i] < res.latencies[j] })
+	return res, nil
+}
+
+const timestampSize = 8
+
+// connStats are the statistics of one connection.
+type connStats struct {
+	connected bool
+	sent      int64
+	bytes     int64
+	latencies []time.Duration
+	err       string // kind of the first error
+}
+
+func runConn(ctx context.Context, d *websocket.Dialer, cfg *Config, mt, size int) *connStats {
+	s := &connStats{}
+	c, resp, err := d.DialContext(ctx, cfg.URL, cfg.Header)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.err = errorKind("dial", err, resp)
+		}
+		return s
+	}
+	s.connected = true
+	defer c.Close()
+
+	// Close the connection when the test ends to unblock reads and writes.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+				time.Now().Add(time.Second))
+			c.Close()
+		case <-done:
+		}
+	}()
+
+	payload := make([]byte, size)
+	if mt == websocket.TextMessage {
+		for i := range payload {
+			payload[i] = 'a'
+		}
+	}
+	if cfg.Rate > 0 {
+		err = s.runOpenLoop(ctx, c, cfg.Rate, mt, payload)
+	} else {
+		err = s.runClosedLoop(c, mt, payload)
+	}
+	if err != nil && ctx.Err() == nil {
+		s.err = err.Error()
+	}
+	return s
+}
+
+// opError is an error with the kind of the failed operation.
+type opError string
+
+func (e opError) Error() string { return string(e) }
+
+// runClosedLoop sends a message after receiving the echo of the previous
+// message.
+func (s *connStats) runClosedLoop(c *websocket.Conn, mt int, payload []byte) error {
+	for {
+		putTimestamp(payload, mt, time.Now())
+		if err := c.WriteMessage(mt, payload); err != nil {
+			return opError(errorKind("write", err, nil))
+		}
+		s.sent++
+		if err := s.readEcho(c); err != nil {
+			return err
+		}
+	}
+}
+
+// runOpenLoop sends messages at a fixed rate and receives the echoes
+// concurrently.
+func (s *connStats) runOpenLoop(ctx context.Context, c *websocket.Conn, rate float64, mt int, payload []byte) error {
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			if err := s.readEcho(c); err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+	interval := time.Duration(float64(time.Second) / rate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var err error
+	for err == nil {
+		putTimestamp(payload, mt, time.Now())
+		if werr := c.WriteMessage(mt, payload); werr != nil {
+			err = opError(errorKind("write", werr, nil))
+			break
+		}
+		s.sent++
+		select {
+		case <-ticker.C:
+		case err = <-readErr:
+			return err
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	c.Close()
+	if rerr := <-readErr; err == nil {
+		err = rerr
+	}
+	return err
+}
+
+// readEcho reads an echoed message and records its round trip time. It is
+// called by one goroutine at a time; the fields it updates are read after
+// the goroutine is done.
+func (s *connStats) readEcho(c *websocket.Conn) error {
+	mt, p, err := c.ReadMessage()
+	if err != nil {
+		return opError(errorKind("read", err, nil))
+	}
+	sent, ok := timestamp(p, mt)
+	if !ok {
+		return opError("read: unexpected message")
+	}
+	s.latencies = append(s.latencies, time.Since(sent))
+	s.bytes += int64(len(p))
+	return nil
+}
+
+func timestampLen(mt int) int {
+	if mt == websocket.TextMessage {
+		return 2 * timestampSize
+	}
+	return timestampSize
+}
+
+// putTimestamp writes t at the start of the payload, as hex digits in text
+// messages.
+func putTimestamp(payload []byte, mt int, t time.Time) {
+	v := uint64(t.UnixNano())
+	if mt != websocket.TextMessage {
+		binary.BigEndian.PutUint64(payload, v)
+		return
+	}
+	const digits = "0123456789abcdef"
+	for i := 2*timestampSize - 1; i >= 0; i-- {
+		payload[i] = digits[v&0xf]
+		v >>= 4
+	}
+}
+
+func timestamp(p []byte, mt int) (time.Time, bool) {
+	if len(p) < timestampLen(mt) {
+		return time.Time{}, false
+	}
+	if mt != websocket.TextMessage {
+		return time.Unix(0, int64(binary.BigEndian.Uint64(p))), true
+	}
+	var v uint64
+	for _, b := range p[:2*timestampSize] {
+		switch {
+		case '0' <= b && b <= '9':
+			v = v<<4 | uint64(b-'0')
+		case 'a' <= b && b <= 'f':
+			v = v<<4 | uint64(b-'a'+10)
+		default:
+			return time.Time{}, false
+		}
+	}
+	return time.Unix(0, int64(v)), true
+}
+
+// errorKind returns a low cardinality description of err for the error
+// breakdown.
+func errorKind(op string, err error, resp *http.Response) string {
+	var ce *websocket.CloseError
+	var ne net.Error
+	switch {
+	case errors.As(err, &ce):
+		return fmt.Sprintf("%s: close %d", op, ce.Code)
+	case errors.Is(err, websocket.ErrBadHandshake) && resp != nil:
+		return fmt.Sprintf("%s: status %d", op, resp.StatusCode)
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return op + ": connection refused"
+	case errors.Is(err, syscall.ECONNRESET):
+		return op + ": connection reset"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &ne) && ne.Timeout():
+		return op + ": timeout"
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return op + ": unexpected EOF"
+	}
+	return op + ": " + err.Error()
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wsbench
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func newEchoServer(t *testing.T) string {
+	upgrader := websocket.Upgrader{EnableCompression: true}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		for {
+			mt, p, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := c.WriteMessage(mt, p); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(s.Close)
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+func TestRun(t *testing.T) {
+	url := newEchoServer(t)
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"closed loop", Config{Connections: 4, PayloadSize: 100}},
+		{"open loop", Config{Connections: 4, Rate: 200, RampUp: 20 * time.Millisecond}},
+		{"text compressed", Config{Connections: 2, MessageType: websocket.TextMessage, EnableCompression: true, PayloadSize: 1000}},
+	}
+	for _, tt := range tests {
+		cfg := tt.cfg
+		cfg.URL = url
+		cfg.Duration = 100 * time.Millisecond
+		res, err := Run(context.Background(), &cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if res.Connections != cfg.Connections || res.Received == 0 || res.Sent < res.Received || len(res.Errors) != 0 {
+			t.Errorf("%s: result = %+v", tt.name, res)
+		}
+		if res.Latency(0) > res.Latency(50) || res.Latency(50) > res.Latency(100) || res.Latency(100) == 0 {
+			t.Errorf("%s: latencies p0 %v, p50 %v, p100 %v", tt.name, res.Latency(0), res.Latency(50), res.Latency(100))
+		}
+		if res.Elapsed < cfg.Duration {
+			t.Errorf("%s: elapsed %v, want at least %v", tt.name, res.Elapsed, cfg.Duration)
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "busy", http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+	res, err := Run(context.Background(), &Config{
+		URL:         "ws" + strings.TrimPrefix(s.URL, "http"),
+		Connections: 3,
+		Duration:    50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Connections != 0 || res.Errors["dial: status 503"] != 3 {
+		t.Errorf("result = %+v, want 3 dial errors", res)
+	}
+	var buf bytes.Buffer
+	res.WriteReport(&buf)
+	if !strings.Contains(buf.String(), "error:       dial: status 503: 3\n") {
+		t.Errorf("report = %s", buf.String())
+	}
+
+	if _, err := Run(context.Background(), &Config{MessageType: websocket.PingMessage}); err == nil {
+		t.Error("Run with ping message type succeeded")
+	}
+}
+
+func TestErrorKind(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&websocket.CloseError{Code: 1006}, "read: close 1006"},
+		{io.ErrUnexpectedEOF, "read: unexpected EOF"},
+		{context.DeadlineExceeded, "read: timeout"},
+		{errors.New("other"), "read: other"},
+	}
+	for _, tt := range tests {
+		if got := errorKind("read", tt.err, nil); got != tt.want {
+			t.Errorf("errorKind(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	now := time.Unix(0, time.Now().UnixNano())
+	for _, mt := range []int{websocket.BinaryMessage, websocket.TextMessage} {
+		p := make([]byte, timestampLen(mt))
+		putTimestamp(p, mt, now)
+		if got, ok := timestamp(p, mt); !ok || !got.Equal(now) {
+			t.Errorf("message type %d: timestamp = %v, %v, want %v", mt, got, ok, now)
+		}
+	}
+	if _, ok := timestamp([]byte("not a timestamp!"), websocket.TextMessage); ok {
+		t.Error("timestamp of text accepted")
+	}
+}
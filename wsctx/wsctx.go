@@ -0,0 +1,211 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package wsctx is a context-first API for websocket connections.
+//
+// Every blocking operation takes a context. The deadline of the context
+// bounds the operation and canceling the context interrupts it, so there are
+// no read and write deadlines to set and reset. Reads and writes are safe to
+// call concurrently with each other and with Close:
+//
+//	c, err := wsctx.Accept(w, r)
+//	if err != nil {
+//		return
+//	}
+//	defer c.CloseNow()
+//	for {
+//		ctx, cancel := context.WithTimeout(r.Context(), time.Minute)
+//		mt, p, err := c.Read(ctx)
+//		cancel()
+//		if err != nil {
+//			return
+//		}
+//		if err := c.Write(ctx, mt, p); err != nil {
+//			return
+//		}
+//	}
+//
+// An operation interrupted by its context leaves the connection in an
+// unknown state, so the connection is closed. The error returned by the
+// operation wraps the context's error:
+//
+//	if errors.Is(err, context.DeadlineExceeded) {
+//		// The peer was idle for a minute.
+//	}
+//
+// The package is a thin layer over the websocket package. Use Underlying for
+// the features that the package does not wrap.
+package wsctx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// closeTimeout is the time allowed to send the close message in Close.
+const closeTimeout = 5 * time.Second
+
+// ErrClosed is returned by Close and CloseNow when the connection is
+// already closed.
+var ErrClosed = errors.New("wsctx: connection already closed")
+
+// Conn is a websocket connection with context-first methods. The methods of
+// Conn are safe for concurrent use.
+type Conn struct {
+	ws *websocket.Conn
+
+	readMu  chan struct{}
+	writeMu chan struct{}
+
+	closeOnce sync.Once
+}
+
+// NewConn returns a Conn for ws. The application must not use the read and
+// write methods or deadlines of ws after calling NewConn.
+func NewConn(ws *websocket.Conn) *Conn {
+	c := &Conn{
+		ws:      ws,
+		readMu:  make(chan struct{}, 1),
+		writeMu: make(chan struct{}, 1),
+	}
+	return c
+}
+
+// Accept upgrades the HTTP server connection to the websocket protocol with
+// an upgrader configured by the options. See websocket.NewUpgrader.
+func Accept(w http.ResponseWriter, r *http.Request, opts ...websocket.UpgraderOption) (*Conn, error) {
+	ws, err := websocket.NewUpgrader(opts...).Upgrade(w, r, nil)
+	if err != nil {
+		return nil, err
+	}
+	return NewConn(ws), nil
+}
+
+// Dial creates a client connection with a dialer configured by the options.
+// The context bounds the opening handshake only. See websocket.Dial.
+func Dial(ctx context.Context, urlStr string, opts ...websocket.DialerOption) (*Conn, *http.Response, error) {
+	ws, resp, err := websocket.Dial(ctx, urlStr, opts...)
+	if err != nil {
+		return nil, resp, err
+	}
+	return NewConn(ws), resp, nil
+}
+
+// Underlying returns the websocket connection. Use it for methods such as
+// Subprotocol and SetReadLimit that do not block.
+func (c *Conn) Underlying() *websocket.Conn {
+	return c.ws
+}
+
+// Read reads the next data message. The message type is either
+// websocket.TextMessage or websocket.BinaryMessage. Control messages are
+// handled while reading.
+func (c *Conn) Read(ctx context.Context) (messageType int, p []byte, err error) {
+	if err := lock(ctx, c.readMu); err != nil {
+		return 0, nil, err
+	}
+	defer unlock(c.readMu)
+
+	end := c.watch(ctx, c.ws.SetReadDeadline)
+	messageType, p, err = c.ws.ReadMessage()
+	if err = end(err); err != nil {
+		return 0, nil, err
+	}
+	return messageType, p, nil
+}
+
+// Write writes a message. The message type is websocket.TextMessage or
+// websocket.BinaryMessage.
+func (c *Conn) Write(ctx context.Context, messageType int, p []byte) error {
+	if err := lock(ctx, c.writeMu); err != nil {
+		return err
+	}
+	defer unlock(c.writeMu)
+
+	end := c.watch(ctx, c.ws.SetWriteDeadline)
+	return end(c.ws.WriteMessage(messageType, p))
+}
+
+// Close sends a close message with the code and reason and closes the
+// connection. Close does not wait for the close message of the peer.
+func (c *Conn) Close(code int, reason string) error {
+	err := ErrClosed
+	c.closeOnce.Do(func() {
+		err = c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(closeTimeout))
+		if errors.Is(err, websocket.ErrCloseSent) {
+			err = nil
+		}
+		if cerr := c.ws.Close(); err == nil {
+			err = cerr
+		}
+	})
+	return err
+}
+
+// CloseNow closes the connection without sending a close message.
+func (c *Conn) CloseNow() error {
+	err := ErrClosed
+	c.closeOnce.Do(func() { err = c.ws.Close() })
+	return err
+}
+
+// watch applies the deadline of ctx with setDeadline and interrupts the
+// operation by closing the connection when ctx is done. The returned
+// function ends the watch. It returns the error of the operation, wrapped
+// with the context's error if the context ended the operation.
+func (c *Conn) watch(ctx context.Context, setDeadline func(time.Time) error) func(error) error {
+	deadline, hasDeadline := ctx.Deadline()
+	_ = setDeadline(deadline)
+	if ctx.Done() == nil {
+		return func(err error) error { return err }
+	}
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		select {
+		case <-ctx.Done():
+			_ = c.CloseNow()
+		case <-done:
+		}
+	}()
+	return func(err error) error {
+		close(done)
+		<-finished
+		if err == nil {
+			return nil
+		}
+		var ne net.Error
+		if hasDeadline && errors.As(err, &ne) && ne.Timeout() {
+			// The deadline of ctx passed. Wait for ctx to report it.
+			<-ctx.Done()
+		}
+		if ctx.Err() == nil {
+			return err
+		}
+		_ = c.CloseNow()
+		return fmt.Errorf("wsctx: %w: %w", ctx.Err(), err)
+	}
+}
+
+func lock(ctx context.Context, mu chan struct{}) error {
+	select {
+	case mu <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func unlock(mu chan struct{}) {
+	<-mu
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wsctx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newServer returns a server that runs handler on accepted connections and
+// a client connection to the server.
+func newServer(t *testing.T, handler func(c *Conn)) (*httptest.Server, *Conn) {
+	t.Helper()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := Accept(w, r)
+		if err != nil {
+			t.Errorf("Accept() error = %v", err)
+			return
+		}
+		defer c.CloseNow()
+		handler(c)
+	}))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, _, err := Dial(ctx, "ws"+strings.TrimPrefix(s.URL, "http"))
+	if err != nil {
+		s.Close()
+		t.Fatal(err)
+	}
+	return s, c
+}
+
+func echo(c *Conn) {
+	ctx := context.Background()
+	for {
+		mt, p, err := c.Read(ctx)
+		if err != nil {
+			return
+		}
+		if err := c.Write(ctx, mt, p); err != nil {
+			return
+		}
+	}
+}
+
+func TestEcho(t *testing.T) {
+	s, c := newServer(t, echo)
+	defer s.Close()
+	defer c.CloseNow()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.Write(ctx, websocket.TextMessage, []byte("hello")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	mt, p, err := c.Read(ctx)
+	if err != nil || mt != websocket.TextMessage || string(p) != "hello" {
+		t.Fatalf("Read() = %d, %q, %v, want text message hello", mt, p, err)
+	}
+	if err := c.Close(websocket.CloseNormalClosure, ""); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if err := c.Close(websocket.CloseNormalClosure, ""); err != ErrClosed {
+		t.Errorf("second Close() error = %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestReadContext(t *testing.T) {
+	block := make(chan struct{})
+	s, c := newServer(t, func(*Conn) { <-block })
+	defer s.Close()
+	defer close(block)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := c.Read(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Read() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// The interrupted read closed the connection.
+	if err := c.Write(context.Background(), websocket.TextMessage, []byte("x")); err == nil {
+		t.Error("Write() after interrupted Read succeeded")
+	}
+}
+
+func TestReadCancel(t *testing.T) {
+	block := make(chan struct{})
+	s, c := newServer(t, func(*Conn) { <-block })
+	defer s.Close()
+	defer close(block)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		_, _, err := c.Read(ctx)
+		errc <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-errc:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Read() error = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Read() not interrupted")
+	}
+}
+
+func TestConcurrentWritesAndClose(t *testing.T) {
+	s, c := newServer(t, echo)
+	defer s.Close()
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if err := c.Write(ctx, websocket.BinaryMessage, []byte("message")); err != nil {
+					return
+				}
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			if _, _, err := c.Read(ctx); err != nil {
+				return
+			}
+		}
+	}()
+	time.Sleep(5 * time.Millisecond)
+	c.Close(websocket.CloseGoingAway, "")
+	c.CloseNow()
+	wg.Wait()
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wsframe
+
+import (
+	"encoding/binary"
+	"errors"
+	"unicode/utf8"
+)
+
+// CloseNoStatus is the code reported for a close frame without a payload.
+// It is not sent in close frames.
+const CloseNoStatus = 1005
+
+// MaxCloseReasonSize is the maximum size of the reason of a close frame.
+const MaxCloseReasonSize = MaxControlPayloadSize - 2
+
+var (
+	// ErrClosePayload is returned by ParseClose for a payload of one byte.
+	ErrClosePayload = errors.New("wsframe: close payload of length 1")
+
+	// ErrCloseCode is returned by ParseClose for a code that is not valid
+	// in a close frame.
+	ErrCloseCode = errors.New("wsframe: invalid close code")
+
+	// ErrCloseReason is returned by ParseClose for a reason that is not
+	// valid UTF-8.
+	ErrCloseReason = errors.New("wsframe: invalid UTF-8 in close reason")
+)
+
+// ValidCloseCode reports whether the code is valid in a close frame: a
+// code defined by RFC 6455 or registered with IANA that may be sent, or a
+// code in the range 3000-4999 for libraries, frameworks and applications.
+func ValidCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1013:
+		return true
+	case code >= 3000 && code <= 4999:
+		return true
+	}
+	return false
+}
+
+// ParseClose decodes the payload of a close frame. The reason is a subslice
+// of p. ParseClose returns the code CloseNoStatus for an empty payload.
+func ParseClose(p []byte) (code int, reason []byte, err error) {
+	switch {
+	case len(p) == 0:
+		return CloseNoStatus, nil, nil
+	case len(p) == 1:
+		return 0, nil, ErrClosePayload
+	}
+	code = int(binary.BigEndian.Uint16(p))
+	if !ValidCloseCode(code) {
+		return code, nil, ErrCloseCode
+	}
+	if !utf8.Valid(p[2:]) {
+		return code, nil, ErrCloseReason
+	}
+	return code, p[2:], nil
+}
+
+// AppendClose appends the payload of a close frame to b and returns the
+// extended slice. A reason longer than MaxCloseReasonSize is truncated
+// without splitting a UTF-8 encoded rune, so that a valid UTF-8 reason
+// stays valid. AppendClose appends nothing for the code CloseNoStatus.
+func AppendClose(b []byte, code int, reason string) []byte {
+	if code == CloseNoStatus {
+		return b
+	}
+	if len(reason) > MaxCloseReasonSize {
+		i := MaxCloseReasonSize
+		for i > 0 && !utf8.RuneStart(reason[i]) {
+			i--
+		}
+		reason = reason[:i]
+	}
+	b = binary.BigEndian.AppendUint16(b, uint16(code))
+	return append(b, reason...)
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wsframe
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseClose(t *testing.T) {
+	tests := []struct {
+		name   string
+		p      []byte
+		code   int
+		reason string
+		err    error
+	}{
+		{"empty", nil, CloseNoStatus, "", nil},
+		{"code", []byte{0x03, 0xe8}, 1000, "", nil},
+		{"reason", []byte{0x0f, 0xa0, 'o', 'k'}, 4000, "ok", nil},
+		{"one byte", []byte{0x03}, 0, "", ErrClosePayload},
+		{"no status code", []byte{0x03, 0xed}, 1005, "", ErrCloseCode},
+		{"reserved code", []byte{0x03, 0xf7}, 1015, "", ErrCloseCode},
+		{"invalid reason", []byte{0x03, 0xe8, 0xff}, 1000, "", ErrCloseReason},
+	}
+	for _, tt := range tests {
+		code, reason, err := ParseClose(tt.p)
+		if code != tt.code || string(reason) != tt.reason || err != tt.err {
+			t.Errorf("%s: ParseClose() = %d, %q, %v, want %d, %q, %v", tt.name, code, reason, err, tt.code, tt.reason, tt.err)
+		}
+	}
+}
+
+func TestAppendClose(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   int
+		reason string
+		want   string
+	}{
+		{"no status", CloseNoStatus, "ignored", ""},
+		{"short", 1000, "bye", "\x03\xe8bye"},
+		{"truncated", 1001, strings.Repeat("a", 200), "\x03\xe9" + strings.Repeat("a", MaxCloseReasonSize)},
+		{"rune boundary", 1001, strings.Repeat("a", 122) + "é", "\x03\xe9" + strings.Repeat("a", 122)},
+	}
+	for _, tt := range tests {
+		if got := string(AppendClose(nil, tt.code, tt.reason)); got != tt.want {
+			t.Errorf("%s: AppendClose() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidCloseCode(t *testing.T) {
+	for code := 0; code < 5500; code++ {
+		want := code >= 1000 && code <= 1003 || code >= 1007 && code <= 1013 || code >= 3000 && code <= 4999
+		if got := ValidCloseCode(code); got != want {
+			t.Errorf("ValidCloseCode(%d) = %v, want %v", code, got, want)
+		}
+	}
+}
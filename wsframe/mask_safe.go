@@ -0,0 +1,20 @@
+// Copyright 2016 The Gorilla WebSocket Authors. All rights reserved.  Use of
+// this source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+//go:build appengine
+// +build appengine
+
+package wsframe
+
+// Mask masks or unmasks b in place with key, as specified in section 5.3
+// of RFC 6455. The pos argument is the position of b[0] in the payload
+// modulo 4. Mask returns the position after the end of b modulo 4, for
+// masking the next part of the payload.
+func Mask(key [4]byte, pos int, b []byte) int {
+	for i := range b {
+		b[i] ^= key[pos&3]
+		pos++
+	}
+	return pos & 3
+}
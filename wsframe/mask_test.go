@@ -4,7 +4,7 @@
 
 // !appengine
 
-package websocket
+package wsframe
 
 import (
 	"fmt"
@@ -34,7 +34,7 @@ func TestMaskBytes(t *testing.T) {
 		for align := 0; align < wordSize; align++ {
 			for pos := 0; pos < 4; pos++ {
 				b := make([]byte, size+align)[align:]
-				maskBytes(key, pos, b)
+				Mask(key, pos, b)
 				maskBytesByByte(key, pos, b)
 				if i := notzero(b); i >= 0 {
 					t.Errorf("size:%d, align:%d, pos:%d, offset:%d", size, align, pos, i)
@@ -54,10 +54,10 @@ func BenchmarkMaskBytes(b *testing.B) {
 						fn   func(key [4]byte, pos int, b []byte) int
 					}{
 						{"byte", maskBytesByByte},
-						{"word", maskBytes},
+						{"word", Mask},
 					} {
 						b.Run(fn.name, func(b *testing.B) {
-							key := newMaskKey()
+							key := [4]byte{1, 2, 3, 4}
 							data := make([]byte, size+align)[align:]
 							for i := 0; i < b.N; i++ {
 								fn.fn(key, 0, data)
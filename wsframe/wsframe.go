@@ -0,0 +1,193 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package wsframe encodes and decodes WebSocket frames as specified in RFC
+// 6455, for tools such as fuzzers, packet analyzers and event loop servers
+// that work on frames instead of connections.
+//
+// The functions do not allocate: decoding returns values and subslices of
+// the input, and encoding appends to a slice provided by the caller:
+//
+//	h, n, err := wsframe.ParseHeader(buf)
+//	if err == wsframe.ErrShortHeader {
+//		// Read more data.
+//	}
+//	payload := buf[n : n+int(h.Length)]
+//	if h.Masked {
+//		wsframe.Mask(h.Key, 0, payload)
+//	}
+//
+// The package checks the encoding of frames only. Rules that depend on the
+// connection, such as the reserved bits of extensions, the sequence of
+// fragments and the direction of masking, are left to the caller.
+package wsframe
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Opcode is the opcode of a frame.
+type Opcode byte
+
+// Opcodes defined by RFC 6455.
+const (
+	OpContinuation Opcode = 0
+	OpText         Opcode = 1
+	OpBinary       Opcode = 2
+	OpClose        Opcode = 8
+	OpPing         Opcode = 9
+	OpPong         Opcode = 10
+)
+
+// IsControl reports whether o is the opcode of a control frame.
+func (o Opcode) IsControl() bool { return o&0x8 != 0 }
+
+// IsData reports whether o is the opcode of the first frame of a text or
+// binary message.
+func (o Opcode) IsData() bool { return o == OpText || o == OpBinary }
+
+const (
+	// MaxHeaderSize is the maximum size of a frame header.
+	MaxHeaderSize = 2 + 8 + 4
+
+	// MaxControlPayloadSize is the maximum payload size of a control frame.
+	MaxControlPayloadSize = 125
+)
+
+const (
+	finalBit = 1 << 7
+	rsv1Bit  = 1 << 6
+	rsv2Bit  = 1 << 5
+	rsv3Bit  = 1 << 4
+	maskBit  = 1 << 7
+)
+
+var (
+	// ErrShortHeader is returned by ParseHeader when the input does not
+	// hold a complete header.
+	ErrShortHeader = errors.New("wsframe: short frame header")
+
+	// ErrInvalidLength is returned by ParseHeader when the most significant
+	// bit of a 64-bit payload length is set.
+	ErrInvalidLength = errors.New("wsframe: invalid payload length")
+)
+
+// Header is a frame header.
+type Header struct {
+	// Fin is set on the final frame of a message.
+	Fin bool
+
+	// Rsv1, Rsv2 and Rsv3 are the reserved bits used by extensions.
+	Rsv1, Rsv2, Rsv3 bool
+
+	// Opcode is the opcode of the frame.
+	Opcode Opcode
+
+	// Masked is set if the payload is masked with Key.
+	Masked bool
+	Key    [4]byte
+
+	// Length is the length of the payload.
+	Length int64
+}
+
+// HeaderSize returns the size of the header of a frame with a payload of
+// length bytes.
+func HeaderSize(length int64, masked bool) int {
+	n := 2
+	switch {
+	case length > 0xffff:
+		n += 8
+	case length > 125:
+		n += 2
+	}
+	if masked {
+		n += 4
+	}
+	return n
+}
+
+// ParseHeader decodes the frame header at the start of p and returns the
+// header and its size. ParseHeader returns ErrShortHeader if p holds part
+// of a header only.
+func ParseHeader(p []byte) (h Header, n int, err error) {
+	if len(p) < 2 {
+		return Header{}, 0, ErrShortHeader
+	}
+	b0, b1 := p[0], p[1]
+	h = Header{
+		Fin:    b0&finalBit != 0,
+		Rsv1:   b0&rsv1Bit != 0,
+		Rsv2:   b0&rsv2Bit != 0,
+		Rsv3:   b0&rsv3Bit != 0,
+		Opcode: Opcode(b0 & 0xf),
+		Masked: b1&maskBit != 0,
+		Length: int64(b1 & 0x7f),
+	}
+	n = 2
+	switch h.Length {
+	case 126:
+		if len(p) < n+2 {
+			return Header{}, 0, ErrShortHeader
+		}
+		h.Length = int64(binary.BigEndian.Uint16(p[n:]))
+		n += 2
+	case 127:
+		if len(p) < n+8 {
+			return Header{}, 0, ErrShortHeader
+		}
+		v := binary.BigEndian.Uint64(p[n:])
+		if v&(1<<63) != 0 {
+			return Header{}, 0, ErrInvalidLength
+		}
+		h.Length = int64(v)
+		n += 8
+	}
+	if h.Masked {
+		if len(p) < n+4 {
+			return Header{}, 0, ErrShortHeader
+		}
+		copy(h.Key[:], p[n:])
+		n += 4
+	}
+	return h, n, nil
+}
+
+// AppendHeader appends the encoding of the header to b and returns the
+// extended slice. The payload length is encoded in the shortest form. The
+// Length of h must not be negative.
+func AppendHeader(b []byte, h Header) []byte {
+	b0 := byte(h.Opcode & 0xf)
+	if h.Fin {
+		b0 |= finalBit
+	}
+	if h.Rsv1 {
+		b0 |= rsv1Bit
+	}
+	if h.Rsv2 {
+		b0 |= rsv2Bit
+	}
+	if h.Rsv3 {
+		b0 |= rsv3Bit
+	}
+	var b1 byte
+	if h.Masked {
+		b1 |= maskBit
+	}
+	switch {
+	case h.Length > 0xffff:
+		b = append(b, b0, b1|127)
+		b = binary.BigEndian.AppendUint64(b, uint64(h.Length))
+	case h.Length > 125:
+		b = append(b, b0, b1|126)
+		b = binary.BigEndian.AppendUint16(b, uint16(h.Length))
+	default:
+		b = append(b, b0, b1|byte(h.Length))
+	}
+	if h.Masked {
+		b = append(b, h.Key[:]...)
+	}
+	return b
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wsframe
+
+import (
+	"bytes"
+	"testing"
+)
+
+var headerTests = []struct {
+	name   string
+	header Header
+	enc    []byte
+}{
+	{"empty text", Header{Fin: true, Opcode: OpText}, []byte{0x81, 0x00}},
+	{"125 bytes", Header{Fin: true, Opcode: OpBinary, Length: 125}, []byte{0x82, 125}},
+	{"126 bytes", Header{Opcode: OpText, Length: 126}, []byte{0x01, 126, 0x00, 126}},
+	{"64k", Header{Fin: true, Opcode: OpContinuation, Length: 0xffff}, []byte{0x80, 126, 0xff, 0xff}},
+	{"64k+1", Header{Fin: true, Opcode: OpBinary, Length: 0x10000}, []byte{0x82, 127, 0, 0, 0, 0, 0, 1, 0, 0}},
+	{"rsv", Header{Fin: true, Rsv1: true, Rsv2: true, Rsv3: true, Opcode: OpText}, []byte{0xf1, 0x00}},
+	{
+		"masked ping",
+		Header{Fin: true, Opcode: OpPing, Masked: true, Key: [4]byte{1, 2, 3, 4}, Length: 5},
+		[]byte{0x89, 0x85, 1, 2, 3, 4},
+	},
+}
+
+func TestHeader(t *testing.T) {
+	for _, tt := range headerTests {
+		enc := AppendHeader(nil, tt.header)
+		if !bytes.Equal(enc, tt.enc) {
+			t.Errorf("%s: AppendHeader() = %x, want %x", tt.name, enc, tt.enc)
+		}
+		if n := HeaderSize(tt.header.Length, tt.header.Masked); n != len(tt.enc) {
+			t.Errorf("%s: HeaderSize() = %d, want %d", tt.name, n, len(tt.enc))
+		}
+		h, n, err := ParseHeader(append(tt.enc, "payload"...))
+		if err != nil || n != len(tt.enc) || h != tt.header {
+			t.Errorf("%s: ParseHeader() = %+v, %d, %v, want %+v, %d", tt.name, h, n, err, tt.header, len(tt.enc))
+		}
+		for i := 0; i < len(tt.enc); i++ {
+			if _, _, err := ParseHeader(tt.enc[:i]); err != ErrShortHeader {
+				t.Errorf("%s: ParseHeader() of %d bytes error = %v, want %v", tt.name, i, err, ErrShortHeader)
+			}
+		}
+	}
+}
+
+func TestParseHeaderInvalidLength(t *testing.T) {
+	p := []byte{0x82, 127, 0x80, 0, 0, 0, 0, 0, 0, 0}
+	if _, _, err := ParseHeader(p); err != ErrInvalidLength {
+		t.Errorf("ParseHeader() error = %v, want %v", err, ErrInvalidLength)
+	}
+}
+
+func TestOpcode(t *testing.T) {
+	tests := []struct {
+		op              Opcode
+		control, isData bool
+	}{
+		{OpContinuation, false, false},
+		{OpText, false, true},
+		{OpBinary, false, true},
+		{OpClose, true, false},
+		{OpPing, true, false},
+		{OpPong, true, false},
+	}
+	for _, tt := range tests {
+		if tt.op.IsControl() != tt.control || tt.op.IsData() != tt.isData {
+			t.Errorf("opcode %d: IsControl() = %v, IsData() = %v", tt.op, tt.op.IsControl(), tt.op.IsData())
+		}
+	}
+}
+
+func TestAllocs(t *testing.T) {
+	buf := make([]byte, 0, MaxHeaderSize+MaxControlPayloadSize)
+	p := []byte{0x88, 0x85, 1, 2, 3, 4, 0x03, 0xe8, 'b', 'y', 'e'}
+	allocs := testing.AllocsPerRun(100, func() {
+		h, n, _ := ParseHeader(p)
+		Mask(h.Key, 0, p[n:])
+		ParseClose(p[n:])
+		Mask(h.Key, 0, p[n:])
+		b := AppendHeader(buf[:0], h)
+		AppendClose(b, 1000, "bye")
+	})
+	if allocs != 0 {
+		t.Errorf("allocations = %v, want 0", allocs)
+	}
+}
+
+func FuzzParseHeader(f *testing.F) {
+	for _, tt := range headerTests {
+		f.Add(tt.enc)
+	}
+	f.Fuzz(func(t *testing.T, p []byte) {
+		h, n, err := ParseHeader(p)
+		if err != nil {
+			return
+		}
+		if n > len(p) || n != HeaderSize(h.Length, h.Masked) && !nonMinimal(p) {
+			t.Fatalf("ParseHeader(%x) = %+v, %d", p, h, n)
+		}
+		h2, n2, err := ParseHeader(AppendHeader(nil, h))
+		if err != nil || h2 != h || n2 != HeaderSize(h.Length, h.Masked) {
+			t.Fatalf("round trip of %+v = %+v, %d, %v", h, h2, n2, err)
+		}
+	})
+}
+
+// nonMinimal reports whether the header at the start of p encodes the
+// payload length in a longer form than needed.
+func nonMinimal(p []byte) bool {
+	switch p[1] & 0x7f {
+	case 126:
+		return p[2] == 0 && p[3] <= 125
+	case 127:
+		return p[2] == 0 && p[3] == 0 && p[4] == 0 && p[5] == 0 && p[6] == 0 && p[7] == 0
+	}
+	return false
+}
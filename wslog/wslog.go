@@ -0,0 +1,166 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.21
+
+// Package wslog logs websocket connection events with log/slog.
+//
+// A Logger logs handshake results with the negotiated subprotocol and
+// compression, protocol violations by the peer, peer quirks tolerated by the
+// connection and close events, with the remote address, the labels of the
+// connection and other details as structured attributes. Clients dial
+// with a context from ClientContext, servers wrap the handler that calls
+// Upgrade, and Trace returns hooks for other uses of websocket.ConnTrace:
+//
+//	l := &wslog.Logger{Logger: logger}
+//	http.Handle("/ws", l.Handler(http.HandlerFunc(serveWs)))
+//	...
+//	c, _, err := dialer.DialContext(l.ClientContext(ctx), url, nil)
+//
+// The package requires Go 1.21 or later.
+package wslog
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Logger logs websocket connection events.
+type Logger struct {
+	// Logger is the logger. If Logger is nil, slog.Default() is used.
+	Logger *slog.Logger
+
+	// Level is the level of successful handshakes, triggered quirks and
+	// close events. If Level is nil, slog.LevelInfo is used.
+	Level slog.Leveler
+
+	// ErrorLevel is the level of failed handshakes, protocol violations
+	// and unexpected close codes. If ErrorLevel is nil, slog.LevelWarn is
+	// used.
+	ErrorLevel slog.Leveler
+
+	// ClosePolicy classifies the close codes received from peers. Close
+	// messages with unexpected codes are logged at ErrorLevel. If
+	// ClosePolicy is nil, websocket.DefaultClosePolicy is used.
+	ClosePolicy *websocket.ClosePolicy
+}
+
+// ClientContext returns a context for Dialer.DialContext that logs the
+// events of the connection.
+func (l *Logger) ClientContext(ctx context.Context) context.Context {
+	return websocket.WithConnTrace(ctx, l.Trace(ctx, "client"))
+}
+
+// Handler returns a handler that logs the events of connections upgraded by
+// h.
+func (l *Logger) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		h.ServeHTTP(w, r.WithContext(websocket.WithConnTrace(ctx, l.Trace(ctx, "server"))))
+	})
+}
+
+// Trace returns hooks that log the events of one connection. The side
+// attribute of the records is "client" or "server". The records are logged
+// with ctx.
+func (l *Logger) Trace(ctx context.Context, side string) *websocket.ConnTrace {
+	var target string
+	var closeOnce sync.Once
+	return &websocket.ConnTrace{
+		HandshakeStart: func(req *http.Request) {
+			if side == "client" {
+				target = req.URL.String()
+			} else {
+				target = req.RemoteAddr
+			}
+		},
+		HandshakeDone: func(c *websocket.Conn, status int, err error) {
+			if err != nil {
+				attrs := []slog.Attr{slog.String("side", side), slog.String("peer", target)}
+				if status != 0 {
+					attrs = append(attrs, slog.Int("status", status))
+				}
+				attrs = append(attrs, slog.String("error", err.Error()))
+				l.log(ctx, l.errorLevel(), "websocket handshake failed", attrs...)
+				return
+			}
+			l.log(ctx, l.level(), "websocket handshake",
+				l.connAttrs(c, side,
+					slog.String("subprotocol", c.Subprotocol()),
+					slog.Bool("compression", c.CompressionNegotiated()))...)
+		},
+		ProtocolError: func(c *websocket.Conn, err error) {
+			l.log(ctx, l.errorLevel(), "websocket protocol violation",
+				l.connAttrs(c, side, slog.String("error", err.Error()))...)
+		},
+		CloseReceived: func(c *websocket.Conn, code int, text string) {
+			policy := l.ClosePolicy
+			if policy == nil {
+				policy = websocket.DefaultClosePolicy
+			}
+			level := l.level()
+			if !policy.Expected(code) {
+				level = l.errorLevel()
+			}
+			attrs := []slog.Attr{slog.Int("code", code)}
+			if name := websocket.CloseCodeName(code); name != "" {
+				attrs = append(attrs, slog.String("name", name))
+			}
+			attrs = append(attrs, slog.String("text", text))
+			l.log(ctx, level, "websocket close received", l.connAttrs(c, side, attrs...)...)
+		},
+		QuirkTriggered: func(c *websocket.Conn, q websocket.Quirk) {
+			l.log(ctx, l.level(), "websocket peer quirk",
+				l.connAttrs(c, side, slog.String("quirk", q.String()))...)
+		},
+		Closed: func(c *websocket.Conn) {
+			closeOnce.Do(func() {
+				l.log(ctx, l.level(), "websocket closed", l.connAttrs(c, side)...)
+			})
+		},
+	}
+}
+
+// connAttrs returns the attributes of records about c: the side, the remote
+// address, the labels of the connection as a group and attrs.
+func (l *Logger) connAttrs(c *websocket.Conn, side string, attrs ...slog.Attr) []slog.Attr {
+	base := []slog.Attr{
+		slog.String("side", side),
+		slog.String("remote_addr", c.RemoteAddr().String()),
+	}
+	if keys := c.LabelKeys(); len(keys) > 0 {
+		labels := make([]interface{}, len(keys))
+		for i, k := range keys {
+			labels[i] = slog.String(k, c.Label(k))
+		}
+		base = append(base, slog.Group("labels", labels...))
+	}
+	return append(base, attrs...)
+}
+
+func (l *Logger) log(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	logger := l.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+func (l *Logger) level() slog.Level {
+	if l.Level == nil {
+		return slog.LevelInfo
+	}
+	return l.Level.Level()
+}
+
+func (l *Logger) errorLevel() slog.Level {
+	if l.ErrorLevel == nil {
+		return slog.LevelWarn
+	}
+	return l.ErrorLevel.Level()
+}
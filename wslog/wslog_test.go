@@ -0,0 +1,175 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.21
+
+package wslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// records collects JSON log records.
+type records struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (r *records) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buf.Write(p)
+}
+
+func (r *records) get(t *testing.T) []map[string]interface{} {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var recs []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(r.buf.String()), "\n") {
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("%q: %v", line, err)
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func TestLogger(t *testing.T) {
+	var out records
+	l := &Logger{
+		Logger: slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		Level:  slog.LevelDebug,
+	}
+	done := make(chan struct{})
+	upgrader := websocket.Upgrader{
+		Subprotocols:      []string{"chat"},
+		EnableCompression: true,
+		Labels: func(r *http.Request) map[string]string {
+			return map[string]string{"tenant": "acme"}
+		},
+	}
+	s := httptest.NewServer(l.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		c.ReadMessage()
+	})))
+	defer s.Close()
+
+	d := websocket.Dialer{Subprotocols: []string{"chat"}, EnableCompression: true}
+	c, _, err := d.DialContext(context.Background(), "ws"+strings.TrimPrefix(s.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "bye"), time.Now().Add(time.Second))
+	<-done
+	c.Close()
+
+	want := []struct {
+		msg   string
+		level string
+		attrs map[string]interface{}
+	}{
+		{"websocket handshake", "DEBUG", map[string]interface{}{"side": "server", "subprotocol": "chat", "compression": true}},
+		{"websocket close received", "DEBUG", map[string]interface{}{"side": "server", "code": 1001.0, "name": "going away", "text": "bye"}},
+		{"websocket closed", "DEBUG", map[string]interface{}{"side": "server"}},
+	}
+	recs := out.get(t)
+	if len(recs) != len(want) {
+		t.Fatalf("got %d records, want %d: %v", len(recs), len(want), recs)
+	}
+	for i, w := range want {
+		rec := recs[i]
+		if rec["msg"] != w.msg || rec["level"] != w.level {
+			t.Errorf("record %d = %v, want %s at %s", i, rec, w.msg, w.level)
+		}
+		for k, v := range w.attrs {
+			if rec[k] != v {
+				t.Errorf("record %d: %s = %v, want %v", i, k, rec[k], v)
+			}
+		}
+		if rec["remote_addr"] == nil {
+			t.Errorf("record %d: no remote_addr", i)
+		}
+		if labels, _ := rec["labels"].(map[string]interface{}); labels["tenant"] != "acme" {
+			t.Errorf("record %d: labels = %v, want tenant acme", i, rec["labels"])
+		}
+	}
+}
+
+func TestLoggerHandshakeFailed(t *testing.T) {
+	var out records
+	l := &Logger{Logger: slog.New(slog.NewJSONHandler(&out, nil))}
+	s := httptest.NewServer(http.NotFoundHandler())
+	defer s.Close()
+
+	u := "ws" + strings.TrimPrefix(s.URL, "http")
+	if _, _, err := websocket.DefaultDialer.DialContext(l.ClientContext(context.Background()), u, nil); err == nil {
+		t.Fatal("Dial() succeeded, want error")
+	}
+	recs := out.get(t)
+	if len(recs) != 1 {
+		t.Fatalf("got %d records, want 1", len(recs))
+	}
+	rec := recs[0]
+	if rec["msg"] != "websocket handshake failed" || rec["level"] != "WARN" ||
+		rec["side"] != "client" || rec["status"] != 404.0 || rec["peer"] != strings.Replace(u, "ws", "http", 1) {
+		t.Errorf("record = %v", rec)
+	}
+}
+
+func TestLoggerClosePolicy(t *testing.T) {
+	if err := websocket.RegisterCloseCode(4321, "test code"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		policy *websocket.ClosePolicy
+		code   int
+		level  string
+	}{
+		{nil, websocket.CloseNormalClosure, "INFO"},
+		{nil, websocket.CloseInternalServerErr, "WARN"},
+		{nil, 4321, "WARN"},
+		{websocket.NewClosePolicy(4321), 4321, "INFO"},
+	}
+	for _, tt := range tests {
+		var out records
+		l := &Logger{Logger: slog.New(slog.NewJSONHandler(&out, nil)), ClosePolicy: tt.policy}
+		c, _ := websocket.Pipe()
+		l.Trace(context.Background(), "client").CloseReceived(c, tt.code, "")
+		c.Close()
+		rec := out.get(t)[0]
+		if rec["level"] != tt.level || rec["name"] != websocket.CloseCodeName(tt.code) {
+			t.Errorf("code %d: record = %v, want level %s", tt.code, rec, tt.level)
+		}
+	}
+}
+
+func TestLoggerQuirk(t *testing.T) {
+	var out records
+	l := &Logger{Logger: slog.New(slog.NewJSONHandler(&out, nil))}
+	c, _ := websocket.Pipe()
+	l.Trace(context.Background(), "server").QuirkTriggered(c, websocket.QuirkShortClose)
+	c.Close()
+	rec := out.get(t)[0]
+	if rec["msg"] != "websocket peer quirk" || rec["level"] != "INFO" || rec["quirk"] != "short_close" {
+		t.Errorf("record = %v", rec)
+	}
+}
@@ -0,0 +1,315 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package wsmetrics collects metrics of websocket connections: connection
+// counts, handshake outcomes, message and byte counts, compression ratios,
+// close codes and the reasons why connections end.
+//
+// Metrics are fed by the websocket.ConnTrace hooks. Clients dial with a
+// context from ClientContext and servers wrap the handler that calls
+// Upgrade:
+//
+//	var m wsmetrics.Metrics
+//	http.Handle("/ws", m.Handler(http.HandlerFunc(serveWs)))
+//	http.Handle("/metrics", &m)
+//	...
+//	c, _, err := dialer.DialContext(m.ClientContext(ctx), url, nil)
+//
+// Metrics serves the Prometheus text format. The package does not depend
+// on the Prometheus client library; to register the metrics with a
+// prometheus.Registry, adapt Collect to a prometheus.Collector:
+//
+//	type collector struct{ m *wsmetrics.Metrics }
+//
+//	func (c collector) Describe(chan<- *prometheus.Desc) {}
+//
+//	func (c collector) Collect(ch chan<- prometheus.Metric) {
+//		c.m.Collect(func(s wsmetrics.Sample) {
+//			var names, values []string
+//			for _, l := range s.Labels {
+//				names, values = append(names, l.Name), append(values, l.Value)
+//			}
+//			t := prometheus.CounterValue
+//			if s.Gauge {
+//				t = prometheus.GaugeValue
+//			}
+//			desc := prometheus.NewDesc(s.Name, s.Help, names, nil)
+//			ch <- prometheus.MustNewConstMetric(desc, t, s.Value, values...)
+//		})
+//	}
+package wsmetrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Label is a metric label.
+type Label struct {
+	Name, Value string
+}
+
+// Sample is the value of a metric with a set of labels.
+type Sample struct {
+	// Name is the metric name, including the namespace.
+	Name string
+
+	// Help describes the metric.
+	Help string
+
+	// Gauge specifies that the metric is a gauge. Other metrics are
+	// counters.
+	Gauge bool
+
+	Labels []Label
+	Value  float64
+}
+
+type family struct {
+	name   string
+	help   string
+	gauge  bool
+	labels []string
+}
+
+// Metric families in the order of families.
+const (
+	connectionsActive = iota
+	connectionsTotal
+	handshakesTotal
+	messagesTotal
+	messageBytesTotal
+	messageWireBytesTotal
+	compressedBytesTotal
+	compressedWireBytesTotal
+	compressionRatio
+	closeCodesTotal
+	closesTotal
+)
+
+var families = []family{
+	{"connections_active", "Number of open connections.", true, []string{"side"}},
+	{"connections_total", "Number of connections opened.", false, []string{"side"}},
+	{"handshakes_total", "Number of opening handshakes by result, which is ok, the HTTP status of a failed handshake or error.", false, []string{"side", "result"}},
+	{"messages_total", "Number of data messages.", false, []string{"side", "direction", "type"}},
+	{"message_bytes_total", "Payload bytes of data messages.", false, []string{"side", "direction"}},
+	{"message_wire_bytes_total", "Payload bytes of data messages on the network.", false, []string{"side", "direction"}},
+	{"compressed_message_bytes_total", "Payload bytes of compressed data messages before compression.", false, []string{"side", "direction"}},
+	{"compressed_message_wire_bytes_total", "Payload bytes of compressed data messages after compression.", false, []string{"side", "direction"}},
+	{"compression_ratio", "Ratio of compressed to uncompressed payload bytes of compressed data messages.", true, []string{"side", "direction"}},
+	{"close_codes_total", "Number of close messages received by close code.", false, []string{"side", "code"}},
+	{"closes_total", "Number of closed connections by initiator, close code of the initiator and read error class.", false, []string{"side", "initiator", "code", "error_class"}},
+}
+
+type series struct {
+	family int
+	labels string // label values separated by 0 bytes
+}
+
+// Metrics collects metrics of websocket connections. The zero value is ready
+// to use. Metrics is safe for concurrent use.
+type Metrics struct {
+	// Namespace is the prefix of the metric names. If Namespace is empty,
+	// "websocket" is used.
+	Namespace string
+
+	// Labels are the keys of the connection labels that are added as metric
+	// labels, such as "tenant". See websocket.Conn.SetLabel. Connections
+	// without a label have the empty value. Handshakes that fail before a
+	// connection exists have empty values for all labels. Labels must not
+	// be changed after the metrics are first used.
+	Labels []string
+
+	mu     sync.Mutex
+	values map[series]float64
+	open   map[*websocket.Conn]string // side of open connections
+}
+
+// ClientContext returns a context for Dialer.DialContext that collects
+// metrics of the connection.
+func (m *Metrics) ClientContext(ctx context.Context) context.Context {
+	return websocket.WithConnTrace(ctx, m.trace("client"))
+}
+
+// Handler returns a handler that collects metrics of connections upgraded by
+// h.
+func (m *Metrics) Handler(h http.Handler) http.Handler {
+	trace := m.trace("server")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(websocket.WithConnTrace(r.Context(), trace)))
+	})
+}
+
+func (m *Metrics) trace(side string) *websocket.ConnTrace {
+	return &websocket.ConnTrace{
+		HandshakeDone: func(c *websocket.Conn, status int, err error) {
+			result := "ok"
+			if err != nil {
+				result = "error"
+				if status != 0 {
+					result = strconv.Itoa(status)
+				}
+			}
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			m.add(c, handshakesTotal, 1, side, result)
+			if c != nil {
+				if m.open == nil {
+					m.open = make(map[*websocket.Conn]string)
+				}
+				m.open[c] = side
+				m.add(c, connectionsActive, 1, side)
+				m.add(c, connectionsTotal, 1, side)
+			}
+		},
+		MessageRead: func(c *websocket.Conn, info websocket.MessageInfo) {
+			m.message(c, side, "read", info)
+		},
+		MessageWritten: func(c *websocket.Conn, info websocket.MessageInfo) {
+			m.message(c, side, "written", info)
+		},
+		CloseReceived: func(c *websocket.Conn, code int, text string) {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			m.add(c, closeCodesTotal, 1, side, strconv.Itoa(code))
+		},
+		Closed: func(c *websocket.Conn) {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			if _, ok := m.open[c]; ok {
+				delete(m.open, c)
+				m.add(c, connectionsActive, -1, side)
+				r := c.CloseReason()
+				code := r.LocalCode
+				if r.Initiator == websocket.InitiatorPeer {
+					code = r.PeerCode
+				}
+				codeLabel := ""
+				if code != 0 {
+					codeLabel = strconv.Itoa(code)
+				}
+				m.add(c, closesTotal, 1, side, r.Initiator, codeLabel, r.ErrorClass)
+			}
+		},
+	}
+}
+
+func (m *Metrics) message(c *websocket.Conn, side, direction string, info websocket.MessageInfo) {
+	typ := "binary"
+	if info.Type == websocket.TextMessage {
+		typ = "text"
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.add(c, messagesTotal, 1, side, direction, typ)
+	m.add(c, messageBytesTotal, float64(info.Size), side, direction)
+	m.add(c, messageWireBytesTotal, float64(info.WireSize), side, direction)
+	if info.Compressed {
+		m.add(c, compressedBytesTotal, float64(info.Size), side, direction)
+		m.add(c, compressedWireBytesTotal, float64(info.WireSize), side, direction)
+	}
+}
+
+// add adds v to a series of the connection c, which may be nil. The caller
+// holds m.mu.
+func (m *Metrics) add(c *websocket.Conn, f int, v float64, labels ...string) {
+	if m.values == nil {
+		m.values = make(map[series]float64)
+	}
+	for _, k := range m.Labels {
+		value := ""
+		if c != nil {
+			value = c.Label(k)
+		}
+		labels = append(labels, value)
+	}
+	m.values[series{f, strings.Join(labels, "\x00")}] += v
+}
+
+// Collect calls fn for each sample in the order of the Prometheus text
+// format.
+func (m *Metrics) Collect(fn func(Sample)) {
+	m.mu.Lock()
+	keys := make([]series, 0, len(m.values))
+	values := make(map[series]float64, len(m.values))
+	for k, v := range m.values {
+		keys = append(keys, k)
+		values[k] = v
+	}
+	m.mu.Unlock()
+
+	// Derive the compression ratios from the byte counts.
+	for k, v := range values {
+		if k.family == compressedBytesTotal && v > 0 {
+			r := series{compressionRatio, k.labels}
+			keys = append(keys, r)
+			values[r] = values[series{compressedWireBytesTotal, k.labels}] / v
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].family != keys[j].family {
+			return keys[i].family < keys[j].family
+		}
+		return keys[i].labels < keys[j].labels
+	})
+	ns := m.Namespace
+	if ns == "" {
+		ns = "websocket"
+	}
+	for _, k := range keys {
+		f := families[k.family]
+		s := Sample{Name: ns + "_" + f.name, Help: f.help, Gauge: f.gauge, Value: values[k]}
+		names := append(f.labels[:len(f.labels):len(f.labels)], m.Labels...)
+		for i, v := range strings.Split(k.labels, "\x00") {
+			s.Labels = append(s.Labels, Label{names[i], v})
+		}
+		fn(s)
+	}
+}
+
+// WritePrometheus writes the metrics to w in the Prometheus text format.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	last := ""
+	m.Collect(func(s Sample) {
+		if s.Name != last {
+			last = s.Name
+			typ := "counter"
+			if s.Gauge {
+				typ = "gauge"
+			}
+			fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", s.Name, s.Help, s.Name, typ)
+		}
+		bw.WriteString(s.Name)
+		for i, l := range s.Labels {
+			if i == 0 {
+				bw.WriteByte('{')
+			} else {
+				bw.WriteByte(',')
+			}
+			fmt.Fprintf(bw, "%s=%q", l.Name, l.Value)
+		}
+		if len(s.Labels) > 0 {
+			bw.WriteByte('}')
+		}
+		fmt.Fprintf(bw, " %s\n", strconv.FormatFloat(s.Value, 'g', -1, 64))
+	})
+	return bw.Flush()
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WritePrometheus(w)
+}
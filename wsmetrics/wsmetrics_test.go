@@ -0,0 +1,176 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wsmetrics
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestMetrics(t *testing.T) {
+	var m Metrics
+	done := make(chan struct{})
+	upgrader := websocket.Upgrader{EnableCompression: true}
+	mux := http.NewServeMux()
+	mux.Handle("/ws", m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("reject") != "" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		defer close(done)
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		for {
+			mt, p, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			c.WriteMessage(mt, p)
+		}
+	})))
+	mux.Handle("/metrics", &m)
+	s := httptest.NewServer(mux)
+	defer s.Close()
+	wsURL := "ws" + strings.TrimPrefix(s.URL, "http") + "/ws"
+
+	ctx := m.ClientContext(context.Background())
+	if _, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL+"?reject=1", nil); err == nil {
+		t.Fatal("Dial() succeeded, want error")
+	}
+	d := websocket.Dialer{EnableCompression: true}
+	c, _, err := d.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs := []struct {
+		mt       int
+		p        []byte
+		compress bool
+	}{
+		{websocket.TextMessage, []byte("hello"), false},
+		{websocket.BinaryMessage, bytes.Repeat([]byte("a"), 1000), true},
+	}
+	for _, msg := range msgs {
+		c.EnableWriteCompression(msg.compress)
+		if err := c.WriteMessage(msg.mt, msg.p); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := c.ReadMessage(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(time.Second))
+	<-done
+	c.Close()
+	c.Close()
+
+	resp, err := http.Get(s.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	p, _ := io.ReadAll(resp.Body)
+	body := string(p)
+	for _, want := range []string{
+		"# TYPE websocket_connections_active gauge\n",
+		`websocket_connections_active{side="client"} 0` + "\n",
+		`websocket_connections_active{side="server"} 0` + "\n",
+		`websocket_connections_total{side="client"} 1` + "\n",
+		`websocket_handshakes_total{side="client",result="503"} 1` + "\n",
+		`websocket_handshakes_total{side="client",result="ok"} 1` + "\n",
+		`websocket_handshakes_total{side="server",result="ok"} 1` + "\n",
+		"# TYPE websocket_messages_total counter\n",
+		`websocket_messages_total{side="client",direction="read",type="binary"} 1` + "\n",
+		`websocket_messages_total{side="server",direction="written",type="text"} 1` + "\n",
+		`websocket_message_bytes_total{side="client",direction="written"} 1005` + "\n",
+		`websocket_compressed_message_bytes_total{side="server",direction="read"} 1000` + "\n",
+		`websocket_close_codes_total{side="server",code="1001"} 1` + "\n",
+		`websocket_closes_total{side="server",initiator="peer",code="1001",error_class=""} 1` + "\n",
+		`websocket_closes_total{side="client",initiator="local",code="1001",error_class=""} 1` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, body)
+		}
+	}
+	if !strings.Contains(body, `websocket_compression_ratio{side="client",direction="written"} 0.0`) {
+		t.Errorf("metrics do not contain compression ratio:\n%s", body)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+}
+
+func TestCollectNamespace(t *testing.T) {
+	m := Metrics{Namespace: "app"}
+	var upgrader websocket.Upgrader
+	s := httptest.NewServer(m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader.Upgrade(w, r, nil)
+	})))
+	defer s.Close()
+	resp, err := http.Get(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	var got []Sample
+	m.Collect(func(s Sample) { got = append(got, s) })
+	if len(got) != 1 {
+		t.Fatalf("got %d samples, want 1", len(got))
+	}
+	s0 := got[0]
+	if s0.Name != "app_handshakes_total" || s0.Gauge || s0.Value != 1 ||
+		len(s0.Labels) != 2 || s0.Labels[1] != (Label{"result", "400"}) {
+		t.Errorf("sample = %+v", s0)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	m := Metrics{Labels: []string{"tenant"}}
+	done := make(chan struct{})
+	upgrader := websocket.Upgrader{Labels: func(r *http.Request) map[string]string {
+		return map[string]string{"tenant": r.URL.Query().Get("tenant")}
+	}}
+	s := httptest.NewServer(m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		c.ReadMessage()
+	})))
+	defer s.Close()
+
+	c, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(s.URL, "http")+"?tenant=acme", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.WriteMessage(websocket.TextMessage, []byte("hello"))
+	<-done
+	c.Close()
+
+	var buf bytes.Buffer
+	m.WritePrometheus(&buf)
+	for _, want := range []string{
+		`websocket_connections_total{side="server",tenant="acme"} 1` + "\n",
+		`websocket_messages_total{side="server",direction="read",type="text",tenant="acme"} 1` + "\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package wsmobile is a websocket client API for iOS and Android apps that
+// use gomobile bind.
+//
+// The package uses only the types that gomobile can bind: the app
+// implements the Listener interface in Java, Kotlin, Objective-C or Swift
+// and receives the events of a connection through it. There are no
+// channels, contexts or functions in the API:
+//
+//	opts := wsmobile.NewOptions()
+//	opts.Subprotocols = "chat"
+//	opts.AddHeader("Authorization", "Bearer "+token)
+//	c, err := wsmobile.Dial("wss://example.com/ws", opts, listener)
+//	if err != nil {
+//		...
+//	}
+//	err = c.SendText("hello")
+//
+// The listener is called from a goroutine of the client, one call at a
+// time. Durations are in milliseconds.
+package wsmobile
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// closeTimeout is the time allowed for the peer to answer the close
+// message sent by Close.
+const closeTimeout = 5 * time.Second
+
+// Listener receives the events of a client.
+type Listener interface {
+	// OnText is called with a text message received from the server.
+	OnText(message string)
+
+	// OnBinary is called with a binary message received from the server.
+	OnBinary(message []byte)
+
+	// OnClose is called once when the connection ends. The code and reason
+	// are the ones of the server's close message. If the connection ended
+	// without a close message, the code is 1006 and the reason describes
+	// the error.
+	OnClose(code int, reason string)
+}
+
+// Options are the options of Dial. Create options with NewOptions.
+type Options struct {
+	// Subprotocols is a comma separated list of the subprotocols requested
+	// from the server.
+	Subprotocols string
+
+	// Compression requests per message compression.
+	Compression bool
+
+	// HandshakeTimeout is the time allowed for the handshake in
+	// milliseconds. If HandshakeTimeout is zero, there is no timeout.
+	HandshakeTimeout int64
+
+	// PingInterval is the time between pings in milliseconds. If the
+	// server does not answer a ping before the next one is due, the
+	// connection ends. If PingInterval is zero, no pings are sent.
+	PingInterval int64
+
+	header http.Header
+}
+
+// NewOptions returns the default options.
+func NewOptions() *Options {
+	return &Options{HandshakeTimeout: 45000, header: make(http.Header)}
+}
+
+// AddHeader adds a header to the handshake request.
+func (o *Options) AddHeader(name, value string) {
+	if o.header == nil {
+		o.header = make(http.Header)
+	}
+	o.header.Add(name, value)
+}
+
+// Client is a websocket client connection. The methods of a Client are safe
+// for concurrent use.
+type Client struct {
+	ws     *websocket.Conn
+	cancel context.CancelFunc
+
+	mu sync.Mutex // serializes writes
+
+	pingMu      sync.Mutex
+	pingPending bool // a ping is not answered yet
+}
+
+// Dial connects to the server at url and starts receiving messages. Dial
+// blocks until the handshake completes. If opts is nil, the default options
+// are used.
+func Dial(url string, opts *Options, l Listener) (*Client, error) {
+	if l == nil {
+		return nil, errors.New("wsmobile: nil listener")
+	}
+	if opts == nil {
+		opts = NewOptions()
+	}
+	d := websocket.Dialer{
+		Proxy:             http.ProxyFromEnvironment,
+		HandshakeTimeout:  time.Duration(opts.HandshakeTimeout) * time.Millisecond,
+		EnableCompression: opts.Compression,
+	}
+	for _, p := range strings.Split(opts.Subprotocols, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			d.Subprotocols = append(d.Subprotocols, p)
+		}
+	}
+	ws, _, err := d.Dial(url, opts.header)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Client{ws: ws, cancel: cancel}
+	if opts.PingInterval > 0 {
+		ws.SetPongHandler(c.pong)
+		go c.ping(ctx, time.Duration(opts.PingInterval)*time.Millisecond)
+	}
+	go c.serve(ctx, l)
+	return c, nil
+}
+
+// Subprotocol returns the subprotocol selected by the server.
+func (c *Client) Subprotocol() string {
+	return c.ws.Subprotocol()
+}
+
+// SendText sends a text message.
+func (c *Client) SendText(message string) error {
+	return c.send(websocket.TextMessage, []byte(message))
+}
+
+// SendBinary sends a binary message.
+func (c *Client) SendBinary(message []byte) error {
+	return c.send(websocket.BinaryMessage, message)
+}
+
+func (c *Client) send(messageType int, p []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ws.WriteMessage(messageType, p)
+}
+
+// Close sends a close message with the code and reason to the server.
+// The connection ends when the server answers or after a timeout, and the
+// listener's OnClose is called then.
+func (c *Client) Close(code int, reason string) error {
+	c.mu.Lock()
+	err := c.ws.CloseWrite(code, reason)
+	c.mu.Unlock()
+	if err != nil {
+		c.cancel()
+		return err
+	}
+	time.AfterFunc(closeTimeout, c.cancel)
+	return nil
+}
+
+// serve reads messages until the connection ends and calls the listener.
+func (c *Client) serve(ctx context.Context, l Listener) {
+	err := c.ws.Serve(ctx, func(messageType int, r io.Reader) error {
+		p, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		if messageType == websocket.TextMessage {
+			l.OnText(string(p))
+		} else {
+			l.OnBinary(p)
+		}
+		return nil
+	})
+	c.cancel()
+	code, reason := websocket.CloseAbnormalClosure, err.Error()
+	var ce *websocket.CloseError
+	if errors.As(err, &ce) {
+		code, reason = ce.Code, ce.Text
+	}
+	l.OnClose(code, reason)
+}
+
+// ping sends pings every interval and ends the connection when a ping is
+// not answered before the next one is due.
+func (c *Client) ping(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		c.pingMu.Lock()
+		unanswered := c.pingPending
+		c.pingPending = true
+		c.pingMu.Unlock()
+		if unanswered {
+			c.cancel()
+			return
+		}
+		if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(interval)); err != nil {
+			return
+		}
+	}
+}
+
+func (c *Client) pong(string) error {
+	c.pingMu.Lock()
+	c.pingPending = false
+	c.pingMu.Unlock()
+	return nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wsmobile
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type event struct {
+	kind string
+	text string
+	code int
+}
+
+// recorder is a Listener that sends the events to a channel.
+type recorder chan event
+
+func (r recorder) OnText(message string)   { r <- event{kind: "text", text: message} }
+func (r recorder) OnBinary(message []byte) { r <- event{kind: "binary", text: string(message)} }
+func (r recorder) OnClose(code int, reason string) {
+	r <- event{kind: "close", text: reason, code: code}
+}
+
+func (r recorder) next(t *testing.T) event {
+	t.Helper()
+	select {
+	case e := <-r:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event")
+		return event{}
+	}
+}
+
+// newServer returns a server that runs handler on the upgraded connections.
+func newServer(t *testing.T, handler func(ws *websocket.Conn)) string {
+	u := websocket.Upgrader{Subprotocols: []string{"chat"}}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		ws, err := u.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		handler(ws)
+	}))
+	t.Cleanup(s.Close)
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+func echo(ws *websocket.Conn) {
+	for {
+		mt, p, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		ws.WriteMessage(mt, p)
+	}
+}
+
+func dialOptions() *Options {
+	opts := NewOptions()
+	opts.Subprotocols = "other, chat"
+	opts.AddHeader("Authorization", "Bearer token")
+	return opts
+}
+
+func TestClient(t *testing.T) {
+	url := newServer(t, echo)
+	r := make(recorder, 10)
+	c, err := Dial(url, dialOptions(), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Subprotocol() != "chat" {
+		t.Errorf("Subprotocol() = %q, want chat", c.Subprotocol())
+	}
+	c.SendText("hello")
+	c.SendBinary([]byte{1, 2})
+	want := []event{{kind: "text", text: "hello"}, {kind: "binary", text: "\x01\x02"}}
+	for _, w := range want {
+		if e := r.next(t); e != w {
+			t.Errorf("event = %+v, want %+v", e, w)
+		}
+	}
+	if err := c.Close(websocket.CloseNormalClosure, "bye"); err != nil {
+		t.Fatal(err)
+	}
+	if e := r.next(t); e.kind != "close" || e.code != websocket.CloseNormalClosure {
+		t.Errorf("event = %+v, want close %d", e, websocket.CloseNormalClosure)
+	}
+	if err := c.SendText("late"); err == nil {
+		t.Error("SendText after Close succeeded")
+	}
+}
+
+func TestClientServerClose(t *testing.T) {
+	url := newServer(t, func(ws *websocket.Conn) {
+		ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(4000, "kicked"))
+		ws.ReadMessage()
+	})
+	r := make(recorder, 10)
+	if _, err := Dial(url, dialOptions(), r); err != nil {
+		t.Fatal(err)
+	}
+	if e := r.next(t); e != (event{kind: "close", text: "kicked", code: 4000}) {
+		t.Errorf("event = %+v, want close 4000 kicked", e)
+	}
+}
+
+func TestClientPing(t *testing.T) {
+	// The server does not read, so pings are not answered.
+	done := make(chan struct{})
+	url := newServer(t, func(ws *websocket.Conn) { <-done })
+	defer close(done)
+	opts := dialOptions()
+	opts.PingInterval = 20
+	r := make(recorder, 10)
+	if _, err := Dial(url, opts, r); err != nil {
+		t.Fatal(err)
+	}
+	if e := r.next(t); e.kind != "close" || e.code != websocket.CloseAbnormalClosure {
+		t.Errorf("event = %+v, want close %d", e, websocket.CloseAbnormalClosure)
+	}
+}
+
+func TestDialError(t *testing.T) {
+	url := newServer(t, echo)
+	if _, err := Dial(url, nil, make(recorder, 1)); err == nil {
+		t.Error("Dial without credentials succeeded")
+	}
+	if _, err := Dial(url, dialOptions(), nil); err == nil {
+		t.Error("Dial with nil listener succeeded")
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package wstrace instruments websocket connections with distributed tracing.
+//
+// The package records a span around the opening handshake and a span for the
+// life of the connection with an event for each message. It uses the
+// websocket.ConnTrace hooks and defines small Tracer and Propagator
+// interfaces so that the websocket module does not depend on a tracing
+// library. An adapter for OpenTelemetry is a few lines:
+//
+//	type otelTracer struct{ trace.Tracer }
+//
+//	func (t otelTracer) Start(ctx context.Context, name string, attrs ...wstrace.Attribute) (context.Context, wstrace.Span) {
+//		kvs := make([]attribute.KeyValue, len(attrs))
+//		for i, a := range attrs {
+//			kvs[i] = attribute.String(a.Key, fmt.Sprint(a.Value))
+//		}
+//		ctx, span := t.Tracer.Start(ctx, name, trace.WithAttributes(kvs...))
+//		return ctx, otelSpan{span}
+//	}
+//
+//	type otelSpan struct{ trace.Span }
+//
+//	func (s otelSpan) AddEvent(name string, attrs ...wstrace.Attribute) { ... }
+//	func (s otelSpan) SetError(err error) { s.RecordError(err); s.SetStatus(codes.Error, err.Error()) }
+//
+//	type otelPropagator struct{ propagation.TextMapPropagator }
+//
+//	func (p otelPropagator) Inject(ctx context.Context, h http.Header) {
+//		p.TextMapPropagator.Inject(ctx, propagation.HeaderCarrier(h))
+//	}
+//
+//	func (p otelPropagator) Extract(ctx context.Context, h http.Header) context.Context {
+//		return p.TextMapPropagator.Extract(ctx, propagation.HeaderCarrier(h))
+//	}
+//
+// Clients dial with a context from ClientContext. The Propagator injects the
+// trace context of the handshake span in the handshake request:
+//
+//	c, _, err := dialer.DialContext(in.ClientContext(ctx), url, nil)
+//
+// Servers wrap the handler that calls Upgrade. The Propagator extracts the
+// client's trace context from the handshake request, so the server spans
+// are children of the client's handshake span:
+//
+//	http.Handle("/ws", in.Handler(http.HandlerFunc(serveWs)))
+package wstrace
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Span names.
+const (
+	DialSpan    = "websocket.dial"
+	UpgradeSpan = "websocket.upgrade"
+	ConnSpan    = "websocket.conn"
+)
+
+// Attribute is a key-value pair attached to a span or an event.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Tracer starts spans.
+type Tracer interface {
+	// Start starts a span that is a child of the span in ctx, if any, and
+	// returns a context containing the new span.
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is an operation being traced.
+type Span interface {
+	// AddEvent adds an event to the span.
+	AddEvent(name string, attrs ...Attribute)
+
+	// SetError records that the operation failed.
+	SetError(err error)
+
+	// End ends the span.
+	End()
+}
+
+// Propagator carries trace context across the handshake.
+type Propagator interface {
+	// Inject sets the trace context in ctx to the request header h.
+	Inject(ctx context.Context, h http.Header)
+
+	// Extract returns a context based on ctx with the trace context in the
+	// request header h.
+	Extract(ctx context.Context, h http.Header) context.Context
+}
+
+// Instrumentation traces websocket connections.
+type Instrumentation struct {
+	// Tracer starts the spans.
+	Tracer Tracer
+
+	// Propagator propagates trace context in the handshake. If Propagator
+	// is nil, trace context is not propagated.
+	Propagator Propagator
+
+	// MessageEvents specifies that an event is added to the connection
+	// span for each message read and written.
+	MessageEvents bool
+}
+
+// ClientContext returns a context for one call to Dialer.DialContext that
+// traces the connection.
+func (in *Instrumentation) ClientContext(ctx context.Context) context.Context {
+	s := &connSpans{in: in, ctx: ctx, handshake: DialSpan}
+	return websocket.WithConnTrace(ctx, s.trace(true))
+}
+
+// Handler returns a handler that traces connections upgraded by h.
+func (in *Instrumentation) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if in.Propagator != nil {
+			ctx = in.Propagator.Extract(ctx, r.Header)
+		}
+		s := &connSpans{in: in, ctx: ctx, handshake: UpgradeSpan}
+		h.ServeHTTP(w, r.WithContext(websocket.WithConnTrace(ctx, s.trace(false))))
+	})
+}
+
+// connSpans holds the spans of one connection.
+type connSpans struct {
+	in        *Instrumentation
+	ctx       context.Context
+	handshake string
+
+	hs Span
+
+	mu     sync.Mutex // protects conn from concurrent reads and writes
+	conn   Span
+	closed bool
+}
+
+func (s *connSpans) trace(client bool) *websocket.ConnTrace {
+	t := &websocket.ConnTrace{
+		HandshakeStart: func(req *http.Request) {
+			var ctx context.Context
+			ctx, s.hs = s.in.Tracer.Start(s.ctx, s.handshake,
+				Attribute{"url", req.URL.String()})
+			if client && s.in.Propagator != nil {
+				s.in.Propagator.Inject(ctx, req.Header)
+			}
+		},
+		HandshakeDone: func(c *websocket.Conn, status int, err error) {
+			if status != 0 {
+				s.hs.AddEvent("response", Attribute{"http.status_code", status})
+			}
+			if err != nil {
+				s.hs.SetError(err)
+			}
+			s.hs.End()
+			if c == nil {
+				return
+			}
+			attrs := []Attribute{{"remote_addr", c.RemoteAddr().String()}}
+			if p := c.Subprotocol(); p != "" {
+				attrs = append(attrs, Attribute{"subprotocol", p})
+			}
+			_, span := s.in.Tracer.Start(s.ctx, ConnSpan, attrs...)
+			s.mu.Lock()
+			s.conn = span
+			s.mu.Unlock()
+		},
+		Closed: func(c *websocket.Conn) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.conn != nil && !s.closed {
+				s.closed = true
+				s.conn.End()
+			}
+		},
+	}
+	if s.in.MessageEvents {
+		t.MessageRead = func(c *websocket.Conn, info websocket.MessageInfo) {
+			s.event("message.read", info)
+		}
+		t.MessageWritten = func(c *websocket.Conn, info websocket.MessageInfo) {
+			s.event("message.written", info)
+		}
+	}
+	return t
+}
+
+func (s *connSpans) event(name string, info websocket.MessageInfo) {
+	typ := "binary"
+	if info.Type == websocket.TextMessage {
+		typ = "text"
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil || s.closed {
+		return
+	}
+	s.conn.AddEvent(name,
+		Attribute{"message.type", typ},
+		Attribute{"message.size", info.Size},
+		Attribute{"message.wire_size", info.WireSize},
+		Attribute{"message.compressed", info.Compressed})
+}
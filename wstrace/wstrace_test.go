@@ -0,0 +1,193 @@
+// Copyright 2026 The Gorilla WebSocket Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wstrace
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+type spanKey struct{}
+
+type span struct {
+	tracer *tracer
+	id     int
+	name   string
+	parent int // 0 if none
+	attrs  map[string]interface{}
+	events []string
+	err    error
+	ended  int
+}
+
+func (s *span) AddEvent(name string, attrs ...Attribute) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	for _, a := range attrs {
+		name += fmt.Sprintf(" %s=%v", a.Key, a.Value)
+	}
+	s.events = append(s.events, name)
+}
+
+func (s *span) SetError(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.err = err
+}
+
+func (s *span) End() {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.ended++
+}
+
+// tracer is a Tracer and a Propagator that records spans. The trace context
+// is the ID of the current span.
+type tracer struct {
+	mu    sync.Mutex
+	spans []*span
+}
+
+func (t *tracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	parent, _ := ctx.Value(spanKey{}).(int)
+	s := &span{tracer: t, id: len(t.spans) + 1, name: name, parent: parent, attrs: map[string]interface{}{}}
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+	t.spans = append(t.spans, s)
+	return context.WithValue(ctx, spanKey{}, s.id), s
+}
+
+func (t *tracer) Inject(ctx context.Context, h http.Header) {
+	if id, ok := ctx.Value(spanKey{}).(int); ok {
+		h.Set("Trace-Parent", strconv.Itoa(id))
+	}
+}
+
+func (t *tracer) Extract(ctx context.Context, h http.Header) context.Context {
+	if id, err := strconv.Atoi(h.Get("Trace-Parent")); err == nil {
+		return context.WithValue(ctx, spanKey{}, id)
+	}
+	return ctx
+}
+
+func (t *tracer) find(name string) *span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, s := range t.spans {
+		if s.name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+func TestInstrumentation(t *testing.T) {
+	tr := &tracer{}
+	in := &Instrumentation{Tracer: tr, Propagator: tr, MessageEvents: true}
+	done := make(chan struct{})
+	upgrader := websocket.Upgrader{Subprotocols: []string{"chat"}}
+	s := httptest.NewServer(in.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		mt, p, err := c.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.WriteMessage(mt, p)
+	})))
+	defer s.Close()
+
+	ctx, root := tr.Start(context.Background(), "root")
+	d := websocket.Dialer{Subprotocols: []string{"chat"}}
+	c, _, err := d.DialContext(in.ClientContext(ctx), "ws"+strings.TrimPrefix(s.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.WriteMessage(websocket.TextMessage, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.ReadMessage(); err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+	c.Close()
+	<-done
+
+	rootID := root.(*span).id
+	dial := tr.find(DialSpan)
+	upgrade := tr.find(UpgradeSpan)
+	if dial == nil || upgrade == nil {
+		t.Fatalf("spans = %v, want dial and upgrade spans", tr.spans)
+	}
+	if dial.parent != rootID || upgrade.parent != dial.id {
+		t.Errorf("dial parent = %d, upgrade parent = %d, want %d, %d", dial.parent, upgrade.parent, rootID, dial.id)
+	}
+	for _, hs := range []*span{dial, upgrade} {
+		if hs.ended != 1 || hs.err != nil || len(hs.events) != 1 || hs.events[0] != "response http.status_code=101" {
+			t.Errorf("%s: ended %d, err %v, events %q", hs.name, hs.ended, hs.err, hs.events)
+		}
+	}
+
+	var conns []*span
+	for _, sp := range tr.spans {
+		if sp.name == ConnSpan {
+			conns = append(conns, sp)
+		}
+	}
+	if len(conns) != 2 {
+		t.Fatalf("got %d connection spans, want 2", len(conns))
+	}
+	for _, sp := range conns {
+		if sp.ended != 1 || sp.attrs["subprotocol"] != "chat" || len(sp.events) != 2 {
+			t.Errorf("connection span: ended %d, attrs %v, events %q", sp.ended, sp.attrs, sp.events)
+		}
+	}
+	client, server := conns[0], conns[1]
+	if client.parent != rootID {
+		client, server = server, client
+	}
+	if server.parent != dial.id {
+		t.Errorf("server connection span parent = %d, want %d", server.parent, dial.id)
+	}
+	const read = "message.read message.type=text message.size=5 message.wire_size=5 message.compressed=false"
+	const written = "message.written message.type=text message.size=5 message.wire_size=5 message.compressed=false"
+	if server.events[0] != read || server.events[1] != written {
+		t.Errorf("server events = %q", server.events)
+	}
+}
+
+func TestInstrumentationHandshakeError(t *testing.T) {
+	tr := &tracer{}
+	in := &Instrumentation{Tracer: tr}
+	s := httptest.NewServer(http.NotFoundHandler())
+	defer s.Close()
+
+	_, _, err := websocket.DefaultDialer.DialContext(in.ClientContext(context.Background()), "ws"+strings.TrimPrefix(s.URL, "http"), nil)
+	if err == nil {
+		t.Fatal("Dial() succeeded, want error")
+	}
+	dial := tr.find(DialSpan)
+	if dial == nil || dial.ended != 1 || dial.err != err || dial.events[0] != "response http.status_code=404" {
+		t.Fatalf("dial span = %+v", dial)
+	}
+	if tr.find(ConnSpan) != nil {
+		t.Error("connection span started for failed handshake")
+	}
+}